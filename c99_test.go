@@ -0,0 +1,88 @@
+package main
+
+import (
+	"cuteify/compile"
+	packageSys "cuteify/package"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// cSkip C 后端不能在 64 位宿主机上运行的程序：cast_test 将指针转换为 4 字节的 uint 后再转换回指针，
+// C 后端按 32 位数据模型映射类型（见 compile/arch/c99 的包文档），64 位宿主机上地址被截断
+var cSkip = map[string]bool{"cast_test": true}
+
+// cFlags 编译生成的 C 代码的 gcc 参数：警告视为错误，只放过源程序本身未使用的参数和变量
+var cFlags = []string{"-std=c99", "-pedantic", "-Wall", "-Wextra", "-Werror", "-Wno-unused-parameter", "-Wno-unused-but-set-variable"}
+
+// TestC99 用 C 后端编译 x86Cases 中的程序（C 后端不区分调用约定，每个程序只运行一次），找到 gcc 时编译运行并检查退出码与标准错误输出；
+// 含有 build asm 的程序生成 #error，跳过
+func TestC99(t *testing.T) {
+	gcc, err := exec.LookPath("gcc")
+	if err != nil {
+		t.Skip("没有找到 gcc")
+	}
+
+	arch, ptrSize := compile.GoArch, typeSys.PtrSize
+	compile.GoArch, typeSys.PtrSize = "c99", compile.WordSize("c99")
+	defer func() { compile.GoArch, typeSys.PtrSize = arch, ptrSize }()
+
+	seen := make(map[string]bool)
+	for _, c := range x86Cases {
+		name := c.name
+		if c.boundsCheck {
+			name += "/bounds-check"
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		t.Run(name, func(t *testing.T) {
+			if cSkip[c.name] && strconv.IntSize == 64 {
+				t.Skip("程序需要 32 位 C 目标")
+			}
+			tmp, err := packageSys.GetPackage("./test/"+c.name, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			co := &compile.Compiler{BoundsCheck: c.boundsCheck}
+			code := co.Compile(tmp.AST.(*parser.Node))
+			if strings.Contains(code, "#error") {
+				t.Skip("C 后端不支持 build asm")
+			}
+
+			dir := t.TempDir()
+			src, bin := filepath.Join(dir, "main.c"), filepath.Join(dir, "main")
+			if err := os.WriteFile(src, []byte(code), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if out, err := exec.Command(gcc, append(cFlags, "-o", bin, src)...).CombinedOutput(); err != nil {
+				t.Fatalf("gcc: %v\n%s", err, out)
+			}
+			cmd := exec.Command(bin)
+			cmd.Dir = dir
+			var stderr strings.Builder
+			cmd.Stderr = &stderr
+			exit := 0
+			if err := cmd.Run(); err != nil {
+				var exitErr *exec.ExitError
+				if !errors.As(err, &exitErr) {
+					t.Fatal(err)
+				}
+				exit = exitErr.ExitCode()
+			}
+			if exit != c.exit {
+				t.Errorf("退出码为 %d，应为 %d", exit, c.exit)
+			}
+			if !strings.Contains(stderr.String(), c.stderr) {
+				t.Errorf("标准错误输出为 %q，应包含 %q", stderr.String(), c.stderr)
+			}
+		})
+	}
+}
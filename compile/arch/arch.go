@@ -2,8 +2,10 @@
 package arch
 
 import (
+	"cuteify/compile/ir"
 	"cuteify/compile/regmgr"
 	"cuteify/parser"
+	typeSys "cuteify/type"
 )
 
 // Arch 定义了针对特定目标架构的完整代码生成接口。
@@ -34,7 +36,63 @@ type Arch interface {
 
 	EndFor(forBlock *parser.ForBlock) string
 
+	// 循环控制
+	// While/EndWhile: 生成 while 循环的头部（条件检查）与尾部（回跳）。
+	While(whileBlock *parser.WhileBlock) string
+	EndWhile(whileBlock *parser.WhileBlock) string
+	// Break/Continue: 跳转到最内层循环（break 也可为 switch）的结束位置/下一次迭代。
+	Break(breakBlock *parser.BreakBlock) string
+	Continue(continueBlock *parser.ContinueBlock) string
+
+	// 分支选择
+	// Switch: 计算匹配值并分派到各 case（稠密整数用跳转表，其余用比较链）。
+	// Case/EndCase: 生成分支入口标签与分支结束后跳出 switch 的代码。
+	Switch(switchBlock *parser.SwitchBlock) string
+	Case(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) string
+	EndCase(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) string
+	EndSwitch(switchBlock *parser.SwitchBlock) string
+
 	GenVarAddr(v *parser.VarBlock) string
+
+	// Data: 生成编译过程中收集的数据（如接口虚表、全局变量）和用到的运行时例程，在所有代码之后输出。
+	Data() string
+}
+
+// Syntax 由不生成汇编的后端（如 C 源码、WebAssembly 文本）实现，接管编译器自身输出的文本：文件头、函数标签、
+// if 分支、内联汇编以及程序入口。未实现时编译器按 NASM 语法输出，if 分支用条件跳转与标签实现。
+type Syntax interface {
+	// Header: 文件开头，root 为整个程序的 AST，可用于预先输出声明。
+	Header(root *parser.Node) string
+	// FuncLabel: 函数入口标签，label 为函数符号名，links 为 build link 导出的名称。
+	FuncLabel(funcBlock *parser.FuncBlock, label string, links []string) string
+	// EndFunc: 函数结束标记，在函数尾声之后输出。
+	EndFunc(funcBlock *parser.FuncBlock) string
+	// If/Else/EndIf: if 分支的开始、else 分支（ElseBlock 带条件时为 else if）的开始与整个分支的结束，
+	// label 为编译器为该分支分配的唯一标签。
+	If(ifBlock *parser.IfBlock, label string) string
+	Else(ifBlock *parser.IfBlock, label string) string
+	EndIf(ifBlock *parser.IfBlock, label string) string
+	// InlineAsm: build asm 块。
+	InlineAsm(build *parser.Build) string
+	// StartEntry: 调用 main 的程序入口。
+	StartEntry() string
+}
+
+// Initializer 由能在变量定义处一次写入完整初值的后端实现：没有初始值的变量定义交给 InitVar，
+// 编译器不再逐个写入结构体字段的默认值。
+type Initializer interface {
+	// InitVar: 没有初始值的局部变量定义，写入零值，结构体包括字段默认值。
+	InitVar(varBlock *parser.VarBlock) string
+}
+
+// Backend 由基于 IR 的后端实现：编译器先将整个程序降低为 ir.Program，再逐个函数交给后端生成代码。
+// 文件头、函数标签与程序入口仍由 Syntax（或默认的 NASM 输出）负责。
+type Backend interface {
+	Info() string
+	// Func: 生成一个函数从序言到尾声的全部代码，不含函数标签。
+	Func(f *ir.Func) string
+	// Data: 与 Arch.Data 相同，在所有代码之后输出数据与运行时例程。
+	Data() string
 }
 
 type ExpResult struct {
@@ -42,18 +100,6 @@ type ExpResult struct {
 	MemOffset int
 }
 
-// 计算参数的栈空间
-func CalcArgsSize(funcBlock *parser.FuncBlock) int {
-	if funcBlock.Args == nil || len(funcBlock.Args) == 0 {
-		return 0
-	}
-	argsSize := 0
-	for i := 0; i < len(funcBlock.Args); i++ {
-		argsSize += funcBlock.Args[i].Type.Size()
-	}
-	return argsSize
-}
-
 func GetNeedSaveRegs(regMgr *regmgr.RegMgr, callerSave bool) (ret []string) {
 	rs := regMgr.Regs
 	for i := 0; i < len(rs); i++ {
@@ -80,6 +126,8 @@ func ResetLocalVarOffset(n *parser.Node, offset int) {
 		switch child.Value.(type) {
 		case *parser.VarBlock:
 			child.Value.(*parser.VarBlock).Offset += offset
+		case *parser.WhileBlock, *parser.SwitchBlock, *parser.CaseBlock:
+			ResetLocalVarOffset(child, offset)
 		case *parser.IfBlock:
 			ResetLocalVarOffset(child, offset)
 			if child.Value.(*parser.IfBlock).Else {
@@ -120,7 +168,9 @@ func collectVarOffsets(node *parser.Node, offset *int) {
 				if varSize == 0 {
 					varSize = 4
 				}
+				// 栈向下增长，向下对齐即可
 				*offset -= varSize
+				*offset &^= typeSys.AlignOf(v.Type) - 1
 				v.Offset = *offset
 			}
 		case *parser.ForBlock:
@@ -131,6 +181,8 @@ func collectVarOffsets(node *parser.Node, offset *int) {
 				initVar.Offset = *offset
 			}
 			collectVarOffsets(child, offset)
+		case *parser.WhileBlock, *parser.SwitchBlock, *parser.CaseBlock:
+			collectVarOffsets(child, offset)
 		case *parser.IfBlock:
 			// 递归处理 if 块中的变量
 			collectVarOffsets(child, offset)
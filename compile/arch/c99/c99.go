@@ -0,0 +1,230 @@
+// Package c99 将 AST 转换为可读的 C99 源码，用于没有 x86 汇编工具链的平台，
+// 也可以用同一份 .cute 分别经汇编后端和 C 后端编译，比较退出码交叉验证语义。
+//
+// 函数与局部变量直接对应 C 的函数与变量（局部变量在函数开头统一声明并清零），
+// if、循环与 switch 对应 C 的控制结构。
+//
+// 类型按 32 位数据模型映射：int、uint 与字符串、切片、接口的大小都按 4 字节的字长计算，
+// C 中的指针仍为宿主机的原生指针。指针与 int/uint 互转的程序因此只能在 32 位 C 目标上运行，
+// 64 位宿主机上地址会被截断。
+package c99
+
+import (
+	"cuteify/compile/arch"
+	"cuteify/compile/context"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strconv"
+)
+
+// C 生成 C99 源码的后端，同时实现 arch.Arch 与 arch.Syntax
+type C struct {
+	ctx *context.Context
+
+	names map[any]string  // 当前函数中变量定义（*VarBlock 或 *ArgBlock）对应的 C 名称
+	used  map[string]bool // 当前函数中已使用的 C 名称
+	ext   bool            // 当前函数是否为 build ext 声明的外部函数（不生成函数体）
+
+	mainRet bool // main 函数是否有返回值
+}
+
+// NewC 创建 C 后端，寄存器由 C 编译器分配，不需要寄存器管理器
+func NewC(ctx *context.Context) *C {
+	return &C{ctx: ctx}
+}
+
+func (a *C) Info() string { return "c99" }
+
+func (a *C) Call(call *parser.CallBlock) string {
+	if call == nil || call.Func == nil {
+		return ""
+	}
+	return utils.Format(a.call(call) + ";")
+}
+
+func (a *C) Return(ret *parser.ReturnBlock) string {
+	funcBlock := a.ctx.CurrentFunc
+	if a.ext || funcBlock == nil {
+		return ""
+	}
+	if ret == nil || len(ret.Value) == 0 {
+		// 有返回值的函数末尾不会执行到这里，不需要补齐 return
+		if len(funcBlock.Return) > 0 {
+			return ""
+		}
+		return utils.Format("return;")
+	}
+	return utils.Format("return " + a.convert(ret.Value[0], funcBlock.Return[0]) + ";")
+}
+
+// Func 输出函数头，并在函数开头声明所有局部变量
+func (a *C) Func(funcBlock *parser.FuncBlock) (code string) {
+	a.names = map[any]string{}
+	a.used = map[string]bool{"self": true}
+	a.ext = arch.ExtName(funcBlock) != ""
+	if a.ext {
+		return ""
+	}
+	code = signature(funcBlock, a.defineArg) + "\n{\n"
+	a.declareLocals(a.ctx.Now, &code)
+	return code
+}
+
+// defineArg 为参数分配 C 名称
+func (a *C) defineArg(arg *parser.ArgBlock) string {
+	return a.define(arg, arg.Name)
+}
+
+// define 为变量定义分配当前函数中唯一的 C 名称，不同作用域中的同名变量依次加数字后缀
+func (a *C) define(def any, name parser.Name) string {
+	if s, ok := a.names[def]; ok {
+		return s
+	}
+	s := cName(name)
+	for k := 1; a.used[s]; k++ {
+		s = cName(name) + "_" + strconv.Itoa(k)
+	}
+	a.used[s] = true
+	a.names[def] = s
+	return s
+}
+
+// declareLocals 按汇编后端分配栈空间的方式遍历函数体，为每个局部变量输出清零的声明
+func (a *C) declareLocals(node *parser.Node, code *string) {
+	for _, child := range node.Children {
+		if child.Ignore {
+			continue
+		}
+		switch v := child.Value.(type) {
+		case *parser.VarBlock:
+			if v.IsDefine && !v.IsGlobal {
+				*code += utils.Format(decl(v.Type, a.define(v, v.Name)) + " = " + zero(v.Type) + ";")
+			}
+		case *parser.ForBlock:
+			if v.Init != nil && v.Init.Var != nil {
+				*code += utils.Format(decl(v.Init.Var.Type, a.define(v.Init.Var, v.Init.Var.Name)) + " = " + zero(v.Init.Var.Type) + ";")
+			}
+			a.declareLocals(child, code)
+		case *parser.WhileBlock, *parser.SwitchBlock, *parser.CaseBlock:
+			a.declareLocals(child, code)
+		case *parser.IfBlock:
+			a.declareLocals(child, code)
+			if v.Else {
+				a.declareLocals(v.ElseBlock, code)
+			}
+		}
+	}
+}
+
+// Exp 输出表达式语句，C 后端的条件由 If、While 等直接生成，不使用 result
+func (a *C) Exp(exp *parser.Expression, result, desc string) string {
+	return utils.Format(a.stmt(exp) + ";")
+}
+
+func (a *C) For(forBlock *parser.ForBlock) (code string) {
+	var init, cond, inc string
+	if forBlock.Init != nil {
+		init = a.stmt(forBlock.Init)
+	}
+	if forBlock.Condition != nil && !(forBlock.Condition.IsConst() && forBlock.Condition.Bool) {
+		cond = a.expr(forBlock.Condition)
+	}
+	if forBlock.Increment != nil {
+		inc = a.stmt(forBlock.Increment)
+	}
+	code = utils.Format("for (" + init + "; " + cond + "; " + inc + ") {")
+	utils.Count++
+	return code
+}
+
+func (a *C) EndFor(forBlock *parser.ForBlock) string {
+	utils.Count--
+	return utils.Format("}")
+}
+
+func (a *C) Var(varBlock *parser.VarBlock) string {
+	if varBlock.Value == nil {
+		// 没有初始值的结构体变量清零（循环中每次执行到定义时都要清零），字段默认值随后写入
+		if t, ok := varBlock.Type.(*typeSys.StructType); ok && !t.IsPointer() {
+			return utils.Format(a.varRef(varBlock) + " = (" + baseType(t) + "){0};")
+		}
+		return ""
+	}
+	return utils.Format(a.assign(varBlock) + ";")
+}
+
+func (a *C) While(whileBlock *parser.WhileBlock) (code string) {
+	code = utils.Format("while " + a.cond(whileBlock.Condition) + " {")
+	utils.Count++
+	return code
+}
+
+func (a *C) EndWhile(whileBlock *parser.WhileBlock) string {
+	utils.Count--
+	return utils.Format("}")
+}
+
+func (a *C) Break(breakBlock *parser.BreakBlock) string {
+	return utils.Format("break;")
+}
+
+func (a *C) Continue(continueBlock *parser.ContinueBlock) string {
+	return utils.Format("continue;")
+}
+
+func (a *C) Switch(switchBlock *parser.SwitchBlock) (code string) {
+	code = utils.Format("switch (" + a.expr(switchBlock.Value) + ") {")
+	utils.Count++
+	return code
+}
+
+func (a *C) Case(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) (code string) {
+	if caseBlock.IsDefault {
+		code += utils.Format("default:")
+	}
+	for _, value := range caseBlock.Values {
+		code += utils.Format("case " + strconv.FormatInt(value.CaseValue(), 10) + ":")
+	}
+	utils.Count++
+	return code
+}
+
+func (a *C) EndCase(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) (code string) {
+	code = utils.Format("break;")
+	utils.Count--
+	return code
+}
+
+func (a *C) EndSwitch(switchBlock *parser.SwitchBlock) string {
+	utils.Count--
+	return utils.Format("}")
+}
+
+// GenVarAddr 返回变量对应的 C 左值
+func (a *C) GenVarAddr(v *parser.VarBlock) string {
+	return a.varRef(v)
+}
+
+// Data 虚表、全局变量等都已在文件头中声明，这里没有需要追加的内容
+func (a *C) Data() string {
+	return ""
+}
+
+// fieldType 沿字段路径返回字段的类型
+func (a *C) fieldType(t typeSys.Type, field string) typeSys.Type {
+	structType, ok := t.(*typeSys.StructType)
+	if !ok {
+		panic("编译器内部错误: " + t.Type() + " 不是结构体")
+	}
+	f := structType.Field(field)
+	if f == nil {
+		if block, exists := a.ctx.GetStruct(structType.Type()); exists {
+			f = block.GetFieldByName(field)
+		}
+	}
+	if f == nil {
+		panic("编译器内部错误: 结构体 " + structType.Type() + " 没有字段 " + field)
+	}
+	return f.Type
+}
@@ -0,0 +1,144 @@
+package c99
+
+import (
+	"cuteify/compile/arch"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strconv"
+	"strings"
+)
+
+// keywords C 的关键字与生成代码中用到的名称，同名的变量加 _ 后缀
+var keywords = map[string]bool{
+	"auto": true, "break": true, "case": true, "char": true, "const": true, "continue": true,
+	"default": true, "do": true, "double": true, "else": true, "enum": true, "extern": true,
+	"float": true, "for": true, "goto": true, "if": true, "inline": true, "int": true,
+	"long": true, "register": true, "restrict": true, "return": true, "short": true, "signed": true,
+	"sizeof": true, "static": true, "struct": true, "switch": true, "typedef": true, "union": true,
+	"unsigned": true, "void": true, "volatile": true, "while": true, "bool": true, "true": true,
+	"false": true, "main": true, "self": true, "cute_fn": true, "cute_slice": true,
+	"cute_iface": true, "cute_index": true,
+}
+
+// cName 将 cute 名称转换为合法的 C 标识符
+func cName(name parser.Name) string {
+	s := name.String()
+	if keywords[s] {
+		s += "_"
+	}
+	return s
+}
+
+// structTag 返回结构体的 C 标签名
+func structTag(t *typeSys.StructType) string {
+	return utils.ToNASMName(t.Type())
+}
+
+// decl 返回以 name 为变量名的 C 声明，name 为空时即为类型名（用于类型转换）
+// 如 int32_t x、struct Point *p、uint8_t buf[32]、int32_t (*p)[4]
+func decl(t typeSys.Type, name string) string {
+	switch t := t.(type) {
+	case *typeSys.PointerType:
+		inner := "*" + name
+		if _, ok := t.Elem.(*typeSys.ArrayType); ok {
+			inner = "(" + inner + ")"
+		}
+		return decl(t.Elem, inner)
+	case *typeSys.ArrayType:
+		return decl(t.Elem, name+"["+strconv.Itoa(t.Len)+"]")
+	}
+	base := baseType(t)
+	if t.IsPointer() {
+		// 以 *T 形式声明但没有使用 PointerType 的参数
+		name = "*" + name
+	}
+	if name == "" {
+		return base
+	}
+	if strings.HasSuffix(base, "*") {
+		return base + name
+	}
+	return base + " " + name
+}
+
+// baseType 返回标量、结构体、切片与接口对应的 C 类型
+func baseType(t typeSys.Type) string {
+	switch t := t.(type) {
+	case *typeSys.StructType:
+		return "struct " + structTag(t)
+	case *typeSys.SliceType:
+		return "cute_slice"
+	case *typeSys.InterfaceType:
+		return "cute_iface"
+	}
+	if bits, signed, ok := typeSys.IntInfo(t); ok {
+		if signed {
+			return "int" + strconv.Itoa(bits) + "_t"
+		}
+		return "uint" + strconv.Itoa(bits) + "_t"
+	}
+	switch typeSys.GetTypeType(t) {
+	case "float":
+		if t.Size() == 4 {
+			return "float"
+		}
+		return "double"
+	case "bool":
+		return "bool"
+	case "string":
+		return "const char *"
+	}
+	panic("编译器内部错误: 无法转换为 C 类型 " + t.Type())
+}
+
+// zero 返回类型的零值初始化式
+func zero(t typeSys.Type) string {
+	switch t.(type) {
+	case *typeSys.StructType, *typeSys.ArrayType, *typeSys.SliceType, *typeSys.InterfaceType:
+		if !t.IsPointer() {
+			return "{0}"
+		}
+	}
+	return "0"
+}
+
+// retType 返回函数的 C 返回类型
+func retType(funcBlock *parser.FuncBlock) string {
+	if len(funcBlock.Return) == 0 {
+		return "void"
+	}
+	return decl(funcBlock.Return[0], "")
+}
+
+// funcName 返回函数的 C 名称：外部函数使用其外部名，main 改名为 cute_main，其余与汇编后端的标签一致
+func funcName(funcBlock *parser.FuncBlock) string {
+	if ext := arch.ExtName(funcBlock); ext != "" {
+		return ext
+	}
+	name := funcBlock.Name.String()
+	if name == "main" {
+		return "cute_main"
+	}
+	return name + strconv.Itoa(len(funcBlock.Args))
+}
+
+// params 返回函数的参数列表，方法以接收者指针 self 作为第一个参数
+func params(funcBlock *parser.FuncBlock, names func(*parser.ArgBlock) string) string {
+	var list []string
+	if structType, ok := funcBlock.Class.(*typeSys.StructType); ok {
+		list = append(list, "struct "+structTag(structType)+" *self")
+	}
+	for _, arg := range funcBlock.Args {
+		list = append(list, decl(arg.Type, names(arg)))
+	}
+	if len(list) == 0 {
+		return "void"
+	}
+	return strings.Join(list, ", ")
+}
+
+// signature 返回函数的 C 声明
+func signature(funcBlock *parser.FuncBlock, names func(*parser.ArgBlock) string) string {
+	return retType(funcBlock) + " " + funcName(funcBlock) + "(" + params(funcBlock, names) + ")"
+}
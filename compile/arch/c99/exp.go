@@ -0,0 +1,268 @@
+package c99
+
+import (
+	"cuteify/compile/data"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strconv"
+	"strings"
+)
+
+// expr 返回表达式的 C 代码，二元运算一律加括号，不依赖 C 的运算符优先级
+func (a *C) expr(exp *parser.Expression) string {
+	switch {
+	case exp.Unary != "":
+		return a.unary(exp)
+	case exp.Index != nil:
+		return a.index(exp)
+	case exp.Field != nil:
+		return a.expr(exp.Left) + "." + cName(exp.Field.Var.Name)
+	case exp.Separator != "":
+		return a.binary(exp)
+	case exp.Call != nil:
+		return a.call(exp.Call)
+	case exp.Var != nil:
+		if exp.Var.Value != nil {
+			return "(" + a.assign(exp.Var) + ")"
+		}
+		return a.varRef(exp.Var)
+	}
+	return constant(exp)
+}
+
+// stmt 返回作为语句（或 for 的初始化、增量部分）的表达式，赋值不加括号
+func (a *C) stmt(exp *parser.Expression) string {
+	if exp.Var != nil && exp.Var.Value != nil && exp.Separator == "" && exp.Unary == "" && exp.Index == nil {
+		return a.assign(exp.Var)
+	}
+	return a.expr(exp)
+}
+
+// cond 返回带括号的条件表达式
+func (a *C) cond(exp *parser.Expression) string {
+	code := a.expr(exp)
+	if strings.HasPrefix(code, "(") && strings.HasSuffix(code, ")") {
+		return code
+	}
+	return "(" + code + ")"
+}
+
+// binary 二元运算，指针加减的偏移量在解析时已按元素大小换算为字节数
+func (a *C) binary(exp *parser.Expression) string {
+	if exp.Separator == "^" {
+		panic("编译器内部错误: C 后端不支持非常量的 ^ 运算")
+	}
+	if ptr, ok := exp.Type.(*typeSys.PointerType); ok && (exp.Separator == "+" || exp.Separator == "-") {
+		return "((" + decl(ptr, "") + ")((char *)" + a.expr(exp.Left) + " " + exp.Separator + " " + a.expr(exp.Right) + "))"
+	}
+	return "(" + a.expr(exp.Left) + " " + exp.Separator + " " + a.expr(exp.Right) + ")"
+}
+
+// assign 返回赋值表达式，包括通过指针或下标的赋值
+func (a *C) assign(v *parser.VarBlock) string {
+	if v.Store != nil {
+		return a.expr(v.Store) + " = " + a.convert(v.Value, v.Store.Type)
+	}
+	return a.varRef(v) + " = " + a.convert(v.Value, v.Type)
+}
+
+// varRef 返回变量引用的 C 左值，self 为接收者指针，其字段通过 -> 访问
+func (a *C) varRef(v *parser.VarBlock) string {
+	ref, t := a.varBase(v)
+	if ref == "self" && !v.Name.IsPath() {
+		return "(*self)"
+	}
+	for i, field := range v.Name[1:] {
+		if i == 0 && ref == "self" {
+			ref += "->" + cName(parser.Name{field})
+		} else {
+			ref += "." + cName(parser.Name{field})
+		}
+		t = a.fieldType(t, field)
+	}
+	return ref
+}
+
+// varBase 返回变量引用对应定义的 C 名称与类型
+func (a *C) varBase(v *parser.VarBlock) (string, typeSys.Type) {
+	if v.Name.First() == "self" && a.ctx.CurrentFunc != nil && a.ctx.CurrentFunc.Class != nil {
+		return "self", a.ctx.CurrentFunc.Class
+	}
+	def := data.Define(v)
+	if def.IsGlobal {
+		return data.GlobalLabel(def.Name), def.Type
+	}
+	var key any = def
+	t := def.Type
+	if v.Define != nil {
+		if arg, ok := v.Define.Value.(*parser.ArgBlock); ok {
+			key, t = arg, arg.Type
+		}
+	}
+	name, ok := a.names[key]
+	if !ok {
+		panic("编译器内部错误: 未声明的变量 " + v.Name.String())
+	}
+	return name, t
+}
+
+// convert 返回按目标类型传递的值：结构体转换为接口时取地址并附上虚表，数组转换为切片时附上长度
+func (a *C) convert(value *parser.Expression, to typeSys.Type) string {
+	switch to := to.(type) {
+	case *typeSys.InterfaceType:
+		if src, ok := value.Type.(*typeSys.StructType); ok && value.Var != nil {
+			return "(cute_iface){" + a.addr(value.Var) + ", " + vtableName(src, to) + "}"
+		}
+	case *typeSys.SliceType:
+		if src, ok := value.Type.(*typeSys.ArrayType); ok && value.Var != nil {
+			return "(cute_slice){" + a.varRef(value.Var) + ", " + strconv.Itoa(src.Len) + "}"
+		}
+	}
+	return a.expr(value)
+}
+
+// addr 返回变量的地址，self 本身即为地址
+func (a *C) addr(v *parser.VarBlock) string {
+	if v.IsSelf(a.ctx.CurrentFunc) && !v.Name.IsPath() {
+		return "self"
+	}
+	return "&" + a.varRef(v)
+}
+
+// call 返回函数调用表达式，方法以接收者地址作为第一个实参，接口方法通过虚表间接调用
+func (a *C) call(call *parser.CallBlock) string {
+	var args []string
+	for _, arg := range call.Args {
+		if arg == nil {
+			continue
+		}
+		args = append(args, a.convert(arg.Value, arg.Type))
+	}
+	if call.ThisVar == nil {
+		return funcName(call.Func) + "(" + strings.Join(args, ", ") + ")"
+	}
+	if iface, ok := call.ThisVar.Type.(*typeSys.InterfaceType); ok {
+		ref := a.varRef(call.ThisVar)
+		types := []string{"void *"}
+		for _, arg := range call.Func.Args {
+			types = append(types, decl(arg.Type, ""))
+		}
+		fn := retType(call.Func) + " (*)(" + strings.Join(types, ", ") + ")"
+		slot := strconv.Itoa(parser.MethodSlot(iface, call.Name.Last()))
+		return "((" + fn + ")" + ref + ".vtable[" + slot + "])(" + strings.Join(append([]string{ref + ".data"}, args...), ", ") + ")"
+	}
+	recv := a.addr(call.ThisVar)
+	// 调用父结构体的方法，父结构体的字段位于子结构体开头
+	if class, ok := call.Func.Class.(*typeSys.StructType); ok && structTag(class) != a.recvTag(call.ThisVar) {
+		recv = "(struct " + structTag(class) + " *)" + recv
+	}
+	return funcName(call.Func) + "(" + strings.Join(append([]string{recv}, args...), ", ") + ")"
+}
+
+// recvTag 返回接收者的结构体标签
+func (a *C) recvTag(v *parser.VarBlock) string {
+	t := v.Type
+	if v.IsSelf(a.ctx.CurrentFunc) && !v.Name.IsPath() {
+		t = a.ctx.CurrentFunc.Class
+	}
+	if structType, ok := t.(*typeSys.StructType); ok {
+		return structTag(structType)
+	}
+	return ""
+}
+
+// unary 一元运算：取地址、解引用、切片长度与类型转换
+func (a *C) unary(exp *parser.Expression) string {
+	switch exp.Unary {
+	case "&":
+		if exp.Right.Var != nil && exp.Right.Unary == "" && exp.Right.Index == nil {
+			return a.addr(exp.Right.Var)
+		}
+		return "(&" + a.expr(exp.Right) + ")"
+	case "*":
+		return "(*" + a.expr(exp.Right) + ")"
+	case "len":
+		return a.expr(exp.Right) + ".len"
+	case "as":
+		// 指针与整数之间经 uintptr_t 转换，整数只有 4 字节，64 位目标上地址会被截断（见包文档）
+		if exp.Type.IsPointer() != exp.Right.Type.IsPointer() {
+			return "((" + decl(exp.Type, "") + ")(uintptr_t)" + a.expr(exp.Right) + ")"
+		}
+		return "((" + decl(exp.Type, "") + ")" + a.expr(exp.Right) + ")"
+	}
+	panic("编译器内部错误: 未知的一元运算 " + exp.Unary)
+}
+
+// index 下标访问，开启越界检查时下标经过 cute_index 检查
+func (a *C) index(exp *parser.Expression) string {
+	base := a.expr(exp.Left)
+	idx := a.expr(exp.Index)
+	switch t := exp.Left.Type.(type) {
+	case *typeSys.ArrayType:
+		if a.ctx.BoundsCheck && !exp.Index.IsConst() {
+			idx = "cute_index(" + idx + ", " + strconv.Itoa(t.Len) + ")"
+		}
+		return base + "[" + idx + "]"
+	case *typeSys.SliceType:
+		if a.ctx.BoundsCheck {
+			idx = "cute_index(" + idx + ", " + base + ".len)"
+		}
+		return "((" + decl(typeSys.NewPointerType(t.Elem), "") + ")" + base + ".ptr)[" + idx + "]"
+	}
+	panic("编译器内部错误: 不能对 " + exp.Left.Type.Type() + " 使用下标")
+}
+
+// constant 返回常量的 C 字面量
+func constant(exp *parser.Expression) string {
+	if exp.Type == nil {
+		// 折叠后的比较结果
+		return strconv.FormatBool(exp.Bool)
+	}
+	switch typeSys.GetTypeType(exp.Type) {
+	case "bool":
+		return strconv.FormatBool(exp.Bool)
+	case "string":
+		return cString(exp.StringVal)
+	case "float":
+		s := strconv.FormatFloat(exp.Num, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eEn") {
+			s += ".0"
+		}
+		if exp.Type.Size() == 4 {
+			s += "f"
+		}
+		return s
+	}
+	if exp.Num < 0 {
+		return "(" + strconv.FormatInt(int64(exp.Num), 10) + ")"
+	}
+	if _, signed, _ := typeSys.IntInfo(exp.Type); !signed && exp.Num > 0x7fffffff {
+		return strconv.FormatUint(uint64(exp.Num), 10) + "u"
+	}
+	return strconv.FormatInt(int64(exp.Num), 10)
+}
+
+// cString 返回 C 字符串字面量，不可打印字符使用八进制转义
+func cString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch == '"' || ch == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(ch)
+		case ch < 0x20 || ch >= 0x7f:
+			b.WriteString("\\" + strconv.FormatInt(int64(ch)|0o1000, 8)[1:])
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// vtableName 返回结构体实现接口时的虚表名，与汇编后端的虚表标签一致
+func vtableName(structType *typeSys.StructType, iface *typeSys.InterfaceType) string {
+	return utils.ToNASMName("vtable_" + structType.Type() + "_" + iface.Type())
+}
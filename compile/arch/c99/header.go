@@ -0,0 +1,207 @@
+package c99
+
+import (
+	"cuteify/compile/arch"
+	"cuteify/compile/data"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strings"
+)
+
+// runtime 所有生成的 C 文件共用的类型：切片为 (数据指针, 长度)，接口为 (数据指针, 虚表)
+const runtime = `typedef void (*cute_fn)(void);
+typedef struct { void *ptr; int32_t len; } cute_slice;
+typedef struct { void *data; const cute_fn *vtable; } cute_iface;
+`
+
+// boundsCheck 越界检查例程，与汇编后端一样向 stderr 输出提示并以退出码 2 结束进程
+const boundsCheck = `static inline int32_t cute_index(int32_t i, int32_t len)
+{
+    if ((uint32_t)i >= (uint32_t)len) {
+        fputs("panic: index out of range\n", stderr);
+        exit(2);
+    }
+    return i;
+}
+`
+
+// Header 输出头文件、结构体定义、函数原型、全局变量和虚表，函数体之间因此不需要考虑定义顺序
+func (a *C) Header(root *parser.Node) string {
+	var b strings.Builder
+	b.WriteString("/* 由 cuteify 生成的 C99 代码 */\n")
+	b.WriteString("#include <stdbool.h>\n#include <stdint.h>\n")
+	if a.ctx.BoundsCheck {
+		b.WriteString("#include <stdio.h>\n#include <stdlib.h>\n")
+	}
+	b.WriteString("\n" + runtime + "\n")
+
+	structs, ifaces, funcs, globals := arch.Collect(root)
+
+	// 结构体按字段依赖排序，被包含的结构体先定义
+	done := map[*typeSys.StructType]bool{}
+	var order []*typeSys.StructType
+	var visit func(t typeSys.Type)
+	visit = func(t typeSys.Type) {
+		for {
+			array, ok := t.(*typeSys.ArrayType)
+			if !ok {
+				break
+			}
+			t = array.Elem
+		}
+		st, ok := t.(*typeSys.StructType)
+		if !ok || t.IsPointer() || done[st] {
+			return
+		}
+		done[st] = true
+		for _, field := range st.StructFields {
+			visit(field.Type)
+		}
+		order = append(order, st)
+	}
+	for _, st := range structs {
+		visit(st)
+	}
+	for _, st := range order {
+		b.WriteString("struct " + structTag(st) + ";\n")
+	}
+	for _, st := range order {
+		b.WriteString("\nstruct " + structTag(st) + " {\n")
+		for _, field := range st.StructFields {
+			b.WriteString("    " + decl(field.Type, cName(parser.Name{field.Name})) + ";\n")
+		}
+		if len(st.StructFields) == 0 {
+			b.WriteString("    char _unused;\n")
+		}
+		b.WriteString("};\n")
+	}
+
+	b.WriteString("\n")
+	for _, funcBlock := range funcs {
+		argName := func(arg *parser.ArgBlock) string { return cName(arg.Name) }
+		if arch.ExtName(funcBlock) != "" {
+			b.WriteString("extern ")
+		}
+		b.WriteString(signature(funcBlock, argName) + ";\n")
+		if funcBlock.Name.String() == "main" {
+			a.mainRet = len(funcBlock.Return) > 0
+		}
+	}
+
+	if len(globals) > 0 {
+		b.WriteString("\n")
+	}
+	for _, v := range globals {
+		init := ""
+		if v.Value != nil {
+			init = constant(v.Value)
+		} else {
+			init = defaults(v.Type)
+		}
+		if init != "" {
+			init = " = " + init
+		}
+		b.WriteString(decl(v.Type, data.GlobalLabel(v.Name)) + init + ";\n")
+	}
+
+	// 所有方法都生成了代码的 (结构体, 接口) 组合才输出虚表
+	for _, iface := range ifaces {
+		for _, st := range order {
+			var methods []string
+			for _, m := range iface.Methods {
+				method := parser.FindMethod(st, m.(*parser.FuncBlock).Name.Last())
+				if method == nil || !method.Useful {
+					methods = nil
+					break
+				}
+				methods = append(methods, "(cute_fn)"+funcName(method))
+			}
+			if methods != nil {
+				b.WriteString("\nconst cute_fn " + vtableName(st, iface) + "[] = {" + strings.Join(methods, ", ") + "};\n")
+			}
+		}
+	}
+
+	if a.ctx.BoundsCheck {
+		b.WriteString("\n" + boundsCheck)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// defaults 返回结构体字段默认值的指派初始化式，没有默认值时为空
+func defaults(t typeSys.Type) string {
+	st, ok := t.(*typeSys.StructType)
+	if !ok || t.IsPointer() {
+		return ""
+	}
+	var items []string
+	for _, field := range st.StructFields {
+		value := defaults(field.Type)
+		if def, ok := field.Default.(*parser.Expression); ok && def != nil {
+			value = constant(def)
+		}
+		if value != "" {
+			items = append(items, "."+cName(parser.Name{field.Name})+" = "+value)
+		}
+	}
+	if len(items) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(items, ", ") + "}"
+}
+
+// FuncLabel 函数头由 Func 输出，这里只注明 build link 导出的名称
+func (a *C) FuncLabel(funcBlock *parser.FuncBlock, label string, links []string) (code string) {
+	for _, link := range links {
+		code += "/* build link: " + link + " */\n"
+	}
+	return code
+}
+
+func (a *C) EndFunc(funcBlock *parser.FuncBlock) string {
+	if a.ext {
+		return ""
+	}
+	return "}\n\n"
+}
+
+func (a *C) If(ifBlock *parser.IfBlock, label string) (code string) {
+	code = utils.Format("if " + a.cond(ifBlock.Condition) + " {")
+	utils.Count++
+	return code
+}
+
+func (a *C) Else(ifBlock *parser.IfBlock, label string) (code string) {
+	utils.Count--
+	if cond := ifBlock.ElseBlock.Value.(*parser.ElseBlock).IfCondition; cond != nil {
+		code = utils.Format("} else if " + a.cond(cond) + " {")
+	} else {
+		code = utils.Format("} else {")
+	}
+	utils.Count++
+	return code
+}
+
+func (a *C) EndIf(ifBlock *parser.IfBlock, label string) string {
+	utils.Count--
+	return utils.Format("}")
+}
+
+// InlineAsm 内联汇编依赖 x86 栈帧布局，无法转换为 C，生成编译期错误提示
+func (a *C) InlineAsm(build *parser.Build) string {
+	name := "?"
+	if a.ctx.CurrentFunc != nil {
+		name = a.ctx.CurrentFunc.Name.String()
+	}
+	return "#error \"build asm in " + name + " is not supported by the C backend\"\n"
+}
+
+// StartEntry C 程序的入口，以 main 的返回值作为退出码
+func (a *C) StartEntry() string {
+	if a.mainRet {
+		return "int main(void)\n{\n    return cute_main();\n}\n"
+	}
+	return "int main(void)\n{\n    cute_main();\n    return 0;\n}\n"
+}
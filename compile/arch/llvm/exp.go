@@ -0,0 +1,414 @@
+package llvm
+
+import (
+	"cuteify/compile/data"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// value 生成计算表达式的指令，返回以 want 类型表示的值（want 为空时按表达式自身的类型），
+// 结构体、数组、切片与接口作为一等聚合值传递
+func (a *LLVM) value(exp *parser.Expression, want typeSys.Type) string {
+	if want == nil {
+		want = exp.Type
+	}
+	if exp.IsConst() {
+		return a.constant(exp, want)
+	}
+	if v, ok := a.pair(exp, want); ok {
+		return v
+	}
+	var v string
+	switch {
+	case exp.Unary != "":
+		v = a.unary(exp)
+	case exp.Index != nil:
+		v = a.load(exp.Type, a.elemAddr(exp))
+	case exp.Field != nil:
+		v = a.load(exp.Type, a.lvalue(exp))
+	case exp.Separator != "":
+		v = a.binary(exp)
+	case exp.Call != nil:
+		v = a.call(exp.Call)
+	case exp.Var != nil:
+		if exp.Var.Value != nil {
+			// 赋值表达式的值为赋值后的变量
+			a.assign(exp.Var)
+			if exp.Var.Store != nil {
+				return a.value(exp.Var.Store, want)
+			}
+		}
+		p, t := a.varAddr(exp.Var)
+		v = a.load(t, p)
+	}
+	if exp.Type == nil || want == nil {
+		return v
+	}
+	return a.cast(v, exp.Type, want)
+}
+
+// stmt 生成表达式语句，丢弃表达式的值
+func (a *LLVM) stmt(exp *parser.Expression) {
+	if exp.Var != nil && exp.Var.Value != nil && exp.Separator == "" && exp.Unary == "" && exp.Index == nil {
+		a.assign(exp.Var)
+		return
+	}
+	a.value(exp, nil)
+}
+
+// load 从地址 p 读取 t 类型的值
+func (a *LLVM) load(t typeSys.Type, p string) string {
+	r := a.tmp()
+	a.emit(r + " = load " + typ(t) + ", ptr " + p)
+	return r
+}
+
+// binary 二元运算，比较的操作数按较宽的一侧计算，指针加减的偏移量在解析时已按元素大小换算为字节数
+func (a *LLVM) binary(exp *parser.Expression) string {
+	switch exp.Separator {
+	case "&&", "||":
+		return a.logic(exp)
+	case "^":
+		panic("编译器内部错误: LLVM 后端不支持非常量的 ^ 运算")
+	}
+	if exp.Type != nil && typ(exp.Type) == "ptr" && (exp.Separator == "+" || exp.Separator == "-") {
+		p := a.value(exp.Left, nil)
+		off := a.value(exp.Right, word)
+		if exp.Separator == "-" {
+			neg := a.tmp()
+			a.emit(neg + " = sub " + typ(word) + " 0, " + off)
+			off = neg
+		}
+		r := a.tmp()
+		a.emit(r + " = getelementptr i8, ptr " + p + ", " + typ(word) + " " + off)
+		return r
+	}
+
+	t := exp.Type
+	switch exp.Separator {
+	case "==", "!=", "<", ">", "<=", ">=":
+		t = exp.OperandType()
+	}
+	l := a.value(exp.Left, t)
+	r := a.value(exp.Right, t)
+
+	lt := typ(t)
+	fp := isFloat(lt)
+	s := signed(t)
+	pick := func(sop, uop string) string {
+		if s {
+			return sop
+		}
+		return uop
+	}
+	var op string
+	switch exp.Separator {
+	case "+", "-", "*":
+		op = map[string]string{"+": "add", "-": "sub", "*": "mul"}[exp.Separator]
+		if fp {
+			op = "f" + op
+		}
+	case "/":
+		op = pick("sdiv", "udiv")
+		if fp {
+			op = "fdiv"
+		}
+	case "%":
+		op = pick("srem", "urem")
+		if fp {
+			op = "frem"
+		}
+	case "&":
+		op = "and"
+	case "|":
+		op = "or"
+	case "<<":
+		op = "shl"
+	case ">>":
+		op = pick("ashr", "lshr")
+	case "==", "!=":
+		op = "icmp " + map[string]string{"==": "eq", "!=": "ne"}[exp.Separator]
+		if fp {
+			op = "fcmp " + map[string]string{"==": "oeq", "!=": "une"}[exp.Separator]
+		}
+	case "<", ">", "<=", ">=":
+		pred := map[string]string{"<": "lt", ">": "gt", "<=": "le", ">=": "ge"}[exp.Separator]
+		op = "icmp " + pick("s", "u") + pred
+		if fp {
+			op = "fcmp o" + pred
+		}
+	default:
+		panic("编译器内部错误: 未知的运算符 " + exp.Separator)
+	}
+	v := a.tmp()
+	a.emit(v + " = " + op + " " + lt + " " + l + ", " + r)
+	return v
+}
+
+// logic 短路求值的 && 与 ||，右侧只在需要时计算，结果由 phi 合并
+func (a *LLVM) logic(exp *parser.Expression) string {
+	l := a.value(exp.Left, nil)
+	label := a.newLabel("logic")
+	rhs, end := label+".rhs", label+".end"
+	short := "false"
+	if exp.Separator == "&&" {
+		a.term("br i1 " + l + ", label %" + rhs + ", label %" + end)
+	} else {
+		short = "true"
+		a.term("br i1 " + l + ", label %" + end + ", label %" + rhs)
+	}
+	from := a.block
+	a.label(rhs)
+	r := a.value(exp.Right, nil)
+	a.jump(end)
+	to := a.block
+	a.label(end)
+	v := a.tmp()
+	a.emit(v + " = phi i1 [ " + short + ", %" + from + " ], [ " + r + ", %" + to + " ]")
+	return v
+}
+
+// assign 生成赋值语句，包括通过指针或下标的赋值
+func (a *LLVM) assign(v *parser.VarBlock) {
+	p, t := "", typeSys.Type(nil)
+	if v.Store != nil {
+		p, t = a.lvalue(v.Store), v.Store.Type
+	} else {
+		p, t = a.varAddr(v)
+	}
+	a.emit("store " + typ(t) + " " + a.value(v.Value, t) + ", ptr " + p)
+}
+
+// pair 结构体转换为接口时组成 (数据地址, 虚表)，数组转换为切片时组成 (数据地址, 长度)，不需要转换时 ok 为 false
+func (a *LLVM) pair(value *parser.Expression, t typeSys.Type) (string, bool) {
+	var second string
+	switch to := t.(type) {
+	case *typeSys.InterfaceType:
+		src, ok := value.Type.(*typeSys.StructType)
+		if !ok || src.IsPointer() {
+			return "", false
+		}
+		second = "ptr " + a.vtable(src, to)
+	case *typeSys.SliceType:
+		src, ok := value.Type.(*typeSys.ArrayType)
+		if !ok {
+			return "", false
+		}
+		second = typ(word) + " " + strconv.Itoa(src.Len)
+	default:
+		return "", false
+	}
+	first := a.tmp()
+	a.emit(first + " = insertvalue " + typ(t) + " undef, ptr " + a.addr(value) + ", 0")
+	r := a.tmp()
+	a.emit(r + " = insertvalue " + typ(t) + " " + first + ", " + second + ", 1")
+	return r, true
+}
+
+// varAddr 返回变量（或其字段）的地址与类型，字段通过 getelementptr 按字段下标访问
+func (a *LLVM) varAddr(v *parser.VarBlock) (p string, t typeSys.Type) {
+	switch def := data.Define(v); {
+	case v.IsSelf(a.ctx.CurrentFunc):
+		p, t = "%self", a.ctx.CurrentFunc.Class
+	case def.IsGlobal:
+		p, t = ident("@", data.GlobalLabel(def.Name)), def.Type
+	default:
+		var k any
+		k, t = key(v)
+		s, ok := a.slots[k]
+		if !ok {
+			panic("编译器内部错误: 未声明的变量 " + v.Name.String())
+		}
+		p = s
+	}
+	for _, name := range v.Name[1:] {
+		index, field := a.field(t, name)
+		r := a.tmp()
+		a.emit(r + " = getelementptr inbounds " + typ(t) + ", ptr " + p + ", i32 0, i32 " + strconv.Itoa(index))
+		p, t = r, field.Type
+	}
+	return p, t
+}
+
+// key 返回变量引用对应的定义（*VarBlock 或参数的 *ArgBlock）及其类型
+func key(v *parser.VarBlock) (any, typeSys.Type) {
+	if v.Define != nil {
+		if arg, ok := v.Define.Value.(*parser.ArgBlock); ok {
+			return arg, arg.Type
+		}
+	}
+	def := data.Define(v)
+	return def, def.Type
+}
+
+// lvalue 返回赋值目标的地址：变量、解引用、下标或下标结果的字段
+func (a *LLVM) lvalue(exp *parser.Expression) string {
+	switch {
+	case exp.Unary == "*":
+		return a.value(exp.Right, nil)
+	case exp.Index != nil:
+		return a.elemAddr(exp)
+	case exp.Field != nil:
+		index, _ := a.field(exp.Left.Type, exp.Field.Var.Name.String())
+		r := a.tmp()
+		a.emit(r + " = getelementptr inbounds " + typ(exp.Left.Type) + ", ptr " + a.lvalue(exp.Left) + ", i32 0, i32 " + strconv.Itoa(index))
+		return r
+	case exp.Var != nil && exp.Unary == "" && exp.Separator == "":
+		p, _ := a.varAddr(exp.Var)
+		return p
+	}
+	panic("编译器内部错误: 表达式不能被赋值")
+}
+
+// addr 返回表达式的地址，不是左值的表达式（如返回结构体的调用）先存入临时空间
+func (a *LLVM) addr(exp *parser.Expression) string {
+	if exp.Unary == "*" || exp.Index != nil || exp.Field != nil || exp.Var != nil && exp.Unary == "" && exp.Separator == "" && exp.Var.Value == nil {
+		return a.lvalue(exp)
+	}
+	v := a.value(exp, nil)
+	p := a.tmp()
+	a.emit(p + " = alloca " + typ(exp.Type))
+	a.emit("store " + typ(exp.Type) + " " + v + ", ptr " + p)
+	return p
+}
+
+// call 生成函数调用，方法以接收者地址作为第一个实参，接口方法通过虚表间接调用；返回调用结果，无返回值时为空
+func (a *LLVM) call(call *parser.CallBlock) string {
+	var args []string
+	callee := ident("@", funcName(call.Func))
+	if call.ThisVar != nil {
+		if iface, ok := call.ThisVar.Type.(*typeSys.InterfaceType); ok {
+			p, _ := a.varAddr(call.ThisVar)
+			v := a.load(iface, p)
+			obj, vt, slot, fn := a.tmp(), a.tmp(), a.tmp(), a.tmp()
+			a.emit(obj + " = extractvalue %cute.iface " + v + ", 0")
+			a.emit(vt + " = extractvalue %cute.iface " + v + ", 1")
+			a.emit(slot + " = getelementptr inbounds ptr, ptr " + vt + ", i32 " + strconv.Itoa(parser.MethodSlot(iface, call.Name.Last())))
+			a.emit(fn + " = load ptr, ptr " + slot)
+			args, callee = append(args, "ptr "+obj), fn
+		} else {
+			args = append(args, "ptr "+a.addr(&parser.Expression{Var: call.ThisVar}))
+		}
+	}
+	for _, arg := range call.Args {
+		if arg != nil {
+			args = append(args, typ(arg.Type)+" "+a.value(arg.Value, arg.Type))
+		}
+	}
+	inst := "call " + retType(call.Func) + " " + callee + "(" + strings.Join(args, ", ") + ")"
+	if len(call.Func.Return) == 0 {
+		a.emit(inst)
+		return ""
+	}
+	r := a.tmp()
+	a.emit(r + " = " + inst)
+	return r
+}
+
+// unary 一元运算：取地址、解引用、切片长度与类型转换
+func (a *LLVM) unary(exp *parser.Expression) string {
+	switch exp.Unary {
+	case "&":
+		return a.addr(exp.Right)
+	case "*":
+		return a.load(exp.Type, a.value(exp.Right, nil))
+	case "len":
+		r := a.tmp()
+		a.emit(r + " = extractvalue %cute.slice " + a.value(exp.Right, nil) + ", 1")
+		return r
+	case "as":
+		return a.cast(a.value(exp.Right, nil), exp.Right.Type, exp.Type)
+	}
+	panic("编译器内部错误: 未知的一元运算 " + exp.Unary)
+}
+
+// elemAddr 返回下标访问的元素地址，开启越界检查时下标越界调用 llvm.trap
+func (a *LLVM) elemAddr(exp *parser.Expression) string {
+	var base, length, elemType string
+	_, array := exp.Left.Type.(*typeSys.ArrayType)
+	switch t := exp.Left.Type.(type) {
+	case *typeSys.ArrayType:
+		base, length, elemType = a.addr(exp.Left), strconv.Itoa(t.Len), typ(t)
+	case *typeSys.SliceType:
+		s := a.value(exp.Left, nil)
+		base, length = a.tmp(), a.tmp()
+		a.emit(base + " = extractvalue %cute.slice " + s + ", 0")
+		a.emit(length + " = extractvalue %cute.slice " + s + ", 1")
+		elemType = typ(t.Elem)
+	default:
+		panic("编译器内部错误: 不能对 " + exp.Left.Type.Type() + " 使用下标")
+	}
+	idx := a.value(exp.Index, word)
+	// 数组的常量下标在解析时已经检查过
+	if a.ctx.BoundsCheck && !(array && exp.Index.IsConst()) {
+		c := a.tmp()
+		a.emit(c + " = icmp uge " + typ(word) + " " + idx + ", " + length)
+		label := a.newLabel("bounds")
+		fail, ok := label+".fail", label+".ok"
+		a.term("br i1 " + c + ", label %" + fail + ", label %" + ok)
+		a.label(fail)
+		a.emit("call void @llvm.trap()")
+		a.term("unreachable")
+		a.label(ok)
+	}
+	r := a.tmp()
+	if array {
+		a.emit(r + " = getelementptr inbounds " + elemType + ", ptr " + base + ", " + typ(word) + " 0, " + typ(word) + " " + idx)
+	} else {
+		a.emit(r + " = getelementptr inbounds " + elemType + ", ptr " + base + ", " + typ(word) + " " + idx)
+	}
+	return r
+}
+
+// constant 返回以 want 类型表示的常量
+func (a *LLVM) constant(exp *parser.Expression, want typeSys.Type) string {
+	switch {
+	case exp.Type == nil || typeSys.GetTypeType(exp.Type) == "bool":
+		// 折叠后的比较结果没有类型
+		return strconv.FormatBool(exp.Bool)
+	case typeSys.GetTypeType(exp.Type) == "string":
+		return ident("@", a.ctx.Data.Intern(exp.StringVal))
+	}
+	switch lt := typ(want); {
+	case lt == "ptr":
+		if exp.Num == 0 {
+			return "null"
+		}
+		return "inttoptr (" + typ(word) + " " + strconv.FormatInt(int64(exp.Num), 10) + " to ptr)"
+	case isFloat(lt):
+		return float(exp.Num, lt)
+	case exp.Num >= math.MaxInt64:
+		return strconv.FormatUint(uint64(exp.Num), 10)
+	}
+	return strconv.FormatInt(int64(exp.Num), 10)
+}
+
+// field 返回结构体字段及其在 LLVM 结构体类型中的下标
+func (a *LLVM) field(t typeSys.Type, name string) (int, *typeSys.StructField) {
+	structType, ok := t.(*typeSys.StructType)
+	if !ok {
+		panic("编译器内部错误: " + t.Type() + " 不是结构体")
+	}
+	for i, f := range structType.StructFields {
+		if f.Name == name {
+			return i, f
+		}
+	}
+	panic("编译器内部错误: 结构体 " + structType.Type() + " 没有字段 " + name)
+}
+
+// vtableName 返回结构体实现接口时的虚表名，与汇编后端的虚表标签一致
+func vtableName(structType *typeSys.StructType, iface *typeSys.InterfaceType) string {
+	return utils.ToNASMName("vtable_" + structType.Type() + "_" + iface.Type())
+}
+
+// vtable 登记结构体实现接口时的虚表并返回其全局名，虚表由 Data 输出
+func (a *LLVM) vtable(structType *typeSys.StructType, iface *typeSys.InterfaceType) string {
+	label := vtableName(structType, iface)
+	a.ctx.AddVTable(label, structType, iface)
+	return ident("@", label)
+}
@@ -0,0 +1,373 @@
+// Package llvm 将 AST 转换为 LLVM IR 文本（.ll），交给 llvm-as、llc 或 clang 生成目标代码。
+//
+// 整数类型对应 iN，结构体对应命名结构体类型，切片与接口分别是 (数据指针, 长度) 与 (数据指针, 虚表)。
+// 参数与局部变量都在入口块中 alloca，通过 load/store 访问，由 mem2reg 提升为 SSA 值；
+// build ext 声明的函数输出为 declare，build link 输出为指向函数的 alias。
+// 用户的 main 改名为 cute.main，由生成的 i32 @main 调用并以其返回值作为退出码。
+package llvm
+
+import (
+	"cuteify/compile/arch"
+	"cuteify/compile/context"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"strconv"
+	"strings"
+)
+
+// LLVM 生成 LLVM IR 的后端，同时实现 arch.Arch 与 arch.Syntax
+type LLVM struct {
+	ctx *context.Context
+
+	buf    strings.Builder // 尚未返回给编译器的指令
+	block  string          // 当前基本块
+	done   bool            // 当前基本块是否已经以终结指令结束
+	temps  int             // 当前函数的临时值编号
+	labels int             // 当前函数中短路求值、越界检查等生成的基本块编号
+
+	slots map[any]string  // 当前函数中变量定义（*VarBlock 或 *ArgBlock）对应的 alloca
+	used  map[string]bool // 当前函数中已使用的局部名称
+	ext   bool            // 当前函数是否为 build ext 声明的外部函数（不生成函数体）
+
+	main    bool         // 程序是否定义了 main
+	mainRet typeSys.Type // main 的返回值类型，没有返回值时为 nil
+}
+
+// NewLLVM 创建 LLVM 后端，寄存器由 LLVM 分配，不需要寄存器管理器
+func NewLLVM(ctx *context.Context) *LLVM {
+	return &LLVM{ctx: ctx}
+}
+
+func (a *LLVM) Info() string { return "llvm" }
+
+// emit 在当前基本块中追加一条指令，当前基本块已经结束时（如 return 之后的语句）先开始一个不可达的基本块
+func (a *LLVM) emit(inst string) {
+	if a.done {
+		a.label(a.newLabel("dead"))
+	}
+	a.buf.WriteString("  " + inst + "\n")
+}
+
+// term 追加结束当前基本块的指令
+func (a *LLVM) term(inst string) {
+	a.emit(inst)
+	a.done = true
+}
+
+// jump 当前基本块还没有结束时跳转到 label
+func (a *LLVM) jump(label string) {
+	if !a.done {
+		a.term("br label %" + label)
+	}
+}
+
+// label 开始新的基本块，上一个基本块没有结束时顺序执行到这里
+func (a *LLVM) label(name string) {
+	a.jump(name)
+	a.buf.WriteString(name + ":\n")
+	a.block, a.done = name, false
+}
+
+// newLabel 返回当前函数中唯一的基本块名前缀 kind.N，同一结构的各基本块在其后加 .part，与 for.N.body 等一致
+func (a *LLVM) newLabel(kind string) string {
+	a.labels++
+	return kind + "." + strconv.Itoa(a.labels)
+}
+
+// tmp 返回新的临时值名
+func (a *LLVM) tmp() string {
+	a.temps++
+	return "%." + strconv.Itoa(a.temps)
+}
+
+// flush 返回已生成的指令
+func (a *LLVM) flush() string {
+	code := a.buf.String()
+	a.buf.Reset()
+	return code
+}
+
+func (a *LLVM) Call(call *parser.CallBlock) string {
+	if call == nil || call.Func == nil {
+		return ""
+	}
+	a.call(call)
+	return a.flush()
+}
+
+// Return 没有 return 语句的函数末尾：无返回值时补齐 ret void，有返回值时不会执行到这里
+func (a *LLVM) Return(ret *parser.ReturnBlock) string {
+	funcBlock := a.ctx.CurrentFunc
+	if a.ext || funcBlock == nil {
+		return ""
+	}
+	switch {
+	case ret != nil && len(ret.Value) > 0:
+		t := funcBlock.Return[0]
+		a.term("ret " + typ(t) + " " + a.value(ret.Value[0], t))
+	case len(funcBlock.Return) > 0:
+		if ret != nil || !a.done {
+			a.term("unreachable")
+		}
+	default:
+		if ret != nil || !a.done {
+			a.term("ret void")
+		}
+	}
+	return a.flush()
+}
+
+// Func 输出函数头，在入口块中为参数和局部变量分配空间，参数存入自己的 alloca，局部变量的初值在定义处写入
+func (a *LLVM) Func(funcBlock *parser.FuncBlock) string {
+	a.slots = map[any]string{}
+	a.used = map[string]bool{"self": true}
+	a.temps, a.labels = 0, 0
+	a.ext = arch.ExtName(funcBlock) != ""
+	if a.ext {
+		return ""
+	}
+	var params []string
+	if _, ok := funcBlock.Class.(*typeSys.StructType); ok {
+		params = append(params, "ptr %self")
+	}
+	for _, arg := range funcBlock.Args {
+		params = append(params, typ(arg.Type)+" "+ident("%", arg.Name.String()+".arg"))
+	}
+	a.buf.WriteString("define " + retType(funcBlock) + " " + ident("@", funcName(funcBlock)) + "(" + strings.Join(params, ", ") + ") {\n")
+	a.buf.WriteString("entry:\n")
+	a.block, a.done = "entry", false
+
+	for _, arg := range funcBlock.Args {
+		slot := a.alloca(arg, arg.Name, arg.Type)
+		a.emit("store " + typ(arg.Type) + " " + ident("%", arg.Name.String()+".arg") + ", ptr " + slot)
+	}
+	a.declare(a.ctx.Now)
+	return a.flush()
+}
+
+// alloca 为变量定义分配当前函数中唯一的名称和栈空间，不同作用域中的同名变量依次加数字后缀
+func (a *LLVM) alloca(def any, name parser.Name, t typeSys.Type) string {
+	if s, ok := a.slots[def]; ok {
+		return s
+	}
+	s := name.String()
+	for k := 1; a.used[s]; k++ {
+		s = name.String() + "." + strconv.Itoa(k)
+	}
+	a.used[s] = true
+	slot := ident("%", s)
+	a.slots[def] = slot
+	a.emit(slot + " = alloca " + typ(t))
+	return slot
+}
+
+// declare 遍历函数体，为每个局部变量分配栈空间
+func (a *LLVM) declare(node *parser.Node) {
+	local := func(v *parser.VarBlock) {
+		a.alloca(v, v.Name, v.Type)
+	}
+	for _, child := range node.Children {
+		if child.Ignore {
+			continue
+		}
+		switch v := child.Value.(type) {
+		case *parser.VarBlock:
+			if v.IsDefine && !v.IsGlobal {
+				local(v)
+			}
+		case *parser.ForBlock:
+			if v.Init != nil && v.Init.Var != nil {
+				local(v.Init.Var)
+			}
+			a.declare(child)
+		case *parser.WhileBlock, *parser.SwitchBlock, *parser.CaseBlock:
+			a.declare(child)
+		case *parser.IfBlock:
+			a.declare(child)
+			if v.Else {
+				a.declare(v.ElseBlock)
+			}
+		}
+	}
+}
+
+// Exp 输出表达式语句，条件由 If、For 等直接生成，不使用 result
+func (a *LLVM) Exp(exp *parser.Expression, result, desc string) string {
+	a.stmt(exp)
+	return a.flush()
+}
+
+func (a *LLVM) Var(varBlock *parser.VarBlock) string {
+	if varBlock.Value == nil {
+		return a.InitVar(varBlock)
+	}
+	a.assign(varBlock)
+	return a.flush()
+}
+
+// InitVar 没有初始值的变量用一次 store 写入零值，结构体的初值包括字段默认值
+func (a *LLVM) InitVar(varBlock *parser.VarBlock) string {
+	p, t := a.varAddr(varBlock)
+	a.emit("store " + typ(t) + " " + a.initializer(t, nil) + ", ptr " + p)
+	return a.flush()
+}
+
+// branch 条件成立时跳转到 then，否则跳转到 els
+func (a *LLVM) branch(cond *parser.Expression, then, els string) {
+	if cond == nil || cond.IsConst() && cond.Bool {
+		a.term("br label %" + then)
+		return
+	}
+	a.term("br i1 " + a.value(cond, nil) + ", label %" + then + ", label %" + els)
+}
+
+// For 条件检查、循环体、增量部分与结束位置各为一个基本块，continue 跳转到增量部分
+func (a *LLVM) For(forBlock *parser.ForBlock) string {
+	a.ctx.ForCount++
+	forBlock.Offset = a.ctx.ForCount
+	label := "for." + strconv.Itoa(forBlock.Offset)
+	a.ctx.PushLoop(label+".inc", label+".end")
+
+	if forBlock.Init != nil {
+		a.stmt(forBlock.Init)
+	}
+	a.label(label + ".cond")
+	a.branch(forBlock.Condition, label+".body", label+".end")
+	a.label(label + ".body")
+	return a.flush()
+}
+
+func (a *LLVM) EndFor(forBlock *parser.ForBlock) string {
+	label := "for." + strconv.Itoa(forBlock.Offset)
+	a.label(label + ".inc")
+	if forBlock.Increment != nil {
+		a.stmt(forBlock.Increment)
+	}
+	a.jump(label + ".cond")
+	a.label(label + ".end")
+	a.ctx.PopLoop()
+	return a.flush()
+}
+
+func (a *LLVM) While(whileBlock *parser.WhileBlock) string {
+	a.ctx.WhileCount++
+	whileBlock.Offset = a.ctx.WhileCount
+	label := "while." + strconv.Itoa(whileBlock.Offset)
+	a.ctx.PushLoop(label+".cond", label+".end")
+
+	a.label(label + ".cond")
+	a.branch(whileBlock.Condition, label+".body", label+".end")
+	a.label(label + ".body")
+	return a.flush()
+}
+
+func (a *LLVM) EndWhile(whileBlock *parser.WhileBlock) string {
+	label := "while." + strconv.Itoa(whileBlock.Offset)
+	a.jump(label + ".cond")
+	a.label(label + ".end")
+	a.ctx.PopLoop()
+	return a.flush()
+}
+
+func (a *LLVM) Break(breakBlock *parser.BreakBlock) string {
+	loop, ok := a.ctx.CurrentLoop()
+	if !ok {
+		return ""
+	}
+	a.term("br label %" + loop.End)
+	return a.flush()
+}
+
+func (a *LLVM) Continue(continueBlock *parser.ContinueBlock) string {
+	loop, ok := a.ctx.CurrentLoop()
+	if !ok || loop.Continue == "" {
+		return ""
+	}
+	a.term("br label %" + loop.Continue)
+	return a.flush()
+}
+
+// Switch 使用 LLVM 的 switch 指令，每个分支是一个基本块，没有 default 时不匹配的值跳转到结束位置
+func (a *LLVM) Switch(switchBlock *parser.SwitchBlock) string {
+	a.ctx.SwitchCount++
+	switchBlock.Offset = a.ctx.SwitchCount
+	label := switchLabel(switchBlock)
+	// switch 中的 continue 仍作用于外层循环
+	cont := ""
+	if loop, ok := a.ctx.CurrentLoop(); ok {
+		cont = loop.Continue
+	}
+	a.ctx.PushLoop(cont, label+".end")
+
+	t := switchBlock.Value.Type
+	v := a.value(switchBlock.Value, nil)
+	def := label + ".end"
+	var cases []string
+	for _, c := range switchBlock.Cases {
+		if c.IsDefault {
+			def = caseLabel(switchBlock, c)
+			continue
+		}
+		for _, value := range c.Values {
+			cases = append(cases, typ(t)+" "+a.constant(value, t)+", label %"+caseLabel(switchBlock, c))
+		}
+	}
+	a.term("switch " + typ(t) + " " + v + ", label %" + def + " [" + strings.Join(cases, " ") + "]")
+	return a.flush()
+}
+
+func (a *LLVM) Case(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) string {
+	a.label(caseLabel(switchBlock, caseBlock))
+	return a.flush()
+}
+
+func (a *LLVM) EndCase(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) string {
+	a.jump(switchLabel(switchBlock) + ".end")
+	return a.flush()
+}
+
+func (a *LLVM) EndSwitch(switchBlock *parser.SwitchBlock) string {
+	a.label(switchLabel(switchBlock) + ".end")
+	a.ctx.PopLoop()
+	return a.flush()
+}
+
+// switchLabel 返回 switch 的基本块名前缀
+func switchLabel(switchBlock *parser.SwitchBlock) string {
+	return "switch." + strconv.Itoa(switchBlock.Offset)
+}
+
+// caseLabel 返回 case 分支的基本块名
+func caseLabel(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) string {
+	return switchLabel(switchBlock) + ".case." + strconv.Itoa(caseBlock.Offset)
+}
+
+// GenVarAddr 返回计算变量地址的指令
+func (a *LLVM) GenVarAddr(v *parser.VarBlock) string {
+	a.varAddr(v)
+	return a.flush()
+}
+
+// funcName 返回函数在模块中的名称：外部函数使用其外部名，main 改名为 cute.main，其余与汇编后端的标签一致
+func funcName(funcBlock *parser.FuncBlock) string {
+	if ext := arch.ExtName(funcBlock); ext != "" {
+		return ext
+	}
+	name := funcBlock.Name.String()
+	if name == "main" {
+		return "cute.main"
+	}
+	return name + strconv.Itoa(len(funcBlock.Args))
+}
+
+// funcType 返回函数的 LLVM 函数类型，方法以接收者指针作为第一个参数
+func funcType(funcBlock *parser.FuncBlock) string {
+	var params []string
+	if _, ok := funcBlock.Class.(*typeSys.StructType); ok {
+		params = append(params, "ptr")
+	}
+	for _, arg := range funcBlock.Args {
+		params = append(params, typ(arg.Type))
+	}
+	return retType(funcBlock) + " (" + strings.Join(params, ", ") + ")"
+}
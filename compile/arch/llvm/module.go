@@ -0,0 +1,200 @@
+package llvm
+
+import (
+	"cuteify/compile/arch"
+	"cuteify/compile/data"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"strconv"
+	"strings"
+)
+
+// runtime 所有生成的模块共用的类型与内建函数：切片为 (数据指针, 长度)，接口为 (数据指针, 虚表)
+const runtime = `%cute.slice = type { ptr, i64 }
+%cute.iface = type { ptr, ptr }
+
+declare void @llvm.trap()
+`
+
+// Header 输出结构体类型、外部函数声明与全局变量，LLVM 的全局名不要求先定义后使用
+func (a *LLVM) Header(root *parser.Node) string {
+	var b strings.Builder
+	b.WriteString("; 由 cuteify 生成的 LLVM IR\n\n")
+	b.WriteString(strings.Replace(runtime, "i64", typ(word), 1) + "\n")
+
+	structs, _, funcs, globals := arch.Collect(root)
+	for _, st := range structs {
+		var fields []string
+		for _, field := range st.StructFields {
+			fields = append(fields, typ(field.Type))
+		}
+		b.WriteString(structName(st) + " = type { " + strings.Join(fields, ", ") + " }\n")
+	}
+	if len(structs) > 0 {
+		b.WriteString("\n")
+	}
+
+	for _, funcBlock := range funcs {
+		if funcBlock.Name.String() == "main" {
+			a.main = true
+			if len(funcBlock.Return) > 0 {
+				a.mainRet = funcBlock.Return[0]
+			}
+		}
+		if arch.ExtName(funcBlock) == "" {
+			continue
+		}
+		ft := funcType(funcBlock)
+		ret, params, _ := strings.Cut(ft, " (")
+		b.WriteString("declare " + ret + " " + ident("@", funcName(funcBlock)) + "(" + params + "\n")
+	}
+
+	for _, v := range globals {
+		b.WriteString(ident("@", data.GlobalLabel(v.Name)) + " = global " + typ(v.Type) + " " + a.initializer(v.Type, v.Value) + "\n")
+	}
+	if len(globals) > 0 {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// initializer 返回全局变量的常量初始值，没有初始值的结构体使用字段的默认值
+func (a *LLVM) initializer(t typeSys.Type, value *parser.Expression) string {
+	if value != nil {
+		return a.constant(value, t)
+	}
+	st, ok := t.(*typeSys.StructType)
+	if !ok || t.IsPointer() {
+		return zero(t)
+	}
+	var items []string
+	defaults := false
+	for _, field := range st.StructFields {
+		def, _ := field.Default.(*parser.Expression)
+		v := a.initializer(field.Type, def)
+		defaults = defaults || v != zero(field.Type)
+		items = append(items, typ(field.Type)+" "+v)
+	}
+	if !defaults {
+		return "zeroinitializer"
+	}
+	return "{ " + strings.Join(items, ", ") + " }"
+}
+
+// FuncLabel 函数头由 Func 输出，这里输出 build link 对应的别名
+func (a *LLVM) FuncLabel(funcBlock *parser.FuncBlock, label string, links []string) (code string) {
+	if arch.ExtName(funcBlock) != "" {
+		return ""
+	}
+	for _, link := range links {
+		code += ident("@", link) + " = alias " + funcType(funcBlock) + ", ptr " + ident("@", funcName(funcBlock)) + "\n"
+	}
+	return code
+}
+
+func (a *LLVM) EndFunc(funcBlock *parser.FuncBlock) string {
+	if a.ext {
+		return ""
+	}
+	return a.flush() + "}\n\n"
+}
+
+// If 条件成立时进入 then 块，否则跳转到 else 块或 if 结束位置
+func (a *LLVM) If(ifBlock *parser.IfBlock, label string) string {
+	label = ifLabel(label)
+	els := label + ".end"
+	if ifBlock.Else {
+		els = label + ".else"
+	}
+	a.branch(ifBlock.Condition, label+".then", els)
+	a.label(label + ".then")
+	return a.flush()
+}
+
+// Else then 块执行完毕后跳过 else 分支；else if 的条件不成立时跳转到结束位置
+func (a *LLVM) Else(ifBlock *parser.IfBlock, label string) string {
+	label = ifLabel(label)
+	a.jump(label + ".end")
+	a.label(label + ".else")
+	if cond := ifBlock.ElseBlock.Value.(*parser.ElseBlock).IfCondition; cond != nil {
+		a.branch(cond, label+".elif", label+".end")
+		a.label(label + ".elif")
+	}
+	return a.flush()
+}
+
+func (a *LLVM) EndIf(ifBlock *parser.IfBlock, label string) string {
+	label = ifLabel(label)
+	a.label(label + ".end")
+	return a.flush()
+}
+
+// ifLabel 将编译器分配的 if_N 标签转换为与 for.N、switch.N 一致的基本块名前缀 if.N
+func ifLabel(label string) string {
+	return "if." + strings.TrimPrefix(label, "if_")
+}
+
+// InlineAsm 内联汇编依赖 x86 栈帧布局，无法转换为 LLVM IR，执行到这里时陷入
+func (a *LLVM) InlineAsm(build *parser.Build) string {
+	a.buf.WriteString("  ; build asm 不能转换为 LLVM IR\n")
+	a.emit("call void @llvm.trap()")
+	return a.flush()
+}
+
+// StartEntry 定义 C 运行时调用的 i32 @main，以 cute.main 的返回值作为退出码
+func (a *LLVM) StartEntry() string {
+	if !a.main {
+		return ""
+	}
+	a.buf.WriteString("define i32 @main() {\nentry:\n")
+	a.block, a.done = "entry", false
+	if a.mainRet == nil {
+		a.emit("call void @cute.main()")
+		a.term("ret i32 0")
+		return a.flush() + "}\n"
+	}
+	r := a.tmp()
+	a.emit(r + " = call " + typ(a.mainRet) + " @cute.main()")
+	a.term("ret i32 " + a.cast(r, a.mainRet, typeSys.GetSystemType("i32")))
+	return a.flush() + "}\n"
+}
+
+// Data 输出字符串字面量与用到的虚表，没有生成代码的方法在虚表中为空指针
+func (a *LLVM) Data() string {
+	var b strings.Builder
+	if len(a.ctx.Data.Strings) > 0 || len(a.ctx.VTables) > 0 {
+		b.WriteString("\n")
+	}
+	for _, s := range a.ctx.Data.Strings {
+		b.WriteString(ident("@", s.Label) + " = private unnamed_addr constant [" + strconv.Itoa(len(s.Value)+1) + " x i8] " + llString(s.Value) + "\n")
+	}
+	for _, vt := range a.ctx.VTables {
+		var methods []string
+		for _, m := range vt.Iface.Methods {
+			method := parser.FindMethod(vt.Struct, m.(*parser.FuncBlock).Name.Last())
+			if method == nil || !method.Useful {
+				methods = append(methods, "ptr null")
+				continue
+			}
+			methods = append(methods, "ptr "+ident("@", funcName(method)))
+		}
+		b.WriteString(ident("@", vt.Label) + " = internal constant [" + strconv.Itoa(len(methods)) + " x ptr] [" + strings.Join(methods, ", ") + "]\n")
+	}
+	return b.String()
+}
+
+// llString 返回以 0 结尾的 LLVM 字符数组常量，不可打印字符使用 \XX 转义
+func llString(s string) string {
+	var b strings.Builder
+	b.WriteString(`c"`)
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch == '"' || ch == '\\' || ch < 0x20 || ch >= 0x7f:
+			b.WriteString(`\` + strings.ToUpper(strconv.FormatInt(int64(ch)|0x100, 16)[1:]))
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	b.WriteString(`\00"`)
+	return b.String()
+}
@@ -0,0 +1,151 @@
+package llvm
+
+import (
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// word int/uint 的类型，用于下标与指针偏移
+var word = typeSys.GetSystemType("int")
+
+// ident 返回 LLVM 标识符，名称中含有字母、数字、. 和 _ 以外的字符时加引号
+func ident(sigil, name string) string {
+	for i, ch := range name {
+		ok := ch == '.' || ch == '_' || ch == '$' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || i > 0 && ch >= '0' && ch <= '9'
+		if !ok {
+			return sigil + strconv.Quote(name)
+		}
+	}
+	return sigil + name
+}
+
+// structName 返回结构体对应的 LLVM 命名结构体类型
+func structName(t *typeSys.StructType) string {
+	return ident("%", "struct."+t.Type())
+}
+
+// typ 返回 cute 类型对应的 LLVM 类型：整数为 iN，结构体为命名结构体类型，切片与接口为 %cute.slice 与 %cute.iface
+func typ(t typeSys.Type) string {
+	if t == nil {
+		return "i1"
+	}
+	if t.IsPointer() {
+		return "ptr"
+	}
+	switch t := t.(type) {
+	case *typeSys.StructType:
+		return structName(t)
+	case *typeSys.ArrayType:
+		return "[" + strconv.Itoa(t.Len) + " x " + typ(t.Elem) + "]"
+	case *typeSys.SliceType:
+		return "%cute.slice"
+	case *typeSys.InterfaceType:
+		return "%cute.iface"
+	}
+	if bits, _, ok := typeSys.IntInfo(t); ok {
+		return "i" + strconv.Itoa(bits)
+	}
+	switch typeSys.GetTypeType(t) {
+	case "float":
+		if t.Size() == 4 {
+			return "float"
+		}
+		return "double"
+	case "bool":
+		return "i1"
+	case "string":
+		return "ptr"
+	}
+	panic("编译器内部错误: 无法转换为 LLVM 类型 " + t.Type())
+}
+
+// retType 返回函数的 LLVM 返回类型
+func retType(funcBlock *parser.FuncBlock) string {
+	if len(funcBlock.Return) == 0 {
+		return "void"
+	}
+	return typ(funcBlock.Return[0])
+}
+
+// zero 返回类型的零值
+func zero(t typeSys.Type) string {
+	switch lt := typ(t); {
+	case lt == "ptr":
+		return "null"
+	case lt == "i1":
+		return "false"
+	case lt == "float" || lt == "double":
+		return "0.0"
+	case strings.HasPrefix(lt, "i"):
+		return "0"
+	}
+	return "zeroinitializer"
+}
+
+// signed 报告整数类型是否有符号
+func signed(t typeSys.Type) bool {
+	_, s, ok := typeSys.IntInfo(t)
+	return ok && s && !t.IsPointer()
+}
+
+// isFloat 报告 LLVM 类型是否为浮点数
+func isFloat(lt string) bool {
+	return lt == "float" || lt == "double"
+}
+
+// bits 返回 LLVM 整数类型的位宽
+func bits(lt string) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(lt, "i"))
+	return n
+}
+
+// float 返回浮点常量，LLVM 要求十进制常量能被精确表示，因此统一使用十六进制的 double 位模式
+func float(v float64, lt string) string {
+	if lt == "float" {
+		v = float64(float32(v))
+	}
+	return fmt.Sprintf("0x%016X", math.Float64bits(v))
+}
+
+// cast 将 from 类型的值 v 转换为 to 类型，类型相同时原样返回
+func (a *LLVM) cast(v string, from, to typeSys.Type) string {
+	f, t := typ(from), typ(to)
+	if f == t {
+		return v
+	}
+	var op string
+	switch {
+	case f == "ptr":
+		op = "ptrtoint"
+	case t == "ptr":
+		op = "inttoptr"
+	case isFloat(f) && isFloat(t):
+		op = "fptrunc"
+		if t == "double" {
+			op = "fpext"
+		}
+	case isFloat(f):
+		op = "fptoui"
+		if signed(to) {
+			op = "fptosi"
+		}
+	case isFloat(t):
+		op = "uitofp"
+		if signed(from) {
+			op = "sitofp"
+		}
+	case bits(f) > bits(t):
+		op = "trunc"
+	case signed(from):
+		op = "sext"
+	default:
+		op = "zext"
+	}
+	r := a.tmp()
+	a.emit(r + " = " + op + " " + f + " " + v + " to " + t)
+	return r
+}
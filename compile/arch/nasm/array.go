@@ -0,0 +1,163 @@
+package nasm
+
+import (
+	"cuteify/compile/regmgr"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strconv"
+)
+
+// 切片值为两个字：[+0] 数据地址，[+字长] 长度
+
+// BoundsPanicLabel 下标越界时调用的运行时例程
+const BoundsPanicLabel = "cute_panic_index"
+
+// Index 编译下标读取 a[i]：先算出元素地址，再按元素宽度载入
+// 地址寄存器记录在被索引的表达式名下，exp 自身可能已被强制分配了结果寄存器（如 ret a[i]）
+func (g *Gen) Index(exp *parser.Expression) (code string, reg *regmgr.Reg) {
+	code, reg = g.indexAddr(exp, exp.Left)
+	code += utils.Format(g.Load(reg.Name, exp.Type, DerefAddr(exp, reg.Name)) + "; 读取元素")
+	return code, reg
+}
+
+// Field 编译下标结果的字段读取 vs[i].x：先算出字段地址，再按字段宽度载入
+func (g *Gen) Field(exp *parser.Expression) (code string, reg *regmgr.Reg) {
+	code, reg = g.fieldAddr(exp, exp.Left)
+	code += utils.Format(g.Load(reg.Name, exp.Type, DerefAddr(exp, reg.Name)) + "; 读取字段")
+	return code, reg
+}
+
+// fieldAddr 计算 vs[i].x 的字段地址：结构体地址加上字段偏移
+func (g *Gen) fieldAddr(exp, owner *parser.Expression) (code string, reg *regmgr.Reg) {
+	code, reg = g.addr(exp.Left, owner)
+	if offset := exp.StructField().Offset; offset != 0 {
+		code += utils.Format("lea " + reg.Name + ", " + RefAdd("["+reg.Name+"]", offset) + "; 字段地址")
+	}
+	return code, reg
+}
+
+// indexAddr 计算 a[i] 的元素地址
+// 下标可能含有函数调用，因此先于数组地址计算
+func (g *Gen) indexAddr(exp, owner *parser.Expression) (code string, reg *regmgr.Reg) {
+	index := exp.Index
+	var idxReg *regmgr.Reg
+	if !index.IsConst() {
+		code, idxReg = g.Value(index)
+	}
+
+	baseCode, reg := g.addr(exp.Left, owner)
+	code += baseCode
+
+	switch t := exp.Left.Type.(type) {
+	case *typeSys.ArrayType:
+		if idxReg != nil {
+			code += g.boundsCheck(idxReg.Name, strconv.Itoa(t.Len), "jb")
+		}
+	case *typeSys.SliceType:
+		length := g.ptr() + RefAdd("["+reg.Name+"]", g.Word)
+		if idxReg != nil {
+			code += g.boundsCheck(idxReg.Name, length, "jb")
+		} else {
+			code += g.boundsCheck(length, strconv.Itoa(int(index.Num)), "ja")
+		}
+		code += utils.Format("mov " + reg.Name + ", " + g.ptr() + "[" + reg.Name + "]; 切片数据地址")
+	}
+
+	size := exp.Type.Size()
+	switch {
+	case idxReg == nil:
+		if offset := int(index.Num) * size; offset != 0 {
+			code += utils.Format("lea " + reg.Name + ", " + RefAdd("["+reg.Name+"]", offset) + "; 元素地址")
+		}
+	case size == 1 || size == 2 || size == 4 || size == 8:
+		code += utils.Format("lea " + reg.Name + ", [" + reg.Name + "+" + idxReg.Name + "*" + strconv.Itoa(size) + "]; 元素地址")
+	default:
+		code += utils.Format("imul " + idxReg.Name + ", " + idxReg.Name + ", " + strconv.Itoa(size) + "; 下标乘以元素大小")
+		code += utils.Format("add " + reg.Name + ", " + idxReg.Name + "; 元素地址")
+	}
+	g.Ctx.Reg.Free(index)
+	return code, reg
+}
+
+// boundsCheck 开启越界检查时比较 left 与 right，不满足 jcc 条件时调用越界 panic 例程
+// 下标按无符号数比较，负数下标同样视为越界
+func (g *Gen) boundsCheck(left, right, jcc string) (code string) {
+	if !g.Ctx.BoundsCheck {
+		return ""
+	}
+	g.Ctx.BoundsPanic = true
+	label := "bounds_ok_" + strconv.Itoa(g.Ctx.BoundsCount)
+	g.Ctx.BoundsCount++
+	code += utils.Format("cmp " + left + ", " + right + "; 越界检查")
+	code += utils.Format(jcc + " " + label)
+	code += utils.Format("call " + BoundsPanicLabel)
+	code += utils.Format(label + ":")
+	return code
+}
+
+// length 编译 len(s)，切片长度位于切片值的第二个字（数组长度已在检查阶段折叠为常量）
+func (g *Gen) length(exp *parser.Expression) (code string, reg *regmgr.Reg) {
+	code, reg = g.addr(exp.Right, exp)
+	code += utils.Format("mov " + reg.Name + ", " + g.ptr() + RefAdd("["+reg.Name+"]", g.Word) + "; 切片长度")
+	return code, reg
+}
+
+// SliceVar 将数组变量或切片变量赋值给切片变量
+func (g *Gen) SliceVar(varBlock *parser.VarBlock) (code string) {
+	src := varBlock.Value.Var
+	if src == nil {
+		panic("编译器内部错误: 切片只能由变量赋值")
+	}
+	dst := g.VarRef(varBlock)
+	srcRef := g.VarRef(src)
+	ptr := g.ptr()
+
+	accReg := &regmgr.Reg{Name: g.Acc, RegIndex: 0}
+	g.Ctx.Reg.Force(accReg, g.Ctx.Now, varBlock.Value)
+	if accReg.StoreCode != "" {
+		code += utils.Format(accReg.StoreCode)
+	}
+	switch t := src.Type.(type) {
+	case *typeSys.ArrayType:
+		code += utils.Format("lea " + g.Acc + ", " + srcRef + "; 取" + src.Name.String() + "地址")
+		code += utils.Format("mov " + ptr + dst + ", " + g.Acc + "; 切片数据地址")
+		code += utils.Format("mov " + ptr + RefAdd(dst, g.Word) + ", " + strconv.Itoa(t.Len) + "; 切片长度")
+	case *typeSys.SliceType:
+		code += utils.Format("mov " + g.Acc + ", " + ptr + srcRef + "; 复制切片数据地址")
+		code += utils.Format("mov " + ptr + dst + ", " + g.Acc)
+		code += utils.Format("mov " + g.Acc + ", " + ptr + RefAdd(srcRef, g.Word) + "; 复制切片长度")
+		code += utils.Format("mov " + ptr + RefAdd(dst, g.Word) + ", " + g.Acc)
+	}
+	g.Ctx.Reg.Free(varBlock.Value)
+	return code
+}
+
+// PushSlice 以切片值的形式压入参数，长度先压栈，使数据地址位于低地址
+func (g *Gen) PushSlice(value *parser.Expression, desc string) (code string) {
+	src := value.Var
+	if src == nil {
+		panic("编译器内部错误: 切片只能由变量传参")
+	}
+	srcRef := g.VarRef(src)
+	switch t := src.Type.(type) {
+	case *typeSys.ArrayType:
+		code += utils.Format("push " + strconv.Itoa(t.Len) + "; " + desc + "长度")
+		code += utils.Format("lea " + g.Acc + ", " + srcRef + "; 取" + src.Name.String() + "地址")
+		code += utils.Format("push " + g.Acc + "; " + desc + "数据地址")
+	case *typeSys.SliceType:
+		code += utils.Format("push " + g.ptr() + RefAdd(srcRef, g.Word) + "; " + desc + "长度")
+		code += utils.Format("push " + g.ptr() + srcRef + "; " + desc + "数据地址")
+	}
+	return code
+}
+
+// boundsPanic 输出越界 panic 例程：向 stderr 输出提示并以退出码 2 结束进程，只在用到时输出
+func (g *Gen) boundsPanic() (code string) {
+	if !g.Ctx.BoundsPanic {
+		return ""
+	}
+	msg := "panic: index out of range\n"
+	code += utils.Format(BoundsPanicLabel + ":")
+	return code + g.Exit(g.Ctx.Data.Intern(msg), len(msg))
+}
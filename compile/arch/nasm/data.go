@@ -0,0 +1,110 @@
+package nasm
+
+import (
+	"cuteify/compile/data"
+	"cuteify/utils"
+	"strconv"
+)
+
+// Data 输出代码之后的内容：运行时例程，以及数据段 .rodata（虚表、字符串字面量）、.data（有初始值的全局变量）、.bss（零初始化的全局变量）
+func (g *Gen) Data() (code string) {
+	// 运行时例程仍位于代码段，且可能引用字符串字面量，需先于 .rodata 输出
+	code += g.boundsPanic()
+
+	if rodata := g.vtables() + genStrings(g.Ctx.Data); rodata != "" {
+		code += utils.Format("section .rodata")
+		code += rodata
+	}
+
+	var initialized, zeroed string
+	for _, gl := range g.Ctx.Data.Globals {
+		if gl.IsZero() {
+			zeroed += utils.Format("alignb " + strconv.Itoa(gl.Align()))
+			zeroed += utils.Format(gl.Label + ": resb " + strconv.Itoa(gl.Size()))
+			continue
+		}
+		initialized += utils.Format("align " + strconv.Itoa(gl.Align()))
+		initialized += utils.Format(gl.Label + ":")
+		initialized += genGlobalItems(gl)
+	}
+	if initialized != "" {
+		code += utils.Format("section .data")
+		code += initialized
+	}
+	if zeroed != "" {
+		code += utils.Format("section .bss")
+		code += zeroed
+	}
+	return code
+}
+
+// genGlobalItems 按偏移输出全局变量的初始值，条目之间用零字节填充
+func genGlobalItems(g *data.Global) (code string) {
+	offset := 0
+	for _, item := range g.Items {
+		if item.Offset > offset {
+			code += utils.Format("times " + strconv.Itoa(item.Offset-offset) + " db 0")
+		}
+		code += utils.Format(DataInst(item.Size) + " " + item.Value)
+		offset = item.Offset + item.Size
+	}
+	if offset < g.Size() {
+		code += utils.Format("times " + strconv.Itoa(g.Size()-offset) + " db 0")
+	}
+	return code
+}
+
+// genStrings 输出以 0 结尾的字符串字面量
+func genStrings(section *data.Section) (code string) {
+	for _, str := range section.Strings {
+		code += utils.Format(str.Label + ": db " + dbString(str.Value))
+	}
+	return code
+}
+
+// DataInst 返回对应字节数的数据定义伪指令
+func DataInst(size int) string {
+	switch size {
+	case 1:
+		return "db"
+	case 2:
+		return "dw"
+	case 8:
+		return "dq"
+	}
+	return "dd"
+}
+
+// dbString 将字符串转换为 db 的操作数，可打印字符放在引号中，其余字节（包括引号本身）按数值输出，末尾追加 0
+func dbString(s string) (code string) {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch >= ' ' && ch <= '~' && ch != '"' {
+			if !quoted {
+				if code != "" {
+					code += ", "
+				}
+				code += "\""
+				quoted = true
+			}
+			code += string(ch)
+			continue
+		}
+		if quoted {
+			code += "\""
+			quoted = false
+		}
+		if code != "" {
+			code += ", "
+		}
+		code += strconv.Itoa(int(ch))
+	}
+	if quoted {
+		code += "\""
+	}
+	if code != "" {
+		code += ", "
+	}
+	return code + "0"
+}
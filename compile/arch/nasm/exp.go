@@ -1,4 +1,4 @@
-package x86
+package nasm
 
 import (
 	"cuteify/compile/context"
@@ -7,6 +7,7 @@ import (
 	typeSys "cuteify/type"
 	"cuteify/utils"
 	"strconv"
+	"strings"
 )
 
 // 表达式类型常量定义
@@ -18,18 +19,46 @@ const (
 	NotExp  = 5 // 逻辑非表达式
 )
 
+// expCom 编译一个表达式，varWithSetVal 记录叶子节点是否为带赋值的变量
+// 寄存器名按字长取自累加器：EAX/EBX/EDX 或 RAX/RBX/RDX
 type expCom struct {
+	g             *Gen
 	ctx           *context.Context
 	varWithSetVal bool
 }
 
+// Expr 编译表达式，result 为结果的去向（寄存器、内存操作数或 push），条件表达式时为条件不成立时跳转的标签
+func (g *Gen) Expr(exp *parser.Expression, result, desc string) string {
+	return g.expCom().CompileExpr(exp, result, desc)
+}
+
+// Value 将表达式的值计算到寄存器中
+func (g *Gen) Value(exp *parser.Expression) (string, *regmgr.Reg) {
+	return g.expCom().CompileExprChildren(exp)
+}
+
+// expCom 返回正在编译的表达式，不在表达式中时新建一个
+func (g *Gen) expCom() *expCom {
+	if g.cur != nil {
+		return g.cur
+	}
+	return &expCom{g: g, ctx: g.Ctx}
+}
+
+// gen 返回由 c 继续编译子表达式的代码生成
+func (c *expCom) gen() *Gen {
+	g := *c.g
+	g.cur = c
+	return &g
+}
+
 func (c *expCom) CompileExpr(exp *parser.Expression, result, desc string) (code string) {
 	if exp.Type == nil {
 		panic("Expression Type is nil: " + desc)
 	}
 
-	if exp.Type.Type() == "bool" {
-		return c.CompileBoolExpr(exp, result)
+	if exp.Type.Type() == "bool" && result != "" && !c.g.isOperand(result) {
+		return c.compileCond(exp, result, desc)
 	}
 	if tmp := c.numConstHandle(exp, result, desc); tmp != "" {
 		code = tmp
@@ -43,13 +72,15 @@ func (c *expCom) CompileExpr(exp *parser.Expression, result, desc string) (code
 	if reg != nil && !c.varWithSetVal {
 		if result == "push" {
 			code += utils.Format("push " + reg.Name + "; " + desc)
-			// push后立即释放寄存器，避免被SaveAll溢出
-			c.ctx.Reg.Free(exp)
+			// push后立即释放寄存器，避免被SaveAll或Reserve溢出
+			// 二元运算的结果寄存器记录在子表达式名下，按寄存器名释放
+			c.ctx.Reg.Release(reg.Name)
 		} else {
 			if result != reg.Name {
-				code += utils.Format("mov " + result + ", " + reg.Name + "; " + desc)
-				c.ctx.Reg.Free(exp)
-			} else {
+				code += utils.Format("mov " + result + ", " + c.g.SubReg(reg.Name, result) + "; " + desc)
+				// 同样按寄存器名释放，否则调用前的 SaveAll 会把过期的值溢出到变量中
+				c.ctx.Reg.Release(reg.Name)
+			} else if code != "" { // 值已在目标寄存器中时无需生成代码
 				code = code[:len(code)-1] // 去除原先的换行
 				code += "; " + desc + "\n"
 			}
@@ -59,6 +90,21 @@ func (c *expCom) CompileExpr(exp *parser.Expression, result, desc string) (code
 }
 
 func (c *expCom) CompileExprChildren(exp *parser.Expression) (code string, reg *regmgr.Reg) {
+	if exp.Unary != "" {
+		return c.gen().Unary(exp)
+	}
+	if exp.Index != nil {
+		return c.gen().Index(exp)
+	}
+	if exp.Field != nil {
+		return c.gen().Field(exp)
+	}
+
+	// 比较与逻辑运算的结果按 0 或 1 取值
+	if isCond(exp) {
+		return c.boolValue(exp)
+	}
+
 	//末端子节点处理，递归终止
 	if exp.Separator == "" {
 		return c.compileLeafNode(exp)
@@ -69,9 +115,15 @@ func (c *expCom) CompileExprChildren(exp *parser.Expression) (code string, reg *
 }
 
 func (c *expCom) numConstHandle(exp *parser.Expression, result, desc string) (code string) {
-	if typeSys.CheckTypeType(exp.Type, "int", "uint") && exp.IsConst() {
+	if typeSys.CheckTypeType(exp.Type, "int", "uint", "bool") && exp.IsConst() {
 		var tmp string
 		code, tmp = c.CompileExprVal(exp)
+		if !c.g.isReg(result) {
+			// 内存和 push 只接受 32 位立即数
+			var imm string
+			imm, tmp = c.g.imm(tmp)
+			code += imm
+		}
 		if result == "push" {
 			code += utils.Format("push " + tmp + "; " + desc)
 		} else {
@@ -86,14 +138,14 @@ func (c *expCom) numConstHandle(exp *parser.Expression, result, desc string) (co
 func (c *expCom) compileLeafNode(exp *parser.Expression) (code string, reg *regmgr.Reg) {
 	tmp, resultVal := c.CompileExprVal(exp)
 	code += tmp
-	reg = c.ctx.Reg.Get(c.ctx.Now, exp, false)
-
+	// 赋值表达式的值已直接写入变量，不再占用寄存器，否则调用前的 SaveAll 会把它溢出到变量中
 	if c.varWithSetVal {
 		return
 	}
+	reg = c.ctx.Reg.Get(c.ctx.Now, exp, false)
 
 	// 如果 EBX 已被占用（左子使用），重新分配到其他寄存器
-	if c.ctx.EbxOccupied && reg != nil && reg.Name == "EBX" {
+	if c.ctx.EbxOccupied && reg != nil && reg.Name == c.g.save() {
 		c.ctx.Reg.Free(exp)
 		reg = c.ctx.Reg.Get(c.ctx.Now, exp, false)
 	}
@@ -103,7 +155,11 @@ func (c *expCom) compileLeafNode(exp *parser.Expression) (code string, reg *regm
 	}
 
 	if reg.Name != resultVal {
-		code += utils.Format("mov " + reg.Name + ", " + resultVal)
+		if strings.HasSuffix(resultVal, "]") {
+			code += utils.Format(c.g.Load(reg.Name, exp.Type, resultVal))
+		} else {
+			code += utils.Format("mov " + reg.Name + ", " + resultVal)
+		}
 	}
 	return
 }
@@ -113,6 +169,7 @@ func (c *expCom) compileBinaryOp(exp *parser.Expression) (code string, reg *regm
 	var leftReg, rightReg *regmgr.Reg
 	var leftResult string
 	var rightResult string
+	save := c.g.save()
 
 	// 编译左子
 	leftCode, leftReg, leftResult := c.compileLeftChild(exp)
@@ -121,7 +178,7 @@ func (c *expCom) compileBinaryOp(exp *parser.Expression) (code string, reg *regm
 	if c.needSaveLeftToEBX(exp, leftReg) {
 		code += leftCode
 		code += c.saveLeftToEBX(exp.Left, leftReg, leftResult)
-		leftResult = "EBX"
+		leftResult = save
 		leftReg = nil
 	} else {
 		code = leftCode
@@ -133,9 +190,9 @@ func (c *expCom) compileBinaryOp(exp *parser.Expression) (code string, reg *regm
 
 	// 生成运算代码
 	if exp.Separator != "" {
-		if leftResult == "EBX" && leftReg == nil {
+		if leftResult == save && leftReg == nil {
 			code = c.generateOpWithEBX(code, exp, rightResult, rightReg, exp.Left)
-			reg = &regmgr.Reg{Name: "EBX"}
+			reg = &regmgr.Reg{Name: save}
 		} else {
 			code, reg = c.generateOpNormal(code, exp, leftReg, rightReg, leftResult, rightResult)
 		}
@@ -151,14 +208,14 @@ func (c *expCom) compileLeftChild(exp *parser.Expression) (code string, reg *reg
 	}
 
 	// 优化：如果左子是函数调用且右子也有函数调用，直接把返回值移到 EBX
-	if exp.Left.Call != nil && exp.Right != nil && c.containsCall(exp.Right) {
+	if exp.Left.Call != nil && exp.Right != nil && ContainsCall(exp.Right) {
 		c.ctx.UseEBXDirect = true
 		code, result = c.CompileExprVal(exp.Left)
 		c.ctx.UseEBXDirect = false
 		reg = nil
 
 		// 锁定 EBX，防止被右子分配使用
-		c.ctx.Reg.Force(&regmgr.Reg{Name: "EBX", RegIndex: 1}, c.ctx.Now, exp.Left)
+		c.ctx.Reg.Force(&regmgr.Reg{Name: c.g.save(), RegIndex: 1}, c.ctx.Now, exp.Left)
 		return
 	}
 
@@ -178,8 +235,13 @@ func (c *expCom) compileRightChild(exp *parser.Expression, leftResult string) (c
 	}
 
 	// 如果左子结果在 EBX 中，设置标志防止右子使用 EBX
-	if leftResult == "EBX" {
+	if leftResult == c.g.save() {
 		c.ctx.EbxOccupied = true
+		// 同时锁定 EBX，防止右子在寄存器不足时（如计算下标地址）将其溢出复用
+		if ebx := c.g.saveReg(); !ebx.Locked {
+			ebx.Locked = true
+			defer func() { ebx.Locked = false }()
+		}
 	}
 	defer func() { c.ctx.EbxOccupied = false }()
 
@@ -202,7 +264,7 @@ func (c *expCom) needSaveLeftToEBX(exp *parser.Expression, leftReg *regmgr.Reg)
 	if exp.Right == nil {
 		return false
 	}
-	if !c.containsCall(exp.Right) {
+	if !ContainsCall(exp.Right) {
 		return false
 	}
 	// 左子不是函数调用，需要保存
@@ -216,7 +278,7 @@ func (c *expCom) saveLeftToEBX(leftExp *parser.Expression, leftReg *regmgr.Reg,
 	if leftReg != nil {
 		c.ctx.Reg.Free(leftExp)
 	}
-	code += utils.Format("mov EBX, " + leftResult + "; 保存中间结果到EBX(callee-save)")
+	code += utils.Format("mov " + c.g.save() + ", " + leftResult + "; 保存中间结果到" + c.g.save() + "(callee-save)")
 	return code
 }
 
@@ -227,26 +289,7 @@ func (c *expCom) generateOpWithEBX(code string, exp *parser.Expression, rightRes
 		c.ctx.Reg.Free(exp.Right)
 	}
 
-	formattedSrc := rightResult
-
-	switch exp.Separator {
-	case "+":
-		code += utils.Format("add EBX, " + formattedSrc + "; EBX = fib(i-1) + fib(i-2)")
-	case "-":
-		code += utils.Format("sub EBX, " + formattedSrc)
-	case "*":
-		code += utils.Format("imul EBX, " + formattedSrc)
-	case "/":
-		code += utils.Format("xor edx, edx")
-		code += utils.Format("mov eax, EBX")
-		code += utils.Format("idiv " + formattedSrc)
-		code += utils.Format("mov EBX, eax")
-	case "%":
-		code += utils.Format("xor edx, edx")
-		code += utils.Format("mov eax, EBX")
-		code += utils.Format("idiv " + formattedSrc)
-		code += utils.Format("mov EBX, edx")
-	}
+	code += c.emitOpInstruction(c.g.save(), exp.Separator, rightResult)
 
 	// 解锁 EBX
 	c.ctx.Reg.Free(leftExp)
@@ -263,12 +306,10 @@ func (c *expCom) generateOpNormal(code string, exp *parser.Expression, leftReg,
 		if reg.StoreCode != "" {
 			code += utils.Format(reg.StoreCode)
 		}
-		formattedLeft := leftResult
-		code += utils.Format("mov " + reg.Name + ", " + formattedLeft)
+		code += utils.Format("mov " + reg.Name + ", " + leftResult)
 	}
 
-	formattedSrc := src
-	code += c.emitOpInstruction(reg.Name, exp.Separator, formattedSrc)
+	code += c.emitOpInstruction(reg.Name, exp.Separator, src)
 
 	return code, reg
 }
@@ -290,30 +331,144 @@ func (c *expCom) selectResultReg(leftReg, rightReg *regmgr.Reg, leftResult, righ
 	return nil, rightResult
 }
 
-// 发出运算指令
-func (c *expCom) emitOpInstruction(regName, op, src string) string {
+// 发出运算指令，超出立即数范围的常量先载入临时寄存器
+func (c *expCom) emitOpInstruction(regName, op, src string) (code string) {
+	code, src = c.g.imm(src)
 	switch op {
 	case "+":
-		return utils.Format("add " + regName + ", " + src)
+		return code + utils.Format("add "+regName+", "+src)
 	case "-":
-		return utils.Format("sub " + regName + ", " + src)
+		return code + utils.Format("sub "+regName+", "+src)
 	case "*":
-		return utils.Format("imul " + regName + ", " + src)
+		return code + utils.Format("imul "+regName+", "+src)
 	case "/":
-		return utils.Format("xor edx, edx") +
-			utils.Format("mov eax, "+regName) +
-			utils.Format("idiv "+src) +
-			utils.Format("mov "+regName+", eax")
+		return code + c.g.div(regName, src, c.g.Acc)
 	case "%":
-		return utils.Format("xor edx, edx") +
-			utils.Format("mov eax, "+regName) +
-			utils.Format("idiv "+src) +
-			utils.Format("mov "+regName+", edx")
+		return code + c.g.div(regName, src, c.g.reg("DX"))
 	default:
 		return ""
 	}
 }
 
+// div 生成 regName = regName / src（result 为 EAX）或 regName % src（result 为 EDX）
+// idiv 隐式使用 EAX、EDX，结果寄存器之外的 EAX、EDX 在前后保存和恢复
+// 除数先放入 Scratch；没有 Scratch 时压栈，从栈顶取用，避免除数本身位于 EAX、EDX 中被覆盖
+func (g *Gen) div(regName, src, result string) (code string) {
+	acc, dx := g.Acc, g.reg("DX")
+	var saved []string
+	for _, r := range []string{acc, dx} {
+		if r != regName {
+			code += utils.Format("push " + r)
+			saved = append(saved, r)
+		}
+	}
+	divisor := g.Scratch
+	if divisor == "" {
+		code += utils.Format("push " + src + "; 除数")
+		divisor = g.ptr() + "[" + g.Stack + "]"
+	} else if src != divisor {
+		code += utils.Format("mov " + divisor + ", " + src + "; 除数")
+	}
+	if regName != acc {
+		code += utils.Format("mov " + acc + ", " + regName)
+	}
+	if g.Word == 8 {
+		code += utils.Format("cqo")
+	} else {
+		code += utils.Format("cdq")
+	}
+	code += utils.Format("idiv " + divisor)
+	if g.Scratch == "" {
+		code += utils.Format("add " + g.Stack + ", " + strconv.Itoa(g.Word) + "; 弹出除数")
+	}
+	if regName != result {
+		code += utils.Format("mov " + regName + ", " + result)
+	}
+	for i := len(saved) - 1; i >= 0; i-- {
+		code += utils.Format("pop " + saved[i])
+	}
+	return code
+}
+
+// isCond 判断表达式是否为比较或逻辑运算
+func isCond(exp *parser.Expression) bool {
+	switch exp.Separator {
+	case "==", "!=", "<", ">", "<=", ">=", "&&", "||":
+		return true
+	}
+	return false
+}
+
+// boolLabels 返回一对唯一的标签，用于条件的短路求值和比较结果的取值
+func (c *expCom) boolLabels() (next, end string) {
+	n := strconv.Itoa(c.ctx.BoolCount)
+	c.ctx.BoolCount++
+	return "bool_" + n + "_next", "bool_" + n + "_end"
+}
+
+// compileCond 编译条件，exp 为假时跳转到 label
+// && 与 || 短路求值；变量、调用等布尔值与 0 比较，只看最低字节
+func (c *expCom) compileCond(exp *parser.Expression, label, desc string) (code string) {
+	switch {
+	case exp.IsConst():
+		// 常量条件：恒真无需检查，恒假直接跳转
+		if !exp.Bool {
+			code += utils.Format("jmp " + label + "; " + desc)
+		}
+		return code
+	case exp.Separator == "&&":
+		return c.compileCond(exp.Left, label, desc) + c.compileCond(exp.Right, label, desc)
+	case exp.Separator == "||":
+		next, end := c.boolLabels()
+		code += c.compileCond(exp.Left, next, desc)
+		code += utils.Format("jmp " + end + "; 左侧为真")
+		code += utils.Format(next + ":")
+		code += c.compileCond(exp.Right, label, desc)
+		return code + utils.Format(end+":")
+	case isCond(exp):
+		return c.CompileBoolExpr(exp, label)
+	case exp.Var != nil || exp.Call != nil:
+		var val string
+		code, val = c.CompileExprVal(exp)
+		if c.g.isReg(val) {
+			val = c.g.SubReg(val, "BYTE[")
+		}
+		code += utils.Format("cmp " + val + ", 0")
+	default:
+		// 下标、解引用等其余表达式按值计算到寄存器后检查
+		valueCode, reg := c.CompileExprChildren(exp)
+		code += valueCode
+		code += utils.Format("test " + reg.Name + ", " + reg.Name)
+		c.ctx.Reg.Free(valueOwner(exp))
+	}
+	return code + utils.Format("je "+label+"; "+desc)
+}
+
+// valueOwner 返回值寄存器所记录的表达式：解引用与下标的结果寄存器记录在地址所属的子表达式名下
+func valueOwner(exp *parser.Expression) *parser.Expression {
+	switch {
+	case exp.Unary == "*":
+		return exp.Right
+	case exp.Index != nil || exp.Field != nil:
+		return exp.Left
+	}
+	return exp
+}
+
+// boolValue 将比较或逻辑运算的结果（0 或 1）计算到寄存器中
+func (c *expCom) boolValue(exp *parser.Expression) (code string, reg *regmgr.Reg) {
+	falseLabel, end := c.boolLabels()
+	code += c.compileCond(exp, falseLabel, "比较结果")
+	reg = c.ctx.Reg.Get(c.ctx.Now, exp, false)
+	code += reg.StoreCode
+	code += utils.Format("mov " + reg.Name + ", 1")
+	code += utils.Format("jmp " + end)
+	code += utils.Format(falseLabel + ":")
+	code += utils.Format("mov " + reg.Name + ", 0")
+	code += utils.Format(end + ":")
+	return code, reg
+}
+
 func (c *expCom) CompileBoolExpr(exp *parser.Expression, result string) (code string) {
 	var leftReg *regmgr.Reg
 	var rightReg *regmgr.Reg
@@ -343,9 +498,9 @@ func (c *expCom) CompileBoolExpr(exp *parser.Expression, result string) (code st
 		code += rightCode
 
 		// 生成代码
-		formattedLeft := leftResult
-		formattedRight := rightResult
-		code += utils.Format("cmp " + formattedLeft + ", " + formattedRight)
+		immCode, formattedRight := c.g.imm(rightResult)
+		code += immCode
+		code += utils.Format("cmp " + leftResult + ", " + formattedRight)
 		// 释放寄存器
 		if exp.Left != nil {
 			c.ctx.Reg.Free(exp.Left)
@@ -389,6 +544,9 @@ func (c *expCom) CompileExprVal(exp *parser.Expression) (code, result string) {
 			} else {
 				result = "0"
 			}
+		} else if typeSys.CheckTypeType(exp.Type, "string") {
+			// 字符串字面量的值为其在 .rodata 中的地址
+			result = c.ctx.Data.Intern(exp.StringVal)
 		}
 	} else if exp.Var != nil {
 		if exp.Var.Value != nil {
@@ -400,27 +558,16 @@ func (c *expCom) CompileExprVal(exp *parser.Expression) (code, result string) {
 		if rInfo := c.ctx.Reg.Reuse(exp.Var.Define); rInfo != nil {
 			result = rInfo.Name
 		} else {
-			result = genVarAddr(c.ctx, exp.Var)
+			result = c.g.VarAddr(exp.Var)
 		}
 	} else if exp.Call != nil {
 		code = c.ctx.Arch.Call(exp.Call)
-		result = "EAX"
+		result = c.g.Acc
 		// 如果标记为直接使用 EBX，生成 mov EBX, EAX
 		if c.ctx.UseEBXDirect {
-			code += utils.Format("mov EBX, EAX; 函数返回值直接移到EBX")
-			result = "EBX"
+			code += utils.Format("mov " + c.g.save() + ", " + c.g.Acc + "; 函数返回值直接移到" + c.g.save())
+			result = c.g.save()
 		}
 	}
 	return
 }
-
-// containsCall 检查表达式或其子表达式中是否包含函数调用
-func (c *expCom) containsCall(exp *parser.Expression) bool {
-	if exp == nil {
-		return false
-	}
-	if exp.Call != nil {
-		return true
-	}
-	return c.containsCall(exp.Left) || c.containsCall(exp.Right)
-}
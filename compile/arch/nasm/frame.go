@@ -0,0 +1,201 @@
+package nasm
+
+import (
+	"cuteify/compile/data"
+	"cuteify/compile/regmgr"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strconv"
+)
+
+// VarAddr 生成带长度前缀的变量地址，如 DWORD[ebp-8]
+// 方法中的 self 本身即为接收者地址，直接返回 Self 寄存器
+func (g *Gen) VarAddr(v *parser.VarBlock) string {
+	if v.Name.First() == "self" && !v.Name.IsPath() {
+		return g.Self
+	}
+	return utils.GetLengthName(v.Type.Size()) + g.VarRef(v)
+}
+
+// VarRef 生成变量的内存地址（不含长度前缀），结构体字段按布局加上字段偏移
+// self 的字段通过 Self 寄存器中的接收者地址间接访问，全局变量使用数据段中的符号地址
+func (g *Gen) VarRef(v *parser.VarBlock) string {
+	if data.IsGlobal(v) {
+		return RefAdd("["+data.GlobalLabel(data.Define(v).Name)+"]", g.fieldOffset(v))
+	}
+	base := g.Base
+	var isDefineInArg bool
+	if v.Define != nil {
+		switch def := v.Define.Value.(type) {
+		case *parser.VarBlock:
+			v.Offset = def.Offset
+		case *parser.ArgBlock:
+			isDefineInArg = true
+			v.Offset = def.Offset
+		}
+	}
+
+	totalOffset := v.Offset
+	if v.Name.First() == "self" {
+		base = g.Self
+		totalOffset = 0
+	} else if !isDefineInArg {
+		totalOffset += g.Ctx.BpOffset
+	}
+
+	if len(v.Name) > 1 {
+		totalOffset += g.fieldOffset(v)
+	}
+
+	offsetStr := strconv.FormatInt(int64(totalOffset), 10)
+	if totalOffset < 0 {
+		return "[" + base + offsetStr + "]"
+	} else if totalOffset == 0 {
+		return "[" + base + "]"
+	}
+	return "[" + base + "+" + offsetStr + "]"
+}
+
+// fieldOffset 返回变量路径中各级字段偏移之和
+func (g *Gen) fieldOffset(v *parser.VarBlock) int {
+	if len(v.Name) <= 1 {
+		return 0
+	}
+
+	var currentType typeSys.Type
+	if v.Define != nil {
+		switch def := v.Define.Value.(type) {
+		case *parser.VarBlock:
+			currentType = def.Type
+		case *parser.ArgBlock:
+			currentType = def.Type
+		}
+	}
+	if currentType == nil {
+		return 0
+	}
+
+	totalOffset := 0
+	for i := 1; i < len(v.Name); i++ {
+		fieldName := v.Name[i]
+		structName := currentType.Type()
+		structBlock, exists := g.Ctx.GetStruct(structName)
+		if !exists {
+			panic("编译器内部错误: 未注册的结构体 " + structName)
+		}
+		field := structBlock.GetFieldByName(fieldName)
+		if field == nil {
+			panic("编译器内部错误: 结构体 " + structName + " 没有字段 " + fieldName)
+		}
+		totalOffset += field.Offset
+		currentType = field.Type
+	}
+	return totalOffset
+}
+
+// Prologue 建立栈帧并保存 callee-saved 寄存器，方法把 recv 处的接收者地址载入 Self 寄存器
+// 返回已压栈的寄存器个数，局部变量位于它们之下
+func (g *Gen) Prologue(funcBlock *parser.FuncBlock, recv string) (code string, saved int) {
+	code += utils.Format("push " + g.Base + "; 保存调用者的栈帧基址")
+	code += utils.Format("mov " + g.Base + ", " + g.Stack + "; 设置当前栈帧基址")
+
+	for _, r := range g.Regs {
+		if r.CalleeSave {
+			code += utils.Format("push " + r.Name + "; 保存" + r.Name)
+			saved++
+		}
+	}
+	if funcBlock.Class != nil {
+		code += utils.Format("push " + g.Self + "; 保存" + g.Self)
+		code += utils.Format("mov " + g.Self + ", " + recv + "; self地址")
+		saved++
+	}
+	return code, saved
+}
+
+// AllocFrame 按 ctx.StackSize 分配局部变量栈空间
+func (g *Gen) AllocFrame() string {
+	if g.Ctx.StackSize <= 0 {
+		return ""
+	}
+	size := strconv.Itoa(g.Ctx.StackSize)
+	return utils.Format("sub " + g.Stack + ", " + size + "; 分配栈空间(" + size + "字节)")
+}
+
+// Return 生成返回值传递和函数尾声，retInst 为最终的返回指令（如 ret 或 ret N）
+func (g *Gen) Return(ret *parser.ReturnBlock, retInst string) (code string) {
+	ctx := g.Ctx
+	// 处理返回值：将值放入累加器
+	if ret != nil && len(ret.Value) != 0 {
+		// 强制使用累加器作为返回值寄存器
+		accReg := &regmgr.Reg{Name: g.Acc, RegIndex: 0}
+		ctx.Reg.Force(accReg, ctx.Now, ret.Value[0])
+
+		if accReg.StoreCode != "" {
+			code += utils.Format(accReg.StoreCode)
+		}
+
+		// 编译返回表达式到累加器
+		code += ctx.Arch.Exp(ret.Value[0], g.Acc, "return值存入"+g.Acc)
+		// 释放表达式使用的寄存器
+		ctx.Reg.Free(ret.Value[0])
+	}
+
+	code += utils.Format("; ---- 退出函数 ----")
+
+	// 清理局部变量栈空间
+	if ctx.StackSize > 0 {
+		code += utils.Format("add " + g.Stack + ", " + strconv.Itoa(ctx.StackSize) + "; 清理局部变量栈空间(" + strconv.Itoa(ctx.StackSize) + "字节)")
+	}
+
+	// 恢复callee-saved寄存器
+	if ctx.CurrentFunc != nil && ctx.CurrentFunc.Class != nil {
+		code += utils.Format("pop " + g.Self + "; 恢复" + g.Self)
+	}
+	for i := len(g.Regs) - 1; i >= 0; i-- {
+		r := g.Regs[i]
+		if r.CalleeSave {
+			code += utils.Format("pop " + r.Name + "; 恢复" + r.Name)
+		}
+	}
+
+	// 恢复调用者的栈帧基址
+	code += utils.Format("leave")
+	code += utils.Format(retInst + "\n")
+	return code
+}
+
+// PushReceiver 压入接收者地址，self 直接使用 Self 寄存器
+func (g *Gen) PushReceiver(recv *parser.VarBlock) (code string) {
+	ref := g.VarRef(recv)
+	if ref == "["+g.Self+"]" {
+		return utils.Format("push " + g.Self + "; 接收者地址")
+	}
+	code += utils.Format("lea " + g.Acc + ", " + ref + "; 取接收者地址")
+	code += utils.Format("push " + g.Acc + "; 接收者地址")
+	return code
+}
+
+// Var 生成变量赋值
+func (g *Gen) Var(varBlock *parser.VarBlock) (code string) {
+	if varBlock.Store != nil {
+		return g.Store(varBlock)
+	}
+	addr := g.VarAddr(varBlock)
+	if varBlock.Value == nil {
+		// 没有初始值的结构体变量清零，字段默认值随后写入
+		if t, ok := varBlock.Type.(*typeSys.StructType); ok && !t.IsPointer() {
+			code += g.Zero(g.VarRef(varBlock), varBlock.Type.Size())
+		}
+		return
+	}
+	switch t := varBlock.Type.(type) {
+	case *typeSys.InterfaceType:
+		return g.IfaceVar(varBlock, t)
+	case *typeSys.SliceType:
+		return g.SliceVar(varBlock)
+	}
+	code += g.Ctx.Arch.Exp(varBlock.Value, addr, "设置变量"+varBlock.Name.String())
+	return
+}
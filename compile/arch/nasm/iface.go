@@ -0,0 +1,100 @@
+package nasm
+
+import (
+	"cuteify/compile/context"
+	"cuteify/compile/regmgr"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strconv"
+)
+
+// 接口值为两个字的胖指针：[+0] 数据地址，[+字长] 虚表地址
+
+// VTableLabel 返回结构体实现接口时的虚表标签，并登记到上下文中以便在 .rodata 中输出
+func VTableLabel(ctx *context.Context, structType *typeSys.StructType, iface *typeSys.InterfaceType) string {
+	label := utils.ToNASMName("vtable_" + structType.Type() + "_" + iface.Type())
+	ctx.AddVTable(label, structType, iface)
+	return label
+}
+
+// MethodSlot 返回方法在接口虚表中的槽位
+func MethodSlot(iface *typeSys.InterfaceType, name string) int {
+	for i, m := range iface.Methods {
+		if m.(*parser.FuncBlock).Name.Last() == name {
+			return i
+		}
+	}
+	panic("编译器内部错误: 接口 " + iface.Type() + " 没有方法 " + name)
+}
+
+// IfaceVar 将结构体变量或接口变量赋值给接口变量
+func (g *Gen) IfaceVar(varBlock *parser.VarBlock, iface *typeSys.InterfaceType) (code string) {
+	src := varBlock.Value.Var
+	if src == nil {
+		panic("编译器内部错误: 接口只能由变量赋值")
+	}
+	dst := g.VarRef(varBlock)
+	srcRef := g.VarRef(src)
+	ptr := g.ptr()
+
+	accReg := &regmgr.Reg{Name: g.Acc, RegIndex: 0}
+	g.Ctx.Reg.Force(accReg, g.Ctx.Now, varBlock.Value)
+	if accReg.StoreCode != "" {
+		code += utils.Format(accReg.StoreCode)
+	}
+	switch t := src.Type.(type) {
+	case *typeSys.StructType:
+		code += utils.Format("lea " + g.Acc + ", " + srcRef + "; 取" + src.Name.String() + "地址")
+		code += utils.Format("mov " + ptr + dst + ", " + g.Acc + "; 接口数据地址")
+		code += utils.Format("mov " + ptr + RefAdd(dst, g.Word) + ", " + VTableLabel(g.Ctx, t, iface) + "; 接口虚表")
+	case *typeSys.InterfaceType:
+		code += utils.Format("mov " + g.Acc + ", " + ptr + srcRef + "; 复制接口数据地址")
+		code += utils.Format("mov " + ptr + dst + ", " + g.Acc)
+		code += utils.Format("mov " + g.Acc + ", " + ptr + RefAdd(srcRef, g.Word) + "; 复制接口虚表")
+		code += utils.Format("mov " + ptr + RefAdd(dst, g.Word) + ", " + g.Acc)
+	}
+	g.Ctx.Reg.Free(varBlock.Value)
+	return code
+}
+
+// PushIface 以接口值的形式压入参数，虚表地址先压栈，使数据地址位于低地址
+func (g *Gen) PushIface(value *parser.Expression, iface *typeSys.InterfaceType, desc string) (code string) {
+	src := value.Var
+	if src == nil {
+		panic("编译器内部错误: 接口只能由变量传参")
+	}
+	srcRef := g.VarRef(src)
+	switch t := src.Type.(type) {
+	case *typeSys.StructType:
+		code += utils.Format("push " + VTableLabel(g.Ctx, t, iface) + "; " + desc + "虚表")
+		code += utils.Format("lea " + g.Acc + ", " + srcRef + "; 取" + src.Name.String() + "地址")
+		code += utils.Format("push " + g.Acc + "; " + desc + "数据地址")
+	case *typeSys.InterfaceType:
+		code += utils.Format("push " + g.ptr() + RefAdd(srcRef, g.Word) + "; " + desc + "虚表")
+		code += utils.Format("push " + g.ptr() + srcRef + "; " + desc + "数据地址")
+	}
+	return code
+}
+
+// IfaceCall 通过接口值的虚表间接调用方法，接收者（数据地址）已由调用约定放好
+func (g *Gen) IfaceCall(call *parser.CallBlock, iface *typeSys.InterfaceType) (code string) {
+	ref := g.VarRef(call.ThisVar)
+	slot := MethodSlot(iface, call.Name.Last())
+	code += utils.Format("mov " + g.Acc + ", " + g.ptr() + RefAdd(ref, g.Word) + "; 取虚表地址")
+	code += utils.Format("call " + g.ptr() + RefAdd("["+g.Acc+"]", slot*g.Word) + "; 动态分派" + call.Name.String())
+	return code
+}
+
+// vtables 在 .rodata 中输出用到的虚表，每个槽位为对应结构体方法的标签
+func (g *Gen) vtables() (code string) {
+	for _, vt := range g.Ctx.VTables {
+		code += utils.Format("align " + strconv.Itoa(g.Word))
+		code += utils.Format(vt.Label + ":")
+		for _, m := range vt.Iface.Methods {
+			name := m.(*parser.FuncBlock).Name.Last()
+			code += utils.Format(DataInst(g.Word) + " " + FuncLabel(parser.FindMethod(vt.Struct, name)))
+		}
+	}
+	return code
+}
@@ -0,0 +1,107 @@
+package nasm
+
+import (
+	"cuteify/parser"
+	"cuteify/utils"
+	"strconv"
+)
+
+// 循环代码生成（for/while/break/continue）
+
+// loopCondition 生成循环条件检查，条件为假时跳转到 endLabel
+func (g *Gen) loopCondition(cond *parser.Expression, endLabel, desc string) (code string) {
+	if cond == nil {
+		return
+	}
+	return g.Expr(cond, endLabel, desc)
+}
+
+func (g *Gen) For(forBlock *parser.ForBlock) (code string) {
+	if forBlock == nil {
+		return ""
+	}
+
+	g.Ctx.ForCount++
+	forBlock.Offset = g.Ctx.ForCount
+	forLabel := "for_" + strconv.Itoa(forBlock.Offset)
+	forEndLabel := forLabel + "_end"
+	g.Ctx.PushLoop(forLabel+"_continue", forEndLabel)
+
+	code += utils.Format("")
+	code += utils.Format("")
+
+	// 1. 初始化 - 编译变量定义
+	if forBlock.Init != nil {
+		// 编译初始化表达式到变量地址
+		code += g.Expr(forBlock.Init, "", "初始化for循环")
+	}
+
+	code += utils.Format("")
+	code += utils.Format("")
+
+	// 循环开始标签
+	code += utils.Format(forLabel + ": ; for循环开始")
+
+	// 2. 条件检查 - 如果条件为假则跳出循环
+	code += g.loopCondition(forBlock.Condition, forEndLabel, "for循环条件检查")
+
+	code += utils.Format("")
+	code += utils.Format("")
+
+	return code
+}
+
+func (g *Gen) EndFor(forBlock *parser.ForBlock) (code string) {
+	code += utils.Format("")
+	code += utils.Format("")
+
+	forLabel := "for_" + strconv.Itoa(forBlock.Offset)
+	code += utils.Format(forLabel + "_continue: ; for循环增量")
+	if forBlock.Increment != nil {
+		code += g.Expr(forBlock.Increment, "", "for循环增量")
+	}
+	code += utils.Format("jmp " + forLabel + "; for循环")
+	code += utils.Format(forLabel + "_end: ; for循环结束")
+	g.Ctx.PopLoop()
+	return
+}
+
+func (g *Gen) While(whileBlock *parser.WhileBlock) (code string) {
+	if whileBlock == nil {
+		return ""
+	}
+
+	g.Ctx.WhileCount++
+	whileBlock.Offset = g.Ctx.WhileCount
+	whileLabel := "while_" + strconv.Itoa(whileBlock.Offset)
+	whileEndLabel := whileLabel + "_end"
+	g.Ctx.PushLoop(whileLabel, whileEndLabel)
+
+	code += utils.Format(whileLabel + ": ; while循环开始")
+	code += g.loopCondition(whileBlock.Condition, whileEndLabel, "while循环条件检查")
+	return code
+}
+
+func (g *Gen) EndWhile(whileBlock *parser.WhileBlock) (code string) {
+	whileLabel := "while_" + strconv.Itoa(whileBlock.Offset)
+	code += utils.Format("jmp " + whileLabel + "; while循环")
+	code += utils.Format(whileLabel + "_end: ; while循环结束")
+	g.Ctx.PopLoop()
+	return
+}
+
+func (g *Gen) Break() string {
+	loop, ok := g.Ctx.CurrentLoop()
+	if !ok {
+		panic("编译器内部错误: break 不在循环中")
+	}
+	return utils.Format("jmp " + loop.End + "; break")
+}
+
+func (g *Gen) Continue() string {
+	loop, ok := g.Ctx.CurrentLoop()
+	if !ok || loop.Continue == "" {
+		panic("编译器内部错误: continue 不在循环中")
+	}
+	return utils.Format("jmp " + loop.Continue + "; continue")
+}
@@ -0,0 +1,112 @@
+// Package nasm 是 32 位 x86 与 x86-64 两个 NASM 汇编后端共用的代码生成：表达式、栈帧、循环、switch、数组与切片、指针、接口与数据段。
+//
+// 两者的差异（字长、寄存器名、载入与扩展指令、系统调用）由 Gen 的字段描述。
+package nasm
+
+import (
+	"cuteify/compile/context"
+	"cuteify/compile/regmgr"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"strconv"
+	"strings"
+)
+
+// Gen 一个后端在当前上下文中的代码生成，由后端按需创建
+type Gen struct {
+	Ctx     *context.Context
+	Word    int           // 字长（字节）：4 或 8
+	Acc     string        // 累加器：EAX 或 RAX，同名的 BX、DX 寄存器为 EBX/RBX、EDX/RDX
+	Base    string        // 栈帧基址寄存器：ebp 或 rbp
+	Stack   string        // 栈指针：esp 或 rsp
+	Self    string        // 方法中保存接收者地址（self）的寄存器，不参与寄存器分配
+	Scratch string        // 不参与寄存器分配的临时寄存器，没有时为空
+	Regs    []*regmgr.Reg // 参与分配的寄存器，其中 CalleeSave 的在序言中保存
+
+	// Load 返回从内存 mem 按类型 t 载入寄存器 reg 的指令，窄类型扩展到整个寄存器
+	Load func(reg string, t typeSys.Type, mem string) string
+	// Convert 将寄存器中按 from 类型解释的值转换为 to 类型
+	Convert func(reg string, from, to typeSys.Type) string
+	// SubReg 返回写入内存地址 addr 时应使用的寄存器，如 EAX 写入 BYTE[...] 时为 AL
+	SubReg func(reg, addr string) string
+	// Imm 返回常量作为比较指令的源操作数时的写法，超出立即数范围时先载入临时寄存器；为空时直接使用常量
+	Imm func(src string) (code, operand string)
+	// Exit 返回越界 panic 例程的系统调用：向 stderr 写出 label 处的 n 字节，然后以退出码 2 结束进程
+	Exit func(label string, n int) string
+
+	cur *expCom // 正在编译的表达式，子表达式由它继续编译
+}
+
+// ptr 返回字长的内存操作数前缀
+func (g *Gen) ptr() string {
+	if g.Word == 8 {
+		return "QWORD"
+	}
+	return "DWORD"
+}
+
+// imm 返回常量 src 作为源操作数时的写法
+func (g *Gen) imm(src string) (code, operand string) {
+	if g.Imm == nil {
+		return "", src
+	}
+	return g.Imm(src)
+}
+
+// reg 返回与累加器同字长的寄存器，如 reg("DX") 为 EDX 或 RDX
+func (g *Gen) reg(name string) string {
+	return g.Acc[:1] + name
+}
+
+// save 返回跨函数调用保存中间结果的 callee-saved 寄存器：EBX 或 RBX
+func (g *Gen) save() string {
+	return g.reg("BX")
+}
+
+// saveReg 返回 save 寄存器在 Regs 中的描述
+func (g *Gen) saveReg() *regmgr.Reg {
+	for _, r := range g.Regs {
+		if r.Name == g.save() {
+			return r
+		}
+	}
+	panic("编译器内部错误: 寄存器表中没有 " + g.save())
+}
+
+// isReg 判断操作数是否为整个字长的寄存器（名称与累加器同以 E 或 R 开头）
+func (g *Gen) isReg(operand string) bool {
+	return strings.HasPrefix(operand, g.Acc[:1])
+}
+
+// isOperand 判断表达式结果的去向是否为操作数（寄存器、内存或 push），否则为条件不成立时跳转的标签
+func (g *Gen) isOperand(result string) bool {
+	return result == "push" || strings.Contains(result, "[") || g.isReg(result)
+}
+
+// RefAdd 在内存地址表达式上追加偏移，如 [ebp-8] + 4 => [ebp-8+4]
+func RefAdd(ref string, offset int) string {
+	if offset == 0 {
+		return ref
+	}
+	return strings.TrimSuffix(ref, "]") + "+" + strconv.Itoa(offset) + "]"
+}
+
+// FuncLabel 返回函数的汇编标签（函数名 + 参数个数，main 除外）
+func FuncLabel(funcBlock *parser.FuncBlock) string {
+	name := funcBlock.Name.String()
+	if name != "main" {
+		name = name + strconv.Itoa(len(funcBlock.Args))
+	}
+	return name
+}
+
+// ContainsCall 检查表达式或其子表达式中是否包含函数调用
+func ContainsCall(exp *parser.Expression) bool {
+	if exp == nil {
+		return false
+	}
+	if exp.Call != nil {
+		return true
+	}
+	return ContainsCall(exp.Left) || ContainsCall(exp.Right) || ContainsCall(exp.Index)
+}
@@ -0,0 +1,100 @@
+package nasm
+
+import (
+	"cuteify/compile/regmgr"
+	"cuteify/parser"
+	"cuteify/utils"
+)
+
+// Unary 编译一元运算：& 取地址，* 按元素宽度从指针处载入，len 取切片长度，as 类型转换
+func (g *Gen) Unary(exp *parser.Expression) (code string, reg *regmgr.Reg) {
+	operand := exp.Right
+	switch exp.Unary {
+	case "&":
+		return g.addr(operand, exp)
+	case "*":
+		code, reg = g.Value(operand)
+		code += utils.Format(g.Load(reg.Name, exp.Type, DerefAddr(exp, reg.Name)) + "; 解引用")
+	case "len":
+		return g.length(exp)
+	case "as":
+		code, reg = g.Value(operand)
+		code += g.Convert(reg.Name, operand.Type, exp.Type)
+	}
+	return code, reg
+}
+
+// addr 计算可取地址表达式（变量、字段、解引用、下标）的地址，变量地址所用寄存器记录在 owner 名下
+func (g *Gen) addr(exp, owner *parser.Expression) (code string, reg *regmgr.Reg) {
+	switch {
+	case exp.Unary == "*":
+		// &*p 即 p 本身
+		return g.Value(exp.Right)
+	case exp.Index != nil:
+		return g.indexAddr(exp, owner)
+	case exp.Field != nil:
+		return g.fieldAddr(exp, owner)
+	}
+	reg = g.Ctx.Reg.Get(g.Ctx.Now, owner, false)
+	if reg.StoreCode != "" {
+		code += reg.StoreCode
+	}
+	code += utils.Format("lea " + reg.Name + ", " + g.VarRef(exp.Var) + "; 取" + exp.Var.Name.String() + "地址")
+	return code, reg
+}
+
+// DerefAddr 返回解引用或下标表达式在内存中的操作数，如 BYTE[EAX]
+func DerefAddr(deref *parser.Expression, reg string) string {
+	return utils.GetLengthName(deref.Type.Size()) + "[" + reg + "]"
+}
+
+// Store 生成通过指针或下标赋值（*p = v、a[i] = v）的代码
+// 值中含有函数调用时先计算值并压栈，避免调用破坏已算出的地址
+func (g *Gen) Store(varBlock *parser.VarBlock) (code string) {
+	ctx := g.Ctx
+	target := varBlock.Store
+	// 解引用时地址即指针的值，寄存器记录在指针表达式名下
+	owner := target
+	if target.Unary == "*" {
+		owner = target.Right
+	}
+	if !ContainsCall(varBlock.Value) {
+		addrCode, addrReg := g.addr(target, target)
+		code += addrCode
+		// 计算值期间锁定地址寄存器，避免寄存器不足时被溢出复用
+		addrReg.Locked = true
+		code += ctx.Arch.Exp(varBlock.Value, DerefAddr(target, addrReg.Name), "通过指针赋值")
+		addrReg.Locked = false
+		ctx.Reg.Free(owner)
+		return code
+	}
+
+	code += ctx.Arch.Exp(varBlock.Value, "push", "暂存待写入的值")
+	// 计算地址时可能还有调用，压栈的值计入栈对齐
+	ctx.SpOffset += g.Word
+	addrCode, addrReg := g.addr(target, target)
+	code += addrCode
+	valReg := ctx.Reg.Get(ctx.Now, varBlock.Value, false)
+	if valReg.StoreCode != "" {
+		code += valReg.StoreCode
+	}
+	code += utils.Format("pop " + valReg.Name)
+	ctx.SpOffset -= g.Word
+	code += utils.Format("mov " + DerefAddr(target, addrReg.Name) + ", " + g.SubReg(valReg.Name, DerefAddr(target, addrReg.Name)) + "; 通过指针赋值")
+	ctx.Reg.Free(varBlock.Value)
+	ctx.Reg.Free(owner)
+	return code
+}
+
+// Zero 将 ref 处的 size 字节清零，先按字长写入，余下的部分按 2 的幂依次减半
+func (g *Gen) Zero(ref string, size int) (code string) {
+	for off := 0; off < size; {
+		n := g.Word
+		for n > size-off {
+			n /= 2
+		}
+		code += utils.Format("mov " + utils.GetLengthName(n) + RefAdd(ref, off) + ", 0; 清零")
+		off += n
+	}
+	return code
+}
@@ -0,0 +1,181 @@
+package nasm
+
+import (
+	"cuteify/compile/regmgr"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"sort"
+	"strconv"
+)
+
+// switch 分支代码生成
+
+const (
+	jumpTableMinCases = 4 // 使用跳转表所需的最少分支值数量
+	jumpTableDensity  = 3 // 跳转表允许的最大 值域/分支值数量 比例
+)
+
+// SwitchCase 一个分支值及其跳转目标
+type SwitchCase struct {
+	Value int64
+	Label string
+}
+
+func switchLabel(switchBlock *parser.SwitchBlock) string {
+	return "switch_" + strconv.Itoa(switchBlock.Offset)
+}
+
+func caseLabel(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) string {
+	if caseBlock.IsDefault {
+		return switchLabel(switchBlock) + "_default"
+	}
+	return switchLabel(switchBlock) + "_case_" + strconv.Itoa(caseBlock.Offset)
+}
+
+func (g *Gen) Switch(switchBlock *parser.SwitchBlock) (code string) {
+	if switchBlock == nil {
+		return ""
+	}
+
+	g.Ctx.SwitchCount++
+	switchBlock.Offset = g.Ctx.SwitchCount
+	endLabel := switchLabel(switchBlock) + "_end"
+
+	// break 跳出 switch，continue 仍作用于外层循环
+	continueLabel := ""
+	if loop, ok := g.Ctx.CurrentLoop(); ok {
+		continueLabel = loop.Continue
+	}
+	g.Ctx.PushLoop(continueLabel, endLabel)
+
+	// 没有匹配的分支时跳转到 default，没有 default 则跳出
+	fallback := endLabel
+	if switchBlock.Default != nil {
+		fallback = caseLabel(switchBlock, switchBlock.Default)
+	}
+
+	var cases []SwitchCase
+	for _, caseBlock := range switchBlock.Cases {
+		for _, v := range caseBlock.Values {
+			cases = append(cases, SwitchCase{Value: v.CaseValue(), Label: caseLabel(switchBlock, caseBlock)})
+		}
+	}
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Value < cases[j].Value })
+
+	code += utils.Format("; ---- switch开始 ----")
+	if typeSys.CheckTypeType(switchBlock.Value.Type, "bool") {
+		return code + g.boolSwitch(switchBlock, cases, fallback)
+	}
+
+	// 匹配值存入累加器
+	accReg := &regmgr.Reg{Name: g.Acc, RegIndex: 0}
+	g.Ctx.Reg.Force(accReg, g.Ctx.Now, switchBlock.Value)
+	if accReg.StoreCode != "" {
+		code += utils.Format(accReg.StoreCode)
+	}
+	if value := switchBlock.Value; value.Var != nil && value.Var.Value == nil && value.Type.Size() < g.Word {
+		// 窄类型变量需要扩展到整个寄存器再比较
+		code += utils.Format(g.Load(g.Acc, value.Type, g.VarAddr(value.Var)) + "; switch值扩展后存入" + g.Acc)
+	} else {
+		code += g.Expr(value, g.Acc, "switch值存入"+g.Acc)
+	}
+	g.Ctx.Reg.Free(switchBlock.Value)
+
+	if UseJumpTable(cases) {
+		code += g.JumpTable(switchLabel(switchBlock)+"_table", cases, fallback)
+	} else {
+		code += g.CompareChain(cases, fallback)
+	}
+	return code
+}
+
+// UseJumpTable 分支值足够多且足够稠密时使用跳转表，cases 按分支值升序排列
+func UseJumpTable(cases []SwitchCase) bool {
+	if len(cases) < jumpTableMinCases {
+		return false
+	}
+	span := cases[len(cases)-1].Value - cases[0].Value + 1
+	return span <= int64(len(cases)*jumpTableDensity)
+}
+
+// JumpTable 按累加器中的值生成带边界检查的跳转表分派，跳转表放在 .rodata 中，标签为 tableLabel
+func (g *Gen) JumpTable(tableLabel string, cases []SwitchCase, fallback string) (code string) {
+	minValue := cases[0].Value
+	span := cases[len(cases)-1].Value - minValue + 1
+
+	if minValue != 0 {
+		code += utils.Format("sub " + g.Acc + ", " + strconv.FormatInt(minValue, 10) + "; 减去最小分支值")
+	}
+	// 无符号比较同时处理小于最小值的情况
+	code += utils.Format("cmp " + g.Acc + ", " + strconv.FormatInt(span-1, 10) + "; 跳转表边界检查")
+	code += utils.Format("ja " + fallback + "; 超出范围")
+	code += utils.Format("jmp [" + tableLabel + "+" + g.Acc + "*" + strconv.Itoa(g.Word) + "]; 跳转表分派")
+
+	code += utils.Format("section .rodata")
+	code += utils.Format("align " + strconv.Itoa(g.Word))
+	code += utils.Format(tableLabel + ":")
+	next := 0
+	for v := minValue; v < minValue+span; v++ {
+		label := fallback
+		if cases[next].Value == v {
+			label = cases[next].Label
+			next++
+		}
+		code += utils.Format(DataInst(g.Word) + " " + label)
+	}
+	code += utils.Format("section .text")
+	return code
+}
+
+// CompareChain 将累加器中的值逐个与分支值比较
+func (g *Gen) CompareChain(cases []SwitchCase, fallback string) (code string) {
+	for _, c := range cases {
+		immCode, value := g.imm(strconv.FormatInt(c.Value, 10))
+		code += immCode
+		code += utils.Format("cmp " + g.Acc + ", " + value)
+		code += utils.Format("je " + c.Label)
+	}
+	code += utils.Format("jmp " + fallback + "; 没有匹配的分支")
+	return code
+}
+
+// boolSwitch 布尔值的 switch：按真假两个目标跳转
+func (g *Gen) boolSwitch(switchBlock *parser.SwitchBlock, cases []SwitchCase, fallback string) (code string) {
+	trueLabel, falseLabel := fallback, fallback
+	for _, c := range cases {
+		if c.Value == 1 {
+			trueLabel = c.Label
+		} else {
+			falseLabel = c.Label
+		}
+	}
+	value := switchBlock.Value
+	isFalseLabel := switchLabel(switchBlock) + "_false"
+
+	if value.IsConst() {
+		if value.Bool {
+			return code + utils.Format("jmp "+trueLabel+"; 常量switch值")
+		}
+		return code + utils.Format("jmp "+falseLabel+"; 常量switch值")
+	}
+	code += g.Expr(value, isFalseLabel, "switch条件")
+	code += utils.Format("jmp " + trueLabel + "; switch值为真")
+	code += utils.Format(isFalseLabel + ":")
+	code += utils.Format("jmp " + falseLabel + "; switch值为假")
+	return code
+}
+
+func (g *Gen) Case(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) string {
+	return utils.Format(caseLabel(switchBlock, caseBlock) + ":")
+}
+
+func (g *Gen) EndCase(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) string {
+	// 分支之间不贯穿
+	return utils.Format("jmp " + switchLabel(switchBlock) + "_end; 分支结束")
+}
+
+func (g *Gen) EndSwitch(switchBlock *parser.SwitchBlock) string {
+	g.Ctx.PopLoop()
+	return utils.Format(switchLabel(switchBlock) + "_end: ; switch结束")
+}
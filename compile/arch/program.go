@@ -0,0 +1,72 @@
+package arch
+
+import (
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"strings"
+)
+
+// 不生成汇编的后端需要在文件开头声明整个程序，这里提供它们共用的程序遍历
+
+// Collect 收集程序中的结构体（包括泛型实例）、接口、要生成代码的函数和全局变量，
+// 函数的取舍与编译器一致：跳过 build os 不匹配的函数和未被使用的函数，泛型只生成实例
+func Collect(root *parser.Node) (structs []*typeSys.StructType, ifaces []*typeSys.InterfaceType, funcs []*parser.FuncBlock, globals []*parser.VarBlock) {
+	var addFunc func(n *parser.Node)
+	addFunc = func(n *parser.Node) {
+		funcBlock := n.Value.(*parser.FuncBlock)
+		if funcBlock.Generic != nil {
+			for _, inst := range funcBlock.Generic.Instances {
+				addFunc(inst)
+			}
+			return
+		}
+		if funcBlock.Useful || funcBlock.Name.String() == "main" {
+			funcs = append(funcs, funcBlock)
+		}
+	}
+	for _, n := range root.Children {
+		if n.Ignore {
+			continue
+		}
+		switch v := n.Value.(type) {
+		case *parser.FuncBlock:
+			ignored := false
+			for _, flag := range v.BuildFlags {
+				ignored = ignored || flag.Type == "os" && flag.Ignore
+			}
+			if !ignored {
+				addFunc(n)
+			}
+		case *parser.StructBlock:
+			if v.Generic == nil {
+				structs = append(structs, v.Type().(*typeSys.StructType))
+				continue
+			}
+			for _, inst := range v.Generic.Instances {
+				structs = append(structs, inst.Value.(*parser.StructBlock).Type().(*typeSys.StructType))
+			}
+			for _, method := range v.MethodTemplates {
+				for _, inst := range method.Generic.Instances {
+					addFunc(inst)
+				}
+			}
+		case *parser.InterfaceBlock:
+			ifaces = append(ifaces, v.Type().(*typeSys.InterfaceType))
+		case *parser.VarBlock:
+			if v.IsGlobal {
+				globals = append(globals, v)
+			}
+		}
+	}
+	return
+}
+
+// ExtName 返回 build ext("name") 指定的外部函数名，不是外部函数时为空
+func ExtName(funcBlock *parser.FuncBlock) string {
+	for _, flag := range funcBlock.BuildFlags {
+		if flag.Type == "ext" {
+			return strings.Trim(strings.TrimSpace(flag.Ext), "()\"`'")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,151 @@
+package riscv
+
+import (
+	"cuteify/compile/data"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// boundsPanicLabel 下标越界时跳转到的运行时例程
+const boundsPanicLabel = "cute_panic_index"
+
+// Data 输出代码之后的内容：运行时例程，以及 .rodata（虚表、字符串字面量）、.data（有初始值的全局变量）、.bss（零初始化的全局变量）
+func (a *RISCV) Data() (code string) {
+	// 运行时例程仍位于代码段，且可能引用字符串字面量，需先于 .rodata 输出
+	code += a.boundsPanic()
+
+	if rodata := a.vtables() + a.stringLits(); rodata != "" {
+		code += utils.Format(".section .rodata")
+		code += rodata
+	}
+
+	var initialized, zeroed string
+	for _, g := range a.ctx.Data.Globals {
+		if g.IsZero() {
+			zeroed += utils.Format(".balign " + strconv.Itoa(g.Align()))
+			zeroed += utils.Format(g.Label + ":")
+			zeroed += utils.Format(".zero " + strconv.Itoa(g.Size()))
+			continue
+		}
+		initialized += utils.Format(".balign " + strconv.Itoa(g.Align()))
+		initialized += utils.Format(g.Label + ":")
+		initialized += globalItems(g)
+	}
+	if initialized != "" {
+		code += utils.Format(".data")
+		code += initialized
+	}
+	if zeroed != "" {
+		code += utils.Format(".bss")
+		code += zeroed
+	}
+	return code
+}
+
+// boundsPanic 输出越界 panic 例程：向 stderr 输出提示并以退出码 2 结束进程，只在用到时输出
+func (a *RISCV) boundsPanic() (code string) {
+	if !a.ctx.BoundsPanic {
+		return ""
+	}
+	msg := "panic: index out of range\n"
+	code += utils.Format(boundsPanicLabel + ":")
+	code += utils.Format("li a0, 2  # stderr")
+	code += utils.Format("la a1, " + a.ctx.Data.Intern(msg))
+	code += utils.Format("li a2, " + strconv.Itoa(len(msg)))
+	code += utils.Format("li a7, 64  # write")
+	code += utils.Format("ecall")
+	code += utils.Format("li a0, 2")
+	code += utils.Format("li a7, 93  # exit")
+	code += utils.Format("ecall")
+	return code
+}
+
+// vtable 登记结构体实现接口时的虚表并返回其标签，标签与汇编后端一致
+func (a *RISCV) vtable(structType *typeSys.StructType, iface *typeSys.InterfaceType) string {
+	label := utils.ToNASMName("vtable_" + structType.Type() + "_" + iface.Type())
+	a.ctx.AddVTable(label, structType, iface)
+	return label
+}
+
+// vtables 输出用到的虚表，每个槽位为对应结构体方法的地址，没有生成代码的方法为 0
+func (a *RISCV) vtables() (code string) {
+	word := dataInst(a.xlen)
+	for _, vt := range a.ctx.VTables {
+		code += utils.Format(".balign " + strconv.Itoa(a.xlen))
+		code += utils.Format(vt.Label + ":")
+		for _, m := range vt.Iface.Methods {
+			method := parser.FindMethod(vt.Struct, m.(*parser.FuncBlock).Name.Last())
+			if method == nil || !method.Useful {
+				code += utils.Format(word + " 0")
+				continue
+			}
+			code += utils.Format(word + " " + funcLabel(method))
+		}
+	}
+	return code
+}
+
+// stringLits 输出以 0 结尾的字符串字面量
+func (a *RISCV) stringLits() (code string) {
+	for _, str := range a.ctx.Data.Strings {
+		code += utils.Format(str.Label + ": .asciz " + asciz(str.Value))
+	}
+	return code
+}
+
+// globalItems 按偏移输出全局变量的初始值，条目之间用零字节填充
+func globalItems(g *data.Global) (code string) {
+	offset := 0
+	for _, item := range g.Items {
+		if item.Offset > offset {
+			code += utils.Format(".zero " + strconv.Itoa(item.Offset-offset))
+		}
+		inst := dataInst(item.Size)
+		// 浮点数的初始值带有小数点
+		if strings.Contains(item.Value, ".") && !strings.HasPrefix(item.Value, "str_") {
+			inst = ".double"
+			if item.Size == 4 {
+				inst = ".float"
+			}
+		}
+		code += utils.Format(inst + " " + item.Value)
+		offset = item.Offset + item.Size
+	}
+	if offset < g.Size() {
+		code += utils.Format(".zero " + strconv.Itoa(g.Size()-offset))
+	}
+	return code
+}
+
+// dataInst 返回对应字节数的数据定义伪指令
+func dataInst(size int) string {
+	switch size {
+	case 1:
+		return ".byte"
+	case 2:
+		return ".half"
+	case 8:
+		return ".dword"
+	}
+	return ".word"
+}
+
+// asciz 返回 .asciz 的字符串操作数，引号、反斜杠与不可打印字符使用八进制转义
+func asciz(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch >= ' ' && ch <= '~' && ch != '"' && ch != '\\' {
+			b.WriteByte(ch)
+			continue
+		}
+		b.WriteString(fmt.Sprintf("\\%03o", ch))
+	}
+	b.WriteByte('"')
+	return b.String()
+}
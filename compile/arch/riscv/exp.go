@@ -0,0 +1,384 @@
+package riscv
+
+import (
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"math/bits"
+	"strconv"
+)
+
+// alloc 分配一个临时寄存器并锁定，寄存器不足时寄存器管理器只会选用未被占用的 callee-saved 寄存器
+func (a *RISCV) alloc() string {
+	key := &parser.Expression{}
+	r := a.ctx.Reg.Get(a.ctx.Now, key, false)
+	r.Locked = true
+	a.ctx.Reg.Record[key] = r
+	return r.Name
+}
+
+// release 释放由 alloc 分配的寄存器
+func (a *RISCV) release(reg string) {
+	for key, r := range a.ctx.Reg.Record {
+		if r.Name == reg {
+			a.ctx.Reg.Free(key)
+			delete(a.ctx.Reg.Record, key)
+		}
+	}
+}
+
+// imm 返回按字长截断后的整数立即数
+func (a *RISCV) imm(v int64) string {
+	if a.xlen == 4 {
+		v = int64(int32(v))
+	}
+	return strconv.FormatInt(v, 10)
+}
+
+// value 计算表达式，返回存放结果的寄存器：整数按类型扩展到整个寄存器，布尔值为 0 或 1，
+// 结构体、数组、切片与接口值为其地址
+func (a *RISCV) value(exp *parser.Expression) (code, reg string) {
+	if exp.IsConst() {
+		return a.constant(exp)
+	}
+	switch {
+	case exp.Unary != "":
+		return a.unary(exp)
+	case exp.Index != nil:
+		code, reg = a.elemAddr(exp)
+		return code + a.load(reg, exp.Type, 0, reg), reg
+	case exp.Field != nil:
+		code, reg = a.addr(exp.Left)
+		return code + a.load(reg, exp.Type, exp.StructField().Offset, reg), reg
+	case exp.Separator != "":
+		return a.binary(exp)
+	case exp.Call != nil:
+		return a.call(exp.Call)
+	case exp.Var != nil:
+		if exp.Var.Value != nil {
+			// 赋值表达式的值为赋值后的变量
+			code = a.assign(exp.Var)
+			if exp.Var.Store != nil {
+				storeCode, reg := a.value(exp.Var.Store)
+				return code + storeCode, reg
+			}
+		}
+		varCode, base, offset := a.varRef(exp.Var)
+		reg = a.alloc()
+		return code + varCode + a.load(reg, a.varType(exp.Var), offset, base), reg
+	}
+	panic("编译器内部错误: 无法计算的表达式")
+}
+
+// stmt 生成表达式语句，丢弃表达式的值
+func (a *RISCV) stmt(exp *parser.Expression) string {
+	if exp.Var != nil && exp.Var.Value != nil && exp.Separator == "" && exp.Unary == "" && exp.Index == nil {
+		return a.assign(exp.Var)
+	}
+	if exp.Call != nil && exp.Separator == "" && exp.Unary == "" && exp.Index == nil && len(exp.Call.Func.Return) == 0 {
+		code, _ := a.call(exp.Call)
+		return code
+	}
+	code, reg := a.value(exp)
+	a.release(reg)
+	return code
+}
+
+// constant 将常量载入寄存器，字符串常量为其在 .rodata 中的地址
+func (a *RISCV) constant(exp *parser.Expression) (code, reg string) {
+	reg = a.alloc()
+	switch {
+	case exp.Type == nil || typeSys.GetTypeType(exp.Type) == "bool":
+		// 折叠后的比较结果没有类型
+		v := "0"
+		if exp.Bool {
+			v = "1"
+		}
+		return utils.Format("li " + reg + ", " + v), reg
+	case typeSys.GetTypeType(exp.Type) == "string":
+		return utils.Format("la " + reg + ", " + a.ctx.Data.Intern(exp.StringVal)), reg
+	case typeSys.GetTypeType(exp.Type) == "float":
+		panic("编译器内部错误: RISC-V 后端不支持浮点数")
+	}
+	v := int64(exp.Num)
+	if exp.Num >= 1<<63 {
+		v = int64(uint64(exp.Num))
+	}
+	return utils.Format("li " + reg + ", " + a.imm(v)), reg
+}
+
+// binary 二元运算，比较的结果为 0 或 1，算术运算的结果按表达式类型截断，
+// 指针加减的偏移量在解析时已按元素大小换算为字节数
+func (a *RISCV) binary(exp *parser.Expression) (code, reg string) {
+	switch exp.Separator {
+	case "&&", "||":
+		return a.logic(exp)
+	case "^":
+		panic("编译器内部错误: RISC-V 后端不支持非常量的 ^ 运算")
+	}
+	code, reg = a.value(exp.Left)
+	if inst, ok := immInsts[exp.Separator]; ok && exp.Right.IsConst() && exp.Right.Type != nil {
+		if v := int64(exp.Right.Num); float64(v) == exp.Right.Num && fitsImm(int(v)) && fitsImm(-int(v)) {
+			if exp.Separator == "-" {
+				v = -v
+			}
+			if exp.Separator == ">>" && !signed(exp.Type) {
+				inst = "srli"
+			}
+			code += utils.Format(inst + " " + reg + ", " + reg + ", " + strconv.FormatInt(v, 10))
+			return code + a.narrow(reg, exp.Type), reg
+		}
+	}
+	rightCode, right := a.value(exp.Right)
+	code += rightCode
+	defer a.release(right)
+
+	switch exp.Separator {
+	case "==", "!=", "<", ">", "<=", ">=":
+		return code + a.compare(exp.Separator, reg, right, signed(exp.OperandType())), reg
+	}
+	s := signed(exp.Type)
+	pick := func(sop, uop string) string {
+		if s {
+			return sop
+		}
+		return uop
+	}
+	var op string
+	switch exp.Separator {
+	case "+":
+		op = "add"
+	case "-":
+		op = "sub"
+	case "*":
+		op = "mul"
+	case "/":
+		op = pick("div", "divu")
+	case "%":
+		op = pick("rem", "remu")
+	case "&":
+		op = "and"
+	case "|":
+		op = "or"
+	case "<<":
+		op = "sll"
+	case ">>":
+		op = pick("sra", "srl")
+	default:
+		panic("编译器内部错误: 未知的运算符 " + exp.Separator)
+	}
+	code += utils.Format(op + " " + reg + ", " + reg + ", " + right)
+	return code + a.narrow(reg, exp.Type), reg
+}
+
+// immInsts 右侧为小常量时可以使用立即数指令的运算
+var immInsts = map[string]string{
+	"+":  "addi",
+	"-":  "addi",
+	"&":  "andi",
+	"|":  "ori",
+	"<<": "slli",
+	">>": "srai",
+}
+
+// compare 比较 left 与 right，结果 0 或 1 写回 left
+func (a *RISCV) compare(op, left, right string, signed bool) (code string) {
+	slt := "sltu"
+	if signed {
+		slt = "slt"
+	}
+	switch op {
+	case "==", "!=":
+		code += utils.Format("xor " + left + ", " + left + ", " + right)
+		if op == "==" {
+			return code + utils.Format("seqz "+left+", "+left)
+		}
+		return code + utils.Format("snez "+left+", "+left)
+	case "<":
+		return utils.Format(slt + " " + left + ", " + left + ", " + right)
+	case ">":
+		return utils.Format(slt + " " + left + ", " + right + ", " + left)
+	case "<=":
+		code += utils.Format(slt + " " + left + ", " + right + ", " + left)
+	case ">=":
+		code += utils.Format(slt + " " + left + ", " + left + ", " + right)
+	}
+	return code + utils.Format("xori "+left+", "+left+", 1")
+}
+
+// logic 短路求值的 && 与 ||，右侧只在需要时计算
+func (a *RISCV) logic(exp *parser.Expression) (code, reg string) {
+	code, reg = a.value(exp.Left)
+	end := a.newLabel("logic_end")
+	if exp.Separator == "&&" {
+		code += utils.Format("beqz " + reg + ", " + end)
+	} else {
+		code += utils.Format("bnez " + reg + ", " + end)
+	}
+	rightCode, right := a.value(exp.Right)
+	code += rightCode
+	code += utils.Format("mv " + reg + ", " + right)
+	a.release(right)
+	return code + utils.Format(end+":"), reg
+}
+
+// signed 报告类型的值是否按有符号数比较和运算，指针、布尔值与字符串地址按无符号数处理
+func signed(t typeSys.Type) bool {
+	return t != nil && typeSys.CheckTypeType(t, "int")
+}
+
+// unary 一元运算：取地址、解引用、切片长度与类型转换
+func (a *RISCV) unary(exp *parser.Expression) (code, reg string) {
+	switch exp.Unary {
+	case "&":
+		return a.addr(exp.Right)
+	case "*":
+		code, reg = a.value(exp.Right)
+		return code + a.load(reg, exp.Type, 0, reg), reg
+	case "len":
+		code, reg = a.value(exp.Right)
+		return code + a.load(reg, nil, a.xlen, reg), reg
+	case "as":
+		code, reg = a.value(exp.Right)
+		return code + a.convert(reg, exp.Right.Type, exp.Type), reg
+	}
+	panic("编译器内部错误: 未知的一元运算 " + exp.Unary)
+}
+
+// addr 计算可取地址表达式（变量、字段、解引用、下标）的地址，聚合类型的值本身即为地址
+func (a *RISCV) addr(exp *parser.Expression) (code, reg string) {
+	switch {
+	case exp.Unary == "*":
+		return a.value(exp.Right)
+	case exp.Index != nil:
+		return a.elemAddr(exp)
+	case exp.Field != nil:
+		code, reg = a.addr(exp.Left)
+		return code + addi(reg, reg, exp.StructField().Offset), reg
+	case exp.Var != nil && exp.Unary == "" && exp.Separator == "" && exp.Var.Value == nil:
+		return a.varAddr(exp.Var)
+	case isAggregate(exp.Type):
+		return a.value(exp)
+	}
+	panic("编译器内部错误: 表达式不能取地址")
+}
+
+// elemAddr 计算 a[i] 的元素地址，开启越界检查时下标越界跳转到越界 panic 例程
+// 下标可能含有函数调用，因此先于数组地址计算
+func (a *RISCV) elemAddr(exp *parser.Expression) (code, reg string) {
+	index := exp.Index
+	_, array := exp.Left.Type.(*typeSys.ArrayType)
+	check := a.ctx.BoundsCheck && !(array && index.IsConst())
+	var idx string
+	if !index.IsConst() || check {
+		code, idx = a.value(index)
+	}
+
+	baseCode, reg := a.addr(exp.Left)
+	code += baseCode
+	switch t := exp.Left.Type.(type) {
+	case *typeSys.ArrayType:
+		if check {
+			code += utils.Format("li " + scratchReg + ", " + strconv.Itoa(t.Len))
+			code += a.boundsCheck(idx)
+		}
+	case *typeSys.SliceType:
+		if check {
+			code += a.load(scratchReg, nil, a.xlen, reg)
+			code += a.boundsCheck(idx)
+		}
+		code += a.load(reg, nil, 0, reg) // 切片数据地址
+	default:
+		panic("编译器内部错误: 不能对 " + exp.Left.Type.Type() + " 使用下标")
+	}
+
+	size := exp.Type.Size()
+	switch {
+	case idx == "":
+		code += addi(reg, reg, int(index.Num)*size)
+		return code, reg
+	case size&(size-1) == 0:
+		if shift := bits.TrailingZeros(uint(size)); shift > 0 {
+			code += utils.Format("slli " + idx + ", " + idx + ", " + strconv.Itoa(shift))
+		}
+	default:
+		code += utils.Format("li " + scratchReg + ", " + strconv.Itoa(size))
+		code += utils.Format("mul " + idx + ", " + idx + ", " + scratchReg + "  # 下标乘以元素大小")
+	}
+	code += utils.Format("add " + reg + ", " + reg + ", " + idx + "  # 元素地址")
+	a.release(idx)
+	return code, reg
+}
+
+// boundsCheck 下标 idx 按无符号数不小于 scratchReg 中的长度时跳转到越界 panic 例程，负数下标同样视为越界
+func (a *RISCV) boundsCheck(idx string) (code string) {
+	a.ctx.BoundsPanic = true
+	ok := a.newLabel("bounds_ok")
+	code += utils.Format("bltu " + idx + ", " + scratchReg + ", " + ok + "  # 越界检查")
+	code += utils.Format("j " + boundsPanicLabel)
+	code += utils.Format(ok + ":")
+	return code
+}
+
+// assign 生成赋值语句，包括通过指针或下标的赋值
+func (a *RISCV) assign(v *parser.VarBlock) (code string) {
+	var t typeSys.Type
+	if v.Store != nil {
+		t = v.Store.Type
+	} else {
+		t = a.varType(v)
+	}
+	if isAggregate(t) {
+		valueCode, src := a.addr(v.Value)
+		code += valueCode
+		dstCode, dst := a.target(v)
+		code += dstCode
+		code += a.storeAggregate(v.Value, t, src, dst, 0)
+		a.release(dst)
+		a.release(src)
+		return code
+	}
+	valueCode, reg := a.value(v.Value)
+	code += valueCode
+	code += a.convert(reg, v.Value.Type, t)
+	if v.Store == nil {
+		varCode, base, offset := a.varRef(v)
+		code += varCode
+		code += a.store(reg, t, offset, base)
+	} else {
+		dstCode, dst := a.addr(v.Store)
+		code += dstCode
+		code += a.store(reg, t, 0, dst)
+		a.release(dst)
+	}
+	a.release(reg)
+	return code
+}
+
+// target 返回赋值目标的地址
+func (a *RISCV) target(v *parser.VarBlock) (code, reg string) {
+	if v.Store != nil {
+		return a.addr(v.Store)
+	}
+	return a.varAddr(v)
+}
+
+// storeAggregate 将地址 src 处的聚合值写入 dst+offset：结构体转换为接口时写入 (数据地址, 虚表)，
+// 数组转换为切片时写入 (数据地址, 长度)，其余按类型大小复制
+func (a *RISCV) storeAggregate(value *parser.Expression, t typeSys.Type, src, dst string, offset int) (code string) {
+	switch to := t.(type) {
+	case *typeSys.InterfaceType:
+		if from, ok := value.Type.(*typeSys.StructType); ok && !from.IsPointer() {
+			code += a.store(src, nil, offset, dst)
+			code += utils.Format("la " + scratchReg + ", " + a.vtable(from, to))
+			return code + a.store(scratchReg, nil, offset+a.xlen, dst)
+		}
+	case *typeSys.SliceType:
+		if from, ok := value.Type.(*typeSys.ArrayType); ok {
+			code += a.store(src, nil, offset, dst)
+			code += utils.Format("li " + scratchReg + ", " + strconv.Itoa(from.Len))
+			return code + a.store(scratchReg, nil, offset+a.xlen, dst)
+		}
+	}
+	return a.copyMem(dst, offset, src, t.Size(), typeSys.AlignOf(t))
+}
@@ -0,0 +1,233 @@
+package riscv
+
+import (
+	"cuteify/compile/arch"
+	"cuteify/compile/regmgr"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strconv"
+)
+
+// 栈帧布局（s0 为进入函数时的 sp）：
+//
+//	s0+xlen*k        压栈传入的第 k 个参数槽位
+//	s0-xlen ...      保存区：ra、s0、s1（方法）与 s2-s11，按 16 字节对齐
+//	保存区之下        经寄存器传入的参数，之后为局部变量
+
+// argLoc 参数的传递位置
+type argLoc struct {
+	reg   int // 第一个槽位对应的参数寄存器下标，压栈传递时为 -1
+	stack int // 压栈传递时第一个槽位在栈参数区中的下标
+	slots int // 占用的字长槽位数
+}
+
+// funcLabel 返回函数的汇编标签（函数名 + 参数个数，main 除外），外部函数为 build ext 指定的名称
+func funcLabel(funcBlock *parser.FuncBlock) string {
+	if ext := arch.ExtName(funcBlock); ext != "" {
+		return ext
+	}
+	name := funcBlock.Name.String()
+	if name != "main" {
+		name = name + strconv.Itoa(len(funcBlock.Args))
+	}
+	return symbol(name)
+}
+
+// symbol 返回 GNU as 的符号写法，名称中含有字母、数字、. 、_ 和 $ 以外的字符（如泛型实例的方括号）时加引号
+func symbol(name string) string {
+	for i, ch := range name {
+		ok := ch == '.' || ch == '_' || ch == '$' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || i > 0 && ch >= '0' && ch <= '9'
+		if !ok {
+			return strconv.Quote(name)
+		}
+	}
+	return name
+}
+
+// alignStack 将字节数向上对齐到 16 字节
+func alignStack(size int) int {
+	return typeSys.AlignUp(size, stackAlignment)
+}
+
+// argSlots 返回类型为 t 的参数占用的字长槽位数，切片和接口值为两个
+func (a *RISCV) argSlots(t typeSys.Type) int {
+	if n := (t.Size() + a.xlen - 1) / a.xlen; n > 0 {
+		return n
+	}
+	return 1
+}
+
+// assignArgs 为参数分配传递位置，方法的接收者地址固定占用 a0
+// 参数的全部槽位能放入剩余的参数寄存器时经寄存器传递，否则整体压栈，之后的参数仍可使用剩余的寄存器
+func (a *RISCV) assignArgs(funcBlock *parser.FuncBlock) (locs []argLoc, regSlots, stackSlots int) {
+	if funcBlock.Class != nil {
+		regSlots = 1
+	}
+	for _, arg := range funcBlock.Args {
+		loc := argLoc{reg: -1, slots: a.argSlots(arg.Type)}
+		if regSlots+loc.slots <= len(regmgr.RISCVArgRegs) {
+			loc.reg = regSlots
+			regSlots += loc.slots
+		} else {
+			loc.stack = stackSlots
+			stackSlots += loc.slots
+		}
+		locs = append(locs, loc)
+	}
+	return locs, regSlots, stackSlots
+}
+
+// prologue 生成函数序言：保存 ra、s0 与 callee-saved 寄存器，建立帧指针，分配局部变量空间，
+// 经寄存器传入的参数存入栈帧；方法的接收者地址保存在 selfReg 中
+func (a *RISCV) prologue(funcBlock *parser.FuncBlock) (code string) {
+	a.saved = []string{"ra", "s0"}
+	if funcBlock.Class != nil {
+		a.saved = append(a.saved, selfReg)
+	}
+	for _, r := range a.ctx.Reg.Regs {
+		if r.CalleeSave {
+			a.saved = append(a.saved, r.Name)
+		}
+	}
+	size := alignStack(len(a.saved) * a.xlen)
+	code += utils.Format("addi sp, sp, -" + strconv.Itoa(size))
+	for k, r := range a.saved {
+		code += a.store(r, nil, size-(k+1)*a.xlen, "sp")
+	}
+	code += utils.Format("addi s0, sp, " + strconv.Itoa(size) + "  # 帧指针")
+	if funcBlock.Class != nil {
+		code += utils.Format("mv " + selfReg + ", a0  # self地址")
+	}
+
+	offset := -size
+	locs, _, _ := a.assignArgs(funcBlock)
+	for i, arg := range funcBlock.Args {
+		if locs[i].reg < 0 {
+			arg.Offset = a.xlen * locs[i].stack
+			continue
+		}
+		offset -= a.xlen * locs[i].slots
+		arg.Offset = offset
+	}
+	if locals := arch.SetupVarOffsets(a.ctx.Now, stackAlignment, offset) - size; locals > 0 {
+		code += addi("sp", "sp", -locals)
+	}
+	for i, arg := range funcBlock.Args {
+		for j := 0; j < locs[i].slots && locs[i].reg >= 0; j++ {
+			code += a.store(regmgr.RISCVArgRegs[locs[i].reg+j], nil, arg.Offset+a.xlen*j, "s0")
+		}
+	}
+	code += utils.Format("# ---- 函数开始 ----")
+	return code
+}
+
+// epilogue 生成函数尾声：恢复保存的寄存器并返回
+func (a *RISCV) epilogue() (code string) {
+	size := alignStack(len(a.saved) * a.xlen)
+	code += utils.Format("# ---- 退出函数 ----")
+	code += utils.Format("addi sp, s0, -" + strconv.Itoa(size))
+	for k, r := range a.saved {
+		code += a.load(r, nil, size-(k+1)*a.xlen, "sp")
+	}
+	code += utils.Format("addi sp, sp, " + strconv.Itoa(size))
+	return code + utils.Format("ret")
+}
+
+// call 生成函数调用，返回存放返回值的寄存器（无返回值时为空）
+// 调用前把仍在使用的临时寄存器保存到栈上；实参依次计算并写入栈上的暂存区，
+// 压栈传递的槽位位于暂存区底部（即 call 时的 0(sp) 起），经寄存器传递的槽位在其上方，全部计算完后再载入 a0-a7
+func (a *RISCV) call(call *parser.CallBlock) (code, reg string) {
+	var live []string
+	for _, r := range a.ctx.Reg.Regs {
+		if r.Using && !r.CalleeSave {
+			live = append(live, r.Name)
+		}
+	}
+	saveSize := alignStack(len(live) * a.xlen)
+	if saveSize > 0 {
+		code += utils.Format("addi sp, sp, -" + strconv.Itoa(saveSize) + "  # 保存临时寄存器")
+		for i, r := range live {
+			code += a.store(r, nil, i*a.xlen, "sp")
+		}
+	}
+
+	locs, regSlots, stackSlots := a.assignArgs(call.Func)
+	area := alignStack((regSlots + stackSlots) * a.xlen)
+	if area > 0 {
+		code += utils.Format("addi sp, sp, -" + strconv.Itoa(area) + "  # 参数暂存区")
+	}
+	regOffset := func(k int) int { return (stackSlots + k) * a.xlen }
+
+	var iface *typeSys.InterfaceType
+	if call.ThisVar != nil {
+		var recv string
+		iface, _ = call.ThisVar.Type.(*typeSys.InterfaceType)
+		if iface != nil {
+			// 接口值的数据地址即接收者地址
+			refCode, base, offset := a.varRef(call.ThisVar)
+			recv = a.alloc()
+			code += refCode + a.load(recv, nil, offset, base)
+		} else {
+			var recvCode string
+			recvCode, recv = a.varAddr(call.ThisVar)
+			code += recvCode
+		}
+		code += a.store(recv, nil, regOffset(0), "sp")
+		a.release(recv)
+	}
+	for i, arg := range call.Args {
+		if arg == nil || i >= len(locs) {
+			continue
+		}
+		offset := a.xlen * locs[i].stack
+		if locs[i].reg >= 0 {
+			offset = regOffset(locs[i].reg)
+		}
+		code += a.storeArg(arg, offset)
+	}
+	for k := 0; k < regSlots; k++ {
+		code += a.load(regmgr.RISCVArgRegs[k], nil, regOffset(k), "sp")
+	}
+
+	if iface != nil {
+		refCode, base, offset := a.varRef(call.ThisVar)
+		code += refCode
+		code += a.load(scratchReg, nil, offset+a.xlen, base) // 虚表地址
+		code += a.load(scratchReg, nil, parser.MethodSlot(iface, call.Name.Last())*a.xlen, scratchReg)
+		code += utils.Format("jalr " + scratchReg + "  # 动态分派" + call.Name.String())
+	} else {
+		code += utils.Format("call " + funcLabel(call.Func))
+	}
+	if area > 0 {
+		code += utils.Format("addi sp, sp, " + strconv.Itoa(area))
+	}
+	if len(call.Func.Return) > 0 {
+		reg = a.alloc()
+		code += utils.Format("mv " + reg + ", a0  # 返回值")
+	}
+	if saveSize > 0 {
+		for i, r := range live {
+			code += a.load(r, nil, i*a.xlen, "sp")
+		}
+		code += utils.Format("addi sp, sp, " + strconv.Itoa(saveSize) + "  # 恢复临时寄存器")
+	}
+	return code, reg
+}
+
+// storeArg 计算实参并按形参类型写入暂存区 offset(sp) 处，标量按整个字长写入
+func (a *RISCV) storeArg(arg *parser.ArgBlock, offset int) (code string) {
+	if isAggregate(arg.Type) {
+		valueCode, src := a.addr(arg.Value)
+		code += valueCode
+		code += a.storeAggregate(arg.Value, arg.Type, src, "sp", offset)
+		a.release(src)
+		return code
+	}
+	valueCode, reg := a.value(arg.Value)
+	code += valueCode
+	code += a.convert(reg, arg.Value.Type, arg.Type)
+	code += a.store(reg, nil, offset, "sp")
+	a.release(reg)
+	return code
+}
@@ -0,0 +1,259 @@
+package riscv
+
+import (
+	"cuteify/compile/data"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strconv"
+)
+
+// isAggregate 报告类型的值是否按地址处理：结构体、数组、切片与接口值在寄存器中为其地址
+func isAggregate(t typeSys.Type) bool {
+	if t == nil || t.IsPointer() {
+		return false
+	}
+	switch t.(type) {
+	case *typeSys.StructType, *typeSys.ArrayType, *typeSys.SliceType, *typeSys.InterfaceType:
+		return true
+	}
+	return false
+}
+
+// fitsImm 判断偏移量能否作为 12 位有符号立即数
+func fitsImm(offset int) bool {
+	return offset >= -2048 && offset < 2048
+}
+
+// mem 返回 base+offset 处的内存操作数，偏移量超出 12 位立即数范围时先用 scratchReg 算出地址
+func mem(offset int, base string) (code, operand string) {
+	if fitsImm(offset) {
+		return "", strconv.Itoa(offset) + "(" + base + ")"
+	}
+	code += utils.Format("li " + scratchReg + ", " + strconv.Itoa(offset))
+	code += utils.Format("add " + scratchReg + ", " + base + ", " + scratchReg)
+	return code, "0(" + scratchReg + ")"
+}
+
+// addi 返回 dst = src + imm 的指令，立即数超出范围时经 scratchReg 相加
+func addi(dst, src string, imm int) string {
+	if fitsImm(imm) {
+		if imm == 0 && dst == src {
+			return ""
+		}
+		return utils.Format("addi " + dst + ", " + src + ", " + strconv.Itoa(imm))
+	}
+	return utils.Format("li "+scratchReg+", "+strconv.Itoa(imm)) + utils.Format("add "+dst+", "+src+", "+scratchReg)
+}
+
+// loadInst 返回按类型 t 从内存载入寄存器的指令，窄类型按符号扩展或零扩展
+func (a *RISCV) loadInst(t typeSys.Type) string {
+	size := a.xlen
+	signed := false
+	if t != nil && !t.IsPointer() {
+		size = t.Size()
+		signed = typeSys.CheckTypeType(t, "int")
+	}
+	switch size {
+	case 1:
+		return map[bool]string{true: "lb", false: "lbu"}[signed]
+	case 2:
+		return map[bool]string{true: "lh", false: "lhu"}[signed]
+	case 4:
+		if a.xlen == 8 && !signed {
+			return "lwu"
+		}
+		return "lw"
+	}
+	return "ld"
+}
+
+// storeInst 返回按字节数写入内存的指令
+func storeInst(size int) string {
+	switch size {
+	case 1:
+		return "sb"
+	case 2:
+		return "sh"
+	case 4:
+		return "sw"
+	}
+	return "sd"
+}
+
+// wordLoad 与 wordStore 返回读写一个字长的指令
+func (a *RISCV) wordLoad() string {
+	if a.xlen == 8 {
+		return "ld"
+	}
+	return "lw"
+}
+
+func (a *RISCV) wordStore() string {
+	if a.xlen == 8 {
+		return "sd"
+	}
+	return "sw"
+}
+
+// load 从 base+offset 按类型 t 载入 reg，聚合类型只计算地址
+func (a *RISCV) load(reg string, t typeSys.Type, offset int, base string) string {
+	if isAggregate(t) {
+		return addi(reg, base, offset)
+	}
+	code, operand := mem(offset, base)
+	return code + utils.Format(a.loadInst(t)+" "+reg+", "+operand)
+}
+
+// store 将 reg 按类型 t 的宽度写入 base+offset
+func (a *RISCV) store(reg string, t typeSys.Type, offset int, base string) string {
+	size := a.xlen
+	if t != nil && !t.IsPointer() {
+		size = t.Size()
+	}
+	code, operand := mem(offset, base)
+	return code + utils.Format(storeInst(size)+" "+reg+", "+operand)
+}
+
+// copyMem 将 src 处 size 字节复制到 dst+offset 处，按 align 选择每次复制的宽度
+func (a *RISCV) copyMem(dst string, offset int, src string, size, align int) (code string) {
+	tmp := a.alloc()
+	for done := 0; done < size; {
+		width := a.xlen
+		for width > align || width > size-done {
+			width /= 2
+		}
+		t := typeSys.GetSystemType("u" + strconv.Itoa(width*8))
+		code += a.load(tmp, t, done, src)
+		code += a.store(tmp, t, offset+done, dst)
+		done += width
+	}
+	a.release(tmp)
+	return code
+}
+
+// zeroMem 将 base 加 offset 处的 size 字节清零，按不超过对齐与字长的宽度写入 zero 寄存器
+func (a *RISCV) zeroMem(base string, offset, size, align int) (code string) {
+	for done := 0; done < size; {
+		width := a.xlen
+		for width > align || width > size-done {
+			width /= 2
+		}
+		t := typeSys.GetSystemType("u" + strconv.Itoa(width*8))
+		code += a.store("zero", t, offset+done, base)
+		done += width
+	}
+	return code
+}
+
+// varRef 返回变量（或其字段）的基址寄存器与偏移：局部变量与参数相对于 s0，self 相对于 selfReg，
+// 全局变量的地址先用 la 载入 scratchReg
+func (a *RISCV) varRef(v *parser.VarBlock) (code, base string, offset int) {
+	field := a.fieldOffset(v)
+	switch {
+	case v.IsSelf(a.ctx.CurrentFunc):
+		return "", selfReg, field
+	case data.IsGlobal(v):
+		label := data.GlobalLabel(data.Define(v).Name)
+		if field != 0 {
+			label += "+" + strconv.Itoa(field)
+		}
+		return utils.Format("la " + scratchReg + ", " + label), scratchReg, 0
+	}
+	if v.Define != nil {
+		switch def := v.Define.Value.(type) {
+		case *parser.VarBlock:
+			v.Offset = def.Offset
+		case *parser.ArgBlock:
+			v.Offset = def.Offset
+		}
+	}
+	return "", "s0", v.Offset + field
+}
+
+// varType 返回变量引用（包括字段访问）的类型
+func (a *RISCV) varType(v *parser.VarBlock) typeSys.Type {
+	if v.IsSelf(a.ctx.CurrentFunc) && !v.Name.IsPath() {
+		return a.ctx.CurrentFunc.Class
+	}
+	return v.Type
+}
+
+// fieldOffset 返回字段访问相对于变量起始地址的偏移
+func (a *RISCV) fieldOffset(v *parser.VarBlock) int {
+	if len(v.Name) <= 1 {
+		return 0
+	}
+	var t typeSys.Type
+	switch {
+	case v.IsSelf(a.ctx.CurrentFunc):
+		t = a.ctx.CurrentFunc.Class
+	case v.Define != nil:
+		switch def := v.Define.Value.(type) {
+		case *parser.VarBlock:
+			t = def.Type
+		case *parser.ArgBlock:
+			t = def.Type
+		}
+	}
+	if t == nil {
+		return 0
+	}
+	offset := 0
+	for _, name := range v.Name[1:] {
+		structBlock, ok := a.ctx.GetStruct(t.Type())
+		if !ok {
+			panic("编译器内部错误: 未注册的结构体 " + t.Type())
+		}
+		field := structBlock.GetFieldByName(name)
+		if field == nil {
+			panic("编译器内部错误: 结构体 " + t.Type() + " 没有字段 " + name)
+		}
+		offset += field.Offset
+		t = field.Type
+	}
+	return offset
+}
+
+// varAddr 将变量（或其字段）的地址载入新分配的寄存器
+func (a *RISCV) varAddr(v *parser.VarBlock) (code, reg string) {
+	code, base, offset := a.varRef(v)
+	reg = a.alloc()
+	return code + addi(reg, base, offset), reg
+}
+
+// narrow 将寄存器中的整数截断到类型 t 的宽度并按其符号扩展回整个寄存器
+func (a *RISCV) narrow(reg string, t typeSys.Type) string {
+	bits, signed, ok := typeSys.IntInfo(t)
+	if !ok && t != nil && typeSys.GetTypeType(t) == "bool" {
+		bits, ok = 8, true
+	}
+	if !ok || bits >= a.xlen*8 {
+		return ""
+	}
+	shift := strconv.Itoa(a.xlen*8 - bits)
+	switch {
+	case bits == 32 && signed:
+		return utils.Format("sext.w " + reg + ", " + reg + "  # 截断为" + t.Type())
+	case bits == 8 && !signed:
+		return utils.Format("andi " + reg + ", " + reg + ", 255  # 截断为" + t.Type())
+	case signed:
+		return utils.Format("slli "+reg+", "+reg+", "+shift) + utils.Format("srai "+reg+", "+reg+", "+shift+"  # 截断为"+t.Type())
+	}
+	return utils.Format("slli "+reg+", "+reg+", "+shift) + utils.Format("srli "+reg+", "+reg+", "+shift+"  # 截断为"+t.Type())
+}
+
+// convert 将寄存器中按 from 类型表示的值转换为 to 类型，to 能表示 from 的全部取值时寄存器中的值不变，
+// 否则按 to 截断并重新扩展
+func (a *RISCV) convert(reg string, from, to typeSys.Type) string {
+	toBits, toSigned, ok := typeSys.IntInfo(to)
+	if !ok {
+		return ""
+	}
+	if fromBits, fromSigned, ok := typeSys.IntInfo(from); ok {
+		if fromSigned == toSigned && toBits >= fromBits || !fromSigned && toSigned && toBits > fromBits {
+			return ""
+		}
+	}
+	return a.narrow(reg, to)
+}
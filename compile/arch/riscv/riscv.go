@@ -0,0 +1,248 @@
+// Package riscv 将 AST 转换为 RISC-V（rv64 / rv32）的 GNU as 汇编，程序在 Linux 上经 ecall 退出。
+//
+// 局部变量位于以 s0 为帧指针的栈帧中，表达式的中间值由寄存器管理器分配在临时寄存器中，
+// if、循环与 switch 用条件分支实现。参数按槽位经 a0-a7 传递，放不下的参数压栈；
+// 方法的接收者地址占第一个参数寄存器，进入函数后保存在 s1 中。
+// 寄存器中的整数总是按其类型扩展到整个寄存器（有符号数符号扩展，无符号数零扩展）。
+package riscv
+
+import (
+	"cuteify/compile/arch"
+	"cuteify/compile/context"
+	"cuteify/compile/data"
+	"cuteify/compile/regmgr"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strconv"
+)
+
+const (
+	selfReg    = "s1" // 方法中保存接收者地址的寄存器，callee-saved 且不参与寄存器分配
+	scratchReg = "t6" // 临时寄存器，不参与寄存器分配，用于大偏移量、全局变量地址与常量比较
+
+	stackAlignment = 16 // psABI 要求 sp 始终按 16 字节对齐
+)
+
+// RISCV 生成 RISC-V 汇编的后端，同时实现 arch.Arch 与 arch.Syntax
+type RISCV struct {
+	ctx  *context.Context
+	xlen int // 字长（字节）：rv64 为 8，rv32 为 4
+
+	ext    bool     // 当前函数是否为 build ext 声明的外部函数（不生成函数体）
+	saved  []string // 当前函数序言中保存的寄存器，依次存放在保存区的高地址处
+	labels int      // 短路求值、越界检查等生成的局部标签编号
+}
+
+// NewRISCV 创建 RISC-V 后端，xlen 为 8 时生成 rv64 代码，为 4 时生成 rv32 代码
+func NewRISCV(ctx *context.Context, xlen int) *RISCV {
+	a := &RISCV{ctx: ctx, xlen: xlen}
+	ctx.Reg = regmgr.NewRegMgr(regmgr.NewRISCVRegs(xlen), a.GenVarAddr)
+	return a
+}
+
+func (a *RISCV) Info() string {
+	return "rv" + strconv.Itoa(a.xlen*8)
+}
+
+func (a *RISCV) Call(call *parser.CallBlock) string {
+	if call == nil || call.Func == nil {
+		return ""
+	}
+	code, reg := a.call(call)
+	if reg != "" {
+		a.release(reg)
+	}
+	return code
+}
+
+func (a *RISCV) Return(ret *parser.ReturnBlock) (code string) {
+	if a.ext || a.ctx.CurrentFunc == nil {
+		return ""
+	}
+	if ret != nil && len(ret.Value) > 0 {
+		value := ret.Value[0]
+		t := a.ctx.CurrentFunc.Return[0]
+		if isAggregate(t) {
+			panic("编译器内部错误: RISC-V 后端不支持返回 " + t.Type())
+		}
+		valueCode, reg := a.value(value)
+		code += valueCode
+		code += a.convert(reg, value.Type, t)
+		code += utils.Format("mv a0, " + reg + "  # 返回值存入a0")
+		a.release(reg)
+	}
+	return code + a.epilogue()
+}
+
+func (a *RISCV) Func(funcBlock *parser.FuncBlock) string {
+	a.ext = arch.ExtName(funcBlock) != ""
+	if a.ext {
+		return ""
+	}
+	return a.prologue(funcBlock)
+}
+
+// Exp 输出表达式语句，条件由 If、For 等直接生成，不使用 result
+func (a *RISCV) Exp(exp *parser.Expression, result, desc string) string {
+	return a.stmt(exp)
+}
+
+func (a *RISCV) Var(varBlock *parser.VarBlock) (code string) {
+	if varBlock.Value == nil {
+		// 没有初始值的结构体变量清零，字段默认值随后写入
+		if t, ok := varBlock.Type.(*typeSys.StructType); ok && !t.IsPointer() {
+			varCode, base, offset := a.varRef(varBlock)
+			code += varCode
+			code += a.zeroMem(base, offset, t.Size(), typeSys.AlignOf(t))
+		}
+		return code
+	}
+	return a.assign(varBlock)
+}
+
+// exit 条件不成立时跳转到 label
+func (a *RISCV) exit(cond *parser.Expression, label, desc string) (code string) {
+	if cond == nil {
+		return ""
+	}
+	if cond.IsConst() {
+		// 常量条件：恒真无需检查，恒假直接跳出
+		if !cond.Bool {
+			code += utils.Format("j " + label + "  # " + desc)
+		}
+		return code
+	}
+	condCode, reg := a.value(cond)
+	code += condCode
+	code += utils.Format("beqz " + reg + ", " + label + "  # " + desc)
+	a.release(reg)
+	return code
+}
+
+func (a *RISCV) For(forBlock *parser.ForBlock) (code string) {
+	a.ctx.ForCount++
+	forBlock.Offset = a.ctx.ForCount
+	label := "for_" + strconv.Itoa(forBlock.Offset)
+	a.ctx.PushLoop(label+"_continue", label+"_end")
+
+	if forBlock.Init != nil {
+		code += a.stmt(forBlock.Init)
+	}
+	code += utils.Format(label + ":  # for循环开始")
+	return code + a.exit(forBlock.Condition, label+"_end", "for循环条件检查")
+}
+
+func (a *RISCV) EndFor(forBlock *parser.ForBlock) (code string) {
+	label := "for_" + strconv.Itoa(forBlock.Offset)
+	code += utils.Format(label + "_continue:  # for循环增量")
+	if forBlock.Increment != nil {
+		code += a.stmt(forBlock.Increment)
+	}
+	code += utils.Format("j " + label)
+	code += utils.Format(label + "_end:  # for循环结束")
+	a.ctx.PopLoop()
+	return code
+}
+
+func (a *RISCV) While(whileBlock *parser.WhileBlock) (code string) {
+	a.ctx.WhileCount++
+	whileBlock.Offset = a.ctx.WhileCount
+	label := "while_" + strconv.Itoa(whileBlock.Offset)
+	a.ctx.PushLoop(label, label+"_end")
+
+	code += utils.Format(label + ":  # while循环开始")
+	return code + a.exit(whileBlock.Condition, label+"_end", "while循环条件检查")
+}
+
+func (a *RISCV) EndWhile(whileBlock *parser.WhileBlock) (code string) {
+	label := "while_" + strconv.Itoa(whileBlock.Offset)
+	code += utils.Format("j " + label)
+	code += utils.Format(label + "_end:  # while循环结束")
+	a.ctx.PopLoop()
+	return code
+}
+
+func (a *RISCV) Break(breakBlock *parser.BreakBlock) string {
+	loop, ok := a.ctx.CurrentLoop()
+	if !ok {
+		panic("编译器内部错误: break 不在循环中")
+	}
+	return utils.Format("j " + loop.End + "  # break")
+}
+
+func (a *RISCV) Continue(continueBlock *parser.ContinueBlock) string {
+	loop, ok := a.ctx.CurrentLoop()
+	if !ok || loop.Continue == "" {
+		panic("编译器内部错误: continue 不在循环中")
+	}
+	return utils.Format("j " + loop.Continue + "  # continue")
+}
+
+// Switch 依次比较各分支值，没有匹配的分支时跳转到 default，没有 default 则跳出
+func (a *RISCV) Switch(switchBlock *parser.SwitchBlock) (code string) {
+	a.ctx.SwitchCount++
+	switchBlock.Offset = a.ctx.SwitchCount
+	end := switchLabel(switchBlock) + "_end"
+	// break 跳出 switch，continue 仍作用于外层循环
+	cont := ""
+	if loop, ok := a.ctx.CurrentLoop(); ok {
+		cont = loop.Continue
+	}
+	a.ctx.PushLoop(cont, end)
+
+	fallback := end
+	if switchBlock.Default != nil {
+		fallback = caseLabel(switchBlock, switchBlock.Default)
+	}
+	valueCode, reg := a.value(switchBlock.Value)
+	code += utils.Format("# ---- switch开始 ----")
+	code += valueCode
+	for _, caseBlock := range switchBlock.Cases {
+		for _, v := range caseBlock.Values {
+			code += utils.Format("li " + scratchReg + ", " + strconv.FormatInt(v.CaseValue(), 10))
+			code += utils.Format("beq " + reg + ", " + scratchReg + ", " + caseLabel(switchBlock, caseBlock))
+		}
+	}
+	a.release(reg)
+	return code + utils.Format("j "+fallback)
+}
+
+func (a *RISCV) Case(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) string {
+	return utils.Format(caseLabel(switchBlock, caseBlock) + ":")
+}
+
+func (a *RISCV) EndCase(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) string {
+	return utils.Format("j " + switchLabel(switchBlock) + "_end")
+}
+
+func (a *RISCV) EndSwitch(switchBlock *parser.SwitchBlock) string {
+	a.ctx.PopLoop()
+	return utils.Format(switchLabel(switchBlock) + "_end:  # switch结束")
+}
+
+func switchLabel(switchBlock *parser.SwitchBlock) string {
+	return "switch_" + strconv.Itoa(switchBlock.Offset)
+}
+
+func caseLabel(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) string {
+	if caseBlock.IsDefault {
+		return switchLabel(switchBlock) + "_default"
+	}
+	return switchLabel(switchBlock) + "_case_" + strconv.Itoa(caseBlock.Offset)
+}
+
+// GenVarAddr 返回变量的内存操作数，如 -24(s0)；全局变量为其符号
+func (a *RISCV) GenVarAddr(v *parser.VarBlock) string {
+	if data.IsGlobal(v) {
+		return data.GlobalLabel(data.Define(v).Name)
+	}
+	_, base, offset := a.varRef(v)
+	return strconv.Itoa(offset) + "(" + base + ")"
+}
+
+// newLabel 返回唯一的局部标签
+func (a *RISCV) newLabel(prefix string) string {
+	a.labels++
+	return ".L" + prefix + "_" + strconv.Itoa(a.labels)
+}
@@ -0,0 +1,83 @@
+package riscv
+
+import (
+	"cuteify/compile/arch"
+	"cuteify/parser"
+	"cuteify/utils"
+)
+
+// Header 文件开头，程序入口 _start 为全局符号
+func (a *RISCV) Header(root *parser.Node) string {
+	return "# 由 cuteify 生成的 RISC-V 汇编（" + a.Info() + "），使用 GNU as 汇编\n.text\n.globl _start\n\n"
+}
+
+// FuncLabel 外部函数只有声明，不输出标签
+func (a *RISCV) FuncLabel(funcBlock *parser.FuncBlock, label string, links []string) (code string) {
+	if arch.ExtName(funcBlock) != "" {
+		return ""
+	}
+	code += utils.Format("# ==============================")
+	code += utils.Format("# Function: " + label)
+	// build link("name") 导出同名的全局符号，指向函数入口
+	for _, link := range links {
+		code += utils.Format(".globl " + symbol(link))
+		code += utils.Format(symbol(link) + ":")
+	}
+	code += utils.Format(symbol(label) + ":")
+	return code
+}
+
+func (a *RISCV) EndFunc(funcBlock *parser.FuncBlock) string {
+	if arch.ExtName(funcBlock) != "" {
+		return ""
+	}
+	return utils.Format("# ======函数完毕=======\n\n")
+}
+
+// If 条件不成立时跳转到 else 分支或 if 结束位置
+func (a *RISCV) If(ifBlock *parser.IfBlock, label string) (code string) {
+	if ifBlock.Else {
+		code += a.exit(ifBlock.Condition, "else_"+label, "if条件检查")
+	} else {
+		code += a.exit(ifBlock.Condition, "end_"+label, "if条件检查")
+	}
+	return code + utils.Format(label+":")
+}
+
+// Else if 分支执行完毕后跳过 else 分支；else if 的条件不成立时跳转到结束位置
+func (a *RISCV) Else(ifBlock *parser.IfBlock, label string) (code string) {
+	code += utils.Format("j end_" + label + "  # 跳过else分支")
+	code += utils.Format("else_" + label + ":")
+	if cond := ifBlock.ElseBlock.Value.(*parser.ElseBlock).IfCondition; cond != nil {
+		code += a.exit(cond, "end_"+label, "else if条件检查")
+	}
+	return code
+}
+
+func (a *RISCV) EndIf(ifBlock *parser.IfBlock, label string) string {
+	return utils.Format("end_" + label + ":")
+}
+
+// InlineAsm build asm 中是 x86 汇编，RISC-V 后端无法使用，执行到这里时触发非法指令异常
+func (a *RISCV) InlineAsm(build *parser.Build) string {
+	return utils.Format("# build asm 为 x86 汇编，RISC-V 后端不支持") + utils.Format("unimp")
+}
+
+// StartEntry 程序入口：初始化 gp 后调用 main，以其返回值为退出码调用 exit（93 号系统调用）
+func (a *RISCV) StartEntry() (code string) {
+	code += utils.Format("# ==============================")
+	code += utils.Format("# 程序入口点 (ELF入口)")
+	code += utils.Format("_start:")
+	utils.Count++
+	// gp 相对寻址由链接器松弛生成，设置 gp 的指令本身不能被松弛
+	code += utils.Format(".option push")
+	code += utils.Format(".option norelax")
+	code += utils.Format("la gp, __global_pointer$")
+	code += utils.Format(".option pop")
+	code += utils.Format("call main")
+	code += utils.Format("# 返回值在a0中，即为退出码")
+	code += utils.Format("li a7, 93  # exit")
+	code += utils.Format("ecall\n")
+	utils.Count--
+	return code
+}
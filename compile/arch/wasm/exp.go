@@ -0,0 +1,423 @@
+package wasm
+
+import (
+	"cuteify/compile/data"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"math"
+	"strconv"
+)
+
+// word 地址、长度等 32 位值的类型
+var word = typeSys.GetSystemType("i32")
+
+// push 生成计算表达式的指令，结果以 want 对应的 wasm 值类型留在操作数栈上（want 为空时按表达式自身的类型），
+// 结构体、数组、切片与接口的值为其地址
+func (a *Wasm) push(exp *parser.Expression, want typeSys.Type) (code string) {
+	if want == nil {
+		want = exp.Type
+	}
+	if exp.IsConst() {
+		return a.constant(exp, want)
+	}
+	switch {
+	case exp.Unary != "":
+		code = a.unary(exp)
+	case exp.Index != nil:
+		base, offset := a.elemAddr(exp)
+		code = base + load(exp.Type, offset)
+	case exp.Field != nil:
+		base, offset := a.lvalue(exp)
+		code = base + load(exp.Type, offset)
+	case exp.Separator != "":
+		code = a.binary(exp)
+	case exp.Call != nil:
+		code = a.call(exp.Call)
+	case exp.Var != nil:
+		if exp.Var.Value != nil {
+			// 赋值表达式的值为赋值后的变量
+			code = a.assign(exp.Var)
+			if exp.Var.Store != nil {
+				return code + a.push(exp.Var.Store, want)
+			}
+		}
+		code += a.loadVar(exp.Var)
+	}
+	return code + convert(exp.Type, want)
+}
+
+// stmt 生成表达式语句，丢弃表达式的值
+func (a *Wasm) stmt(exp *parser.Expression) string {
+	if exp.Var != nil && exp.Var.Value != nil && exp.Separator == "" && exp.Unary == "" && exp.Index == nil {
+		return a.assign(exp.Var)
+	}
+	code := a.push(exp, nil)
+	if exp.Call != nil && exp.Call.Func != nil && len(exp.Call.Func.Return) == 0 {
+		return code
+	}
+	return code + utils.Format("drop")
+}
+
+// binary 二元运算，比较的操作数按较宽的一侧计算，指针加减的偏移量在解析时已按元素大小换算为字节数
+func (a *Wasm) binary(exp *parser.Expression) (code string) {
+	switch exp.Separator {
+	case "&&", "||":
+		code += a.push(exp.Left, nil)
+		code += utils.Format("if (result i32)")
+		utils.Count++
+		if exp.Separator == "&&" {
+			code += a.push(exp.Right, nil)
+		} else {
+			code += utils.Format("i32.const 1")
+		}
+		utils.Count--
+		code += utils.Format("else")
+		utils.Count++
+		if exp.Separator == "&&" {
+			code += utils.Format("i32.const 0")
+		} else {
+			code += a.push(exp.Right, nil)
+		}
+		utils.Count--
+		return code + utils.Format("end")
+	case "^":
+		panic("编译器内部错误: WebAssembly 后端不支持非常量的 ^ 运算")
+	}
+
+	t := exp.Type
+	compare := false
+	switch exp.Separator {
+	case "==", "!=", "<", ">", "<=", ">=":
+		compare = true
+		t = exp.OperandType()
+	}
+	code += a.push(exp.Left, t)
+	code += a.push(exp.Right, t)
+
+	vt := valType(t)
+	float := vt == "f32" || vt == "f64"
+	var op string
+	switch exp.Separator {
+	case "+":
+		op = "add"
+	case "-":
+		op = "sub"
+	case "*":
+		op = "mul"
+	case "&":
+		op = "and"
+	case "|":
+		op = "or"
+	case "<<":
+		op = "shl"
+	case ">>":
+		op = "shr" + suffix(t)
+	case "/":
+		op = "div"
+		if !float {
+			op += suffix(t)
+		}
+	case "%":
+		if float {
+			panic("编译器内部错误: WebAssembly 没有浮点数取余指令")
+		}
+		op = "rem" + suffix(t)
+	case "==":
+		op = "eq"
+	case "!=":
+		op = "ne"
+	case "<":
+		op = "lt"
+	case ">":
+		op = "gt"
+	case "<=":
+		op = "le"
+	case ">=":
+		op = "ge"
+	default:
+		panic("编译器内部错误: 未知的运算符 " + exp.Separator)
+	}
+	if compare && !float && op != "eq" && op != "ne" {
+		op += suffix(t)
+	}
+	return code + utils.Format(vt+"."+op)
+}
+
+// assign 生成赋值语句，包括通过指针或下标的赋值
+func (a *Wasm) assign(v *parser.VarBlock) string {
+	if v.Store != nil {
+		base, offset := a.lvalue(v.Store)
+		return a.storeValue(base, offset, v.Value, v.Store.Type)
+	}
+	if s := a.localSlot(v); s != nil {
+		return a.push(v.Value, v.Type) + narrow(v.Type) + utils.Format("local.set $"+s.local)
+	}
+	base, offset, t := a.varAddr(v)
+	return a.storeValue(base, offset, v.Value, t)
+}
+
+// storeValue 将 value 按 t 类型写入 base 地址加 offset 处，聚合类型整体复制，
+// 结构体转换为接口、数组转换为切片时分别写入两个字
+func (a *Wasm) storeValue(base string, offset int, value *parser.Expression, t typeSys.Type) (code string) {
+	if !isAggregate(t) {
+		return base + a.push(value, t) + narrow(t) + store(t, offset)
+	}
+	if first, second, ok := a.pair(value, t); ok {
+		code += base + first + store(word, offset)
+		code += base + second + store(word, offset+4)
+		return code
+	}
+	code += base + addOffset(offset)
+	code += a.push(value, nil)
+	code += utils.Format("i32.const " + strconv.Itoa(t.Size()))
+	code += utils.Format("memory.copy")
+	return code
+}
+
+// pair 结构体转换为接口时返回数据地址与虚表地址，数组转换为切片时返回数据地址与长度，不需要转换时 ok 为 false
+func (a *Wasm) pair(value *parser.Expression, t typeSys.Type) (first, second string, ok bool) {
+	switch to := t.(type) {
+	case *typeSys.InterfaceType:
+		if src, ok := value.Type.(*typeSys.StructType); ok && !src.IsPointer() {
+			return a.push(value, nil), utils.Format("i32.const " + strconv.Itoa(a.vtable(src, to))), true
+		}
+	case *typeSys.SliceType:
+		if src, ok := value.Type.(*typeSys.ArrayType); ok {
+			return a.push(value, nil), utils.Format("i32.const " + strconv.Itoa(src.Len)), true
+		}
+	}
+	return "", "", false
+}
+
+// converts 报告 value 作为 t 类型传递时是否需要组成新的接口或切片
+func converts(value *parser.Expression, t typeSys.Type) bool {
+	switch t.(type) {
+	case *typeSys.InterfaceType:
+		src, ok := value.Type.(*typeSys.StructType)
+		return ok && !src.IsPointer()
+	case *typeSys.SliceType:
+		_, ok := value.Type.(*typeSys.ArrayType)
+		return ok
+	}
+	return false
+}
+
+// localSlot 返回保存在 wasm 局部变量中的变量，变量位于内存中时返回 nil
+func (a *Wasm) localSlot(v *parser.VarBlock) *slot {
+	if v.Name.IsPath() || v.IsSelf(a.ctx.CurrentFunc) || data.IsGlobal(v) {
+		return nil
+	}
+	k, _ := key(v)
+	s, ok := a.frame.slots[k]
+	if !ok {
+		panic("编译器内部错误: 未声明的变量 " + v.Name.String())
+	}
+	if s.local == "" {
+		return nil
+	}
+	return s
+}
+
+// loadVar 读取变量的值，self 本身的值为接收者地址
+func (a *Wasm) loadVar(v *parser.VarBlock) string {
+	if v.IsSelf(a.ctx.CurrentFunc) && !v.Name.IsPath() {
+		return utils.Format("local.get $self")
+	}
+	if s := a.localSlot(v); s != nil {
+		return utils.Format("local.get $" + s.local)
+	}
+	base, offset, t := a.varAddr(v)
+	return base + load(t, offset)
+}
+
+// varAddr 返回计算内存中变量（或其字段）基址的指令、静态偏移与变量的类型
+func (a *Wasm) varAddr(v *parser.VarBlock) (code string, offset int, t typeSys.Type) {
+	switch def := data.Define(v); {
+	case v.IsSelf(a.ctx.CurrentFunc):
+		code, t = utils.Format("local.get $self"), a.ctx.CurrentFunc.Class
+	case def.IsGlobal:
+		addr, ok := a.globals[def]
+		if !ok {
+			panic("编译器内部错误: 未分配地址的全局变量 " + def.Name.String())
+		}
+		code, t = utils.Format("i32.const "+strconv.Itoa(addr)), def.Type
+	default:
+		var k any
+		k, t = key(v)
+		s, ok := a.frame.slots[k]
+		if !ok || s.local != "" {
+			panic("编译器内部错误: 变量 " + v.Name.String() + " 不在栈帧中")
+		}
+		code, offset = utils.Format("local.get $fp"), s.offset
+	}
+	for _, name := range v.Name[1:] {
+		field := a.field(t, name)
+		offset += field.Offset
+		t = field.Type
+	}
+	return code, offset, t
+}
+
+// lvalue 返回赋值目标的基址与静态偏移：变量、解引用、下标或下标结果的字段
+func (a *Wasm) lvalue(exp *parser.Expression) (code string, offset int) {
+	switch {
+	case exp.Unary == "*":
+		return a.push(exp.Right, nil), 0
+	case exp.Index != nil:
+		return a.elemAddr(exp)
+	case exp.Field != nil:
+		code, offset = a.lvalue(exp.Left)
+		return code, offset + exp.StructField().Offset
+	case exp.Var != nil && exp.Unary == "" && exp.Separator == "":
+		code, offset, _ = a.varAddr(exp.Var)
+		return code, offset
+	}
+	panic("编译器内部错误: 表达式不能被赋值")
+}
+
+// addr 返回取地址运算的结果
+func (a *Wasm) addr(exp *parser.Expression) string {
+	if exp.Var != nil && exp.Unary == "" && exp.Index == nil && exp.Var.IsSelf(a.ctx.CurrentFunc) && !exp.Var.Name.IsPath() {
+		return utils.Format("local.get $self")
+	}
+	base, offset := a.lvalue(exp)
+	return base + addOffset(offset)
+}
+
+// call 生成函数调用，方法以接收者地址作为第一个实参，接口方法通过函数表间接调用
+func (a *Wasm) call(call *parser.CallBlock) (code string) {
+	var args string
+	for _, arg := range call.Args {
+		if arg != nil {
+			args += a.arg(arg)
+		}
+	}
+	if call.ThisVar == nil {
+		return args + utils.Format("call $"+funcName(call.Func))
+	}
+	if _, ok := call.ThisVar.Type.(*typeSys.InterfaceType); ok {
+		base, offset, _ := a.varAddr(call.ThisVar)
+		code += base + load(word, offset)
+		code += args
+		code += base + load(word, offset+4)
+		code += load(word, 4*parser.MethodSlot(call.ThisVar.Type.(*typeSys.InterfaceType), call.Name.Last()))
+		return code + utils.Format("call_indirect (type "+a.sigType(call.Func, true)+")")
+	}
+	code += a.addr(&parser.Expression{Var: call.ThisVar})
+	return code + args + utils.Format("call $"+funcName(call.Func))
+}
+
+// arg 生成一个实参：标量按形参类型传值，聚合类型传地址，需要转换的接口与切片先在栈帧的临时空间中组成
+func (a *Wasm) arg(arg *parser.ArgBlock) (code string) {
+	if !isAggregate(arg.Type) {
+		return a.push(arg.Value, arg.Type) + narrow(arg.Type)
+	}
+	first, second, ok := a.pair(arg.Value, arg.Type)
+	if !ok {
+		return a.push(arg.Value, nil)
+	}
+	offset := a.frame.slots[arg].offset
+	fp := utils.Format("local.get $fp")
+	code += fp + first + store(word, offset)
+	code += fp + second + store(word, offset+4)
+	return code + fp + addOffset(offset)
+}
+
+// unary 一元运算：取地址、解引用、切片长度与类型转换
+func (a *Wasm) unary(exp *parser.Expression) string {
+	switch exp.Unary {
+	case "&":
+		return a.addr(exp.Right)
+	case "*":
+		return a.push(exp.Right, nil) + load(exp.Type, 0)
+	case "len":
+		return a.push(exp.Right, nil) + load(word, 4)
+	case "as":
+		// 指针与整数之间都是 i32，不需要转换
+		return a.push(exp.Right, nil) + convert(exp.Right.Type, exp.Type) + narrow(exp.Type)
+	}
+	panic("编译器内部错误: 未知的一元运算 " + exp.Unary)
+}
+
+// elemAddr 返回下标访问的元素地址与静态偏移，开启越界检查时下标越界执行 unreachable
+func (a *Wasm) elemAddr(exp *parser.Expression) (code string, offset int) {
+	var length string
+	var elem typeSys.Type
+	switch t := exp.Left.Type.(type) {
+	case *typeSys.ArrayType:
+		code, elem = a.push(exp.Left, nil), t.Elem
+		length = utils.Format("i32.const " + strconv.Itoa(t.Len))
+	case *typeSys.SliceType:
+		code, elem = a.push(exp.Left, nil)+load(word, 0), t.Elem
+		length = a.push(exp.Left, nil) + load(word, 4)
+	default:
+		panic("编译器内部错误: 不能对 " + exp.Left.Type.Type() + " 使用下标")
+	}
+	size := elem.Size()
+	_, array := exp.Left.Type.(*typeSys.ArrayType)
+	if exp.Index.IsConst() && (array || !a.ctx.BoundsCheck) {
+		// 数组的常量下标在解析时已经检查过
+		return code, int(exp.Index.Num) * size
+	}
+	code += a.push(exp.Index, word)
+	if a.ctx.BoundsCheck {
+		code += utils.Format("local.tee $idx")
+		code += length
+		code += utils.Format("i32.ge_u")
+		code += utils.Format("if")
+		utils.Count++
+		code += utils.Format("unreachable ;; 下标越界")
+		utils.Count--
+		code += utils.Format("end")
+		code += utils.Format("local.get $idx")
+	}
+	if size != 1 {
+		code += utils.Format("i32.const " + strconv.Itoa(size))
+		code += utils.Format("i32.mul")
+	}
+	return code + utils.Format("i32.add"), 0
+}
+
+// constant 返回以 want 对应的值类型表示的常量
+func (a *Wasm) constant(exp *parser.Expression, want typeSys.Type) string {
+	vt := valType(want)
+	switch {
+	case exp.Type == nil || typeSys.GetTypeType(exp.Type) == "bool":
+		// 折叠后的比较结果没有类型
+		if exp.Bool {
+			return utils.Format("i32.const 1")
+		}
+		return utils.Format("i32.const 0")
+	case typeSys.GetTypeType(exp.Type) == "string":
+		return utils.Format("i32.const " + strconv.Itoa(a.str(exp.StringVal)))
+	}
+	switch vt {
+	case "f32", "f64":
+		return utils.Format(vt + ".const " + strconv.FormatFloat(exp.Num, 'g', -1, 64))
+	case "i64":
+		if exp.Num >= math.MaxInt64 {
+			return utils.Format("i64.const " + strconv.FormatInt(int64(uint64(exp.Num)), 10))
+		}
+		return utils.Format("i64.const " + strconv.FormatInt(int64(exp.Num), 10))
+	}
+	return utils.Format("i32.const " + strconv.FormatInt(int64(int32(uint32(int64(exp.Num)))), 10))
+}
+
+// field 返回结构体字段
+func (a *Wasm) field(t typeSys.Type, name string) *typeSys.StructField {
+	structType, ok := t.(*typeSys.StructType)
+	if !ok {
+		panic("编译器内部错误: " + t.Type() + " 不是结构体")
+	}
+	f := structType.Field(name)
+	if f == nil {
+		if block, exists := a.ctx.GetStruct(structType.Type()); exists {
+			f = block.GetFieldByName(name)
+		}
+	}
+	if f == nil {
+		panic("编译器内部错误: 结构体 " + structType.Type() + " 没有字段 " + name)
+	}
+	return f
+}
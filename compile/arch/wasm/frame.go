@@ -0,0 +1,242 @@
+package wasm
+
+import (
+	"cuteify/compile/data"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strconv"
+)
+
+// 局部变量分配：wasm 是栈式虚拟机，表达式的中间值留在操作数栈上，不需要寄存器管理器。
+// 标量局部变量与参数直接成为 wasm 局部变量；结构体、数组、切片、接口以及被取地址的变量
+// 放在线性内存中的栈帧里，栈帧从全局变量 $sp 向下分配，函数内通过局部变量 $fp 访问。
+
+// frameAlign 栈帧的对齐值，保证 8 字节的值可以自然对齐
+const frameAlign = 8
+
+// slot 变量的存放位置
+type slot struct {
+	local  string // wasm 局部变量名（不含 $），为空时位于栈帧中
+	offset int    // 在栈帧中相对 $fp 的偏移
+}
+
+// frame 当前函数的局部变量与栈帧布局
+type frame struct {
+	slots  map[any]*slot      // 变量定义（*VarBlock 或 *ArgBlock）、switch 匹配值与需要转换的实参对应的位置
+	used   map[string]bool    // 已使用的局部变量名
+	addrs  map[any]bool       // 被取地址的变量定义
+	locals []string           // 函数体中声明的 wasm 局部变量
+	copies []*parser.ArgBlock // 需要在序言中复制到栈帧的参数
+	size   int                // 栈帧大小
+}
+
+func newFrame() *frame {
+	return &frame{
+		slots: map[any]*slot{},
+		used:  map[string]bool{"fp": true, "self": true, "idx": true},
+		addrs: map[any]bool{},
+	}
+}
+
+// name 返回当前函数中唯一的局部变量名，不同作用域中的同名变量依次加数字后缀
+func (f *frame) name(base string) string {
+	s := base
+	for k := 1; f.used[s]; k++ {
+		s = base + "_" + strconv.Itoa(k)
+	}
+	f.used[s] = true
+	return s
+}
+
+// local 声明一个 wasm 局部变量
+func (f *frame) local(key any, base, vt string) *slot {
+	s := &slot{local: f.name(base)}
+	f.locals = append(f.locals, "(local $"+s.local+" "+vt+")")
+	f.slots[key] = s
+	return s
+}
+
+// memory 在栈帧中为 t 类型的值分配空间
+func (f *frame) memory(key any, t typeSys.Type) *slot {
+	offset := typeSys.AlignUp(f.size, typeSys.AlignOf(t))
+	f.size = offset + t.Size()
+	s := &slot{offset: offset}
+	f.slots[key] = s
+	return s
+}
+
+// define 为变量定义分配位置：不会被取地址的标量为 wasm 局部变量，其余放在栈帧中
+func (f *frame) define(key any, name parser.Name, t typeSys.Type) {
+	if _, ok := f.slots[key]; ok {
+		return
+	}
+	if isAggregate(t) || f.addrs[key] {
+		f.memory(key, t)
+		return
+	}
+	f.local(key, name.String(), valType(t))
+}
+
+// arg 为参数分配位置，参数本身总是 wasm 参数，聚合类型与被取地址的参数在序言中复制到栈帧
+func (f *frame) arg(arg *parser.ArgBlock) (param string) {
+	param = f.name(arg.Name.String())
+	if isAggregate(arg.Type) || f.addrs[arg] {
+		f.memory(arg, arg.Type)
+		f.slots[paramKey{arg}] = &slot{local: param}
+		f.copies = append(f.copies, arg)
+	} else {
+		f.slots[arg] = &slot{local: param}
+	}
+	return param
+}
+
+// declare 遍历函数体，为局部变量与 switch 的匹配值分配位置
+func (f *frame) declare(node *parser.Node) {
+	for _, child := range node.Children {
+		if child.Ignore {
+			continue
+		}
+		switch v := child.Value.(type) {
+		case *parser.VarBlock:
+			if v.IsDefine && !v.IsGlobal {
+				f.define(v, v.Name, v.Type)
+			}
+		case *parser.ForBlock:
+			if v.Init != nil && v.Init.Var != nil {
+				f.define(v.Init.Var, v.Init.Var.Name, v.Init.Var.Type)
+			}
+			f.declare(child)
+		case *parser.SwitchBlock:
+			f.local(v, "switch", valType(v.Value.Type))
+			f.declare(child)
+		case *parser.WhileBlock, *parser.CaseBlock:
+			f.declare(child)
+		case *parser.IfBlock:
+			f.declare(child)
+			if v.Else {
+				f.declare(v.ElseBlock)
+			}
+		}
+	}
+}
+
+// prologue 分配并清零栈帧，把需要放在栈帧中的参数复制进来
+func (f *frame) prologue() (code string) {
+	if f.size == 0 {
+		return ""
+	}
+	f.size = typeSys.AlignUp(f.size, frameAlign)
+	size := strconv.Itoa(f.size)
+	code += utils.Format("global.get $sp")
+	code += utils.Format("i32.const " + size)
+	code += utils.Format("i32.sub")
+	code += utils.Format("local.tee $fp")
+	code += utils.Format("global.set $sp")
+	code += utils.Format("local.get $fp")
+	code += utils.Format("i32.const 0")
+	code += utils.Format("i32.const " + size)
+	code += utils.Format("memory.fill")
+	for _, arg := range f.copies {
+		s := f.slots[arg]
+		code += utils.Format("local.get $fp")
+		if isAggregate(arg.Type) {
+			code += addOffset(s.offset)
+			code += utils.Format("local.get $" + f.param(arg))
+			code += utils.Format("i32.const " + strconv.Itoa(arg.Type.Size()))
+			code += utils.Format("memory.copy")
+			continue
+		}
+		code += utils.Format("local.get $" + f.param(arg))
+		code += store(arg.Type, s.offset)
+	}
+	return code
+}
+
+// param 返回复制到栈帧中的参数对应的 wasm 参数名
+func (f *frame) param(arg *parser.ArgBlock) string {
+	return f.slots[paramKey{arg}].local
+}
+
+// paramKey 复制到栈帧中的参数，其 wasm 参数名单独登记
+type paramKey struct{ arg *parser.ArgBlock }
+
+// epilogue 释放栈帧
+func (f *frame) epilogue() (code string) {
+	if f.size == 0 {
+		return ""
+	}
+	code += utils.Format("local.get $fp")
+	code += utils.Format("i32.const " + strconv.Itoa(f.size))
+	code += utils.Format("i32.add")
+	code += utils.Format("global.set $sp")
+	return code
+}
+
+// key 返回变量引用对应的定义（*VarBlock 或 *ArgBlock）及其类型
+func key(v *parser.VarBlock) (any, typeSys.Type) {
+	if v.Define != nil {
+		if arg, ok := v.Define.Value.(*parser.ArgBlock); ok {
+			return arg, arg.Type
+		}
+	}
+	def := data.Define(v)
+	return def, def.Type
+}
+
+// eachExp 对函数体中所有语句的表达式（包括子表达式）调用 fn
+func eachExp(node *parser.Node, fn func(*parser.Expression)) {
+	for _, child := range node.Children {
+		if child.Ignore {
+			continue
+		}
+		switch v := child.Value.(type) {
+		case *parser.VarBlock:
+			visit(v.Value, fn)
+			visit(v.Store, fn)
+		case *parser.CallBlock:
+			visit(&parser.Expression{Call: v}, fn)
+		case *parser.ReturnBlock:
+			for _, value := range v.Value {
+				visit(value, fn)
+			}
+		case *parser.IfBlock:
+			visit(v.Condition, fn)
+			if v.Else {
+				visit(v.ElseBlock.Value.(*parser.ElseBlock).IfCondition, fn)
+				eachExp(v.ElseBlock, fn)
+			}
+		case *parser.ForBlock:
+			visit(v.Init, fn)
+			visit(v.Condition, fn)
+			visit(v.Increment, fn)
+		case *parser.WhileBlock:
+			visit(v.Condition, fn)
+		case *parser.SwitchBlock:
+			visit(v.Value, fn)
+		}
+		eachExp(child, fn)
+	}
+}
+
+// visit 对表达式及其所有子表达式调用 fn
+func visit(exp *parser.Expression, fn func(*parser.Expression)) {
+	if exp == nil {
+		return
+	}
+	fn(exp)
+	visit(exp.Left, fn)
+	visit(exp.Right, fn)
+	visit(exp.Index, fn)
+	if exp.Call != nil {
+		for _, arg := range exp.Call.Args {
+			if arg != nil {
+				visit(arg.Value, fn)
+			}
+		}
+	}
+	if exp.Var != nil {
+		visit(exp.Var.Value, fn)
+		visit(exp.Var.Store, fn)
+	}
+}
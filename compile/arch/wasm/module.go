@@ -0,0 +1,249 @@
+package wasm
+
+import (
+	"cuteify/compile/arch"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	dataBase  = 16      // 静态数据的起始地址，地址 0 保留给空指针
+	stackSize = 1 << 16 // 栈帧所用的内存大小
+	pageSize  = 1 << 16 // wasm 内存页大小
+)
+
+// segment 线性内存中的一段初始化数据
+type segment struct {
+	addr  int
+	bytes []byte
+	name  string // 注释
+}
+
+// Header 输出模块开头、外部函数的导入，并为全局变量分配地址
+func (a *Wasm) Header(root *parser.Node) string {
+	var b strings.Builder
+	b.WriteString(";; 由 cuteify 生成的 WebAssembly 文本格式模块\n(module\n")
+	_, _, funcs, globals := arch.Collect(root)
+	for _, funcBlock := range funcs {
+		if ext := arch.ExtName(funcBlock); ext != "" {
+			recv := false
+			if _, ok := funcBlock.Class.(*typeSys.StructType); ok {
+				recv = true
+			}
+			b.WriteString("    (import \"env\" " + wasmString([]byte(ext)) + " (func $" + ext + signature(funcBlock, recv) + "))\n")
+		}
+	}
+	for _, v := range globals {
+		a.globals[v] = a.alloc(v.Type.Size(), typeSys.AlignOf(v.Type))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// FuncLabel 函数头由 Func 输出，这里输出 build link 对应的导出
+func (a *Wasm) FuncLabel(funcBlock *parser.FuncBlock, label string, links []string) (code string) {
+	if arch.ExtName(funcBlock) != "" {
+		return ""
+	}
+	for _, link := range links {
+		code += "    (export " + wasmString([]byte(link)) + " (func $" + funcName(funcBlock) + "))\n"
+	}
+	return code
+}
+
+func (a *Wasm) EndFunc(funcBlock *parser.FuncBlock) string {
+	if a.ext {
+		return ""
+	}
+	code := utils.Format(")")
+	utils.Count--
+	return code + "\n"
+}
+
+func (a *Wasm) If(ifBlock *parser.IfBlock, label string) (code string) {
+	code += a.push(ifBlock.Condition, nil)
+	code += utils.Format("if ;; " + label)
+	utils.Count++
+	return code
+}
+
+// Else else if 在 else 分支中嵌套一个 if，由 EndIf 多结束一层
+func (a *Wasm) Else(ifBlock *parser.IfBlock, label string) (code string) {
+	utils.Count--
+	code += utils.Format("else")
+	utils.Count++
+	if cond := ifBlock.ElseBlock.Value.(*parser.ElseBlock).IfCondition; cond != nil {
+		code += a.push(cond, nil)
+		code += utils.Format("if")
+		utils.Count++
+	}
+	return code
+}
+
+func (a *Wasm) EndIf(ifBlock *parser.IfBlock, label string) (code string) {
+	if ifBlock.Else && ifBlock.ElseBlock.Value.(*parser.ElseBlock).IfCondition != nil {
+		utils.Count--
+		code += utils.Format("end")
+	}
+	utils.Count--
+	return code + utils.Format("end")
+}
+
+// InlineAsm 内联汇编依赖 x86 栈帧布局，无法转换为 wasm，执行到这里时陷入
+func (a *Wasm) InlineAsm(build *parser.Build) (code string) {
+	code += utils.Format(";; build asm 不能在 WebAssembly 中执行")
+	return code + utils.Format("unreachable")
+}
+
+// StartEntry 导出 main，由宿主调用并以其返回值作为退出码
+func (a *Wasm) StartEntry() string {
+	return "    (export \"main\" (func $main))\n"
+}
+
+// Data 输出函数类型、函数表、线性内存、栈指针以及全局变量、字符串和虚表的初始数据，并结束模块
+func (a *Wasm) Data() string {
+	// 全局变量的初始值可能引用字符串，先于内存大小确定
+	var globals []segment
+	for _, g := range a.ctx.Data.Globals {
+		if g.IsZero() {
+			continue
+		}
+		bytes := make([]byte, g.Size())
+		for _, item := range g.Items {
+			a.encode(bytes[item.Offset:item.Offset+item.Size], item.Value)
+		}
+		globals = append(globals, segment{addr: a.globals[g.Var], bytes: bytes, name: g.Label})
+	}
+
+	var b strings.Builder
+	b.WriteString("\n")
+	for _, t := range a.types {
+		b.WriteString("    " + t + "\n")
+	}
+	if len(a.table) > 0 {
+		b.WriteString("    (table " + strconv.Itoa(len(a.table)) + " funcref)\n")
+		b.WriteString("    (elem (i32.const 0) func $" + strings.Join(a.table, " $") + ")\n")
+	}
+	pages := (typeSys.AlignUp(a.top, frameAlign) + stackSize + pageSize - 1) / pageSize
+	b.WriteString("    (memory (export \"memory\") " + strconv.Itoa(pages) + ")\n")
+	b.WriteString("    (global $sp (mut i32) (i32.const " + strconv.Itoa(pages*pageSize) + "))\n")
+	for _, s := range append(globals, a.segments...) {
+		b.WriteString("    (data (i32.const " + strconv.Itoa(s.addr) + ") " + wasmString(s.bytes) + ") ;; " + s.name + "\n")
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// encode 按小端序写入全局变量的一个初始值，字符串标签写入其地址
+func (a *Wasm) encode(dst []byte, value string) {
+	var bits uint64
+	switch {
+	case strings.HasPrefix(value, "str_"):
+		for _, s := range a.ctx.Data.Strings {
+			if s.Label == value {
+				bits = uint64(a.str(s.Value))
+			}
+		}
+	case strings.Contains(value, "."):
+		f, _ := strconv.ParseFloat(value, 64)
+		if len(dst) == 4 {
+			bits = uint64(math.Float32bits(float32(f)))
+		} else {
+			bits = math.Float64bits(f)
+		}
+	default:
+		n, _ := strconv.ParseInt(value, 10, 64)
+		bits = uint64(n)
+	}
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], bits)
+	copy(dst, buf[:])
+}
+
+// alloc 在静态数据区分配 size 字节，返回按 align 对齐的地址
+func (a *Wasm) alloc(size, align int) int {
+	addr := typeSys.AlignUp(a.top, align)
+	a.top = addr + size
+	return addr
+}
+
+// str 返回字符串字面量的地址，字符串以 0 结尾，相同内容共用一份
+func (a *Wasm) str(value string) int {
+	label := a.ctx.Data.Intern(value)
+	if addr, ok := a.strings[label]; ok {
+		return addr
+	}
+	addr := a.alloc(len(value)+1, 1)
+	a.strings[label] = addr
+	a.segments = append(a.segments, segment{addr: addr, bytes: append([]byte(value), 0), name: label})
+	return addr
+}
+
+// vtable 返回结构体实现接口时的虚表地址，虚表的每一项是方法在函数表中的下标
+func (a *Wasm) vtable(structType *typeSys.StructType, iface *typeSys.InterfaceType) int {
+	label := utils.ToNASMName("vtable_" + structType.Type() + "_" + iface.Type())
+	if addr, ok := a.vtables[label]; ok {
+		return addr
+	}
+	a.ctx.AddVTable(label, structType, iface)
+	bytes := make([]byte, 4*len(iface.Methods))
+	for i, m := range iface.Methods {
+		index := -1
+		if method := parser.FindMethod(structType, m.(*parser.FuncBlock).Name.Last()); method != nil && method.Useful {
+			index = a.elem(funcName(method))
+		}
+		binary.LittleEndian.PutUint32(bytes[4*i:], uint32(index))
+	}
+	addr := a.alloc(len(bytes), 4)
+	a.vtables[label] = addr
+	a.segments = append(a.segments, segment{addr: addr, bytes: bytes, name: label})
+	return addr
+}
+
+// elem 返回函数在函数表中的下标
+func (a *Wasm) elem(name string) int {
+	if index, ok := a.elems[name]; ok {
+		return index
+	}
+	a.elems[name] = len(a.table)
+	a.table = append(a.table, name)
+	return a.elems[name]
+}
+
+// sigType 返回 call_indirect 使用的函数类型名，首次使用时登记类型定义
+func (a *Wasm) sigType(funcBlock *parser.FuncBlock, recv bool) string {
+	sig := signature(funcBlock, recv)
+	name := "$sig" + strings.NewReplacer(" (param", "", " (result", "_to", ")", "", " ", "_").Replace(sig)
+	def := "(type " + name + " (func" + sig + "))"
+	for _, t := range a.types {
+		if t == def {
+			return name
+		}
+	}
+	a.types = append(a.types, def)
+	return name
+}
+
+// wasmString 返回 wat 字符串字面量，不可打印字符使用十六进制转义
+func wasmString(bytes []byte) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, ch := range bytes {
+		switch {
+		case ch == '"' || ch == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(ch)
+		case ch < 0x20 || ch >= 0x7f:
+			b.WriteString("\\" + strconv.FormatUint(uint64(ch)|0x100, 16)[1:])
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,133 @@
+package wasm
+
+import (
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strconv"
+)
+
+// valType 返回 cute 类型在 wasm 中的值类型：64 位整数为 i64，浮点数为 f32/f64，
+// 其余（较窄的整数、bool、指针、字符串，以及以地址表示的结构体、数组、切片和接口）都是 i32
+func valType(t typeSys.Type) string {
+	if t == nil {
+		return "i32"
+	}
+	if isAggregate(t) || t.IsPointer() {
+		return "i32"
+	}
+	switch typeSys.GetTypeType(t) {
+	case "float":
+		if t.Size() == 4 {
+			return "f32"
+		}
+		return "f64"
+	case "int", "uint":
+		if t.Size() == 8 {
+			return "i64"
+		}
+	}
+	return "i32"
+}
+
+// isAggregate 报告类型的值是否存放在线性内存中，以地址传递：结构体、数组、切片与接口
+func isAggregate(t typeSys.Type) bool {
+	if t == nil || t.IsPointer() {
+		return false
+	}
+	switch t.(type) {
+	case *typeSys.StructType, *typeSys.ArrayType, *typeSys.SliceType, *typeSys.InterfaceType:
+		return true
+	}
+	return false
+}
+
+// signed 报告整数类型是否有符号，指针、bool 等按无符号处理
+func signed(t typeSys.Type) bool {
+	_, s, ok := typeSys.IntInfo(t)
+	return ok && s && !t.IsPointer()
+}
+
+// suffix 返回按符号选择的指令后缀 _s 或 _u
+func suffix(t typeSys.Type) string {
+	if signed(t) {
+		return "_s"
+	}
+	return "_u"
+}
+
+// load 返回从栈顶地址加 offset 处读取 t 类型值的指令，聚合类型的值即其地址
+func load(t typeSys.Type, offset int) string {
+	if isAggregate(t) {
+		return addOffset(offset)
+	}
+	vt := valType(t)
+	inst := vt + ".load"
+	if size := t.Size(); vt == "i32" && size < 4 && size > 0 {
+		inst = "i32.load" + strconv.Itoa(size*8) + suffix(t)
+	}
+	return utils.Format(inst + memarg(offset))
+}
+
+// store 返回将栈顶的值写入次栈顶地址加 offset 处的指令，按类型大小选择写入宽度
+func store(t typeSys.Type, offset int) string {
+	vt := valType(t)
+	inst := vt + ".store"
+	if size := t.Size(); vt == "i32" && size < 4 && size > 0 {
+		inst = "i32.store" + strconv.Itoa(size*8)
+	}
+	return utils.Format(inst + memarg(offset))
+}
+
+// memarg 返回访存指令的偏移立即数
+func memarg(offset int) string {
+	if offset == 0 {
+		return ""
+	}
+	return " offset=" + strconv.Itoa(offset)
+}
+
+// addOffset 将栈顶的地址加上 offset
+func addOffset(offset int) (code string) {
+	if offset == 0 {
+		return ""
+	}
+	code += utils.Format("i32.const " + strconv.Itoa(offset))
+	code += utils.Format("i32.add")
+	return code
+}
+
+// convert 将栈顶 from 类型的值转换为 to 类型的 wasm 值类型，只处理值类型之间的扩展、截断与整数浮点转换
+func convert(from, to typeSys.Type) string {
+	f, t := valType(from), valType(to)
+	if f == t {
+		return ""
+	}
+	var inst string
+	switch {
+	case f == "i32" && t == "i64":
+		inst = "i64.extend_i32" + suffix(from)
+	case f == "i64" && t == "i32":
+		inst = "i32.wrap_i64"
+	case f == "f32" && t == "f64":
+		inst = "f64.promote_f32"
+	case f == "f64" && t == "f32":
+		inst = "f32.demote_f64"
+	case t == "f32" || t == "f64":
+		inst = t + ".convert_" + f + suffix(from)
+	default:
+		inst = t + ".trunc_" + f + suffix(to)
+	}
+	return utils.Format(inst)
+}
+
+// narrow 将栈顶的 i32 值截断到 t 的宽度并按 t 的符号重新扩展，用于写入局部变量、返回值与实参
+func narrow(t typeSys.Type) string {
+	bits, s, ok := typeSys.IntInfo(t)
+	if !ok || bits >= 32 || t.IsPointer() {
+		return ""
+	}
+	if s {
+		return utils.Format("i32.extend" + strconv.Itoa(bits) + "_s")
+	}
+	return utils.Format("i32.const "+strconv.Itoa(1<<bits-1)) + utils.Format("i32.and")
+}
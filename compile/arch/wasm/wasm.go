@@ -0,0 +1,356 @@
+// Package wasm 将 AST 转换为 WebAssembly 文本格式（.wat）的模块。
+//
+// 函数对应带类型参数的 wasm 函数，标量局部变量对应 wasm 局部变量；聚合类型与被取地址的变量
+// 放在线性内存的栈帧中（见 frame.go）。if 对应 wasm 的 if/else，循环与 switch 用 block/loop/br_if 实现，
+// 接口方法通过函数表 call_indirect 调用，build ext 声明的函数从 env 模块导入。
+package wasm
+
+import (
+	"cuteify/compile/arch"
+	"cuteify/compile/context"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strconv"
+	"strings"
+)
+
+// Wasm 生成 WebAssembly 文本格式的后端，同时实现 arch.Arch 与 arch.Syntax
+type Wasm struct {
+	ctx *context.Context
+
+	frame *frame // 当前函数的局部变量与栈帧
+	ext   bool   // 当前函数是否为 build ext 声明的外部函数（从 env 导入，不生成函数体）
+
+	// 线性内存中的静态数据，地址从 dataBase 开始依次分配
+	top      int
+	globals  map[*parser.VarBlock]int // 全局变量的地址
+	strings  map[string]int           // 字符串字面量（按 .rodata 标签）的地址
+	vtables  map[string]int           // 虚表的地址
+	segments []segment
+
+	table []string       // 函数表，虚表中存放函数在表中的下标
+	elems map[string]int // 函数在函数表中的下标
+	types []string       // call_indirect 用到的函数类型
+}
+
+// NewWasm 创建 WebAssembly 后端，wasm 是栈式虚拟机，不需要寄存器管理器
+func NewWasm(ctx *context.Context) *Wasm {
+	return &Wasm{
+		ctx:     ctx,
+		top:     dataBase,
+		globals: map[*parser.VarBlock]int{},
+		strings: map[string]int{},
+		vtables: map[string]int{},
+		elems:   map[string]int{},
+	}
+}
+
+func (a *Wasm) Info() string { return "wasm32" }
+
+func (a *Wasm) Call(call *parser.CallBlock) string {
+	if call == nil || call.Func == nil {
+		return ""
+	}
+	code := a.call(call)
+	if len(call.Func.Return) > 0 {
+		code += utils.Format("drop")
+	}
+	return code
+}
+
+// Return 返回值留在操作数栈上，释放栈帧后返回；函数末尾没有 return 时只释放栈帧
+func (a *Wasm) Return(ret *parser.ReturnBlock) (code string) {
+	funcBlock := a.ctx.CurrentFunc
+	if a.ext || funcBlock == nil {
+		return ""
+	}
+	if ret == nil {
+		if len(funcBlock.Return) > 0 {
+			// 有返回值的函数不会执行到末尾
+			return utils.Format("unreachable")
+		}
+		return a.frame.epilogue()
+	}
+	if len(ret.Value) > 0 {
+		t := funcBlock.Return[0]
+		code += a.push(ret.Value[0], t) + narrow(t)
+	}
+	code += a.frame.epilogue()
+	return code + utils.Format("return")
+}
+
+// Func 输出函数头，声明局部变量并分配栈帧
+func (a *Wasm) Func(funcBlock *parser.FuncBlock) (code string) {
+	a.ext = arch.ExtName(funcBlock) != ""
+	if a.ext {
+		return ""
+	}
+	for _, t := range funcBlock.Return {
+		if isAggregate(t) {
+			panic("编译器内部错误: WebAssembly 后端不支持返回 " + t.Type())
+		}
+	}
+	f := newFrame()
+	a.frame = f
+
+	// 被取地址的变量必须放在栈帧中；需要组成接口或切片的实参在栈帧中占用临时空间
+	var temps []*parser.ArgBlock
+	eachExp(a.ctx.Now, func(exp *parser.Expression) {
+		if exp.Unary == "&" && exp.Right.Var != nil && exp.Right.Unary == "" && exp.Right.Index == nil && !exp.Right.Var.IsSelf(a.ctx.CurrentFunc) {
+			k, _ := key(exp.Right.Var)
+			f.addrs[k] = true
+		}
+		if exp.Call != nil {
+			for _, arg := range exp.Call.Args {
+				if arg != nil && isAggregate(arg.Type) && converts(arg.Value, arg.Type) {
+					temps = append(temps, arg)
+				}
+			}
+		}
+	})
+
+	head := "(func $" + funcName(funcBlock)
+	if _, ok := funcBlock.Class.(*typeSys.StructType); ok {
+		head += " (param $self i32)"
+	}
+	for _, arg := range funcBlock.Args {
+		head += " (param $" + f.arg(arg) + " " + valType(arg.Type) + ")"
+	}
+	if len(funcBlock.Return) > 0 {
+		head += " (result " + valType(funcBlock.Return[0]) + ")"
+	}
+	f.declare(a.ctx.Now)
+	for _, arg := range temps {
+		f.memory(arg, arg.Type)
+	}
+
+	code += utils.Format(head)
+	utils.Count++
+	if f.size > 0 {
+		code += utils.Format("(local $fp i32)")
+	}
+	if a.ctx.BoundsCheck {
+		code += utils.Format("(local $idx i32)")
+	}
+	for _, local := range f.locals {
+		code += utils.Format(local)
+	}
+	return code + f.prologue()
+}
+
+// Exp 输出表达式语句，条件由 If、For 等直接生成，不使用 result
+func (a *Wasm) Exp(exp *parser.Expression, result, desc string) string {
+	return a.stmt(exp)
+}
+
+func (a *Wasm) Var(varBlock *parser.VarBlock) string {
+	if varBlock.Value == nil {
+		// 没有初始值的结构体变量清零，字段默认值随后写入
+		if t, ok := varBlock.Type.(*typeSys.StructType); ok && !t.IsPointer() {
+			base, offset, _ := a.varAddr(varBlock)
+			code := base + addOffset(offset)
+			code += utils.Format("i32.const 0")
+			code += utils.Format("i32.const " + strconv.Itoa(t.Size()))
+			return code + utils.Format("memory.fill")
+		}
+		return ""
+	}
+	return a.assign(varBlock)
+}
+
+// exit 条件不成立时跳出到 label
+func (a *Wasm) exit(cond *parser.Expression, label string) (code string) {
+	if cond == nil {
+		return ""
+	}
+	if cond.IsConst() {
+		if !cond.Bool {
+			code += utils.Format("br " + label)
+		}
+		return code
+	}
+	code += a.push(cond, nil)
+	code += utils.Format("i32.eqz")
+	return code + utils.Format("br_if "+label)
+}
+
+// For 外层 block 为 break 的目标，loop 为回跳的目标，循环体包在内层 block 中，continue 跳出它执行增量部分
+func (a *Wasm) For(forBlock *parser.ForBlock) (code string) {
+	a.ctx.ForCount++
+	forBlock.Offset = a.ctx.ForCount
+	label := "$for_" + strconv.Itoa(forBlock.Offset)
+	a.ctx.PushLoop(label+"_continue", label+"_end")
+
+	if forBlock.Init != nil {
+		code += a.stmt(forBlock.Init)
+	}
+	code += utils.Format("block " + label + "_end")
+	utils.Count++
+	code += utils.Format("loop " + label)
+	utils.Count++
+	code += a.exit(forBlock.Condition, label+"_end")
+	code += utils.Format("block " + label + "_continue")
+	utils.Count++
+	return code
+}
+
+func (a *Wasm) EndFor(forBlock *parser.ForBlock) (code string) {
+	label := "$for_" + strconv.Itoa(forBlock.Offset)
+	utils.Count--
+	code += utils.Format("end")
+	if forBlock.Increment != nil {
+		code += a.stmt(forBlock.Increment)
+	}
+	code += utils.Format("br " + label)
+	utils.Count--
+	code += utils.Format("end")
+	utils.Count--
+	code += utils.Format("end")
+	a.ctx.PopLoop()
+	return code
+}
+
+func (a *Wasm) While(whileBlock *parser.WhileBlock) (code string) {
+	a.ctx.WhileCount++
+	whileBlock.Offset = a.ctx.WhileCount
+	label := "$while_" + strconv.Itoa(whileBlock.Offset)
+	a.ctx.PushLoop(label, label+"_end")
+
+	code += utils.Format("block " + label + "_end")
+	utils.Count++
+	code += utils.Format("loop " + label)
+	utils.Count++
+	return code + a.exit(whileBlock.Condition, label+"_end")
+}
+
+func (a *Wasm) EndWhile(whileBlock *parser.WhileBlock) (code string) {
+	code += utils.Format("br $while_" + strconv.Itoa(whileBlock.Offset))
+	utils.Count--
+	code += utils.Format("end")
+	utils.Count--
+	code += utils.Format("end")
+	a.ctx.PopLoop()
+	return code
+}
+
+func (a *Wasm) Break(breakBlock *parser.BreakBlock) string {
+	loop, ok := a.ctx.CurrentLoop()
+	if !ok {
+		return ""
+	}
+	return utils.Format("br " + loop.End)
+}
+
+func (a *Wasm) Continue(continueBlock *parser.ContinueBlock) string {
+	loop, ok := a.ctx.CurrentLoop()
+	if !ok || loop.Continue == "" {
+		return ""
+	}
+	return utils.Format("br " + loop.Continue)
+}
+
+// Switch 匹配值保存在局部变量中，每个分支是一个 block，不匹配时跳过，执行完毕后跳出整个 switch
+func (a *Wasm) Switch(switchBlock *parser.SwitchBlock) (code string) {
+	a.ctx.SwitchCount++
+	switchBlock.Offset = a.ctx.SwitchCount
+	label := switchLabel(switchBlock)
+	// switch 中的 continue 仍作用于外层循环
+	cont := ""
+	if loop, ok := a.ctx.CurrentLoop(); ok {
+		cont = loop.Continue
+	}
+	a.ctx.PushLoop(cont, label+"_end")
+
+	code += a.push(switchBlock.Value, nil)
+	code += utils.Format("local.set $" + a.frame.slots[switchBlock].local)
+	code += utils.Format("block " + label + "_end")
+	utils.Count++
+	return code
+}
+
+// Case default 分支在不匹配任何 case 时执行，因此与它的位置无关
+func (a *Wasm) Case(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) (code string) {
+	label := switchLabel(switchBlock) + "_case_" + strconv.Itoa(caseBlock.Offset)
+	code += utils.Format("block " + label)
+	utils.Count++
+
+	op, join := "eq", "or"
+	values := caseBlock.Values
+	if caseBlock.IsDefault {
+		op, join, values = "ne", "and", nil
+		for _, c := range switchBlock.Cases {
+			if !c.IsDefault {
+				values = append(values, c.Values...)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return code
+	}
+	t := switchBlock.Value.Type
+	local := utils.Format("local.get $" + a.frame.slots[switchBlock].local)
+	for i, value := range values {
+		code += local + a.constant(value, t)
+		code += utils.Format(valType(t) + "." + op)
+		if i > 0 {
+			code += utils.Format("i32." + join)
+		}
+	}
+	code += utils.Format("i32.eqz")
+	return code + utils.Format("br_if "+label)
+}
+
+func (a *Wasm) EndCase(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) (code string) {
+	code += utils.Format("br " + switchLabel(switchBlock) + "_end")
+	utils.Count--
+	return code + utils.Format("end")
+}
+
+func (a *Wasm) EndSwitch(switchBlock *parser.SwitchBlock) string {
+	utils.Count--
+	a.ctx.PopLoop()
+	return utils.Format("end")
+}
+
+// switchLabel 返回 switch 的标签前缀
+func switchLabel(switchBlock *parser.SwitchBlock) string {
+	return "$switch_" + strconv.Itoa(switchBlock.Offset)
+}
+
+// GenVarAddr 返回计算变量地址的指令，变量必须位于内存中
+func (a *Wasm) GenVarAddr(v *parser.VarBlock) string {
+	base, offset, _ := a.varAddr(v)
+	return base + addOffset(offset)
+}
+
+// funcName 返回函数在模块中的名称：外部函数使用其外部名，其余与汇编后端的标签一致
+func funcName(funcBlock *parser.FuncBlock) string {
+	if ext := arch.ExtName(funcBlock); ext != "" {
+		return ext
+	}
+	name := funcBlock.Name.String()
+	if name == "main" {
+		return name
+	}
+	return name + strconv.Itoa(len(funcBlock.Args))
+}
+
+// signature 返回函数的参数与返回值类型，recv 为真时第一个参数为接收者地址
+func signature(funcBlock *parser.FuncBlock, recv bool) string {
+	var b strings.Builder
+	if recv || len(funcBlock.Args) > 0 {
+		b.WriteString(" (param")
+		if recv {
+			b.WriteString(" i32")
+		}
+		for _, arg := range funcBlock.Args {
+			b.WriteString(" " + valType(arg.Type))
+		}
+		b.WriteString(")")
+	}
+	if len(funcBlock.Return) > 0 {
+		b.WriteString(" (result " + valType(funcBlock.Return[0]) + ")")
+	}
+	return b.String()
+}
@@ -1,7 +1,6 @@
 package x86
 
 import (
-	"cuteify/compile/arch"
 	"cuteify/compile/context"
 	"cuteify/compile/regmgr"
 	"cuteify/parser"
@@ -28,180 +27,77 @@ func (a *Cdecl) Call(call *parser.CallBlock) (code string) {
 	if call == nil || call.Func == nil {
 		return ""
 	}
-	fn := call.Func
+	code += genCall(a.ctx, call)
 
-	savedRegs := a.ctx.Reg.SaveAll(false)
-	for _, regCode := range savedRegs {
-		code += regCode
-	}
-
-	if call.ThisVar != nil {
-		thisAddr := genVarAddr(a.ctx, call.ThisVar)
-		code += utils.Format("push " + thisAddr + "; push this pointer")
-	}
-
-	for i := len(call.Args) - 1; i >= 0; i-- {
-		arg := call.Args[i]
-		if arg == nil {
-			continue
-		}
-		code += a.Exp(arg.Value, "push", "参数"+strconv.Itoa(i))
-	}
-
-	name := fn.Name.String()
-	if name != "main" {
-		name = name + strconv.Itoa(len(fn.Args))
-	}
-	code += utils.Format("call " + name)
-
-	argSize := arch.CalcArgsSize(call.Func)
-	if call.ThisVar != nil {
-		argSize += 4
-	}
-	if argSize > 0 {
+	if argSize := argsSize(call.Func); argSize > 0 {
 		code += utils.Format("add esp, " + strconv.Itoa(argSize) + "; 清理参数栈(cdecl)")
 	}
-
 	return code
 }
 
 func (a *Cdecl) Return(ret *parser.ReturnBlock) (code string) {
-	// 处理返回值：将值放入EAX寄存器
-	if ret != nil && len(ret.Value) != 0 {
-		// 强制使用EAX作为返回值寄存器
-		eaxReg := &regmgr.Reg{Name: "EAX", RegIndex: 0}
-		a.ctx.Reg.Force(eaxReg, a.ctx.Now, ret.Value[0])
-
-		if eaxReg.StoreCode != "" {
-			code += utils.Format(eaxReg.StoreCode)
-		}
-
-		// 编译返回表达式到EAX
-		code += a.Exp(ret.Value[0], "EAX", "return值存入EAX")
-		// 释放表达式使用的寄存器
-		a.ctx.Reg.Free(ret.Value[0])
-	}
-
-	code += utils.Format("; ---- 退出函数 ----")
-
-	// 清理局部变量栈空间
-	if a.ctx.StackSize > 0 {
-		code += utils.Format("add esp, " + strconv.Itoa(a.ctx.StackSize) + "; 清理局部变量栈空间(" + strconv.Itoa(a.ctx.StackSize) + "字节)")
-	}
-
-	// 恢复callee-saved寄存器
-	for i := len(regs) - 1; i >= 0; i-- {
-		r := regs[i]
-		if r.CalleeSave {
-			code += utils.Format("pop " + r.Name + "; 恢复" + r.Name)
-		}
-	}
-
-	// 恢复调用者的栈帧基址
-	code += utils.Format("leave")
-	code += utils.Format("ret\n")
-	return code
+	return gen(a.ctx).Return(ret, "ret")
 }
 
 func (a *Cdecl) Func(funcBlock *parser.FuncBlock) (code string) {
 	if funcBlock == nil {
 		return ""
 	}
-
-	argOffset := 8
-	if funcBlock.Class != nil {
-		argOffset += 4
-	}
-
-	for i := 0; i < len(funcBlock.Args); i++ {
-		arg := funcBlock.Args[i]
-		arg.Offset = argOffset
-		argOffset += arg.Type.Size()
-	}
-
-	code += utils.Format("push ebp; 保存调用者的栈帧基址")
-	code += utils.Format("mov ebp, esp; 设置当前栈帧基址")
-
-	csCount := 0
-	for i := 0; i < len(regs); i++ {
-		r := regs[i]
-		if r.CalleeSave {
-			code += utils.Format("push " + r.Name + "; 保存" + r.Name)
-			csCount++
-		}
-	}
-
-	a.ctx.StackSize = arch.SetupVarOffsets(a.ctx.Now, a.ctx.StackAlignment, -4*csCount)
-
-	if a.ctx.StackSize > 0 {
-		code += utils.Format("sub esp, " + strconv.Itoa(a.ctx.StackSize) + "; 分配栈空间(" + strconv.Itoa(a.ctx.StackSize) + "字节)")
-	}
-
-	code += utils.Format("; ---- 函数开始 ----")
-	return code
+	return genFuncFrame(a.ctx, funcBlock)
 }
 
 func (a *Cdecl) Exp(exp *parser.Expression, result, desc string) (code string) {
-	expc := expCom{ctx: a.ctx}
-	return expc.CompileExpr(exp, result, desc)
+	return gen(a.ctx).Expr(exp, result, desc)
 }
 
 func (a *Cdecl) For(forBlock *parser.ForBlock) (code string) {
-	if forBlock == nil {
-		return ""
-	}
-
-	forBlock.Offset = a.ctx.IfCount
-	forLabel := "for_" + strconv.Itoa(forBlock.Offset)
-	forEndLabel := forLabel + "_end"
+	return gen(a.ctx).For(forBlock)
+}
 
-	code += utils.Format("")
-	code += utils.Format("")
+func (a *Cdecl) EndFor(forBlock *parser.ForBlock) (code string) {
+	return gen(a.ctx).EndFor(forBlock)
+}
 
-	// 1. 初始化 - 编译变量定义
-	if forBlock.Init != nil {
-		// 编译初始化表达式到变量地址
-		code += a.Exp(forBlock.Init, "", "初始化for循环")
-	}
+func (a *Cdecl) While(whileBlock *parser.WhileBlock) (code string) {
+	return gen(a.ctx).While(whileBlock)
+}
 
-	code += utils.Format("")
-	code += utils.Format("")
+func (a *Cdecl) EndWhile(whileBlock *parser.WhileBlock) (code string) {
+	return gen(a.ctx).EndWhile(whileBlock)
+}
 
-	// 循环开始标签
-	code += utils.Format(forLabel + ": ; for循环开始")
+func (a *Cdecl) Break(breakBlock *parser.BreakBlock) (code string) {
+	return gen(a.ctx).Break()
+}
 
-	// 2. 条件检查 - 如果条件为假则跳出循环
-	if forBlock.Condition != nil && !forBlock.Condition.Bool {
-		// 将条件编译到一个临时寄存器
-		code += a.Exp(forBlock.Condition, forEndLabel, "for循环条件检查")
-	}
+func (a *Cdecl) Continue(continueBlock *parser.ContinueBlock) (code string) {
+	return gen(a.ctx).Continue()
+}
 
-	code += utils.Format("")
-	code += utils.Format("")
+func (a *Cdecl) Switch(switchBlock *parser.SwitchBlock) (code string) {
+	return gen(a.ctx).Switch(switchBlock)
+}
 
-	return code
+func (a *Cdecl) Case(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) (code string) {
+	return gen(a.ctx).Case(switchBlock, caseBlock)
 }
 
-func (a *Cdecl) EndFor(forBlock *parser.ForBlock) (code string) {
-	code += utils.Format("")
-	code += utils.Format("")
+func (a *Cdecl) EndCase(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) (code string) {
+	return gen(a.ctx).EndCase(switchBlock, caseBlock)
+}
 
-	forLabel := "for_" + strconv.Itoa(forBlock.Offset)
-	code += a.Exp(forBlock.Increment, "", "for循环增量")
-	code += utils.Format("jmp " + forLabel + "; for循环")
-	code += utils.Format(forLabel + "_end: ; for循环结束")
-	return
+func (a *Cdecl) EndSwitch(switchBlock *parser.SwitchBlock) (code string) {
+	return gen(a.ctx).EndSwitch(switchBlock)
 }
 
 func (a *Cdecl) Var(varBlock *parser.VarBlock) (code string) {
-	addr := genVarAddr(a.ctx, varBlock)
-	if varBlock.Value == nil {
-		return
-	}
-	code += a.ctx.Arch.Exp(varBlock.Value, addr, "设置变量"+varBlock.Name.String())
-	return
+	return gen(a.ctx).Var(varBlock)
 }
 
 func (a *Cdecl) GenVarAddr(varBlock *parser.VarBlock) (addr string) {
-	return genVarAddr(a.ctx, varBlock)
+	return gen(a.ctx).VarAddr(varBlock)
+}
+
+func (a *Cdecl) Data() (code string) {
+	return gen(a.ctx).Data()
 }
@@ -0,0 +1,129 @@
+package x86
+
+import (
+	"cuteify/compile/arch"
+	"cuteify/compile/context"
+	"cuteify/compile/regmgr"
+	"cuteify/parser"
+)
+
+// Dispatcher 按函数选择调用约定：函数体使用自身通过 build callconv(...) 指定的约定生成序言和尾声，
+// 调用处使用被调函数的约定传参，未指定的函数使用默认约定。
+// 除调用和函数帧以外的代码生成与调用约定无关，交给默认约定处理。
+type Dispatcher struct {
+	ctx   *context.Context
+	def   arch.Arch
+	convs map[string]arch.Arch
+}
+
+func NewDispatcher(ctx *context.Context, conv string) *Dispatcher {
+	a := &Dispatcher{
+		ctx: ctx,
+		convs: map[string]arch.Arch{
+			"cdecl":    NewCdecl(ctx),
+			"stdcall":  NewStdcall(ctx),
+			"fastcall": NewFastcall(ctx),
+		},
+	}
+	a.def = a.convs[conv]
+	if a.def == nil {
+		panic("编译器内部错误: 未知的调用约定 " + conv)
+	}
+	ctx.Reg = regmgr.NewRegMgr(regs, a.GenVarAddr)
+	return a
+}
+
+// conv 返回函数使用的调用约定
+func (a *Dispatcher) conv(funcBlock *parser.FuncBlock) arch.Arch {
+	if funcBlock != nil {
+		if c := a.convs[funcBlock.CallConv()]; c != nil {
+			return c
+		}
+	}
+	return a.def
+}
+
+func (a *Dispatcher) Info() string { return a.def.Info() }
+
+func (a *Dispatcher) Call(call *parser.CallBlock) string {
+	if call == nil || call.Func == nil {
+		return ""
+	}
+	return a.conv(call.Func).Call(call)
+}
+
+func (a *Dispatcher) Return(ret *parser.ReturnBlock) string {
+	return a.conv(a.ctx.CurrentFunc).Return(ret)
+}
+
+func (a *Dispatcher) Func(funcBlock *parser.FuncBlock) string {
+	if funcBlock == nil {
+		return ""
+	}
+	return a.conv(funcBlock).Func(funcBlock)
+}
+
+func (a *Dispatcher) Exp(exp *parser.Expression, result, desc string) string {
+	return a.def.Exp(exp, result, desc)
+}
+
+func (a *Dispatcher) For(forBlock *parser.ForBlock) string {
+	return a.def.For(forBlock)
+}
+
+func (a *Dispatcher) EndFor(forBlock *parser.ForBlock) string {
+	return a.def.EndFor(forBlock)
+}
+
+func (a *Dispatcher) While(whileBlock *parser.WhileBlock) string {
+	return a.def.While(whileBlock)
+}
+
+func (a *Dispatcher) EndWhile(whileBlock *parser.WhileBlock) string {
+	return a.def.EndWhile(whileBlock)
+}
+
+func (a *Dispatcher) Break(breakBlock *parser.BreakBlock) string {
+	return a.def.Break(breakBlock)
+}
+
+func (a *Dispatcher) Continue(continueBlock *parser.ContinueBlock) string {
+	return a.def.Continue(continueBlock)
+}
+
+func (a *Dispatcher) Switch(switchBlock *parser.SwitchBlock) string {
+	return a.def.Switch(switchBlock)
+}
+
+func (a *Dispatcher) Case(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) string {
+	return a.def.Case(switchBlock, caseBlock)
+}
+
+func (a *Dispatcher) EndCase(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) string {
+	return a.def.EndCase(switchBlock, caseBlock)
+}
+
+func (a *Dispatcher) EndSwitch(switchBlock *parser.SwitchBlock) string {
+	return a.def.EndSwitch(switchBlock)
+}
+
+func (a *Dispatcher) Var(varBlock *parser.VarBlock) string {
+	return a.def.Var(varBlock)
+}
+
+func (a *Dispatcher) GenVarAddr(varBlock *parser.VarBlock) (addr string) {
+	return gen(a.ctx).VarAddr(varBlock)
+}
+
+// Data 输出数据段；通过接口动态分派的方法按接口方法的约定（默认约定）调用，检查阶段已拒绝另行指定调用约定的方法
+func (a *Dispatcher) Data() string {
+	for _, vt := range a.ctx.VTables {
+		for _, m := range vt.Iface.Methods {
+			name := m.(*parser.FuncBlock).Name.Last()
+			if method := parser.FindMethod(vt.Struct, name); a.conv(method) != a.def {
+				panic("编译器内部错误: 方法 " + method.Name.String() + " 通过接口调用，不能指定调用约定 " + method.CallConv())
+			}
+		}
+	}
+	return a.def.Data()
+}
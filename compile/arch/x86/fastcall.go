@@ -1,116 +1,254 @@
 package x86
 
-/*
-// Fastcall: x86 fastcall 调用约定（简化实现：ECX、EDX 放前两个 4 字节参数，其余压栈，调用者清理压栈部分）
-// Fastcall 实现 x86 fastcall 调用约定（ECX、EDX 放前两个 4 字节参数，其余压栈）。
-type Fastcall struct{}
+import (
+	"cuteify/compile/arch/nasm"
+	"cuteify/compile/context"
+	"cuteify/compile/regmgr"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strconv"
+)
+
+// fastcallRegs fastcall 传参寄存器，依次存放前两个 4 字节参数（方法的接收者地址占用 ECX）
+var fastcallRegs = []string{"ECX", "EDX"}
+
+// Fastcall 实现 x86 fastcall 调用约定（ECX、EDX 放前两个 4 字节参数，其余从右到左压栈，被调者通过 ret N 清理）。
+type Fastcall struct {
+	ctx *context.Context
+}
+
+func NewFastcall(ctx *context.Context) *Fastcall {
+	a := &Fastcall{
+		ctx: ctx,
+	}
+	ctx.Reg = regmgr.NewRegMgr(regs, a.GenVarAddr)
+	return a
+}
 
 func (a *Fastcall) Info() string { return "x86 fastcall" }
-func (a *Fastcall) Regs() []string {return regs}
 
 func (a *Fastcall) Call(call *parser.CallBlock) string {
 	if call == nil || call.Func == nil {
 		return ""
 	}
-	fn := call.Func
-	code := ""
-	usedECX, usedEDX := false, false
-	pushed := 0
+	// 压栈部分由被调函数清理
+	return genFastcall(a.ctx, call)
+}
 
-	// 1) 先把需要压栈的参数（除去前两个寄存器可容纳者）逆序压栈
-	for i := len(call.Args) - 1; i >= 0; i-- {
-		arg := call.Args[i]
-		if arg.Type == nil && arg.Defind != nil {
-			arg.Type = arg.Defind.Type
+func (a *Fastcall) Return(ret *parser.ReturnBlock) string {
+	retInst := "ret"
+	if a.ctx.CurrentFunc != nil {
+		if size := fastcallStackSize(a.ctx.CurrentFunc); size > 0 {
+			retInst += " " + strconv.Itoa(size) + "; 清理参数栈(fastcall)"
 		}
-		sz := 4
-		if arg.Type != nil {
-			sz = arg.Type.Size()
+	}
+	return gen(a.ctx).Return(ret, retInst)
+}
+
+func (a *Fastcall) Func(funcBlock *parser.FuncBlock) string {
+	if funcBlock == nil {
+		return ""
+	}
+	return genFrame(a.ctx, funcBlock, fastcallLayout(funcBlock))
+}
+
+func (a *Fastcall) Exp(exp *parser.Expression, result, desc string) string {
+	return gen(a.ctx).Expr(exp, result, desc)
+}
+
+func (a *Fastcall) For(forBlock *parser.ForBlock) string {
+	return gen(a.ctx).For(forBlock)
+}
+
+func (a *Fastcall) EndFor(forBlock *parser.ForBlock) (code string) {
+	return gen(a.ctx).EndFor(forBlock)
+}
+
+func (a *Fastcall) While(whileBlock *parser.WhileBlock) string {
+	return gen(a.ctx).While(whileBlock)
+}
+
+func (a *Fastcall) EndWhile(whileBlock *parser.WhileBlock) string {
+	return gen(a.ctx).EndWhile(whileBlock)
+}
+
+func (a *Fastcall) Break(breakBlock *parser.BreakBlock) string {
+	return gen(a.ctx).Break()
+}
+
+func (a *Fastcall) Continue(continueBlock *parser.ContinueBlock) string {
+	return gen(a.ctx).Continue()
+}
+
+func (a *Fastcall) Switch(switchBlock *parser.SwitchBlock) string {
+	return gen(a.ctx).Switch(switchBlock)
+}
+
+func (a *Fastcall) Case(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) string {
+	return gen(a.ctx).Case(switchBlock, caseBlock)
+}
+
+func (a *Fastcall) EndCase(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) string {
+	return gen(a.ctx).EndCase(switchBlock, caseBlock)
+}
+
+func (a *Fastcall) EndSwitch(switchBlock *parser.SwitchBlock) string {
+	return gen(a.ctx).EndSwitch(switchBlock)
+}
+
+func (a *Fastcall) Var(varBlock *parser.VarBlock) string {
+	return gen(a.ctx).Var(varBlock)
+}
+
+func (a *Fastcall) GenVarAddr(varBlock *parser.VarBlock) (addr string) {
+	return gen(a.ctx).VarAddr(varBlock)
+}
+
+func (a *Fastcall) Data() (code string) {
+	return gen(a.ctx).Data()
+}
+
+// fastcallLayout 返回 fastcall 的参数布局：接收者地址在 ECX，之后从左到右前两个不超过 4 字节的标量参数依次放入剩余的传参寄存器
+func fastcallLayout(funcBlock *parser.FuncBlock) argLayout {
+	layout := argLayout{recv: "ECX", stackStart: 8}
+	free := fastcallRegs
+	if funcBlock.Class != nil {
+		free = free[1:]
+	}
+	for _, arg := range funcBlock.Args {
+		if len(free) == 0 {
+			break
 		}
-		// 仅当不是第 0、1 个且为 4 字节时才考虑寄存器，其他情况直接压栈
-		if (i == 0 || i == 1) && sz == 4 {
+		if !fastcallRegArg(arg.Type) {
 			continue
 		}
-		code += a.Exp(arg.Value, "push", "参数")
-		pushed += sz
+		layout.regArgs = append(layout.regArgs, regArg{arg: arg, reg: free[0]})
+		free = free[1:]
 	}
+	return layout
+}
 
-	// 2) 安排前两个寄存器参数（4 字节）
-	for i := 0; i < len(call.Args) && i < 2; i++ {
-		arg := call.Args[i]
-		if arg.Type == nil && arg.Defind != nil {
-			arg.Type = arg.Defind.Type
-		}
-		if arg.Type != nil && arg.Type.Size() == 4 {
-			if i == 0 && !usedECX {
-				code += a.Exp(arg.Value, "ECX", "参数寄存器")
-				usedECX = true
-				continue
-			}
-			if i == 1 && !usedEDX {
-				code += a.Exp(arg.Value, "EDX", "参数寄存器")
-				usedEDX = true
-				continue
-			}
+// fastcallRegArg 判断类型为 t 的参数能否经寄存器传递，结构体、切片和接口值总是压栈
+func fastcallRegArg(t typeSys.Type) bool {
+	switch t.(type) {
+	case *typeSys.StructType, *typeSys.SliceType, *typeSys.InterfaceType:
+		return false
+	}
+	return t.Size() <= 4
+}
+
+// fastcallStackSize 返回 fastcall 调用中压栈参数的总字节数
+func fastcallStackSize(funcBlock *parser.FuncBlock) int {
+	layout := fastcallLayout(funcBlock)
+	size := 0
+	for _, arg := range funcBlock.Args {
+		if layout.regOf(arg) == "" {
+			size += align4(arg.Type.Size())
 		}
-		// 不能放寄存器的（非 4 字节或没位置），补充压栈（按从左到右顺序，因此用 push）
-		code += a.Exp(arg.Value, "push", "参数")
-		if arg.Type != nil {
-			pushed += arg.Type.Size()
-		} else {
-			pushed += 4
+	}
+	return size
+}
+
+// genFastcall 生成 fastcall 的参数传递和 call 指令
+// 压栈参数从右到左压入；寄存器参数求值期间通过 regmgr 预留 ECX、EDX，避免被表达式分配或溢出复用
+// 含有函数调用或除法的寄存器参数会破坏 ECX、EDX，先求值压栈，最后弹出到对应寄存器
+func genFastcall(ctx *context.Context, call *parser.CallBlock) (code string) {
+	savedRegs := ctx.Reg.SaveAll(false)
+	for _, regCode := range savedRegs {
+		code += regCode
+	}
+
+	layout := fastcallLayout(call.Func)
+	argReg := func(i int) string {
+		if i >= len(call.Func.Args) {
+			return ""
 		}
+		return layout.regOf(call.Func.Args[i])
 	}
 
-	// 3) 生成 call
-	name := fn.Name.String()
-	if name != "main" {
-		name = name + strconv.Itoa(len(fn.Args))
+	for i := len(call.Args) - 1; i >= 0; i-- {
+		arg := call.Args[i]
+		if arg == nil || argReg(i) != "" {
+			continue
+		}
+		code += genPushArg(ctx, arg, "参数"+strconv.Itoa(i))
 	}
-	code += utils.Format("call " + name)
-	// 4) 调用者仅清理由我们 push 的部分（寄存器不计入）
-	if pushed > 0 {
-		code += utils.Format("add esp, " + strconv.Itoa(pushed))
+
+	// 复杂的寄存器参数先压栈
+	var pushed []int
+	for i, arg := range call.Args {
+		if arg == nil || argReg(i) == "" || simpleRegArg(arg.Value) {
+			continue
+		}
+		code += ctx.Arch.Exp(arg.Value, "push", "参数"+strconv.Itoa(i))
+		pushed = append(pushed, i)
 	}
-	return code
-}
 
-func (a *Fastcall) Return(ret *parser.ReturnBlock) string {
-	if ret == nil || len(ret.Value) == 0 {
-		return utils.Format("ret\n")
+	var reserved []string
+	if call.ThisVar != nil {
+		reserved = append(reserved, fastcallRegs[0])
+	}
+	for _, ra := range layout.regArgs {
+		reserved = append(reserved, ra.reg)
+	}
+	for _, name := range reserved {
+		code += ctx.Reg.Reserve(name)
 	}
-	code := a.Exp(ret.Value[0], "EAX", "return")
-	code += utils.Format("ret\n")
-	return code
-}
 
-func (a *Fastcall) Func(funcBlock *parser.FuncBlock) string {
-	if funcBlock == nil {
-		return ""
+	for i, arg := range call.Args {
+		if arg == nil || argReg(i) == "" || !simpleRegArg(arg.Value) {
+			continue
+		}
+		code += ctx.Arch.Exp(arg.Value, argReg(i), "参数"+strconv.Itoa(i))
 	}
-	name := funcBlock.Name.String()
-	if name != "main" {
-		name = name + strconv.Itoa(len(funcBlock.Args))
+	for k := len(pushed) - 1; k >= 0; k-- {
+		code += utils.Format("pop " + argReg(pushed[k]) + "; 参数" + strconv.Itoa(pushed[k]))
 	}
-	code := utils.Format("; ==============================")
-	code += utils.Format("; Function:" + name)
-	code += utils.Format(name + ":")
-	code += utils.Format("push ebp")
-	code += utils.Format("mov ebp, esp")
 
-	// Fastcall 简化实现，暂不处理栈分配
-	// 如需完整支持，可参考 cdecl 的实现
+	if call.ThisVar != nil {
+		ref := gen(ctx).VarRef(call.ThisVar)
+		switch iface, _ := call.ThisVar.Type.(*typeSys.InterfaceType); {
+		case iface != nil:
+			code += utils.Format("mov ECX, DWORD" + ref + "; 接收者地址")
+			code += gen(ctx).IfaceCall(call, iface)
+			releaseRegs(ctx, reserved)
+			return code
+		case ref == "["+selfReg+"]":
+			code += utils.Format("mov ECX, " + selfReg + "; 接收者地址")
+		default:
+			code += utils.Format("lea ECX, " + ref + "; 取接收者地址")
+		}
+	}
 
+	code += utils.Format("call " + nasm.FuncLabel(call.Func))
+	releaseRegs(ctx, reserved)
 	return code
 }
 
-func (a *Fastcall) Exp(exp *parser.Expression, result, desc string) string {
-	if exp != nil && exp.Call != nil {
-		code := a.Call(exp.Call)
-		code += genericExp(exp, result, desc)
-		return code
+// releaseRegs 释放调用前预留的传参寄存器
+func releaseRegs(ctx *context.Context, names []string) {
+	for _, name := range names {
+		ctx.Reg.Release(name)
 	}
-	return genericExp(exp, result, desc)
 }
 
-// 无额外辅助函数（通用表达式逻辑在 exp_generic.go）。*/
+// simpleRegArg 判断寄存器参数能否在预留 ECX、EDX 后直接求值：常量、变量或两者之间不含除法的二元运算
+// 其余表达式可能需要更多寄存器，或通过调用、idiv 破坏 ECX、EDX
+func simpleRegArg(exp *parser.Expression) bool {
+	leaf := func(e *parser.Expression) bool {
+		return e != nil && e.Unary == "" && e.Index == nil && e.Call == nil && e.Separator == "" &&
+			(e.IsConst() || e.Var != nil && e.Var.Value == nil)
+	}
+	if leaf(exp) {
+		return true
+	}
+	if exp.Unary != "" || exp.Index != nil || exp.Call != nil {
+		return false
+	}
+	switch exp.Separator {
+	case "+", "-", "*":
+		return leaf(exp.Left) && leaf(exp.Right)
+	}
+	return false
+}
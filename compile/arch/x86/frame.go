@@ -0,0 +1,135 @@
+package x86
+
+import (
+	"cuteify/compile/arch"
+	"cuteify/compile/arch/nasm"
+	"cuteify/compile/context"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strconv"
+)
+
+// selfReg 方法中保存接收者地址（self）的寄存器，不参与寄存器分配
+const selfReg = "ESI"
+
+// argsSize 返回调用方压入的参数总字节数（方法包含接收者地址），每个参数按 4 字节对齐
+func argsSize(funcBlock *parser.FuncBlock) int {
+	size := 0
+	for _, arg := range funcBlock.Args {
+		size += align4(arg.Type.Size())
+	}
+	if funcBlock.Class != nil {
+		size += 4
+	}
+	return size
+}
+
+// argLayout 参数的传递方式
+type argLayout struct {
+	recv       string   // 方法的接收者地址所在位置
+	stackStart int      // 第一个压栈参数相对 ebp 的偏移
+	regArgs    []regArg // 经寄存器传入的参数（fastcall）
+}
+
+// regArg 经寄存器传入的参数及其寄存器
+type regArg struct {
+	arg *parser.ArgBlock
+	reg string
+}
+
+// regOf 返回参数所在的寄存器，压栈传入时为空
+func (l argLayout) regOf(arg *parser.ArgBlock) string {
+	for _, ra := range l.regArgs {
+		if ra.arg == arg {
+			return ra.reg
+		}
+	}
+	return ""
+}
+
+// stackLayout 返回全部参数压栈时的布局（cdecl/stdcall）：方法的接收者地址位于 [ebp+8]，参数随后
+func stackLayout(funcBlock *parser.FuncBlock) argLayout {
+	layout := argLayout{recv: "DWORD[ebp+8]", stackStart: 8}
+	if funcBlock.Class != nil {
+		layout.stackStart += 4
+	}
+	return layout
+}
+
+// genFuncFrame 生成函数序言：建立栈帧、保存 callee-saved 寄存器、分配局部变量空间
+// 方法的接收者地址位于 [ebp+8]，序言中载入 selfReg
+func genFuncFrame(ctx *context.Context, funcBlock *parser.FuncBlock) (code string) {
+	return genFrame(ctx, funcBlock, stackLayout(funcBlock))
+}
+
+// genFrame 按参数布局 layout 生成函数序言，经寄存器传入的参数存入 callee-saved 寄存器之下的栈槽位
+func genFrame(ctx *context.Context, funcBlock *parser.FuncBlock, layout argLayout) (code string) {
+	argOffset := layout.stackStart
+
+	for i := 0; i < len(funcBlock.Args); i++ {
+		arg := funcBlock.Args[i]
+		if layout.regOf(arg) != "" {
+			continue
+		}
+		arg.Offset = argOffset
+		argOffset += align4(arg.Type.Size())
+	}
+
+	code, csCount := gen(ctx).Prologue(funcBlock, layout.recv)
+
+	offset := -4 * csCount
+	for _, ra := range layout.regArgs {
+		offset -= 4
+		ra.arg.Offset = offset
+	}
+	ctx.StackSize = arch.SetupVarOffsets(ctx.Now, ctx.StackAlignment, offset)
+
+	code += gen(ctx).AllocFrame()
+
+	for _, ra := range layout.regArgs {
+		code += utils.Format("mov DWORD[ebp" + strconv.Itoa(ra.arg.Offset) + "], " + ra.reg + "; 保存参数" + ra.arg.Name.String())
+	}
+
+	code += utils.Format("; ---- 函数开始 ----")
+	return code
+}
+
+// genCall 生成参数压栈（从右到左）和 call 指令，不包含参数栈清理
+// 方法调用的接收者地址最后压栈，位于被调函数的 [ebp+8]
+func genCall(ctx *context.Context, call *parser.CallBlock) (code string) {
+	savedRegs := ctx.Reg.SaveAll(false)
+	for _, regCode := range savedRegs {
+		code += regCode
+	}
+
+	for i := len(call.Args) - 1; i >= 0; i-- {
+		arg := call.Args[i]
+		if arg == nil {
+			continue
+		}
+		code += genPushArg(ctx, arg, "参数"+strconv.Itoa(i))
+	}
+
+	if call.ThisVar != nil {
+		if iface, ok := call.ThisVar.Type.(*typeSys.InterfaceType); ok {
+			code += utils.Format("push DWORD" + gen(ctx).VarRef(call.ThisVar) + "; 接收者地址")
+			return code + gen(ctx).IfaceCall(call, iface)
+		}
+		code += gen(ctx).PushReceiver(call.ThisVar)
+	}
+
+	code += utils.Format("call " + nasm.FuncLabel(call.Func))
+	return code
+}
+
+// genPushArg 压入一个参数，切片和接口值占两个槽位
+func genPushArg(ctx *context.Context, arg *parser.ArgBlock, desc string) string {
+	switch t := arg.Type.(type) {
+	case *typeSys.InterfaceType:
+		return gen(ctx).PushIface(arg.Value, t, desc)
+	case *typeSys.SliceType:
+		return gen(ctx).PushSlice(arg.Value, desc)
+	}
+	return ctx.Arch.Exp(arg.Value, "push", desc)
+}
@@ -0,0 +1,966 @@
+package x86
+
+import (
+	"cuteify/compile/arch/nasm"
+	"cuteify/compile/context"
+	"cuteify/compile/ir"
+	"cuteify/compile/regalloc"
+	"cuteify/parser"
+	"cuteify/utils"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Backend 基于 IR 的 x86 后端，实现 arch.Backend。
+//
+// 有结果的值放在栈帧中（[ebp-N]），不同时活跃的值共用位置；常量、地址与参数在使用处直接生成，不占用位置。
+// 结果只被紧随其后的指令使用的值留在 EAX 中，条件跳转前的比较直接设置标志位。phi 在前驱的末尾复制，
+// 关键边在生成代码之前拆分。只使用 EAX、ECX、EDX，含有内联汇编的函数额外保存 EBX、ESI、EDI。
+// 上下文选择 GraphAlloc 时，由 regalloc 将值分配到 EBX、ESI、EDI 中（用到的在序言中保存），溢出的值才放在栈帧中。
+// 与直接从语法树生成时一样，64 位整数只保留低 32 位，写入内存与传参时按类型扩展为 8 字节；不支持浮点数。
+type Backend struct {
+	ctx  *context.Context
+	conv string // 函数未通过 build callconv 指定时使用的调用约定
+}
+
+// NewBackend 创建 IR 后端，conv 为默认调用约定（cdecl、stdcall 或 fastcall）
+func NewBackend(ctx *context.Context, conv string) *Backend {
+	switch conv {
+	case "cdecl", "stdcall", "fastcall":
+	default:
+		panic("编译器内部错误: 未知的调用约定 " + conv)
+	}
+	return &Backend{ctx: ctx, conv: conv}
+}
+
+func (b *Backend) Info() string { return "x86 " + b.conv + " (IR)" }
+
+func (b *Backend) Data() string { return gen(b.ctx).Data() }
+
+// convOf 返回函数使用的调用约定
+func (b *Backend) convOf(fn *parser.FuncBlock) string {
+	switch c := fn.CallConv(); c {
+	case "cdecl", "stdcall", "fastcall":
+		return c
+	}
+	return b.conv
+}
+
+// paramLoc 调用时参数的传递位置：寄存器，或相对被调函数 ebp 的栈上偏移
+type paramLoc struct {
+	reg string
+	off int
+}
+
+// paramLayout 返回按调用约定传递参数的位置，以及压栈参数的总字节数（每个参数按 4 字节对齐）。
+// fastcall 中前两个不超过 4 字节的标量参数（依次包括结果地址与接收者地址）经 ECX、EDX 传递
+func paramLayout(params []ir.Param, conv string) (locs []paramLoc, stack int) {
+	free := []string{}
+	if conv == "fastcall" {
+		free = fastcallRegs
+	}
+	locs = make([]paramLoc, len(params))
+	for i, p := range params {
+		if len(free) > 0 && !p.Agg && p.Size <= 4 {
+			locs[i].reg, free = free[0], free[1:]
+			continue
+		}
+		locs[i].off = 8 + stack
+		stack += align4(p.Size)
+	}
+	return locs, stack
+}
+
+func align4(n int) int {
+	return (n + 3) &^ 3
+}
+
+// Func 生成函数的序言、各基本块与尾声
+func (b *Backend) Func(f *ir.Func) string {
+	ir.SplitCriticalEdges(f)
+	g := &funcGen{
+		Backend: b,
+		f:       f,
+		label:   nasm.FuncLabel(f.Decl),
+		conv:    b.convOf(f.Decl),
+		users:   make(map[*ir.Value][]use),
+		acc:     make(map[*ir.Value]bool),
+		warm:    make(map[*ir.Value]*ir.Value),
+		tail:    make(map[*ir.Block]*ir.Value),
+		folded:  make(map[*ir.Value]bool),
+		memLoad: make(map[*ir.Value]bool),
+		fused:   make(map[*ir.Value]bool),
+		home:    make(map[*ir.Value]int),
+		reg:     make(map[*ir.Value]string),
+		color:   make(map[*ir.Value]int),
+		slots:   make(map[*ir.Slot]int),
+		skip:    make(map[*ir.Block]bool),
+	}
+	g.analyze()
+	if b.ctx.RegAlloc == context.GraphAlloc {
+		g.allocate()
+	}
+	g.layout()
+	g.findForwarders()
+	g.prologue()
+	var blocks []*ir.Block
+	for _, block := range f.Blocks {
+		if !g.skip[block] {
+			blocks = append(blocks, block)
+		}
+	}
+	for i, block := range blocks {
+		g.next = nil
+		if i+1 < len(blocks) {
+			g.next = blocks[i+1]
+		}
+		g.block(block)
+	}
+	return g.code.String()
+}
+
+// use 值的一次使用：作为 v 的第 i 个参数，v 为空时为基本块 b 的控制值
+type use struct {
+	v *ir.Value
+	i int
+	b *ir.Block
+}
+
+// funcGen 生成一个函数时的状态
+type funcGen struct {
+	*Backend
+	f     *ir.Func
+	label string
+	conv  string
+	code  strings.Builder
+
+	users   map[*ir.Value][]use
+	acc     map[*ir.Value]bool      // 结果留在 EAX 中、由下一条指令直接使用的值
+	warm    map[*ir.Value]*ir.Value // 指令可以从 EAX 读取的前一个值（该值另有使用，仍存入位置）
+	tail    map[*ir.Block]*ir.Value // 基本块最后一个有结果的值，跳转时仍在 EAX 中
+	folded  map[*ir.Value]bool      // 只作为地址使用、折叠进寻址方式的 基址+常量
+	memLoad map[*ir.Value]bool      // 在使用处直接作为内存操作数的载入
+	fused   map[*ir.Value]bool      // 只设置标志位、由条件跳转使用的比较
+	home    map[*ir.Value]int       // 值在栈帧中的位置
+	reg     map[*ir.Value]string    // 分配到寄存器的值（GraphAlloc），这些值没有栈帧中的位置
+	color   map[*ir.Value]int       // 共用栈帧中位置的值的编号（不使用 GraphAlloc 时），编号相同的值位置相同
+	slots   map[*ir.Slot]int        // 栈上对象的位置
+	params  []int                   // 参数在栈帧中的位置
+	regs    []paramLoc              // 参数的传递位置
+	stack   int                     // 压栈参数的总字节数
+	saved   []string                // 序言中保存的寄存器
+	frame   int                     // 序言中分配的栈空间
+
+	skip map[*ir.Block]bool // 不生成代码、跳转时直接转到其后继的基本块
+	next *ir.Block          // 布局中的下一个基本块，跳转到它时可以省略
+	cc   string             // 合并的比较设置的条件码
+}
+
+func (g *funcGen) emit(text string) {
+	g.code.WriteString(utils.Format(text))
+}
+
+// analyze 统计值的使用，确定哪些值折叠进寻址方式、留在 EAX 中或与条件跳转合并
+func (g *funcGen) analyze() {
+	clobbered := make(map[string]bool)
+	for _, b := range g.f.Blocks {
+		for _, v := range b.Values {
+			checkType(v.Type)
+			for i, arg := range v.Args {
+				g.users[arg] = append(g.users[arg], use{v: v, i: i, b: b})
+			}
+			if v.Op == ir.OpAsm {
+				for _, r := range calleeSavedRe.FindAllString(v.Aux.(*ir.Asm).Build.Asm, -1) {
+					clobbered[calleeSavedRegs[strings.ToUpper(r)]] = true
+				}
+			}
+		}
+		if b.Control != nil {
+			g.users[b.Control] = append(g.users[b.Control], use{b: b})
+		}
+	}
+	for _, r := range []string{"EBX", "ESI", "EDI"} {
+		if clobbered[r] {
+			g.saved = append(g.saved, r)
+		}
+	}
+	for _, b := range g.f.Blocks {
+		for _, v := range b.Values {
+			g.folded[v] = g.foldable(v)
+		}
+	}
+	for _, b := range g.f.Blocks {
+		for _, v := range b.Values {
+			g.memLoad[v] = g.loadFoldable(v)
+		}
+	}
+	for _, b := range g.f.Blocks {
+		var prev *ir.Value
+		for _, v := range b.Values {
+			if !g.emits(v) {
+				continue
+			}
+			if prev != nil && g.accFor(prev, v) {
+				if g.usedOnlyBy(prev, v) {
+					g.acc[prev] = true
+				} else {
+					g.warm[v] = prev
+				}
+			}
+			prev = v
+		}
+		if prev == nil || prev.Type == ir.Void {
+			continue
+		}
+		g.tail[b] = prev
+		if b.Control != prev || len(g.users[prev]) != 1 {
+			continue
+		}
+		switch {
+		case b.Kind == ir.BlockIf && prev.Op.IsCompare():
+			g.fused[prev] = true
+		case b.Kind == ir.BlockIf || b.Kind == ir.BlockSwitch || b.Kind == ir.BlockRet:
+			g.acc[prev] = true
+		}
+	}
+}
+
+// calleeSavedRe 匹配内联汇编中出现的被调者保存寄存器，函数在序言中保存内联汇编用到的这些寄存器
+var calleeSavedRe = regexp.MustCompile(`(?i)\b(e?b[xlh]|e?si|e?di)\b`)
+
+var calleeSavedRegs = map[string]string{
+	"EBX": "EBX", "BX": "EBX", "BL": "EBX", "BH": "EBX",
+	"ESI": "ESI", "SI": "ESI", "EDI": "EDI", "DI": "EDI",
+}
+
+// checkType 检查值的类型是否受支持：x86 后端不处理浮点数
+func checkType(t ir.Type) {
+	if t.IsFloat() {
+		panic("编译器内部错误: x86 IR 后端不支持 " + t.String())
+	}
+}
+
+// remat 报告值是否在使用处直接生成：整数常量、字符串与虚表地址、栈上对象与全局变量的地址（可带常量偏移）
+func (g *funcGen) remat(v *ir.Value) bool {
+	switch v.Op {
+	case ir.OpConst, ir.OpString, ir.OpVTable:
+		return true
+	}
+	return isAddr(v)
+}
+
+// isAddr 报告值是否为栈上对象、参数或全局变量的地址（可带常量偏移）
+func isAddr(v *ir.Value) bool {
+	switch v.Op {
+	case ir.OpAddr, ir.OpArgAddr, ir.OpGlobal:
+		return true
+	case ir.OpAdd:
+		return v.Args[1].IsConst() && isAddr(v.Args[0])
+	}
+	return false
+}
+
+// foldable 报告 基址+常量 是否只作为 Load/Store/Move/Zero 的地址使用，可以折叠进寻址方式
+func (g *funcGen) foldable(v *ir.Value) bool {
+	if v.Op != ir.OpAdd || v.Type != ir.Ptr || !v.Args[1].IsConst() || g.remat(v) || len(g.users[v]) == 0 {
+		return false
+	}
+	for _, u := range g.users[v] {
+		if !isAddrUse(u) {
+			return false
+		}
+	}
+	return true
+}
+
+// loadFoldable 报告从栈上对象、参数或全局变量载入的 4 字节值能否在使用处直接作为内存操作数：
+// 所有使用都在同一基本块中，且载入与使用之间没有可能写内存的指令
+func (g *funcGen) loadFoldable(v *ir.Value) bool {
+	if v.Op != ir.OpLoad || v.Type.Size() != 4 || !isAddr(v.Args[0]) || len(g.users[v]) == 0 {
+		return false
+	}
+	end := g.lastUse(v)
+	if end < 0 {
+		return false
+	}
+	values := v.Block.Values
+	for i := slices.Index(values, v) + 1; i < end; i++ {
+		if writesMem(values[i].Op) {
+			return false
+		}
+	}
+	return true
+}
+
+// lastUse 返回 v 在其所在基本块中最后一次使用的下标（经过折叠的地址时取地址的使用），控制值的使用为基本块的长度；
+// 在其他基本块中使用时返回 -1
+func (g *funcGen) lastUse(v *ir.Value) int {
+	last := 0
+	for _, u := range g.users[v] {
+		if u.b != v.Block {
+			return -1
+		}
+		i := len(u.b.Values)
+		if u.v != nil {
+			i = slices.Index(u.b.Values, u.v)
+		}
+		if u.v != nil && g.folded[u.v] {
+			if i = g.lastUse(u.v); i < 0 {
+				return -1
+			}
+		}
+		last = max(last, i)
+	}
+	return last
+}
+
+// writesMem 报告指令是否可能写内存
+func writesMem(op ir.Op) bool {
+	switch op {
+	case ir.OpStore, ir.OpMove, ir.OpZero, ir.OpCall, ir.OpCallInd, ir.OpAsm:
+		return true
+	}
+	return false
+}
+
+// isAddrUse 报告使用是否为访存指令的地址
+func isAddrUse(u use) bool {
+	if u.v == nil {
+		return false
+	}
+	switch u.v.Op {
+	case ir.OpLoad, ir.OpZero:
+		return true
+	case ir.OpStore:
+		return u.i == 0
+	case ir.OpMove:
+		return true
+	}
+	return false
+}
+
+// emits 报告值是否在定义处生成代码
+func (g *funcGen) emits(v *ir.Value) bool {
+	return v.Op != ir.OpPhi && v.Op != ir.OpArg && !g.remat(v) && !g.folded[v] && !g.memLoad[v]
+}
+
+// accFor 报告紧随其后的指令 next 能否直接从 EAX 读取 v 的结果：next 只在一处使用 v（可以经过折叠的地址），
+// 且按生成的顺序在改写 EAX 之前读取
+func (g *funcGen) accFor(v, next *ir.Value) bool {
+	if v.Type == ir.Void {
+		return false
+	}
+	var uses []use
+	for _, u := range g.users[v] {
+		switch {
+		case u.v != nil && g.folded[u.v]:
+			for _, fu := range g.users[u.v] {
+				if fu.v == next {
+					uses = append(uses, fu)
+				}
+			}
+		case u.v == next:
+			uses = append(uses, u)
+		}
+	}
+	if len(uses) != 1 {
+		return false
+	}
+	u := uses[0]
+	switch next.Op {
+	case ir.OpLoad, ir.OpStore, ir.OpMove, ir.OpZero, ir.OpConvert, ir.OpBoundsCheck:
+		return true
+	case ir.OpCall, ir.OpCallInd:
+		// 只有最先压栈的最后一个参数可以留在 EAX 中
+		params := ir.Params(next.Aux.(*parser.FuncBlock))
+		locs, _ := paramLayout(params, g.convOf(next.Aux.(*parser.FuncBlock)))
+		last := len(next.Args) - 1
+		return u.i == last && len(params) > 0 && locs[len(locs)-1].reg == "" && (next.Op == ir.OpCall || last > 0)
+	}
+	if next.Op.IsBinary() || next.Op.IsCompare() {
+		return next.Args[0] != next.Args[1]
+	}
+	return false
+}
+
+// usedOnlyBy 报告 v 的所有使用（包括经过折叠的地址）是否都在 next 中，此时 v 不需要位置
+func (g *funcGen) usedOnlyBy(v, next *ir.Value) bool {
+	for _, u := range g.users[v] {
+		if u.v != nil && g.folded[u.v] {
+			if !g.usedOnlyBy(u.v, next) {
+				return false
+			}
+			continue
+		}
+		if u.v != next {
+			return false
+		}
+	}
+	return true
+}
+
+// needsLoc 报告值是否需要位置（寄存器或栈帧中）：有结果且被使用、不留在 EAX 中或与条件跳转合并，并在定义处生成或为 phi
+func (g *funcGen) needsLoc(v *ir.Value) bool {
+	return v.Type != ir.Void && len(g.users[v]) > 0 && !g.acc[v] && !g.fused[v] && (g.emits(v) || v.Op == ir.OpPhi)
+}
+
+// allocRegs GraphAlloc 时分配给值的寄存器，均为被调者保存寄存器，调用前后不必保存
+var allocRegs = []string{"EBX", "ESI", "EDI"}
+
+// saveCost 使用尚未保存的寄存器的代价：序言与尾声中各一条指令，相当于若干次经栈帧的读写
+const saveCost = 6
+
+// allocate 对函数的冲突图着色，将值分配到 allocRegs 中；用到的寄存器加入序言中保存的寄存器，
+// 内联汇编中出现的寄存器不分配给跨过它仍然活跃的值
+func (g *funcGen) allocate() {
+	costs := make([]float64, len(allocRegs))
+	for i, r := range allocRegs {
+		if !slices.Contains(g.saved, r) {
+			costs[i] = saveCost
+		}
+	}
+	res := regalloc.Allocate(g.f, regalloc.Config{
+		Regs:   allocRegs,
+		Needs:  g.needsLoc,
+		Inline: func(v *ir.Value) bool { return g.folded[v] || g.memLoad[v] },
+		Clobbers: func(v *ir.Value) (regs []int) {
+			if v.Op != ir.OpAsm {
+				return nil
+			}
+			for _, r := range calleeSavedRe.FindAllString(v.Aux.(*ir.Asm).Build.Asm, -1) {
+				regs = append(regs, slices.Index(allocRegs, calleeSavedRegs[strings.ToUpper(r)]))
+			}
+			return regs
+		},
+		Costs: costs,
+	})
+	for v, r := range res.Reg {
+		g.reg[v] = allocRegs[r]
+	}
+	// 分配到寄存器的值可能直接在寄存器中计算，不再假定它仍在 EAX 中
+	for v, prev := range g.warm {
+		if _, ok := g.reg[prev]; ok {
+			delete(g.warm, v)
+		}
+	}
+	for b, v := range g.tail {
+		if _, ok := g.reg[v]; ok {
+			delete(g.tail, b)
+		}
+	}
+	var saved []string
+	for i, r := range allocRegs {
+		if res.Used[i] || slices.Contains(g.saved, r) {
+			saved = append(saved, r)
+		}
+	}
+	g.saved = saved
+}
+
+// layout 分配栈帧：保存的寄存器、经寄存器传入的参数、各个值的位置，然后是栈上对象
+func (g *funcGen) layout() {
+	off := -4 * len(g.saved)
+	g.regs, g.stack = paramLayout(g.f.Params, g.conv)
+	g.params = make([]int, len(g.f.Params))
+	for i, loc := range g.regs {
+		g.params[i] = loc.off
+		if loc.reg != "" {
+			off -= 4
+			g.params[i] = off
+		}
+	}
+	if g.ctx.RegAlloc != context.GraphAlloc {
+		// 着色时已经优先让 phi 与其参数使用同一寄存器，溢出的值各自使用栈帧中的位置
+		g.shareSlots()
+	}
+	slot := make(map[int]int)
+	for _, b := range g.f.Blocks {
+		for _, v := range b.Values {
+			if !g.needsLoc(v) {
+				continue
+			}
+			if _, ok := g.reg[v]; ok {
+				continue
+			}
+			c, ok := g.color[v]
+			if !ok {
+				off -= 4
+				g.home[v] = off
+				continue
+			}
+			if _, ok := slot[c]; !ok {
+				off -= 4
+				slot[c] = off
+			}
+			g.home[v] = slot[c]
+		}
+	}
+	for _, s := range g.f.Slots {
+		off -= s.Size
+		off &^= s.Align - 1
+		g.slots[s] = off
+	}
+	g.frame = align4(-off) - 4*len(g.saved)
+}
+
+// shareSlots 值都放在栈帧中时，同样对冲突图着色（颜色数不限），使不同时活跃的值共用栈帧中的位置，
+// phi 与不冲突的参数合并后使用同一位置，省去前驱末尾的复制
+func (g *funcGen) shareSlots() {
+	n := 0
+	for _, b := range g.f.Blocks {
+		for _, v := range b.Values {
+			if v.Type != ir.Void && g.needsLoc(v) {
+				n++
+			}
+		}
+	}
+	res := regalloc.Allocate(g.f, regalloc.Config{
+		Regs:   make([]string, n),
+		Needs:  g.needsLoc,
+		Inline: func(v *ir.Value) bool { return g.folded[v] || g.memLoad[v] },
+	})
+	for v, c := range res.Reg {
+		g.color[v] = c
+	}
+}
+
+func (g *funcGen) prologue() {
+	g.emit("push ebp; 保存调用者的栈帧基址")
+	g.emit("mov ebp, esp; 设置当前栈帧基址")
+	for _, r := range g.saved {
+		g.emit("push " + r + "; 保存" + r)
+	}
+	if g.frame > 0 {
+		g.emit("sub esp, " + strconv.Itoa(g.frame) + "; 分配栈空间(" + strconv.Itoa(g.frame) + "字节)")
+	}
+	for i, loc := range g.regs {
+		if loc.reg != "" {
+			g.emit("mov DWORD[ebp" + strconv.Itoa(g.params[i]) + "], " + loc.reg + "; 保存参数" + g.f.Params[i].Name)
+		}
+	}
+}
+
+func (g *funcGen) epilogue() {
+	if len(g.saved) > 0 {
+		if g.frame > 0 {
+			g.emit("lea esp, [ebp-" + strconv.Itoa(4*len(g.saved)) + "]")
+		}
+		for i := len(g.saved) - 1; i >= 0; i-- {
+			g.emit("pop " + g.saved[i] + "; 恢复" + g.saved[i])
+		}
+	}
+	g.emit("leave")
+	if g.conv != "cdecl" && g.stack > 0 {
+		g.emit("ret " + strconv.Itoa(g.stack))
+		return
+	}
+	g.emit("ret")
+}
+
+// findForwarders 找出只有一个无条件跳转的基本块（没有指令，也不需要为后继的 phi 复制参数），跳转到它们时直接转到后继
+func (g *funcGen) findForwarders() {
+	for _, b := range g.f.Blocks[1:] {
+		if b.Kind != ir.BlockPlain || b.Succs[0] == b || g.needsCopies(b, b.Succs[0]) {
+			continue
+		}
+		empty := true
+		for _, v := range b.Values {
+			empty = empty && !g.emits(v)
+		}
+		g.skip[b] = empty
+	}
+	// 只由可跳过的基本块组成的循环（如空的死循环）至少保留一个
+	for _, b := range g.f.Blocks {
+		seen := map[*ir.Block]bool{}
+		for t := b; g.skip[t]; t = t.Succs[0] {
+			if seen[t] {
+				g.skip[t] = false
+				break
+			}
+			seen[t] = true
+		}
+	}
+}
+
+// target 返回跳转到基本块 b 时实际的目标，跳过只有无条件跳转的基本块
+func (g *funcGen) target(b *ir.Block) *ir.Block {
+	for g.skip[b] {
+		b = b.Succs[0]
+	}
+	return b
+}
+
+// needsCopies 报告从 b 跳转到 succ 时是否需要为 phi 复制参数
+func (g *funcGen) needsCopies(b, succ *ir.Block) bool {
+	for i, pred := range succ.Preds {
+		if pred != b {
+			continue
+		}
+		for _, v := range succ.Values {
+			if loc, ok := g.located(v); ok && v.Op == ir.OpPhi && v.Args[i] != v {
+				if l, ok := g.located(v.Args[i]); !ok || l != loc || v.Args[i].Op == ir.OpArg {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// blockLabel 返回基本块的标签
+func (g *funcGen) blockLabel(b *ir.Block) string {
+	return g.label + ".b" + strconv.Itoa(b.ID)
+}
+
+func (g *funcGen) block(b *ir.Block) {
+	if len(b.Preds) > 0 {
+		g.emit(g.blockLabel(b) + ":")
+	}
+	for _, v := range b.Values {
+		if g.emits(v) {
+			g.value(v)
+		}
+	}
+	switch b.Kind {
+	case ir.BlockPlain:
+		g.phiCopies(b, b.Succs[0])
+		g.jump(b.Succs[0])
+	case ir.BlockIf:
+		c := b.Control
+		cc := "ne"
+		switch {
+		case g.fused[c]:
+			cc = g.cc
+		case c.IsConst():
+			g.jump(b.Succs[1-min(c.AuxInt, 1)])
+			return
+		case g.acc[c] || g.tail[b] == c:
+			g.emit("test EAX, EAX")
+		case g.reg[c] != "":
+			g.emit("test " + g.reg[c] + ", " + g.reg[c])
+		default:
+			g.emit("cmp DWORD" + g.homeRef(c).String() + ", 0")
+		}
+		g.branch(cc, b.Succs[0], b.Succs[1])
+	case ir.BlockSwitch:
+		g.loadControl(b)
+		cases := make([]nasm.SwitchCase, len(b.Cases))
+		for i, value := range b.Cases {
+			cases[i] = nasm.SwitchCase{Value: value, Label: g.blockLabel(g.target(b.Succs[i]))}
+		}
+		sort.Slice(cases, func(i, j int) bool { return cases[i].Value < cases[j].Value })
+		fallback := g.blockLabel(g.target(b.Succs[len(b.Succs)-1]))
+		if nasm.UseJumpTable(cases) {
+			g.code.WriteString(gen(g.ctx).JumpTable(g.blockLabel(b)+"_table", cases, fallback))
+		} else {
+			g.code.WriteString(gen(g.ctx).CompareChain(cases, fallback))
+		}
+	case ir.BlockRet:
+		if b.Control != nil {
+			g.loadControl(b)
+		}
+		g.epilogue()
+	}
+}
+
+// loadControl 将基本块的控制值放入 EAX
+func (g *funcGen) loadControl(b *ir.Block) {
+	if g.tail[b] != b.Control {
+		g.load("EAX", b.Control)
+	}
+}
+
+// jump 跳转到基本块 to，to 紧随其后时省略
+func (g *funcGen) jump(to *ir.Block) {
+	if to = g.target(to); to != g.next {
+		g.emit("jmp " + g.blockLabel(to))
+	}
+}
+
+// branch 条件码 cc 成立时跳转到 t，否则跳转到 f，紧随其后的一侧不生成跳转
+func (g *funcGen) branch(cc string, t, f *ir.Block) {
+	t, f = g.target(t), g.target(f)
+	switch {
+	case f == g.next:
+		g.emit("j" + cc + " " + g.blockLabel(t))
+	case t == g.next:
+		g.emit("j" + negateCC[cc] + " " + g.blockLabel(f))
+	default:
+		g.emit("j" + cc + " " + g.blockLabel(t))
+		g.emit("jmp " + g.blockLabel(f))
+	}
+}
+
+// phiCopies 在前驱 b 的末尾将 succ 中各 phi 对应的参数复制到 phi 的位置；
+// 参数中含有同一基本块的其他 phi 时先全部压栈再依次弹出，避免覆盖尚未读取的值
+func (g *funcGen) phiCopies(b, succ *ir.Block) {
+	idx := 0
+	for i, pred := range succ.Preds {
+		if pred == b {
+			idx = i
+		}
+	}
+	var dsts, srcs []*ir.Value
+	parallel := false
+	for _, v := range succ.Values {
+		if v.Op != ir.OpPhi {
+			break
+		}
+		src := v.Args[idx]
+		loc, ok := g.located(v)
+		if !ok || src == v {
+			continue
+		}
+		if l, ok := g.located(src); ok && l == loc && src.Op != ir.OpArg {
+			continue
+		}
+		dsts, srcs = append(dsts, v), append(srcs, src)
+		parallel = parallel || src.Op == ir.OpPhi && src.Block == succ
+	}
+	if !parallel {
+		for i, src := range srcs {
+			if src == g.tail[b] && i > 0 {
+				// 基本块最后的值仍在 EAX 中，先复制它
+				srcs[0], srcs[i] = srcs[i], srcs[0]
+				dsts[0], dsts[i] = dsts[i], dsts[0]
+			}
+		}
+		for i, dst := range dsts {
+			loc := g.loc(dst)
+			if i == 0 && srcs[i] == g.tail[b] {
+				g.emit("mov " + loc + ", EAX")
+				continue
+			}
+			if imm, ok := g.imm(srcs[i]); ok {
+				g.emit("mov " + loc + ", " + imm)
+				continue
+			}
+			if r := g.reg[dst]; r != "" {
+				g.load(r, srcs[i])
+				continue
+			}
+			if r := g.reg[srcs[i]]; r != "" {
+				g.emit("mov " + loc + ", " + r)
+				continue
+			}
+			g.load("EAX", srcs[i])
+			g.emit("mov " + loc + ", EAX")
+		}
+		return
+	}
+	for _, src := range srcs {
+		g.push(src)
+	}
+	for i := len(dsts) - 1; i >= 0; i-- {
+		g.emit("pop " + g.loc(dsts[i]))
+	}
+}
+
+// memRef 内存操作数 [base+sym+off]
+type memRef struct {
+	base string
+	sym  string
+	off  int
+}
+
+func (m memRef) String() string {
+	s := m.base
+	if m.sym != "" {
+		if s != "" {
+			s += "+"
+		}
+		s += m.sym
+	}
+	switch {
+	case s == "":
+		s = strconv.Itoa(m.off)
+	case m.off > 0:
+		s += "+" + strconv.Itoa(m.off)
+	case m.off < 0:
+		s += strconv.Itoa(m.off)
+	}
+	return "[" + s + "]"
+}
+
+// at 返回偏移 k 字节处的内存操作数
+func (m memRef) at(k int) memRef {
+	m.off += k
+	return m
+}
+
+// addrOf 返回栈上对象、参数或全局变量（可带常量偏移）的地址所对应的内存操作数
+func (g *funcGen) addrOf(v *ir.Value) (memRef, bool) {
+	switch v.Op {
+	case ir.OpAddr:
+		return memRef{base: "ebp", off: g.slots[v.Aux.(*ir.Slot)]}, true
+	case ir.OpArgAddr:
+		return memRef{base: "ebp", off: g.params[v.AuxInt]}, true
+	case ir.OpGlobal:
+		return memRef{sym: globalLabel(v.Aux.(*parser.VarBlock))}, true
+	case ir.OpAdd:
+		if v.Args[1].IsConst() {
+			if m, ok := g.addrOf(v.Args[0]); ok {
+				return m.at(int(int32(v.Args[1].AuxInt))), true
+			}
+		}
+	}
+	return memRef{}, false
+}
+
+// homeRef 返回值在栈帧中的位置，直接作为内存操作数的载入为载入的地址
+func (g *funcGen) homeRef(v *ir.Value) memRef {
+	if g.memLoad[v] {
+		m, _ := g.addrOf(v.Args[0])
+		return m
+	}
+	if v.Op == ir.OpArg {
+		return memRef{base: "ebp", off: g.params[v.AuxInt]}
+	}
+	off, ok := g.home[v]
+	if !ok {
+		panic("编译器内部错误: " + v.LongString() + " 没有分配位置")
+	}
+	return memRef{base: "ebp", off: off}
+}
+
+// located 返回值的位置（寄存器名或栈帧中的偏移），没有分配位置时返回 false
+func (g *funcGen) located(v *ir.Value) (string, bool) {
+	if r, ok := g.reg[v]; ok {
+		return r, true
+	}
+	if off, ok := g.home[v]; ok {
+		return strconv.Itoa(off), true
+	}
+	return "", false
+}
+
+// loc 返回值的位置作为操作数的形式：分配到的寄存器，或栈帧中的位置（DWORD[ebp-N]）
+func (g *funcGen) loc(v *ir.Value) string {
+	if r, ok := g.reg[v]; ok {
+		return r
+	}
+	return "DWORD" + g.homeRef(v).String()
+}
+
+// imm 返回可以作为立即数的值：整数常量、字符串与虚表的标签、全局变量的地址
+func (g *funcGen) imm(v *ir.Value) (string, bool) {
+	switch v.Op {
+	case ir.OpConst:
+		if v.Type.Size() > 4 {
+			return strconv.FormatInt(int64(int32(v.AuxInt)), 10), true
+		}
+		return strconv.FormatInt(v.AuxInt, 10), true
+	case ir.OpString:
+		return g.ctx.Data.Intern(v.Aux.(string)), true
+	case ir.OpVTable:
+		vt := v.Aux.(ir.VTable)
+		return nasm.VTableLabel(g.ctx, vt.Struct, vt.Iface), true
+	}
+	if m, ok := g.addrOf(v); ok && m.base == "" {
+		return strings.Trim(m.String(), "[]"), true
+	}
+	return "", false
+}
+
+// load 将值放入寄存器 reg
+func (g *funcGen) load(reg string, v *ir.Value) {
+	if g.acc[v] {
+		if reg != "EAX" {
+			g.emit("mov " + reg + ", EAX")
+		}
+		return
+	}
+	if imm, ok := g.imm(v); ok {
+		g.emit("mov " + reg + ", " + imm)
+		return
+	}
+	if m, ok := g.addrOf(v); ok {
+		g.emit("lea " + reg + ", " + m.String())
+		return
+	}
+	if op := g.loc(v); op != reg {
+		g.emit("mov " + reg + ", " + op)
+	}
+}
+
+// src 返回值作为指令的源操作数的形式：立即数、寄存器或栈帧中的位置；地址先用 lea 载入 scratch
+func (g *funcGen) src(v *ir.Value, scratch string) (op string, isImm bool) {
+	if g.acc[v] {
+		return "EAX", false
+	}
+	if imm, ok := g.imm(v); ok {
+		return imm, true
+	}
+	if _, ok := g.addrOf(v); ok {
+		g.load(scratch, v)
+		return scratch, false
+	}
+	return g.loc(v), false
+}
+
+// mem 返回以值 v 为地址的内存操作数，基址不能直接表示时载入寄存器 reg
+func (g *funcGen) mem(v *ir.Value, reg string) memRef {
+	if m, ok := g.addrOf(v); ok {
+		return m
+	}
+	if g.folded[v] {
+		return g.mem(v.Args[0], reg).at(int(int32(v.Args[1].AuxInt)))
+	}
+	if g.acc[v] {
+		return memRef{base: "EAX"}
+	}
+	if r, ok := g.reg[v]; ok {
+		return memRef{base: r}
+	}
+	g.load(reg, v)
+	return memRef{base: reg}
+}
+
+// push 将值压栈
+func (g *funcGen) push(v *ir.Value) {
+	op, _ := g.src(v, "EAX")
+	g.emit("push " + op)
+}
+
+// result 将 EAX 中的结果存入值的位置
+func (g *funcGen) result(v *ir.Value) {
+	if r, ok := g.reg[v]; ok {
+		g.emit("mov " + r + ", EAX")
+		return
+	}
+	if off, ok := g.home[v]; ok {
+		g.emit("mov DWORD" + memRef{base: "ebp", off: off}.String() + ", EAX")
+	}
+}
+
+// partReg 返回通用寄存器对应宽度的部分，如 EAX 的低 8 位为 AL
+func partReg(reg string, size int) string {
+	switch size {
+	case 1:
+		return reg[1:2] + "L"
+	case 2:
+		return reg[1:]
+	}
+	return reg
+}
+
+// extend 将 EAX 的低位按类型 t 的宽度与符号扩展到 32 位
+func (g *funcGen) extend(t ir.Type) {
+	size := t.Size()
+	if size >= 4 {
+		return
+	}
+	inst := "movzx"
+	if t.IsSigned() {
+		inst = "movsx"
+	}
+	g.emit(inst + " EAX, " + partReg("EAX", size))
+}
+
+// globalLabel 返回全局变量的标签
+func globalLabel(v *parser.VarBlock) string {
+	return "g_" + v.Name.String()
+}
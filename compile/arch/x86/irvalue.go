@@ -0,0 +1,491 @@
+package x86
+
+import (
+	"cuteify/compile/arch/nasm"
+	"cuteify/compile/ir"
+	"cuteify/parser"
+	"cuteify/utils"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// negateCC 条件码取反
+var negateCC = map[string]string{
+	"e": "ne", "ne": "e",
+	"l": "ge", "ge": "l", "le": "g", "g": "le",
+	"b": "ae", "ae": "b", "be": "a", "a": "be",
+}
+
+// swapCC 交换比较的两侧后对应的条件码
+var swapCC = map[string]string{
+	"e": "e", "ne": "ne",
+	"l": "g", "g": "l", "le": "ge", "ge": "le",
+	"b": "a", "a": "b", "be": "ae", "ae": "be",
+}
+
+// condCode 返回比较运算对应的条件码，有符号整数之外（无符号整数、布尔值、地址）按无符号比较
+func condCode(op ir.Op, t ir.Type) string {
+	signed := t.IsSigned()
+	switch op {
+	case ir.OpEq:
+		return "e"
+	case ir.OpNe:
+		return "ne"
+	case ir.OpLt:
+		if signed {
+			return "l"
+		}
+		return "b"
+	case ir.OpLe:
+		if signed {
+			return "le"
+		}
+		return "be"
+	case ir.OpGt:
+		if signed {
+			return "g"
+		}
+		return "a"
+	}
+	if signed {
+		return "ge"
+	}
+	return "ae"
+}
+
+// binaryInsts 可以直接以内存或立即数为源操作数的二元运算
+var binaryInsts = map[ir.Op]string{
+	ir.OpAdd: "add", ir.OpSub: "sub", ir.OpAnd: "and", ir.OpOr: "or", ir.OpMul: "imul",
+}
+
+// value 生成一条指令
+func (g *funcGen) value(v *ir.Value) {
+	if prev := g.warm[v]; prev != nil {
+		// 前一个值刚算出，仍在 EAX 中
+		g.acc[prev] = true
+		defer delete(g.acc, prev)
+	}
+	switch {
+	case v.Op.IsCompare():
+		g.compare(v)
+	case v.Op == ir.OpDiv || v.Op == ir.OpMod:
+		g.div(v)
+	case v.Op == ir.OpShl || v.Op == ir.OpShr:
+		g.shift(v)
+	case v.Op == ir.OpPow:
+		g.pow(v)
+	case v.Op.IsBinary():
+		g.binary(v)
+	case v.Op == ir.OpConvert:
+		g.load("EAX", v.Args[0])
+		if v.Type != v.Args[0].Type {
+			g.extend(v.Type)
+		}
+		g.result(v)
+	case v.Op == ir.OpLoad:
+		g.loadMem(v)
+	case v.Op == ir.OpStore:
+		g.store(v)
+	case v.Op == ir.OpMove:
+		g.move(v)
+	case v.Op == ir.OpZero:
+		m := g.mem(v.Args[0], "ECX")
+		covered := g.overwritten(v, m)
+		g.chunks(int(v.AuxInt), func(k, size int) {
+			if !slices.Contains(covered[k:k+size], false) {
+				return
+			}
+			g.emit("mov " + utils.GetLengthName(size) + m.at(k).String() + ", 0")
+		})
+	case v.Op == ir.OpBoundsCheck:
+		g.boundsCheck(v)
+	case v.Op == ir.OpCall || v.Op == ir.OpCallInd:
+		g.call(v)
+	case v.Op == ir.OpAsm:
+		g.asm(v)
+	default:
+		panic("编译器内部错误: x86 IR 后端无法生成 " + v.LongString())
+	}
+}
+
+// operands 将 a 放入 EAX，返回 b 作为源操作数的形式；b 留在 EAX 中时先移到 ECX
+func (g *funcGen) operands(a, b *ir.Value) (src string, isImm bool) {
+	if g.acc[b] {
+		g.emit("mov ECX, EAX")
+		g.load("EAX", a)
+		return "ECX", false
+	}
+	g.load("EAX", a)
+	return g.src(b, "ECX")
+}
+
+// binary 加、减、乘与按位运算，结果按类型的宽度扩展
+func (g *funcGen) binary(v *ir.Value) {
+	a, b := v.Args[0], v.Args[1]
+	inst := binaryInsts[v.Op]
+	if v.Op != ir.OpSub && (g.acc[b] || g.remat(a) && !g.remat(b)) {
+		// 可交换的运算让 EAX 中的值或不能作为立即数的值在左侧
+		a, b = b, a
+	}
+	if r := g.reg[v]; r != "" && v.Type.Size() >= 4 {
+		// 结果直接在分配到的寄存器中计算，不经过 EAX
+		if g.reg[b] == r && v.Op != ir.OpSub {
+			a, b = b, a
+		}
+		if g.reg[b] != r {
+			g.binaryIn(r, inst, a, b)
+			return
+		}
+	}
+	src, isImm := g.operands(a, b)
+	if inst == "imul" && isImm {
+		g.emit("imul EAX, EAX, " + src)
+	} else {
+		g.emit(inst + " EAX, " + src)
+	}
+	g.extend(v.Type)
+	g.result(v)
+}
+
+// binaryIn 在寄存器 r 中计算 a inst b，b 不在 r 中
+func (g *funcGen) binaryIn(r, inst string, a, b *ir.Value) {
+	src, isImm := g.src(b, "ECX")
+	if inst == "imul" && isImm {
+		op, _ := g.src(a, "ECX")
+		g.emit("imul " + r + ", " + op + ", " + src)
+		return
+	}
+	g.load(r, a)
+	g.emit(inst + " " + r + ", " + src)
+}
+
+// div 除法与取余：除数放在 ECX，有符号类型使用 idiv
+func (g *funcGen) div(v *ir.Value) {
+	g.operands2(v.Args[0], v.Args[1])
+	if v.Type.IsSigned() {
+		g.emit("cdq")
+		g.emit("idiv ECX")
+	} else {
+		g.emit("xor EDX, EDX")
+		g.emit("div ECX")
+	}
+	if v.Op == ir.OpMod {
+		g.emit("mov EAX, EDX")
+	}
+	g.extend(v.Type)
+	g.result(v)
+}
+
+// operands2 将 a 放入 EAX，b 放入 ECX
+func (g *funcGen) operands2(a, b *ir.Value) {
+	if g.acc[b] {
+		g.emit("mov ECX, EAX")
+		g.load("EAX", a)
+		return
+	}
+	g.load("EAX", a)
+	g.load("ECX", b)
+}
+
+// shift 移位：次数为常量时使用立即数，否则放在 CL；有符号类型右移为算术右移
+func (g *funcGen) shift(v *ir.Value) {
+	inst := "shl"
+	if v.Op == ir.OpShr {
+		inst = "shr"
+		if v.Type.IsSigned() {
+			inst = "sar"
+		}
+	}
+	if count := v.Args[1]; count.IsConst() {
+		if r := g.reg[v]; r != "" && v.Type.Size() >= 4 {
+			g.load(r, v.Args[0])
+			g.emit(inst + " " + r + ", " + strconv.FormatInt(count.AuxInt&31, 10))
+			return
+		}
+		g.load("EAX", v.Args[0])
+		g.emit(inst + " EAX, " + strconv.FormatInt(count.AuxInt&31, 10))
+	} else {
+		g.operands2(v.Args[0], count)
+		g.emit(inst + " EAX, CL")
+	}
+	g.extend(v.Type)
+	g.result(v)
+}
+
+// pow 乘方：底数放在 EDX，指数放在 ECX，循环相乘；指数不为正时结果为 1
+func (g *funcGen) pow(v *ir.Value) {
+	base, exp := v.Args[0], v.Args[1]
+	if g.acc[exp] {
+		g.load("ECX", exp)
+		g.load("EDX", base)
+	} else {
+		g.load("EDX", base)
+		g.load("ECX", exp)
+	}
+	loop := g.label + ".pow" + strconv.Itoa(v.ID)
+	g.emit("mov EAX, 1")
+	g.emit(loop + ":")
+	g.emit("test ECX, ECX")
+	g.emit("jle " + loop + "_end")
+	g.emit("imul EAX, EDX")
+	g.emit("dec ECX")
+	g.emit("jmp " + loop)
+	g.emit(loop + "_end:")
+	g.extend(v.Type)
+	g.result(v)
+}
+
+// compare 比较：与条件跳转合并时只设置标志位，否则用 setcc 得到 0 或 1
+func (g *funcGen) compare(v *ir.Value) {
+	a, b := v.Args[0], v.Args[1]
+	cc := condCode(v.Op, a.Type)
+	if g.acc[b] || g.remat(a) && !g.remat(b) {
+		a, b, cc = b, a, swapCC[cc]
+	}
+	imm, isImm := g.imm(b)
+	switch {
+	case isImm && !g.acc[a] && !g.remat(a):
+		// 寄存器或栈帧中的值直接与立即数比较
+		g.emit("cmp " + g.loc(a) + ", " + imm)
+	case g.reg[a] != "" && !g.acc[b]:
+		src, _ := g.src(b, "ECX")
+		g.emit("cmp " + g.reg[a] + ", " + src)
+	default:
+		src, _ := g.operands(a, b)
+		g.emit("cmp EAX, " + src)
+	}
+	if g.fused[v] {
+		g.cc = cc
+		return
+	}
+	g.emit("set" + cc + " AL")
+	g.emit("movzx EAX, AL")
+	g.result(v)
+}
+
+// loadMem 载入，不足 4 字节的值按类型扩展，64 位整数只载入低 32 位
+func (g *funcGen) loadMem(v *ir.Value) {
+	m := g.mem(v.Args[0], "EAX")
+	size := min(v.Type.Size(), 4)
+	reg := "EAX"
+	if r := g.reg[v]; r != "" {
+		// 直接载入分配到的寄存器
+		reg = r
+	}
+	switch {
+	case size == 4:
+		g.emit("mov " + reg + ", DWORD" + m.String())
+	case v.Type.IsSigned():
+		g.emit("movsx " + reg + ", " + utils.GetLengthName(size) + m.String())
+	default:
+		g.emit("movzx " + reg + ", " + utils.GetLengthName(size) + m.String())
+	}
+	if reg == "EAX" {
+		g.result(v)
+	}
+}
+
+// store 写入：值为立即数时直接写入，否则经 EAX 或 EDX 写入；64 位整数的高 32 位按类型扩展
+func (g *funcGen) store(v *ir.Value) {
+	addr, val := v.Args[0], v.Args[1]
+	size := min(val.Type.Size(), 4)
+	m := g.mem(addr, "ECX")
+	if imm, ok := g.imm(val); ok {
+		g.emit("mov " + utils.GetLengthName(size) + m.String() + ", " + imm)
+		if val.Type.Size() > 4 {
+			g.emit("mov DWORD" + m.at(4).String() + ", " + strconv.FormatInt(val.AuxInt>>32, 10))
+		}
+		return
+	}
+	if r := g.reg[val]; r != "" && val.Type.Size() <= 4 && (size > 1 || r == "EBX") {
+		// 寄存器中的值直接写入（ESI、EDI 没有 8 位的部分）
+		g.emit("mov " + utils.GetLengthName(size) + m.String() + ", " + partReg(r, size))
+		return
+	}
+	reg := "EAX"
+	if !g.acc[val] {
+		reg = "EDX"
+		g.load(reg, val)
+	}
+	g.emit("mov " + utils.GetLengthName(size) + m.String() + ", " + partReg(reg, size))
+	if val.Type.Size() <= 4 {
+		return
+	}
+	if !val.Type.IsSigned() {
+		g.emit("mov DWORD" + m.at(4).String() + ", 0")
+		return
+	}
+	g.emit("sar " + reg + ", 31")
+	g.emit("mov DWORD" + m.at(4).String() + ", " + reg)
+}
+
+// move 复制内存：目的地址放在 ECX、源地址放在 EDX（能直接寻址时不占用寄存器），经 EAX 逐块复制
+func (g *funcGen) move(v *ir.Value) {
+	dst := g.memIn(v.Args[0], "ECX")
+	src := g.memIn(v.Args[1], "EDX")
+	g.chunks(int(v.AuxInt), func(k, size int) {
+		reg := partReg("EAX", size)
+		g.emit("mov " + reg + ", " + utils.GetLengthName(size) + src.at(k).String())
+		g.emit("mov " + utils.GetLengthName(size) + dst.at(k).String() + ", " + reg)
+	})
+}
+
+// memIn 与 mem 相同，但基址在 EAX 中时移到 reg，以便用 EAX 复制数据
+func (g *funcGen) memIn(v *ir.Value, reg string) memRef {
+	m := g.mem(v, reg)
+	if m.base == "EAX" {
+		g.emit("mov " + reg + ", EAX")
+		m.base = reg
+	}
+	return m
+}
+
+// overwritten 返回清零的 AuxInt 字节中，紧随其后的一组写入（中间没有其他指令）会覆盖的字节，这些字节不必清零
+func (g *funcGen) overwritten(zero *ir.Value, m memRef) []bool {
+	covered := make([]bool, zero.AuxInt)
+	if m.base != "ebp" && m.base != "" {
+		return covered
+	}
+	values := zero.Block.Values
+	for _, x := range values[slices.Index(values, zero)+1:] {
+		if !g.emits(x) {
+			continue
+		}
+		if x.Op != ir.OpStore || !isAddr(x.Args[0]) {
+			break
+		}
+		dst, _ := g.addrOf(x.Args[0])
+		if dst.base != m.base || dst.sym != m.sym {
+			break
+		}
+		for k := dst.off - m.off; k < dst.off-m.off+x.Args[1].Type.Size(); k++ {
+			if k >= 0 && k < len(covered) {
+				covered[k] = true
+			}
+		}
+	}
+	return covered
+}
+
+// chunks 将 n 字节按 4、2、1 字节分块，依次以块的偏移与大小调用 fn
+func (g *funcGen) chunks(n int, fn func(k, size int)) {
+	for k := 0; k < n; {
+		size := 4
+		for k+size > n {
+			size /= 2
+		}
+		fn(k, size)
+		k += size
+	}
+}
+
+// boundsCheck 下标按无符号数不小于长度时调用越界 panic 例程
+func (g *funcGen) boundsCheck(v *ir.Value) {
+	g.ctx.BoundsPanic = true
+	label := "bounds_ok_" + strconv.Itoa(g.ctx.BoundsCount)
+	g.ctx.BoundsCount++
+	src, _ := g.operands(v.Args[0], v.Args[1])
+	g.emit("cmp EAX, " + src + "; 越界检查")
+	g.emit("jb " + label)
+	g.emit("call " + nasm.BoundsPanicLabel)
+	g.emit(label + ":")
+}
+
+// call 调用：从右到左压栈，fastcall 的寄存器参数最后载入；按值传入的聚合参数复制到栈上
+func (g *funcGen) call(v *ir.Value) {
+	fn := v.Aux.(*parser.FuncBlock)
+	args := v.Args
+	if v.Op == ir.OpCallInd {
+		args = args[1:]
+	}
+	params := ir.Params(fn)
+	conv := g.convOf(fn)
+	locs, stack := paramLayout(params, conv)
+	for i := len(params) - 1; i >= 0; i-- {
+		switch {
+		case locs[i].reg != "":
+		case params[i].Agg:
+			g.pushAgg(args[i], params[i].Size)
+		case params[i].Size > 4:
+			g.pushWide(args[i])
+		default:
+			g.push(args[i])
+		}
+	}
+	for i, loc := range locs {
+		if loc.reg != "" {
+			g.load(loc.reg, args[i])
+		}
+	}
+	if v.Op == ir.OpCallInd {
+		g.emit("call " + g.loc(v.Args[0]) + "; 调用" + fn.Name.String())
+	} else {
+		g.emit("call " + nasm.FuncLabel(fn))
+	}
+	if conv == "cdecl" && stack > 0 {
+		g.emit("add esp, " + strconv.Itoa(stack) + "; 清理参数")
+	}
+	if v.Type != ir.Void {
+		g.extend(v.Type)
+		g.result(v)
+	}
+}
+
+// pushWide 将 64 位整数按类型扩展后压栈，高 32 位在前
+func (g *funcGen) pushWide(v *ir.Value) {
+	if imm, ok := g.imm(v); ok {
+		g.emit("push " + strconv.FormatInt(v.AuxInt>>32, 10))
+		g.emit("push " + imm)
+		return
+	}
+	g.load("EAX", v)
+	if v.Type.IsSigned() {
+		g.emit("cdq")
+		g.emit("push EDX")
+	} else {
+		g.emit("push 0")
+	}
+	g.emit("push EAX")
+}
+
+// pushAgg 将地址 addr 处 size 字节的值复制到栈上，占用的空间按 4 字节对齐
+func (g *funcGen) pushAgg(addr *ir.Value, size int) {
+	m := g.memIn(addr, "ECX")
+	if size%4 == 0 {
+		for k := size - 4; k >= 0; k -= 4 {
+			g.emit("push DWORD" + m.at(k).String())
+		}
+		return
+	}
+	g.emit("sub esp, " + strconv.Itoa(align4(size)))
+	g.chunks(size, func(k, n int) {
+		reg := partReg("EAX", n)
+		g.emit("mov " + reg + ", " + utils.GetLengthName(n) + m.at(k).String())
+		g.emit("mov " + utils.GetLengthName(n) + memRef{base: "esp", off: k}.String() + ", " + reg)
+	})
+}
+
+// asm 内联汇编：将 $name 替换为变量的内存操作数，名称较长的先替换，避免 $a 替换掉 $ab 的前缀
+func (g *funcGen) asm(v *ir.Value) {
+	asm := v.Aux.(*ir.Asm)
+	order := make([]int, len(asm.Names))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return len(asm.Names[order[i]]) > len(asm.Names[order[j]]) })
+	text := asm.Build.Asm
+	for _, i := range order {
+		m, ok := g.addrOf(v.Args[i])
+		if !ok {
+			panic("编译器内部错误: 内联汇编引用的 " + asm.Names[i] + " 不在栈帧或全局数据中")
+		}
+		text = strings.ReplaceAll(text, "$"+asm.Names[i], "DWORD"+m.String())
+	}
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if strings.TrimSpace(line) != "" {
+			g.code.WriteString(utils.Format(line))
+		}
+	}
+}
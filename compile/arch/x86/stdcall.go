@@ -2,17 +2,13 @@
 package x86
 
 import (
-	"cuteify/compile/arch"
 	"cuteify/compile/context"
 	"cuteify/compile/regmgr"
 	"cuteify/parser"
-	"cuteify/utils"
 	"strconv"
 )
 
-// Stdcall 实现 x86 stdcall 调用约定（全部压栈，被调者清理）。
-// 由于 Arch 接口的 Return 无法直接得知总参数字节数，这里暂以简单 ret 代替；
-// 真实 ret N 需要外层提供 totalArgBytes 信息并拼接到返回处（后续接线时补上）。
+// Stdcall 实现 x86 stdcall 调用约定（全部压栈，被调者通过 ret N 清理）。
 type Stdcall struct {
 	ctx *context.Context
 }
@@ -31,85 +27,79 @@ func (a *Stdcall) Call(call *parser.CallBlock) string {
 	if call == nil || call.Func == nil {
 		return ""
 	}
-	fn := call.Func
-	code := ""
-	//argSize := arch.CalcArgsSize(a.now)
-	// 参数的栈空间
-	//code += utils.Format("sub esp, " + strconv.Itoa(argSize) + "; 创建参数栈空间")
-	offset := 0
-	for i := 0; i < len(call.Args); i++ {
-		arg := call.Args[i]
-		if arg == nil {
-			continue
-		}
-		// 计算参数地址
-		code += a.Exp(arg.Value, caldAddrWithLen(arg.Type.Size(), offset), "")
-		offset += arg.Type.Size()
-	}
-	name := fn.Name.String()
-	if name != "main" {
-		name = name + strconv.Itoa(len(fn.Args))
-	}
-	code += utils.Format("call " + name)
-	return code
+	// 参数栈由被调函数清理
+	return genCall(a.ctx, call)
 }
 
 func (a *Stdcall) Return(ret *parser.ReturnBlock) string {
-	code := ""
-	if ret != nil && len(ret.Value) != 0 {
-		code += a.Exp(ret.Value[0], "EAX", "return")
-	}
-
-	if a.ctx.ArgsSize+a.ctx.StackSize > 0 {
-		code += utils.Format("add esp, " + strconv.Itoa(a.ctx.ArgsSize) + "; 清理参数栈")
+	retInst := "ret"
+	if a.ctx.CurrentFunc != nil {
+		if size := argsSize(a.ctx.CurrentFunc); size > 0 {
+			retInst += " " + strconv.Itoa(size) + "; 清理参数栈(stdcall)"
+		}
 	}
-	code += utils.Format("pop ebp; 跳转到函数返回部分")
-	code += utils.Format("ret\n")
-	return code
+	return gen(a.ctx).Return(ret, retInst)
 }
 
 func (a *Stdcall) Func(funcBlock *parser.FuncBlock) string {
 	if funcBlock == nil {
 		return ""
 	}
-	name := funcBlock.Name.String()
-	if name != "main" {
-		name = name + strconv.Itoa(len(funcBlock.Args))
-	}
-	code := utils.Format("; ==============================")
-	code += utils.Format("; Function:" + name)
-	code += utils.Format(name + ":")
-	utils.Count++
-	code += utils.Format("push ebp")
-	code += utils.Format("mov ebp, esp")
-
-	// 设置变量偏移量并计算栈大小
-	a.ctx.StackSize = arch.SetupVarOffsets(a.ctx.Now, a.ctx.StackAlignment, -4)
-
-	if a.ctx.StackSize > 0 {
-		code += utils.Format("sub esp, " + strconv.Itoa(a.ctx.StackSize) + "; 分配栈空间")
-	}
-
-	return code
+	return genFuncFrame(a.ctx, funcBlock)
 }
 
 func (a *Stdcall) Exp(exp *parser.Expression, result, desc string) string {
-	expc := expCom{ctx: a.ctx}
-	return expc.CompileExpr(exp, result, desc)
+	return gen(a.ctx).Expr(exp, result, desc)
 }
 
 func (a *Stdcall) For(forBlock *parser.ForBlock) string {
-	return ""
+	return gen(a.ctx).For(forBlock)
 }
 
 func (a *Stdcall) EndFor(forBlock *parser.ForBlock) (code string) {
-	return ""
+	return gen(a.ctx).EndFor(forBlock)
+}
+
+func (a *Stdcall) While(whileBlock *parser.WhileBlock) string {
+	return gen(a.ctx).While(whileBlock)
+}
+
+func (a *Stdcall) EndWhile(whileBlock *parser.WhileBlock) string {
+	return gen(a.ctx).EndWhile(whileBlock)
+}
+
+func (a *Stdcall) Break(breakBlock *parser.BreakBlock) string {
+	return gen(a.ctx).Break()
+}
+
+func (a *Stdcall) Continue(continueBlock *parser.ContinueBlock) string {
+	return gen(a.ctx).Continue()
+}
+
+func (a *Stdcall) Switch(switchBlock *parser.SwitchBlock) string {
+	return gen(a.ctx).Switch(switchBlock)
+}
+
+func (a *Stdcall) Case(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) string {
+	return gen(a.ctx).Case(switchBlock, caseBlock)
+}
+
+func (a *Stdcall) EndCase(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) string {
+	return gen(a.ctx).EndCase(switchBlock, caseBlock)
+}
+
+func (a *Stdcall) EndSwitch(switchBlock *parser.SwitchBlock) string {
+	return gen(a.ctx).EndSwitch(switchBlock)
 }
 
 func (a *Stdcall) Var(varBlock *parser.VarBlock) string {
-	return ""
+	return gen(a.ctx).Var(varBlock)
 }
 
 func (a *Stdcall) GenVarAddr(varBlock *parser.VarBlock) (addr string) {
-	return genVarAddr(a.ctx, varBlock)
+	return gen(a.ctx).VarAddr(varBlock)
+}
+
+func (a *Stdcall) Data() (code string) {
+	return gen(a.ctx).Data()
 }
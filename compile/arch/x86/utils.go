@@ -1,144 +1,100 @@
 package x86
 
 import (
+	"cuteify/compile/arch/nasm"
 	"cuteify/compile/context"
-	"cuteify/parser"
+	typeSys "cuteify/type"
 	"cuteify/utils"
 	"strconv"
-	// TODO: typeSys "cuteify/type"
+	"strings"
 )
 
-// caldAddrWithLen 生成带长度前缀的内存地址表达式
-// 用于函数参数传递时的栈地址计算
-func caldAddrWithLen(size int, offset int) (code string) {
-	if offset == 0 {
-		return utils.GetLengthName(size) + "[ebp]"
+// gen 返回与 x86-64 后端共用的代码生成
+func gen(ctx *context.Context) *nasm.Gen {
+	return &nasm.Gen{
+		Ctx:     ctx,
+		Word:    4,
+		Acc:     "EAX",
+		Base:    "ebp",
+		Stack:   "esp",
+		Self:    selfReg,
+		Scratch: "",
+		Regs:    regs,
+		Load:    loadTo,
+		Convert: genConvert,
+		SubReg:  subReg,
+		Exit:    genExit,
 	}
-	return utils.GetLengthName(size) + "[ebp + " + strconv.FormatInt(int64(offset), 10) + "]"
 }
 
-func genVarAddr(ctx *context.Context, v *parser.VarBlock) string {
-	if v.Name.First() == "this" {
-		if len(v.Name) > 1 {
-			thisAddr := "[ebp+8]"
-			fieldOffset := calculateThisFieldOffset(ctx, v)
-			var addr string
-			if fieldOffset > 0 {
-				addr = "[ebp+8+" + strconv.FormatInt(int64(fieldOffset), 10) + "]"
-			} else {
-				addr = thisAddr
-			}
-			sizePrefix := utils.GetLengthName(v.Type.Size())
-			return sizePrefix + addr
-		}
-		return "DWORD[ebp+8]"
-	}
-
-	var isDefineInArg bool
-	if v.Define != nil {
-		switch def := v.Define.Value.(type) {
-		case *parser.VarBlock:
-			v.Offset = def.Offset
-		case *parser.ArgBlock:
-			isDefineInArg = true
-			v.Offset = def.Offset
-		}
-	}
+// genExit 越界 panic 例程的系统调用：向 stderr 写出 label 处的 n 字节，然后以退出码 2 结束进程
+func genExit(label string, n int) (code string) {
+	code += utils.Format("mov eax, 4; sys_write")
+	code += utils.Format("mov ebx, 2; stderr")
+	code += utils.Format("mov ecx, " + label)
+	code += utils.Format("mov edx, " + strconv.Itoa(n))
+	code += utils.Format("int 0x80")
+	code += utils.Format("mov eax, 1; sys_exit")
+	code += utils.Format("mov ebx, 2")
+	code += utils.Format("int 0x80")
+	return code
+}
 
-	baseOffset := v.Offset
-	fieldOffset := 0
+// loadTo 返回从内存 mem 按类型 t 载入32位寄存器 reg 的指令
+func loadTo(reg string, t typeSys.Type, mem string) string {
+	return loadInst(t) + " " + reg + ", " + mem
+}
 
-	if len(v.Name) > 1 {
-		fieldOffset = calculateFieldOffset(ctx, v)
+// loadInst 返回从内存载入32位寄存器所用的指令，窄类型需要零扩展或符号扩展
+func loadInst(t typeSys.Type) string {
+	if t == nil || t.Size() >= 4 || t.Size() == 0 {
+		return "mov"
 	}
-
-	totalOffset := baseOffset + fieldOffset
-
-	if !isDefineInArg {
-		totalOffset += ctx.BpOffset
+	if typeSys.CheckTypeType(t, "int") {
+		return "movsx"
 	}
+	return "movzx"
+}
 
-	var addr string
-	offsetStr := strconv.FormatInt(int64(totalOffset), 10)
-	if totalOffset < 0 {
-		addr = "[ebp" + offsetStr + "]"
-	} else if totalOffset == 0 {
-		addr = "[ebp]"
-	} else {
-		addr = "[ebp+" + offsetStr + "]"
+// subReg 返回写入内存地址 addr 时应使用的寄存器，如 EAX 写入 BYTE[...] 时为 AL
+func subReg(reg, addr string) string {
+	if len(reg) != 3 || reg[0] != 'E' {
+		return reg
+	}
+	switch {
+	case strings.HasPrefix(addr, "BYTE["):
+		return reg[1:2] + "L"
+	case strings.HasPrefix(addr, "WORD["):
+		return reg[1:]
 	}
-	sizePrefix := utils.GetLengthName(v.Type.Size())
-	return sizePrefix + addr
+	return reg
 }
 
-func calculateThisFieldOffset(ctx *context.Context, v *parser.VarBlock) int {
-	if len(v.Name) <= 1 {
-		return 0
+// caldAddrWithLen 生成带长度前缀的内存地址表达式
+// 用于函数参数传递时的栈地址计算
+func caldAddrWithLen(size int, offset int) (code string) {
+	if offset == 0 {
+		return utils.GetLengthName(size) + "[ebp]"
 	}
-
-	// TODO: var currentType typeSys.Type
-	// TODO: for current := ctx.Parser.ThisBlock; current != nil; current = current.Father {
-	// TODO: 	if funcBlock, ok := current.Value.(*parser.FuncBlock); ok {
-	// TODO: 		if funcBlock.Class != nil {
-	// TODO: 			currentType = funcBlock.Class
-	// TODO: 			break
-	// TODO: 		}
-	// TODO: 	}
-	// TODO: }
-	// TODO:
-	// TODO: if currentType == nil {
-	// TODO: 	return 0
-	// TODO: }
-	// TODO:
-	// TODO: totalOffset := 0
-	// TODO: for i := 1; i < len(v.Name); i++ {
-	// TODO: 	fieldName := v.Name[i]
-	// TODO: 	structName := currentType.Type()
-	// TODO: 	structBlock, exists := ctx.GetStruct(structName)
-	// TODO: 	if !exists {
-	// TODO: 		return 0
-	// TODO: 	}
-	// TODO: 	field := structBlock.GetFieldByName(fieldName)
-	// TODO: 	if field == nil {
-	// TODO: 		return 0
-	// TODO: 	}
-	// TODO: 	totalOffset += field.Offset
-	// TODO: 	currentType = field.Type
-	// TODO: }
-	// TODO: return totalOffset
-	return 0
+	return utils.GetLengthName(size) + "[ebp + " + strconv.FormatInt(int64(offset), 10) + "]"
 }
 
-func calculateFieldOffset(ctx *context.Context, v *parser.VarBlock) int {
-	if len(v.Name) <= 1 {
-		return 0
+// genConvert 将寄存器中按 from 类型解释的值转换为 to 类型，结果仍占满 32 位寄存器
+// 目标比 32 位窄时截断到目标宽度并按目标的符号重新扩展，否则按源类型的符号扩展窄的源值
+func genConvert(reg string, from, to typeSys.Type) string {
+	if toBits, toSigned, ok := typeSys.IntInfo(to); ok && toBits < 32 {
+		return utils.Format(extendInst(toSigned) + " " + reg + ", " + subReg(reg, utils.GetLengthName(toBits/8)+"[") + "; 转换为" + to.Type())
 	}
+	if fromBits, fromSigned, ok := typeSys.IntInfo(from); ok && fromBits < 32 {
+		return utils.Format(extendInst(fromSigned) + " " + reg + ", " + subReg(reg, utils.GetLengthName(fromBits/8)+"[") + "; " + from.Type() + "扩展为" + to.Type())
+	}
+	return ""
+}
 
-	// TODO: var currentType typeSys.Type
-	// TODO: if v.Define != nil {
-	// TODO: 	switch def := v.Define.Value.(type) {
-	// TODO: 	case *parser.VarBlock:
-	// TODO: 		currentType = def.Type
-	// TODO: 	case *parser.ArgBlock:
-	// TODO: 		currentType = def.Type
-	// TODO: 	}
-	// TODO: }
-	// TODO:
-	// TODO: totalOffset := 0
-	// TODO: for i := 1; i < len(v.Name); i++ {
-	// TODO: 	fieldName := v.Name[i]
-	// TODO: 	structName := currentType.Type()
-	// TODO: 	structBlock, exists := ctx.GetStruct(structName)
-	// TODO: 	if !exists {
-	// TODO: 		return 0
-	// TODO: 	}
-	// TODO: 	field := structBlock.GetFieldByName(fieldName)
-	// TODO: 	if field == nil {
-	// TODO: 		return 0
-	// TODO: 	}
-	// TODO: 	totalOffset += field.Offset
-	// TODO: 	currentType = field.Type
-	// TODO: }
-	// TODO: return totalOffset
-	return 0
+// extendInst 返回按符号扩展（movsx）或零扩展（movzx）的指令
+func extendInst(signed bool) string {
+	if signed {
+		return "movsx"
+	}
+	return "movzx"
 }
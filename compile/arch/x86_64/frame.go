@@ -0,0 +1,152 @@
+package x86_64
+
+import (
+	"cuteify/compile/arch"
+	"cuteify/compile/arch/nasm"
+	"cuteify/compile/context"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strconv"
+)
+
+// selfReg 方法中保存接收者地址（self）的寄存器，callee-saved 且不参与寄存器分配
+const selfReg = "R12"
+
+// argLoc 参数的传递位置
+type argLoc struct {
+	reg   int // 第一个槽位对应的 argRegs 下标，压栈传递时为 -1
+	stack int // 压栈传递时第一个槽位在栈参数区中的下标
+	slots int // 占用的 8 字节槽位数
+}
+
+// argSlots 返回类型为 t 的参数占用的 8 字节槽位数，切片和接口值为两个
+func argSlots(t typeSys.Type) int {
+	if n := (t.Size() + 7) / 8; n > 0 {
+		return n
+	}
+	return 1
+}
+
+// assignArgs 为参数分配传递位置，方法的接收者地址固定占用第一个参数寄存器
+// 参数的全部槽位能放入剩余的参数寄存器时经寄存器传递，否则整体压栈，之后的参数仍可使用剩余的寄存器
+func assignArgs(funcBlock *parser.FuncBlock) (locs []argLoc, stackSlots int) {
+	next := 0
+	if funcBlock.Class != nil {
+		next = 1
+	}
+	for _, arg := range funcBlock.Args {
+		loc := argLoc{reg: -1, slots: argSlots(arg.Type)}
+		if next+loc.slots <= len(argRegs) {
+			loc.reg = next
+			next += loc.slots
+		} else {
+			loc.stack = stackSlots
+			stackSlots += loc.slots
+		}
+		locs = append(locs, loc)
+	}
+	return locs, stackSlots
+}
+
+// genFuncFrame 生成函数序言：建立栈帧、保存 callee-saved 寄存器、分配局部变量空间
+// 经寄存器传入的参数在序言中存入栈帧，压栈传入的参数位于 [rbp+16] 起；序言结束时 rsp 按 16 字节对齐
+func genFuncFrame(ctx *context.Context, funcBlock *parser.FuncBlock) (code string) {
+	ctx.SpOffset = 0
+
+	code, csCount := gen(ctx).Prologue(funcBlock, argRegs[0])
+
+	offset := -8 * csCount
+	locs, _ := assignArgs(funcBlock)
+	for i, arg := range funcBlock.Args {
+		if locs[i].reg < 0 {
+			arg.Offset = 16 + 8*locs[i].stack
+			continue
+		}
+		offset -= 8 * locs[i].slots
+		arg.Offset = offset
+	}
+
+	// 已压栈的 callee-saved 寄存器计入栈帧，剩余部分一次分配
+	ctx.StackSize = arch.SetupVarOffsets(ctx.Now, stackAlignment, offset) - 8*csCount
+
+	code += gen(ctx).AllocFrame()
+
+	for i, arg := range funcBlock.Args {
+		for j := 0; j < locs[i].slots && locs[i].reg >= 0; j++ {
+			code += utils.Format("mov QWORD" + nasm.RefAdd("[rbp"+strconv.Itoa(arg.Offset)+"]", 8*j) + ", " + argRegs[locs[i].reg+j] + "; 保存参数" + arg.Name.String())
+		}
+	}
+
+	code += utils.Format("; ---- 函数开始 ----")
+	return code
+}
+
+// genCall 生成参数传递和 call 指令，返回调用结束后需要清理的栈空间字节数
+// 全部参数从右到左压栈（经栈传递的参数先压），再把最后压入的寄存器参数依次弹出到参数寄存器
+// 栈参数之下按需填充 8 字节，使 call 时 rsp 按 16 字节对齐；ctx.SpOffset 记录当前函数中尚未弹出的压栈字节数
+func genCall(ctx *context.Context, call *parser.CallBlock) (code string, cleanup int) {
+	savedRegs := ctx.Reg.SaveAll(false)
+	for _, regCode := range savedRegs {
+		code += regCode
+	}
+
+	locs, stackSlots := assignArgs(call.Func)
+	if (ctx.SpOffset+8*stackSlots)%stackAlignment != 0 {
+		code += utils.Format("sub rsp, 8; 对齐栈参数")
+		ctx.SpOffset += 8
+		cleanup += 8
+	}
+
+	regSlots := 0
+	for _, onStack := range []bool{true, false} {
+		for i := len(call.Args) - 1; i >= 0; i-- {
+			arg := call.Args[i]
+			if arg == nil || i >= len(locs) || (locs[i].reg < 0) != onStack {
+				continue
+			}
+			code += genPushArg(ctx, arg, "参数"+strconv.Itoa(i))
+			if !onStack {
+				regSlots += locs[i].slots
+			}
+		}
+	}
+	cleanup += 8 * stackSlots
+
+	var iface *typeSys.InterfaceType
+	if call.ThisVar != nil {
+		iface, _ = call.ThisVar.Type.(*typeSys.InterfaceType)
+		if iface != nil {
+			code += utils.Format("push QWORD" + gen(ctx).VarRef(call.ThisVar) + "; 接收者地址")
+		} else {
+			code += gen(ctx).PushReceiver(call.ThisVar)
+		}
+		ctx.SpOffset += 8
+		regSlots++
+	}
+
+	for k := 0; k < regSlots; k++ {
+		code += utils.Format("pop " + argRegs[k])
+	}
+	ctx.SpOffset -= 8 * regSlots
+
+	if iface != nil {
+		return code + gen(ctx).IfaceCall(call, iface), cleanup
+	}
+	code += utils.Format("call " + nasm.FuncLabel(call.Func))
+	return code, cleanup
+}
+
+// genPushArg 压入一个参数，切片和接口值占两个槽位
+func genPushArg(ctx *context.Context, arg *parser.ArgBlock, desc string) (code string) {
+	switch t := arg.Type.(type) {
+	case *typeSys.InterfaceType:
+		code = gen(ctx).PushIface(arg.Value, t, desc)
+	case *typeSys.SliceType:
+		code = gen(ctx).PushSlice(arg.Value, desc)
+	default:
+		code = ctx.Arch.Exp(arg.Value, "push", desc)
+	}
+	ctx.SpOffset += 8 * argSlots(arg.Type)
+	return code
+}
@@ -0,0 +1,123 @@
+// Package x86_64 实现 x86-64 平台的 System V 调用约定，生成 nasm -f elf64 汇编。
+package x86_64
+
+import (
+	"cuteify/compile/context"
+	"cuteify/compile/regmgr"
+	"cuteify/parser"
+	"cuteify/utils"
+	"strconv"
+)
+
+var regs = []*regmgr.Reg{
+	{Name: "RAX", Size: 8},
+	{Name: "RBX", Size: 8, CalleeSave: true}, // CalleeSave
+	{Name: "RCX", Size: 8},
+	{Name: "RDX", Size: 8},
+	{Name: "R10", Size: 8},
+}
+
+// argRegs System V 依次传递前 6 个整数参数（每个占一个 8 字节槽位）的寄存器
+var argRegs = []string{"RDI", "RSI", "RDX", "RCX", "R8", "R9"}
+
+// scratchReg 临时寄存器，不参与寄存器分配，用于载入 64 位常量和除法的除数
+const scratchReg = "R11"
+
+// stackAlignment System V 要求 call 指令执行前 rsp 按 16 字节对齐
+const stackAlignment = 16
+
+// SysV 实现 x86-64 System V 调用约定（前 6 个参数槽位经寄存器传递，其余压栈，调用者清理）。
+type SysV struct {
+	ctx *context.Context
+}
+
+func NewSysV(ctx *context.Context) *SysV {
+	a := &SysV{
+		ctx: ctx,
+	}
+	ctx.Reg = regmgr.NewRegMgr(regs, a.GenVarAddr)
+	return a
+}
+
+func (a *SysV) Info() (code string) { return "x86_64 sysv" }
+
+func (a *SysV) Call(call *parser.CallBlock) (code string) {
+	if call == nil || call.Func == nil {
+		return ""
+	}
+	var cleanup int
+	code, cleanup = genCall(a.ctx, call)
+
+	if cleanup > 0 {
+		code += utils.Format("add rsp, " + strconv.Itoa(cleanup) + "; 清理参数栈(sysv)")
+		a.ctx.SpOffset -= cleanup
+	}
+	return code
+}
+
+func (a *SysV) Return(ret *parser.ReturnBlock) (code string) {
+	return gen(a.ctx).Return(ret, "ret")
+}
+
+func (a *SysV) Func(funcBlock *parser.FuncBlock) (code string) {
+	if funcBlock == nil {
+		return ""
+	}
+	return genFuncFrame(a.ctx, funcBlock)
+}
+
+func (a *SysV) Exp(exp *parser.Expression, result, desc string) (code string) {
+	return gen(a.ctx).Expr(exp, result, desc)
+}
+
+func (a *SysV) For(forBlock *parser.ForBlock) (code string) {
+	return gen(a.ctx).For(forBlock)
+}
+
+func (a *SysV) EndFor(forBlock *parser.ForBlock) (code string) {
+	return gen(a.ctx).EndFor(forBlock)
+}
+
+func (a *SysV) While(whileBlock *parser.WhileBlock) (code string) {
+	return gen(a.ctx).While(whileBlock)
+}
+
+func (a *SysV) EndWhile(whileBlock *parser.WhileBlock) (code string) {
+	return gen(a.ctx).EndWhile(whileBlock)
+}
+
+func (a *SysV) Break(breakBlock *parser.BreakBlock) (code string) {
+	return gen(a.ctx).Break()
+}
+
+func (a *SysV) Continue(continueBlock *parser.ContinueBlock) (code string) {
+	return gen(a.ctx).Continue()
+}
+
+func (a *SysV) Switch(switchBlock *parser.SwitchBlock) (code string) {
+	return gen(a.ctx).Switch(switchBlock)
+}
+
+func (a *SysV) Case(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) (code string) {
+	return gen(a.ctx).Case(switchBlock, caseBlock)
+}
+
+func (a *SysV) EndCase(switchBlock *parser.SwitchBlock, caseBlock *parser.CaseBlock) (code string) {
+	return gen(a.ctx).EndCase(switchBlock, caseBlock)
+}
+
+func (a *SysV) EndSwitch(switchBlock *parser.SwitchBlock) (code string) {
+	return gen(a.ctx).EndSwitch(switchBlock)
+}
+
+func (a *SysV) Var(varBlock *parser.VarBlock) (code string) {
+	return gen(a.ctx).Var(varBlock)
+}
+
+func (a *SysV) GenVarAddr(varBlock *parser.VarBlock) (addr string) {
+	return gen(a.ctx).VarAddr(varBlock)
+}
+
+func (a *SysV) Data() (code string) {
+	return gen(a.ctx).Data()
+}
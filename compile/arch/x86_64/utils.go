@@ -0,0 +1,157 @@
+package x86_64
+
+import (
+	"cuteify/compile/arch/nasm"
+	"cuteify/compile/context"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strconv"
+	"strings"
+)
+
+// gen 返回与 32 位 x86 后端共用的代码生成
+func gen(ctx *context.Context) *nasm.Gen {
+	return &nasm.Gen{
+		Ctx:     ctx,
+		Word:    8,
+		Acc:     "RAX",
+		Base:    "rbp",
+		Stack:   "rsp",
+		Self:    selfReg,
+		Scratch: scratchReg,
+		Regs:    regs,
+		Load:    loadTo,
+		Convert: genConvert,
+		SubReg:  subReg,
+		Imm:     immOperand,
+		Exit:    genExit,
+	}
+}
+
+// genExit 越界 panic 例程的系统调用：向 stderr 写出 label 处的 n 字节，然后以退出码 2 结束进程
+func genExit(label string, n int) (code string) {
+	code += utils.Format("mov rax, 1; sys_write")
+	code += utils.Format("mov rdi, 2; stderr")
+	code += utils.Format("mov rsi, " + label)
+	code += utils.Format("mov rdx, " + strconv.Itoa(n))
+	code += utils.Format("syscall")
+	code += utils.Format("mov rax, 60; sys_exit")
+	code += utils.Format("mov rdi, 2")
+	code += utils.Format("syscall")
+	return code
+}
+
+// loadTo 返回从内存 mem 按类型 t 载入64位寄存器 reg 的指令，窄类型需要零扩展或符号扩展
+// 32 位无符号数写入 32 位子寄存器即自动零扩展
+func loadTo(reg string, t typeSys.Type, mem string) string {
+	size := 0
+	if t != nil {
+		size = t.Size()
+	}
+	if size == 0 || size >= 8 {
+		return "mov " + reg + ", " + mem
+	}
+	signed := typeSys.CheckTypeType(t, "int")
+	if size == 4 {
+		if signed {
+			return "movsxd " + reg + ", " + mem
+		}
+		return "mov " + subReg(reg, mem) + ", " + mem
+	}
+	return extendInst(signed) + " " + reg + ", " + mem
+}
+
+// subReg 返回写入内存地址 addr 时应使用的寄存器，如 RAX 写入 DWORD[...] 时为 EAX、写入 BYTE[...] 时为 AL
+func subReg(reg, addr string) string {
+	width := 8
+	switch {
+	case strings.HasPrefix(addr, "BYTE["):
+		width = 1
+	case strings.HasPrefix(addr, "WORD["):
+		width = 2
+	case strings.HasPrefix(addr, "DWORD["):
+		width = 4
+	}
+	return regPart(reg, width)
+}
+
+// regPart 返回64位寄存器 reg 的低 width 字节部分，如 RAX 的 4 字节部分为 EAX，R10 的 1 字节部分为 R10B
+func regPart(reg string, width int) string {
+	if width == 8 || len(reg) < 3 {
+		return reg
+	}
+	if reg[0] == 'R' && reg[1] >= '0' && reg[1] <= '9' {
+		switch width {
+		case 4:
+			return reg + "D"
+		case 2:
+			return reg + "W"
+		}
+		return reg + "B"
+	}
+	if reg[0] != 'R' {
+		return reg
+	}
+	name := reg[1:]
+	switch width {
+	case 4:
+		return "E" + name
+	case 2:
+		return name
+	}
+	switch name {
+	case "SI", "DI", "SP", "BP":
+		return name + "L"
+	}
+	return name[:1] + "L"
+}
+
+// fitsImm 判断常量 src 能否直接作为指令的 32 位立即数（按符号扩展到 64 位）
+func fitsImm(src string) bool {
+	v, err := strconv.ParseInt(src, 10, 64)
+	if err != nil {
+		return true
+	}
+	return v >= -1<<31 && v < 1<<31
+}
+
+// immOperand 超出 32 位立即数范围的常量先载入 scratchReg，返回可作为源操作数的写法
+func immOperand(src string) (code, operand string) {
+	if fitsImm(src) {
+		return "", src
+	}
+	return utils.Format("mov " + scratchReg + ", " + src + "; 64位常量"), scratchReg
+}
+
+// genConvert 将寄存器中按 from 类型解释的值转换为 to 类型，结果仍占满 64 位寄存器
+// 目标比 64 位窄时截断到目标宽度并按目标的符号重新扩展，否则按源类型的符号扩展窄的源值
+func genConvert(reg string, from, to typeSys.Type) string {
+	if toBits, toSigned, ok := typeSys.IntInfo(to); ok && toBits < 64 {
+		return utils.Format(extendReg(reg, toBits/8, toSigned) + "; 转换为" + to.Type())
+	}
+	if fromBits, fromSigned, ok := typeSys.IntInfo(from); ok && fromBits < 64 {
+		return utils.Format(extendReg(reg, fromBits/8, fromSigned) + "; " + from.Type() + "扩展为" + to.Type())
+	}
+	return ""
+}
+
+// extendReg 返回将 reg 的低 width 字节扩展回整个寄存器的指令
+// 32 位零扩展借助写 32 位子寄存器时自动清零高位的特性
+func extendReg(reg string, width int, signed bool) string {
+	part := regPart(reg, width)
+	if width == 4 {
+		if signed {
+			return "movsxd " + reg + ", " + part
+		}
+		return "mov " + part + ", " + part
+	}
+	return extendInst(signed) + " " + reg + ", " + part
+}
+
+// extendInst 返回按符号扩展（movsx）或零扩展（movzx）的指令
+func extendInst(signed bool) string {
+	if signed {
+		return "movsx"
+	}
+	return "movzx"
+}
@@ -0,0 +1,348 @@
+// Package asm 将后端生成的 NASM 汇编（32 位 x86 指令子集）直接编码为机器码，供 elf 包写出目标文件或可执行文件，无需外部的 nasm。
+package asm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Section 一个节：代码、数据或零初始化数据（.bss 只记录大小）
+type Section struct {
+	Name  string
+	Data  []byte
+	Size  int // .bss 的大小，其余节为 len(Data)
+	Align int // 节内出现过的最大对齐要求
+}
+
+// Len 返回节当前的长度
+func (s *Section) Len() int {
+	if s.IsBss() {
+		return s.Size
+	}
+	return len(s.Data)
+}
+
+// IsBss 判断是否为零初始化数据节
+func (s *Section) IsBss() bool {
+	return s.Name == ".bss"
+}
+
+// Symbol 节中定义的标签
+type Symbol struct {
+	Name    string
+	Section string
+	Value   int // 在节中的偏移
+}
+
+// Reloc 对符号的引用，待写出时按符号的最终地址修正
+// 被修正的 4 字节中已写入加数：绝对引用为额外的偏移，相对引用为 -4（相对下一条指令）
+type Reloc struct {
+	Section string // 引用所在的节
+	Offset  int    // 被修正的 4 字节在节中的偏移
+	Symbol  string // 引用的符号
+	PCRel   bool   // 是否为相对 PC 的引用
+}
+
+// Object 汇编结果
+type Object struct {
+	Sections []*Section
+	Symbols  map[string]*Symbol
+	Order    []string        // 符号的定义顺序
+	Globals  map[string]bool // global 声明的符号
+	Externs  []string        // extern 声明的符号
+	Relocs   []Reloc
+}
+
+// Section 按名称返回节，不存在时返回 nil
+func (o *Object) Section(name string) *Section {
+	for _, s := range o.Sections {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Undefined 返回被引用但没有定义的符号
+func (o *Object) Undefined() (names []string) {
+	seen := map[string]bool{}
+	for _, r := range o.Relocs {
+		if o.Symbols[r.Symbol] == nil && !seen[r.Symbol] {
+			seen[r.Symbol] = true
+			names = append(names, r.Symbol)
+		}
+	}
+	return names
+}
+
+// assembler 汇编过程的状态
+type assembler struct {
+	obj *Object
+	sec *Section
+}
+
+// Assemble 汇编 NASM 源码，同一节内的相对跳转直接解析，其余引用记录为重定位
+func Assemble(src string) (*Object, error) {
+	a := &assembler{obj: &Object{
+		Symbols: map[string]*Symbol{},
+		Globals: map[string]bool{},
+	}}
+	a.switchSection(".text")
+	for i, line := range strings.Split(src, "\n") {
+		if err := a.line(line); err != nil {
+			return nil, fmt.Errorf("第 %d 行 %q: %v", i+1, strings.TrimSpace(line), err)
+		}
+	}
+	a.resolveLocal()
+	return a.obj, nil
+}
+
+func (a *assembler) switchSection(name string) {
+	if s := a.obj.Section(name); s != nil {
+		a.sec = s
+		return
+	}
+	a.sec = &Section{Name: name, Align: 1}
+	a.obj.Sections = append(a.obj.Sections, a.sec)
+}
+
+// line 汇编一行：可选的标签，后跟伪指令或指令
+func (a *assembler) line(line string) error {
+	line = strings.TrimSpace(stripComment(line))
+	if label, rest, ok := splitLabel(line); ok {
+		if err := a.define(label); err != nil {
+			return err
+		}
+		line = rest
+	}
+	if line == "" {
+		return nil
+	}
+	mnemonic, rest, _ := strings.Cut(line, " ")
+	mnemonic = strings.ToLower(mnemonic)
+	rest = strings.TrimSpace(rest)
+	switch mnemonic {
+	case "section", "segment":
+		a.switchSection(strings.Fields(rest)[0])
+	case "global":
+		for _, name := range splitOperands(rest) {
+			a.obj.Globals[name] = true
+		}
+	case "extern":
+		a.obj.Externs = append(a.obj.Externs, splitOperands(rest)...)
+	case "bits", "[bits":
+		if !strings.HasPrefix(rest, "32") {
+			return fmt.Errorf("只支持 32 位代码")
+		}
+	case "align", "alignb":
+		n, err := parseNumber(rest)
+		if err != nil || n <= 0 || n&(n-1) != 0 {
+			return fmt.Errorf("无效的对齐值 %s", rest)
+		}
+		a.align(int(n))
+	case "times":
+		count, body, _ := strings.Cut(rest, " ")
+		n, err := parseNumber(count)
+		if err != nil || n < 0 {
+			return fmt.Errorf("无效的重复次数 %s", count)
+		}
+		for k := int64(0); k < n; k++ {
+			if err := a.line(body); err != nil {
+				return err
+			}
+		}
+	case "db", "dw", "dd":
+		return a.data(map[string]int{"db": 1, "dw": 2, "dd": 4}[mnemonic], rest)
+	case "resb", "resw", "resd":
+		n, err := parseNumber(rest)
+		if err != nil || n < 0 {
+			return fmt.Errorf("无效的大小 %s", rest)
+		}
+		a.reserve(int(n) * map[string]int{"resb": 1, "resw": 2, "resd": 4}[mnemonic])
+	default:
+		if a.sec.IsBss() {
+			return fmt.Errorf(".bss 中不能有指令")
+		}
+		ops, err := parseOperands(rest)
+		if err != nil {
+			return err
+		}
+		return a.encode(mnemonic, ops)
+	}
+	return nil
+}
+
+// define 在当前位置定义标签
+func (a *assembler) define(name string) error {
+	if a.obj.Symbols[name] != nil {
+		return fmt.Errorf("重复定义的标签 %s", name)
+	}
+	a.obj.Symbols[name] = &Symbol{Name: name, Section: a.sec.Name, Value: a.sec.Len()}
+	a.obj.Order = append(a.obj.Order, name)
+	return nil
+}
+
+// align 将当前位置对齐到 n 字节，代码节用 nop 填充
+func (a *assembler) align(n int) {
+	if n > a.sec.Align {
+		a.sec.Align = n
+	}
+	pad := (n - a.sec.Len()%n) % n
+	if a.sec.IsBss() {
+		a.sec.Size += pad
+		return
+	}
+	fill := byte(0)
+	if a.sec.Name == ".text" {
+		fill = 0x90
+	}
+	for k := 0; k < pad; k++ {
+		a.sec.Data = append(a.sec.Data, fill)
+	}
+}
+
+func (a *assembler) reserve(n int) {
+	if !a.sec.IsBss() {
+		a.sec.Data = append(a.sec.Data, make([]byte, n)...)
+		return
+	}
+	a.sec.Size += n
+}
+
+// data 输出 db/dw/dd 的操作数：数值、字符串（db）或标签（dd）
+func (a *assembler) data(size int, rest string) error {
+	if a.sec.IsBss() {
+		return fmt.Errorf(".bss 中不能定义初始值")
+	}
+	for _, item := range splitOperands(rest) {
+		if len(item) >= 2 && (item[0] == '"' || item[0] == '\'' || item[0] == '`') && item[len(item)-1] == item[0] {
+			str := item[1 : len(item)-1]
+			for k := 0; k < len(str); k++ {
+				a.emitValue(int64(str[k]), size)
+			}
+			continue
+		}
+		v, sym, err := parseExpr(item)
+		if err != nil {
+			return err
+		}
+		if sym != "" {
+			if size != 4 {
+				return fmt.Errorf("标签只能用 dd 定义")
+			}
+			a.reloc(sym, false)
+		}
+		a.emitValue(v, size)
+	}
+	return nil
+}
+
+// emit 在当前节末尾追加字节
+func (a *assembler) emit(b ...byte) {
+	a.sec.Data = append(a.sec.Data, b...)
+}
+
+// emitValue 按小端序追加 size 字节的数值
+func (a *assembler) emitValue(v int64, size int) {
+	for k := 0; k < size; k++ {
+		a.emit(byte(v >> (8 * k)))
+	}
+}
+
+// reloc 记录对符号的引用，被修正的 4 字节位于当前位置，应紧接着写入
+func (a *assembler) reloc(sym string, pcRel bool) {
+	a.obj.Relocs = append(a.obj.Relocs, Reloc{Section: a.sec.Name, Offset: len(a.sec.Data), Symbol: sym, PCRel: pcRel})
+}
+
+// resolveLocal 直接解析目标在同一节内的相对引用
+func (a *assembler) resolveLocal() {
+	var remain []Reloc
+	for _, r := range a.obj.Relocs {
+		sym := a.obj.Symbols[r.Symbol]
+		if !r.PCRel || sym == nil || sym.Section != r.Section {
+			remain = append(remain, r)
+			continue
+		}
+		data := a.obj.Section(r.Section).Data
+		addend := int32(uint32(data[r.Offset]) | uint32(data[r.Offset+1])<<8 | uint32(data[r.Offset+2])<<16 | uint32(data[r.Offset+3])<<24)
+		v := uint32(int32(sym.Value-r.Offset) + addend)
+		data[r.Offset], data[r.Offset+1], data[r.Offset+2], data[r.Offset+3] = byte(v), byte(v>>8), byte(v>>16), byte(v>>24)
+	}
+	a.obj.Relocs = remain
+}
+
+// stripComment 去掉 ; 之后的注释（引号中的 ; 除外）
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch ch := line[i]; {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'' || ch == '`':
+			quote = ch
+		case ch == ';':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// splitLabel 拆分行首的 "标签:"
+func splitLabel(line string) (label, rest string, ok bool) {
+	i := 0
+	for i < len(line) && isIdentChar(line[i]) {
+		i++
+	}
+	if i == 0 || i >= len(line) || line[i] != ':' {
+		return "", line, false
+	}
+	return line[:i], strings.TrimSpace(line[i+1:]), true
+}
+
+func isIdentChar(ch byte) bool {
+	return ch == '_' || ch == '.' || ch == '$' || ch == '@' || ch == '?' ||
+		ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9'
+}
+
+// splitOperands 按逗号拆分操作数（引号和方括号中的逗号除外）
+func splitOperands(s string) (items []string) {
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'' || ch == '`':
+			quote = ch
+		case ch == '[':
+			depth++
+		case ch == ']':
+			depth--
+		case ch == ',' && depth == 0:
+			items = append(items, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" || len(items) > 0 {
+		items = append(items, last)
+	}
+	return items
+}
+
+// parseNumber 解析十进制、0x 十六进制或字符常量
+func parseNumber(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 3 && (s[0] == '\'' || s[0] == '"') && s[2] == s[0] {
+		return int64(s[1]), nil
+	}
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		v, err := strconv.ParseUint(s[2:], 16, 32)
+		return int64(v), err
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
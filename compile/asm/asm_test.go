@@ -0,0 +1,170 @@
+package asm
+
+import (
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// text 汇编 src 并返回 .text 节的内容（十六进制）
+func text(t *testing.T, src string) string {
+	t.Helper()
+	obj, err := Assemble(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(obj.Section(".text").Data)
+}
+
+// TestEncode 逐条检查指令的机器码（与 objdump -M intel 反汇编的结果对照得到）
+func TestEncode(t *testing.T) {
+	cases := []struct {
+		src  string
+		want string
+	}{
+		{"mov eax, 42", "b82a000000"},
+		{"mov ecx, ebx", "89d9"},
+		{"mov ebp, esp", "89e5"},
+		{"mov DWORD[ebp-8], 0", "c745f800000000"},
+		{"mov EAX, DWORD[ebp+8]", "8b4508"},
+		{"mov eax, DWORD[eax+ecx*4+8]", "8b448808"},
+		{"mov BYTE[eax], cl", "8808"},
+		{"mov WORD[ecx+2], 7", "66c741020700"},
+		{"mov al, ah", "88e0"},
+		{"movzx eax, BYTE[ebp-1]", "0fb645ff"},
+		{"movsx ecx, WORD[eax]", "0fbf08"},
+		{"add eax, 1", "83c001"},
+		{"add eax, 1000", "81c0e8030000"},
+		{"sub esp, 24", "83ec18"},
+		{"cmp DWORD[ebp-4], 8", "837dfc08"},
+		{"xor edx, edx", "31d2"},
+		{"and eax, ebx", "21d8"},
+		{"test eax, eax", "85c0"},
+		{"imul eax, ecx", "0fafc1"},
+		{"imul ecx, ecx, 12", "6bc90c"},
+		{"idiv ecx", "f7f9"},
+		{"neg eax", "f7d8"},
+		{"inc eax", "40"},
+		{"dec DWORD[ebp-4]", "ff4dfc"},
+		{"shl eax, 2", "c1e002"},
+		{"sar eax, cl", "d3f8"},
+		{"lea eax, [ebx+ecx*4]", "8d048b"},
+		{"lea eax, [esp+4]", "8d442404"},
+		{"push ebp", "55"},
+		{"push 7", "6a07"},
+		{"push 1000", "68e8030000"},
+		{"push DWORD[ebp-12]", "ff75f4"},
+		{"pop ebx", "5b"},
+		{"sete al", "0f94c0"},
+		{"cmovl eax, ecx", "0f4cc1"},
+		{"cdq", "99"},
+		{"int 0x80", "cd80"},
+		{"leave", "c9"},
+		{"ret", "c3"},
+		{"ret 8", "c20800"},
+	}
+	for _, c := range cases {
+		if got := text(t, c.src); got != c.want {
+			t.Errorf("%s 编码为 %s，应为 %s", c.src, got, c.want)
+		}
+	}
+}
+
+// TestBranch 同一节内的跳转在汇编时解析为 rel32，向前与向后的目标都相对下一条指令
+func TestBranch(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"向后", "top:\n nop\n jmp top\n jne top", "90" + "e9faffffff" + "0f85f4ffffff"},
+		{"向前", "jmp end\n nop\nend:", "e901000000" + "90"},
+		{"较远", "je far\n times 200 nop\nfar:", "0f84c8000000" + strings.Repeat("90", 200)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := text(t, c.src); got != c.want {
+				t.Errorf("编码为 %s，应为 %s", got, c.want)
+			}
+		})
+	}
+}
+
+// TestReloc 对其他节中的符号与未定义符号的引用记录为重定位，被修正的 4 字节中写入加数
+func TestReloc(t *testing.T) {
+	obj, err := Assemble(`extern helper
+call helper
+mov eax, DWORD[g_x]
+jmp [g_t+eax*4]
+section .data
+g_x: dd 4
+section .rodata
+g_t: dd top
+section .text
+top:`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hex.EncodeToString(obj.Section(".text").Data), "e8fcffffff"+"8b0500000000"+"ff248500000000"; got != want {
+		t.Errorf(".text 为 %s，应为 %s", got, want)
+	}
+	want := []Reloc{
+		{Section: ".text", Offset: 1, Symbol: "helper", PCRel: true},
+		{Section: ".text", Offset: 7, Symbol: "g_x"},
+		{Section: ".text", Offset: 14, Symbol: "g_t"},
+		{Section: ".rodata", Offset: 0, Symbol: "top"},
+	}
+	if !reflect.DeepEqual(obj.Relocs, want) {
+		t.Errorf("重定位为 %+v，应为 %+v", obj.Relocs, want)
+	}
+	if got := obj.Undefined(); !reflect.DeepEqual(got, []string{"helper"}) {
+		t.Errorf("未定义的符号为 %v，应为 [helper]", got)
+	}
+	if sym := obj.Symbols["top"]; sym == nil || sym.Section != ".text" || sym.Value != 18 {
+		t.Errorf("top 为 %+v，应位于 .text+18", sym)
+	}
+}
+
+// TestData 数据伪指令按 align 填充，.bss 只记录大小
+func TestData(t *testing.T) {
+	obj, err := Assemble(`section .data
+align 4
+g_a: db 1, 2
+align 4
+g_b: dd 7
+section .bss
+alignb 4
+g_c: resb 6`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, bss := obj.Section(".data"), obj.Section(".bss")
+	if got := hex.EncodeToString(data.Data); got != "0102000007000000" || data.Align != 4 {
+		t.Errorf(".data 为 %s（对齐 %d），应为 0102000007000000（对齐 4）", got, data.Align)
+	}
+	if bss.Len() != 6 || len(bss.Data) != 0 || bss.Align != 4 {
+		t.Errorf(".bss 的大小为 %d（内容 %d 字节，对齐 %d），应为 6（没有内容，对齐 4）", bss.Len(), len(bss.Data), bss.Align)
+	}
+	if sym := obj.Symbols["g_b"]; sym == nil || sym.Value != 4 {
+		t.Errorf("g_b 为 %+v，应位于 .data+4", sym)
+	}
+}
+
+// TestError 不支持的指令与无效的操作数报告出错的行
+func TestError(t *testing.T) {
+	cases := []struct {
+		src  string
+		want string
+	}{
+		{"nop\nfoo eax", "第 2 行"},
+		{"mov eax", "mov 的操作数无效"},
+		{"sete eax", "需要 8 位操作数"},
+	}
+	for _, c := range cases {
+		_, err := Assemble(c.src)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%q 的错误为 %v，应包含 %q", c.src, err, c.want)
+		}
+	}
+}
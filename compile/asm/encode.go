@@ -0,0 +1,495 @@
+package asm
+
+import "fmt"
+
+// 双操作数算术指令在 ModRM.reg 中的扩展码（同时决定 r/m,reg 形式的操作码 op*8+1）
+var aluOps = map[string]byte{"add": 0, "or": 1, "adc": 2, "sbb": 3, "and": 4, "sub": 5, "xor": 6, "cmp": 7}
+
+// 单操作数 F6/F7 组指令的扩展码
+var unaryOps = map[string]byte{"not": 2, "neg": 3, "mul": 4, "div": 6, "idiv": 7}
+
+// 移位指令的扩展码
+var shiftOps = map[string]byte{"rol": 0, "ror": 1, "rcl": 2, "rcr": 3, "shl": 4, "sal": 4, "shr": 5, "sar": 7}
+
+// 条件码，用于 jcc/setcc/cmovcc
+var conditions = map[string]byte{
+	"o": 0, "no": 1, "b": 2, "c": 2, "nae": 2, "ae": 3, "nb": 3, "nc": 3,
+	"e": 4, "z": 4, "ne": 5, "nz": 5, "be": 6, "na": 6, "a": 7, "nbe": 7,
+	"s": 8, "ns": 9, "p": 10, "pe": 10, "np": 11, "po": 11,
+	"l": 12, "nge": 12, "ge": 13, "nl": 13, "le": 14, "ng": 14, "g": 15, "nle": 15,
+}
+
+// 无操作数指令
+var plainOps = map[string][]byte{
+	"leave": {0xC9}, "cdq": {0x99}, "nop": {0x90}, "hlt": {0xF4}, "int3": {0xCC},
+}
+
+// encode 编码一条指令
+func (a *assembler) encode(mn string, ops []operand) error {
+	if code, ok := plainOps[mn]; ok {
+		if len(ops) != 0 {
+			return fmt.Errorf("%s 没有操作数", mn)
+		}
+		a.emit(code...)
+		return nil
+	}
+	if op, ok := aluOps[mn]; ok {
+		return a.encodeALU(op, ops)
+	}
+	if op, ok := unaryOps[mn]; ok {
+		return a.encodeUnary(op, ops)
+	}
+	if op, ok := shiftOps[mn]; ok {
+		return a.encodeShift(op, ops)
+	}
+	if len(mn) > 1 && mn[0] == 'j' && mn != "jmp" {
+		if cc, ok := conditions[mn[1:]]; ok {
+			return a.encodeBranch([]byte{0x0F, 0x80 + cc}, nil, 0, ops)
+		}
+	}
+	if len(mn) > 3 && mn[:3] == "set" {
+		if cc, ok := conditions[mn[3:]]; ok {
+			if len(ops) != 1 || ops[0].kind == opImm || ops[0].size == 2 || ops[0].size == 4 {
+				return fmt.Errorf("%s 需要 8 位操作数", mn)
+			}
+			a.emit(0x0F, 0x90+cc)
+			return a.modrm(0, ops[0])
+		}
+	}
+	if len(mn) > 4 && mn[:4] == "cmov" {
+		if cc, ok := conditions[mn[4:]]; ok {
+			if len(ops) != 2 || ops[0].kind != opReg || ops[0].size != 4 || ops[1].kind == opImm {
+				return fmt.Errorf("%s 的操作数无效", mn)
+			}
+			a.emit(0x0F, 0x40+cc)
+			return a.modrm(byte(ops[0].reg), ops[1])
+		}
+	}
+
+	switch mn {
+	case "mov":
+		return a.encodeMov(ops)
+	case "movzx", "movsx":
+		return a.encodeExtend(mn, ops)
+	case "lea":
+		if len(ops) != 2 || ops[0].kind != opReg || ops[0].size != 4 || ops[1].kind != opMem {
+			return fmt.Errorf("lea 的操作数无效")
+		}
+		a.emit(0x8D)
+		return a.modrm(byte(ops[0].reg), ops[1])
+	case "test":
+		return a.encodeTest(ops)
+	case "imul":
+		return a.encodeImul(ops)
+	case "inc", "dec":
+		return a.encodeIncDec(mn, ops)
+	case "push":
+		return a.encodePush(ops)
+	case "pop":
+		if len(ops) != 1 || ops[0].kind == opImm {
+			return fmt.Errorf("pop 的操作数无效")
+		}
+		if ops[0].kind == opReg {
+			if ops[0].size != 4 {
+				return fmt.Errorf("pop 只支持 32 位寄存器")
+			}
+			a.emit(0x58 + byte(ops[0].reg))
+			return nil
+		}
+		a.emit(0x8F)
+		return a.modrm(0, ops[0])
+	case "jmp":
+		return a.encodeBranch([]byte{0xE9}, []byte{0xFF}, 4, ops)
+	case "call":
+		return a.encodeBranch([]byte{0xE8}, []byte{0xFF}, 2, ops)
+	case "ret":
+		if len(ops) == 0 {
+			a.emit(0xC3)
+			return nil
+		}
+		if len(ops) != 1 || ops[0].kind != opImm || ops[0].sym != "" {
+			return fmt.Errorf("ret 的操作数无效")
+		}
+		a.emit(0xC2)
+		a.emitValue(ops[0].value, 2)
+		return nil
+	case "int":
+		if len(ops) != 1 || ops[0].kind != opImm || ops[0].sym != "" {
+			return fmt.Errorf("int 的操作数无效")
+		}
+		a.emit(0xCD, byte(ops[0].value))
+		return nil
+	}
+	return fmt.Errorf("不支持的指令 %s", mn)
+}
+
+// operandSize 推断指令的操作宽度：寄存器或带长度前缀的内存操作数
+func operandSize(ops ...operand) (int, error) {
+	size := 0
+	for _, op := range ops {
+		if op.kind == opImm || op.size == 0 {
+			continue
+		}
+		if size != 0 && size != op.size {
+			return 0, fmt.Errorf("操作数宽度不一致")
+		}
+		size = op.size
+	}
+	if size == 0 {
+		return 0, fmt.Errorf("无法确定操作数宽度")
+	}
+	return size, nil
+}
+
+// sizePrefix 16 位操作输出操作数大小前缀
+func (a *assembler) sizePrefix(size int) {
+	if size == 2 {
+		a.emit(0x66)
+	}
+}
+
+// imm 输出宽度为 size 的立即数，引用标签时记录重定位
+func (a *assembler) imm(op operand, size int) {
+	if op.sym != "" {
+		a.reloc(op.sym, false)
+	}
+	a.emitValue(op.value, size)
+}
+
+// modrm 输出 ModRM（以及需要时的 SIB 和偏移），reg 为寄存器编号或扩展码
+func (a *assembler) modrm(reg byte, rm operand) error {
+	switch rm.kind {
+	case opReg:
+		a.emit(0xC0 | reg<<3 | byte(rm.reg))
+		return nil
+	case opImm:
+		return fmt.Errorf("此处不能使用立即数")
+	}
+
+	scaleBits := map[int]byte{1: 0, 2: 1, 4: 2, 8: 3}[rm.scale]
+	if rm.base < 0 {
+		if rm.index < 0 {
+			// [disp32]
+			a.emit(reg<<3 | 5)
+		} else {
+			// [index*scale + disp32]
+			a.emit(reg<<3|4, scaleBits<<6|byte(rm.index)<<3|5)
+		}
+		a.imm(operand{value: rm.value, sym: rm.sym}, 4)
+		return nil
+	}
+
+	var mod byte
+	dispSize := 0
+	switch {
+	case rm.sym != "" || rm.value < -128 || rm.value > 127:
+		mod, dispSize = 2, 4
+	case rm.value != 0 || rm.base == 5:
+		// EBP 作基址时没有不带偏移的编码
+		mod, dispSize = 1, 1
+	}
+	if rm.index >= 0 || rm.base == 4 {
+		index := byte(4) // 没有变址
+		if rm.index >= 0 {
+			index = byte(rm.index)
+		}
+		a.emit(mod<<6|reg<<3|4, scaleBits<<6|index<<3|byte(rm.base))
+	} else {
+		a.emit(mod<<6 | reg<<3 | byte(rm.base))
+	}
+	switch dispSize {
+	case 1:
+		a.emit(byte(rm.value))
+	case 4:
+		a.imm(operand{value: rm.value, sym: rm.sym}, 4)
+	}
+	return nil
+}
+
+func (a *assembler) encodeMov(ops []operand) error {
+	if len(ops) != 2 || ops[0].kind == opImm || ops[0].kind == opMem && ops[1].kind == opMem {
+		return fmt.Errorf("mov 的操作数无效")
+	}
+	dst, src := ops[0], ops[1]
+	size, err := operandSize(dst, src)
+	if err != nil {
+		return err
+	}
+	a.sizePrefix(size)
+	wide := byte(1)
+	if size == 1 {
+		wide = 0
+	}
+	switch {
+	case src.kind == opImm && dst.kind == opReg:
+		a.emit(0xB0 + wide*8 + byte(dst.reg))
+		a.imm(src, size)
+		return nil
+	case src.kind == opImm:
+		a.emit(0xC6 + wide)
+		if err := a.modrm(0, dst); err != nil {
+			return err
+		}
+		a.imm(src, size)
+		return nil
+	case src.kind == opReg:
+		a.emit(0x88 + wide)
+		return a.modrm(byte(src.reg), dst)
+	default:
+		a.emit(0x8A + wide)
+		return a.modrm(byte(dst.reg), src)
+	}
+}
+
+func (a *assembler) encodeExtend(mn string, ops []operand) error {
+	if len(ops) != 2 || ops[0].kind != opReg || ops[1].kind == opImm {
+		return fmt.Errorf("%s 的操作数无效", mn)
+	}
+	if ops[1].size != 1 && ops[1].size != 2 {
+		return fmt.Errorf("%s 的源操作数需要 8 位或 16 位宽度", mn)
+	}
+	if ops[0].size <= ops[1].size {
+		return fmt.Errorf("%s 的目标操作数必须更宽", mn)
+	}
+	a.sizePrefix(ops[0].size)
+	op := byte(0xB6)
+	if mn == "movsx" {
+		op = 0xBE
+	}
+	if ops[1].size == 2 {
+		op++
+	}
+	a.emit(0x0F, op)
+	return a.modrm(byte(ops[0].reg), ops[1])
+}
+
+// encodeALU 编码 add/sub/cmp 等双操作数算术指令
+func (a *assembler) encodeALU(op byte, ops []operand) error {
+	if len(ops) != 2 || ops[0].kind == opImm || ops[0].kind == opMem && ops[1].kind == opMem {
+		return fmt.Errorf("操作数无效")
+	}
+	dst, src := ops[0], ops[1]
+	size, err := operandSize(dst, src)
+	if err != nil {
+		return err
+	}
+	a.sizePrefix(size)
+	wide := byte(1)
+	if size == 1 {
+		wide = 0
+	}
+	switch {
+	case src.kind == opImm:
+		immSize := size
+		switch {
+		case size == 1:
+			a.emit(0x80)
+		case src.fits8():
+			a.emit(0x83)
+			immSize = 1
+		default:
+			a.emit(0x81)
+		}
+		if err := a.modrm(op, dst); err != nil {
+			return err
+		}
+		a.imm(src, immSize)
+		return nil
+	case src.kind == opReg:
+		a.emit(op*8 + wide)
+		return a.modrm(byte(src.reg), dst)
+	default:
+		a.emit(op*8 + 2 + wide)
+		return a.modrm(byte(dst.reg), src)
+	}
+}
+
+func (a *assembler) encodeTest(ops []operand) error {
+	if len(ops) != 2 || ops[0].kind == opImm || ops[1].kind == opMem {
+		return fmt.Errorf("test 的操作数无效")
+	}
+	size, err := operandSize(ops...)
+	if err != nil {
+		return err
+	}
+	a.sizePrefix(size)
+	wide := byte(1)
+	if size == 1 {
+		wide = 0
+	}
+	if ops[1].kind == opImm {
+		a.emit(0xF6 + wide)
+		if err := a.modrm(0, ops[0]); err != nil {
+			return err
+		}
+		a.imm(ops[1], size)
+		return nil
+	}
+	a.emit(0x84 + wide)
+	return a.modrm(byte(ops[1].reg), ops[0])
+}
+
+// encodeUnary 编码 not/neg/mul/div/idiv
+func (a *assembler) encodeUnary(op byte, ops []operand) error {
+	if len(ops) != 1 || ops[0].kind == opImm {
+		return fmt.Errorf("操作数无效")
+	}
+	size, err := operandSize(ops...)
+	if err != nil {
+		return err
+	}
+	a.sizePrefix(size)
+	if size == 1 {
+		a.emit(0xF6)
+	} else {
+		a.emit(0xF7)
+	}
+	return a.modrm(op, ops[0])
+}
+
+func (a *assembler) encodeShift(op byte, ops []operand) error {
+	if len(ops) != 2 || ops[0].kind == opImm {
+		return fmt.Errorf("操作数无效")
+	}
+	size, err := operandSize(ops[0])
+	if err != nil {
+		return err
+	}
+	a.sizePrefix(size)
+	wide := byte(1)
+	if size == 1 {
+		wide = 0
+	}
+	switch count := ops[1]; {
+	case count.kind == opReg && count.size == 1 && count.reg == 1:
+		a.emit(0xD2 + wide)
+		return a.modrm(op, ops[0])
+	case count.kind == opImm && count.sym == "" && count.value == 1:
+		a.emit(0xD0 + wide)
+		return a.modrm(op, ops[0])
+	case count.kind == opImm && count.sym == "":
+		a.emit(0xC0 + wide)
+		if err := a.modrm(op, ops[0]); err != nil {
+			return err
+		}
+		a.emit(byte(count.value))
+		return nil
+	}
+	return fmt.Errorf("移位次数只能是立即数或 CL")
+}
+
+// encodeImul 编码 imul 的单操作数、双操作数和三操作数形式
+func (a *assembler) encodeImul(ops []operand) error {
+	switch len(ops) {
+	case 1:
+		return a.encodeUnary(5, ops)
+	case 2:
+		if ops[1].kind == opImm {
+			return a.encodeImul([]operand{ops[0], ops[0], ops[1]})
+		}
+	}
+	if len(ops) < 2 || ops[0].kind != opReg || ops[0].size == 1 || ops[1].kind == opImm {
+		return fmt.Errorf("imul 的操作数无效")
+	}
+	size, err := operandSize(ops[0], ops[1])
+	if err != nil {
+		return err
+	}
+	a.sizePrefix(size)
+	if len(ops) == 2 {
+		a.emit(0x0F, 0xAF)
+		return a.modrm(byte(ops[0].reg), ops[1])
+	}
+	if len(ops) != 3 || ops[2].kind != opImm {
+		return fmt.Errorf("imul 的操作数无效")
+	}
+	immSize := size
+	if ops[2].fits8() {
+		a.emit(0x6B)
+		immSize = 1
+	} else {
+		a.emit(0x69)
+	}
+	if err := a.modrm(byte(ops[0].reg), ops[1]); err != nil {
+		return err
+	}
+	a.imm(ops[2], immSize)
+	return nil
+}
+
+func (a *assembler) encodeIncDec(mn string, ops []operand) error {
+	if len(ops) != 1 || ops[0].kind == opImm {
+		return fmt.Errorf("%s 的操作数无效", mn)
+	}
+	ext := byte(0)
+	if mn == "dec" {
+		ext = 1
+	}
+	size, err := operandSize(ops...)
+	if err != nil {
+		return err
+	}
+	if ops[0].kind == opReg && size == 4 {
+		a.emit(0x40 + ext*8 + byte(ops[0].reg))
+		return nil
+	}
+	a.sizePrefix(size)
+	if size == 1 {
+		a.emit(0xFE)
+	} else {
+		a.emit(0xFF)
+	}
+	return a.modrm(ext, ops[0])
+}
+
+func (a *assembler) encodePush(ops []operand) error {
+	if len(ops) != 1 {
+		return fmt.Errorf("push 的操作数无效")
+	}
+	switch op := ops[0]; op.kind {
+	case opReg:
+		if op.size != 4 {
+			return fmt.Errorf("push 只支持 32 位寄存器")
+		}
+		a.emit(0x50 + byte(op.reg))
+	case opImm:
+		if op.fits8() {
+			a.emit(0x6A, byte(op.value))
+			return nil
+		}
+		a.emit(0x68)
+		a.imm(op, 4)
+	default:
+		if op.size != 0 && op.size != 4 {
+			return fmt.Errorf("push 只支持 32 位操作数")
+		}
+		a.emit(0xFF)
+		return a.modrm(6, op)
+	}
+	return nil
+}
+
+// encodeBranch 编码跳转和调用：目标为标签时使用 32 位相对偏移，否则通过 r/m 间接跳转
+func (a *assembler) encodeBranch(rel, indirect []byte, ext byte, ops []operand) error {
+	if len(ops) != 1 {
+		return fmt.Errorf("跳转指令需要一个操作数")
+	}
+	target := ops[0]
+	if target.kind == opImm {
+		if target.sym == "" || target.value != 0 {
+			return fmt.Errorf("跳转目标必须是标签")
+		}
+		a.emit(rel...)
+		a.reloc(target.sym, true)
+		a.emitValue(-4, 4)
+		return nil
+	}
+	if indirect == nil {
+		return fmt.Errorf("条件跳转目标必须是标签")
+	}
+	if target.size != 0 && target.size != 4 {
+		return fmt.Errorf("间接跳转只支持 32 位操作数")
+	}
+	a.emit(indirect...)
+	return a.modrm(ext, target)
+}
@@ -0,0 +1,208 @@
+package asm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 操作数种类
+const (
+	opReg = iota // 寄存器
+	opMem        // 内存
+	opImm        // 立即数或标签
+)
+
+// operand 解析后的操作数
+type operand struct {
+	kind  int
+	reg   int    // 寄存器编号
+	size  int    // 宽度（字节），内存操作数没有长度前缀时为 0
+	base  int    // 内存操作数的基址寄存器，没有时为 -1
+	index int    // 内存操作数的变址寄存器，没有时为 -1
+	scale int    // 变址比例
+	value int64  // 立即数或内存偏移
+	sym   string // 立即数或内存偏移中引用的标签
+}
+
+// register 寄存器名称与编号
+type register struct {
+	num  int
+	size int
+}
+
+var registers = map[string]register{
+	"eax": {0, 4}, "ecx": {1, 4}, "edx": {2, 4}, "ebx": {3, 4},
+	"esp": {4, 4}, "ebp": {5, 4}, "esi": {6, 4}, "edi": {7, 4},
+	"ax": {0, 2}, "cx": {1, 2}, "dx": {2, 2}, "bx": {3, 2},
+	"sp": {4, 2}, "bp": {5, 2}, "si": {6, 2}, "di": {7, 2},
+	"al": {0, 1}, "cl": {1, 1}, "dl": {2, 1}, "bl": {3, 1},
+	"ah": {4, 1}, "ch": {5, 1}, "dh": {6, 1}, "bh": {7, 1},
+}
+
+var sizePrefixes = map[string]int{"byte": 1, "word": 2, "dword": 4}
+
+func parseOperands(s string) (ops []operand, err error) {
+	for _, item := range splitOperands(s) {
+		op, err := parseOperand(item)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// parseOperand 解析单个操作数，如 EAX、DWORD[ebp-8]、[table+EAX*4]、-5、str_0
+func parseOperand(s string) (op operand, err error) {
+	s = strings.TrimSpace(s)
+	lower := strings.ToLower(s)
+	for prefix, size := range sizePrefixes {
+		if strings.HasPrefix(lower, prefix) && len(s) > len(prefix) && !isIdentChar(s[len(prefix)]) {
+			op.size = size
+			s = strings.TrimSpace(s[len(prefix):])
+			if strings.HasPrefix(strings.ToLower(s), "ptr") {
+				s = strings.TrimSpace(s[3:])
+			}
+			break
+		}
+	}
+	if r, ok := registers[strings.ToLower(s)]; ok {
+		return operand{kind: opReg, reg: r.num, size: r.size}, nil
+	}
+	if strings.HasPrefix(s, "[") {
+		if !strings.HasSuffix(s, "]") {
+			return op, fmt.Errorf("缺少 ]: %s", s)
+		}
+		return parseMem(s[1:len(s)-1], op.size)
+	}
+	op.kind = opImm
+	op.value, op.sym, err = parseExpr(s)
+	return op, err
+}
+
+// parseMem 解析方括号中的地址表达式：基址 + 变址*比例 + 偏移 + 标签
+func parseMem(s string, size int) (op operand, err error) {
+	op = operand{kind: opMem, size: size, base: -1, index: -1, scale: 1}
+	for _, t := range splitTerms(s) {
+		reg, scale, isReg := parseIndex(t.text)
+		switch {
+		case isReg && t.neg:
+			return op, fmt.Errorf("寄存器不能取负: %s", s)
+		case isReg && scale == 1 && op.base < 0:
+			op.base = reg
+		case isReg && op.index < 0:
+			if reg == 4 {
+				return op, fmt.Errorf("ESP 不能作为变址寄存器")
+			}
+			op.index, op.scale = reg, scale
+		case isReg:
+			return op, fmt.Errorf("寄存器过多: %s", s)
+		default:
+			v, sym, err := parseTerm(t)
+			if err != nil {
+				return op, err
+			}
+			if sym != "" {
+				if op.sym != "" {
+					return op, fmt.Errorf("地址中只能引用一个标签: %s", s)
+				}
+				op.sym = sym
+			}
+			op.value += v
+		}
+	}
+	return op, nil
+}
+
+// parseIndex 解析 REG 或 REG*N / N*REG
+func parseIndex(s string) (reg, scale int, ok bool) {
+	left, right, hasScale := strings.Cut(s, "*")
+	if !hasScale {
+		r, ok := registers[strings.ToLower(s)]
+		return r.num, 1, ok && r.size == 4
+	}
+	r, ok := registers[strings.ToLower(left)]
+	n := right
+	if !ok {
+		r, ok = registers[strings.ToLower(right)]
+		n = left
+	}
+	v, err := parseNumber(n)
+	if !ok || r.size != 4 || err != nil || (v != 1 && v != 2 && v != 4 && v != 8) {
+		return 0, 0, false
+	}
+	return r.num, int(v), true
+}
+
+// term 表达式中带符号的一项
+type term struct {
+	neg  bool
+	text string
+}
+
+// splitTerms 按 + - 拆分表达式，连续的符号合并（如 ebp+-8）
+func splitTerms(s string) (terms []term) {
+	s = strings.ReplaceAll(s, " ", "")
+	neg := false
+	start := 0
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) && s[i] != '+' && s[i] != '-' {
+			continue
+		}
+		if i > start {
+			terms = append(terms, term{neg: neg, text: s[start:i]})
+			neg = false
+		}
+		if i < len(s) && s[i] == '-' {
+			neg = !neg
+		}
+		start = i + 1
+	}
+	return terms
+}
+
+// parseExpr 解析 数值 ± 数值 + 标签 形式的表达式
+func parseExpr(s string) (value int64, sym string, err error) {
+	terms := splitTerms(s)
+	if len(terms) == 0 {
+		return 0, "", fmt.Errorf("缺少操作数")
+	}
+	for _, t := range terms {
+		v, name, err := parseTerm(t)
+		if err != nil {
+			return 0, "", err
+		}
+		if name != "" {
+			if sym != "" {
+				return 0, "", fmt.Errorf("表达式中只能引用一个标签: %s", s)
+			}
+			sym = name
+		}
+		value += v
+	}
+	return value, sym, nil
+}
+
+// parseTerm 解析数值或标签，标签不能取负
+func parseTerm(t term) (int64, string, error) {
+	if v, err := parseNumber(t.text); err == nil {
+		if t.neg {
+			v = -v
+		}
+		return v, "", nil
+	}
+	for k := 0; k < len(t.text); k++ {
+		if !isIdentChar(t.text[k]) {
+			return 0, "", fmt.Errorf("无法识别的操作数 %s", t.text)
+		}
+	}
+	if t.neg || t.text[0] >= '0' && t.text[0] <= '9' {
+		return 0, "", fmt.Errorf("无法识别的操作数 %s", t.text)
+	}
+	return 0, t.text, nil
+}
+
+// fits8 判断立即数能否以 8 位有符号数编码
+func (op operand) fits8() bool {
+	return op.sym == "" && op.value >= -128 && op.value <= 127
+}
@@ -1,8 +1,11 @@
 package compile
 
 import (
+	"cuteify/compile/arch"
 	"cuteify/compile/context"
+	"cuteify/compile/peephole"
 	"cuteify/parser"
+	typeSys "cuteify/type"
 	"cuteify/utils"
 	"fmt"
 	"os"
@@ -18,11 +21,19 @@ func init() {
 		GoArch = v
 		fmt.Println("Using target arch:", GoArch)
 	}
+	// 类型大小在解析阶段就已确定，需要在解析之前按目标架构设置字长
+	typeSys.PtrSize = WordSize(GoArch)
 }
 
 // Compiler 编译器结构体，负责将AST转换为汇编代码
 type Compiler struct {
-	Ctx *context.Context // 编译器上下文
+	Ctx         *context.Context // 编译器上下文
+	BoundsCheck bool             // 是否在下标访问时插入越界检查
+	Legacy      bool             // 有 IR 后端的架构（32 位 x86）默认先降低为 SSA 形式的中间表示再生成代码（见 compileIR），为真时直接从语法树生成
+	RegAlloc    context.RegAlloc // 寄存器分配方式，GraphAlloc 总是经 IR 生成
+	NoPeephole  bool             // 是否关闭对生成的 32 位 x86 汇编的窥孔优化
+	NoInline    bool             // 经 IR 生成时是否关闭在调用处展开函数（见 ir.Program.Inline）
+	Peephole    *peephole.Stats  // 最近一次编译的窥孔优化统计，未优化时为 nil
 }
 
 // NewCompiler 创建新的编译器
@@ -35,12 +46,33 @@ func NewCompiler(parser *parser.Parser) *Compiler {
 // Compile 编译入口方法，将AST节点编译为汇编代码
 func (c *Compiler) Compile(node *parser.Node) (code string) {
 	c.initializeContext()
+	if node.Father == nil {
+		defer func() { code = c.optimize(code) }()
+	}
+	if c.useIR() && node.Father == nil {
+		return c.compileIR(node)
+	}
 	code = c.compileRoot(node, code)
-	code += c.compileChildren(node, code)
+	code = c.compileChildren(node, code)
 	code += c.compileRootTail(node)
 	return code
 }
 
+// useIR 报告是否经中间表示编译整个程序：有 IR 后端的架构默认经 IR，Legacy 时直接从语法树生成，GraphAlloc 除外
+func (c *Compiler) useIR() bool {
+	return c.RegAlloc == context.GraphAlloc || HasBackend(GoArch) && !c.Legacy
+}
+
+// optimize 对整个程序的汇编代码做窥孔优化，只处理 32 位 x86 的 NASM 汇编
+func (c *Compiler) optimize(code string) string {
+	c.Peephole = nil
+	if c.NoPeephole || !IsAsm(GoArch) || WordSize(GoArch) != 4 {
+		return code
+	}
+	code, c.Peephole = peephole.Optimize(code)
+	return code
+}
+
 func (c *Compiler) initializeContext() {
 	if c.Ctx == nil {
 		c.Ctx = context.NewContext()
@@ -48,11 +80,15 @@ func (c *Compiler) initializeContext() {
 	if c.Ctx.Arch == nil {
 		c.Ctx.Arch = NewArch(GoArch, c.Ctx)
 	}
+	c.Ctx.BoundsCheck = c.BoundsCheck
+	c.Ctx.RegAlloc = c.RegAlloc
 }
 
 func (c *Compiler) compileRoot(node *parser.Node, code string) string {
 	if node.Father == nil {
-		return "section .text\nglobal _start\n\n"
+		// 缩进层级是全局状态，同一进程中多次编译时从顶层开始
+		utils.Count = 0
+		return c.syntax().Header(node)
 	}
 	return ""
 }
@@ -88,8 +124,16 @@ func (c *Compiler) compileChild(n *parser.Node) string {
 		return c.compileCallBlock(n)
 	case *parser.ForBlock:
 		return c.compileForBlock(n)
-	// TODO: case *parser.StructBlock:
-	// TODO: 	return c.compileStructBlock(n)
+	case *parser.WhileBlock:
+		return c.compileWhileBlock(n)
+	case *parser.SwitchBlock:
+		return c.compileSwitchBlock(n)
+	case *parser.BreakBlock:
+		return c.Ctx.Arch.Break(v)
+	case *parser.ContinueBlock:
+		return c.Ctx.Arch.Continue(v)
+	case *parser.StructBlock:
+		return c.compileStructBlock(n)
 	case *parser.Build:
 		return c.CompileBuild(n)
 	default:
@@ -99,6 +143,9 @@ func (c *Compiler) compileChild(n *parser.Node) string {
 
 func (c *Compiler) compileFuncBlock(n *parser.Node) string {
 	funcBlock := n.Value.(*parser.FuncBlock)
+	if funcBlock.Generic != nil {
+		return c.compileInstances(funcBlock.Generic)
+	}
 	if !funcBlock.Useful && funcBlock.Name.String() != "main" {
 		return ""
 	}
@@ -112,33 +159,12 @@ func (c *Compiler) compileIfBlock(n *parser.Node) string {
 	label := fmt.Sprintf("if_%d", c.Ctx.IfCount)
 
 	var code string
-	var endLabel string
+	code += c.syntax().If(ifBlock, label) + c.Compile(n)
 	if ifBlock.Else {
-		endLabel = "else_" + label
-	} else {
-		endLabel = "end_" + label
-	}
-
-	code += c.Ctx.Arch.Exp(ifBlock.Condition, endLabel, "")
-
-	code += utils.Format(label+":") + c.Compile(n)
-
-	if ifBlock.Else {
-		code += c.compileElseBlock(ifBlock, label)
-	}
-	code += utils.Format("end_" + label + ":")
-	return code
-}
-
-func (c *Compiler) compileElseBlock(ifBlock *parser.IfBlock, label string) string {
-	var code string
-	code += utils.Format("else_" + label + ":")
-	if ifBlock.ElseBlock.Value.(*parser.ElseBlock).IfCondition != nil {
-		code += c.Ctx.Arch.Exp(ifBlock.ElseBlock.Value.(*parser.ElseBlock).IfCondition, "end_"+label, "")
-	}
-	if ifBlock.ElseBlock != nil {
+		code += c.syntax().Else(ifBlock, label)
 		code += c.Compile(ifBlock.ElseBlock)
 	}
+	code += c.syntax().EndIf(ifBlock, label)
 	return code
 }
 
@@ -149,12 +175,37 @@ func (c *Compiler) compileReturnBlock(n *parser.Node) string {
 
 func (c *Compiler) compileVarBlock(n *parser.Node) string {
 	varBlock := n.Value.(*parser.VarBlock)
-	if varBlock.IsDefine && varBlock.Value == nil {
+	if varBlock.IsGlobal {
+		// 全局变量不生成代码，初始值在数据段中给出
+		c.Ctx.Data.AddGlobal(varBlock)
 		return ""
 	}
+	if varBlock.IsDefine && varBlock.Value == nil {
+		if init, ok := c.Ctx.Arch.(arch.Initializer); ok {
+			return init.InitVar(varBlock)
+		}
+		// 后端先将结构体变量清零，再写入字段默认值
+		return c.Ctx.Arch.Var(varBlock) + c.compileStructDefaults(n, varBlock.Name, varBlock.Type)
+	}
 	return c.Ctx.Arch.Var(varBlock)
 }
 
+// compileStructDefaults 为未初始化的结构体变量写入字段默认值（包括嵌套结构体）
+func (c *Compiler) compileStructDefaults(n *parser.Node, name parser.Name, t typeSys.Type) (code string) {
+	structType, ok := t.(*typeSys.StructType)
+	if !ok {
+		return ""
+	}
+	for _, field := range structType.StructFields {
+		fieldName := name.ForkJoin(field.Name)
+		if def, ok := field.Default.(*parser.Expression); ok && def != nil {
+			code += c.Ctx.Arch.Var(&parser.VarBlock{Name: fieldName, Type: field.Type, Value: def, Define: n})
+		}
+		code += c.compileStructDefaults(n, fieldName, field.Type)
+	}
+	return code
+}
+
 func (c *Compiler) compileCallBlock(n *parser.Node) string {
 	callBlock := n.Value.(*parser.CallBlock)
 	return c.Ctx.Arch.Call(callBlock)
@@ -169,19 +220,67 @@ func (c *Compiler) compileForBlock(n *parser.Node) string {
 	return code
 }
 
-// TODO: func (c *Compiler) compileStructBlock(n *parser.Node) string {
-// TODO: 	structBlock := n.Value.(*parser.StructBlock)
-// TODO: 	c.Ctx.AddStruct(structBlock)
-// TODO: 	return ""
-// TODO: }
+func (c *Compiler) compileWhileBlock(n *parser.Node) string {
+	whileBlock := n.Value.(*parser.WhileBlock)
+	var code string
+	code += c.Ctx.Arch.While(whileBlock)
+	code += c.Compile(n)
+	code += c.Ctx.Arch.EndWhile(whileBlock)
+	return code
+}
 
-func (c *Compiler) compileRootTail(node *parser.Node) string {
-	if node.Father == nil {
-		if c.hasMainFunction(node) {
-			return c.generateStartEntry()
+func (c *Compiler) compileSwitchBlock(n *parser.Node) string {
+	switchBlock := n.Value.(*parser.SwitchBlock)
+	var code string
+	code += c.Ctx.Arch.Switch(switchBlock)
+	for _, caseNode := range n.Children {
+		caseBlock, ok := caseNode.Value.(*parser.CaseBlock)
+		if !ok || caseNode.Ignore {
+			continue
 		}
+		code += c.Ctx.Arch.Case(switchBlock, caseBlock)
+		code += c.Compile(caseNode)
+		code += c.Ctx.Arch.EndCase(switchBlock, caseBlock)
 	}
-	return ""
+	code += c.Ctx.Arch.EndSwitch(switchBlock)
+	return code
+}
+
+func (c *Compiler) compileStructBlock(n *parser.Node) string {
+	structBlock := n.Value.(*parser.StructBlock)
+	if structBlock.Generic == nil {
+		c.Ctx.AddStruct(structBlock)
+		return ""
+	}
+	// 泛型结构体登记各个实例，并生成实例的方法
+	for _, inst := range structBlock.Generic.Instances {
+		c.Ctx.AddStruct(inst.Value.(*parser.StructBlock))
+	}
+	var code string
+	for _, method := range structBlock.MethodTemplates {
+		code += c.compileInstances(method.Generic)
+	}
+	return code
+}
+
+// compileInstances 生成泛型模板的各个实例，模板本身不生成代码
+func (c *Compiler) compileInstances(generic *parser.Generic) (code string) {
+	for _, inst := range generic.Instances {
+		c.Ctx.Now = inst
+		code += c.compileFuncBlock(inst)
+	}
+	return code
+}
+
+func (c *Compiler) compileRootTail(node *parser.Node) string {
+	if node.Father != nil {
+		return ""
+	}
+	var code string
+	if c.hasMainFunction(node) {
+		code += c.syntax().StartEntry()
+	}
+	return code + c.Ctx.Arch.Data()
 }
 
 func (c *Compiler) hasMainFunction(node *parser.Node) bool {
@@ -195,22 +294,6 @@ func (c *Compiler) hasMainFunction(node *parser.Node) bool {
 	return false
 }
 
-func (c *Compiler) generateStartEntry() string {
-	var code string
-	code += utils.Format("; ==============================")
-	code += utils.Format("; 程序入口点 (ELF入口)")
-	code += utils.Format("_start:")
-	utils.Count++
-	code += utils.Format("; 调用main函数")
-	code += utils.Format("call main")
-	code += utils.Format("; 使用系统调用退出程序 (sys_exit = 1)")
-	code += utils.Format("; 返回值在EAX中")
-	code += utils.Format("mov ebx, eax; 返回码")
-	code += utils.Format("mov eax, 1; sys_exit")
-	code += utils.Format("int 0x80; 调用内核\n")
-	return code
-}
-
 func (c *Compiler) funcHandle(funcBlock *parser.FuncBlock, node *parser.Node) (code string) {
 	//optimizer.OptimizeRecursion(c.Ctx.Now)           // 尝试优化递归函数
 	//optimizer.ConvertRecursionToIteration(c.Ctx.Now) // 实际转换递归为迭代
@@ -219,33 +302,49 @@ func (c *Compiler) funcHandle(funcBlock *parser.FuncBlock, node *parser.Node) (c
 	c.Ctx.CurrentFunc = funcBlock
 	defer func() { c.Ctx.CurrentFunc = nil }()
 
-	name := funcBlock.Name.String()
-	if name != "main" {
-		name = name + strconv.Itoa(len(funcBlock.Args))
-	}
-	code += utils.Format("; ==============================")
-	code += utils.Format("; Function: " + name)
-	code += utils.Format(name + ":")
+	code += c.syntax().FuncLabel(funcBlock, funcName(funcBlock), links(node))
 	utils.Count++
 	code += c.Ctx.Arch.Func(funcBlock)
 
 	code += c.Compile(c.Ctx.Now)
 
-	// 若最后不是显式 return，则补齐尾部清理
-	if _, ok := node.Children[len(node.Children)-1].Value.(*parser.ReturnBlock); !ok {
+	// 执行可能到达函数体末尾时补齐尾部清理。文本后端（Syntax）的输出还需要在结构上以返回结束，
+	// 最后一条语句不是 ret 时（如各分支都已返回的 if）即使末尾不可达也要补齐，由后端输出 unreachable 等
+	_, text := c.Ctx.Arch.(arch.Syntax)
+	if funcBlock.CFG == nil || funcBlock.CFG.EndReachable() || text && !endsWithReturn(node) {
 		code += c.Ctx.Arch.Return(nil)
 	}
 	if utils.Count > 0 {
 		utils.Count--
 	}
-	code += utils.Format("; ======函数完毕=======\n\n")
+	code += c.syntax().EndFunc(funcBlock)
 
-	// 清理寄存器分配数据
-	c.Ctx.Reg.Reset()
+	// 清理寄存器分配数据（不使用寄存器分配的后端没有寄存器管理器）
+	if c.Ctx.Reg != nil {
+		c.Ctx.Reg.Reset()
+	}
 
 	return
 }
 
+// endsWithReturn 判断函数体的最后一条语句是否为 ret
+func endsWithReturn(node *parser.Node) bool {
+	if len(node.Children) == 0 {
+		return false
+	}
+	_, ok := node.Children[len(node.Children)-1].Value.(*parser.ReturnBlock)
+	return ok
+}
+
+// funcName 返回函数标签使用的名称：main 之外的函数名后加参数个数以区分重载
+func funcName(funcBlock *parser.FuncBlock) string {
+	name := funcBlock.Name.String()
+	if name != "main" {
+		name = name + strconv.Itoa(len(funcBlock.Args))
+	}
+	return name
+}
+
 func pr(block *parser.Node, tabnum int) {
 	if block.Ignore {
 		return
@@ -2,10 +2,54 @@ package context
 
 import (
 	"cuteify/compile/arch"
+	"cuteify/compile/data"
 	"cuteify/compile/regmgr"
 	"cuteify/parser"
+	typeSys "cuteify/type"
 )
 
+// LoopLabel 循环的跳转标签
+type LoopLabel struct {
+	Continue string // continue 跳转目标（for 为增量部分，while 为条件检查，switch 沿用外层循环）
+	End      string // break 跳转目标（循环或 switch 结束位置）
+}
+
+// VTable 结构体实现某个接口时使用的虚表
+type VTable struct {
+	Label  string                 // 虚表标签
+	Struct *typeSys.StructType    // 实现接口的结构体
+	Iface  *typeSys.InterfaceType // 接口，方法顺序即虚表槽位顺序
+}
+
+// RegAlloc 寄存器分配方式
+type RegAlloc int
+
+const (
+	// RegMgrAlloc 默认：直接从语法树生成时由 RegMgr 按表达式分配寄存器，经 IR 生成时值放在栈帧中（不同时活跃的值共用位置）
+	RegMgrAlloc RegAlloc = iota
+	// GraphAlloc 经 IR 生成，由 compile/regalloc 以函数为单位对冲突图着色分配寄存器，放不下的值溢出到栈帧中
+	GraphAlloc
+)
+
+// String 返回分配方式的名称，与命令行参数 -regalloc 的取值相同
+func (r RegAlloc) String() string {
+	if r == GraphAlloc {
+		return "graph"
+	}
+	return "regmgr"
+}
+
+// ParseRegAlloc 由名称（regmgr 或 graph）得到分配方式
+func ParseRegAlloc(name string) (RegAlloc, bool) {
+	switch name {
+	case "regmgr":
+		return RegMgrAlloc, true
+	case "graph":
+		return GraphAlloc, true
+	}
+	return RegMgrAlloc, false
+}
+
 // Context 编译器上下文，统一管理编译状态
 type Context struct {
 	// AST 相关
@@ -15,8 +59,9 @@ type Context struct {
 	CurrentFunc *parser.FuncBlock // 当前正在编译的函数
 
 	// 寄存器管理
-	Reg  *regmgr.RegMgr // 寄存器管理器
-	Arch arch.Arch      // 编译器架构接口
+	Reg      *regmgr.RegMgr // 寄存器管理器
+	Arch     arch.Arch      // 编译器架构接口
+	RegAlloc RegAlloc       // 寄存器分配方式，GraphAlloc 时不使用 Reg
 
 	// 栈帧相关
 	SpOffset int // ESP 偏移量（栈指针相对于函数帧的偏移）
@@ -40,9 +85,26 @@ type Context struct {
 	ArgOffset    int // 参数偏移量，用于跟踪函数参数在栈中的位置
 	IfCount      int // if 块数量计数，用于生成唯一的if标签
 	ForCount     int // for 块数量计数，用于生成唯一的for标签
+	WhileCount   int // while 块数量计数，用于生成唯一的while标签
+	SwitchCount  int // switch 块数量计数，用于生成唯一的switch标签
+	BoundsCount  int // 越界检查数量计数，用于生成唯一的检查标签
+	BoolCount    int // 比较结果取值数量计数，用于生成唯一的取值标签
+
+	// 循环相关
+	Loops []LoopLabel // 循环标签栈，栈顶为最内层循环或 switch
 
 	// 结构体相关
-	// TODO: Structs map[string]*parser.StructBlock // 存储结构体定义
+	Structs map[string]*parser.StructBlock // 存储结构体定义（按类型名索引）
+
+	// 接口相关
+	VTables []*VTable // 用到的虚表，按首次使用顺序输出
+
+	// 数据段相关
+	Data *data.Section // 全局变量与字符串字面量
+
+	// 下标越界检查
+	BoundsCheck bool // 是否在下标访问时插入越界检查
+	BoundsPanic bool // 是否用到了越界 panic 例程
 }
 
 // NewContext 创建新的编译器上下文
@@ -62,7 +124,10 @@ func NewContext() *Context {
 		ArgOffset:      0,
 		IfCount:        0,
 		ForCount:       0,
-		// TODO: Structs:        make(map[string]*parser.StructBlock),
+		WhileCount:     0,
+		SwitchCount:    0,
+		Structs:        make(map[string]*parser.StructBlock),
+		Data:           data.NewSection(),
 	}
 }
 
@@ -77,6 +142,7 @@ func (ctx *Context) Reset() {
 	ctx.EbxOccupied = false
 	ctx.ExpType = 0
 	ctx.VarStackSize = 0
+	ctx.Loops = nil
 }
 
 // Clone 克隆当前上下文（用于保存和恢复状态）
@@ -85,6 +151,7 @@ func (ctx *Context) Clone() *Context {
 		Now:            ctx.Now,
 		Reg:            ctx.Reg,
 		Arch:           ctx.Arch,
+		RegAlloc:       ctx.RegAlloc,
 		SpOffset:       ctx.SpOffset,
 		BpOffset:       ctx.BpOffset,
 		StackSize:      ctx.StackSize,
@@ -98,33 +165,73 @@ func (ctx *Context) Clone() *Context {
 		ArgOffset:      ctx.ArgOffset,
 		IfCount:        ctx.IfCount,
 		ForCount:       ctx.ForCount,
-		// TODO: Structs:        ctx.Structs, // 共享结构体映射
+		WhileCount:     ctx.WhileCount,
+		SwitchCount:    ctx.SwitchCount,
+		BoundsCount:    ctx.BoundsCount,
+		BoolCount:      ctx.BoolCount,
+		Loops:          ctx.Loops,
+		Structs:        ctx.Structs, // 共享结构体映射
+		VTables:        ctx.VTables,
+		Data:           ctx.Data,
+		BoundsCheck:    ctx.BoundsCheck,
+		BoundsPanic:    ctx.BoundsPanic,
 	}
 }
 
-// TODO: // AddStruct 添加结构体定义到上下文
-// TODO: func (ctx *Context) AddStruct(structBlock *parser.StructBlock) {
-// TODO: 	ctx.Structs[structBlock.Name] = structBlock
-// TODO: }
-// TODO:
-// TODO: // GetStruct 获取结构体定义
-// TODO: func (ctx *Context) GetStruct(name string) (*parser.StructBlock, bool) {
-// TODO: 	structBlock, exists := ctx.Structs[name]
-// TODO: 	return structBlock, exists
-// TODO: }
-// TODO:
-// TODO: // GetStructFieldOffset 获取结构体字段的偏移量
-// TODO: func (ctx *Context) GetStructFieldOffset(structName, fieldName string) (int, bool) {
-// TODO: 	structBlock, exists := ctx.GetStruct(structName)
-// TODO: 	if !exists {
-// TODO: 		return 0, false
-// TODO: 	}
-// TODO:
-// TODO: 	for _, field := range structBlock.Fields {
-// TODO: 		if field.Name == fieldName {
-// TODO: 			return field.Offset, true
-// TODO: 		}
-// TODO: 	}
-// TODO:
-// TODO: 	return 0, false
-// TODO: }
+// PushLoop 进入循环，记录其 continue/break 跳转标签
+func (ctx *Context) PushLoop(continueLabel, endLabel string) {
+	ctx.Loops = append(ctx.Loops, LoopLabel{Continue: continueLabel, End: endLabel})
+}
+
+// PopLoop 离开最内层循环
+func (ctx *Context) PopLoop() {
+	if len(ctx.Loops) > 0 {
+		ctx.Loops = ctx.Loops[:len(ctx.Loops)-1]
+	}
+}
+
+// CurrentLoop 获取最内层循环的跳转标签
+func (ctx *Context) CurrentLoop() (LoopLabel, bool) {
+	if len(ctx.Loops) == 0 {
+		return LoopLabel{}, false
+	}
+	return ctx.Loops[len(ctx.Loops)-1], true
+}
+
+// AddStruct 添加结构体定义到上下文
+func (ctx *Context) AddStruct(structBlock *parser.StructBlock) {
+	ctx.Structs[structBlock.Type().Type()] = structBlock
+}
+
+// GetStruct 获取结构体定义
+func (ctx *Context) GetStruct(name string) (*parser.StructBlock, bool) {
+	structBlock, exists := ctx.Structs[name]
+	return structBlock, exists
+}
+
+// GetStructFieldOffset 获取结构体字段的偏移量
+func (ctx *Context) GetStructFieldOffset(structName, fieldName string) (int, bool) {
+	structBlock, exists := ctx.GetStruct(structName)
+	if !exists {
+		return 0, false
+	}
+
+	field := structBlock.GetFieldByName(fieldName)
+	if field == nil {
+		return 0, false
+	}
+
+	return field.Offset, true
+}
+
+// AddVTable 登记 (结构体, 接口) 对应的虚表，已存在时直接返回
+func (ctx *Context) AddVTable(label string, structType *typeSys.StructType, iface *typeSys.InterfaceType) *VTable {
+	for _, vt := range ctx.VTables {
+		if vt.Label == label {
+			return vt
+		}
+	}
+	vt := &VTable{Label: label, Struct: structType, Iface: iface}
+	ctx.VTables = append(ctx.VTables, vt)
+	return vt
+}
@@ -0,0 +1,150 @@
+// Package data 管理与目标架构无关的数据段内容：全局变量（.data/.bss）和字符串字面量（.rodata）。
+// 各架构只负责把这里收集到的条目格式化为对应的汇编伪指令。
+package data
+
+import (
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Item 全局变量初始值中的一个非零标量
+type Item struct {
+	Offset int    // 相对变量起始地址的偏移
+	Size   int    // 字节数
+	Value  string // 数值常量或标签（如字符串字面量的地址）
+}
+
+// Global 全局变量
+type Global struct {
+	Label string
+	Var   *parser.VarBlock
+	Items []Item // 按偏移排序的非零初始值，为空时放入 .bss
+}
+
+// Size 返回全局变量占用的字节数
+func (g *Global) Size() int {
+	return g.Var.Type.Size()
+}
+
+// Align 返回全局变量的对齐值
+func (g *Global) Align() int {
+	return typeSys.AlignOf(g.Var.Type)
+}
+
+// IsZero 报告全局变量是否零初始化
+func (g *Global) IsZero() bool {
+	return len(g.Items) == 0
+}
+
+// String 字符串字面量，以 0 结尾存放在 .rodata 中
+type String struct {
+	Label string
+	Value string
+}
+
+// Section 编译过程中收集到的数据段内容
+type Section struct {
+	Globals []*Global
+	Strings []*String
+	strings map[string]*String
+}
+
+// NewSection 创建空的数据段
+func NewSection() *Section {
+	return &Section{strings: make(map[string]*String)}
+}
+
+// GlobalLabel 返回全局变量的符号名，加前缀避免与寄存器名、汇编关键字冲突
+func GlobalLabel(name parser.Name) string {
+	return "g_" + name.String()
+}
+
+// IsGlobal 报告变量引用（或定义）是否指向全局变量
+func IsGlobal(v *parser.VarBlock) bool {
+	return Define(v).IsGlobal
+}
+
+// Define 返回变量引用对应的定义，字段访问时为整个变量的定义
+func Define(v *parser.VarBlock) *parser.VarBlock {
+	if v.Define != nil {
+		if def, ok := v.Define.Value.(*parser.VarBlock); ok {
+			return def
+		}
+	}
+	return v
+}
+
+// AddGlobal 登记全局变量，初始值必须是常量（结构体按字段默认值初始化）
+func (s *Section) AddGlobal(v *parser.VarBlock) *Global {
+	for _, g := range s.Globals {
+		if g.Var == v {
+			return g
+		}
+	}
+	g := &Global{Label: GlobalLabel(v.Name), Var: v}
+	if v.Value != nil {
+		g.Items = s.constItems(v.Value, v.Type, 0, g.Items)
+	} else {
+		g.Items = s.defaultItems(v.Type, 0, g.Items)
+	}
+	sort.Slice(g.Items, func(i, j int) bool { return g.Items[i].Offset < g.Items[j].Offset })
+	s.Globals = append(s.Globals, g)
+	return g
+}
+
+// Intern 将字符串字面量放入 .rodata 并返回其标签，相同内容共用一个标签
+func (s *Section) Intern(value string) string {
+	if str, ok := s.strings[value]; ok {
+		return str.Label
+	}
+	str := &String{Label: "str_" + strconv.Itoa(len(s.Strings)), Value: value}
+	s.strings[value] = str
+	s.Strings = append(s.Strings, str)
+	return str.Label
+}
+
+// constItems 将常量表达式转换为初始值条目，零值省略
+func (s *Section) constItems(exp *parser.Expression, t typeSys.Type, offset int, items []Item) []Item {
+	var value string
+	switch typeSys.GetTypeType(t) {
+	case "string":
+		value = s.Intern(exp.StringVal)
+	case "bool":
+		if exp.Bool {
+			value = "1"
+		}
+	case "float":
+		if exp.Num != 0 {
+			value = strconv.FormatFloat(exp.Num, 'f', -1, 64)
+			if !strings.Contains(value, ".") {
+				value += ".0"
+			}
+		}
+	default:
+		if exp.Num != 0 {
+			value = strconv.FormatInt(int64(exp.Num), 10)
+		}
+	}
+	if value == "" {
+		return items
+	}
+	return append(items, Item{Offset: offset, Size: t.Size(), Value: value})
+}
+
+// defaultItems 按结构体字段默认值生成初始值条目（包括嵌套结构体）
+func (s *Section) defaultItems(t typeSys.Type, offset int, items []Item) []Item {
+	structType, ok := t.(*typeSys.StructType)
+	if !ok {
+		return items
+	}
+	for _, field := range structType.StructFields {
+		if def, ok := field.Default.(*parser.Expression); ok && def != nil {
+			items = s.constItems(def, field.Type, offset+field.Offset, items)
+		}
+		items = s.defaultItems(field.Type, offset+field.Offset, items)
+	}
+	return items
+}
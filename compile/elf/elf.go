@@ -0,0 +1,383 @@
+// Package elf 将 asm 包的汇编结果写成 ELF32（i386）可重定位目标文件或静态可执行文件，无需外部的 ld。
+package elf
+
+import (
+	"bytes"
+	"cuteify/compile/asm"
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+const (
+	ehdrSize = 52 // ELF 头大小
+	phdrSize = 32 // 程序头大小
+	shdrSize = 40 // 节头大小
+	symSize  = 16 // 符号表项大小
+	relSize  = 8  // 重定位项大小
+
+	etRel   = 1
+	etExec  = 2
+	em386   = 3
+	ptLoad  = 1
+	r386_32 = 1 // 绝对地址
+	r386PC  = 2 // 相对 PC
+
+	baseAddr = 0x08048000 // 可执行文件的装载基址
+	pageSize = 0x1000
+)
+
+// 节类型与标志
+const (
+	shtProgbits = 1
+	shtSymtab   = 2
+	shtStrtab   = 3
+	shtNobits   = 8
+	shtRel      = 9
+
+	shfWrite = 1
+	shfAlloc = 2
+	shfExec  = 4
+)
+
+// sectionOrder 支持的节，按输出顺序排列
+var sectionOrder = []string{".text", ".rodata", ".data", ".bss"}
+
+func sectionFlags(name string) uint32 {
+	switch name {
+	case ".text":
+		return shfAlloc | shfExec
+	case ".rodata":
+		return shfAlloc
+	}
+	return shfAlloc | shfWrite
+}
+
+// sections 按输出顺序返回目标中的节，遇到不支持的节时报错
+func sections(obj *asm.Object) ([]*asm.Section, error) {
+	var secs []*asm.Section
+	for _, s := range obj.Sections {
+		known := false
+		for _, name := range sectionOrder {
+			known = known || s.Name == name
+		}
+		if !known {
+			return nil, fmt.Errorf("不支持的节 %s", s.Name)
+		}
+	}
+	for _, name := range sectionOrder {
+		if s := obj.Section(name); s != nil {
+			secs = append(secs, s)
+		}
+	}
+	return secs, nil
+}
+
+func alignUp(v, align int) int {
+	if align <= 1 {
+		return v
+	}
+	return (v + align - 1) / align * align
+}
+
+// patch 在被修正的 4 字节上加上 v（其中已有汇编时写入的加数）
+func patch(data []byte, offset int, v uint32) {
+	binary.LittleEndian.PutUint32(data[offset:], binary.LittleEndian.Uint32(data[offset:])+v)
+}
+
+// WriteExecutable 生成以 _start 为入口的静态可执行文件
+// 代码和只读数据放在可读可执行的段中（连同文件头），可写数据和 .bss 放在另一个段中
+func WriteExecutable(obj *asm.Object) ([]byte, error) {
+	secs, err := sections(obj)
+	if err != nil {
+		return nil, err
+	}
+	if undefined := obj.Undefined(); len(undefined) > 0 {
+		return nil, fmt.Errorf("未定义的符号 %v", undefined)
+	}
+	entry := obj.Symbols["_start"]
+	if entry == nil {
+		return nil, fmt.Errorf("缺少入口 _start")
+	}
+
+	// 布局：虚拟地址 = 装载基址 + 文件偏移，可写段从新的一页开始
+	var text, data []*asm.Section
+	for _, s := range secs {
+		if sectionFlags(s.Name)&shfWrite == 0 {
+			text = append(text, s)
+		} else {
+			data = append(data, s)
+		}
+	}
+	phnum := 1
+	if len(data) > 0 {
+		phnum++
+	}
+	addr := map[string]int{}
+	offset := ehdrSize + phnum*phdrSize
+	for _, s := range text {
+		offset = alignUp(offset, s.Align)
+		addr[s.Name] = offset
+		offset += s.Len()
+	}
+	textEnd := offset
+	dataStart := alignUp(offset, pageSize)
+	offset = dataStart
+	fileEnd := dataStart
+	for _, s := range data {
+		offset = alignUp(offset, s.Align)
+		addr[s.Name] = offset
+		offset += s.Len()
+		if !s.IsBss() {
+			fileEnd = offset
+		}
+	}
+	memEnd := offset
+
+	// 应用重定位
+	contents := map[string][]byte{}
+	for _, s := range secs {
+		contents[s.Name] = append([]byte(nil), s.Data...)
+	}
+	for _, r := range obj.Relocs {
+		sym := obj.Symbols[r.Symbol]
+		target := uint32(baseAddr + addr[sym.Section] + sym.Value)
+		if r.PCRel {
+			target -= uint32(baseAddr + addr[r.Section] + r.Offset)
+		}
+		patch(contents[r.Section], r.Offset, target)
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, etExec, uint32(baseAddr+addr[entry.Section]+entry.Value), ehdrSize, 0, phnum, 0, 0)
+	writePhdr(&buf, 0, baseAddr, textEnd, textEnd, 5) // R+X
+	if len(data) > 0 {
+		writePhdr(&buf, dataStart, baseAddr+dataStart, fileEnd-dataStart, memEnd-dataStart, 6) // R+W
+	}
+	for _, s := range secs {
+		if s.IsBss() {
+			continue
+		}
+		buf.Write(make([]byte, addr[s.Name]-buf.Len()))
+		buf.Write(contents[s.Name])
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteObject 生成可重定位目标文件：节内标签为局部符号，global 声明和未定义的符号（extern 或 build link 引用的符号）为全局符号
+func WriteObject(obj *asm.Object) ([]byte, error) {
+	secs, err := sections(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	// 节头索引：0 为空，随后是各个节，再是重定位节和符号表、字符串表
+	secIndex := map[string]int{}
+	for i, s := range secs {
+		secIndex[s.Name] = i + 1
+	}
+
+	// 符号表：空符号、节符号、局部标签、全局符号
+	strtab := newStrtab()
+	var symtab bytes.Buffer
+	symtab.Write(make([]byte, symSize))
+	symIndex := map[string]int{}
+	count := 1
+	for _, s := range secs {
+		writeSym(&symtab, 0, 0, 3, secIndex[s.Name]) // STB_LOCAL, STT_SECTION
+		count++
+	}
+	var globals []string
+	for _, name := range obj.Order {
+		if obj.Globals[name] {
+			globals = append(globals, name)
+			continue
+		}
+		sym := obj.Symbols[name]
+		writeSym(&symtab, strtab.add(name), uint32(sym.Value), 0, secIndex[sym.Section])
+		symIndex[name] = count
+		count++
+	}
+	firstGlobal := count
+	undefined := obj.Undefined()
+	for _, name := range obj.Externs {
+		if obj.Symbols[name] == nil && !contains(undefined, name) {
+			undefined = append(undefined, name)
+		}
+	}
+	sort.Strings(undefined)
+	for _, name := range append(globals, undefined...) {
+		value, shndx := uint32(0), 0
+		if sym := obj.Symbols[name]; sym != nil {
+			value, shndx = uint32(sym.Value), secIndex[sym.Section]
+		}
+		writeSym(&symtab, strtab.add(name), value, 1<<4, shndx) // STB_GLOBAL, STT_NOTYPE
+		symIndex[name] = count
+		count++
+	}
+
+	// 重定位：局部标签改为引用所在的节符号，偏移并入加数
+	contents := map[string][]byte{}
+	rels := map[string]*bytes.Buffer{}
+	for _, s := range secs {
+		contents[s.Name] = append([]byte(nil), s.Data...)
+	}
+	for _, r := range obj.Relocs {
+		index, ok := symIndex[r.Symbol]
+		sym := obj.Symbols[r.Symbol]
+		if sym != nil && !obj.Globals[r.Symbol] {
+			index = secIndex[sym.Section]
+			patch(contents[r.Section], r.Offset, uint32(sym.Value))
+		} else if !ok {
+			return nil, fmt.Errorf("编译器内部错误: 符号 %s 不在符号表中", r.Symbol)
+		}
+		typ := uint32(r386_32)
+		if r.PCRel {
+			typ = r386PC
+		}
+		if rels[r.Section] == nil {
+			rels[r.Section] = &bytes.Buffer{}
+		}
+		binary.Write(rels[r.Section], binary.LittleEndian, [2]uint32{uint32(r.Offset), uint32(index)<<8 | typ})
+	}
+
+	shstrtab := newStrtab()
+	type shdr struct {
+		name                       string
+		typ, flags                 uint32
+		data                       []byte
+		size                       int
+		link, info, align, entsize int
+	}
+	var headers []shdr
+	for _, s := range secs {
+		h := shdr{name: s.Name, typ: shtProgbits, flags: sectionFlags(s.Name), data: contents[s.Name], size: s.Len(), align: s.Align}
+		if s.IsBss() {
+			h.typ, h.data = shtNobits, nil
+		}
+		headers = append(headers, h)
+	}
+	symtabIndex := len(secs) + 1
+	for _, s := range secs {
+		if rels[s.Name] == nil {
+			continue
+		}
+		data := rels[s.Name].Bytes()
+		headers = append(headers, shdr{name: ".rel" + s.Name, typ: shtRel, data: data, size: len(data),
+			link: symtabIndex + countRel(secs, rels), info: secIndex[s.Name], align: 4, entsize: relSize})
+	}
+	headers = append(headers,
+		shdr{name: ".symtab", typ: shtSymtab, data: symtab.Bytes(), size: symtab.Len(), link: len(headers) + 2, info: firstGlobal, align: 4, entsize: symSize},
+		shdr{name: ".strtab", typ: shtStrtab, data: strtab.Bytes(), size: strtab.Len(), align: 1},
+	)
+	for _, h := range headers {
+		shstrtab.add(h.name)
+	}
+	shstrtab.add(".shstrtab")
+	headers = append(headers, shdr{name: ".shstrtab", typ: shtStrtab, data: shstrtab.Bytes(), size: shstrtab.Len(), align: 1})
+
+	// 节内容依次排在 ELF 头之后，节头表放在最后
+	var body bytes.Buffer
+	offsets := make([]int, len(headers))
+	for i, h := range headers {
+		pos := alignUp(ehdrSize+body.Len(), h.align)
+		body.Write(make([]byte, pos-ehdrSize-body.Len()))
+		offsets[i] = pos
+		body.Write(h.data)
+	}
+	shoff := alignUp(ehdrSize+body.Len(), 4)
+	body.Write(make([]byte, shoff-ehdrSize-body.Len()))
+
+	var buf bytes.Buffer
+	writeHeader(&buf, etRel, 0, 0, uint32(shoff), 0, len(headers)+1, len(headers))
+	buf.Write(body.Bytes())
+	buf.Write(make([]byte, shdrSize))
+	for i, h := range headers {
+		binary.Write(&buf, binary.LittleEndian, [10]uint32{
+			uint32(shstrtab.index[h.name]), h.typ, h.flags, 0, uint32(offsets[i]), uint32(h.size),
+			uint32(h.link), uint32(h.info), uint32(h.align), uint32(h.entsize),
+		})
+	}
+	return buf.Bytes(), nil
+}
+
+// countRel 返回重定位节的数量
+func countRel(secs []*asm.Section, rels map[string]*bytes.Buffer) int {
+	n := 0
+	for _, s := range secs {
+		if rels[s.Name] != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// writeHeader 写入 ELF 头
+func writeHeader(buf *bytes.Buffer, typ uint16, entry, phoff, shoff uint32, phnum, shnum, shstrndx int) {
+	buf.Write([]byte{0x7F, 'E', 'L', 'F', 1, 1, 1, 0}) // 32 位、小端、版本 1、System V ABI
+	buf.Write(make([]byte, 8))
+	phentsize := uint16(0)
+	if phnum > 0 {
+		phentsize = phdrSize
+	}
+	shentsize := uint16(0)
+	if shnum > 0 {
+		shentsize = shdrSize
+	}
+	binary.Write(buf, binary.LittleEndian, struct {
+		Type, Machine                uint16
+		Version, Entry, Phoff, Shoff uint32
+		Flags                        uint32
+		Ehsize, Phentsize, Phnum     uint16
+		Shentsize, Shnum, Shstrndx   uint16
+	}{typ, em386, 1, entry, phoff, shoff, 0, ehdrSize, phentsize, uint16(phnum), shentsize, uint16(shnum), uint16(shstrndx)})
+}
+
+// writePhdr 写入 PT_LOAD 程序头
+func writePhdr(buf *bytes.Buffer, offset, vaddr, filesz, memsz int, flags uint32) {
+	binary.Write(buf, binary.LittleEndian, [8]uint32{
+		ptLoad, uint32(offset), uint32(vaddr), uint32(vaddr), uint32(filesz), uint32(memsz), flags, pageSize,
+	})
+}
+
+// writeSym 写入符号表项
+func writeSym(buf *bytes.Buffer, name, value uint32, info byte, shndx int) {
+	binary.Write(buf, binary.LittleEndian, struct {
+		Name, Value, Size uint32
+		Info, Other       byte
+		Shndx             uint16
+	}{name, value, 0, info, 0, uint16(shndx)})
+}
+
+// strtab 字符串表，以空字符串开头
+type strtab struct {
+	bytes.Buffer
+	index map[string]int
+}
+
+func newStrtab() *strtab {
+	t := &strtab{index: map[string]int{}}
+	t.WriteByte(0)
+	t.index[""] = 0
+	return t
+}
+
+// add 添加字符串并返回其偏移，重复的字符串只保存一份
+func (t *strtab) add(s string) uint32 {
+	if i, ok := t.index[s]; ok {
+		return uint32(i)
+	}
+	t.index[s] = t.Len()
+	t.WriteString(s)
+	t.WriteByte(0)
+	return uint32(t.index[s])
+}
@@ -0,0 +1,255 @@
+// Package emu 解释执行后端生成的 NASM 汇编（compile/arch/x86 输出的 32 位 x86 指令子集），
+// int 0x80 系统调用在内存文件系统上模拟，用于在没有 nasm、ld 或 32 位内核接口的机器上运行编译出的程序。
+package emu
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// 进程地址空间的布局：代码标签的地址为 textBase 加指令序号，数据节从 dataBase 起按页依次存放
+const (
+	textBase  = 0x08048000
+	dataBase  = 0x08100000
+	mmapBase  = 0x40000000
+	stackTop  = 0xC0000000
+	stackSize = 8 << 20
+	pageSize  = 4096
+)
+
+// DefaultMaxSteps 默认最多执行的指令数，防止死循环的程序让测试挂起
+const DefaultMaxSteps = 50_000_000
+
+// Machine 模拟执行的 32 位 x86 进程
+type Machine struct {
+	FS       map[string][]byte // 内存文件系统：路径到文件内容，open 在这里查找或创建文件
+	Stdin    []byte            // 标准输入的内容
+	Stdout   bytes.Buffer      // 写到 fd 1 的内容
+	Stderr   bytes.Buffer      // 写到 fd 2 的内容
+	MaxSteps int               // 最多执行的指令数，为 0 时使用 DefaultMaxSteps
+	Steps    int               // Run 已执行的指令数
+
+	prog   *program
+	labels map[string]uint32 // 标签地址
+	entry  uint32
+
+	regs  [8]uint32
+	eip   uint32
+	flags flags
+	pages map[uint32][]byte // 已映射的页，按页号索引
+
+	brk, brkBase uint32
+	mmapNext     uint32
+	files        map[int]*file // 打开的文件
+	stdinPos     int
+
+	exited   bool
+	exitCode int
+}
+
+// flags 条件码
+type flags struct {
+	cf, zf, sf, of, pf bool
+}
+
+// New 解析并装载汇编源码，入口为 _start
+func New(src string) (*Machine, error) {
+	prog, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	m := &Machine{
+		FS:     map[string][]byte{},
+		prog:   prog,
+		labels: map[string]uint32{},
+		pages:  map[uint32][]byte{},
+		files:  map[int]*file{},
+	}
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Run 解释执行汇编源码，返回退出码和写到标准输出的内容
+func Run(src string) (exitCode int, stdout []byte, err error) {
+	m, err := New(src)
+	if err != nil {
+		return 0, nil, err
+	}
+	exitCode, err = m.Run()
+	return exitCode, m.Stdout.Bytes(), err
+}
+
+// load 为标签分配地址，把数据节写入内存并建立初始栈
+func (m *Machine) load() error {
+	addr := uint32(dataBase)
+	for _, s := range m.prog.sections {
+		s.addr = addr
+		addr += alignUp(uint32(s.len()), pageSize)
+	}
+	for name, l := range m.prog.labels {
+		if l.sec == nil {
+			m.labels[name] = textBase + uint32(l.offset)
+		} else {
+			m.labels[name] = l.sec.addr + uint32(l.offset)
+		}
+	}
+	for _, f := range m.prog.fixups {
+		v, ok := m.labels[f.sym]
+		if !ok {
+			return fmt.Errorf("未定义的标签 %s", f.sym)
+		}
+		v += f.addend
+		f.sec.data[f.offset], f.sec.data[f.offset+1], f.sec.data[f.offset+2], f.sec.data[f.offset+3] = byte(v), byte(v>>8), byte(v>>16), byte(v>>24)
+	}
+	for i := range m.prog.text {
+		for k := range m.prog.text[i].args {
+			op := &m.prog.text[i].args[k]
+			if op.sym == "" {
+				continue
+			}
+			v, ok := m.labels[op.sym]
+			if !ok {
+				return fmt.Errorf("第 %d 行 %q: 未定义的标签 %s", m.prog.text[i].line, m.prog.text[i].text, op.sym)
+			}
+			op.value += v
+		}
+	}
+	for _, s := range m.prog.sections {
+		m.mapRange(s.addr, uint32(s.len()))
+		if err := m.writeBytes(s.addr, s.data); err != nil {
+			return err
+		}
+	}
+	m.brkBase, m.brk = addr, addr
+	m.mmapNext = mmapBase
+
+	entry, ok := m.labels["_start"]
+	if !ok {
+		return fmt.Errorf("缺少入口 _start")
+	}
+	m.entry, m.eip = entry, entry
+	// 与 Linux 进程的初始栈一致：argc 为 0，argv 与 envp 为空
+	m.regs[esp] = stackTop - 16
+	return nil
+}
+
+// Run 从入口开始执行到进程退出，返回退出码；执行出错（如访问未映射的内存、除零）时返回错误
+func (m *Machine) Run() (int, error) {
+	limit := m.MaxSteps
+	if limit == 0 {
+		limit = DefaultMaxSteps
+	}
+	for ; !m.exited; m.Steps++ {
+		if m.Steps >= limit {
+			return 0, fmt.Errorf("超过 %d 条指令仍未退出", limit)
+		}
+		idx := m.eip - textBase
+		if m.eip < textBase || int(idx) >= len(m.prog.text) {
+			return 0, fmt.Errorf("跳转到无效的地址 %#x", m.eip)
+		}
+		in := &m.prog.text[idx]
+		m.eip++
+		if err := m.exec(in); err != nil {
+			return 0, fmt.Errorf("第 %d 行 %q: %v", in.line, in.text, err)
+		}
+	}
+	return m.exitCode, nil
+}
+
+// Reg 返回 32 位寄存器的值，name 如 "eax"
+func (m *Machine) Reg(name string) uint32 {
+	r, ok := registers[name]
+	if !ok || r.size != 4 {
+		panic("emu: 无效的寄存器 " + name)
+	}
+	return m.regs[r.num]
+}
+
+// page 返回地址所在的页，栈区的页在首次访问时映射
+func (m *Machine) page(addr uint32) ([]byte, error) {
+	if p, ok := m.pages[addr/pageSize]; ok {
+		return p, nil
+	}
+	if addr < stackTop && addr >= stackTop-stackSize {
+		p := make([]byte, pageSize)
+		m.pages[addr/pageSize] = p
+		return p, nil
+	}
+	return nil, fmt.Errorf("访问未映射的地址 %#x", addr)
+}
+
+// mapRange 映射 [addr, addr+n) 所在的页
+func (m *Machine) mapRange(addr, n uint32) {
+	for p := addr / pageSize; p*pageSize < addr+n; p++ {
+		if m.pages[p] == nil {
+			m.pages[p] = make([]byte, pageSize)
+		}
+	}
+}
+
+// read 按小端序读取 size 字节
+func (m *Machine) read(addr uint32, size int) (uint32, error) {
+	var v uint32
+	for k := 0; k < size; k++ {
+		p, err := m.page(addr + uint32(k))
+		if err != nil {
+			return 0, err
+		}
+		v |= uint32(p[(addr+uint32(k))%pageSize]) << (8 * k)
+	}
+	return v, nil
+}
+
+// write 按小端序写入 size 字节
+func (m *Machine) write(addr uint32, size int, v uint32) error {
+	for k := 0; k < size; k++ {
+		p, err := m.page(addr + uint32(k))
+		if err != nil {
+			return err
+		}
+		p[(addr+uint32(k))%pageSize] = byte(v >> (8 * k))
+	}
+	return nil
+}
+
+func (m *Machine) readBytes(addr, n uint32) ([]byte, error) {
+	buf := make([]byte, n)
+	for k := uint32(0); k < n; k++ {
+		v, err := m.read(addr+k, 1)
+		if err != nil {
+			return nil, err
+		}
+		buf[k] = byte(v)
+	}
+	return buf, nil
+}
+
+func (m *Machine) writeBytes(addr uint32, data []byte) error {
+	for k, b := range data {
+		if err := m.write(addr+uint32(k), 1, uint32(b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readString 读取以 0 结尾的字符串
+func (m *Machine) readString(addr uint32) (string, error) {
+	var buf []byte
+	for {
+		v, err := m.read(addr+uint32(len(buf)), 1)
+		if err != nil {
+			return "", err
+		}
+		if v == 0 {
+			return string(buf), nil
+		}
+		buf = append(buf, byte(v))
+	}
+}
+
+func alignUp(v, align uint32) uint32 {
+	return (v + align - 1) &^ (align - 1)
+}
@@ -0,0 +1,196 @@
+package emu
+
+import (
+	"strings"
+	"testing"
+)
+
+// source 拼出以 _start 为入口的程序，body 之后以 EAX 为退出码退出
+func source(body string, data ...string) string {
+	src := "section .text\nglobal _start\n_start:\n" + body + "\n    mov ebx, eax\n    mov eax, 1\n    int 0x80\n"
+	if len(data) > 0 {
+		src += strings.Join(data, "\n") + "\n"
+	}
+	return src
+}
+
+func TestExitCode(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"mov", "mov eax, 42", 42},
+		{"truncated", "mov eax, 300", 44},
+		{"call", "push 7\n push 5\n call sub\n add esp, 8\n jmp done\nsub:\n push ebp\n mov ebp, esp\n mov eax, DWORD[ebp+8]\n sub eax, DWORD[ebp+12]\n leave\n ret\ndone:", 254},
+		{"stdcall", "push 3\n push 4\n call mul\n jmp done\nmul:\n mov eax, DWORD[esp+4]\n imul eax, DWORD[esp+8]\n ret 8\ndone:", 12},
+		{"idiv", "mov eax, -17\n cdq\n mov ecx, 5\n idiv ecx\n imul eax, 10\n add eax, edx\n add eax, 100", 68},
+		{"div", "mov eax, 17\n xor edx, edx\n mov ecx, 5\n div ecx\n imul edx, 10\n add eax, edx", 23},
+		{"signed", "mov eax, -1\n cmp eax, 1\n jl yes\n mov eax, 0\n jmp done\nyes:\n mov eax, 1\ndone:", 1},
+		{"unsigned", "mov eax, -1\n cmp eax, 1\n jb yes\n mov eax, 0\n jmp done\nyes:\n mov eax, 1\ndone:", 0},
+		{"setcc", "mov ecx, 3\n cmp ecx, 3\n sete al\n movzx eax, al", 1},
+		{"extend", "mov ecx, 0x1F0\n movsx eax, cl\n neg eax", 16},
+		{"high byte", "mov eax, 0x1234\n mov al, ah\n and eax, 0xFF", 0x12},
+		{"shift", "mov eax, -64\n sar eax, 3\n neg eax\n mov cl, 2\n shl eax, cl", 32},
+		{"loop", "mov eax, 0\n mov ecx, 10\nagain:\n add eax, ecx\n dec ecx\n jnz again", 55},
+		{"table", "mov eax, 2\n jmp [g_table+eax*4]\nc0:\n mov eax, 10\n jmp done\nc1:\n mov eax, 11\n jmp done\nc2:\n mov eax, 12\ndone:", 12},
+		{"data", "mov eax, DWORD[g_x]\n add eax, DWORD[g_y]\n mov DWORD[g_z], eax\n mov eax, DWORD[g_z]", 9},
+	}
+	data := []string{
+		"section .data", "align 4", "g_x: dd 4", "g_y: dd 5",
+		"section .bss", "alignb 4", "g_z: resb 4",
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			src := source(c.body, data...)
+			if strings.Contains(c.body, "g_table") {
+				src += "section .rodata\ng_table: dd c0, c1, c2\n"
+			}
+			got, _, err := Run(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("退出码为 %d，应为 %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	src := source("mov eax, 4\n mov ebx, 1\n mov ecx, msg\n mov edx, 6\n int 0x80\n"+
+		"mov eax, 4\n mov ebx, 2\n mov ecx, msg\n mov edx, 2\n int 0x80\n mov eax, 0",
+		"section .rodata", `msg: db "hello", 10, 0`)
+	m, err := New(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Stdout.String(); got != "hello\n" {
+		t.Errorf("stdout 为 %q", got)
+	}
+	if got := m.Stderr.String(); got != "he" {
+		t.Errorf("stderr 为 %q", got)
+	}
+}
+
+func TestFileSystem(t *testing.T) {
+	// 打开已有的 in.txt 读出内容写入新建的 out.txt，再打开不存在的文件，以其错误码为退出码
+	src := source(`
+    mov eax, 5
+    mov ebx, in_path
+    mov ecx, 0
+    int 0x80
+    mov esi, eax
+    mov eax, 3
+    mov ebx, esi
+    mov ecx, buf
+    mov edx, 64
+    int 0x80
+    mov edi, eax
+    mov eax, 6
+    mov ebx, esi
+    int 0x80
+    mov eax, 5
+    mov ebx, out_path
+    mov ecx, 0x242
+    mov edx, 420
+    int 0x80
+    mov esi, eax
+    mov eax, 4
+    mov ebx, esi
+    mov ecx, buf
+    mov edx, edi
+    int 0x80
+    mov eax, 6
+    mov ebx, esi
+    int 0x80
+    mov eax, 5
+    mov ebx, missing
+    mov ecx, 0
+    int 0x80
+    neg eax`,
+		"section .rodata", `in_path: db "in.txt", 0`, `out_path: db "out.txt", 0`, `missing: db "none", 0`,
+		"section .bss", "buf: resb 64")
+	m, err := New(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.FS["in.txt"] = []byte("cute")
+	code, err := m.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(m.FS["out.txt"]); got != "cute" {
+		t.Errorf("out.txt 的内容为 %q", got)
+	}
+	if code != errNOENT {
+		t.Errorf("打开不存在的文件返回 %d，应为 -%d", -code, errNOENT)
+	}
+}
+
+func TestMmap(t *testing.T) {
+	// mmap2 分配匿名页并读写，munmap 之后再访问应出错
+	src := source(`
+    mov eax, 192
+    mov ebx, 0
+    mov ecx, 8192
+    mov edx, 3
+    mov esi, 0x22
+    mov edi, -1
+    mov ebp, 0
+    int 0x80
+    mov ebx, eax
+    mov DWORD[ebx+4096], 77
+    mov eax, DWORD[ebx+4096]
+    push eax
+    mov eax, 91
+    mov ecx, 8192
+    int 0x80
+    pop eax
+    mov ecx, DWORD[ebx]`)
+	_, _, err := Run(src)
+	if err == nil || !strings.Contains(err.Error(), "未映射") {
+		t.Errorf("munmap 之后访问应出错，得到 %v", err)
+	}
+	code, _, err := Run(strings.Replace(src, "mov ecx, DWORD[ebx]", "", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != 77 {
+		t.Errorf("退出码为 %d，应为 77", code)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"no entry", "section .text\nmain:\n ret\n", "_start"},
+		{"undefined", source("call missing"), "missing"},
+		{"divide", source("mov eax, 1\n xor edx, edx\n mov ecx, 0\n idiv ecx"), "除零"},
+		{"bad jump", source("push 0\n ret"), "无效的地址"},
+		{"unsupported", source("cpuid"), "cpuid"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, _, err := Run(c.src)
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("错误为 %v，应包含 %q", err, c.want)
+			}
+		})
+	}
+
+	m, err := New(source("spin:\n jmp spin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.MaxSteps = 1000
+	if _, err := m.Run(); err == nil {
+		t.Error("死循环应在超过指令数上限后出错")
+	}
+}
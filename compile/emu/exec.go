@@ -0,0 +1,510 @@
+package emu
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// conditions 条件码对应的判断，用于 jcc/setcc/cmovcc
+var conditions = map[string]func(f flags) bool{
+	"o": func(f flags) bool { return f.of }, "no": func(f flags) bool { return !f.of },
+	"b": func(f flags) bool { return f.cf }, "ae": func(f flags) bool { return !f.cf },
+	"e": func(f flags) bool { return f.zf }, "ne": func(f flags) bool { return !f.zf },
+	"be": func(f flags) bool { return f.cf || f.zf }, "a": func(f flags) bool { return !f.cf && !f.zf },
+	"s": func(f flags) bool { return f.sf }, "ns": func(f flags) bool { return !f.sf },
+	"p": func(f flags) bool { return f.pf }, "np": func(f flags) bool { return !f.pf },
+	"l": func(f flags) bool { return f.sf != f.of }, "ge": func(f flags) bool { return f.sf == f.of },
+	"le": func(f flags) bool { return f.zf || f.sf != f.of }, "g": func(f flags) bool { return !f.zf && f.sf == f.of },
+}
+
+// conditionAliases 条件码的别名
+var conditionAliases = map[string]string{
+	"c": "b", "nae": "b", "nb": "ae", "nc": "ae", "z": "e", "nz": "ne", "na": "be", "nbe": "a",
+	"pe": "p", "po": "np", "nge": "l", "nl": "ge", "ng": "le", "nle": "g",
+}
+
+// condition 返回条件码的判断，不是条件码时返回 nil
+func condition(cc string) func(f flags) bool {
+	if alias, ok := conditionAliases[cc]; ok {
+		cc = alias
+	}
+	return conditions[cc]
+}
+
+func mask(size int) uint32 {
+	return uint32(1<<(8*size) - 1)
+}
+
+func signBit(size int) uint32 {
+	return 1 << (8*size - 1)
+}
+
+// signExtend 将 size 字节的值符号扩展为 32 位
+func signExtend(v uint32, size int) int32 {
+	shift := 32 - 8*size
+	return int32(v<<shift) >> shift
+}
+
+func (m *Machine) getReg(num, size int) uint32 {
+	switch size {
+	case 1:
+		if num >= 4 {
+			return m.regs[num-4] >> 8 & 0xFF
+		}
+		return m.regs[num] & 0xFF
+	case 2:
+		return m.regs[num] & 0xFFFF
+	}
+	return m.regs[num]
+}
+
+func (m *Machine) setReg(num, size int, v uint32) {
+	switch size {
+	case 1:
+		if num >= 4 {
+			m.regs[num-4] = m.regs[num-4]&^0xFF00 | (v&0xFF)<<8
+			return
+		}
+		m.regs[num] = m.regs[num]&^0xFF | v&0xFF
+	case 2:
+		m.regs[num] = m.regs[num]&^0xFFFF | v&0xFFFF
+	default:
+		m.regs[num] = v
+	}
+}
+
+// addr 计算内存操作数的地址
+func (m *Machine) addr(op operand) uint32 {
+	a := op.value
+	if op.base >= 0 {
+		a += m.regs[op.base]
+	}
+	if op.index >= 0 {
+		a += m.regs[op.index] * uint32(op.scale)
+	}
+	return a
+}
+
+// get 读取 size 字节宽的操作数
+func (m *Machine) get(op operand, size int) (uint32, error) {
+	switch op.kind {
+	case opReg:
+		return m.getReg(op.reg, op.size), nil
+	case opMem:
+		return m.read(m.addr(op), size)
+	}
+	return op.value & mask(size), nil
+}
+
+// set 写入 size 字节宽的操作数
+func (m *Machine) set(op operand, size int, v uint32) error {
+	switch op.kind {
+	case opReg:
+		m.setReg(op.reg, op.size, v)
+		return nil
+	case opMem:
+		return m.write(m.addr(op), size, v)
+	}
+	return fmt.Errorf("不能写入立即数")
+}
+
+// operandSize 推断指令的操作宽度：寄存器或带长度前缀的内存操作数
+func operandSize(ops ...operand) (int, error) {
+	size := 0
+	for _, op := range ops {
+		if op.kind == opImm || op.size == 0 {
+			continue
+		}
+		if size != 0 && size != op.size {
+			return 0, fmt.Errorf("操作数宽度不一致")
+		}
+		size = op.size
+	}
+	if size == 0 {
+		return 0, fmt.Errorf("无法确定操作数宽度")
+	}
+	return size, nil
+}
+
+func (m *Machine) push(v uint32) error {
+	m.regs[esp] -= 4
+	return m.write(m.regs[esp], 4, v)
+}
+
+func (m *Machine) pop() (uint32, error) {
+	v, err := m.read(m.regs[esp], 4)
+	m.regs[esp] += 4
+	return v, err
+}
+
+// setResult 按结果设置 ZF、SF、PF
+func (m *Machine) setResult(r uint32, size int) {
+	r &= mask(size)
+	m.flags.zf = r == 0
+	m.flags.sf = r&signBit(size) != 0
+	m.flags.pf = bits.OnesCount8(uint8(r))%2 == 0
+}
+
+// arith 执行双操作数算术指令并设置条件码
+func (m *Machine) arith(op string, a, b uint32, size int) uint32 {
+	mk, sign := mask(size), signBit(size)
+	var r uint32
+	switch op {
+	case "add", "adc":
+		carry := uint32(0)
+		if op == "adc" && m.flags.cf {
+			carry = 1
+		}
+		r = (a + b + carry) & mk
+		m.flags.cf = uint64(a)+uint64(b)+uint64(carry) > uint64(mk)
+		m.flags.of = (a^r)&(b^r)&sign != 0
+	case "sub", "sbb", "cmp":
+		borrow := uint32(0)
+		if op == "sbb" && m.flags.cf {
+			borrow = 1
+		}
+		r = (a - b - borrow) & mk
+		m.flags.cf = uint64(a) < uint64(b)+uint64(borrow)
+		m.flags.of = (a^b)&(a^r)&sign != 0
+	case "and", "test":
+		r = a & b
+		m.flags.cf, m.flags.of = false, false
+	case "or":
+		r = a | b
+		m.flags.cf, m.flags.of = false, false
+	case "xor":
+		r = a ^ b
+		m.flags.cf, m.flags.of = false, false
+	}
+	m.setResult(r, size)
+	return r
+}
+
+// shift 执行移位与循环移位，count 已按 31 取模且不为 0
+func (m *Machine) shift(op string, v, count uint32, size int) uint32 {
+	mk, sign, width := mask(size), signBit(size), uint32(8*size)
+	var r uint32
+	switch op {
+	case "shl", "sal":
+		r = v << count & mk
+		m.flags.cf = count <= width && v>>(width-count)&1 != 0
+		m.flags.of = (r&sign != 0) != m.flags.cf
+	case "shr":
+		r = v >> count
+		m.flags.cf = v>>(count-1)&1 != 0
+		m.flags.of = v&sign != 0
+	case "sar":
+		r = uint32(signExtend(v, size)>>count) & mk
+		m.flags.cf = uint32(signExtend(v, size)>>(count-1))&1 != 0
+		m.flags.of = false
+	case "rol":
+		count %= width
+		r = (v<<count | v>>(width-count)) & mk
+		m.flags.cf = r&1 != 0
+		m.flags.of = (r&sign != 0) != m.flags.cf
+		return r
+	case "ror":
+		count %= width
+		r = (v>>count | v<<(width-count)) & mk
+		m.flags.cf = r&sign != 0
+		m.flags.of = (r&sign != 0) != (r&(sign>>1) != 0)
+		return r
+	}
+	m.setResult(r, size)
+	return r
+}
+
+// exec 执行一条指令
+func (m *Machine) exec(in *inst) error {
+	args := in.args
+	switch in.op {
+	case "nop":
+		return nil
+	case "mov":
+		if len(args) != 2 {
+			return fmt.Errorf("mov 需要两个操作数")
+		}
+		size, err := operandSize(args...)
+		if err != nil {
+			return err
+		}
+		v, err := m.get(args[1], size)
+		if err != nil {
+			return err
+		}
+		return m.set(args[0], size, v)
+	case "movzx", "movsx":
+		if len(args) != 2 || args[0].kind != opReg || args[1].kind == opImm || args[1].size == 0 {
+			return fmt.Errorf("%s 的操作数无效", in.op)
+		}
+		v, err := m.get(args[1], args[1].size)
+		if err != nil {
+			return err
+		}
+		if in.op == "movsx" {
+			v = uint32(signExtend(v, args[1].size))
+		}
+		m.setReg(args[0].reg, args[0].size, v)
+		return nil
+	case "lea":
+		if len(args) != 2 || args[0].kind != opReg || args[1].kind != opMem {
+			return fmt.Errorf("lea 的操作数无效")
+		}
+		m.setReg(args[0].reg, args[0].size, m.addr(args[1]))
+		return nil
+	case "add", "adc", "sub", "sbb", "and", "or", "xor", "cmp", "test":
+		if len(args) != 2 {
+			return fmt.Errorf("%s 需要两个操作数", in.op)
+		}
+		size, err := operandSize(args...)
+		if err != nil {
+			return err
+		}
+		a, err := m.get(args[0], size)
+		if err != nil {
+			return err
+		}
+		b, err := m.get(args[1], size)
+		if err != nil {
+			return err
+		}
+		r := m.arith(in.op, a, b, size)
+		if in.op == "cmp" || in.op == "test" {
+			return nil
+		}
+		return m.set(args[0], size, r)
+	case "inc", "dec", "neg", "not":
+		if len(args) != 1 {
+			return fmt.Errorf("%s 需要一个操作数", in.op)
+		}
+		size, err := operandSize(args...)
+		if err != nil {
+			return err
+		}
+		v, err := m.get(args[0], size)
+		if err != nil {
+			return err
+		}
+		switch in.op {
+		case "inc", "dec":
+			cf := m.flags.cf
+			if in.op == "inc" {
+				v = m.arith("add", v, 1, size)
+			} else {
+				v = m.arith("sub", v, 1, size)
+			}
+			m.flags.cf = cf
+		case "neg":
+			v = m.arith("sub", 0, v, size)
+			m.flags.cf = v != 0
+		case "not":
+			v = ^v & mask(size)
+		}
+		return m.set(args[0], size, v)
+	case "shl", "sal", "shr", "sar", "rol", "ror":
+		if len(args) != 2 || args[1].kind == opMem || args[1].kind == opReg && (args[1].reg != ecx || args[1].size != 1) {
+			return fmt.Errorf("%s 的移位次数只能是立即数或 CL", in.op)
+		}
+		size, err := operandSize(args[0])
+		if err != nil {
+			return err
+		}
+		v, err := m.get(args[0], size)
+		if err != nil {
+			return err
+		}
+		count, _ := m.get(args[1], 1)
+		if count &= 31; count == 0 {
+			return nil
+		}
+		return m.set(args[0], size, m.shift(in.op, v, count, size))
+	case "imul":
+		return m.imul(args)
+	case "mul", "div", "idiv":
+		return m.mulDiv(in.op, args)
+	case "cdq":
+		m.regs[edx] = uint32(int32(m.regs[eax]) >> 31)
+		return nil
+	case "push":
+		if len(args) != 1 {
+			return fmt.Errorf("push 需要一个操作数")
+		}
+		v, err := m.get(args[0], 4)
+		if err != nil {
+			return err
+		}
+		return m.push(v)
+	case "pop":
+		if len(args) != 1 || args[0].kind == opImm {
+			return fmt.Errorf("pop 的操作数无效")
+		}
+		v, err := m.pop()
+		if err != nil {
+			return err
+		}
+		return m.set(args[0], 4, v)
+	case "leave":
+		m.regs[esp] = m.regs[ebp]
+		v, err := m.pop()
+		m.regs[ebp] = v
+		return err
+	case "jmp", "call":
+		if len(args) != 1 {
+			return fmt.Errorf("%s 需要一个操作数", in.op)
+		}
+		target, err := m.get(args[0], 4)
+		if err != nil {
+			return err
+		}
+		if in.op == "call" {
+			if err := m.push(m.eip); err != nil {
+				return err
+			}
+		}
+		m.eip = target
+		return nil
+	case "ret":
+		ip, err := m.pop()
+		if err != nil {
+			return err
+		}
+		if len(args) == 1 {
+			m.regs[esp] += args[0].value
+		}
+		m.eip = ip
+		return nil
+	case "int":
+		if len(args) != 1 || args[0].kind != opImm || args[0].value != 0x80 {
+			return fmt.Errorf("只支持 int 0x80")
+		}
+		return m.syscall()
+	case "hlt", "int3":
+		return fmt.Errorf("执行了 %s", in.op)
+	}
+
+	if cond := condition(strings.TrimPrefix(in.op, "j")); in.op[0] == 'j' && cond != nil {
+		if len(args) != 1 || args[0].kind != opImm {
+			return fmt.Errorf("%s 需要跳转目标", in.op)
+		}
+		if cond(m.flags) {
+			m.eip = args[0].value
+		}
+		return nil
+	}
+	if cond := condition(strings.TrimPrefix(in.op, "set")); strings.HasPrefix(in.op, "set") && cond != nil {
+		if len(args) != 1 || args[0].kind == opImm || args[0].size == 2 || args[0].size == 4 {
+			return fmt.Errorf("%s 需要 8 位操作数", in.op)
+		}
+		v := uint32(0)
+		if cond(m.flags) {
+			v = 1
+		}
+		return m.set(args[0], 1, v)
+	}
+	if cond := condition(strings.TrimPrefix(in.op, "cmov")); strings.HasPrefix(in.op, "cmov") && cond != nil {
+		if len(args) != 2 || args[0].kind != opReg || args[0].size != 4 || args[1].kind == opImm {
+			return fmt.Errorf("%s 的操作数无效", in.op)
+		}
+		v, err := m.get(args[1], 4)
+		if err != nil || !cond(m.flags) {
+			return err
+		}
+		m.regs[args[0].reg] = v
+		return nil
+	}
+	return fmt.Errorf("不支持的指令 %s", in.op)
+}
+
+// imul 有符号乘法：单操作数形式结果放在 EDX:EAX（8 位为 AX），两、三操作数形式截断到目标寄存器
+func (m *Machine) imul(args []operand) error {
+	if len(args) == 1 {
+		return m.mulDiv("imul", args)
+	}
+	if len(args) > 3 || args[0].kind != opReg || args[0].size == 1 {
+		return fmt.Errorf("imul 的操作数无效")
+	}
+	size := args[0].size
+	a, b := args[0], args[1]
+	if len(args) == 3 {
+		a, b = args[1], args[2]
+	}
+	x, err := m.get(a, size)
+	if err != nil {
+		return err
+	}
+	y, err := m.get(b, size)
+	if err != nil {
+		return err
+	}
+	full := int64(signExtend(x, size)) * int64(signExtend(y, size))
+	r := uint32(full) & mask(size)
+	m.flags.cf = int64(signExtend(r, size)) != full
+	m.flags.of = m.flags.cf
+	m.setResult(r, size)
+	m.setReg(args[0].reg, size, r)
+	return nil
+}
+
+// mulDiv 单操作数的乘除法，被乘数与被除数为 AL/AX/EAX 与其高位扩展 AH/DX/EDX
+func (m *Machine) mulDiv(op string, args []operand) error {
+	if len(args) != 1 || args[0].kind == opImm {
+		return fmt.Errorf("%s 的操作数无效", op)
+	}
+	size, err := operandSize(args...)
+	if err != nil {
+		return err
+	}
+	src, err := m.get(args[0], size)
+	if err != nil {
+		return err
+	}
+	// 低半部分与高半部分：8 位为 AL 与 AH，其余为 AX/EAX 与 DX/EDX
+	lo, hi := m.getReg(eax, size), m.getReg(edx, size)
+	if size == 1 {
+		hi = m.getReg(4, 1)
+	}
+	setPair := func(l, h uint32) {
+		if size == 1 {
+			m.setReg(eax, 2, l&0xFF|(h&0xFF)<<8)
+			return
+		}
+		m.setReg(eax, size, l)
+		m.setReg(edx, size, h)
+	}
+	width := uint(8 * size)
+	switch op {
+	case "mul":
+		full := uint64(lo) * uint64(src)
+		setPair(uint32(full), uint32(full>>width))
+		m.flags.cf = full>>width != 0
+		m.flags.of = m.flags.cf
+	case "imul":
+		full := int64(signExtend(lo, size)) * int64(signExtend(src, size))
+		setPair(uint32(full), uint32(full>>width))
+		m.flags.cf = full != int64(signExtend(uint32(full)&mask(size), size))
+		m.flags.of = m.flags.cf
+	case "div":
+		if src == 0 {
+			return fmt.Errorf("除零")
+		}
+		dividend := uint64(hi)<<width | uint64(lo)
+		q := dividend / uint64(src)
+		if q > uint64(mask(size)) {
+			return fmt.Errorf("除法溢出")
+		}
+		setPair(uint32(q), uint32(dividend%uint64(src)))
+	case "idiv":
+		if src == 0 {
+			return fmt.Errorf("除零")
+		}
+		dividend := int64(uint64(hi)<<width|uint64(lo)) << (64 - 2*width) >> (64 - 2*width)
+		divisor := int64(signExtend(src, size))
+		q := dividend / divisor
+		if q != int64(signExtend(uint32(q)&mask(size), size)) {
+			return fmt.Errorf("除法溢出")
+		}
+		setPair(uint32(q), uint32(dividend%divisor))
+	}
+	return nil
+}
@@ -0,0 +1,470 @@
+package emu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 操作数种类
+const (
+	opReg = iota // 寄存器
+	opMem        // 内存
+	opImm        // 立即数或标签
+)
+
+// operand 解析后的操作数，标签在装载时解析为地址并计入 value
+type operand struct {
+	kind  int
+	reg   int    // 寄存器编号，8 位的 AH~BH 为 4~7
+	size  int    // 宽度（字节），内存操作数没有长度前缀时为 0
+	base  int    // 内存操作数的基址寄存器，没有时为 -1
+	index int    // 内存操作数的变址寄存器，没有时为 -1
+	scale int    // 变址比例
+	value uint32 // 立即数或内存偏移
+	sym   string // 立即数或内存偏移中引用的标签
+}
+
+// inst 一条指令
+type inst struct {
+	op   string
+	args []operand
+	line int    // 源码行号
+	text string // 源码文本，出错时报告
+}
+
+// 寄存器编号
+const (
+	eax = iota
+	ecx
+	edx
+	ebx
+	esp
+	ebp
+	esi
+	edi
+)
+
+type register struct {
+	num  int
+	size int
+}
+
+var registers = map[string]register{
+	"eax": {eax, 4}, "ecx": {ecx, 4}, "edx": {edx, 4}, "ebx": {ebx, 4},
+	"esp": {esp, 4}, "ebp": {ebp, 4}, "esi": {esi, 4}, "edi": {edi, 4},
+	"ax": {eax, 2}, "cx": {ecx, 2}, "dx": {edx, 2}, "bx": {ebx, 2},
+	"sp": {esp, 2}, "bp": {ebp, 2}, "si": {esi, 2}, "di": {edi, 2},
+	"al": {0, 1}, "cl": {1, 1}, "dl": {2, 1}, "bl": {3, 1},
+	"ah": {4, 1}, "ch": {5, 1}, "dh": {6, 1}, "bh": {7, 1},
+}
+
+var sizePrefixes = map[string]int{"byte": 1, "word": 2, "dword": 4}
+
+// section 数据节，装载时放在各自的页上
+type section struct {
+	name string
+	data []byte
+	size int // .bss 的大小
+	addr uint32
+}
+
+func (s *section) len() int {
+	if s.name == ".bss" {
+		return s.size
+	}
+	return len(s.data)
+}
+
+// label 标签的定义位置：代码标签为指令序号，数据标签为节内偏移
+type label struct {
+	sec    *section // 为 nil 时是代码标签
+	offset int
+}
+
+// fixup 数据中对标签的引用（dd 标签），装载时写入标签地址
+type fixup struct {
+	sec    *section
+	offset int
+	sym    string
+	addend uint32
+}
+
+// program 解析后的程序
+type program struct {
+	text     []inst
+	sections []*section
+	labels   map[string]label
+	fixups   []fixup
+}
+
+// parser 解析过程的状态
+type parser struct {
+	prog *program
+	sec  *section // 当前节，代码节为 nil
+}
+
+// parse 解析 NASM 源码：代码节逐条记录指令，其余节按伪指令生成数据
+func parse(src string) (*program, error) {
+	p := &parser{prog: &program{labels: map[string]label{}}}
+	for i, line := range strings.Split(src, "\n") {
+		if err := p.line(line, i+1); err != nil {
+			return nil, fmt.Errorf("第 %d 行 %q: %v", i+1, strings.TrimSpace(line), err)
+		}
+	}
+	return p.prog, nil
+}
+
+func (p *parser) switchSection(name string) {
+	if name == ".text" {
+		p.sec = nil
+		return
+	}
+	for _, s := range p.prog.sections {
+		if s.name == name {
+			p.sec = s
+			return
+		}
+	}
+	p.sec = &section{name: name}
+	p.prog.sections = append(p.prog.sections, p.sec)
+}
+
+// line 解析一行：可选的标签，后跟伪指令或指令
+func (p *parser) line(line string, num int) error {
+	line = strings.TrimSpace(stripComment(line))
+	if name, rest, ok := splitLabel(line); ok {
+		if _, dup := p.prog.labels[name]; dup {
+			return fmt.Errorf("重复定义的标签 %s", name)
+		}
+		if p.sec == nil {
+			p.prog.labels[name] = label{offset: len(p.prog.text)}
+		} else {
+			p.prog.labels[name] = label{sec: p.sec, offset: p.sec.len()}
+		}
+		line = rest
+	}
+	if line == "" {
+		return nil
+	}
+	mnemonic, rest, _ := strings.Cut(line, " ")
+	mnemonic = strings.ToLower(mnemonic)
+	rest = strings.TrimSpace(rest)
+	switch mnemonic {
+	case "section", "segment":
+		p.switchSection(strings.Fields(rest)[0])
+	case "global", "extern":
+		// 外部符号在调用时才报错
+	case "bits", "[bits":
+		if !strings.HasPrefix(rest, "32") {
+			return fmt.Errorf("只支持 32 位代码")
+		}
+	case "align", "alignb":
+		n, err := parseNumber(rest)
+		if err != nil || n <= 0 || n&(n-1) != 0 {
+			return fmt.Errorf("无效的对齐值 %s", rest)
+		}
+		if p.sec != nil {
+			p.reserve((int(n) - p.sec.len()%int(n)) % int(n))
+		}
+	case "times":
+		count, body, _ := strings.Cut(rest, " ")
+		n, err := parseNumber(count)
+		if err != nil || n < 0 {
+			return fmt.Errorf("无效的重复次数 %s", count)
+		}
+		for k := int64(0); k < n; k++ {
+			if err := p.line(body, num); err != nil {
+				return err
+			}
+		}
+	case "db", "dw", "dd":
+		return p.data(map[string]int{"db": 1, "dw": 2, "dd": 4}[mnemonic], rest)
+	case "resb", "resw", "resd":
+		n, err := parseNumber(rest)
+		if err != nil || n < 0 || p.sec == nil {
+			return fmt.Errorf("无效的大小 %s", rest)
+		}
+		p.reserve(int(n) * map[string]int{"resb": 1, "resw": 2, "resd": 4}[mnemonic])
+	default:
+		if p.sec != nil {
+			return fmt.Errorf("%s 节中不能有指令", p.sec.name)
+		}
+		var args []operand
+		for _, item := range splitOperands(rest) {
+			op, err := parseOperand(item)
+			if err != nil {
+				return err
+			}
+			args = append(args, op)
+		}
+		p.prog.text = append(p.prog.text, inst{op: mnemonic, args: args, line: num, text: line})
+	}
+	return nil
+}
+
+// reserve 在当前节末尾保留 n 个零字节
+func (p *parser) reserve(n int) {
+	if p.sec.name == ".bss" {
+		p.sec.size += n
+		return
+	}
+	p.sec.data = append(p.sec.data, make([]byte, n)...)
+}
+
+// data 输出 db/dw/dd 的操作数：数值、字符串（db）或标签（dd）
+func (p *parser) data(size int, rest string) error {
+	if p.sec == nil || p.sec.name == ".bss" {
+		return fmt.Errorf("只能在数据节中定义初始值")
+	}
+	for _, item := range splitOperands(rest) {
+		if len(item) >= 2 && (item[0] == '"' || item[0] == '\'' || item[0] == '`') && item[len(item)-1] == item[0] {
+			str := item[1 : len(item)-1]
+			for k := 0; k < len(str); k++ {
+				p.emit(uint32(str[k]), size)
+			}
+			continue
+		}
+		v, sym, err := parseExpr(item)
+		if err != nil {
+			return err
+		}
+		if sym != "" {
+			if size != 4 {
+				return fmt.Errorf("标签只能用 dd 定义")
+			}
+			p.prog.fixups = append(p.prog.fixups, fixup{sec: p.sec, offset: len(p.sec.data), sym: sym, addend: v})
+		}
+		p.emit(v, size)
+	}
+	return nil
+}
+
+// emit 按小端序追加 size 字节的数值
+func (p *parser) emit(v uint32, size int) {
+	for k := 0; k < size; k++ {
+		p.sec.data = append(p.sec.data, byte(v>>(8*k)))
+	}
+}
+
+// parseOperand 解析单个操作数，如 EAX、DWORD[ebp-8]、[table+EAX*4]、-5、str_0
+func parseOperand(s string) (op operand, err error) {
+	s = strings.TrimSpace(s)
+	lower := strings.ToLower(s)
+	for prefix, size := range sizePrefixes {
+		if strings.HasPrefix(lower, prefix) && len(s) > len(prefix) && !isIdentChar(s[len(prefix)]) {
+			op.size = size
+			s = strings.TrimSpace(s[len(prefix):])
+			if strings.HasPrefix(strings.ToLower(s), "ptr") {
+				s = strings.TrimSpace(s[3:])
+			}
+			break
+		}
+	}
+	if r, ok := registers[strings.ToLower(s)]; ok {
+		return operand{kind: opReg, reg: r.num, size: r.size}, nil
+	}
+	if strings.HasPrefix(s, "[") {
+		if !strings.HasSuffix(s, "]") {
+			return op, fmt.Errorf("缺少 ]: %s", s)
+		}
+		return parseMem(s[1:len(s)-1], op.size)
+	}
+	op.kind = opImm
+	op.value, op.sym, err = parseExpr(s)
+	return op, err
+}
+
+// parseMem 解析方括号中的地址表达式：基址 + 变址*比例 + 偏移 + 标签
+func parseMem(s string, size int) (op operand, err error) {
+	op = operand{kind: opMem, size: size, base: -1, index: -1, scale: 1}
+	for _, t := range splitTerms(s) {
+		reg, scale, isReg := parseIndex(t.text)
+		switch {
+		case isReg && t.neg:
+			return op, fmt.Errorf("寄存器不能取负: %s", s)
+		case isReg && scale == 1 && op.base < 0:
+			op.base = reg
+		case isReg && op.index < 0:
+			op.index, op.scale = reg, scale
+		case isReg:
+			return op, fmt.Errorf("寄存器过多: %s", s)
+		default:
+			v, sym, err := parseTerm(t)
+			if err != nil {
+				return op, err
+			}
+			if sym != "" {
+				if op.sym != "" {
+					return op, fmt.Errorf("地址中只能引用一个标签: %s", s)
+				}
+				op.sym = sym
+			}
+			op.value += v
+		}
+	}
+	return op, nil
+}
+
+// parseIndex 解析 REG 或 REG*N / N*REG
+func parseIndex(s string) (reg, scale int, ok bool) {
+	left, right, hasScale := strings.Cut(s, "*")
+	if !hasScale {
+		r, ok := registers[strings.ToLower(s)]
+		return r.num, 1, ok && r.size == 4
+	}
+	r, ok := registers[strings.ToLower(left)]
+	n := right
+	if !ok {
+		r, ok = registers[strings.ToLower(right)]
+		n = left
+	}
+	v, err := parseNumber(n)
+	if !ok || r.size != 4 || err != nil || (v != 1 && v != 2 && v != 4 && v != 8) {
+		return 0, 0, false
+	}
+	return r.num, int(v), true
+}
+
+// term 表达式中带符号的一项
+type term struct {
+	neg  bool
+	text string
+}
+
+// splitTerms 按 + - 拆分表达式，连续的符号合并（如 ebp+-8）
+func splitTerms(s string) (terms []term) {
+	s = strings.ReplaceAll(s, " ", "")
+	neg := false
+	start := 0
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) && s[i] != '+' && s[i] != '-' {
+			continue
+		}
+		if i > start {
+			terms = append(terms, term{neg: neg, text: s[start:i]})
+			neg = false
+		}
+		if i < len(s) && s[i] == '-' {
+			neg = !neg
+		}
+		start = i + 1
+	}
+	return terms
+}
+
+// parseExpr 解析 数值 ± 数值 + 标签 形式的表达式
+func parseExpr(s string) (value uint32, sym string, err error) {
+	terms := splitTerms(s)
+	if len(terms) == 0 {
+		return 0, "", fmt.Errorf("缺少操作数")
+	}
+	for _, t := range terms {
+		v, name, err := parseTerm(t)
+		if err != nil {
+			return 0, "", err
+		}
+		if name != "" {
+			if sym != "" {
+				return 0, "", fmt.Errorf("表达式中只能引用一个标签: %s", s)
+			}
+			sym = name
+		}
+		value += v
+	}
+	return value, sym, nil
+}
+
+// parseTerm 解析数值或标签，标签不能取负
+func parseTerm(t term) (uint32, string, error) {
+	if v, err := parseNumber(t.text); err == nil {
+		if t.neg {
+			v = -v
+		}
+		return uint32(v), "", nil
+	}
+	for k := 0; k < len(t.text); k++ {
+		if !isIdentChar(t.text[k]) {
+			return 0, "", fmt.Errorf("无法识别的操作数 %s", t.text)
+		}
+	}
+	if t.neg || t.text[0] >= '0' && t.text[0] <= '9' {
+		return 0, "", fmt.Errorf("无法识别的操作数 %s", t.text)
+	}
+	return 0, t.text, nil
+}
+
+// stripComment 去掉 ; 之后的注释（引号中的 ; 除外）
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch ch := line[i]; {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'' || ch == '`':
+			quote = ch
+		case ch == ';':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// splitLabel 拆分行首的 "标签:"
+func splitLabel(line string) (name, rest string, ok bool) {
+	i := 0
+	for i < len(line) && isIdentChar(line[i]) {
+		i++
+	}
+	if i == 0 || i >= len(line) || line[i] != ':' {
+		return "", line, false
+	}
+	return line[:i], strings.TrimSpace(line[i+1:]), true
+}
+
+func isIdentChar(ch byte) bool {
+	return ch == '_' || ch == '.' || ch == '$' || ch == '@' || ch == '?' ||
+		ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9'
+}
+
+// splitOperands 按逗号拆分操作数（引号和方括号中的逗号除外）
+func splitOperands(s string) (items []string) {
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'' || ch == '`':
+			quote = ch
+		case ch == '[':
+			depth++
+		case ch == ']':
+			depth--
+		case ch == ',' && depth == 0:
+			items = append(items, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" || len(items) > 0 {
+		items = append(items, last)
+	}
+	return items
+}
+
+// parseNumber 解析十进制、0x 十六进制或字符常量
+func parseNumber(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 3 && (s[0] == '\'' || s[0] == '"') && s[2] == s[0] {
+		return int64(s[1]), nil
+	}
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		v, err := strconv.ParseUint(s[2:], 16, 32)
+		return int64(v), err
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
@@ -0,0 +1,211 @@
+package emu
+
+// i386 Linux 的系统调用号
+const (
+	sysExit      = 1
+	sysRead      = 3
+	sysWrite     = 4
+	sysOpen      = 5
+	sysClose     = 6
+	sysBrk       = 45
+	sysOldMmap   = 90
+	sysMunmap    = 91
+	sysMmap2     = 192
+	sysExitGroup = 252
+)
+
+// 错误码，系统调用失败时返回其相反数
+const (
+	errNOENT  = 2
+	errBADF   = 9
+	errNOMEM  = 12
+	errFAULT  = 14
+	errINVAL  = 22
+	errNOSYS  = 38
+	errNODEV  = 19
+	errACCESS = 13
+)
+
+// open 的标志
+const (
+	oAccMode = 3
+	oWronly  = 1
+	oRdwr    = 2
+	oCreat   = 0x40
+	oTrunc   = 0x200
+	oAppend  = 0x400
+)
+
+// mapAnonymous mmap 的匿名映射标志
+const mapAnonymous = 0x20
+
+// file 打开的文件
+type file struct {
+	path   string
+	offset int
+	flags  uint32
+}
+
+// syscall 执行 int 0x80：EAX 为调用号，EBX、ECX、EDX、ESI、EDI、EBP 为参数，结果写回 EAX
+func (m *Machine) syscall() error {
+	a1, a2, a3 := m.regs[ebx], m.regs[ecx], m.regs[edx]
+	var ret int32
+	switch m.regs[eax] {
+	case sysExit, sysExitGroup:
+		m.exited, m.exitCode = true, int(a1&0xFF)
+		return nil
+	case sysRead:
+		ret = m.sysRead(int32(a1), a2, a3)
+	case sysWrite:
+		ret = m.sysWrite(int32(a1), a2, a3)
+	case sysOpen:
+		ret = m.sysOpen(a1, a2)
+	case sysClose:
+		if _, ok := m.files[int(int32(a1))]; !ok {
+			ret = -errBADF
+			break
+		}
+		delete(m.files, int(int32(a1)))
+	case sysBrk:
+		ret = int32(m.sysBrk(a1))
+	case sysOldMmap:
+		// 旧式 mmap 的 6 个参数放在 EBX 指向的内存中，偏移以字节为单位
+		var p [6]uint32
+		for k := range p {
+			v, err := m.read(a1+uint32(4*k), 4)
+			if err != nil {
+				ret = -errFAULT
+				break
+			}
+			p[k] = v
+		}
+		if ret == 0 {
+			ret = m.sysMmap(p[1], p[3], int32(p[4]))
+		}
+	case sysMmap2:
+		ret = m.sysMmap(a2, m.regs[esi], int32(m.regs[edi]))
+	case sysMunmap:
+		if a1%pageSize != 0 || a2 == 0 {
+			ret = -errINVAL
+			break
+		}
+		for p := a1 / pageSize; p*pageSize < a1+a2; p++ {
+			delete(m.pages, p)
+		}
+	default:
+		ret = -errNOSYS
+	}
+	m.regs[eax] = uint32(ret)
+	return nil
+}
+
+func (m *Machine) sysRead(fd int32, buf, count uint32) int32 {
+	var data []byte
+	if fd == 0 {
+		data = m.Stdin[m.stdinPos:]
+	} else if f, ok := m.files[int(fd)]; !ok || f.flags&oAccMode == oWronly {
+		return -errBADF
+	} else if content := m.FS[f.path]; f.offset < len(content) {
+		data = content[f.offset:]
+	}
+	if uint32(len(data)) > count {
+		data = data[:count]
+	}
+	if m.writeBytes(buf, data) != nil {
+		return -errFAULT
+	}
+	if fd == 0 {
+		m.stdinPos += len(data)
+	} else {
+		m.files[int(fd)].offset += len(data)
+	}
+	return int32(len(data))
+}
+
+func (m *Machine) sysWrite(fd int32, buf, count uint32) int32 {
+	data, err := m.readBytes(buf, count)
+	if err != nil {
+		return -errFAULT
+	}
+	switch fd {
+	case 1:
+		m.Stdout.Write(data)
+	case 2:
+		m.Stderr.Write(data)
+	default:
+		f, ok := m.files[int(fd)]
+		if !ok || f.flags&oAccMode == 0 {
+			return -errBADF
+		}
+		content := m.FS[f.path]
+		if f.flags&oAppend != 0 {
+			f.offset = len(content)
+		}
+		if end := f.offset + len(data); end > len(content) {
+			content = append(content, make([]byte, end-len(content))...)
+		}
+		copy(content[f.offset:], data)
+		m.FS[f.path] = content
+		f.offset += len(data)
+	}
+	return int32(len(data))
+}
+
+// sysOpen 在内存文件系统中打开文件，返回最小的空闲描述符
+func (m *Machine) sysOpen(pathAddr, flags uint32) int32 {
+	path, err := m.readString(pathAddr)
+	if err != nil {
+		return -errFAULT
+	}
+	if path == "" {
+		return -errNOENT
+	}
+	if _, ok := m.FS[path]; !ok {
+		if flags&oCreat == 0 {
+			return -errNOENT
+		}
+		m.FS[path] = nil
+	}
+	if flags&oTrunc != 0 {
+		if flags&oAccMode == 0 {
+			return -errACCESS
+		}
+		m.FS[path] = nil
+	}
+	fd := 3
+	for m.files[fd] != nil {
+		fd++
+	}
+	m.files[fd] = &file{path: path, flags: flags}
+	return int32(fd)
+}
+
+// sysBrk 移动堆顶，失败时返回原来的堆顶
+func (m *Machine) sysBrk(addr uint32) uint32 {
+	if addr < m.brkBase || addr >= mmapBase {
+		return m.brk
+	}
+	if addr > m.brk {
+		m.mapRange(m.brk, addr-m.brk)
+	}
+	m.brk = addr
+	return m.brk
+}
+
+// sysMmap 分配匿名映射，页的内容为零
+func (m *Machine) sysMmap(length, flags uint32, fd int32) int32 {
+	if length == 0 {
+		return -errINVAL
+	}
+	if flags&mapAnonymous == 0 || fd != -1 && fd != 0 {
+		return -errNODEV
+	}
+	size := alignUp(length, pageSize)
+	if m.mmapNext+size > stackTop-stackSize || m.mmapNext+size < m.mmapNext {
+		return -errNOMEM
+	}
+	addr := m.mmapNext
+	m.mmapNext += size
+	m.mapRange(addr, size)
+	return int32(addr)
+}
@@ -0,0 +1,396 @@
+package ir
+
+import (
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"math"
+)
+
+var binaryOps = map[string]Op{
+	"+": OpAdd, "-": OpSub, "*": OpMul, "/": OpDiv, "%": OpMod,
+	"&": OpAnd, "|": OpOr, "<<": OpShl, ">>": OpShr, "^": OpPow,
+	"==": OpEq, "!=": OpNe, "<": OpLt, "<=": OpLe, ">": OpGt, ">=": OpGe,
+}
+
+// value 计算表达式，聚合类型的值为其地址
+func (l *lowerer) value(exp *parser.Expression) *Value {
+	if exp.IsConst() {
+		return l.constant(exp)
+	}
+	switch {
+	case exp.Unary != "":
+		return l.unary(exp)
+	case exp.Index != nil:
+		return l.load(l.elemAddr(exp), exp.Type)
+	case exp.Field != nil:
+		return l.load(l.addr(exp), exp.Type)
+	case exp.Separator != "":
+		return l.binary(exp)
+	case exp.Call != nil:
+		if v := l.call(exp.Call); v != nil {
+			return v
+		}
+		return l.numConst(TypeOf(exp.Type), 0)
+	case exp.Var != nil:
+		if exp.Var.Value != nil {
+			// 赋值表达式的值为赋值后的变量
+			l.assign(exp.Var)
+			if exp.Var.Store != nil {
+				return l.value(exp.Var.Store)
+			}
+		}
+		if variable := l.promoted(exp.Var); variable != nil {
+			return l.read(variable, l.b)
+		}
+		addr, t := l.varAddr(exp.Var)
+		return l.load(addr, t)
+	}
+	panic("编译器内部错误: 无法计算的表达式")
+}
+
+// eval 计算表达式语句，丢弃表达式的值
+func (l *lowerer) eval(exp *parser.Expression) {
+	if exp.Var != nil && exp.Var.Value != nil && exp.Separator == "" && exp.Unary == "" && exp.Index == nil {
+		l.assign(exp.Var)
+		return
+	}
+	if exp.Call != nil && exp.Separator == "" && exp.Unary == "" && exp.Index == nil {
+		l.call(exp.Call)
+		return
+	}
+	l.value(exp)
+}
+
+// operand 按类型 t 计算表达式，整数与浮点数常量直接按 t 生成
+func (l *lowerer) operand(exp *parser.Expression, t Type) *Value {
+	if exp.IsConst() && exp.Type != nil {
+		switch typeSys.GetTypeType(exp.Type) {
+		case "string", "bool":
+		default:
+			return l.numConst(t, exp.Num)
+		}
+	}
+	return l.convert(l.value(exp), t)
+}
+
+// constant 常量的值，折叠后的比较结果没有类型，按布尔值处理
+func (l *lowerer) constant(exp *parser.Expression) *Value {
+	switch {
+	case exp.Type == nil || typeSys.GetTypeType(exp.Type) == "bool":
+		if exp.Bool {
+			return l.numConst(Bool, 1)
+		}
+		return l.numConst(Bool, 0)
+	case typeSys.GetTypeType(exp.Type) == "string":
+		v := l.b.NewValue(OpString, Ptr)
+		v.Aux = exp.StringVal
+		return v
+	}
+	return l.numConst(TypeOf(exp.Type), exp.Num)
+}
+
+// numConst 生成类型为 t 的数值常量，整数按 t 的宽度截断
+func (l *lowerer) numConst(t Type, num float64) *Value {
+	v := l.b.NewValue(OpConst, t)
+	switch {
+	case t.IsFloat():
+		if t == F32 {
+			num = float64(float32(num))
+		}
+		v.AuxInt = int64(math.Float64bits(num))
+	case t == Bool:
+		if num != 0 {
+			v.AuxInt = 1
+		}
+	case num >= 1<<63:
+		v.AuxInt = narrow(int64(uint64(num)), t)
+	default:
+		v.AuxInt = narrow(int64(num), t)
+	}
+	return v
+}
+
+// narrow 将整数截断到类型 t 的宽度并按其符号扩展回 64 位
+func narrow(n int64, t Type) int64 {
+	size := t.Size()
+	if t == Bool {
+		if n != 0 {
+			return 1
+		}
+		return 0
+	}
+	if size == 0 || size >= 8 {
+		return n
+	}
+	shift := 64 - 8*size
+	if t.IsSigned() {
+		return n << shift >> shift
+	}
+	return int64(uint64(n) << shift >> shift)
+}
+
+// convert 将值转换为类型 t，常量直接折叠；转换为布尔值时按是否非零
+func (l *lowerer) convert(v *Value, t Type) *Value {
+	if v.Type == t {
+		return v
+	}
+	if v.IsConst() {
+		c := l.b.NewValue(OpConst, t)
+		c.AuxInt = convertConst(v.AuxInt, v.Type, t)
+		return c
+	}
+	if t == Bool {
+		return l.b.NewValue(OpNe, Bool, v, l.b.NewValue(OpConst, v.Type))
+	}
+	return l.b.NewValue(OpConvert, t, v)
+}
+
+// convertConst 按 OpConvert 的语义转换常量
+func convertConst(n int64, from, to Type) int64 {
+	switch {
+	case from.IsFloat() && to.IsFloat():
+		f := math.Float64frombits(uint64(n))
+		if to == F32 {
+			f = float64(float32(f))
+		}
+		return int64(math.Float64bits(f))
+	case from.IsFloat():
+		f := math.Trunc(math.Float64frombits(uint64(n)))
+		if to.IsSigned() || f < 0 {
+			return narrow(int64(f), to)
+		}
+		return narrow(int64(uint64(f)), to)
+	case to.IsFloat():
+		f := float64(n)
+		if from == U64 || from == Ptr && from.Size() == 8 {
+			f = float64(uint64(n))
+		}
+		if to == F32 {
+			f = float64(float32(f))
+		}
+		return int64(math.Float64bits(f))
+	}
+	return narrow(n, to)
+}
+
+// load 从地址载入类型为 t 的值，聚合类型的值即为其地址
+func (l *lowerer) load(addr *Value, t typeSys.Type) *Value {
+	if IsAggregate(t) {
+		return addr
+	}
+	return l.b.NewValue(OpLoad, TypeOf(t), addr)
+}
+
+// binary 二元运算：比较的两侧按操作数类型计算，指针加减按字长计算（偏移量在解析时已换算为字节数），
+// 其余运算的两侧按表达式的类型计算
+func (l *lowerer) binary(exp *parser.Expression) *Value {
+	if exp.Separator == "&&" || exp.Separator == "||" {
+		return l.logic(exp)
+	}
+	op, ok := binaryOps[exp.Separator]
+	if !ok {
+		panic("编译器内部错误: 未知的运算符 " + exp.Separator)
+	}
+	t := TypeOf(exp.Type)
+	switch {
+	case op.IsCompare():
+		t = Bool
+		if operand := exp.OperandType(); operand != nil {
+			t = TypeOf(operand)
+		}
+		left := l.operand(exp.Left, t)
+		return l.b.NewValue(op, Bool, left, l.operand(exp.Right, t))
+	case exp.Type != nil && exp.Type.IsPointer():
+		t = Ptr
+	}
+	left := l.operand(exp.Left, t)
+	return l.b.NewValue(op, t, left, l.operand(exp.Right, t))
+}
+
+// logic 计算 && 与 || 的布尔值：按短路求值分支，在汇合处以 phi 选择结果
+func (l *lowerer) logic(exp *parser.Expression) *Value {
+	t, f, join := l.f.NewBlock(), l.f.NewBlock(), l.f.NewBlock()
+	l.branch(exp, t, f)
+	l.seal(t)
+	l.seal(f)
+	l.b = t
+	one := l.numConst(Bool, 1)
+	l.jump(join)
+	l.b = f
+	zero := l.numConst(Bool, 0)
+	l.jump(join)
+	l.seal(join)
+	l.b = join
+	return join.NewPhi(Bool, one, zero)
+}
+
+// unary 一元运算：取地址、解引用、切片长度与类型转换
+func (l *lowerer) unary(exp *parser.Expression) *Value {
+	switch exp.Unary {
+	case "&":
+		return l.addr(exp.Right)
+	case "*":
+		return l.load(l.value(exp.Right), exp.Type)
+	case "len":
+		return l.load(l.offset(l.value(exp.Right), int64(typeSys.PtrSize)), exp.Type)
+	case "as":
+		return l.operand(exp.Right, TypeOf(exp.Type))
+	}
+	panic("编译器内部错误: 未知的一元运算 " + exp.Unary)
+}
+
+// addr 计算可取地址表达式（变量、字段、解引用、下标）的地址，聚合类型的值本身即为地址
+func (l *lowerer) addr(exp *parser.Expression) *Value {
+	switch {
+	case exp.Unary == "*":
+		return l.value(exp.Right)
+	case exp.Index != nil:
+		return l.elemAddr(exp)
+	case exp.Field != nil:
+		return l.offset(l.addr(exp.Left), int64(exp.StructField().Offset))
+	case exp.Var != nil && exp.Unary == "" && exp.Separator == "" && exp.Var.Value == nil:
+		addr, _ := l.varAddr(exp.Var)
+		return addr
+	case IsAggregate(exp.Type):
+		return l.value(exp)
+	}
+	panic("编译器内部错误: 表达式不能取地址")
+}
+
+// elemAddr 计算 a[i] 的元素地址，开启越界检查时插入检查（下标与长度都是常量且未越界时省略）；
+// 下标可能含有函数调用，因此先于数组地址计算
+func (l *lowerer) elemAddr(exp *parser.Expression) *Value {
+	index := l.operand(exp.Index, Ptr)
+	base := l.addr(exp.Left)
+	var length *Value
+	switch t := exp.Left.Type.(type) {
+	case *typeSys.ArrayType:
+		length = l.numConst(Ptr, float64(t.Len))
+	case *typeSys.SliceType:
+		length = l.b.NewValue(OpLoad, Ptr, l.offset(base, int64(typeSys.PtrSize)))
+		base = l.b.NewValue(OpLoad, Ptr, base)
+	default:
+		panic("编译器内部错误: 不能对 " + exp.Left.Type.Type() + " 使用下标")
+	}
+	if l.boundsCheck && !(index.IsConst() && length.IsConst() && uint64(index.AuxInt) < uint64(length.AuxInt)) {
+		l.b.NewValue(OpBoundsCheck, Void, index, length)
+	}
+	size := int64(exp.Type.Size())
+	if index.IsConst() {
+		return l.offset(base, index.AuxInt*size)
+	}
+	if size != 1 {
+		index = l.b.NewValue(OpMul, Ptr, index, l.numConst(Ptr, float64(size)))
+	}
+	return l.b.NewValue(OpAdd, Ptr, base, index)
+}
+
+// offset 返回 addr 加上常量偏移后的地址，连续的常量偏移合并为一次
+func (l *lowerer) offset(addr *Value, off int64) *Value {
+	if off == 0 {
+		return addr
+	}
+	if addr.Op == OpAdd && addr.Args[1].IsConst() {
+		off += addr.Args[1].AuxInt
+		addr = addr.Args[0]
+		if off == 0 {
+			return addr
+		}
+	}
+	c := l.b.NewValue(OpConst, Ptr)
+	c.AuxInt = narrow(off, Ptr)
+	return l.b.NewValue(OpAdd, Ptr, addr, c)
+}
+
+// call 降低函数调用，返回返回值（返回聚合类型时为调用方临时空间的地址，没有返回值时为空）
+// 接口方法通过接口值中的虚表间接调用；聚合类型的实参传入其地址，由后端复制到调用的参数区，
+// 需要转换（结构体转接口、数组转切片）或之后的实参含有函数调用时先写入临时空间
+func (l *lowerer) call(call *parser.CallBlock) *Value {
+	if call == nil || call.Func == nil {
+		return nil
+	}
+	fn := call.Func
+	var args []*Value
+	var result, callee *Value
+	if len(fn.Return) > 0 && IsAggregate(fn.Return[0]) {
+		result = l.temp(fn.Return[0])
+		args = append(args, result)
+	}
+	if call.ThisVar != nil {
+		self, t := l.varAddr(call.ThisVar)
+		if iface, ok := t.(*typeSys.InterfaceType); ok {
+			vtable := l.b.NewValue(OpLoad, Ptr, l.offset(self, int64(typeSys.PtrSize)))
+			self = l.b.NewValue(OpLoad, Ptr, self)
+			slot := int64(parser.MethodSlot(iface, call.Name.Last()) * typeSys.PtrSize)
+			callee = l.b.NewValue(OpLoad, Ptr, l.offset(vtable, slot))
+		}
+		args = append(args, self)
+	}
+	exps := make([]*parser.Expression, len(fn.Args))
+	for i, arg := range fn.Args {
+		exps[i] = arg.Default
+		if i < len(call.Args) && call.Args[i] != nil && call.Args[i].Value != nil {
+			exps[i] = call.Args[i].Value
+		}
+	}
+	for i, arg := range fn.Args {
+		exp := exps[i]
+		switch {
+		case exp == nil:
+			args = append(args, l.numConst(TypeOf(arg.Type), 0))
+		case IsAggregate(arg.Type):
+			src := l.addr(exp)
+			if needsConvert(exp.Type, arg.Type) || hasCall(exps[i+1:]) {
+				tmp := l.temp(arg.Type)
+				l.storeAggregate(exp.Type, arg.Type, src, tmp)
+				src = tmp
+			}
+			args = append(args, src)
+		default:
+			args = append(args, l.operand(exp, TypeOf(arg.Type)))
+		}
+	}
+	var v *Value
+	if callee != nil {
+		v = l.b.NewValue(OpCallInd, ResultType(fn), append([]*Value{callee}, args...)...)
+	} else {
+		v = l.b.NewValue(OpCall, ResultType(fn), args...)
+	}
+	v.Aux = fn
+	if result != nil {
+		return result
+	}
+	if v.Type == Void {
+		return nil
+	}
+	return v
+}
+
+// needsConvert 报告聚合值作为另一类型传递时是否需要转换
+func needsConvert(from, to typeSys.Type) bool {
+	switch to.(type) {
+	case *typeSys.InterfaceType:
+		st, ok := from.(*typeSys.StructType)
+		return ok && !st.IsPointer()
+	case *typeSys.SliceType:
+		_, ok := from.(*typeSys.ArrayType)
+		return ok
+	}
+	return false
+}
+
+// hasCall 报告表达式中是否含有函数调用或赋值
+func hasCall(exps []*parser.Expression) bool {
+	for _, exp := range exps {
+		if exp == nil {
+			continue
+		}
+		if exp.Call != nil || exp.Var != nil && exp.Var.Value != nil {
+			return true
+		}
+		if hasCall([]*parser.Expression{exp.Left, exp.Right, exp.Index}) {
+			return true
+		}
+	}
+	return false
+}
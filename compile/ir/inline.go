@@ -0,0 +1,252 @@
+package ir
+
+import (
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"slices"
+)
+
+// InlineBudget 没有标注 build inline 的函数在调用处展开时的最大大小（见 size）
+const InlineBudget = 10
+
+// Inline 在调用处展开被调函数：被调函数不（直接或间接）调用自身、函数体中没有内联汇编、不是 build ext 声明的外部函数，
+// 且大小不超过 budget 或标注了 build inline。被调函数先于调用它的函数处理，展开的函数体中能展开的调用已经展开。
+// 被调函数的栈上对象在调用方中另行分配，在栈上的参数复制到新的对象中；被展开的函数仍然生成，供间接调用与导出的名称使用
+func (p *Program) Inline(budget int) {
+	funcs := make(map[*parser.FuncBlock]*Func)
+	for _, f := range p.Funcs {
+		funcs[f.Decl] = f
+	}
+	callee := func(v *Value) *Func {
+		if v.Op != OpCall {
+			return nil
+		}
+		return funcs[v.Aux.(*parser.FuncBlock)]
+	}
+
+	recursive := recursiveFuncs(p.Funcs, callee)
+	ok := make(map[*Func]bool)
+	for _, f := range callOrder(p.Funcs, callee) {
+		changed := false
+		// 展开时拆分出的基本块与复制的函数体追加在末尾，之后继续检查
+		for i := 0; i < len(f.Blocks); i++ {
+			for _, v := range f.Blocks[i].Values {
+				g := callee(v)
+				if g == nil || g == f || recursive[g] {
+					continue
+				}
+				if _, seen := ok[g]; !seen {
+					ok[g] = inlinable(g) && (g.Decl.Inline() || size(g) <= budget)
+				}
+				if ok[g] {
+					inlineCall(f, v, g)
+					changed = true
+					break
+				}
+			}
+		}
+		if changed {
+			finish(f)
+			if err := Verify(f); err != nil {
+				panic("编译器内部错误: " + err.Error())
+			}
+		}
+	}
+}
+
+// inlinable 报告函数体能否复制到调用处：函数体中没有内联汇编（其中的代码可能依赖栈帧与返回方式），也不是外部函数
+func inlinable(f *Func) bool {
+	for _, flag := range f.Decl.BuildFlags {
+		if flag.Type == "ext" {
+			return false
+		}
+	}
+	for _, b := range f.Blocks {
+		for _, v := range b.Values {
+			if v.Op == OpAsm {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// size 返回函数展开后大致的指令数：参数、常量与栈上对象的地址在使用处生成，不计入
+func size(f *Func) (n int) {
+	for _, b := range f.Blocks {
+		for _, v := range b.Values {
+			switch v.Op {
+			case OpArg, OpArgAddr, OpConst, OpAddr:
+			default:
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// recursiveFuncs 返回经直接调用能回到自身的函数
+func recursiveFuncs(funcs []*Func, callee func(v *Value) *Func) map[*Func]bool {
+	recursive := make(map[*Func]bool)
+	for _, f := range funcs {
+		seen := make(map[*Func]bool)
+		var visit func(g *Func)
+		visit = func(g *Func) {
+			for _, b := range g.Blocks {
+				for _, v := range b.Values {
+					h := callee(v)
+					if h == nil || seen[h] {
+						continue
+					}
+					seen[h] = true
+					visit(h)
+				}
+			}
+		}
+		visit(f)
+		recursive[f] = seen[f]
+	}
+	return recursive
+}
+
+// callOrder 按调用关系的后序返回函数，被调函数排在调用它的函数之前（递归的函数之间顺序任意）
+func callOrder(funcs []*Func, callee func(v *Value) *Func) []*Func {
+	var order []*Func
+	seen := make(map[*Func]bool)
+	var visit func(f *Func)
+	visit = func(f *Func) {
+		seen[f] = true
+		for _, b := range f.Blocks {
+			for _, v := range b.Values {
+				if g := callee(v); g != nil && !seen[g] {
+					visit(g)
+				}
+			}
+		}
+		order = append(order, f)
+	}
+	for _, f := range funcs {
+		if !seen[f] {
+			visit(f)
+		}
+	}
+	return order
+}
+
+// inlineCall 将 f 中的调用 call 替换为被调函数 g 的函数体：调用所在的基本块在调用处拆分，前一半跳转到复制的入口块，
+// 复制的各返回块跳转到后一半，返回值有多个来源时在后一半开头以 phi 合并，调用本身改为返回值的复制
+func inlineCall(f *Func, call *Value, g *Func) {
+	b := call.Block
+	k := slices.Index(b.Values, call)
+	after := f.NewBlock()
+	after.Values = append(after.Values, b.Values[k+1:]...)
+	for _, v := range after.Values {
+		v.Block = after
+	}
+	after.Kind, after.Control, after.Cases, after.Succs = b.Kind, b.Control, b.Cases, b.Succs
+	for _, succ := range after.Succs {
+		for i, pred := range succ.Preds {
+			if pred == b {
+				succ.Preds[i] = after
+			}
+		}
+	}
+	b.Values = b.Values[:k]
+	b.Kind, b.Control, b.Cases, b.Succs = BlockPlain, nil, nil, nil
+
+	blocks := make(map[*Block]*Block)
+	values := make(map[*Value]*Value)
+	slots := make(map[*Slot]*Slot)
+	for _, s := range g.Slots {
+		name := s.Name
+		if name != "" {
+			name = g.Name + "." + name
+		}
+		slots[s] = f.NewSlot(name, s.Size, s.Align)
+	}
+	for _, gb := range g.Blocks {
+		blocks[gb] = f.NewBlock()
+	}
+	// 标量参数直接使用调用处的实参，在栈上的参数复制到新的对象中；其余指令先复制，再按对应关系替换参数
+	for _, gb := range g.Blocks {
+		nb := blocks[gb]
+		for _, v := range gb.Values {
+			switch v.Op {
+			case OpArg:
+				values[v] = call.Args[v.AuxInt]
+				continue
+			case OpArgAddr:
+				values[v] = paramCopy(f, b, g, call, int(v.AuxInt))
+				continue
+			}
+			nv := nb.NewValue(v.Op, v.Type, v.Args...)
+			nv.AuxInt, nv.Aux = v.AuxInt, v.Aux
+			if s, ok := v.Aux.(*Slot); ok {
+				nv.Aux = slots[s]
+			}
+			values[v] = nv
+		}
+	}
+	var rets []*Value
+	for _, gb := range g.Blocks {
+		nb := blocks[gb]
+		for _, v := range nb.Values {
+			v.Args = slices.Clone(v.Args)
+			for i, arg := range v.Args {
+				v.Args[i] = values[arg]
+			}
+		}
+		for _, pred := range gb.Preds {
+			nb.Preds = append(nb.Preds, blocks[pred])
+		}
+		if gb.Kind == BlockRet {
+			nb.Kind = BlockPlain
+			nb.AddEdge(after)
+			rets = append(rets, values[gb.Control])
+			continue
+		}
+		nb.Kind, nb.Control, nb.Cases = gb.Kind, values[gb.Control], slices.Clone(gb.Cases)
+		for _, succ := range gb.Succs {
+			nb.Succs = append(nb.Succs, blocks[succ])
+		}
+	}
+	b.AddEdge(blocks[g.Entry()])
+
+	if call.Type == Void {
+		return
+	}
+	var result *Value
+	switch len(rets) {
+	case 0:
+		// 被调函数不会返回，后一半不可达
+		result = b.NewValue(OpConst, call.Type)
+	case 1:
+		result = rets[0]
+	default:
+		result = after.NewPhi(call.Type, rets...)
+	}
+	call.Op, call.Args, call.Aux, call.AuxInt, call.Block = OpCopy, []*Value{result}, nil, 0, after
+	i := 0
+	for i < len(after.Values) && after.Values[i].Op == OpPhi {
+		i++
+	}
+	after.Values = slices.Insert(after.Values, i, call)
+}
+
+// paramCopy 为被调函数 g 在栈上的第 i 个参数在调用方中分配对象，在调用前的基本块 b 中写入实参，返回对象的地址
+func paramCopy(f *Func, b *Block, g *Func, call *Value, i int) *Value {
+	p := g.Params[i]
+	align := p.Size
+	if p.Agg {
+		align = typeSys.PtrSize
+	}
+	addr := b.NewValue(OpAddr, Ptr)
+	addr.Aux = f.NewSlot(g.Name+"."+p.Name, p.Size, align)
+	if p.Agg {
+		move := b.NewValue(OpMove, Void, addr, call.Args[i])
+		move.AuxInt = int64(p.Size)
+	} else {
+		b.NewValue(OpStore, Void, addr, call.Args[i])
+	}
+	return addr
+}
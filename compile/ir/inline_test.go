@@ -0,0 +1,162 @@
+package ir
+
+import (
+	"cuteify/parser"
+	"testing"
+)
+
+// newCaller 构造 main(a) = callee(a, 3) + 1，callee 有两个参数
+func newCaller(callee *Func) *Program {
+	if callee.Decl == nil {
+		callee.Decl = &parser.FuncBlock{}
+	}
+	f := &Func{Name: "main", Decl: &parser.FuncBlock{}, Result: I32, Params: []Param{{Name: "a", Kind: ParamArg, Type: I32, Size: 4}}}
+	entry := f.NewBlock()
+	a := entry.NewValue(OpArg, I32)
+	three := entry.NewValue(OpConst, I32)
+	three.AuxInt = 3
+	call := entry.NewValue(OpCall, callee.Result, a, three)
+	call.Aux = callee.Decl
+	one := entry.NewValue(OpConst, I32)
+	one.AuxInt = 1
+	entry.Kind, entry.Control = BlockRet, entry.NewValue(OpAdd, I32, call, one)
+	return &Program{Funcs: []*Func{f, callee}}
+}
+
+// newLocal 构造 local(x, y)：x 被取地址留在栈上，结果经栈上对象 t 返回
+//
+//	b0: v0 = argaddr #0; v1 = arg #1; v2 = addr s0; v3 = load v0; v4 = add v3 v1; store v2 v4; ret load v2
+func newLocal() *Func {
+	f := &Func{Name: "local", Result: I32, Params: []Param{
+		{Name: "x", Kind: ParamArg, Type: I32, Size: 4},
+		{Name: "y", Kind: ParamArg, Type: I32, Size: 4},
+	}}
+	t := f.NewSlot("t", 4, 4)
+	b := f.NewBlock()
+	x := b.NewValue(OpArgAddr, Ptr)
+	y := b.NewValue(OpArg, I32)
+	y.AuxInt = 1
+	addr := b.NewValue(OpAddr, Ptr)
+	addr.Aux = t
+	sum := b.NewValue(OpAdd, I32, b.NewValue(OpLoad, I32, x), y)
+	b.NewValue(OpStore, Void, addr, sum)
+	b.Kind, b.Control = BlockRet, b.NewValue(OpLoad, I32, addr)
+	return f
+}
+
+// calls 返回函数中直接调用的次数
+func calls(f *Func) (n int) {
+	for _, b := range f.Blocks {
+		for _, v := range b.Values {
+			if v.Op == OpCall {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestInline(t *testing.T) {
+	callee := newMax()
+	prog := newCaller(callee)
+	before := callee.String()
+	prog.Inline(InlineBudget)
+	f := prog.Funcs[0]
+	if err := Verify(f); err != nil {
+		t.Fatal(err)
+	}
+	if calls(f) != 0 {
+		t.Errorf("调用没有展开:\n%s", f)
+	}
+	// 两个返回值的来源在调用后的基本块中以 phi 合并，它的结果直接用于加法
+	phis := 0
+	for _, b := range f.Blocks {
+		for _, v := range b.Values {
+			if v.Op == OpPhi {
+				phis++
+			}
+			if v.Op == OpAdd && v.Args[0].Op != OpPhi {
+				t.Errorf("加法的参数为 %s，应为 phi", v.Args[0].LongString())
+			}
+		}
+	}
+	if phis != 1 {
+		t.Errorf("有 %d 个 phi，应为 1 个:\n%s", phis, f)
+	}
+	if got := callee.String(); got != before {
+		t.Errorf("被调函数被修改:\n%s", got)
+	}
+}
+
+func TestInlineSlots(t *testing.T) {
+	prog := newCaller(newLocal())
+	prog.Inline(InlineBudget)
+	f := prog.Funcs[0]
+	if err := Verify(f); err != nil {
+		t.Fatal(err)
+	}
+	if calls(f) != 0 {
+		t.Fatalf("调用没有展开:\n%s", f)
+	}
+	// 被调函数的对象与被取地址的参数在调用方中另行分配
+	var names []string
+	for _, s := range f.Slots {
+		names = append(names, s.Name)
+	}
+	if len(names) != 2 || names[0] != "local.t" || names[1] != "local.x" {
+		t.Errorf("调用方的栈上对象为 %q，应为 [local.t local.x]", names)
+	}
+	stores := 0
+	for _, v := range f.Entry().Values {
+		if v.Op == OpStore && v.Args[1].Op == OpArg {
+			stores++
+		}
+	}
+	if stores != 1 {
+		t.Errorf("实参没有写入参数的对象:\n%s", f)
+	}
+}
+
+func TestInlineLimits(t *testing.T) {
+	cases := []struct {
+		name   string
+		callee func() *Func
+		budget int
+		calls  int
+	}{
+		{"超出大小", newMax, 0, 1},
+		{"build inline", func() *Func {
+			f := newMax()
+			f.Decl = &parser.FuncBlock{BuildFlags: []*parser.Build{{Type: "inline"}}}
+			return f
+		}, 0, 0},
+		{"内联汇编", func() *Func {
+			f := newMax()
+			then := f.Blocks[1]
+			then.Values = append(then.Values, &Value{Op: OpAsm, Type: Void, Block: then, Aux: &Asm{}})
+			return f
+		}, InlineBudget, 1},
+		{"外部函数", func() *Func {
+			f := newMax()
+			f.Decl = &parser.FuncBlock{BuildFlags: []*parser.Build{{Type: "ext", Ext: "max"}}}
+			return f
+		}, InlineBudget, 1},
+		{"递归", func() *Func {
+			f := newMax()
+			f.Decl = &parser.FuncBlock{}
+			then := f.Blocks[1]
+			call := then.NewValue(OpCall, I32, f.Blocks[0].Values[0], f.Blocks[0].Values[1])
+			call.Aux = f.Decl
+			return f
+		}, InlineBudget, 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			prog := newCaller(c.callee())
+			prog.Inline(c.budget)
+			if got := calls(prog.Funcs[0]); got != c.calls {
+				t.Errorf("展开后有 %d 个调用，应为 %d 个:\n%s", got, c.calls, prog.Funcs[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,391 @@
+// Package ir 定义语法树与目标后端之间的中间表示：带类型的 SSA 形式三地址码。
+//
+// 每个函数由基本块组成，基本块中依次存放 Value，每个 Value 即一条指令及其结果（虚拟寄存器），
+// 以 Op 区分运算，参数为其他 Value；基本块以 Kind 给出的跳转结束。没有取地址、也不在内联汇编中引用的
+// 标量局部变量在构造时提升为虚拟寄存器，汇合处以 phi 合并；结构体、数组、切片、接口值以及被取地址的变量
+// 存放在栈上的 Slot 中，通过 Load/Store 访问，聚合类型的值为其地址。
+//
+// Lower 将整个程序的语法树降低为 Program，Verify 检查 SSA 的结构与类型，Program.String 输出文本形式。
+package ir
+
+import (
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"strconv"
+)
+
+// Type IR 值的机器类型，聚合类型的值为其地址（Ptr）
+type Type uint8
+
+const (
+	Void Type = iota
+	Bool
+	I8
+	I16
+	I32
+	I64
+	U8
+	U16
+	U32
+	U64
+	F32
+	F64
+	Ptr
+)
+
+var typeNames = [...]string{"void", "bool", "i8", "i16", "i32", "i64", "u8", "u16", "u32", "u64", "f32", "f64", "ptr"}
+
+func (t Type) String() string {
+	return typeNames[t]
+}
+
+// Size 返回类型的字节数，Ptr 为目标的字长
+func (t Type) Size() int {
+	switch t {
+	case Bool, I8, U8:
+		return 1
+	case I16, U16:
+		return 2
+	case I32, U32, F32:
+		return 4
+	case I64, U64, F64:
+		return 8
+	case Ptr:
+		return typeSys.PtrSize
+	}
+	return 0
+}
+
+// IsInt 报告是否为整数类型（不包括 Ptr 与 Bool）
+func (t Type) IsInt() bool {
+	return t >= I8 && t <= U64
+}
+
+// IsSigned 报告是否为有符号整数
+func (t Type) IsSigned() bool {
+	return t >= I8 && t <= I64
+}
+
+// IsFloat 报告是否为浮点类型
+func (t Type) IsFloat() bool {
+	return t == F32 || t == F64
+}
+
+// TypeOf 返回源语言类型对应的 IR 类型：指针、字符串与聚合类型为 Ptr
+func TypeOf(t typeSys.Type) Type {
+	if t == nil {
+		return Void
+	}
+	if t.IsPointer() || IsAggregate(t) {
+		return Ptr
+	}
+	switch typeSys.GetTypeType(t) {
+	case "string":
+		return Ptr
+	case "bool":
+		return Bool
+	case "float":
+		if t.Size() == 4 {
+			return F32
+		}
+		return F64
+	}
+	bits, signed, ok := typeSys.IntInfo(t)
+	if !ok {
+		panic("编译器内部错误: 类型 " + t.Type() + " 没有对应的 IR 类型")
+	}
+	types := map[int]Type{8: U8, 16: U16, 32: U32, 64: U64}
+	if signed {
+		types = map[int]Type{8: I8, 16: I16, 32: I32, 64: I64}
+	}
+	return types[bits]
+}
+
+// IsAggregate 报告源语言类型的值是否按地址处理：结构体、数组、切片与接口值
+func IsAggregate(t typeSys.Type) bool {
+	if t == nil || t.IsPointer() {
+		return false
+	}
+	switch t.(type) {
+	case *typeSys.StructType, *typeSys.ArrayType, *typeSys.SliceType, *typeSys.InterfaceType:
+		return true
+	}
+	return false
+}
+
+// Op 指令的运算
+type Op uint8
+
+const (
+	OpInvalid Op = iota
+
+	OpConst   // 常量，值为 AuxInt（浮点数为 f64 的位模式）
+	OpString  // 字符串字面量的地址，Aux 为字符串内容
+	OpGlobal  // 全局变量的地址，Aux 为其定义 *parser.VarBlock
+	OpVTable  // 结构体实现接口时的虚表地址，Aux 为 VTable
+	OpAddr    // 栈上对象的地址，Aux 为 *Slot
+	OpArg     // 标量参数的值，AuxInt 为 Func.Params 中的下标
+	OpArgAddr // 参数在栈上的地址（按值传入的聚合参数或被取地址的参数），AuxInt 为参数下标
+	OpPhi     // 按前驱选择参数，Args 与 Block.Preds 一一对应
+	OpCopy    // 复制 Args[0]
+
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv // 按类型区分有符号与无符号除法
+	OpMod
+	OpAnd
+	OpOr
+	OpShl
+	OpShr // 有符号类型为算术右移
+	OpPow // 乘方，即源语言中的 ^
+
+	OpEq // 比较的结果为 Bool，按参数的类型区分有符号与无符号
+	OpNe
+	OpLt
+	OpLe
+	OpGt
+	OpGe
+
+	OpConvert // 将 Args[0] 转换为结果类型：整数截断或扩展，整数与浮点数之间按数值转换
+
+	OpLoad  // 从地址 Args[0] 载入结果类型的值
+	OpStore // 将 Args[1] 按其类型写入地址 Args[0]
+	OpMove  // 从地址 Args[1] 复制 AuxInt 字节到地址 Args[0]
+	OpZero  // 将地址 Args[0] 处的 AuxInt 字节清零
+
+	OpCall        // 调用 Aux（*parser.FuncBlock），Args 与被调函数的 Params 一一对应
+	OpCallInd     // 调用地址 Args[0] 处的函数，Aux 为其声明，Args[1:] 与 Params 一一对应
+	OpAsm         // 内联汇编，Aux 为 *Asm，Args 为其中引用的变量的地址
+	OpBoundsCheck // Args[0] 不小于 Args[1]（按无符号数比较）时按越界结束程序
+)
+
+var opNames = [...]string{
+	OpInvalid: "invalid",
+	OpConst:   "const", OpString: "string", OpGlobal: "global", OpVTable: "vtable",
+	OpAddr: "addr", OpArg: "arg", OpArgAddr: "argaddr", OpPhi: "phi", OpCopy: "copy",
+	OpAdd: "add", OpSub: "sub", OpMul: "mul", OpDiv: "div", OpMod: "mod",
+	OpAnd: "and", OpOr: "or", OpShl: "shl", OpShr: "shr", OpPow: "pow",
+	OpEq: "eq", OpNe: "ne", OpLt: "lt", OpLe: "le", OpGt: "gt", OpGe: "ge",
+	OpConvert: "convert",
+	OpLoad:    "load", OpStore: "store", OpMove: "move", OpZero: "zero",
+	OpCall: "call", OpCallInd: "callind", OpAsm: "asm", OpBoundsCheck: "boundscheck",
+}
+
+func (op Op) String() string {
+	return opNames[op]
+}
+
+// IsCompare 报告是否为比较运算
+func (op Op) IsCompare() bool {
+	return op >= OpEq && op <= OpGe
+}
+
+// IsBinary 报告是否为两个同类型参数的算术或位运算
+func (op Op) IsBinary() bool {
+	return op >= OpAdd && op <= OpPow
+}
+
+// HasSideEffects 报告指令除结果之外是否还有其他作用（写内存、调用、可能结束程序），没有使用时也不能删除
+func (op Op) HasSideEffects() bool {
+	switch op {
+	case OpStore, OpMove, OpZero, OpCall, OpCallInd, OpAsm, OpBoundsCheck:
+		return true
+	}
+	return false
+}
+
+// Value 一条指令及其结果
+type Value struct {
+	ID     int
+	Op     Op
+	Type   Type // 结果的类型，没有结果时为 Void
+	Args   []*Value
+	AuxInt int64
+	Aux    any
+	Block  *Block
+}
+
+// String 返回值的名称，如 v3
+func (v *Value) String() string {
+	return "v" + strconv.Itoa(v.ID)
+}
+
+// IsConst 报告值是否为常量
+func (v *Value) IsConst() bool {
+	return v.Op == OpConst
+}
+
+// BlockKind 基本块结束时的跳转方式
+type BlockKind uint8
+
+const (
+	BlockPlain  BlockKind = iota // 跳转到 Succs[0]
+	BlockIf                      // Control 为真时跳转到 Succs[0]，否则跳转到 Succs[1]
+	BlockSwitch                  // Control 等于 Cases[i] 时跳转到 Succs[i]，都不相等时跳转到最后一个后继
+	BlockRet                     // 返回 Control，没有返回值时 Control 为空
+)
+
+var blockKindNames = [...]string{"plain", "if", "switch", "ret"}
+
+func (k BlockKind) String() string {
+	return blockKindNames[k]
+}
+
+// Block 基本块，phi 位于 Values 的开头
+type Block struct {
+	ID      int
+	Kind    BlockKind
+	Values  []*Value
+	Control *Value
+	Cases   []int64
+	Succs   []*Block
+	Preds   []*Block
+	Func    *Func
+}
+
+// String 返回基本块的名称，如 b2
+func (b *Block) String() string {
+	return "b" + strconv.Itoa(b.ID)
+}
+
+// ParamKind 参数的来源
+type ParamKind uint8
+
+const (
+	ParamResult ParamKind = iota // 返回聚合类型时调用方提供的结果地址
+	ParamSelf                    // 方法的接收者地址
+	ParamArg                     // 源代码中的参数
+)
+
+// Param 函数在调用时依次传入的参数
+type Param struct {
+	Name string
+	Kind ParamKind
+	Type Type // 按值传入的聚合参数为 Ptr，调用处的实参为待复制数据的地址
+	Size int  // 参数在调用时占用的字节数（按值传入的聚合参数为整个值的大小）
+	Agg  bool // 是否为按值传入的聚合参数
+}
+
+// Slot 栈上的对象：未提升的局部变量或聚合类型的临时空间
+type Slot struct {
+	ID    int
+	Name  string // 变量名，临时空间为空
+	Size  int
+	Align int
+}
+
+func (s *Slot) String() string {
+	return "s" + strconv.Itoa(s.ID)
+}
+
+// VTable OpVTable 的 Aux：结构体作为接口值时使用的虚表
+type VTable struct {
+	Struct *typeSys.StructType
+	Iface  *typeSys.InterfaceType
+}
+
+// Asm OpAsm 的 Aux：内联汇编及其中引用的变量名，变量的地址依次为 OpAsm 的参数
+type Asm struct {
+	Build *parser.Build
+	Names []string
+}
+
+// Params 返回调用函数时依次传入的参数：返回聚合类型时的结果地址、方法的接收者地址，然后是源代码中的参数
+func Params(fn *parser.FuncBlock) (params []Param) {
+	if len(fn.Return) > 0 && IsAggregate(fn.Return[0]) {
+		params = append(params, Param{Name: "result", Kind: ParamResult, Type: Ptr, Size: typeSys.PtrSize})
+	}
+	if fn.Class != nil {
+		params = append(params, Param{Name: "self", Kind: ParamSelf, Type: Ptr, Size: typeSys.PtrSize})
+	}
+	for _, arg := range fn.Args {
+		p := Param{Name: arg.Name.String(), Kind: ParamArg, Type: TypeOf(arg.Type), Size: TypeOf(arg.Type).Size()}
+		if IsAggregate(arg.Type) {
+			p.Agg, p.Size = true, arg.Type.Size()
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+// ResultType 返回函数返回值的 IR 类型，没有返回值时为 Void
+func ResultType(fn *parser.FuncBlock) Type {
+	if len(fn.Return) == 0 {
+		return Void
+	}
+	return TypeOf(fn.Return[0])
+}
+
+// Func 一个函数的 IR
+type Func struct {
+	Name   string
+	Decl   *parser.FuncBlock
+	Params []Param
+	Result Type     // 返回值的类型，返回聚合类型时为 Ptr（即结果地址）
+	Blocks []*Block // Blocks[0] 为入口
+	Slots  []*Slot
+	Links  []string // build link 导出的名称
+
+	nextValue int
+	nextBlock int
+}
+
+// Entry 返回入口基本块
+func (f *Func) Entry() *Block {
+	return f.Blocks[0]
+}
+
+// NewBlock 新建基本块并追加到函数末尾
+func (f *Func) NewBlock() *Block {
+	b := &Block{ID: f.nextBlock, Func: f}
+	f.nextBlock++
+	f.Blocks = append(f.Blocks, b)
+	return b
+}
+
+// NewSlot 在栈上新建对象
+func (f *Func) NewSlot(name string, size, align int) *Slot {
+	s := &Slot{ID: len(f.Slots), Name: name, Size: max(size, 1), Align: max(align, 1)}
+	f.Slots = append(f.Slots, s)
+	return s
+}
+
+// newValue 新建不属于任何基本块的值
+func (f *Func) newValue(op Op, t Type, args ...*Value) *Value {
+	v := &Value{ID: f.nextValue, Op: op, Type: t, Args: args}
+	f.nextValue++
+	return v
+}
+
+// NewValue 在基本块末尾追加值
+func (b *Block) NewValue(op Op, t Type, args ...*Value) *Value {
+	v := b.Func.newValue(op, t, args...)
+	v.Block = b
+	b.Values = append(b.Values, v)
+	return v
+}
+
+// NewPhi 在基本块开头（已有的 phi 之后）插入 phi
+func (b *Block) NewPhi(t Type, args ...*Value) *Value {
+	v := b.Func.newValue(OpPhi, t, args...)
+	v.Block = b
+	i := 0
+	for i < len(b.Values) && b.Values[i].Op == OpPhi {
+		i++
+	}
+	b.Values = append(b.Values[:i], append([]*Value{v}, b.Values[i:]...)...)
+	return v
+}
+
+// AddEdge 添加从 b 到 succ 的边
+func (b *Block) AddEdge(succ *Block) {
+	b.Succs = append(b.Succs, succ)
+	succ.Preds = append(succ.Preds, b)
+}
+
+// Program 整个程序的 IR
+type Program struct {
+	Funcs   []*Func
+	Globals []*parser.VarBlock
+}
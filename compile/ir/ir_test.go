@@ -0,0 +1,125 @@
+package ir
+
+import (
+	"strings"
+	"testing"
+)
+
+// newMax 构造 max(a, b)：条件分支在汇合处以 phi 选择结果
+func newMax() *Func {
+	f := &Func{Name: "max", Result: I32, Params: []Param{
+		{Name: "a", Kind: ParamArg, Type: I32, Size: 4},
+		{Name: "b", Kind: ParamArg, Type: I32, Size: 4},
+	}}
+	entry, then, join := f.NewBlock(), f.NewBlock(), f.NewBlock()
+	a := entry.NewValue(OpArg, I32)
+	b := entry.NewValue(OpArg, I32)
+	b.AuxInt = 1
+	entry.Kind, entry.Control = BlockIf, entry.NewValue(OpGt, Bool, a, b)
+	entry.AddEdge(then)
+	entry.AddEdge(join)
+	then.Kind = BlockPlain
+	then.AddEdge(join)
+	join.Kind, join.Control = BlockRet, join.NewPhi(I32, a, b)
+	return f
+}
+
+func TestPrint(t *testing.T) {
+	want := `func max(a i32, b i32) i32 {
+b0:
+  v0 = arg i32 #0
+  v1 = arg i32 #1
+  v2 = gt bool v0 v1
+  if v2 b1 b2
+b1: ; preds b0
+  jmp b2
+b2: ; preds b0 b1
+  v3 = phi i32 v0:b0 v1:b1
+  ret v3
+}
+`
+	if got := newMax().String(); got != want {
+		t.Errorf("输出为\n%s应为\n%s", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	if err := Verify(newMax()); err != nil {
+		t.Fatalf("正确的函数未通过检查: %v", err)
+	}
+	cases := []struct {
+		name   string
+		mutate func(f *Func)
+		want   string
+	}{
+		{"phi 参数个数", func(f *Func) {
+			phi := f.Blocks[2].Values[0]
+			phi.Args = phi.Args[:1]
+		}, "需要 2 个参数"},
+		{"类型不符", func(f *Func) {
+			f.Blocks[0].Values[1].Type = I8
+			f.Params[1].Type = I8
+		}, "两侧类型"},
+		{"条件不是 bool", func(f *Func) {
+			f.Blocks[0].Control = f.Blocks[0].Values[0]
+		}, "if 的条件不是 bool"},
+		{"返回类型", func(f *Func) {
+			f.Result = I64
+		}, "返回值的类型"},
+		{"边不对应", func(f *Func) {
+			f.Blocks[2].Preds = f.Blocks[2].Preds[1:]
+		}, "边不对应"},
+		{"不支配使用", func(f *Func) {
+			// 在 b1 中定义、在汇合处使用：经 b0 直接到达 b2 时没有定义
+			then := f.Blocks[1]
+			v := then.NewValue(OpAdd, I32, f.Blocks[0].Values[0], f.Blocks[0].Values[1])
+			f.Blocks[2].Control = v
+		}, "不支配"},
+		{"不可达", func(f *Func) {
+			dead := f.NewBlock()
+			dead.Kind = BlockPlain
+			dead.AddEdge(f.Blocks[2])
+			f.Blocks[2].Values[0].Args = append(f.Blocks[2].Values[0].Args, f.Blocks[0].Values[0])
+		}, "不可达"},
+		{"phi 不在开头", func(f *Func) {
+			join := f.Blocks[2]
+			c := join.NewValue(OpConst, I32)
+			join.Values[0], join.Values[1] = c, join.Values[0]
+		}, "不在基本块开头"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f := newMax()
+			c.mutate(f)
+			err := Verify(f)
+			if err == nil {
+				t.Fatal("错误的函数通过了检查")
+			}
+			if !strings.Contains(err.Error(), c.want) {
+				t.Errorf("错误为 %q，应包含 %q", err, c.want)
+			}
+		})
+	}
+}
+
+func TestSplitCriticalEdges(t *testing.T) {
+	f := newMax()
+	// b0 -> b2 是关键边：b0 有两个后继，b2 有两个前驱
+	SplitCriticalEdges(f)
+	if err := Verify(f); err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range f.Blocks {
+		if len(b.Succs) < 2 {
+			continue
+		}
+		for _, succ := range b.Succs {
+			if len(succ.Preds) > 1 {
+				t.Errorf("%s -> %s 仍是关键边", b, succ)
+			}
+		}
+	}
+	if len(f.Blocks) != 4 {
+		t.Errorf("有 %d 个基本块，应为 4 个", len(f.Blocks))
+	}
+}
@@ -0,0 +1,670 @@
+package ir
+
+import (
+	"cuteify/compile/data"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"sort"
+)
+
+// Lower 将整个程序（GetPackage 得到的合并后的 AST）降低为 IR。函数的取舍与编译器一致：跳过 build os 不匹配的函数
+// 和未被使用的函数，泛型只生成实例。boundsCheck 为真时在数组与切片的下标访问处插入越界检查
+func Lower(root *parser.Node, boundsCheck bool) *Program {
+	prog := &Program{}
+	var addFunc func(n *parser.Node)
+	addFunc = func(n *parser.Node) {
+		funcBlock := n.Value.(*parser.FuncBlock)
+		if funcBlock.Generic != nil {
+			for _, inst := range funcBlock.Generic.Instances {
+				addFunc(inst)
+			}
+			return
+		}
+		if funcBlock.Useful || funcBlock.Name.String() == "main" {
+			prog.Funcs = append(prog.Funcs, lowerFunc(n, boundsCheck))
+		}
+	}
+	for _, n := range root.Children {
+		if n.Ignore {
+			continue
+		}
+		switch v := n.Value.(type) {
+		case *parser.FuncBlock:
+			ignored := false
+			for _, flag := range v.BuildFlags {
+				ignored = ignored || flag.Type == "os" && flag.Ignore
+			}
+			if !ignored {
+				addFunc(n)
+			}
+		case *parser.StructBlock:
+			if v.Generic == nil {
+				continue
+			}
+			for _, method := range v.MethodTemplates {
+				for _, inst := range method.Generic.Instances {
+					addFunc(inst)
+				}
+			}
+		case *parser.VarBlock:
+			if v.IsGlobal {
+				prog.Globals = append(prog.Globals, v)
+			}
+		}
+	}
+	return prog
+}
+
+// lowerer 降低一个函数时的状态
+type lowerer struct {
+	f           *Func
+	fn          *parser.FuncBlock
+	b           *Block // 当前基本块，已经以跳转结束时为空
+	boundsCheck bool
+
+	vars   map[any]*variable // 局部变量与参数的定义（*parser.VarBlock 或 *parser.ArgBlock）
+	self   *Value            // 方法的接收者地址
+	result *Value            // 返回聚合类型时的结果地址
+	loops  []loop
+
+	defs       map[*Block]map[*variable]*Value // 各基本块末尾提升的变量的当前值
+	incomplete map[*Block][]pendingPhi         // 未封闭的基本块中等待补齐参数的 phi
+	sealed     map[*Block]bool                 // 前驱已全部确定的基本块
+}
+
+// variable 局部变量或参数
+type variable struct {
+	name string
+	typ  typeSys.Type
+	addr *Value // 存放在栈上时为其地址，提升为虚拟寄存器时为空
+}
+
+// loop break 与 continue 的跳转目标，switch 中 continue 沿用外层循环的目标
+type loop struct {
+	cont *Block
+	brk  *Block
+}
+
+// pendingPhi 未封闭的基本块中读取变量时创建的 phi
+type pendingPhi struct {
+	v   *variable
+	phi *Value
+}
+
+func lowerFunc(n *parser.Node, boundsCheck bool) *Func {
+	funcBlock := n.Value.(*parser.FuncBlock)
+	f := &Func{
+		Name:   funcBlock.Name.String(),
+		Decl:   funcBlock,
+		Params: Params(funcBlock),
+		Result: ResultType(funcBlock),
+		Links:  links(n),
+	}
+	l := &lowerer{
+		f:           f,
+		fn:          funcBlock,
+		boundsCheck: boundsCheck,
+		vars:        make(map[any]*variable),
+		defs:        make(map[*Block]map[*variable]*Value),
+		incomplete:  make(map[*Block][]pendingPhi),
+		sealed:      make(map[*Block]bool),
+	}
+	l.b = f.NewBlock()
+	l.seal(l.b)
+	escaped := escapes(n)
+	l.params(escaped)
+	l.declare(n, escaped)
+	l.block(n)
+	if l.b != nil {
+		l.b.Kind = BlockRet
+		l.b = nil
+	}
+	finish(f)
+	if err := Verify(f); err != nil {
+		panic("编译器内部错误: " + err.Error())
+	}
+	return f
+}
+
+// links 返回函数体中 build link("name") 要导出的名称
+func links(n *parser.Node) (names []string) {
+	for _, child := range n.Children {
+		if block, ok := child.Value.(*parser.Build); ok && block.Type == "link" && block.Link != "" {
+			names = append(names, block.Link)
+		}
+	}
+	return
+}
+
+// params 在入口块中取出参数：标量参数提升为虚拟寄存器，按值传入的聚合参数与被取地址的参数留在调用方压入的位置
+func (l *lowerer) params(escaped map[any]bool) {
+	entry := l.f.Entry()
+	args := l.fn.Args
+	for i, p := range l.f.Params {
+		switch p.Kind {
+		case ParamResult:
+			l.result = entry.NewValue(OpArg, Ptr)
+			l.result.AuxInt = int64(i)
+		case ParamSelf:
+			l.self = entry.NewValue(OpArg, Ptr)
+			l.self.AuxInt = int64(i)
+		case ParamArg:
+			arg := args[0]
+			args = args[1:]
+			v := &variable{name: arg.Name.String(), typ: arg.Type}
+			l.vars[arg] = v
+			if p.Agg || escaped[arg] {
+				v.addr = entry.NewValue(OpArgAddr, Ptr)
+				v.addr.AuxInt = int64(i)
+				continue
+			}
+			value := entry.NewValue(OpArg, p.Type)
+			value.AuxInt = int64(i)
+			l.write(v, entry, value)
+		}
+	}
+}
+
+// declare 登记函数体中定义的局部变量（包括 for 循环的初始化变量），聚合类型与被取地址的变量在栈上分配
+func (l *lowerer) declare(n *parser.Node, escaped map[any]bool) {
+	for _, child := range n.Children {
+		if v, ok := child.Value.(*parser.VarBlock); ok && v.IsDefine && !v.IsGlobal {
+			variable := &variable{name: v.Name.String(), typ: v.Type}
+			if IsAggregate(v.Type) || escaped[v] {
+				slot := l.f.NewSlot(variable.name, v.Type.Size(), typeSys.AlignOf(v.Type))
+				variable.addr = l.f.Entry().NewValue(OpAddr, Ptr)
+				variable.addr.Aux = slot
+			}
+			l.vars[v] = variable
+		}
+		l.declare(child, escaped)
+		if ifBlock, ok := child.Value.(*parser.IfBlock); ok && ifBlock.Else {
+			l.declare(ifBlock.ElseBlock, escaped)
+		}
+	}
+}
+
+// escapes 返回函数体中被取地址或在内联汇编中引用的变量，它们不能提升为虚拟寄存器
+func escapes(n *parser.Node) map[any]bool {
+	escaped := make(map[any]bool)
+	var visit func(exp *parser.Expression)
+	visit = func(exp *parser.Expression) {
+		if exp == nil {
+			return
+		}
+		if exp.Unary == "&" && exp.Right != nil && exp.Right.Var != nil && exp.Right.Var.Value == nil {
+			escaped[key(exp.Right.Var)] = true
+		}
+		visit(exp.Left)
+		visit(exp.Right)
+		visit(exp.Index)
+		if exp.Call != nil {
+			// 方法调用传入接收者的地址
+			if exp.Call.ThisVar != nil {
+				escaped[key(exp.Call.ThisVar)] = true
+			}
+			for _, arg := range exp.Call.Args {
+				if arg != nil {
+					visit(arg.Value)
+				}
+			}
+		}
+		if exp.Var != nil {
+			visit(exp.Var.Value)
+			visit(exp.Var.Store)
+		}
+	}
+	var walk func(n *parser.Node)
+	walk = func(n *parser.Node) {
+		for _, child := range n.Children {
+			switch v := child.Value.(type) {
+			case *parser.VarBlock:
+				visit(v.Value)
+				visit(v.Store)
+			case *parser.CallBlock:
+				visit(&parser.Expression{Call: v})
+			case *parser.IfBlock:
+				visit(v.Condition)
+				if v.Else {
+					visit(v.ElseBlock.Value.(*parser.ElseBlock).IfCondition)
+					walk(v.ElseBlock)
+				}
+			case *parser.ForBlock:
+				visit(v.Init)
+				visit(v.Condition)
+				visit(v.Increment)
+			case *parser.WhileBlock:
+				visit(v.Condition)
+			case *parser.SwitchBlock:
+				visit(v.Value)
+			case *parser.ReturnBlock:
+				for _, value := range v.Value {
+					visit(value)
+				}
+			case *parser.Build:
+				for _, tmp := range v.VarMap {
+					escaped[key(tmp)] = true
+				}
+			}
+			walk(child)
+		}
+	}
+	walk(n)
+	return escaped
+}
+
+// key 返回变量引用对应的定义：局部变量为其 *parser.VarBlock，参数为其 *parser.ArgBlock
+func key(v *parser.VarBlock) any {
+	if v.Define != nil {
+		switch def := v.Define.Value.(type) {
+		case *parser.VarBlock:
+			return def
+		case *parser.ArgBlock:
+			return def
+		}
+	}
+	return v
+}
+
+// block 依次降低节点的子语句，跳转之后不可达的语句放在没有前驱的基本块中，最后统一删除
+func (l *lowerer) block(n *parser.Node) {
+	for _, child := range n.Children {
+		if child.Ignore {
+			continue
+		}
+		if l.b == nil {
+			l.b = l.f.NewBlock()
+			l.seal(l.b)
+		}
+		l.stmt(child)
+	}
+}
+
+func (l *lowerer) stmt(n *parser.Node) {
+	switch v := n.Value.(type) {
+	case *parser.VarBlock:
+		l.varBlock(v)
+	case *parser.CallBlock:
+		l.call(v)
+	case *parser.IfBlock:
+		l.ifBlock(n, v)
+	case *parser.ForBlock:
+		if v.Init != nil {
+			l.eval(v.Init)
+		}
+		l.loop(n, v.Condition, v.Increment)
+	case *parser.WhileBlock:
+		l.loop(n, v.Condition, nil)
+	case *parser.SwitchBlock:
+		l.switchBlock(n, v)
+	case *parser.ReturnBlock:
+		l.ret(v)
+	case *parser.BreakBlock:
+		if len(l.loops) == 0 {
+			panic("编译器内部错误: break 不在循环中")
+		}
+		l.jump(l.loops[len(l.loops)-1].brk)
+	case *parser.ContinueBlock:
+		if len(l.loops) == 0 || l.loops[len(l.loops)-1].cont == nil {
+			panic("编译器内部错误: continue 不在循环中")
+		}
+		l.jump(l.loops[len(l.loops)-1].cont)
+	case *parser.Build:
+		if v.Type == "asm" {
+			l.asm(v)
+		}
+	}
+}
+
+// jump 以无条件跳转结束当前基本块，当前基本块已经结束时不做任何事
+func (l *lowerer) jump(to *Block) {
+	if l.b == nil {
+		return
+	}
+	l.b.Kind = BlockPlain
+	l.b.AddEdge(to)
+	l.b = nil
+}
+
+// branch 按条件跳转到 t 或 f，&& 与 || 按短路求值拆分为多个基本块
+func (l *lowerer) branch(cond *parser.Expression, t, f *Block) {
+	switch {
+	case cond == nil:
+		l.jump(t)
+	case cond.IsConst():
+		if cond.Bool {
+			l.jump(t)
+		} else {
+			l.jump(f)
+		}
+	case cond.Separator == "&&" || cond.Separator == "||":
+		mid := l.f.NewBlock()
+		if cond.Separator == "&&" {
+			l.branch(cond.Left, mid, f)
+		} else {
+			l.branch(cond.Left, t, mid)
+		}
+		l.seal(mid)
+		l.b = mid
+		l.branch(cond.Right, t, f)
+	default:
+		c := l.value(cond)
+		l.b.Kind = BlockIf
+		l.b.Control = c
+		l.b.AddEdge(t)
+		l.b.AddEdge(f)
+		l.b = nil
+	}
+}
+
+func (l *lowerer) ifBlock(n *parser.Node, ifBlock *parser.IfBlock) {
+	then, end := l.f.NewBlock(), l.f.NewBlock()
+	els := end
+	if ifBlock.Else {
+		els = l.f.NewBlock()
+	}
+	l.branch(ifBlock.Condition, then, els)
+	l.seal(then)
+	l.b = then
+	l.block(n)
+	l.jump(end)
+	if ifBlock.Else {
+		l.seal(els)
+		l.b = els
+		if cond := ifBlock.ElseBlock.Value.(*parser.ElseBlock).IfCondition; cond != nil {
+			body := l.f.NewBlock()
+			l.branch(cond, body, end)
+			l.seal(body)
+			l.b = body
+		}
+		l.block(ifBlock.ElseBlock)
+		l.jump(end)
+	}
+	l.seal(end)
+	l.b = end
+}
+
+// loop 降低循环：头部检查条件，continue 跳转到增量部分（没有增量时为头部），break 跳出循环
+func (l *lowerer) loop(n *parser.Node, cond, increment *parser.Expression) {
+	header := l.f.NewBlock()
+	l.jump(header)
+	body, exit := l.f.NewBlock(), l.f.NewBlock()
+	cont := header
+	if increment != nil {
+		cont = l.f.NewBlock()
+	}
+	l.b = header
+	l.branch(cond, body, exit)
+	l.seal(body)
+	l.b = body
+	l.loops = append(l.loops, loop{cont: cont, brk: exit})
+	l.block(n)
+	l.loops = l.loops[:len(l.loops)-1]
+	l.jump(cont)
+	if increment != nil {
+		l.seal(cont)
+		l.b = cont
+		l.eval(increment)
+		l.jump(header)
+	}
+	l.seal(header)
+	l.seal(exit)
+	l.b = exit
+}
+
+// switchBlock 降低 switch：按匹配值分派到各分支，没有匹配的分支时进入 default；分支之间不贯穿，break 跳出 switch
+func (l *lowerer) switchBlock(n *parser.Node, switchBlock *parser.SwitchBlock) {
+	dispatch := l.b
+	dispatch.Kind = BlockSwitch
+	dispatch.Control = l.convert(l.value(switchBlock.Value), wordInt())
+	end := l.f.NewBlock()
+	blocks := make(map[*parser.CaseBlock]*Block)
+	for _, caseBlock := range switchBlock.Cases {
+		blocks[caseBlock] = l.f.NewBlock()
+	}
+	seen := make(map[int64]bool)
+	for _, caseBlock := range switchBlock.Cases {
+		if caseBlock.IsDefault {
+			continue
+		}
+		for _, v := range caseBlock.Values {
+			// 重复的分支值以第一个为准
+			if value := v.CaseValue(); !seen[value] {
+				seen[value] = true
+				dispatch.Cases = append(dispatch.Cases, value)
+				dispatch.AddEdge(blocks[caseBlock])
+			}
+		}
+	}
+	if switchBlock.Default != nil {
+		dispatch.AddEdge(blocks[switchBlock.Default])
+	} else {
+		dispatch.AddEdge(end)
+	}
+	l.b = nil
+
+	var cont *Block
+	if len(l.loops) > 0 {
+		cont = l.loops[len(l.loops)-1].cont
+	}
+	for _, caseBlock := range switchBlock.Cases {
+		l.seal(blocks[caseBlock])
+		l.b = blocks[caseBlock]
+		for _, caseNode := range n.Children {
+			if caseNode.Value == caseBlock && !caseNode.Ignore {
+				l.loops = append(l.loops, loop{cont: cont, brk: end})
+				l.block(caseNode)
+				l.loops = l.loops[:len(l.loops)-1]
+			}
+		}
+		l.jump(end)
+	}
+	l.seal(end)
+	l.b = end
+}
+
+// ret 按函数的返回类型计算返回值，聚合类型写入调用方提供的结果地址并返回该地址
+func (l *lowerer) ret(ret *parser.ReturnBlock) {
+	var value *Value
+	if len(ret.Value) > 0 && len(l.fn.Return) > 0 {
+		exp, t := ret.Value[0], l.fn.Return[0]
+		if IsAggregate(t) {
+			l.storeAggregate(exp.Type, t, l.addr(exp), l.result)
+			value = l.result
+		} else {
+			value = l.operand(exp, TypeOf(t))
+		}
+	}
+	l.b.Kind = BlockRet
+	l.b.Control = value
+	l.b = nil
+}
+
+// varBlock 降低变量定义或赋值，没有初始值的定义清零后按结构体字段默认值初始化
+func (l *lowerer) varBlock(v *parser.VarBlock) {
+	if v.IsGlobal {
+		return
+	}
+	if v.IsDefine && v.Value == nil {
+		variable := l.lookup(v)
+		if variable.addr == nil {
+			l.write(variable, l.b, l.numConst(TypeOf(v.Type), 0))
+			return
+		}
+		zero := l.b.NewValue(OpZero, Void, variable.addr)
+		zero.AuxInt = int64(v.Type.Size())
+		l.defaults(variable.addr, v.Type)
+		return
+	}
+	l.assign(v)
+}
+
+// asm 降低内联汇编，按名称顺序传入其中引用的变量的地址
+func (l *lowerer) asm(build *parser.Build) {
+	names := make([]string, 0, len(build.VarMap))
+	for name := range build.VarMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	args := make([]*Value, len(names))
+	for i, name := range names {
+		args[i], _ = l.varAddr(build.VarMap[name])
+	}
+	v := l.b.NewValue(OpAsm, Void, args...)
+	v.Aux = &Asm{Build: build, Names: names}
+}
+
+// lookup 返回变量引用对应的局部变量或参数
+func (l *lowerer) lookup(v *parser.VarBlock) *variable {
+	variable, ok := l.vars[key(v)]
+	if !ok {
+		panic("编译器内部错误: 变量 " + v.Name.String() + " 不是局部变量")
+	}
+	return variable
+}
+
+// promoted 返回提升为虚拟寄存器的变量，变量引用指向接收者、全局变量、字段或栈上的变量时为空
+func (l *lowerer) promoted(v *parser.VarBlock) *variable {
+	if v.IsSelf(l.fn) || data.IsGlobal(v) || v.Name.IsPath() {
+		return nil
+	}
+	if variable := l.lookup(v); variable.addr == nil {
+		return variable
+	}
+	return nil
+}
+
+// varAddr 返回存放在内存中的变量（或其字段）的地址与类型
+func (l *lowerer) varAddr(v *parser.VarBlock) (*Value, typeSys.Type) {
+	var addr *Value
+	var t typeSys.Type
+	switch {
+	case v.IsSelf(l.fn):
+		addr, t = l.self, l.fn.Class
+	case data.IsGlobal(v):
+		def := data.Define(v)
+		addr, t = l.b.NewValue(OpGlobal, Ptr), def.Type
+		addr.Aux = def
+	default:
+		variable := l.lookup(v)
+		if variable.addr == nil {
+			panic("编译器内部错误: 变量 " + v.Name.String() + " 没有存放在内存中")
+		}
+		addr, t = variable.addr, variable.typ
+	}
+	for _, name := range v.Name[1:] {
+		st, ok := t.(*typeSys.StructType)
+		if !ok {
+			panic("编译器内部错误: " + t.Type() + " 不是结构体")
+		}
+		field := st.Field(name)
+		if field == nil {
+			panic("编译器内部错误: 结构体 " + t.Type() + " 没有字段 " + name)
+		}
+		addr, t = l.offset(addr, int64(field.Offset)), field.Type
+	}
+	return addr, t
+}
+
+// assign 降低赋值，包括通过指针或下标的赋值；先计算值，再计算目标地址
+func (l *lowerer) assign(v *parser.VarBlock) {
+	if v.Store != nil {
+		t := v.Store.Type
+		if IsAggregate(t) {
+			src := l.addr(v.Value)
+			l.storeAggregate(v.Value.Type, t, src, l.addr(v.Store))
+			return
+		}
+		value := l.operand(v.Value, TypeOf(t))
+		l.b.NewValue(OpStore, Void, l.addr(v.Store), value)
+		return
+	}
+	if variable := l.promoted(v); variable != nil {
+		l.write(variable, l.b, l.operand(v.Value, TypeOf(variable.typ)))
+		return
+	}
+	if v.IsSelf(l.fn) && !v.Name.IsPath() {
+		panic("编译器内部错误: 不能给 self 赋值")
+	}
+	if t := l.varType(v); IsAggregate(t) {
+		src := l.addr(v.Value)
+		dst, _ := l.varAddr(v)
+		l.storeAggregate(v.Value.Type, t, src, dst)
+		return
+	}
+	value := l.operand(v.Value, TypeOf(v.Type))
+	dst, t := l.varAddr(v)
+	if TypeOf(t) != value.Type {
+		value = l.convert(value, TypeOf(t))
+	}
+	l.b.NewValue(OpStore, Void, dst, value)
+}
+
+// varType 返回变量引用（包括字段访问）的类型
+func (l *lowerer) varType(v *parser.VarBlock) typeSys.Type {
+	if v.IsSelf(l.fn) && !v.Name.IsPath() {
+		return l.fn.Class
+	}
+	return v.Type
+}
+
+// storeAggregate 将地址 src 处类型为 from 的聚合值作为类型 to 写入 dst：结构体转换为接口时写入 (数据地址, 虚表)，
+// 数组转换为切片时写入 (数据地址, 长度)，其余按类型大小复制
+func (l *lowerer) storeAggregate(from, to typeSys.Type, src, dst *Value) {
+	switch to := to.(type) {
+	case *typeSys.InterfaceType:
+		if st, ok := from.(*typeSys.StructType); ok && !st.IsPointer() {
+			vtable := l.b.NewValue(OpVTable, Ptr)
+			vtable.Aux = VTable{Struct: st, Iface: to}
+			l.b.NewValue(OpStore, Void, dst, src)
+			l.b.NewValue(OpStore, Void, l.offset(dst, int64(typeSys.PtrSize)), vtable)
+			return
+		}
+	case *typeSys.SliceType:
+		if array, ok := from.(*typeSys.ArrayType); ok {
+			l.b.NewValue(OpStore, Void, dst, src)
+			l.b.NewValue(OpStore, Void, l.offset(dst, int64(typeSys.PtrSize)), l.numConst(Ptr, float64(array.Len)))
+			return
+		}
+	}
+	move := l.b.NewValue(OpMove, Void, dst, src)
+	move.AuxInt = int64(to.Size())
+}
+
+// storeValue 计算 exp 并按类型 t 写入 addr
+func (l *lowerer) storeValue(exp *parser.Expression, t typeSys.Type, addr *Value) {
+	if IsAggregate(t) {
+		l.storeAggregate(exp.Type, t, l.addr(exp), addr)
+		return
+	}
+	l.b.NewValue(OpStore, Void, addr, l.operand(exp, TypeOf(t)))
+}
+
+// defaults 写入结构体字段的默认值（包括嵌套结构体）
+func (l *lowerer) defaults(addr *Value, t typeSys.Type) {
+	st, ok := t.(*typeSys.StructType)
+	if !ok {
+		return
+	}
+	for _, field := range st.StructFields {
+		if def, ok := field.Default.(*parser.Expression); ok && def != nil {
+			l.storeValue(def, field.Type, l.offset(addr, int64(field.Offset)))
+		}
+		l.defaults(l.offset(addr, int64(field.Offset)), field.Type)
+	}
+}
+
+// temp 在栈上为类型 t 分配临时空间，返回其地址
+func (l *lowerer) temp(t typeSys.Type) *Value {
+	addr := l.b.NewValue(OpAddr, Ptr)
+	addr.Aux = l.f.NewSlot("", t.Size(), typeSys.AlignOf(t))
+	return addr
+}
+
+// wordInt 返回与字长相同的有符号整数类型
+func wordInt() Type {
+	if typeSys.PtrSize == 8 {
+		return I64
+	}
+	return I32
+}
@@ -0,0 +1,144 @@
+package ir
+
+import (
+	"cuteify/parser"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// String 返回程序的文本形式：全局变量，然后依次为各个函数
+func (p *Program) String() string {
+	var sb strings.Builder
+	for _, g := range p.Globals {
+		fmt.Fprintf(&sb, "global %s %s\n", g.Name, TypeOf(g.Type))
+	}
+	for i, f := range p.Funcs {
+		if i > 0 || len(p.Globals) > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(f.String())
+	}
+	return sb.String()
+}
+
+// String 返回函数的文本形式，例如：
+//
+//	func add(a i32, b i32) i32 {
+//	b0:
+//	  v0 = arg i32 #0
+//	  v1 = arg i32 #1
+//	  v2 = add i32 v0 v1
+//	  ret v2
+//	}
+func (f *Func) String() string {
+	var sb strings.Builder
+	params := make([]string, len(f.Params))
+	for i, p := range f.Params {
+		params[i] = p.Name + " " + p.Type.String()
+		if p.Agg {
+			params[i] += fmt.Sprintf(" [%d]", p.Size)
+		}
+	}
+	fmt.Fprintf(&sb, "func %s(%s)", f.Name, strings.Join(params, ", "))
+	if f.Result != Void {
+		fmt.Fprintf(&sb, " %s", f.Result)
+	}
+	sb.WriteString(" {\n")
+	for _, s := range f.Slots {
+		fmt.Fprintf(&sb, "  %s size %d align %d", s, s.Size, s.Align)
+		if s.Name != "" {
+			fmt.Fprintf(&sb, " ; %s", s.Name)
+		}
+		sb.WriteString("\n")
+	}
+	for _, b := range f.Blocks {
+		sb.WriteString(b.LongString())
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// LongString 返回基本块的文本形式：标签与前驱、各条指令以及结束的跳转
+func (b *Block) LongString() string {
+	var sb strings.Builder
+	sb.WriteString(b.String() + ":")
+	if len(b.Preds) > 0 {
+		preds := make([]string, len(b.Preds))
+		for i, pred := range b.Preds {
+			preds[i] = pred.String()
+		}
+		sb.WriteString(" ; preds " + strings.Join(preds, " "))
+	}
+	sb.WriteString("\n")
+	for _, v := range b.Values {
+		sb.WriteString("  " + v.LongString() + "\n")
+	}
+	sb.WriteString("  ")
+	switch b.Kind {
+	case BlockPlain:
+		fmt.Fprintf(&sb, "jmp %s", b.Succs[0])
+	case BlockIf:
+		fmt.Fprintf(&sb, "if %s %s %s", b.Control, b.Succs[0], b.Succs[1])
+	case BlockSwitch:
+		fmt.Fprintf(&sb, "switch %s", b.Control)
+		for i, c := range b.Cases {
+			fmt.Fprintf(&sb, " %d:%s", c, b.Succs[i])
+		}
+		fmt.Fprintf(&sb, " default:%s", b.Succs[len(b.Succs)-1])
+	case BlockRet:
+		sb.WriteString("ret")
+		if b.Control != nil {
+			sb.WriteString(" " + b.Control.String())
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// LongString 返回指令的文本形式，如 v3 = add i32 v1 v2
+func (v *Value) LongString() string {
+	var sb strings.Builder
+	if v.Type != Void {
+		fmt.Fprintf(&sb, "%s = %s %s", v, v.Op, v.Type)
+	} else {
+		sb.WriteString(v.Op.String())
+	}
+	switch v.Op {
+	case OpConst:
+		if v.Type.IsFloat() {
+			sb.WriteString(" " + strconv.FormatFloat(math.Float64frombits(uint64(v.AuxInt)), 'g', -1, 64))
+		} else {
+			sb.WriteString(" " + strconv.FormatInt(v.AuxInt, 10))
+		}
+	case OpString:
+		sb.WriteString(" " + strconv.Quote(v.Aux.(string)))
+	case OpGlobal:
+		sb.WriteString(" " + v.Aux.(*parser.VarBlock).Name.String())
+	case OpVTable:
+		vt := v.Aux.(VTable)
+		fmt.Fprintf(&sb, " %s:%s", vt.Struct.Type(), vt.Iface.Type())
+	case OpAddr:
+		sb.WriteString(" " + v.Aux.(*Slot).String())
+	case OpArg, OpArgAddr:
+		fmt.Fprintf(&sb, " #%d", v.AuxInt)
+	case OpPhi:
+		for i, arg := range v.Args {
+			fmt.Fprintf(&sb, " %s:%s", arg, v.Block.Preds[i])
+		}
+		return sb.String()
+	}
+	for _, arg := range v.Args {
+		sb.WriteString(" " + arg.String())
+	}
+	switch v.Op {
+	case OpMove, OpZero:
+		fmt.Fprintf(&sb, " [%d]", v.AuxInt)
+	case OpCall, OpCallInd:
+		sb.WriteString(" ; " + v.Aux.(*parser.FuncBlock).Name.String())
+	case OpAsm:
+		sb.WriteString(" ; " + strings.Join(v.Aux.(*Asm).Names, " "))
+	}
+	return sb.String()
+}
@@ -0,0 +1,329 @@
+package ir
+
+// 提升的变量按 Braun 等人的方法直接构造 SSA：每个基本块记录变量的当前值，读取时沿前驱查找，
+// 多个前驱汇合处插入 phi；前驱尚未全部确定（未封闭）的基本块先插入没有参数的 phi，封闭时再补齐。
+
+// write 记录变量在基本块 b 中的当前值
+func (l *lowerer) write(v *variable, b *Block, value *Value) {
+	defs, ok := l.defs[b]
+	if !ok {
+		defs = make(map[*variable]*Value)
+		l.defs[b] = defs
+	}
+	defs[v] = value
+}
+
+// read 返回变量在基本块 b 中的当前值
+func (l *lowerer) read(v *variable, b *Block) *Value {
+	if value, ok := l.defs[b][v]; ok {
+		return value
+	}
+	var value *Value
+	switch {
+	case !l.sealed[b]:
+		value = b.NewPhi(TypeOf(v.typ))
+		l.incomplete[b] = append(l.incomplete[b], pendingPhi{v: v, phi: value})
+	case len(b.Preds) == 0:
+		// 不可达的基本块中读取未赋值的变量，按零值处理
+		value = l.zero(TypeOf(v.typ))
+	case len(b.Preds) == 1:
+		value = l.read(v, b.Preds[0])
+	default:
+		phi := b.NewPhi(TypeOf(v.typ))
+		// 先记录 phi 以打断循环中的递归查找
+		l.write(v, b, phi)
+		value = l.addPhiArgs(v, phi)
+	}
+	l.write(v, b, value)
+	return value
+}
+
+// addPhiArgs 按前驱补齐 phi 的参数，phi 只有唯一的来源时改为该来源的复制
+func (l *lowerer) addPhiArgs(v *variable, phi *Value) *Value {
+	for _, pred := range phi.Block.Preds {
+		phi.Args = append(phi.Args, l.read(v, pred))
+	}
+	var same *Value
+	for _, arg := range phi.Args {
+		arg = resolve(arg)
+		if arg == same || arg == phi {
+			continue
+		}
+		if same != nil {
+			return phi
+		}
+		same = arg
+	}
+	if same == nil {
+		// 所有参数都是 phi 自身：只在不可达的循环中出现
+		same = l.zero(phi.Type)
+	}
+	phi.Op, phi.Args = OpCopy, []*Value{same}
+	return same
+}
+
+// seal 在基本块的前驱全部确定后补齐其中等待的 phi
+func (l *lowerer) seal(b *Block) {
+	for _, pending := range l.incomplete[b] {
+		l.addPhiArgs(pending.v, pending.phi)
+	}
+	delete(l.incomplete, b)
+	l.sealed[b] = true
+}
+
+// zero 在入口块中生成类型为 t 的零值，入口块支配所有基本块
+func (l *lowerer) zero(t Type) *Value {
+	return l.f.Entry().NewValue(OpConst, t)
+}
+
+// resolve 沿复制找到实际的值
+func resolve(v *Value) *Value {
+	for v.Op == OpCopy {
+		v = v.Args[0]
+	}
+	return v
+}
+
+// finish 整理构造完成的函数：删除不可达的基本块与复制，化简只有唯一来源的 phi，删除没有使用的无副作用指令，
+// 合并只以无条件跳转相连的基本块，最后按逆后序排列基本块并重新编号
+func finish(f *Func) {
+	removeUnreachable(f)
+	for simplifyPhis(f) {
+	}
+	removeCopies(f)
+	deadCode(f)
+	mergeBlocks(f)
+	order := postorder(f)
+	f.Blocks = f.Blocks[:0]
+	for i := len(order) - 1; i >= 0; i-- {
+		f.Blocks = append(f.Blocks, order[i])
+	}
+	renumber(f)
+}
+
+// removeUnreachable 删除从入口不可达的基本块，并删除可达基本块中来自它们的前驱与对应的 phi 参数
+func removeUnreachable(f *Func) {
+	reachable := make(map[*Block]bool)
+	for _, b := range postorder(f) {
+		reachable[b] = true
+	}
+	blocks := f.Blocks[:0]
+	for _, b := range f.Blocks {
+		if reachable[b] {
+			blocks = append(blocks, b)
+		}
+	}
+	f.Blocks = blocks
+	for _, b := range f.Blocks {
+		preds := b.Preds[:0]
+		for i, pred := range b.Preds {
+			if !reachable[pred] {
+				for _, v := range b.Values {
+					if v.Op == OpPhi {
+						v.Args[i] = nil
+					}
+				}
+				continue
+			}
+			preds = append(preds, pred)
+		}
+		b.Preds = preds
+		for _, v := range b.Values {
+			if v.Op != OpPhi {
+				continue
+			}
+			args := v.Args[:0]
+			for _, arg := range v.Args {
+				if arg != nil {
+					args = append(args, arg)
+				}
+			}
+			v.Args = args
+		}
+	}
+}
+
+// simplifyPhis 将只有唯一来源的 phi 改为复制，返回是否有改动
+func simplifyPhis(f *Func) (changed bool) {
+	for _, b := range f.Blocks {
+		for _, v := range b.Values {
+			if v.Op != OpPhi {
+				continue
+			}
+			var same *Value
+			trivial := true
+			for _, arg := range v.Args {
+				arg = resolve(arg)
+				if arg == same || arg == v {
+					continue
+				}
+				if same != nil {
+					trivial = false
+					break
+				}
+				same = arg
+			}
+			if trivial && same != nil {
+				v.Op, v.Args = OpCopy, []*Value{same}
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// removeCopies 将对复制的使用替换为实际的值并删除复制
+func removeCopies(f *Func) {
+	for _, b := range f.Blocks {
+		for _, v := range b.Values {
+			for i, arg := range v.Args {
+				v.Args[i] = resolve(arg)
+			}
+		}
+		if b.Control != nil {
+			b.Control = resolve(b.Control)
+		}
+	}
+	for _, b := range f.Blocks {
+		values := b.Values[:0]
+		for _, v := range b.Values {
+			if v.Op != OpCopy {
+				values = append(values, v)
+			}
+		}
+		b.Values = values
+	}
+}
+
+// deadCode 删除结果没有被使用的无副作用指令
+func deadCode(f *Func) {
+	live := make(map[*Value]bool)
+	var work []*Value
+	mark := func(v *Value) {
+		if v != nil && !live[v] {
+			live[v] = true
+			work = append(work, v)
+		}
+	}
+	for _, b := range f.Blocks {
+		for _, v := range b.Values {
+			if v.Op.HasSideEffects() {
+				mark(v)
+			}
+		}
+		mark(b.Control)
+	}
+	for len(work) > 0 {
+		v := work[len(work)-1]
+		work = work[:len(work)-1]
+		for _, arg := range v.Args {
+			mark(arg)
+		}
+	}
+	for _, b := range f.Blocks {
+		values := b.Values[:0]
+		for _, v := range b.Values {
+			if live[v] {
+				values = append(values, v)
+			}
+		}
+		b.Values = values
+	}
+}
+
+// mergeBlocks 将唯一前驱以无条件跳转结束的基本块并入前驱，被并入的基本块从入口不可达，在排序时删除
+func mergeBlocks(f *Func) {
+	for _, b := range f.Blocks {
+		// 已经并入前驱的基本块没有前驱与后继
+		for b.Kind == BlockPlain && len(b.Succs) > 0 {
+			s := b.Succs[0]
+			if s == b || s == f.Entry() || len(s.Preds) != 1 {
+				break
+			}
+			for _, v := range s.Values {
+				v.Block = b
+			}
+			b.Values = append(b.Values, s.Values...)
+			b.Kind, b.Control, b.Cases, b.Succs = s.Kind, s.Control, s.Cases, s.Succs
+			for _, succ := range s.Succs {
+				for i, pred := range succ.Preds {
+					if pred == s {
+						succ.Preds[i] = b
+					}
+				}
+			}
+			s.Values, s.Succs, s.Preds = nil, nil, nil
+		}
+	}
+}
+
+// postorder 返回从入口可达的基本块的后序，后继按逆序访问，使逆后序中 Succs[0] 一侧排在前面
+func postorder(f *Func) []*Block {
+	var order []*Block
+	seen := make(map[*Block]bool)
+	var visit func(b *Block)
+	visit = func(b *Block) {
+		seen[b] = true
+		for i := len(b.Succs) - 1; i >= 0; i-- {
+			if !seen[b.Succs[i]] {
+				visit(b.Succs[i])
+			}
+		}
+		order = append(order, b)
+	}
+	visit(f.Entry())
+	return order
+}
+
+// renumber 按基本块与指令的顺序重新编号
+func renumber(f *Func) {
+	f.nextBlock, f.nextValue = 0, 0
+	for _, b := range f.Blocks {
+		b.ID = f.nextBlock
+		f.nextBlock++
+		for _, v := range b.Values {
+			v.ID = f.nextValue
+			f.nextValue++
+		}
+	}
+}
+
+// SplitCriticalEdges 在有多个后继的基本块与有多个前驱的基本块之间插入空的基本块，
+// 便于后端在前驱末尾为 phi 复制参数；插入后重新按逆后序排列基本块并编号
+func SplitCriticalEdges(f *Func) {
+	for _, b := range f.Blocks {
+		if len(b.Succs) < 2 {
+			continue
+		}
+		// 同一对基本块之间可能有多条边，按出现的次序对应
+		seen := make(map[*Block]int)
+		for i, succ := range b.Succs {
+			n := seen[succ]
+			seen[succ]++
+			if len(succ.Preds) < 2 {
+				continue
+			}
+			mid := f.NewBlock()
+			mid.Kind = BlockPlain
+			mid.Preds = []*Block{b}
+			mid.Succs = []*Block{succ}
+			b.Succs[i] = mid
+			for j, pred := range succ.Preds {
+				if pred != b {
+					continue
+				}
+				if n == 0 {
+					succ.Preds[j] = mid
+					break
+				}
+				n--
+			}
+		}
+	}
+	order := postorder(f)
+	f.Blocks = f.Blocks[:0]
+	for i := len(order) - 1; i >= 0; i-- {
+		f.Blocks = append(f.Blocks, order[i])
+	}
+	renumber(f)
+}
@@ -0,0 +1,371 @@
+package ir
+
+import (
+	"cuteify/parser"
+	"fmt"
+)
+
+// Verify 检查函数的结构与类型：基本块的前驱与后继一致且都从入口可达，phi 位于基本块开头且参数与前驱一一对应，
+// 每个值的定义支配其使用，各运算的参数与结果类型相符。返回发现的第一个错误
+func Verify(f *Func) error {
+	if len(f.Blocks) == 0 {
+		return fmt.Errorf("%s: 没有基本块", f.Name)
+	}
+	if len(f.Entry().Preds) > 0 {
+		return fmt.Errorf("%s: 入口块 %s 有前驱", f.Name, f.Entry())
+	}
+	blocks := make(map[*Block]bool)
+	for _, b := range f.Blocks {
+		if blocks[b] {
+			return fmt.Errorf("%s: 基本块 %s 重复出现", f.Name, b)
+		}
+		blocks[b] = true
+	}
+	slots := make(map[*Slot]bool)
+	for _, s := range f.Slots {
+		slots[s] = true
+	}
+	for _, b := range f.Blocks {
+		if err := verifyEdges(b, blocks); err != nil {
+			return fmt.Errorf("%s: %s: %v", f.Name, b, err)
+		}
+	}
+	idom := dominators(f)
+	for _, b := range f.Blocks {
+		if b != f.Entry() && idom[b] == nil {
+			return fmt.Errorf("%s: %s 从入口不可达", f.Name, b)
+		}
+	}
+	pos := make(map[*Value]int)
+	for _, b := range f.Blocks {
+		for i, v := range b.Values {
+			if _, ok := pos[v]; ok {
+				return fmt.Errorf("%s: %s 重复出现", f.Name, v)
+			}
+			if v.Block != b {
+				return fmt.Errorf("%s: %s 所属的基本块不是 %s", f.Name, v, b)
+			}
+			pos[v] = i
+		}
+	}
+	// dominates 报告 def 的定义是否支配位于基本块 b 第 i 条指令处的使用
+	dominates := func(def *Value, b *Block, i int) bool {
+		if def.Block == b {
+			return pos[def] < i
+		}
+		for d := idom[b]; d != nil; d = idom[d] {
+			if d == def.Block {
+				return true
+			}
+			if d == f.Entry() {
+				break
+			}
+		}
+		return false
+	}
+	for _, b := range f.Blocks {
+		for i, v := range b.Values {
+			if v.Op == OpPhi && i > 0 && b.Values[i-1].Op != OpPhi {
+				return fmt.Errorf("%s: %s 不在基本块开头", f.Name, v)
+			}
+			for j, arg := range v.Args {
+				if _, ok := pos[arg]; !ok {
+					return fmt.Errorf("%s: %s 的参数 %s 不在函数中", f.Name, v, arg)
+				}
+				use, at := b, i
+				if v.Op == OpPhi {
+					use, at = b.Preds[j], len(b.Preds[j].Values)
+				}
+				if !dominates(arg, use, at) {
+					return fmt.Errorf("%s: %s 的参数 %s 的定义不支配其使用", f.Name, v, arg)
+				}
+			}
+			if err := verifyValue(f, v, slots); err != nil {
+				return fmt.Errorf("%s: %s: %v", f.Name, v.LongString(), err)
+			}
+		}
+		if c := b.Control; c != nil {
+			if _, ok := pos[c]; !ok {
+				return fmt.Errorf("%s: %s 的控制值 %s 不在函数中", f.Name, b, c)
+			}
+			if !dominates(c, b, len(b.Values)) {
+				return fmt.Errorf("%s: %s 的控制值 %s 的定义不支配其使用", f.Name, b, c)
+			}
+		}
+	}
+	return nil
+}
+
+// verifyEdges 检查基本块的跳转方式与后继数量相符，且前驱与后继互相对应
+func verifyEdges(b *Block, blocks map[*Block]bool) error {
+	want := 0
+	switch b.Kind {
+	case BlockPlain:
+		want = 1
+	case BlockIf:
+		want = 2
+		if b.Control == nil || b.Control.Type != Bool {
+			return fmt.Errorf("if 的条件不是 bool")
+		}
+	case BlockSwitch:
+		want = len(b.Cases) + 1
+		if b.Control == nil || !b.Control.Type.IsInt() {
+			return fmt.Errorf("switch 的匹配值不是整数")
+		}
+	case BlockRet:
+		if b.Control != nil && b.Control.Type != b.Func.Result {
+			return fmt.Errorf("返回值的类型 %s 与函数的返回类型 %s 不符", b.Control.Type, b.Func.Result)
+		}
+	}
+	if len(b.Succs) != want {
+		return fmt.Errorf("%s 块有 %d 个后继", b.Kind, len(b.Succs))
+	}
+	if b.Kind == BlockPlain && b.Control != nil {
+		return fmt.Errorf("无条件跳转带有控制值")
+	}
+	for _, succ := range b.Succs {
+		if !blocks[succ] {
+			return fmt.Errorf("后继 %s 不在函数中", succ)
+		}
+		if count(succ.Preds, b) != count(b.Succs, succ) {
+			return fmt.Errorf("与后继 %s 的边不对应", succ)
+		}
+	}
+	for _, pred := range b.Preds {
+		if !blocks[pred] {
+			return fmt.Errorf("前驱 %s 不在函数中", pred)
+		}
+		if count(pred.Succs, b) != count(b.Preds, pred) {
+			return fmt.Errorf("与前驱 %s 的边不对应", pred)
+		}
+	}
+	return nil
+}
+
+func count(blocks []*Block, b *Block) (n int) {
+	for _, x := range blocks {
+		if x == b {
+			n++
+		}
+	}
+	return n
+}
+
+// verifyValue 检查指令的参数个数与类型
+func verifyValue(f *Func, v *Value, slots map[*Slot]bool) error {
+	args := func(n int) error {
+		if len(v.Args) != n {
+			return fmt.Errorf("需要 %d 个参数，实际为 %d 个", n, len(v.Args))
+		}
+		return nil
+	}
+	typed := func(want Type) error {
+		if v.Type != want {
+			return fmt.Errorf("结果类型应为 %s", want)
+		}
+		return nil
+	}
+	switch {
+	case v.Op == OpConst:
+		if v.Type == Void {
+			return fmt.Errorf("常量没有类型")
+		}
+		return args(0)
+	case v.Op == OpString || v.Op == OpGlobal || v.Op == OpVTable || v.Op == OpAddr:
+		if err := args(0); err != nil {
+			return err
+		}
+		ok := false
+		switch aux := v.Aux.(type) {
+		case string:
+			ok = v.Op == OpString
+		case *parser.VarBlock:
+			ok = v.Op == OpGlobal
+		case VTable:
+			ok = v.Op == OpVTable && aux.Struct != nil && aux.Iface != nil
+		case *Slot:
+			ok = v.Op == OpAddr && slots[aux]
+		}
+		if !ok {
+			return fmt.Errorf("Aux %T 不符", v.Aux)
+		}
+		return typed(Ptr)
+	case v.Op == OpArg || v.Op == OpArgAddr:
+		if v.AuxInt < 0 || int(v.AuxInt) >= len(f.Params) {
+			return fmt.Errorf("参数下标越界")
+		}
+		p := f.Params[v.AuxInt]
+		if v.Op == OpArg && p.Agg {
+			return fmt.Errorf("按值传入的聚合参数只能取地址")
+		}
+		if v.Op == OpArg {
+			return typed(p.Type)
+		}
+		return typed(Ptr)
+	case v.Op == OpPhi:
+		if err := args(len(v.Block.Preds)); err != nil {
+			return err
+		}
+		for _, arg := range v.Args {
+			if arg.Type != v.Type {
+				return fmt.Errorf("参数 %s 的类型为 %s", arg, arg.Type)
+			}
+		}
+	case v.Op == OpCopy:
+		if err := args(1); err != nil {
+			return err
+		}
+		return typed(v.Args[0].Type)
+	case v.Op.IsBinary() || v.Op.IsCompare():
+		if err := args(2); err != nil {
+			return err
+		}
+		t := v.Args[0].Type
+		if v.Args[1].Type != t {
+			return fmt.Errorf("两侧类型 %s 与 %s 不同", t, v.Args[1].Type)
+		}
+		if v.Op.IsCompare() {
+			return typed(Bool)
+		}
+		if t == Void || t == Bool {
+			return fmt.Errorf("%s 不能参与算术运算", t)
+		}
+		if t.IsFloat() && (v.Op == OpMod || v.Op == OpAnd || v.Op == OpOr || v.Op == OpShl || v.Op == OpShr) {
+			return fmt.Errorf("浮点数不支持 %s", v.Op)
+		}
+		return typed(t)
+	case v.Op == OpConvert:
+		if err := args(1); err != nil {
+			return err
+		}
+		if v.Type == Void || v.Args[0].Type == Void {
+			return fmt.Errorf("不能转换 void")
+		}
+	case v.Op == OpLoad:
+		if err := args(1); err != nil {
+			return err
+		}
+		if v.Type == Void {
+			return fmt.Errorf("载入的类型为 void")
+		}
+		return ptrArgs(v, 1)
+	case v.Op == OpStore:
+		if err := args(2); err != nil {
+			return err
+		}
+		if v.Args[1].Type == Void {
+			return fmt.Errorf("写入的值没有类型")
+		}
+		return ptrArgs(v, 1)
+	case v.Op == OpMove || v.Op == OpZero:
+		n := 2
+		if v.Op == OpZero {
+			n = 1
+		}
+		if err := args(n); err != nil {
+			return err
+		}
+		if v.AuxInt <= 0 {
+			return fmt.Errorf("大小应为正数")
+		}
+		return ptrArgs(v, n)
+	case v.Op == OpCall || v.Op == OpCallInd:
+		fn, ok := v.Aux.(*parser.FuncBlock)
+		if !ok {
+			return fmt.Errorf("没有被调函数的声明")
+		}
+		callArgs := v.Args
+		if v.Op == OpCallInd {
+			if len(callArgs) == 0 || callArgs[0].Type != Ptr {
+				return fmt.Errorf("间接调用的地址不是 ptr")
+			}
+			callArgs = callArgs[1:]
+		}
+		params := Params(fn)
+		if len(callArgs) != len(params) {
+			return fmt.Errorf("需要 %d 个实参，实际为 %d 个", len(params), len(callArgs))
+		}
+		for i, p := range params {
+			if callArgs[i].Type != p.Type {
+				return fmt.Errorf("实参 %s 的类型为 %s，形参 %s 的类型为 %s", callArgs[i], callArgs[i].Type, p.Name, p.Type)
+			}
+		}
+		return typed(ResultType(fn))
+	case v.Op == OpAsm:
+		asm, ok := v.Aux.(*Asm)
+		if !ok {
+			return fmt.Errorf("没有内联汇编")
+		}
+		if err := args(len(asm.Names)); err != nil {
+			return err
+		}
+		return ptrArgs(v, len(v.Args))
+	case v.Op == OpBoundsCheck:
+		if err := args(2); err != nil {
+			return err
+		}
+		if v.Args[0].Type != Ptr || v.Args[1].Type != Ptr {
+			return fmt.Errorf("下标与长度应为 ptr")
+		}
+	default:
+		return fmt.Errorf("未知的运算 %d", v.Op)
+	}
+	return nil
+}
+
+// ptrArgs 检查前 n 个参数都是地址
+func ptrArgs(v *Value, n int) error {
+	for _, arg := range v.Args[:n] {
+		if arg.Type != Ptr {
+			return fmt.Errorf("参数 %s 不是地址", arg)
+		}
+	}
+	return nil
+}
+
+// dominators 按 Cooper、Harvey 与 Kennedy 的迭代方法计算直接支配者，入口的直接支配者为自身，不可达的基本块没有
+func dominators(f *Func) map[*Block]*Block {
+	order := postorder(f)
+	index := make(map[*Block]int, len(order))
+	for i, b := range order {
+		index[b] = i
+	}
+	entry := f.Entry()
+	idom := map[*Block]*Block{entry: entry}
+	intersect := func(a, b *Block) *Block {
+		for a != b {
+			for index[a] < index[b] {
+				a = idom[a]
+			}
+			for index[b] < index[a] {
+				b = idom[b]
+			}
+		}
+		return a
+	}
+	for changed := true; changed; {
+		changed = false
+		for i := len(order) - 1; i >= 0; i-- {
+			b := order[i]
+			if b == entry {
+				continue
+			}
+			var dom *Block
+			for _, pred := range b.Preds {
+				if idom[pred] == nil {
+					continue
+				}
+				if dom == nil {
+					dom = pred
+				} else {
+					dom = intersect(pred, dom)
+				}
+			}
+			if idom[b] != dom {
+				idom[b] = dom
+				changed = true
+			}
+		}
+	}
+	return idom
+}
@@ -0,0 +1,33 @@
+package compile
+
+import (
+	"cuteify/compile/ir"
+	"cuteify/parser"
+	"cuteify/utils"
+)
+
+// compileIR 经中间表示编译整个程序：语法树先由 ir.Lower 降低为 SSA 形式，再由架构的 IR 后端逐个生成函数。
+// 函数标签、入口与数据段的输出与直接从语法树生成时相同
+func (c *Compiler) compileIR(root *parser.Node) string {
+	backend := NewBackend(GoArch, c.Ctx)
+	utils.Count = 0
+	code := c.syntax().Header(root)
+	prog := ir.Lower(root, c.BoundsCheck)
+	if !c.NoInline {
+		prog.Inline(ir.InlineBudget)
+	}
+	for _, g := range prog.Globals {
+		c.Ctx.Data.AddGlobal(g)
+	}
+	for _, f := range prog.Funcs {
+		code += c.syntax().FuncLabel(f.Decl, funcName(f.Decl), f.Links)
+		utils.Count++
+		code += backend.Func(f)
+		utils.Count--
+		code += c.syntax().EndFunc(f.Decl)
+	}
+	if c.hasMainFunction(root) {
+		code += c.syntax().StartEntry()
+	}
+	return code + backend.Data()
+}
@@ -0,0 +1,191 @@
+// Package peephole 对生成的 32 位 x86 汇编（NASM 语法，即 utils.Format 输出的文本）做窥孔优化：
+// 逐行解析为指令、标签与其他行（注释、伪指令、数据），按规则表反复改写相邻的指令直到不再变化。
+// 规则只删除或合并不影响程序行为的指令；标签、伪指令与段切换是屏障，跨过它们的指令不视为相邻，
+// .text 之外的段原样保留。未被改写的行保持原样输出。
+package peephole
+
+import (
+	"fmt"
+	"strings"
+)
+
+// line 汇编代码中的一行
+type line struct {
+	text   string   // 输出的文本（含换行），改写后重新生成
+	indent string   // 行首的缩进
+	label  string   // 标签名（不含冒号），不是标签时为空
+	op     string   // 小写的助记符，不是 .text 中的指令时为空
+	args   []string // 操作数，连续的空白合并为一个空格
+	dir    bool     // 伪指令或 .text 之外的行
+	dead   bool     // 已删除
+}
+
+// Stats 优化的统计
+type Stats struct {
+	Before  int            // 优化前的指令数
+	Removed map[string]int // 各规则删除的指令数
+}
+
+// Total 返回删除的指令总数
+func (s *Stats) Total() (n int) {
+	for _, k := range s.Removed {
+		n += k
+	}
+	return
+}
+
+// String 按规则表的顺序列出各规则删除的指令数，如 "删除 5/120 条指令：self-move 2，push-pop 3"
+func (s *Stats) String() string {
+	var parts []string
+	for _, r := range rules {
+		if n := s.Removed[r.name]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", r.name, n))
+		}
+	}
+	text := fmt.Sprintf("删除 %d/%d 条指令", s.Total(), s.Before)
+	if len(parts) > 0 {
+		text += "：" + strings.Join(parts, "，")
+	}
+	return text
+}
+
+// rule 一条改写规则：apply 尝试在第 i 行的指令处改写，返回删除的指令数，不适用时返回 0
+type rule struct {
+	name  string
+	apply func(p *pass, i int) int
+}
+
+// rules 规则表，每轮按顺序在每条指令处尝试
+var rules = []rule{
+	{"self-move", selfMove},
+	{"redundant-move", redundantMove},
+	{"dead-move", deadMove},
+	{"push-pop", pushPop},
+	{"zero-op", zeroOp},
+	{"jump-next", jumpNext},
+	{"unreachable", unreachable},
+}
+
+// pass 一次优化的状态
+type pass struct {
+	lines []*line
+}
+
+// Optimize 优化汇编代码，返回优化后的代码与统计
+func Optimize(code string) (string, *Stats) {
+	p := &pass{lines: parse(code)}
+	stats := &Stats{Removed: make(map[string]int)}
+	for _, l := range p.lines {
+		if l.op != "" {
+			stats.Before++
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for i, l := range p.lines {
+			if l.dead || l.op == "" {
+				continue
+			}
+			for _, r := range rules {
+				if n := r.apply(p, i); n > 0 {
+					stats.Removed[r.name] += n
+					changed = true
+				}
+				if l.dead {
+					break
+				}
+			}
+		}
+	}
+	var out strings.Builder
+	for _, l := range p.lines {
+		if !l.dead {
+			out.WriteString(l.text)
+		}
+	}
+	return out.String(), stats
+}
+
+// directives 不是指令的行首关键字
+var directives = map[string]bool{
+	"section": true, "segment": true, "global": true, "extern": true, "align": true, "bits": true, "default": true,
+	"db": true, "dw": true, "dd": true, "dq": true, "resb": true, "resw": true, "resd": true, "resq": true, "times": true, "equ": true,
+}
+
+// parse 将代码按行解析，只有 .text 段中的指令才有助记符
+func parse(code string) []*line {
+	var lines []*line
+	text := true
+	for _, raw := range strings.SplitAfter(code, "\n") {
+		if raw == "" {
+			continue
+		}
+		l := &line{text: raw}
+		lines = append(lines, l)
+		body := strings.TrimSpace(stripComment(raw))
+		l.indent = raw[:len(raw)-len(strings.TrimLeft(raw, " \t"))]
+		if body == "" {
+			continue
+		}
+		fields := strings.Fields(body)
+		head := strings.ToLower(fields[0])
+		switch {
+		case head == "section" || head == "segment":
+			text = len(fields) > 1 && fields[1] == ".text"
+			l.dir = true
+		case strings.HasSuffix(fields[0], ":"):
+			l.label = strings.TrimSuffix(fields[0], ":")
+			l.dir = len(fields) > 1
+		case !text || directives[head] || strings.HasPrefix(head, "%") || strings.HasPrefix(head, "["):
+			l.dir = true
+		default:
+			l.op = head
+			if rest := strings.TrimSpace(body[len(fields[0]):]); rest != "" {
+				for _, arg := range strings.Split(rest, ",") {
+					l.args = append(l.args, strings.Join(strings.Fields(arg), " "))
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// stripComment 去掉行中的注释，引号中的分号不算
+func stripComment(s string) string {
+	quote := rune(0)
+	for i, c := range s {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == ';':
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// set 改写第 i 行的指令
+func (p *pass) set(i int, op string, args ...string) {
+	l := p.lines[i]
+	l.op, l.args = op, args
+	l.text = l.indent + op + " " + strings.Join(args, ", ") + "\n"
+}
+
+// next 返回紧随第 i 行之后的指令（中间只有空行与注释），没有时返回 -1
+func (p *pass) next(i int) int {
+	for j := i + 1; j < len(p.lines); j++ {
+		l := p.lines[j]
+		switch {
+		case l.dead:
+		case l.op != "":
+			return j
+		case l.label != "" || l.dir:
+			return -1
+		}
+	}
+	return -1
+}
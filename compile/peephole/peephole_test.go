@@ -0,0 +1,113 @@
+package peephole
+
+import (
+	"strings"
+	"testing"
+)
+
+// code 将各行拼成带缩进的汇编代码，以冒号结尾的行是标签
+func code(lines ...string) string {
+	var b strings.Builder
+	for _, l := range lines {
+		if !strings.HasSuffix(l, ":") {
+			b.WriteString("    ")
+		}
+		b.WriteString(l + "\n")
+	}
+	return b.String()
+}
+
+func TestRules(t *testing.T) {
+	cases := []struct {
+		rule   string
+		before []string
+		after  []string
+	}{
+		{"self-move", []string{"mov EBX, EBX", "ret"}, []string{"ret"}},
+		{"redundant-move",
+			[]string{"mov DWORD [ebp-4], EAX", "mov EAX, DWORD [ebp-4]", "ret"},
+			[]string{"mov DWORD [ebp-4], EAX", "ret"}},
+		{"redundant-move",
+			[]string{"mov EAX, EBX", "; 注释", "mov EBX, EAX", "ret"},
+			[]string{"mov EAX, EBX", "; 注释", "ret"}},
+		{"dead-move",
+			[]string{"mov dword [ebp-44], EAX", "mov dword [ebp-44], ECX", "ret"},
+			[]string{"mov dword [ebp-44], ECX", "ret"}},
+		{"dead-move", []string{"mov EAX, 1", "mov EAX, 2", "ret"}, []string{"mov EAX, 2", "ret"}},
+		{"push-pop", []string{"push EAX", "pop EAX", "ret"}, []string{"ret"}},
+		{"push-pop", []string{"push EAX", "pop ECX", "ret"}, []string{"mov ECX, EAX", "ret"}},
+		{"push-pop", []string{"push 5", "pop DWORD [ebp-4]", "ret"}, []string{"mov DWORD [ebp-4], 5", "ret"}},
+		{"zero-op", []string{"shl EAX, 0", "ret"}, []string{"ret"}},
+		{"zero-op", []string{"add ESP, 0", "cmp EAX, 1", "je L1", "L1:"}, []string{"cmp EAX, 1", "L1:"}},
+		{"jump-next", []string{"jmp L1", "L1:", "ret"}, []string{"L1:", "ret"}},
+		{"jump-next", []string{"jne L2", "L1:", "L2:", "ret"}, []string{"L1:", "L2:", "ret"}},
+		{"unreachable",
+			[]string{"jmp L1", "mov EAX, 1", "add EAX, 2", "L2:", "ret", "L1:", "ret"},
+			[]string{"jmp L1", "L2:", "ret", "L1:", "ret"}},
+		{"unreachable", []string{"ret", "mov EAX, 1", "ret"}, []string{"ret"}},
+	}
+	for _, c := range cases {
+		got, stats := Optimize(code(c.before...))
+		if want := code(c.after...); got != want {
+			t.Errorf("%s:\n%s优化后为\n%s应为\n%s", c.rule, code(c.before...), got, want)
+			continue
+		}
+		if stats.Removed[c.rule] == 0 {
+			t.Errorf("%s: 没有统计到删除的指令: %v", c.rule, stats)
+		}
+	}
+}
+
+// TestUnsafe 改写会改变程序行为的代码保持不变
+func TestUnsafe(t *testing.T) {
+	cases := []struct {
+		name string
+		code []string
+	}{
+		{"内存复制到自身", []string{"mov DWORD [ebp-4], DWORD [ebp-4]", "ret"}},
+		{"写回的地址已经改变", []string{"mov EAX, DWORD [EAX+4]", "mov DWORD [EAX+4], EAX", "ret"}},
+		{"部分寄存器写回的地址已经改变", []string{"mov AL, BYTE [EAX]", "mov BYTE [EAX], AL", "ret"}},
+		{"第二条读取第一条写入的寄存器", []string{"mov EAX, 1", "mov EAX, DWORD [EAX+4]", "ret"}},
+		{"中间隔着标签", []string{"mov EAX, 1", "L1:", "mov EAX, 2", "ret"}},
+		{"中间隔着其他指令", []string{"mov EAX, 1", "call f", "mov EAX, 2", "ret"}},
+		{"内存到内存", []string{"push DWORD [ebp-4]", "pop DWORD [ebp-8]", "ret"}},
+		{"用到 esp", []string{"push EAX", "pop DWORD [esp+4]", "ret"}},
+		{"16 位寄存器", []string{"push AX", "pop BX", "ret"}},
+		{"立即数写入未写明宽度的内存", []string{"push 5", "pop [ebp-4]", "ret"}},
+		{"之后读取标志位", []string{"add EAX, 0", "je L1", "ret", "L1:", "ret"}},
+		{"之后是标签", []string{"sub EAX, 0", "L1:", "je L2", "ret", "L2:", "ret"}},
+		{"跳转到其他标签", []string{"jmp L2", "L1:", "ret", "L2:", "jmp L1"}},
+		{"跳转与标签之间有伪指令", []string{"jmp L1", "section .rodata", "L1:", "dd 0"}},
+		{"跳转表之后的代码", []string{"jmp [T+EAX*4]", "section .rodata", "T: dd L1", "section .text", "L1:", "ret"}},
+		{"jmp 之后不认识的行", []string{"jmp L1", "foo", "L1:", "ret"}},
+	}
+	for _, c := range cases {
+		before := code(c.code...)
+		if got, stats := Optimize(before); got != before {
+			t.Errorf("%s:\n%s被改写为\n%s（%v）", c.name, before, got, stats)
+		}
+	}
+}
+
+// TestDataSection .text 之外的段不被当作指令
+func TestDataSection(t *testing.T) {
+	before := "section .data\n    mov db 1\n    ret db 2\nsection .text\n    mov EAX, EAX\n    ret\n"
+	got, stats := Optimize(before)
+	if want := "section .data\n    mov db 1\n    ret db 2\nsection .text\n    ret\n"; got != want {
+		t.Errorf("优化后为\n%s应为\n%s", got, want)
+	}
+	if stats.Before != 2 {
+		t.Errorf("优化前的指令数为 %d，应为 2", stats.Before)
+	}
+}
+
+// TestFixpoint 一条规则删除指令后，其他规则可以继续改写新相邻的指令
+func TestFixpoint(t *testing.T) {
+	got, stats := Optimize(code("push EAX", "mov EBX, EBX", "pop EAX", "jmp L1", "mov EAX, 1", "L1:", "ret"))
+	if want := code("L1:", "ret"); got != want {
+		t.Errorf("优化后为\n%s应为\n%s", got, want)
+	}
+	if want := "删除 5/6 条指令：self-move 1，push-pop 2，jump-next 1，unreachable 1"; stats.String() != want {
+		t.Errorf("统计为 %q，应为 %q", stats, want)
+	}
+}
@@ -0,0 +1,245 @@
+package peephole
+
+import (
+	"regexp"
+	"strings"
+)
+
+// selfMove mov X, X：32 位寄存器复制到自身没有效果
+func selfMove(p *pass, i int) int {
+	l := p.lines[i]
+	if l.op != "mov" || len(l.args) != 2 || l.args[0] != l.args[1] || isMem(l.args[0]) {
+		return 0
+	}
+	l.dead = true
+	return 1
+}
+
+// redundantMove mov A, B 之后紧接 mov B, A：第二条复制回的值与原值相同。
+// A 为寄存器时 B 的地址不能用到它，否则第二条写入的位置已经改变
+func redundantMove(p *pass, i int) int {
+	a := p.lines[i]
+	j := p.next(i)
+	if a.op != "mov" || len(a.args) != 2 || j < 0 {
+		return 0
+	}
+	b := p.lines[j]
+	if b.op != "mov" || len(b.args) != 2 || b.args[0] != a.args[1] || b.args[1] != a.args[0] {
+		return 0
+	}
+	if !isMem(a.args[0]) && reads(a.args[1], a.args[0]) {
+		return 0
+	}
+	b.dead = true
+	return 1
+}
+
+// deadMove mov X, A 之后紧接 mov X, B，且 B 不读取 X：第一条写入的值没有被使用
+func deadMove(p *pass, i int) int {
+	a := p.lines[i]
+	j := p.next(i)
+	if a.op != "mov" || len(a.args) != 2 || j < 0 {
+		return 0
+	}
+	b := p.lines[j]
+	if b.op != "mov" || len(b.args) != 2 || b.args[0] != a.args[0] {
+		return 0
+	}
+	if !isMem(a.args[0]) && reads(b.args[1], a.args[0]) {
+		return 0
+	}
+	a.dead = true
+	return 1
+}
+
+// pushPop push A 之后紧接 pop B：A 与 B 相同时两条都删除，否则合并为 mov B, A。
+// 两侧都是内存、用到 esp 或不是 32 位时不合并
+func pushPop(p *pass, i int) int {
+	a := p.lines[i]
+	j := p.next(i)
+	if a.op != "push" || len(a.args) != 1 || j < 0 {
+		return 0
+	}
+	b := p.lines[j]
+	if b.op != "pop" || len(b.args) != 1 {
+		return 0
+	}
+	src, dst := a.args[0], b.args[0]
+	if src == dst && !isMem(src) {
+		a.dead, b.dead = true, true
+		return 2
+	}
+	if isMem(src) && isMem(dst) || reads(src, "esp") || reads(dst, "esp") || !dword(src) || !dword(dst) {
+		return 0
+	}
+	// 立即数写入内存需要写明宽度
+	if isMem(dst) && isImm(src) && !strings.HasPrefix(strings.ToUpper(dst), "DWORD") {
+		return 0
+	}
+	p.set(i, "mov", dst, src)
+	b.dead = true
+	return 1
+}
+
+// zeroOp 加、减、或、异或 0 与移位 0 位不改变操作数：移位 0 位也不改变标志位，可以直接删除；
+// 其余的会设置标志位，只在之后的标志位在被读取之前就被改写时删除
+func zeroOp(p *pass, i int) int {
+	l := p.lines[i]
+	if len(l.args) != 2 || l.args[1] != "0" {
+		return 0
+	}
+	switch l.op {
+	case "shl", "shr", "sar", "sal", "rol", "ror":
+	case "add", "sub", "or", "xor":
+		if p.flagsLive(i) {
+			return 0
+		}
+	default:
+		return 0
+	}
+	l.dead = true
+	return 1
+}
+
+// jumpNext 跳转到紧随其后的标签（中间只有标签、空行与注释）：删除跳转
+func jumpNext(p *pass, i int) int {
+	l := p.lines[i]
+	if !isJump(l.op) || len(l.args) != 1 {
+		return 0
+	}
+	for j := i + 1; j < len(p.lines); j++ {
+		next := p.lines[j]
+		switch {
+		case next.dead:
+		case next.label == l.args[0] && !next.dir:
+			l.dead = true
+			return 1
+		case next.label != "" && !next.dir:
+		case next.op != "" || next.dir:
+			return 0
+		}
+	}
+	return 0
+}
+
+// unreachable jmp 或 ret 之后、下一个标签之前的指令不会被执行
+func unreachable(p *pass, i int) int {
+	if op := p.lines[i].op; op != "jmp" && op != "ret" {
+		return 0
+	}
+	n := 0
+	for j := p.next(i); j >= 0 && known(p.lines[j].op); j = p.next(j) {
+		p.lines[j].dead = true
+		n++
+	}
+	return n
+}
+
+// flagsLive 报告第 i 行指令设置的标志位是否可能在被改写之前被读取：遇到读取标志位的指令、
+// 标签或跳转时视为可能读取；遇到改写全部状态标志位的指令、调用或返回时不再读取
+func (p *pass) flagsLive(i int) bool {
+	for j := i + 1; j < len(p.lines); j++ {
+		l := p.lines[j]
+		switch {
+		case l.dead || l.op == "" && l.label == "" && !l.dir:
+			continue
+		case l.op == "":
+			return true
+		case readsFlags(l.op) || l.op == "jmp":
+			return true
+		case setsFlags[l.op] || l.op == "call" || l.op == "ret":
+			return false
+		case !known(l.op):
+			return true
+		}
+	}
+	return true
+}
+
+// setsFlags 改写全部状态标志位（不读取）的指令
+var setsFlags = map[string]bool{
+	"cmp": true, "test": true, "add": true, "sub": true, "and": true, "or": true, "xor": true, "neg": true,
+}
+
+// readsFlags 报告指令是否读取标志位
+func readsFlags(op string) bool {
+	switch op {
+	case "adc", "sbb", "rcl", "rcr", "pushf", "pushfd", "lahf", "into":
+		return true
+	}
+	return isJump(op) && op != "jmp" || strings.HasPrefix(op, "set") || strings.HasPrefix(op, "cmov")
+}
+
+// isJump 报告指令是否为 jmp 或条件跳转
+func isJump(op string) bool {
+	return strings.HasPrefix(op, "j")
+}
+
+// knownOps 可以安全删除的指令：不是 NASM 中省略冒号的标签
+var knownOps = map[string]bool{
+	"mov": true, "movzx": true, "movsx": true, "lea": true, "push": true, "pop": true, "xchg": true,
+	"add": true, "sub": true, "imul": true, "mul": true, "idiv": true, "div": true, "cdq": true, "neg": true, "not": true,
+	"and": true, "or": true, "xor": true, "shl": true, "shr": true, "sar": true, "sal": true, "rol": true, "ror": true, "inc": true, "dec": true,
+	"cmp": true, "test": true, "call": true, "ret": true, "leave": true, "nop": true, "int": true,
+}
+
+// known 报告助记符是否为已知的指令
+func known(op string) bool {
+	return knownOps[op] || isJump(op) || strings.HasPrefix(op, "set") || strings.HasPrefix(op, "cmov")
+}
+
+// isMem 报告操作数是否为内存
+func isMem(arg string) bool {
+	return strings.Contains(arg, "[")
+}
+
+// isImm 报告操作数是否为立即数（数字或符号）
+func isImm(arg string) bool {
+	return !isMem(arg) && regFamily(arg) == ""
+}
+
+// dword 报告操作数是否为 32 位：32 位寄存器，或未写明其他宽度的立即数与内存
+func dword(arg string) bool {
+	upper := strings.ToUpper(arg)
+	if regFamily(arg) != "" {
+		return len(upper) == 3 && upper[0] == 'E'
+	}
+	return !strings.HasPrefix(upper, "BYTE") && !strings.HasPrefix(upper, "WORD") && !strings.HasPrefix(upper, "QWORD")
+}
+
+// regRe 匹配通用寄存器（任意宽度的部分）
+var regRe = regexp.MustCompile(`(?i)\b(e?[abcd]x|[abcd][lh]|e?si|e?di|e?bp|e?sp|sil|dil)\b`)
+
+// regFamily 返回寄存器所属的 32 位寄存器，如 AL 为 EAX；不是寄存器时返回空串
+func regFamily(arg string) string {
+	if !regRe.MatchString(arg) || regRe.FindString(arg) != arg {
+		return ""
+	}
+	return family(arg)
+}
+
+func family(reg string) string {
+	r := strings.ToUpper(reg)
+	switch {
+	case strings.HasSuffix(r, "SIL"):
+		return "ESI"
+	case strings.HasSuffix(r, "DIL"):
+		return "EDI"
+	case len(r) == 2 && (r[1] == 'L' || r[1] == 'H'):
+		return "E" + r[:1] + "X"
+	case len(r) == 2:
+		return "E" + r
+	}
+	return r
+}
+
+// reads 报告操作数 arg 中是否用到寄存器 reg 所属的 32 位寄存器（任意宽度的部分）
+func reads(arg, reg string) bool {
+	target := family(reg)
+	for _, r := range regRe.FindAllString(arg, -1) {
+		if family(r) == target {
+			return true
+		}
+	}
+	return false
+}
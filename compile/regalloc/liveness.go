@@ -0,0 +1,199 @@
+package regalloc
+
+import "cuteify/compile/ir"
+
+// Liveness 各基本块入口与出口处活跃的值（只包括需要位置的值）。
+// phi 在所在基本块的开头定义，它的参数在对应前驱的出口处活跃，不计入本基本块的入口
+type Liveness struct {
+	In  map[*ir.Block][]*ir.Value
+	Out map[*ir.Block][]*ir.Value
+}
+
+// uses 返回 v 实际读取的值：在使用处生成的值（Inline）不占用位置，由它的参数代替
+func (a *allocator) uses(v *ir.Value, fn func(u *ir.Value)) {
+	for _, arg := range v.Args {
+		a.use(arg, fn)
+	}
+}
+
+func (a *allocator) use(v *ir.Value, fn func(u *ir.Value)) {
+	switch {
+	case a.id(v) >= 0:
+		fn(v)
+	case a.cfg.Inline != nil && a.cfg.Inline(v):
+		a.uses(v, fn)
+	}
+}
+
+// liveness 以基本块为单位迭代到不动点，得到各基本块入口与出口处活跃的值
+func (a *allocator) liveness() {
+	f := a.f
+	n := len(a.nodes)
+	a.in = make(map[*ir.Block]bitset, len(f.Blocks))
+	a.out = make(map[*ir.Block]bitset, len(f.Blocks))
+	gen := make(map[*ir.Block]bitset, len(f.Blocks))
+	kill := make(map[*ir.Block]bitset, len(f.Blocks))
+	for _, b := range f.Blocks {
+		g, k := newBitset(n), newBitset(n)
+		add := func(u *ir.Value) {
+			if i := a.id(u); !k.has(i) {
+				g.add(i)
+			}
+		}
+		for _, v := range b.Values {
+			if v.Op != ir.OpPhi && !a.inline(v) {
+				a.uses(v, add)
+			}
+			if i := a.id(v); i >= 0 {
+				k.add(i)
+			}
+		}
+		if b.Control != nil {
+			a.use(b.Control, add)
+		}
+		gen[b], kill[b] = g, k
+		a.in[b], a.out[b] = newBitset(n), newBitset(n)
+	}
+	for changed := true; changed; {
+		changed = false
+		for i := len(f.Blocks) - 1; i >= 0; i-- {
+			b := f.Blocks[i]
+			out := a.liveOut(b)
+			in := out.clone()
+			in.subtract(kill[b])
+			in.union(gen[b])
+			if !out.equal(a.out[b]) || !in.equal(a.in[b]) {
+				a.out[b], a.in[b] = out, in
+				changed = true
+			}
+		}
+	}
+}
+
+// liveOut 由各后继入口处活跃的值与后继中 phi 来自 b 的参数得到 b 出口处活跃的值
+func (a *allocator) liveOut(b *ir.Block) bitset {
+	out := newBitset(len(a.nodes))
+	for _, succ := range b.Succs {
+		out.union(a.in[succ])
+		a.phiArgs(b, succ, func(phi, arg *ir.Value) {
+			a.use(arg, func(u *ir.Value) { out.add(a.id(u)) })
+		})
+	}
+	return out
+}
+
+// phiArgs 依次以 succ 中的 phi 及其来自前驱 b 的参数调用 fn
+func (a *allocator) phiArgs(b, succ *ir.Block, fn func(phi, arg *ir.Value)) {
+	for i, pred := range succ.Preds {
+		if pred != b {
+			continue
+		}
+		for _, v := range succ.Values {
+			if v.Op != ir.OpPhi {
+				break
+			}
+			fn(v, v.Args[i])
+		}
+	}
+}
+
+// Liveness 返回分配时计算的活跃信息
+func (r *Result) Liveness() *Liveness {
+	return r.live
+}
+
+func (a *allocator) export() *Liveness {
+	live := &Liveness{In: make(map[*ir.Block][]*ir.Value), Out: make(map[*ir.Block][]*ir.Value)}
+	for _, b := range a.f.Blocks {
+		live.In[b] = a.values(a.in[b])
+		live.Out[b] = a.values(a.out[b])
+	}
+	return live
+}
+
+func (a *allocator) values(s bitset) (vs []*ir.Value) {
+	for i := range a.nodes {
+		if s.has(i) {
+			vs = append(vs, a.nodes[i].v)
+		}
+	}
+	return
+}
+
+// loopDepth 返回各基本块所在循环的嵌套层数：基本块按逆后序排列，指向不在其后的基本块的边为回边，
+// 回边的源沿前驱向上直到循环头的基本块组成循环
+func loopDepth(f *ir.Func) map[*ir.Block]int {
+	order := make(map[*ir.Block]int, len(f.Blocks))
+	for i, b := range f.Blocks {
+		order[b] = i
+	}
+	depth := make(map[*ir.Block]int, len(f.Blocks))
+	for _, b := range f.Blocks {
+		for _, h := range b.Succs {
+			if order[h] > order[b] {
+				continue
+			}
+			body := map[*ir.Block]bool{h: true}
+			stack := []*ir.Block{b}
+			for len(stack) > 0 {
+				x := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if body[x] {
+					continue
+				}
+				body[x] = true
+				stack = append(stack, x.Preds...)
+			}
+			for x := range body {
+				depth[x]++
+			}
+		}
+	}
+	return depth
+}
+
+// bitset 以值在分配器中的编号为下标的集合
+type bitset []uint64
+
+func newBitset(n int) bitset { return make(bitset, (n+63)/64) }
+
+func (s bitset) add(i int)      { s[i/64] |= 1 << (i % 64) }
+func (s bitset) remove(i int)   { s[i/64] &^= 1 << (i % 64) }
+func (s bitset) has(i int) bool { return i >= 0 && s[i/64]&(1<<(i%64)) != 0 }
+
+func (s bitset) clone() bitset { return append(bitset(nil), s...) }
+
+func (s bitset) union(t bitset) {
+	for i := range s {
+		s[i] |= t[i]
+	}
+}
+
+func (s bitset) subtract(t bitset) {
+	for i := range s {
+		s[i] &^= t[i]
+	}
+}
+
+func (s bitset) equal(t bitset) bool {
+	for i := range s {
+		if s[i] != t[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// each 按编号从小到大依次以集合中的元素调用 fn
+func (s bitset) each(fn func(i int)) {
+	for w, bits := range s {
+		for bits != 0 {
+			k := 0
+			for bits&(1<<k) == 0 {
+				k++
+			}
+			fn(w*64 + k)
+			bits &^= 1 << k
+		}
+	}
+}
@@ -0,0 +1,360 @@
+// Package regalloc 以函数为单位为 IR 中的值分配寄存器：计算活跃区间，建立冲突图并用目标的寄存器着色，
+// 寄存器不足时溢出使用密度（按循环嵌套加权的使用次数除以活跃区间的长度）最低的值。
+package regalloc
+
+import (
+	"cuteify/compile/ir"
+	"math"
+	"slices"
+)
+
+// Config 描述分配的目标，各回调由后端提供
+type Config struct {
+	Regs []string // 可以分配的寄存器
+	// Needs 报告值是否需要位置；不需要的值（常量、在使用处生成的地址、留在累加器中的值等）不参与分配
+	Needs func(v *ir.Value) bool
+	// Inline 报告值是否在每次使用处生成（如折叠进寻址方式的 基址+常量），此时它的参数活跃到它的各个使用处
+	Inline func(v *ir.Value) bool
+	// Clobbers 返回指令改写的寄存器（Regs 中的下标），指令之后仍然活跃的值不能使用这些寄存器
+	Clobbers func(v *ir.Value) []int
+	// Costs 各寄存器的固定使用代价（如在序言与尾声中保存与恢复），以一次使用为单位，为空时均为 0。
+	// 分配到某个寄存器的值的加权使用次数之和不超过它的代价时不再使用该寄存器，其余的值重新着色
+	Costs []float64
+}
+
+// Result 分配的结果
+type Result struct {
+	Reg     map[*ir.Value]int // 分配到寄存器的值 -> Regs 中的下标
+	Spilled []*ir.Value       // 没有分配到寄存器、需要放在栈上的值，按在函数中出现的顺序排列
+	Used    []bool            // 各寄存器是否被分配给了某个值
+
+	live *Liveness
+}
+
+// node 冲突图中的一个值
+type node struct {
+	v        *ir.Value
+	adj      []int   // 相邻（同时活跃）的值
+	forbid   []bool  // 不能使用的寄存器
+	partners []int   // 希望使用同一寄存器的值（phi 与它的参数），相同时省去复制
+	weight   float64 // 按循环嵌套加权的定义与使用次数
+	length   int     // 活跃区间的长度（活跃的指令数）
+	color    int
+	alias    int // 合并到的值，未合并时为 -1
+}
+
+type allocator struct {
+	f     *ir.Func
+	cfg   Config
+	nodes []*node
+	index map[*ir.Value]int
+	edges map[[2]int]bool
+	in    map[*ir.Block]bitset
+	out   map[*ir.Block]bitset
+}
+
+// Allocate 为函数 f 中需要位置的值分配寄存器，f 的基本块应按逆后序排列
+func Allocate(f *ir.Func, cfg Config) *Result {
+	a := &allocator{f: f, cfg: cfg, index: make(map[*ir.Value]int), edges: make(map[[2]int]bool)}
+	for _, b := range f.Blocks {
+		for _, v := range b.Values {
+			if v.Type != ir.Void && cfg.Needs(v) {
+				a.index[v] = len(a.nodes)
+				a.nodes = append(a.nodes, &node{v: v, forbid: make([]bool, len(cfg.Regs)), color: -1, alias: -1})
+			}
+		}
+	}
+	a.liveness()
+	a.interfere()
+	a.weigh()
+	a.coalesce()
+	a.color()
+	for a.drop() {
+		a.color()
+	}
+
+	r := &Result{Reg: make(map[*ir.Value]int), Used: make([]bool, len(cfg.Regs)), live: a.export()}
+	for i, n := range a.nodes {
+		n.color = a.nodes[a.find(i)].color
+	}
+	for _, n := range a.nodes {
+		if n.color < 0 {
+			r.Spilled = append(r.Spilled, n.v)
+			continue
+		}
+		r.Reg[n.v] = n.color
+		r.Used[n.color] = true
+	}
+	return r
+}
+
+// id 返回值在冲突图中的编号，不参与分配的值为 -1
+func (a *allocator) id(v *ir.Value) int {
+	if i, ok := a.index[v]; ok {
+		return i
+	}
+	return -1
+}
+
+func (a *allocator) inline(v *ir.Value) bool {
+	return a.cfg.Inline != nil && a.cfg.Inline(v)
+}
+
+func (a *allocator) addEdge(i, j int) {
+	if i == j || i < 0 || j < 0 {
+		return
+	}
+	if i > j {
+		i, j = j, i
+	}
+	if a.edges[[2]int{i, j}] {
+		return
+	}
+	a.edges[[2]int{i, j}] = true
+	a.nodes[i].adj = append(a.nodes[i].adj, j)
+	a.nodes[j].adj = append(a.nodes[j].adj, i)
+}
+
+// interfere 从各基本块的出口向前扫描：定义一个值时，它与此时活跃的其他值冲突；同时统计活跃区间的长度
+func (a *allocator) interfere() {
+	for _, b := range a.f.Blocks {
+		live := a.out[b].clone()
+		// 前驱末尾依次复制 phi 的参数，phi 的位置不能与出口处仍要读取的其他值相同
+		for _, succ := range b.Succs {
+			a.phiArgs(b, succ, func(phi, arg *ir.Value) {
+				p := a.id(phi)
+				live.each(func(i int) {
+					if a.nodes[i].v != arg {
+						a.addEdge(p, i)
+					}
+				})
+				if q := a.id(arg); q >= 0 && p >= 0 {
+					a.nodes[p].partners = append(a.nodes[p].partners, q)
+					a.nodes[q].partners = append(a.nodes[q].partners, p)
+				}
+			})
+		}
+		if b.Control != nil {
+			a.use(b.Control, func(u *ir.Value) { live.add(a.id(u)) })
+		}
+		var phis []int
+		for i := len(b.Values) - 1; i >= 0; i-- {
+			v := b.Values[i]
+			if v.Op == ir.OpPhi {
+				if p := a.id(v); p >= 0 {
+					phis = append(phis, p)
+				}
+				continue
+			}
+			if d := a.id(v); d >= 0 {
+				live.remove(d)
+				live.each(func(j int) { a.addEdge(d, j) })
+			}
+			live.each(func(j int) { a.nodes[j].length++ })
+			if a.cfg.Clobbers != nil {
+				for _, r := range a.cfg.Clobbers(v) {
+					live.each(func(j int) { a.nodes[j].forbid[r] = true })
+				}
+			}
+			if !a.inline(v) {
+				a.uses(v, func(u *ir.Value) { live.add(a.id(u)) })
+			}
+		}
+		// phi 同时在基本块开头定义，彼此冲突，也与入口处活跃的值冲突
+		for _, p := range phis {
+			live.remove(p)
+		}
+		for k, p := range phis {
+			live.each(func(j int) { a.addEdge(p, j) })
+			for _, q := range phis[k+1:] {
+				a.addEdge(p, q)
+			}
+		}
+	}
+}
+
+// weigh 统计各值按循环嵌套加权的定义与使用次数：嵌套 d 层的循环中的一次计为 10^d 次
+func (a *allocator) weigh() {
+	depth := loopDepth(a.f)
+	cost := func(b *ir.Block) float64 { return math.Pow(10, float64(min(depth[b], 6))) }
+	for _, b := range a.f.Blocks {
+		for _, v := range b.Values {
+			if d := a.id(v); d >= 0 {
+				a.nodes[d].weight += cost(b)
+			}
+			if v.Op == ir.OpPhi {
+				for i, arg := range v.Args {
+					a.use(arg, func(u *ir.Value) { a.nodes[a.id(u)].weight += cost(b.Preds[i]) })
+				}
+				continue
+			}
+			if !a.inline(v) {
+				a.uses(v, func(u *ir.Value) { a.nodes[a.id(u)].weight += cost(b) })
+			}
+		}
+		if b.Control != nil {
+			a.use(b.Control, func(u *ir.Value) { a.nodes[a.id(u)].weight += cost(b) })
+		}
+	}
+}
+
+// density 使用密度，越低越先溢出
+func (n *node) density() float64 {
+	return n.weight / float64(n.length+1)
+}
+
+// colors 返回值可以使用的寄存器数
+func (n *node) colors() int {
+	k := 0
+	for _, f := range n.forbid {
+		if !f {
+			k++
+		}
+	}
+	return k
+}
+
+// find 返回值合并后所在的值
+func (a *allocator) find(i int) int {
+	for a.nodes[i].alias >= 0 {
+		i = a.nodes[i].alias
+	}
+	return i
+}
+
+// interferes 报告两个值之间是否有冲突
+func (a *allocator) interferes(i, j int) bool {
+	return a.edges[[2]int{min(i, j), max(i, j)}]
+}
+
+// coalesce 保守地合并不冲突的 phi 与其参数，使它们使用同一寄存器、省去复制：
+// 只在合并后相邻值中度数不小于寄存器数的少于寄存器数时合并（Briggs），不会使原本可以着色的图需要溢出
+func (a *allocator) coalesce() {
+	k := len(a.cfg.Regs)
+	for i, n := range a.nodes {
+		for _, p := range n.partners {
+			x, y := a.find(i), a.find(p)
+			if x == y || a.interferes(x, y) {
+				continue
+			}
+			high := 0
+			seen := make(map[int]bool)
+			for _, j := range append(append([]int(nil), a.nodes[x].adj...), a.nodes[y].adj...) {
+				if !seen[j] && len(a.nodes[j].adj) >= k {
+					high++
+				}
+				seen[j] = true
+			}
+			if high < k {
+				a.merge(x, y)
+			}
+		}
+	}
+}
+
+// merge 将值 y 合并到 x：y 的冲突、禁用的寄存器与使用次数都转给 x
+func (a *allocator) merge(x, y int) {
+	nx, ny := a.nodes[x], a.nodes[y]
+	ny.alias = x
+	for _, j := range ny.adj {
+		nj := a.nodes[j]
+		nj.adj = slices.DeleteFunc(nj.adj, func(k int) bool { return k == y })
+		delete(a.edges, [2]int{min(j, y), max(j, y)})
+		a.addEdge(x, j)
+	}
+	ny.adj = nil
+	for r, f := range ny.forbid {
+		nx.forbid[r] = nx.forbid[r] || f
+	}
+	nx.weight += ny.weight
+	nx.length += ny.length
+}
+
+// color 乐观着色：反复移除度数小于可用寄存器数的值，没有时移除使用密度最低的值作为可能溢出的值，
+// 然后按移除的相反顺序选择与已着色的相邻值不同的寄存器，优先选择未能合并的 phi 或参数已经使用的寄存器
+func (a *allocator) color() {
+	removed := make([]bool, len(a.nodes))
+	degree := make([]int, len(a.nodes))
+	var stack []int
+	left := 0
+	for i, n := range a.nodes {
+		degree[i] = len(n.adj)
+		if n.alias >= 0 {
+			removed[i] = true
+		} else {
+			left++
+		}
+	}
+	for ; left > 0; left-- {
+		pick := -1
+		for i, n := range a.nodes {
+			if !removed[i] && degree[i] < n.colors() {
+				pick = i
+				break
+			}
+		}
+		if pick < 0 {
+			for i, n := range a.nodes {
+				if !removed[i] && (pick < 0 || n.density() < a.nodes[pick].density()) {
+					pick = i
+				}
+			}
+		}
+		removed[pick] = true
+		stack = append(stack, pick)
+		for _, j := range a.nodes[pick].adj {
+			degree[j]--
+		}
+	}
+	for k := len(stack) - 1; k >= 0; k-- {
+		n := a.nodes[stack[k]]
+		taken := append([]bool(nil), n.forbid...)
+		for _, j := range n.adj {
+			if c := a.nodes[j].color; c >= 0 {
+				taken[c] = true
+			}
+		}
+		for _, p := range n.partners {
+			if c := a.nodes[a.find(p)].color; c >= 0 && !taken[c] {
+				n.color = c
+				break
+			}
+		}
+		for c := 0; n.color < 0 && c < len(taken); c++ {
+			if !taken[c] {
+				n.color = c
+			}
+		}
+	}
+}
+
+// drop 找出分配到的值的加权使用次数之和不超过代价的寄存器，禁止所有值使用它们并清除着色，以便重新着色；
+// 没有这样的寄存器时返回 false
+func (a *allocator) drop() bool {
+	if a.cfg.Costs == nil {
+		return false
+	}
+	weight := make([]float64, len(a.cfg.Regs))
+	used := make([]bool, len(a.cfg.Regs))
+	for _, n := range a.nodes {
+		if n.alias < 0 && n.color >= 0 {
+			weight[n.color] += n.weight
+			used[n.color] = true
+		}
+	}
+	dropped := false
+	for r := range a.cfg.Regs {
+		if used[r] && weight[r] <= a.cfg.Costs[r] {
+			for _, n := range a.nodes {
+				n.forbid[r] = true
+			}
+			dropped = true
+		}
+	}
+	if dropped {
+		for _, n := range a.nodes {
+			n.color = -1
+		}
+	}
+	return dropped
+}
@@ -0,0 +1,171 @@
+package regalloc
+
+import (
+	"cuteify/compile/ir"
+	"slices"
+	"testing"
+)
+
+// newSum 构造 sum(n)：循环累加 0..n-1，循环变量与累加和由头部的 phi 选择
+//
+//	b0: i0 = 0; s0 = 0
+//	b1: i = phi(i0, i1); s = phi(s0, s1); if i < n b2 b3
+//	b2: s1 = s + i; i1 = i + 1; jmp b1
+//	b3: ret s
+func newSum() (f *ir.Func, v map[string]*ir.Value) {
+	f = &ir.Func{Name: "sum", Result: ir.I32, Params: []ir.Param{{Name: "n", Kind: ir.ParamArg, Type: ir.I32, Size: 4}}}
+	entry, header, body, exit := f.NewBlock(), f.NewBlock(), f.NewBlock(), f.NewBlock()
+	v = make(map[string]*ir.Value)
+	v["n"] = entry.NewValue(ir.OpArg, ir.I32)
+	v["zero"] = entry.NewValue(ir.OpConst, ir.I32)
+	v["one"] = entry.NewValue(ir.OpConst, ir.I32)
+	v["one"].AuxInt = 1
+	entry.Kind = ir.BlockPlain
+	entry.AddEdge(header)
+
+	v["i"] = header.NewPhi(ir.I32, v["zero"], nil)
+	v["s"] = header.NewPhi(ir.I32, v["zero"], nil)
+	header.Kind, header.Control = ir.BlockIf, header.NewValue(ir.OpLt, ir.Bool, v["i"], v["n"])
+	header.AddEdge(body)
+	header.AddEdge(exit)
+
+	v["s1"] = body.NewValue(ir.OpAdd, ir.I32, v["s"], v["i"])
+	v["i1"] = body.NewValue(ir.OpAdd, ir.I32, v["i"], v["one"])
+	body.Kind = ir.BlockPlain
+	body.AddEdge(header)
+	v["i"].Args[1], v["s"].Args[1] = v["i1"], v["s1"]
+
+	exit.Kind, exit.Control = ir.BlockRet, v["s"]
+	return f, v
+}
+
+// config 以 k 个寄存器分配，常量、参数与比较（与条件跳转合并）不需要位置
+func config(k int) Config {
+	return Config{
+		Regs: []string{"r0", "r1", "r2"}[:k],
+		Needs: func(v *ir.Value) bool {
+			return v.Op != ir.OpConst && v.Op != ir.OpArg && !v.Op.IsCompare()
+		},
+	}
+}
+
+func TestLiveness(t *testing.T) {
+	f, v := newSum()
+	if err := ir.Verify(f); err != nil {
+		t.Fatal(err)
+	}
+	live := Allocate(f, config(3)).Liveness()
+	header, body := f.Blocks[1], f.Blocks[2]
+	cases := []struct {
+		name string
+		got  []*ir.Value
+		want []*ir.Value
+	}{
+		// phi 在头部定义，不在入口处活跃；参数在前驱的出口处活跃
+		{"b1 入口", live.In[header], nil},
+		{"b1 出口", live.Out[header], []*ir.Value{v["i"], v["s"]}},
+		{"b2 入口", live.In[body], []*ir.Value{v["i"], v["s"]}},
+		{"b2 出口", live.Out[body], []*ir.Value{v["s1"], v["i1"]}},
+		{"b3 入口", live.In[f.Blocks[3]], []*ir.Value{v["s"]}},
+	}
+	for _, c := range cases {
+		if !sameValues(c.got, c.want) {
+			t.Errorf("%s活跃的值为 %v，应为 %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	f, v := newSum()
+	r := Allocate(f, config(3))
+	if len(r.Spilled) != 0 {
+		t.Fatalf("寄存器足够时溢出了 %v", r.Spilled)
+	}
+	// phi 与循环中对应的参数不冲突，合并后使用同一寄存器，前驱末尾不需要复制
+	if r.Reg[v["i"]] != r.Reg[v["i1"]] || r.Reg[v["s"]] != r.Reg[v["s1"]] {
+		t.Errorf("phi 与参数的寄存器不同: i %d i1 %d s %d s1 %d", r.Reg[v["i"]], r.Reg[v["i1"]], r.Reg[v["s"]], r.Reg[v["s1"]])
+	}
+	if r.Reg[v["i"]] == r.Reg[v["s"]] {
+		t.Error("同时活跃的 i 与 s 使用了同一寄存器")
+	}
+}
+
+func TestSpill(t *testing.T) {
+	f, v := newSum()
+	// 函数开头另算一个值，直到返回前才使用：活跃区间跨过整个循环，使用密度最低
+	entry, exit := f.Blocks[0], f.Blocks[3]
+	far := entry.NewValue(ir.OpMul, ir.I32, v["n"], v["n"])
+	entry.Values = slices.Insert(entry.Values[:len(entry.Values)-1], 1, far)
+	ret := exit.NewValue(ir.OpAdd, ir.I32, v["s"], far)
+	exit.Control = ret
+	if err := ir.Verify(f); err != nil {
+		t.Fatal(err)
+	}
+	r := Allocate(f, config(2))
+	if !slices.Equal(r.Spilled, []*ir.Value{far}) {
+		t.Errorf("溢出的值为 %v，应只溢出 %v", r.Spilled, far)
+	}
+	for _, name := range []string{"i", "s", "i1", "s1"} {
+		if _, ok := r.Reg[v[name]]; !ok {
+			t.Errorf("循环中的 %s 没有分配到寄存器", name)
+		}
+	}
+}
+
+func TestClobbers(t *testing.T) {
+	f, v := newSum()
+	// 循环体中的指令改写 r0，跨过它仍然活跃的 s 与 i 不能使用 r0
+	body := f.Blocks[2]
+	asm := &ir.Value{Op: ir.OpAsm, Type: ir.Void, Block: body}
+	body.Values = append([]*ir.Value{asm}, body.Values...)
+	cfg := config(3)
+	cfg.Clobbers = func(x *ir.Value) []int {
+		if x == asm {
+			return []int{0}
+		}
+		return nil
+	}
+	r := Allocate(f, cfg)
+	for _, name := range []string{"i", "s"} {
+		if reg, ok := r.Reg[v[name]]; !ok || reg == 0 {
+			t.Errorf("%s 的寄存器为 %d（%v），不能为被改写的 r0", name, reg, ok)
+		}
+	}
+}
+
+func TestCosts(t *testing.T) {
+	f, _ := newSum()
+	cfg := config(3)
+	cfg.Costs = []float64{1000, 1000, 0}
+	r := Allocate(f, cfg)
+	if r.Used[0] || r.Used[1] {
+		t.Errorf("使用了代价超过收益的寄存器: %v", r.Used)
+	}
+	// 只剩一个寄存器时 i 与 s 只能有一个分配到寄存器
+	if len(r.Spilled) != 2 {
+		t.Errorf("溢出的值为 %v，应有 2 个", r.Spilled)
+	}
+}
+
+func TestLoopDepth(t *testing.T) {
+	f, _ := newSum()
+	depth := loopDepth(f)
+	for i, want := range []int{0, 1, 1, 0} {
+		if got := depth[f.Blocks[i]]; got != want {
+			t.Errorf("b%d 的循环嵌套层数为 %d，应为 %d", i, got, want)
+		}
+	}
+}
+
+// sameValues 比较两组值，不计顺序
+func sameValues(a, b []*ir.Value) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, v := range a {
+		if !slices.Contains(b, v) {
+			return false
+		}
+	}
+	return true
+}
@@ -5,6 +5,7 @@ import (
 	"cuteify/utils"
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // RegMgr 寄存器管理器
@@ -26,6 +27,7 @@ type Reg struct {
 	UsingNode  *parser.Node // 使用节点
 	Using      bool         // 是否使用
 	CalleeSave bool         // 被调用者保存
+	Size       int          // 寄存器宽度（字节），0 表示 32 位
 	Locked     bool         // 锁定状态（关键字段）
 	SpillCount int          // 溢出次数统计
 }
@@ -169,6 +171,45 @@ func (rm *RegMgr) Force(reg *Reg, n *parser.Node, exp *parser.Expression) *Reg {
 	return reg
 }
 
+// Reserve 预留指定名称的寄存器（如传参寄存器），溢出其当前占用者并锁定，之后的分配和溢出都不会使用它
+func (rm *RegMgr) Reserve(name string) (code string) {
+	rm.index++
+	for _, reg := range rm.Regs {
+		if reg.Name != name {
+			continue
+		}
+		if reg.Locked {
+			panic(fmt.Sprintf("编译器内部错误: 无法预留寄存器 %s，该寄存器已被锁定", name))
+		}
+		if reg.Using {
+			code = rm.genSpill(reg)
+		}
+		for exp, ri := range rm.Record {
+			if ri == reg {
+				delete(rm.Record, exp)
+			}
+		}
+		reg.Reset()
+		reg.index = rm.index
+		reg.Using = true
+		reg.Locked = true
+		rm.calcUsingReg()
+		return code
+	}
+	panic("编译器内部错误: 没有寄存器 " + name)
+}
+
+// Release 按名称释放寄存器：由 Reserve 预留的寄存器，或值已压栈的临时寄存器
+func (rm *RegMgr) Release(name string) {
+	rm.index++
+	for _, reg := range rm.Regs {
+		if reg.Name == name {
+			reg.Reset()
+		}
+	}
+	rm.calcUsingReg()
+}
+
 // spillReg 溢出单个寄存器
 func (rm *RegMgr) spillReg(n *parser.Node, exp *parser.Expression, needCalleeSave bool) *Reg {
 	// 计算代价
@@ -210,11 +251,19 @@ func (rm *RegMgr) genSpill(reg *Reg) string {
 		return ""
 	}
 
-	if vb, ok := reg.UsingNode.Value.(*parser.VarBlock); ok {
+	// 通过指针赋值（*p = v）的语句没有对应的变量槽位，不溢出
+	if vb, ok := reg.UsingNode.Value.(*parser.VarBlock); ok && vb.Store == nil {
 		addr := rm.genVarAddr(vb)
-		// x86 32位平台所有寄存器都是32位，溢出时只能存储32位
-		// 强制使用dword避免类型不匹配问题
-		code := utils.Format("mov dword " + addr + ", " + reg.Name + "; spill")
+		// 溢出时按寄存器的完整宽度存储（x86 32位平台为dword）
+		// 去掉地址自带的长度前缀，避免类型不匹配问题
+		if i := strings.Index(addr, "["); i > 0 {
+			addr = addr[i:]
+		}
+		width := "dword"
+		if reg.Size == 8 {
+			width = "qword"
+		}
+		code := utils.Format("mov " + width + " " + addr + ", " + reg.Name + "; spill")
 		reg.StoreCode = code
 		return code
 	}
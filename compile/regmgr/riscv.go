@@ -0,0 +1,21 @@
+package regmgr
+
+// RISC-V 整数寄存器按 psABI 的划分
+var (
+	RISCVArgRegs   = []string{"a0", "a1", "a2", "a3", "a4", "a5", "a6", "a7"}                           // 参数与返回值
+	RISCVSavedRegs = []string{"s0", "s1", "s2", "s3", "s4", "s5", "s6", "s7", "s8", "s9", "s10", "s11"} // callee-saved，s0 兼作帧指针
+	RISCVTempRegs  = []string{"t0", "t1", "t2", "t3", "t4", "t5", "t6"}                                 // caller-saved 临时寄存器
+)
+
+// NewRISCVRegs 返回 RISC-V 参与分配的寄存器，xlen 为字长（字节）
+// 优先分配 t0-t5，寄存器不足时才会用到 s2-s11；s0 为帧指针，s1 保存方法的接收者，t6 留作后端的临时寄存器
+func NewRISCVRegs(xlen int) []*Reg {
+	var regs []*Reg
+	for _, name := range RISCVTempRegs[:6] {
+		regs = append(regs, &Reg{Name: name, Size: xlen})
+	}
+	for _, name := range RISCVSavedRegs[2:] {
+		regs = append(regs, &Reg{Name: name, Size: xlen, CalleeSave: true})
+	}
+	return regs
+}
@@ -0,0 +1,115 @@
+package compile
+
+import (
+	"cuteify/compile/arch"
+	"cuteify/parser"
+	"cuteify/utils"
+	"fmt"
+	"strings"
+)
+
+// nasmSyntax 汇编后端共用的 NASM 文本输出
+type nasmSyntax struct {
+	arch arch.Arch
+}
+
+// syntax 返回当前后端的文本输出方式，后端未实现 arch.Syntax 时使用 NASM 语法
+func (c *Compiler) syntax() arch.Syntax {
+	if s, ok := c.Ctx.Arch.(arch.Syntax); ok {
+		return s
+	}
+	return nasmSyntax{arch: c.Ctx.Arch}
+}
+
+func (nasmSyntax) Header(root *parser.Node) string {
+	return "section .text\nglobal _start\n\n"
+}
+
+func (nasmSyntax) FuncLabel(funcBlock *parser.FuncBlock, label string, links []string) (code string) {
+	code += utils.Format("; ==============================")
+	code += utils.Format("; Function: " + label)
+	// build link("name") 导出同名的全局符号，指向函数入口
+	for _, link := range links {
+		code += utils.Format("global " + link)
+		code += utils.Format(link + ":")
+	}
+	code += utils.Format(label + ":")
+	return code
+}
+
+func (nasmSyntax) EndFunc(funcBlock *parser.FuncBlock) string {
+	return utils.Format("; ======函数完毕=======\n\n")
+}
+
+// If 条件不成立时跳转到 else 分支或 if 结束位置
+func (s nasmSyntax) If(ifBlock *parser.IfBlock, label string) (code string) {
+	if ifBlock.Else {
+		code += s.arch.Exp(ifBlock.Condition, "else_"+label, "")
+	} else {
+		code += s.arch.Exp(ifBlock.Condition, "end_"+label, "")
+	}
+	return code + utils.Format(label+":")
+}
+
+// Else if 分支执行完毕后跳过 else 分支；else if 的条件不成立时跳转到结束位置
+func (s nasmSyntax) Else(ifBlock *parser.IfBlock, label string) (code string) {
+	code += utils.Format("jmp end_" + label + "; 跳过else分支")
+	code += utils.Format("else_" + label + ":")
+	if cond := ifBlock.ElseBlock.Value.(*parser.ElseBlock).IfCondition; cond != nil {
+		code += s.arch.Exp(cond, "end_"+label, "")
+	}
+	return code
+}
+
+func (nasmSyntax) EndIf(ifBlock *parser.IfBlock, label string) string {
+	return utils.Format("end_" + label + ":")
+}
+
+func (nasmSyntax) InlineAsm(block *parser.Build) (code string) {
+	asm := block.Asm
+	// 使用存储的 VarBlock 进行替换
+	for varName, tmpVar := range block.VarMap {
+		placeholder := "$" + varName
+		offset := tmpVar.Offset
+		// 参数的偏移由调用约定在函数序言中确定（如 fastcall 的寄存器参数位于栈帧内）
+		if tmpVar.Define != nil {
+			if arg, ok := tmpVar.Define.Value.(*parser.ArgBlock); ok {
+				offset = arg.Offset
+			}
+		}
+		addr := fmt.Sprintf("DWORD[ebp+%d]", offset)
+		asm = strings.ReplaceAll(asm, placeholder, addr)
+	}
+	// 使用 Format 格式化每一行
+	lines := strings.Split(strings.TrimSpace(asm), "\n")
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			code += utils.Format(line)
+		}
+	}
+	return
+}
+
+func (nasmSyntax) StartEntry() string {
+	var code string
+	code += utils.Format("; ==============================")
+	code += utils.Format("; 程序入口点 (ELF入口)")
+	code += utils.Format("_start:")
+	utils.Count++
+	code += utils.Format("; 调用main函数")
+	code += utils.Format("call main")
+	if WordSize(GoArch) == 8 {
+		code += utils.Format("; 使用系统调用退出程序 (sys_exit = 60)")
+		code += utils.Format("; 返回值在RAX中")
+		code += utils.Format("mov rdi, rax; 返回码")
+		code += utils.Format("mov rax, 60; sys_exit")
+		code += utils.Format("syscall; 调用内核\n")
+		return code
+	}
+	code += utils.Format("; 使用系统调用退出程序 (sys_exit = 1)")
+	code += utils.Format("; 返回值在EAX中")
+	code += utils.Format("mov ebx, eax; 返回码")
+	code += utils.Format("mov eax, 1; sys_exit")
+	code += utils.Format("int 0x80; 调用内核\n")
+	return code
+}
@@ -3,12 +3,19 @@ package compile
 
 import (
 	"cuteify/compile/arch"
+	"cuteify/compile/arch/c99"
+	"cuteify/compile/arch/llvm"
+	"cuteify/compile/arch/riscv"
+	"cuteify/compile/arch/wasm"
 	"cuteify/compile/arch/x86"
+	"cuteify/compile/arch/x86_64"
 	"cuteify/compile/context"
+	"strings"
 )
 
 // NewArch 根据架构名称创建对应的架构处理器
-// 支持的架构: x86 (默认为cdecl), x86.cdecl, x86.stdcall
+// 支持的架构: x86 (默认为cdecl), x86.cdecl, x86.stdcall, x86.fastcall, x86_64 (默认为sysv), x86_64.sysv, c (即 c99), wasm32 (即 wasm), llvm, rv64 (即 riscv64), rv32 (即 riscv32)
+// x86 下架构名中的调用约定为默认约定，函数可通过 build callconv(...) 单独指定；x86_64 只有 System V 约定，忽略该标志
 // 参数:
 //   - archName: 架构名称字符串
 //   - ctx: 编译上下文
@@ -18,19 +25,83 @@ func NewArch(archName string, ctx *context.Context) arch.Arch {
 	var archHandle arch.Arch
 
 	switch archName {
-	case "x86":
-		archHandle = x86.NewCdecl(ctx)
-	case "x86.cdecl":
-		archHandle = x86.NewCdecl(ctx)
+	case "x86", "x86.cdecl":
+		archHandle = x86.NewDispatcher(ctx, "cdecl")
 	case "x86.stdcall":
-		archHandle = x86.NewStdcall(ctx)
-	/*case "x86.fastcall":
-	return &x86FastcallArch{}*/
+		archHandle = x86.NewDispatcher(ctx, "stdcall")
+	case "x86.fastcall":
+		archHandle = x86.NewDispatcher(ctx, "fastcall")
+	case "x86_64", "x86_64.sysv":
+		archHandle = x86_64.NewSysV(ctx)
+	case "c", "c99":
+		archHandle = c99.NewC(ctx)
+	case "wasm32", "wasm":
+		archHandle = wasm.NewWasm(ctx)
+	case "llvm":
+		archHandle = llvm.NewLLVM(ctx)
+	case "rv64", "riscv64":
+		archHandle = riscv.NewRISCV(ctx, 8)
+	case "rv32", "riscv32":
+		archHandle = riscv.NewRISCV(ctx, 4)
 	default:
 		// 默认使用 cdecl 调用约定
-		archHandle = x86.NewCdecl(ctx)
+		archHandle = x86.NewDispatcher(ctx, "cdecl")
 	}
 
 	ctx.Arch = archHandle
 	return archHandle
 }
+
+// NewBackend 根据架构名称创建基于 IR 的后端，目前只有 32 位 x86 支持，调用约定的含义与 NewArch 相同
+func NewBackend(archName string, ctx *context.Context) arch.Backend {
+	switch archName {
+	case "x86", "x86.cdecl":
+		return x86.NewBackend(ctx, "cdecl")
+	case "x86.stdcall":
+		return x86.NewBackend(ctx, "stdcall")
+	case "x86.fastcall":
+		return x86.NewBackend(ctx, "fastcall")
+	}
+	panic("编译器内部错误: 架构 " + archName + " 没有基于 IR 的后端")
+}
+
+// HasBackend 报告架构是否有基于 IR 的后端
+func HasBackend(archName string) bool {
+	switch archName {
+	case "x86", "x86.cdecl", "x86.stdcall", "x86.fastcall":
+		return true
+	}
+	return false
+}
+
+// WordSize 返回架构的字长（字节）：x86_64 与 rv64 为 8，llvm 按 64 位目标为 8，其余为 4
+func WordSize(archName string) int {
+	switch archName {
+	case "llvm", "rv64", "riscv64":
+		return 8
+	}
+	if strings.HasPrefix(archName, "x86_64") {
+		return 8
+	}
+	return 4
+}
+
+// IsAsm 报告架构是否生成 NASM 汇编（C、WebAssembly、LLVM 与 RISC-V 后端不生成 NASM 汇编）
+func IsAsm(archName string) bool {
+	return OutputName(archName) == "_main.asm"
+}
+
+// OutputName 返回生成代码的文件名：C 后端为 _main.c，WebAssembly 后端为 _main.wat，LLVM 后端为 _main.ll，RISC-V 后端为 _main.s（GNU as 语法），其余为 _main.asm
+func OutputName(archName string) string {
+	switch archName {
+	case "c", "c99":
+		return "_main.c"
+	case "wasm32", "wasm":
+		return "_main.wat"
+	case "llvm":
+		return "_main.ll"
+	case "rv64", "riscv64", "rv32", "riscv32":
+		return "_main.s"
+	}
+	return "_main.asm"
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"cuteify/compile"
+	"cuteify/compile/asm"
+	"cuteify/compile/elf"
+	stdelf "debug/elf"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// TestELF 用内置汇编器与 ELF 写出器（-o）处理 x86Cases 中程序生成的汇编：可执行文件检查文件头、程序头与入口，
+// 能运行 32 位程序时直接运行并检查退出码与标准错误输出；目标文件检查节、符号与重定位，找到 ld 时链接后运行
+func TestELF(t *testing.T) {
+	eachX86Case(t, func(t *testing.T, c x86Case) {
+		code, _ := runX86(t, c, &compile.Compiler{})
+		obj, err := asm.Assemble(code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := t.TempDir()
+
+		exe, err := elf.WriteExecutable(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkExecutable(t, exe)
+		path := filepath.Join(dir, "main")
+		if err := os.WriteFile(path, exe, 0755); err != nil {
+			t.Fatal(err)
+		}
+		runELF(t, c, path)
+
+		rel, err := elf.WriteObject(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkObject(t, obj, rel)
+		ld, err := exec.LookPath("ld")
+		if err != nil {
+			return
+		}
+		objPath, linked := filepath.Join(dir, "main.o"), filepath.Join(dir, "linked")
+		if err := os.WriteFile(objPath, rel, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if out, err := exec.Command(ld, "-m", "elf_i386", "-o", linked, objPath).CombinedOutput(); err != nil {
+			t.Fatalf("ld 链接失败: %v\n%s", err, out)
+		}
+		runELF(t, c, linked)
+	})
+}
+
+// checkExecutable 检查静态可执行文件：ELF32 i386，入口位于可执行的装载段中，段按页对齐
+func checkExecutable(t *testing.T, data []byte) {
+	t.Helper()
+	f, err := stdelf.NewFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Class != stdelf.ELFCLASS32 || f.Machine != stdelf.EM_386 || f.Type != stdelf.ET_EXEC {
+		t.Fatalf("文件头为 %v %v %v，应为 ELFCLASS32 EM_386 ET_EXEC", f.Class, f.Machine, f.Type)
+	}
+	entry := false
+	for _, p := range f.Progs {
+		if p.Type != stdelf.PT_LOAD {
+			continue
+		}
+		if p.Vaddr%0x1000 != p.Off%0x1000 {
+			t.Errorf("装载段的地址 %#x 与文件偏移 %#x 不同余", p.Vaddr, p.Off)
+		}
+		// 只有 .bss 的段在文件中没有内容，偏移可以超出文件末尾
+		if p.Filesz > p.Memsz || p.Filesz > 0 && p.Off+p.Filesz > uint64(len(data)) {
+			t.Errorf("装载段的大小无效: 文件中 %d，内存中 %d", p.Filesz, p.Memsz)
+		}
+		if p.Flags&stdelf.PF_X != 0 && f.Entry >= p.Vaddr && f.Entry < p.Vaddr+p.Filesz {
+			entry = true
+		}
+	}
+	if !entry {
+		t.Errorf("入口 %#x 不在可执行的装载段中", f.Entry)
+	}
+}
+
+// checkObject 检查可重定位目标文件：汇编结果中的节都被写出，_start 为 .text 中的全局符号，重定位的数量与汇编结果一致
+func checkObject(t *testing.T, obj *asm.Object, data []byte) {
+	t.Helper()
+	f, err := stdelf.NewFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Class != stdelf.ELFCLASS32 || f.Machine != stdelf.EM_386 || f.Type != stdelf.ET_REL {
+		t.Fatalf("文件头为 %v %v %v，应为 ELFCLASS32 EM_386 ET_REL", f.Class, f.Machine, f.Type)
+	}
+	relocs := 0
+	for _, s := range obj.Sections {
+		if f.Section(s.Name) == nil {
+			t.Errorf("缺少节 %s", s.Name)
+		}
+		if rel := f.Section(".rel" + s.Name); rel != nil {
+			relocs += int(rel.Size) / 8
+		}
+	}
+	if relocs != len(obj.Relocs) {
+		t.Errorf("有 %d 个重定位，汇编结果中为 %d 个", relocs, len(obj.Relocs))
+	}
+	syms, err := f.Symbols()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range syms {
+		if s.Name != "_start" {
+			continue
+		}
+		if stdelf.ST_BIND(s.Info) != stdelf.STB_GLOBAL || f.Sections[s.Section].Name != ".text" {
+			t.Errorf("_start 应为 .text 中的全局符号")
+		}
+		return
+	}
+	t.Error("缺少符号 _start")
+}
+
+// runELF 在临时目录中运行生成的程序，检查退出码与标准错误输出；系统不能运行 32 位程序时跳过
+func runELF(t *testing.T, c x86Case, path string) {
+	t.Helper()
+	cmd := exec.Command(path)
+	cmd.Dir = t.TempDir()
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	exit := 0
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		switch {
+		case errors.As(err, &exitErr):
+			exit = exitErr.ExitCode()
+		case errors.Is(err, syscall.ENOEXEC):
+			t.Skip("不能运行 32 位 x86 程序")
+		default:
+			t.Fatal(err)
+		}
+	}
+	if exit != c.exit {
+		t.Errorf("%s 的退出码为 %d，应为 %d", filepath.Base(path), exit, c.exit)
+	}
+	if !strings.Contains(stderr.String(), c.stderr) {
+		t.Errorf("%s 的标准错误输出为 %q，应包含 %q", filepath.Base(path), stderr.String(), c.stderr)
+	}
+}
@@ -147,13 +147,11 @@ func (e *Error) GetErrPos(start int, end int) string {
 func (e *Error) MissError(errType string, cursor int, msg string) {
 	fmt.Println(e.GetErrPos(cursor, cursor+1) + "\033[31m" + errType + ":\033[0m " + msg)
 	panic("")
-	os.Exit(1)
 }
 
 func (e *Error) MissErrors(errType string, start int, end int, msg string) {
 	fmt.Println(e.GetErrPos(start, end) + "\033[31m" + errType + ":\033[0m " + msg)
 	panic("")
-	os.Exit(1)
 }
 
 func (e *Error) STOP() {
@@ -163,3 +161,8 @@ func (e *Error) STOP() {
 func (e *Error) Warning(msg string) {
 	fmt.Println("\033[33mWarning:\033[0m " + msg)
 }
+
+// WarningAt 输出带源代码位置的警告，不中止编译
+func (e *Error) WarningAt(cursor int, msg string) {
+	fmt.Fprintln(os.Stderr, e.GetErrPos(cursor, cursor+1)+"\033[33mWarning:\033[0m "+msg)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"cuteify/compile"
+	"cuteify/compile/context"
+	"strings"
+	"testing"
+)
+
+// TestInline 经 IR 编译 x86Cases 中的程序（栈帧与图着色两种分配方式），展开函数后在模拟器中运行，退出码与标准错误输出不变，
+// 且执行的指令数不多于不展开时；inline_test 中的递归函数与含内联汇编的函数保持调用，其余的调用都已展开
+func TestInline(t *testing.T) {
+	eachX86Case(t, func(t *testing.T, c x86Case) {
+		for _, alloc := range []context.RegAlloc{context.RegMgrAlloc, context.GraphAlloc} {
+			code, m := runX86(t, c, &compile.Compiler{RegAlloc: alloc})
+			_, plain := runX86(t, c, &compile.Compiler{RegAlloc: alloc, NoInline: true})
+			if m.Steps > plain.Steps {
+				t.Errorf("%s: 执行了 %d 条指令，不展开函数时为 %d 条", alloc, m.Steps, plain.Steps)
+			}
+			t.Logf("%s: 执行的指令数 %d，不展开函数时为 %d", alloc, m.Steps, plain.Steps)
+			if c.name != "inline_test" {
+				continue
+			}
+			for _, fn := range []string{"absdiff", "tri", "shift", "bump"} {
+				if strings.Contains(code, "call "+fn) {
+					t.Errorf("%s: 对 %s 的调用没有展开", alloc, fn)
+				}
+			}
+			for _, fn := range []string{"fact", "seven"} {
+				if !strings.Contains(code, "call "+fn) {
+					t.Errorf("%s: 对 %s 的调用不应展开", alloc, fn)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,514 @@
+package interp
+
+import (
+	"cuteify/compile/arch"
+	"cuteify/compile/data"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"math"
+)
+
+// value 计算表达式：整数按类型扩展到 64 位，布尔值为 0 或 1，浮点数为 f64 的位模式，
+// 指针与字符串为地址，结构体、数组、切片与接口值为其地址
+func (in *Interp) value(exp *parser.Expression) uint64 {
+	if exp.IsConst() {
+		return in.constant(exp)
+	}
+	switch {
+	case exp.Unary != "":
+		return in.unary(exp)
+	case exp.Index != nil:
+		return in.load(in.elemAddr(exp), exp.Type)
+	case exp.Field != nil:
+		return in.load(in.addr(exp), exp.Type)
+	case exp.Separator != "":
+		return in.binary(exp)
+	case exp.Call != nil:
+		return in.call(exp.Call)
+	case exp.Var != nil:
+		if exp.Var.Value != nil {
+			// 赋值表达式的值为赋值后的变量
+			in.assign(exp.Var)
+			if exp.Var.Store != nil {
+				return in.value(exp.Var.Store)
+			}
+		}
+		addr, t := in.varAddr(exp.Var)
+		return in.load(addr, t)
+	}
+	panic("编译器内部错误: 无法计算的表达式")
+}
+
+// eval 计算表达式语句，丢弃表达式的值
+func (in *Interp) eval(exp *parser.Expression) {
+	if exp.Var != nil && exp.Var.Value != nil && exp.Separator == "" && exp.Unary == "" && exp.Index == nil {
+		in.assign(exp.Var)
+		return
+	}
+	if exp.Call != nil && exp.Separator == "" && exp.Unary == "" && exp.Index == nil {
+		in.call(exp.Call)
+		return
+	}
+	in.value(exp)
+}
+
+// cond 计算条件，常量条件在解析时已经折叠
+func (in *Interp) cond(exp *parser.Expression) bool {
+	if exp == nil {
+		return true
+	}
+	return in.value(exp) != 0
+}
+
+// constant 常量的值，字符串常量为其在数据区中的地址
+func (in *Interp) constant(exp *parser.Expression) uint64 {
+	switch {
+	case exp.Type == nil || typeSys.GetTypeType(exp.Type) == "bool":
+		// 折叠后的比较结果没有类型
+		if exp.Bool {
+			return 1
+		}
+		return 0
+	case typeSys.GetTypeType(exp.Type) == "string":
+		return in.intern(exp.StringVal)
+	case typeSys.GetTypeType(exp.Type) == "float":
+		return math.Float64bits(exp.Num)
+	}
+	// 整数常量不按其自身的类型截断，参与运算或赋值时再转换为需要的类型
+	if exp.Num >= 1<<63 {
+		return uint64(exp.Num)
+	}
+	return uint64(int64(exp.Num))
+}
+
+// binary 二元运算，比较的结果为 0 或 1，算术运算的结果按表达式类型截断，
+// 指针加减的偏移量在解析时已按元素大小换算为字节数
+func (in *Interp) binary(exp *parser.Expression) uint64 {
+	switch exp.Separator {
+	case "&&":
+		if !in.cond(exp.Left) {
+			return 0
+		}
+		return in.value(exp.Right)
+	case "||":
+		if in.cond(exp.Left) {
+			return 1
+		}
+		return in.value(exp.Right)
+	}
+	left := in.value(exp.Left)
+	right := in.value(exp.Right)
+	t := exp.Type
+	switch exp.Separator {
+	case "==", "!=", "<", ">", "<=", ">=":
+		t = exp.OperandType()
+		return compare(exp.Separator, convert(left, exp.Left.Type, t), convert(right, exp.Right.Type, t), t)
+	}
+	if t != nil && t.IsPointer() {
+		if exp.Separator == "-" {
+			return (left - right) & word()
+		}
+		return (left + right) & word()
+	}
+	// 两侧按运算的类型计算（常量与较窄的一侧拓宽到该类型）
+	left, right = convert(left, exp.Left.Type, t), convert(right, exp.Right.Type, t)
+	if t != nil && typeSys.GetTypeType(t) == "float" {
+		return floatOp(exp.Separator, math.Float64frombits(left), math.Float64frombits(right), t)
+	}
+	return narrow(intOp(exp.Separator, left, right, signed(t)), t)
+}
+
+// intOp 整数运算，^ 与常量折叠一致按乘方计算
+func intOp(op string, l, r uint64, signed bool) uint64 {
+	switch op {
+	case "+":
+		return l + r
+	case "-":
+		return l - r
+	case "*":
+		return l * r
+	case "/", "%":
+		if r == 0 {
+			fail("整数除零")
+		}
+		switch {
+		case signed && op == "/":
+			return uint64(int64(l) / int64(r))
+		case signed:
+			return uint64(int64(l) % int64(r))
+		case op == "/":
+			return l / r
+		}
+		return l % r
+	case "&":
+		return l & r
+	case "|":
+		return l | r
+	case "<<":
+		return l << (r & 63)
+	case ">>":
+		if signed {
+			return uint64(int64(l) >> (r & 63))
+		}
+		return l >> (r & 63)
+	case "^":
+		result := uint64(1)
+		for ; r > 0 && r < 1<<63; r-- {
+			result *= l
+		}
+		return result
+	}
+	panic("编译器内部错误: 未知的运算符 " + op)
+}
+
+// floatOp 浮点运算，f32 的结果按单精度舍入
+func floatOp(op string, l, r float64, t typeSys.Type) uint64 {
+	var v float64
+	switch op {
+	case "+":
+		v = l + r
+	case "-":
+		v = l - r
+	case "*":
+		v = l * r
+	case "/":
+		v = l / r
+	case "^":
+		v = math.Pow(l, r)
+	default:
+		panic("编译器内部错误: 浮点数不支持运算符 " + op)
+	}
+	if t.Size() == 4 {
+		v = float64(float32(v))
+	}
+	return math.Float64bits(v)
+}
+
+// compare 比较两个值，t 为操作数类型
+func compare(op string, l, r uint64, t typeSys.Type) uint64 {
+	var lt, eq bool
+	switch {
+	case t != nil && typeSys.GetTypeType(t) == "float":
+		lf, rf := math.Float64frombits(l), math.Float64frombits(r)
+		lt, eq = lf < rf, lf == rf
+	case signed(t):
+		lt, eq = int64(l) < int64(r), l == r
+	default:
+		lt, eq = l < r, l == r
+	}
+	var result bool
+	switch op {
+	case "==":
+		result = eq
+	case "!=":
+		result = !eq
+	case "<":
+		result = lt
+	case ">":
+		result = !lt && !eq
+	case "<=":
+		result = lt || eq
+	case ">=":
+		result = !lt
+	}
+	if result {
+		return 1
+	}
+	return 0
+}
+
+// signed 报告类型的值是否按有符号数比较和运算，指针、布尔值与字符串地址按无符号数处理
+func signed(t typeSys.Type) bool {
+	return t != nil && typeSys.CheckTypeType(t, "int")
+}
+
+// narrow 将整数截断到类型 t 的宽度并按其符号扩展回 64 位，指针与字符串截断到字长
+func narrow(v uint64, t typeSys.Type) uint64 {
+	if t == nil {
+		return v
+	}
+	if t.IsPointer() || typeSys.GetTypeType(t) == "string" {
+		return v & word()
+	}
+	bits, isSigned, ok := typeSys.IntInfo(t)
+	if !ok || bits >= 64 {
+		return v
+	}
+	shift := 64 - bits
+	if isSigned {
+		return uint64(int64(v<<shift) >> shift)
+	}
+	return v << shift >> shift
+}
+
+// convert 将按 from 类型表示的值转换为 to 类型：整数截断并重新扩展，整数与浮点数之间按数值转换，浮点数转整数时向零取整
+func convert(v uint64, from, to typeSys.Type) uint64 {
+	if to == nil {
+		return v
+	}
+	fromFloat := from != nil && typeSys.GetTypeType(from) == "float"
+	if typeSys.GetTypeType(to) == "float" {
+		var f float64
+		switch {
+		case fromFloat:
+			f = math.Float64frombits(v)
+		case signed(from):
+			f = float64(int64(v))
+		default:
+			f = float64(v)
+		}
+		if to.Size() == 4 {
+			f = float64(float32(f))
+		}
+		return math.Float64bits(f)
+	}
+	if fromFloat {
+		f := math.Trunc(math.Float64frombits(v))
+		if signed(to) || f < 0 {
+			return narrow(uint64(int64(f)), to)
+		}
+		return narrow(uint64(f), to)
+	}
+	return narrow(v, to)
+}
+
+// unary 一元运算：取地址、解引用、切片长度与类型转换
+func (in *Interp) unary(exp *parser.Expression) uint64 {
+	switch exp.Unary {
+	case "&":
+		return in.addr(exp.Right)
+	case "*":
+		return in.load(in.value(exp.Right), exp.Type)
+	case "len":
+		return in.load(in.value(exp.Right)+uint64(typeSys.PtrSize), exp.Type)
+	case "as":
+		return convert(in.value(exp.Right), exp.Right.Type, exp.Type)
+	}
+	panic("编译器内部错误: 未知的一元运算 " + exp.Unary)
+}
+
+// addr 计算可取地址表达式（变量、字段、解引用、下标）的地址，聚合类型的值本身即为地址
+func (in *Interp) addr(exp *parser.Expression) uint64 {
+	switch {
+	case exp.Unary == "*":
+		return in.value(exp.Right)
+	case exp.Index != nil:
+		return in.elemAddr(exp)
+	case exp.Field != nil:
+		return in.addr(exp.Left) + uint64(exp.StructField().Offset)
+	case exp.Var != nil && exp.Unary == "" && exp.Separator == "" && exp.Var.Value == nil:
+		addr, _ := in.varAddr(exp.Var)
+		return addr
+	case isAggregate(exp.Type):
+		return in.value(exp)
+	}
+	panic("编译器内部错误: 表达式不能取地址")
+}
+
+// elemAddr 计算 a[i] 的元素地址，开启越界检查时下标越界（包括负数下标）按编译器的越界例程结束程序
+// 下标可能含有函数调用，因此先于数组地址计算
+func (in *Interp) elemAddr(exp *parser.Expression) uint64 {
+	index := in.value(exp.Index) & word()
+	base := in.addr(exp.Left)
+	var length uint64
+	switch t := exp.Left.Type.(type) {
+	case *typeSys.ArrayType:
+		length = uint64(t.Len)
+	case *typeSys.SliceType:
+		length = in.load(base+uint64(typeSys.PtrSize), nil)
+		base = in.load(base, nil)
+	default:
+		panic("编译器内部错误: 不能对 " + exp.Left.Type.Type() + " 使用下标")
+	}
+	if in.BoundsCheck && index >= length {
+		in.boundsPanic()
+	}
+	return (base + index*uint64(exp.Type.Size())) & word()
+}
+
+// boundsPanic 与编译器的越界例程一样向 stderr 输出提示并以退出码 2 结束
+func (in *Interp) boundsPanic() {
+	in.Stderr.WriteString("panic: index out of range\n")
+	panic(exitSignal{code: 2})
+}
+
+// assign 执行赋值，包括通过指针或下标的赋值
+func (in *Interp) assign(v *parser.VarBlock) {
+	if v.Store != nil {
+		t := v.Store.Type
+		if isAggregate(t) {
+			src := in.addr(v.Value)
+			in.storeAggregate(v.Value.Type, t, src, in.addr(v.Store))
+			return
+		}
+		value := convert(in.value(v.Value), v.Value.Type, t)
+		in.store(in.addr(v.Store), t, value)
+		return
+	}
+	if isAggregate(in.varType(v)) {
+		src := in.addr(v.Value)
+		dst, t := in.varAddr(v)
+		in.storeAggregate(v.Value.Type, t, src, dst)
+		return
+	}
+	value := in.value(v.Value)
+	dst, t := in.varAddr(v)
+	in.store(dst, t, convert(value, v.Value.Type, t))
+}
+
+// varType 返回变量引用（包括字段访问）的类型
+func (in *Interp) varType(v *parser.VarBlock) typeSys.Type {
+	if in.frame != nil && v.IsSelf(in.frame.fn) && !v.Name.IsPath() {
+		return in.frame.fn.Class
+	}
+	return v.Type
+}
+
+// varAddr 返回变量（或其字段）的地址与类型：局部变量与参数在当前栈帧中，self 为接收者的地址，全局变量在数据区中
+func (in *Interp) varAddr(v *parser.VarBlock) (uint64, typeSys.Type) {
+	var addr uint64
+	var t typeSys.Type
+	switch {
+	case in.frame != nil && v.IsSelf(in.frame.fn):
+		addr, t = in.frame.self, in.frame.fn.Class
+	case data.IsGlobal(v):
+		def := data.Define(v)
+		var ok bool
+		if addr, ok = in.globals[def]; !ok {
+			fail("全局变量 %s 尚未初始化", def.Name)
+		}
+		t = def.Type
+	default:
+		var key any = v
+		t = v.Type
+		if v.Define != nil {
+			switch def := v.Define.Value.(type) {
+			case *parser.VarBlock:
+				key, t = def, def.Type
+			case *parser.ArgBlock:
+				key, t = def, def.Type
+			}
+		}
+		var ok bool
+		if addr, ok = in.frame.vars[key]; !ok {
+			panic("编译器内部错误: 变量 " + v.Name.String() + " 不在当前栈帧中")
+		}
+	}
+	for _, name := range v.Name[1:] {
+		st, ok := t.(*typeSys.StructType)
+		if !ok {
+			panic("编译器内部错误: " + t.Type() + " 不是结构体")
+		}
+		field := st.Field(name)
+		if field == nil {
+			panic("编译器内部错误: 结构体 " + t.Type() + " 没有字段 " + name)
+		}
+		addr += uint64(field.Offset)
+		t = field.Type
+	}
+	return addr, t
+}
+
+// call 执行函数调用，返回返回值（没有返回值时为 0）
+// 实参按形参类型计算，聚合类型的实参先复制到栈上的临时空间；接口方法按接口值中的虚表找到实际结构体的方法
+func (in *Interp) call(call *parser.CallBlock) uint64 {
+	if call == nil || call.Func == nil {
+		return 0
+	}
+	mark := in.sp
+	fn := call.Func
+	var self uint64
+	if call.ThisVar != nil {
+		addr, t := in.varAddr(call.ThisVar)
+		self = addr
+		if _, ok := t.(*typeSys.InterfaceType); ok {
+			self = in.load(addr, nil)
+			id := in.load(addr+uint64(typeSys.PtrSize), nil)
+			if id == 0 || id > uint64(len(in.vtables)) {
+				fail("通过空接口值调用方法 %s", call.Name.Last())
+			}
+			if fn = parser.FindMethod(in.vtables[id-1], call.Name.Last()); fn == nil {
+				fail("%s 没有方法 %s", in.vtables[id-1].Type(), call.Name.Last())
+			}
+		}
+	}
+	args := make([]uint64, len(call.Args))
+	for i, arg := range call.Args {
+		if arg == nil {
+			continue
+		}
+		if isAggregate(arg.Type) {
+			tmp := in.alloc(arg.Type)
+			in.storeAggregate(arg.Value.Type, arg.Type, in.addr(arg.Value), tmp)
+			args[i] = tmp
+			continue
+		}
+		args[i] = convert(in.value(arg.Value), arg.Value.Type, arg.Type)
+	}
+	ret := in.invoke(fn, self, args)
+	in.sp = mark
+	if len(fn.Return) > 0 && isAggregate(fn.Return[0]) {
+		// 返回值位于已经释放的被调函数栈帧中，复制到调用方的临时空间
+		tmp := in.alloc(fn.Return[0])
+		in.mem.move(tmp, ret, fn.Return[0].Size())
+		ret = tmp
+	}
+	return ret
+}
+
+// invoke 调用函数：外部函数交给宿主函数，其余函数在新的栈帧中执行函数体
+func (in *Interp) invoke(fn *parser.FuncBlock, self uint64, args []uint64) uint64 {
+	if ext := arch.ExtName(fn); ext != "" {
+		host, ok := in.Host[ext]
+		if !ok {
+			fail("外部函数 %s 没有宿主实现", ext)
+		}
+		ret := host(in, args)
+		if len(fn.Return) > 0 {
+			ret = narrow(ret, fn.Return[0])
+		}
+		return ret
+	}
+	node, ok := in.funcs[fn]
+	if !ok {
+		panic("编译器内部错误: 找不到函数 " + fn.Name.String() + " 的函数体")
+	}
+
+	base := in.sp
+	f := &frame{fn: fn, self: self, vars: make(map[any]uint64)}
+	for i, arg := range fn.Args {
+		addr := in.alloc(arg.Type)
+		f.vars[arg] = addr
+		switch {
+		case i >= len(args):
+			if arg.Default != nil {
+				in.storeValue(arg.Default, arg.Type, addr)
+			}
+		case isAggregate(arg.Type):
+			in.mem.move(addr, args[i], arg.Type.Size())
+		default:
+			in.store(addr, arg.Type, args[i])
+		}
+	}
+	in.locals(node, f)
+
+	caller := in.frame
+	in.frame = f
+	in.block(node)
+	in.frame = caller
+	in.sp = base
+	return f.ret
+}
+
+// locals 为函数体中定义的局部变量（包括 for 循环的初始化变量）分配栈空间
+func (in *Interp) locals(n *parser.Node, f *frame) {
+	for _, child := range n.Children {
+		if v, ok := child.Value.(*parser.VarBlock); ok && v.IsDefine {
+			f.vars[v] = in.alloc(v.Type)
+		}
+		in.locals(child, f)
+		if ifBlock, ok := child.Value.(*parser.IfBlock); ok && ifBlock.Else {
+			in.locals(ifBlock.ElseBlock, f)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package interp
+
+import (
+	"cuteify/parser"
+	"strconv"
+	"strings"
+)
+
+// flow 语句执行后的控制流
+type flow int
+
+const (
+	flowNext     flow = iota // 继续执行下一条语句
+	flowBreak                // 跳出最内层的循环或 switch
+	flowContinue             // 进入最内层循环的下一次迭代
+	flowReturn               // 从函数返回
+)
+
+// block 依次执行节点的子语句，语句中分配的临时空间在语句结束后释放
+func (in *Interp) block(n *parser.Node) flow {
+	for _, child := range n.Children {
+		if child.Ignore {
+			continue
+		}
+		mark := in.sp
+		f := in.stmt(child)
+		in.sp = mark
+		if f != flowNext {
+			return f
+		}
+	}
+	return flowNext
+}
+
+// stmt 执行一条语句
+func (in *Interp) stmt(n *parser.Node) flow {
+	in.step()
+	switch v := n.Value.(type) {
+	case *parser.VarBlock:
+		in.varBlock(v)
+	case *parser.CallBlock:
+		in.call(v)
+	case *parser.IfBlock:
+		if in.cond(v.Condition) {
+			return in.block(n)
+		}
+		if v.Else && in.cond(v.ElseBlock.Value.(*parser.ElseBlock).IfCondition) {
+			return in.block(v.ElseBlock)
+		}
+	case *parser.ForBlock:
+		return in.forBlock(n, v)
+	case *parser.WhileBlock:
+		return in.loop(n, v.Condition, nil)
+	case *parser.SwitchBlock:
+		return in.switchBlock(n, v)
+	case *parser.ReturnBlock:
+		in.returnBlock(v)
+		return flowReturn
+	case *parser.BreakBlock:
+		return flowBreak
+	case *parser.ContinueBlock:
+		return flowContinue
+	case *parser.Build:
+		if v.Type == "asm" {
+			in.asm(v)
+		}
+	}
+	return flowNext
+}
+
+// varBlock 执行变量定义或赋值，没有初始值的定义清零后按结构体字段默认值初始化
+func (in *Interp) varBlock(v *parser.VarBlock) {
+	if v.IsDefine && v.Value == nil {
+		addr, t := in.varAddr(v)
+		in.mem.mustWriteBytes(addr, make([]byte, max(t.Size(), 1)))
+		in.defaults(addr, t)
+		return
+	}
+	in.assign(v)
+}
+
+func (in *Interp) forBlock(n *parser.Node, forBlock *parser.ForBlock) flow {
+	if forBlock.Init != nil {
+		in.eval(forBlock.Init)
+	}
+	return in.loop(n, forBlock.Condition, forBlock.Increment)
+}
+
+// loop 执行循环体直到条件不成立，每次迭代后计算 increment
+func (in *Interp) loop(n *parser.Node, cond, increment *parser.Expression) flow {
+	mark := in.sp
+	for {
+		in.step()
+		ok := in.cond(cond)
+		in.sp = mark
+		if !ok {
+			return flowNext
+		}
+		switch in.block(n) {
+		case flowBreak:
+			return flowNext
+		case flowReturn:
+			return flowReturn
+		}
+		if increment != nil {
+			in.eval(increment)
+			in.sp = mark
+		}
+	}
+}
+
+// switchBlock 执行第一个匹配的分支，没有匹配的分支时执行 default；break 跳出 switch，continue 作用于外层循环
+func (in *Interp) switchBlock(n *parser.Node, switchBlock *parser.SwitchBlock) flow {
+	value := int64(in.value(switchBlock.Value))
+	matched := switchBlock.Default
+	for _, caseBlock := range switchBlock.Cases {
+		for _, v := range caseBlock.Values {
+			if !caseBlock.IsDefault && v.CaseValue() == value {
+				matched = caseBlock
+				break
+			}
+		}
+		if matched != switchBlock.Default {
+			break
+		}
+	}
+	if matched == nil {
+		return flowNext
+	}
+	for _, caseNode := range n.Children {
+		if caseNode.Value != matched || caseNode.Ignore {
+			continue
+		}
+		if f := in.block(caseNode); f != flowBreak {
+			return f
+		}
+	}
+	return flowNext
+}
+
+// returnBlock 计算返回值并按函数的返回类型保存在栈帧中
+func (in *Interp) returnBlock(ret *parser.ReturnBlock) {
+	if len(ret.Value) == 0 || len(in.frame.fn.Return) == 0 {
+		return
+	}
+	value, t := ret.Value[0], in.frame.fn.Return[0]
+	if isAggregate(t) {
+		tmp := in.alloc(t)
+		in.storeAggregate(value.Type, t, in.addr(value), tmp)
+		in.frame.ret = tmp
+		return
+	}
+	in.frame.ret = convert(in.value(value), value.Type, t)
+}
+
+// asmRegs 内联汇编中可以设置的 i386 寄存器，依次为系统调用号与 6 个参数
+var asmRegs = map[string]int{"eax": 0, "ebx": 1, "ecx": 2, "edx": 3, "esi": 4, "edi": 5, "ebp": 6}
+
+// asm 模拟 build asm 中的系统调用：只支持用 mov 把常量或 $变量 载入寄存器，以及 int 0x80
+func (in *Interp) asm(build *parser.Build) {
+	var regs [7]uint64
+	for _, line := range strings.Split(build.Asm, "\n") {
+		if i := strings.IndexByte(line, ';'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		op, operands, _ := strings.Cut(line, " ")
+		switch strings.ToLower(op) {
+		case "mov":
+			dst, src, ok := strings.Cut(operands, ",")
+			reg, isReg := asmRegs[strings.ToLower(strings.TrimSpace(dst))]
+			if !ok || !isReg {
+				fail("解释器不支持内联汇编指令 %s", line)
+			}
+			regs[reg] = in.asmOperand(build, strings.TrimSpace(src), line)
+		case "int":
+			if strings.TrimSpace(operands) != "0x80" {
+				fail("解释器不支持内联汇编指令 %s", line)
+			}
+			regs[0] = uint64(uint32(in.syscall(regs[0], regs[1:])))
+		default:
+			fail("解释器不支持内联汇编指令 %s", line)
+		}
+	}
+}
+
+// asmOperand 返回 mov 的源操作数：整数常量或 $变量 的值
+func (in *Interp) asmOperand(build *parser.Build, src, line string) uint64 {
+	if name, ok := strings.CutPrefix(src, "$"); ok {
+		v, ok := build.VarMap[name]
+		if !ok {
+			fail("内联汇编引用了未定义的变量 %s", name)
+		}
+		addr, t := in.varAddr(v)
+		return in.load(addr, t)
+	}
+	n, err := strconv.ParseInt(src, 0, 64)
+	if err != nil {
+		fail("解释器不支持内联汇编指令 %s", line)
+	}
+	return uint64(n)
+}
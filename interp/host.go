@@ -0,0 +1,232 @@
+package interp
+
+// HostFunc 宿主函数，实现 build ext 声明的外部函数：参数与返回值都按字长传递，聚合类型的参数为其地址
+type HostFunc func(in *Interp, args []uint64) uint64
+
+// DefaultHost 返回默认的宿主函数：C 库中的 exit、read、write、open、close、malloc、free、mmap、munmap，
+// 以及运行时库使用的 syscall1、syscall3（第一个参数为 i386 的系统调用号）
+func DefaultHost() map[string]HostFunc {
+	sys := func(num uint64) HostFunc {
+		return func(in *Interp, args []uint64) uint64 {
+			return uint64(in.syscall(num, args))
+		}
+	}
+	syscallN := func(in *Interp, args []uint64) uint64 {
+		a := arg6(args)
+		return uint64(in.syscall(a[0], a[1:]))
+	}
+	return map[string]HostFunc{
+		"exit":     sys(sysExit),
+		"read":     sys(sysRead),
+		"write":    sys(sysWrite),
+		"open":     sys(sysOpen),
+		"close":    sys(sysClose),
+		"munmap":   sys(sysMunmap),
+		"syscall1": syscallN,
+		"syscall3": syscallN,
+		"mmap": func(in *Interp, args []uint64) uint64 {
+			a := arg6(args)
+			return uint64(in.sysMmap(a[1], a[3], int32(a[4])))
+		},
+		"malloc": func(in *Interp, args []uint64) uint64 {
+			return in.malloc(arg6(args)[0])
+		},
+		"free": func(in *Interp, args []uint64) uint64 {
+			return 0
+		},
+	}
+}
+
+// i386 Linux 的系统调用号
+const (
+	sysExit      = 1
+	sysRead      = 3
+	sysWrite     = 4
+	sysOpen      = 5
+	sysClose     = 6
+	sysMunmap    = 91
+	sysMmap2     = 192
+	sysExitGroup = 252
+)
+
+// 错误码，系统调用失败时返回其相反数
+const (
+	errNOENT  = 2
+	errBADF   = 9
+	errNOMEM  = 12
+	errFAULT  = 14
+	errINVAL  = 22
+	errNOSYS  = 38
+	errNODEV  = 19
+	errACCESS = 13
+)
+
+// open 的标志
+const (
+	oAccMode = 3
+	oWronly  = 1
+	oCreat   = 0x40
+	oTrunc   = 0x200
+	oAppend  = 0x400
+)
+
+// mapAnonymous mmap 的匿名映射标志
+const mapAnonymous = 0x20
+
+// file 打开的文件
+type file struct {
+	path   string
+	offset int
+	flags  uint64
+}
+
+// arg6 将参数补齐为 6 个
+func arg6(args []uint64) (a [6]uint64) {
+	copy(a[:], args)
+	return a
+}
+
+// syscall 模拟 i386 Linux 的系统调用，失败时返回错误码的相反数，不支持的调用号返回 -ENOSYS
+func (in *Interp) syscall(num uint64, args []uint64) int64 {
+	a := arg6(args)
+	switch num {
+	case sysExit, sysExitGroup:
+		panic(exitSignal{code: int(a[0] & 0xFF)})
+	case sysRead:
+		return in.sysRead(int32(a[0]), a[1], a[2])
+	case sysWrite:
+		return in.sysWrite(int32(a[0]), a[1], a[2])
+	case sysOpen:
+		return in.sysOpen(a[0], a[1])
+	case sysClose:
+		if _, ok := in.files[int(int32(a[0]))]; !ok {
+			return -errBADF
+		}
+		delete(in.files, int(int32(a[0])))
+		return 0
+	case sysMmap2:
+		return in.sysMmap(a[1], a[3], int32(a[4]))
+	case sysMunmap:
+		if a[0]%pageSize != 0 || a[1] == 0 {
+			return -errINVAL
+		}
+		in.mem.unmap(a[0], a[1])
+		return 0
+	}
+	return -errNOSYS
+}
+
+func (in *Interp) sysRead(fd int32, buf, count uint64) int64 {
+	var data []byte
+	if fd == 0 {
+		data = in.Stdin[in.stdinPos:]
+	} else if f, ok := in.files[int(fd)]; !ok || f.flags&oAccMode == oWronly {
+		return -errBADF
+	} else if content := in.FS[f.path]; f.offset < len(content) {
+		data = content[f.offset:]
+	}
+	if uint64(len(data)) > count {
+		data = data[:count]
+	}
+	if !in.mem.writeBytes(buf, data) {
+		return -errFAULT
+	}
+	if fd == 0 {
+		in.stdinPos += len(data)
+	} else {
+		in.files[int(fd)].offset += len(data)
+	}
+	return int64(len(data))
+}
+
+func (in *Interp) sysWrite(fd int32, buf, count uint64) int64 {
+	data, ok := in.mem.readBytes(buf, count)
+	if !ok {
+		return -errFAULT
+	}
+	switch fd {
+	case 1:
+		in.Stdout.Write(data)
+	case 2:
+		in.Stderr.Write(data)
+	default:
+		f, ok := in.files[int(fd)]
+		if !ok || f.flags&oAccMode == 0 {
+			return -errBADF
+		}
+		content := in.FS[f.path]
+		if f.flags&oAppend != 0 {
+			f.offset = len(content)
+		}
+		if end := f.offset + len(data); end > len(content) {
+			content = append(content, make([]byte, end-len(content))...)
+		}
+		copy(content[f.offset:], data)
+		in.FS[f.path] = content
+		f.offset += len(data)
+	}
+	return int64(len(data))
+}
+
+// sysOpen 在内存文件系统中打开文件，返回最小的空闲描述符
+func (in *Interp) sysOpen(pathAddr, flags uint64) int64 {
+	path, ok := in.mem.readString(pathAddr)
+	if !ok {
+		return -errFAULT
+	}
+	if path == "" {
+		return -errNOENT
+	}
+	if _, ok := in.FS[path]; !ok {
+		if flags&oCreat == 0 {
+			return -errNOENT
+		}
+		in.FS[path] = nil
+	}
+	if flags&oTrunc != 0 {
+		if flags&oAccMode == 0 {
+			return -errACCESS
+		}
+		in.FS[path] = nil
+	}
+	fd := 3
+	for in.files[fd] != nil {
+		fd++
+	}
+	in.files[fd] = &file{path: path, flags: flags}
+	return int64(fd)
+}
+
+// sysMmap 分配匿名映射，页的内容为零
+func (in *Interp) sysMmap(length, flags uint64, fd int32) int64 {
+	if length == 0 {
+		return -errINVAL
+	}
+	if flags&mapAnonymous == 0 || fd != -1 && fd != 0 {
+		return -errNODEV
+	}
+	in.heapNext = alignUp(in.heapNext, pageSize)
+	addr, ok := in.heapAlloc(alignUp(length, pageSize))
+	if !ok {
+		return -errNOMEM
+	}
+	return int64(addr)
+}
+
+// malloc 从堆中分配按 8 字节对齐的内存，失败时返回 0
+func (in *Interp) malloc(size uint64) uint64 {
+	in.heapNext = alignUp(in.heapNext, 8)
+	addr, _ := in.heapAlloc(max(size, 1))
+	return addr
+}
+
+// heapAlloc 从堆的空闲地址起分配 size 字节
+func (in *Interp) heapAlloc(size uint64) (uint64, bool) {
+	if in.heapNext+size > heapLimit || in.heapNext+size < in.heapNext {
+		return 0, false
+	}
+	addr := in.heapNext
+	in.heapNext += size
+	in.mem.mapRange(addr, size)
+	return addr, true
+}
@@ -0,0 +1,211 @@
+// Package interp 不经代码生成，直接在 parser.Node 语法树上解释执行程序，作为比较各后端输出的参考实现。
+//
+// 值存放在模拟的字节内存中，布局与编译器一致：标量按 typeSys 给出的大小存放（指针、int 与字符串为 typeSys.PtrSize），
+// 结构体字段位于各自的 Offset，切片为 (数据地址, 长度)，接口值为 (数据地址, 虚表)，因此取地址、指针运算、
+// 类型转换与下标访问的结果与生成的代码相同。整数运算的结果按表达式的类型截断，比较按操作数类型区分有符号与无符号，
+// 与 Expression.Check 推导出的类型一致。
+//
+// build ext 声明的外部函数由 Host 中同名的宿主函数实现；build asm 中先用 mov 设置寄存器、再执行 int 0x80 的系统调用
+// 与宿主函数共用一套 i386 Linux 系统调用的模拟，文件系统与标准输入输出都在内存中。
+package interp
+
+import (
+	"bytes"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"fmt"
+)
+
+// DefaultMaxSteps 默认最多执行的语句数，防止死循环的程序不结束
+const DefaultMaxSteps = 10_000_000
+
+// Interp 解释器，Run 之前可以设置文件系统、标准输入、宿主函数与越界检查
+type Interp struct {
+	FS          map[string][]byte   // 内存文件系统，路径到文件内容
+	Stdin       []byte              // 标准输入的内容
+	Stdout      bytes.Buffer        // 写入 fd 1 的内容
+	Stderr      bytes.Buffer        // 写入 fd 2 的内容
+	Host        map[string]HostFunc // build ext 的外部函数名到宿主函数
+	MaxSteps    int                 // 最多执行的语句数，0 表示不限制
+	BoundsCheck bool                // 下标越界时与编译器插入的检查一样输出提示并以退出码 2 结束
+
+	root    *parser.Node
+	funcs   map[*parser.FuncBlock]*parser.Node // 函数定义（包括泛型实例）到其函数体
+	mem     memory
+	globals map[*parser.VarBlock]uint64 // 全局变量的地址
+	strs    map[string]uint64           // 字符串字面量的地址
+	vtables []*typeSys.StructType       // 接口值中的虚表编号减一为下标，对应实现接口的结构体
+
+	frame    *frame
+	sp       uint64 // 栈顶，栈向低地址增长
+	dataNext uint64 // 数据区中下一个空闲地址
+	heapNext uint64 // malloc 与 mmap 的下一个空闲地址
+	steps    int
+
+	files    map[int]*file
+	stdinPos int
+}
+
+// frame 一次函数调用的栈帧
+type frame struct {
+	fn   *parser.FuncBlock
+	self uint64         // 方法接收者的地址
+	vars map[any]uint64 // 变量定义（*parser.VarBlock 或 *parser.ArgBlock）到其地址
+	ret  uint64         // 返回值，聚合类型为其地址
+}
+
+// runtimeError 执行中的错误（访问未映射的地址、除零等），以 panic 抛出，在 Run 中恢复为 error
+type runtimeError string
+
+func (e runtimeError) Error() string {
+	return string(e)
+}
+
+// exitSignal 程序通过 exit 系统调用结束，以 panic 抛出，在 Run 中恢复为退出码
+type exitSignal struct {
+	code int
+}
+
+// fail 以 runtimeError 结束执行
+func fail(format string, args ...any) {
+	panic(runtimeError(fmt.Sprintf(format, args...)))
+}
+
+// New 为整个程序（GetPackage 得到的合并后的 AST）创建解释器
+func New(root *parser.Node) *Interp {
+	in := &Interp{
+		FS:       make(map[string][]byte),
+		Host:     DefaultHost(),
+		MaxSteps: DefaultMaxSteps,
+		root:     root,
+		funcs:    make(map[*parser.FuncBlock]*parser.Node),
+		mem:      memory{pages: make(map[uint64]*[pageSize]byte)},
+		globals:  make(map[*parser.VarBlock]uint64),
+		strs:     make(map[string]uint64),
+		sp:       stackTop,
+		dataNext: dataBase,
+		heapNext: heapBase,
+		files:    make(map[int]*file),
+	}
+	in.mem.mapRange(stackTop-stackSize, stackSize)
+	in.collect(root)
+	return in
+}
+
+// Run 解释执行整个程序，返回退出码（main 的返回值或 exit 的参数，取低 8 位）与标准输出
+func Run(root *parser.Node) (exitCode int, stdout []byte, err error) {
+	in := New(root)
+	exitCode, err = in.Run()
+	return exitCode, in.Stdout.Bytes(), err
+}
+
+// Run 初始化全局变量后调用 main，程序执行出错时返回 error
+func (in *Interp) Run() (exitCode int, err error) {
+	defer func() {
+		switch r := recover().(type) {
+		case nil:
+		case exitSignal:
+			exitCode = r.code
+		case runtimeError:
+			err = r
+		default:
+			panic(r)
+		}
+	}()
+	var main *parser.FuncBlock
+	for _, n := range in.root.Children {
+		if fb, ok := n.Value.(*parser.FuncBlock); ok && !n.Ignore && fb.Name.String() == "main" {
+			main = fb
+		}
+	}
+	if main == nil {
+		return 0, runtimeError("程序没有 main 函数")
+	}
+	for _, n := range in.root.Children {
+		if v, ok := n.Value.(*parser.VarBlock); ok && v.IsGlobal && !n.Ignore {
+			in.global(v)
+		}
+	}
+	ret := in.invoke(main, 0, nil)
+	return int(ret & 0xFF), nil
+}
+
+// collect 登记所有函数体，包括泛型函数与泛型结构体方法的实例
+func (in *Interp) collect(root *parser.Node) {
+	var addFunc func(n *parser.Node)
+	addFunc = func(n *parser.Node) {
+		funcBlock := n.Value.(*parser.FuncBlock)
+		if funcBlock.Generic != nil {
+			for _, inst := range funcBlock.Generic.Instances {
+				addFunc(inst)
+			}
+			return
+		}
+		in.funcs[funcBlock] = n
+	}
+	for _, n := range root.Children {
+		switch v := n.Value.(type) {
+		case *parser.FuncBlock:
+			addFunc(n)
+		case *parser.StructBlock:
+			if v.Generic == nil {
+				continue
+			}
+			for _, method := range v.MethodTemplates {
+				for _, inst := range method.Generic.Instances {
+					addFunc(inst)
+				}
+			}
+		}
+	}
+}
+
+// global 在数据区中分配全局变量并写入初始值，没有初始值的结构体按字段默认值初始化
+func (in *Interp) global(v *parser.VarBlock) {
+	addr := in.allocData(v.Type.Size(), typeSys.AlignOf(v.Type))
+	in.globals[v] = addr
+	if v.Value == nil {
+		in.defaults(addr, v.Type)
+		return
+	}
+	in.storeValue(v.Value, v.Type, addr)
+}
+
+// intern 返回字符串字面量在数据区中的地址，字符串以 0 结尾，相同内容共用一份
+func (in *Interp) intern(s string) uint64 {
+	if addr, ok := in.strs[s]; ok {
+		return addr
+	}
+	addr := in.allocData(len(s)+1, 1)
+	in.mem.mustWriteBytes(addr, []byte(s))
+	in.strs[s] = addr
+	return addr
+}
+
+// allocData 在数据区中分配按 align 对齐的 size 字节
+func (in *Interp) allocData(size, align int) uint64 {
+	addr := alignUp(in.dataNext, uint64(max(align, 1)))
+	in.dataNext = addr + uint64(max(size, 1))
+	in.mem.mapRange(addr, uint64(max(size, 1)))
+	return addr
+}
+
+// alloc 在栈上分配按类型对齐的空间并清零，函数返回或语句结束时释放
+func (in *Interp) alloc(t typeSys.Type) uint64 {
+	size := uint64(max(t.Size(), 1))
+	addr := (in.sp - size) &^ (uint64(typeSys.AlignOf(t)) - 1)
+	if addr < stackTop-stackSize || addr > in.sp {
+		fail("栈溢出")
+	}
+	in.sp = addr
+	in.mem.mustWriteBytes(addr, make([]byte, size))
+	return addr
+}
+
+// step 计数执行的语句，超过上限时结束执行
+func (in *Interp) step() {
+	in.steps++
+	if in.MaxSteps > 0 && in.steps > in.MaxSteps {
+		fail("执行的语句数超过上限 %d", in.MaxSteps)
+	}
+}
@@ -0,0 +1,217 @@
+package interp
+
+import (
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"encoding/binary"
+	"math"
+)
+
+// 模拟内存的布局，地址在 32 位与 64 位目标下都能表示
+const (
+	pageSize  = 4096
+	dataBase  = 0x08100000 // 全局变量与字符串字面量
+	heapBase  = 0x20000000 // malloc 与 mmap 分配的内存
+	heapLimit = 0x80000000
+	stackTop  = 0xbff00000
+	stackSize = 1 << 20
+)
+
+// memory 按页分配的稀疏内存，访问未映射的页出错
+type memory struct {
+	pages map[uint64]*[pageSize]byte
+}
+
+// mapRange 映射 [addr, addr+size) 覆盖的页，已映射的页保持原有内容
+func (m *memory) mapRange(addr, size uint64) {
+	for p := addr / pageSize; p*pageSize < addr+size; p++ {
+		if m.pages[p] == nil {
+			m.pages[p] = new([pageSize]byte)
+		}
+	}
+}
+
+// unmap 取消映射 [addr, addr+size) 覆盖的页
+func (m *memory) unmap(addr, size uint64) {
+	for p := addr / pageSize; p*pageSize < addr+size; p++ {
+		delete(m.pages, p)
+	}
+}
+
+// readBytes 读取 size 字节，跨越未映射的页时返回 false
+func (m *memory) readBytes(addr, size uint64) ([]byte, bool) {
+	buf := make([]byte, size)
+	for i := uint64(0); i < size; {
+		page := m.pages[(addr+i)/pageSize]
+		if page == nil {
+			return nil, false
+		}
+		i += uint64(copy(buf[i:], page[(addr+i)%pageSize:]))
+	}
+	return buf, true
+}
+
+// writeBytes 写入 data，跨越未映射的页时返回 false（之前的页已经写入）
+func (m *memory) writeBytes(addr uint64, data []byte) bool {
+	for i := 0; i < len(data); {
+		page := m.pages[(addr+uint64(i))/pageSize]
+		if page == nil {
+			return false
+		}
+		i += copy(page[(addr+uint64(i))%pageSize:], data[i:])
+	}
+	return true
+}
+
+// readString 读取以 0 结尾的字符串
+func (m *memory) readString(addr uint64) (string, bool) {
+	var s []byte
+	for {
+		b, ok := m.readBytes(addr+uint64(len(s)), 1)
+		if !ok {
+			return "", false
+		}
+		if b[0] == 0 {
+			return string(s), true
+		}
+		s = append(s, b[0])
+	}
+}
+
+func (m *memory) mustReadBytes(addr, size uint64) []byte {
+	buf, ok := m.readBytes(addr, size)
+	if !ok {
+		fail("访问未映射的地址 0x%x", addr)
+	}
+	return buf
+}
+
+func (m *memory) mustWriteBytes(addr uint64, data []byte) {
+	if !m.writeBytes(addr, data) {
+		fail("访问未映射的地址 0x%x", addr)
+	}
+}
+
+// read 按小端序读取 size 字节的无符号整数
+func (m *memory) read(addr uint64, size int) uint64 {
+	var buf [8]byte
+	copy(buf[:], m.mustReadBytes(addr, uint64(size)))
+	return binary.LittleEndian.Uint64(buf[:])
+}
+
+// write 按小端序写入 v 的低 size 字节
+func (m *memory) write(addr uint64, size int, v uint64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	m.mustWriteBytes(addr, buf[:size])
+}
+
+// move 将 src 处 size 字节复制到 dst，两个区域可以重叠
+func (m *memory) move(dst, src uint64, size int) {
+	m.mustWriteBytes(dst, m.mustReadBytes(src, uint64(size)))
+}
+
+// word 返回字长的位掩码
+func word() uint64 {
+	if typeSys.PtrSize >= 8 {
+		return math.MaxUint64
+	}
+	return 1<<(8*typeSys.PtrSize) - 1
+}
+
+func alignUp(addr, align uint64) uint64 {
+	return (addr + align - 1) &^ (align - 1)
+}
+
+// isAggregate 报告类型的值是否按地址处理：结构体、数组、切片与接口值求值的结果为其地址
+func isAggregate(t typeSys.Type) bool {
+	if t == nil || t.IsPointer() {
+		return false
+	}
+	switch t.(type) {
+	case *typeSys.StructType, *typeSys.ArrayType, *typeSys.SliceType, *typeSys.InterfaceType:
+		return true
+	}
+	return false
+}
+
+// load 从 addr 按类型 t 读出值：整数按类型扩展，f32 转换为 f64 保存，聚合类型的值即地址本身
+func (in *Interp) load(addr uint64, t typeSys.Type) uint64 {
+	if isAggregate(t) {
+		return addr
+	}
+	if t == nil || t.IsPointer() {
+		return in.mem.read(addr, typeSys.PtrSize)
+	}
+	v := in.mem.read(addr, t.Size())
+	if typeSys.GetTypeType(t) == "float" && t.Size() == 4 {
+		return math.Float64bits(float64(math.Float32frombits(uint32(v))))
+	}
+	return narrow(v, t)
+}
+
+// store 将值 v 按类型 t 的宽度写入 addr，f32 先由 f64 转换
+func (in *Interp) store(addr uint64, t typeSys.Type, v uint64) {
+	size := typeSys.PtrSize
+	if t != nil && !t.IsPointer() {
+		size = t.Size()
+	}
+	if t != nil && typeSys.GetTypeType(t) == "float" && size == 4 {
+		v = uint64(math.Float32bits(float32(math.Float64frombits(v))))
+	}
+	in.mem.write(addr, size, v)
+}
+
+// storeValue 计算 exp 并按类型 t 写入 addr，聚合类型经 storeAggregate 复制
+func (in *Interp) storeValue(exp *parser.Expression, t typeSys.Type, addr uint64) {
+	if isAggregate(t) {
+		in.storeAggregate(exp.Type, t, in.addr(exp), addr)
+		return
+	}
+	in.store(addr, t, convert(in.value(exp), exp.Type, t))
+}
+
+// storeAggregate 将 src 处类型为 from 的聚合值作为类型 to 写入 dst：结构体转换为接口时写入 (数据地址, 虚表)，
+// 数组转换为切片时写入 (数据地址, 长度)，其余按类型大小复制
+func (in *Interp) storeAggregate(from, to typeSys.Type, src, dst uint64) {
+	switch to.(type) {
+	case *typeSys.InterfaceType:
+		if st, ok := from.(*typeSys.StructType); ok && !st.IsPointer() {
+			in.store(dst, nil, src)
+			in.store(dst+uint64(typeSys.PtrSize), nil, in.vtable(st))
+			return
+		}
+	case *typeSys.SliceType:
+		if array, ok := from.(*typeSys.ArrayType); ok {
+			in.store(dst, nil, src)
+			in.store(dst+uint64(typeSys.PtrSize), nil, uint64(array.Len))
+			return
+		}
+	}
+	in.mem.move(dst, src, to.Size())
+}
+
+// vtable 返回结构体作为接口值时的虚表编号，调用方法时按编号找回结构体再查找方法
+func (in *Interp) vtable(st *typeSys.StructType) uint64 {
+	for i, t := range in.vtables {
+		if t == st {
+			return uint64(i + 1)
+		}
+	}
+	in.vtables = append(in.vtables, st)
+	return uint64(len(in.vtables))
+}
+
+// defaults 写入结构体字段的默认值（包括嵌套结构体）
+func (in *Interp) defaults(addr uint64, t typeSys.Type) {
+	st, ok := t.(*typeSys.StructType)
+	if !ok {
+		return
+	}
+	for _, field := range st.StructFields {
+		if def, ok := field.Default.(*parser.Expression); ok && def != nil {
+			in.storeValue(def, field.Type, addr+uint64(field.Offset))
+		}
+		in.defaults(addr+uint64(field.Offset), field.Type)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"cuteify/interp"
+	packageSys "cuteify/package"
+	"cuteify/parser"
+	"strings"
+	"testing"
+)
+
+// TestInterp 用解释器（run --interp）直接执行 x86Cases 中的程序，检查退出码与标准错误输出与编译运行时相同
+func TestInterp(t *testing.T) {
+	eachX86Case(t, func(t *testing.T, c x86Case) {
+		tmp, err := packageSys.GetPackage("./test/"+c.name, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		in := interp.New(tmp.AST.(*parser.Node))
+		in.BoundsCheck = c.boundsCheck
+		exit, err := in.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exit != c.exit {
+			t.Errorf("退出码为 %d，应为 %d", exit, c.exit)
+		}
+		if !strings.Contains(in.Stderr.String(), c.stderr) {
+			t.Errorf("标准错误输出为 %q，应包含 %q", in.Stderr.String(), c.stderr)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"cuteify/compile"
+	"cuteify/compile/ir"
+	packageSys "cuteify/package"
+	"cuteify/parser"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// irGolden 与 testdata/ir 下的黄金文件比较 IR 文本与生成的汇编的程序
+var irGolden = map[string]bool{
+	"loop_test":      true,
+	"switch_test":    true,
+	"interface_test": true,
+	"array_test":     true,
+	"callconv_test":  true,
+}
+
+// TestIR 经 IR 后端（默认方式）编译 x86Cases 中的程序并在模拟器中运行，退出码与直接从语法树生成（Compiler.Legacy）时相同，
+// 且执行的指令数不多于后者（都不展开函数，两者都做窥孔优化）；部分程序的 IR 文本与汇编与黄金文件比较（go test -run TestIR -update 更新）
+func TestIR(t *testing.T) {
+	eachX86Case(t, func(t *testing.T, c x86Case) {
+		code, m := runX86(t, c, &compile.Compiler{NoInline: true})
+		if _, legacy := runX86(t, c, &compile.Compiler{Legacy: true, NoInline: true}); m.Steps > legacy.Steps {
+			t.Errorf("执行了 %d 条指令，直接从语法树生成时为 %d 条", m.Steps, legacy.Steps)
+		}
+
+		if !irGolden[c.name] || c.boundsCheck {
+			return
+		}
+		tmp, err := packageSys.GetPackage("./test/"+c.name, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		prog := ir.Lower(tmp.AST.(*parser.Node), c.boundsCheck)
+		base := filepath.Join("testdata", "ir", c.name+"."+c.arch)
+		checkGolden(t, base+".ir", prog.String())
+		checkGolden(t, base+".asm", code)
+	})
+}
+
+// checkGolden 比较 got 与黄金文件的内容，-update 时先用 got 覆盖黄金文件
+func checkGolden(t *testing.T, golden, got string) {
+	t.Helper()
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("输出与 %s 不一致", golden)
+	}
+}
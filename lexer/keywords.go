@@ -17,6 +17,7 @@ var (
 		"elif":      PROCESSCONTROL,
 		"switch":    PROCESSCONTROL,
 		"case":      PROCESSCONTROL,
+		"default":   PROCESSCONTROL,
 		"try":       PROCESSCONTROL,
 		"except":    PROCESSCONTROL,
 		"finally":   PROCESSCONTROL,
@@ -127,7 +128,7 @@ const (
 var SepListLength = 32
 var FuncListLength = 1
 var VarListLength = 3
-var ProcessControlListLength = 14
+var ProcessControlListLength = 15
 var PackageListLength = 4
 var TypeListLength = 2
 var BoolListLength = 2
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"cuteify/compile"
+	packageSys "cuteify/package"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+// llvmCases 用 LLVM 后端编译的测试程序及其退出码，与汇编后端一致；
+// loop_test 按 4 字节 int 编写，在 64 位字长下不能通过类型检查，不在其中
+var llvmCases = []struct {
+	name string
+	exit int
+}{
+	{"switch_test", 49},
+	{"struct_layout", 26},
+	{"method_test", 20},
+	{"simple_method", 42},
+	{"interface_test", 31},
+	{"global_test", 5},
+	{"pointer_test", 52},
+	{"array_test", 49},
+	{"cast_test", 95},
+	{"generic_test", 27},
+	{"callconv_test", 36},
+	{"fastcall_test", 87},
+	{"struct_test", 0},
+	{"struct_method", 0},
+	{"link_test", 0},
+}
+
+// llvmFlags 返回 llvm-as 与 lli 需要的参数：LLVM 15 之前不透明指针需要显式开启
+func llvmFlags(tool string) []string {
+	out, err := exec.Command(tool, "--version").Output()
+	if err != nil {
+		return nil
+	}
+	m := regexp.MustCompile(`LLVM version (\d+)`).FindSubmatch(out)
+	if m == nil {
+		return nil
+	}
+	if major, _ := strconv.Atoi(string(m[1])); major < 15 {
+		return []string{"-opaque-pointers"}
+	}
+	return nil
+}
+
+// TestLLVM 用 LLVM 后端编译 test/ 下的程序，llvm-as 可用时验证生成的 IR，lli 也可用时运行并检查退出码
+func TestLLVM(t *testing.T) {
+	llvmAs, err := exec.LookPath("llvm-as")
+	if err != nil {
+		t.Skip("没有找到 llvm-as")
+	}
+	lli, _ := exec.LookPath("lli")
+	flags := llvmFlags(llvmAs)
+
+	arch, ptrSize := compile.GoArch, typeSys.PtrSize
+	compile.GoArch, typeSys.PtrSize = "llvm", compile.WordSize("llvm")
+	defer func() { compile.GoArch, typeSys.PtrSize = arch, ptrSize }()
+
+	for _, c := range llvmCases {
+		t.Run(c.name, func(t *testing.T) {
+			tmp, err := packageSys.GetPackage("./test/"+c.name, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			co := &compile.Compiler{}
+			code := co.Compile(tmp.AST.(*parser.Node))
+
+			dir := t.TempDir()
+			ll, bc := filepath.Join(dir, "main.ll"), filepath.Join(dir, "main.bc")
+			if err := os.WriteFile(ll, []byte(code), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if out, err := exec.Command(llvmAs, append(flags, ll, "-o", bc)...).CombinedOutput(); err != nil {
+				t.Fatalf("llvm-as: %v\n%s", err, out)
+			}
+			if lli == "" {
+				return
+			}
+			exit := 0
+			if err := exec.Command(lli, append(flags, bc)...).Run(); err != nil {
+				var exitErr *exec.ExitError
+				if !errors.As(err, &exitErr) {
+					t.Fatal(err)
+				}
+				exit = exitErr.ExitCode()
+			}
+			if exit != c.exit {
+				t.Errorf("退出码为 %d，应为 %d", exit, c.exit)
+			}
+		})
+	}
+}
@@ -2,8 +2,14 @@ package main
 
 import (
 	"cuteify/compile"
+	"cuteify/compile/asm"
+	"cuteify/compile/context"
+	"cuteify/compile/elf"
+	"cuteify/compile/emu"
+	"cuteify/interp"
 	packageSys "cuteify/package"
 	"cuteify/parser"
+	"flag"
 	"fmt"
 	"os"
 	"reflect"
@@ -12,22 +18,130 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "run" {
+		os.Exit(run(os.Args[2:]))
+	}
+	boundsCheck := flag.Bool("bounds-check", false, "在数组与切片的下标访问处插入越界检查")
+	legacy := flag.Bool("legacy", false, "32 位 x86 目标不经 SSA 中间表示，直接从语法树生成代码")
+	regAlloc := flag.String("regalloc", "regmgr", "寄存器分配方式：regmgr 或 graph（经 IR 生成，以函数为单位图着色分配）")
+	noPeephole := flag.Bool("no-peephole", false, "关闭对生成的 32 位 x86 汇编的窥孔优化")
+	noInline := flag.Bool("no-inline", false, "经 IR 生成时不在调用处展开函数")
+	output := flag.String("o", "", "直接生成 ELF 文件（以 .o 结尾时为可重定位目标文件，否则为静态可执行文件），无需 nasm 和 ld")
+	flag.Parse()
+
 	startTime := time.Now()
 	path := "./test"
-	if len(os.Args) != 1 {
-		path = os.Args[1]
+	if flag.NArg() != 0 {
+		path = flag.Arg(0)
 	}
 	tmp, err := packageSys.GetPackage(path, true)
 	if err != nil {
 		panic(err)
 	}
-	co := &compile.Compiler{}
+	alloc, ok := context.ParseRegAlloc(*regAlloc)
+	if !ok {
+		fmt.Println("\033[31mError\033[0m: 未知的寄存器分配方式", *regAlloc)
+		os.Exit(1)
+	}
+	if alloc == context.GraphAlloc && !compile.HasBackend(compile.GoArch) {
+		fmt.Println("\033[31mError\033[0m: -regalloc graph 只支持 32 位 x86 目标，当前为", compile.GoArch)
+		os.Exit(1)
+	}
+	co := &compile.Compiler{BoundsCheck: *boundsCheck, Legacy: *legacy, RegAlloc: alloc, NoPeephole: *noPeephole, NoInline: *noInline}
 	//pr(tmp.AST.(*parser.Node), 0)
 	code := co.Compile(tmp.AST.(*parser.Node))
-	os.WriteFile(`./_main.asm`, []byte(code), 0644)
+	os.WriteFile("./"+compile.OutputName(compile.GoArch), []byte(code), 0644)
+	if co.Peephole != nil {
+		fmt.Println("Peephole:", co.Peephole)
+	}
+	if *output != "" {
+		if err := writeELF(code, *output); err != nil {
+			fmt.Println("\033[31mError\033[0m:", err)
+			os.Exit(1)
+		}
+	}
 	fmt.Println("\033[32mOK\033[0m:Finish in", time.Since(startTime))
 }
 
+// run 实现 cuteify run [--interp] [-bounds-check] [-legacy] [-regalloc regmgr|graph] [-no-peephole] [-no-inline] [path]：不写出文件，直接执行程序并返回其退出码。
+// 默认用 x86 后端编译后在内置模拟器中运行，--interp 时不经代码生成，在 AST 上解释执行
+func run(args []string) int {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	useInterp := flags.Bool("interp", false, "不生成代码，直接解释执行语法树")
+	boundsCheck := flags.Bool("bounds-check", false, "在数组与切片的下标访问处检查越界")
+	legacy := flags.Bool("legacy", false, "不经 SSA 中间表示，直接从语法树生成代码")
+	regAlloc := flags.String("regalloc", "regmgr", "寄存器分配方式：regmgr 或 graph")
+	noPeephole := flags.Bool("no-peephole", false, "关闭窥孔优化")
+	noInline := flags.Bool("no-inline", false, "经 IR 生成时不展开函数")
+	flags.Parse(args)
+	alloc, ok := context.ParseRegAlloc(*regAlloc)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "\033[31mError\033[0m: 未知的寄存器分配方式", *regAlloc)
+		return 1
+	}
+
+	path := "./test"
+	if flags.NArg() != 0 {
+		path = flags.Arg(0)
+	}
+	tmp, err := packageSys.GetPackage(path, true)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "\033[31mError\033[0m:", err)
+		return 1
+	}
+	root := tmp.AST.(*parser.Node)
+
+	var exitCode int
+	var stdout, stderr []byte
+	if *useInterp {
+		in := interp.New(root)
+		in.BoundsCheck = *boundsCheck
+		exitCode, err = in.Run()
+		stdout, stderr = in.Stdout.Bytes(), in.Stderr.Bytes()
+	} else {
+		if !compile.IsAsm(compile.GoArch) || compile.WordSize(compile.GoArch) != 4 {
+			fmt.Fprintln(os.Stderr, "\033[31mError\033[0m: run 只能模拟 32 位 x86 目标，当前为", compile.GoArch+"，可使用 --interp")
+			return 1
+		}
+		co := &compile.Compiler{BoundsCheck: *boundsCheck, Legacy: *legacy, RegAlloc: alloc, NoPeephole: *noPeephole, NoInline: *noInline}
+		var m *emu.Machine
+		if m, err = emu.New(co.Compile(root)); err == nil {
+			exitCode, err = m.Run()
+			stdout, stderr = m.Stdout.Bytes(), m.Stderr.Bytes()
+		}
+	}
+	os.Stdout.Write(stdout)
+	os.Stderr.Write(stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "\033[31mError\033[0m:", err)
+		return 1
+	}
+	return exitCode
+}
+
+// writeELF 用内置的汇编器和 ELF 写出器生成目标文件或可执行文件
+func writeELF(code, path string) error {
+	if !compile.IsAsm(compile.GoArch) || compile.WordSize(compile.GoArch) != 4 {
+		return fmt.Errorf("-o 只支持 32 位 x86 目标，当前为 %s", compile.GoArch)
+	}
+	obj, err := asm.Assemble(code)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(path, ".o") {
+		data, err := elf.WriteObject(obj)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, data, 0644)
+	}
+	data, err := elf.WriteExecutable(obj)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0755)
+}
+
 func pr(block *parser.Node, tabnum int) {
 	if block.Ignore {
 		return
@@ -22,6 +22,10 @@ var all *All = &All{
 }
 
 func GetPackage(packagePath string, isRoot bool) (*packageFmt.Info, error) {
+	// 依赖包的 AST 会被替换为合并后的全局 AST，重新编译根包时不能复用
+	if isRoot {
+		packages = make(map[string]*packageFmt.Info)
+	}
 	// 列出目录下所有文件
 	files, err := os.ReadDir(packagePath)
 	if err != nil {
@@ -85,6 +89,13 @@ func GetPackage(packagePath string, isRoot bool) (*packageFmt.Info, error) {
 						// 构建新的Name，将包路径添加到函数名前
 						newName := append([]string{packageFmt.FixPathName(packagePath)}, funcBlock.Name...)
 						funcBlock.Name = newName
+						// 包解析期间已生成的泛型实例同样加上包路径
+						if funcBlock.Generic != nil {
+							for _, inst := range funcBlock.Generic.Instances {
+								instBlock := inst.Value.(*parser.FuncBlock)
+								instBlock.Name = append([]string{packageFmt.FixPathName(packagePath)}, instBlock.Name...)
+							}
+						}
 					}
 				}
 			}
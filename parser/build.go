@@ -15,6 +15,7 @@ type Build struct {
 	OS           []string
 	Ignore       bool
 	Link         string
+	CallConv     string               // 函数的调用约定（cdecl/stdcall/fastcall）
 	VarMap       map[string]*VarBlock // 变量名 -> 临时VarBlock（已填充Offset）
 }
 
@@ -103,6 +104,33 @@ func (b *Build) Parse(p *Parser) {
 			p.Error.MissError("Syntax Error", p.Lexer.Cursor, "Need link name")
 		}
 		p.Lexer.SetCursor(stopToken)
+	case "callconv":
+		b.Type = "callconv"
+		p.Lexer.Skip('(')
+		stopToken := p.Has(lexer.Token{Value: ")", Type: lexer.SEPARATOR}, p.FindEndCursor())
+		conv := p.Lexer.Next()
+		switch conv.Value {
+		case "cdecl", "stdcall", "fastcall":
+			b.CallConv = conv.Value
+		default:
+			p.Error.MissError("Syntax Error", p.Lexer.Cursor, "Unknown calling convention "+conv.Value)
+		}
+		funcBlock, ok := p.ThisBlock.Value.(*FuncBlock)
+		if !ok {
+			p.Error.MissError("Syntax Error", p.Lexer.Cursor, "callconv only in func")
+		}
+		if funcBlock.CallConv() != "" {
+			p.Error.MissError("Syntax Error", p.Lexer.Cursor, "callconv already set")
+		}
+		p.Lexer.SetCursor(stopToken)
+		funcBlock.BuildFlags = append(funcBlock.BuildFlags, b)
+	case "inline":
+		b.Type = "inline"
+		funcBlock, ok := p.ThisBlock.Value.(*FuncBlock)
+		if !ok {
+			p.Error.MissError("Syntax Error", p.Lexer.Cursor, "inline only in func")
+		}
+		funcBlock.BuildFlags = append(funcBlock.BuildFlags, b)
 	default:
 		return
 	}
@@ -7,11 +7,12 @@ import (
 
 // CallBlock 函数调用结构体
 type CallBlock struct {
-	Name    Name
-	Args    []*ArgBlock
-	Func    *FuncBlock
-	Node    *Node
-	ThisVar *VarBlock
+	Name     Name
+	Args     []*ArgBlock
+	Func     *FuncBlock
+	Node     *Node
+	ThisVar  *VarBlock      // 方法调用的接收者，如 p.GetX() 中的 p
+	TypeArgs []typeSys.Type // 显式给出的类型实参，如 new[int]() 中的 int
 }
 
 // Check 检查函数调用的参数数量和类型是否匹配
@@ -21,7 +22,15 @@ func (c *CallBlock) Check(p *Parser) bool {
 		return false
 	}
 
-	if c.Func == nil {
+	if c.Func == nil && c.ThisVar != nil {
+		// 方法可能定义在调用之后，此时才能找到
+		c.Func = FindMethod(c.ThisVar.Type, c.Name.Last())
+		if c.Func == nil && p.Block == p.ThisBlock {
+			p.Error.MissError("Call Error", p.Lexer.Cursor, "type '"+c.ThisVar.Type.Type()+"' has no method '"+c.Name.Last()+"'")
+		}
+	}
+
+	if c.Func == nil && c.ThisVar == nil {
 		_, funcBlock := p.FindFunc(c.Name)
 		c.Func = funcBlock
 	}
@@ -33,6 +42,15 @@ func (c *CallBlock) Check(p *Parser) bool {
 		return false
 	}
 
+	if c.Func.Generic != nil {
+		if !c.instantiate(p) {
+			return false
+		}
+	} else if c.TypeArgs != nil {
+		p.Error.MissError("Call Error", p.Lexer.Cursor, "function '"+c.Name.String()+"' is not generic")
+		return false
+	}
+
 	c.Func.Useful = true
 
 	// 检查参数个数是否匹配（考虑默认参数）
@@ -67,8 +85,8 @@ func (c *CallBlock) Check(p *Parser) bool {
 		arg.Value.Check(p)
 
 		// 类型检查
-		if !typeSys.AutoType(arg.Value.Type, defArg.Type, true) {
-			p.Error.MissError("Type Error", p.Lexer.Cursor-1,
+		if !p.assignable(arg.Value, defArg.Type, arg.Value.IsConst()) {
+			p.assignError(arg.Value, defArg.Type,
 				"cannot use "+arg.Value.Type.Type()+" as type "+
 					defArg.Type.Type()+" in argument to "+c.Name.String())
 			return false
@@ -82,8 +100,72 @@ func (c *CallBlock) Check(p *Parser) bool {
 	return true
 }
 
+// instantiate 将对泛型函数的调用绑定到对应的实例
+// 类型实参没有显式给出时，由各参数的类型推导（先看变量等非常量参数，常量参数只在推导不出时使用）
+func (c *CallBlock) instantiate(p *Parser) bool {
+	template := c.Func
+	params := template.Generic.TypeParams
+	args := c.TypeArgs
+	if args == nil {
+		bindings := map[string]typeSys.Type{}
+		for _, isConst := range []bool{false, true} {
+			for i, arg := range c.Args {
+				if i >= len(template.Args) || arg.Value.IsConst() != isConst || !arg.Value.Check(p) {
+					continue
+				}
+				typeSys.Unify(template.Args[i].Type, arg.Value.Type, bindings)
+			}
+		}
+		for _, param := range params {
+			t := bindings[param]
+			if t == nil {
+				p.Error.MissError("Call Error", p.Lexer.Cursor, "cannot infer type argument '"+param+"' in call to "+c.Name.String())
+				return false
+			}
+			args = append(args, t)
+		}
+	} else if len(args) != len(params) {
+		p.Error.MissError("Call Error", p.Lexer.Cursor, "wrong number of type arguments in call to "+c.Name.String())
+		return false
+	}
+	c.Func = template.Instantiate(args)
+	return true
+}
+
+// bindMethod 将 obj.Method() 绑定为结构体方法调用，obj 作为接收者
+// 前缀不是结构体变量时（如包函数 fs.open）保持普通函数调用
+func (c *CallBlock) bindMethod(p *Parser) {
+	if !c.Name.IsPath() {
+		return
+	}
+	recv := &VarBlock{Name: c.Name[:len(c.Name)-1].Fork()}
+	if !recv.ParseDefine(p) {
+		return
+	}
+	switch recvType := recv.Type.(type) {
+	case *typeSys.StructType:
+		c.ThisVar = recv
+		c.Name = Name([]string{recvType.Type(), c.Name.Last()})
+		c.Func = FindMethod(recvType, c.Name.Last())
+	case *typeSys.InterfaceType:
+		// 接口方法通过虚表动态分派
+		c.ThisVar = recv
+		c.Name = Name([]string{recvType.Type(), c.Name.Last()})
+		c.Func = FindMethod(recvType, c.Name.Last())
+		if c.Func == nil {
+			p.Error.MissError("Call Error", p.Lexer.Cursor, "type '"+recvType.Type()+"' has no method '"+c.Name.Last()+"'")
+		}
+	}
+}
+
 // ParseCall 解析函数调用
 func (c *CallBlock) ParseCall(p *Parser) {
+	c.bindMethod(p)
+	if token := p.Lexer.Next(); token.Value == "[" {
+		c.TypeArgs = p.parseTypeArgs()
+	} else {
+		p.Lexer.SetCursor(token.Cursor)
+	}
 	p.Lexer.Skip('(')
 	var token lexer.Token
 
@@ -0,0 +1,316 @@
+package parser
+
+import (
+	typeSys "cuteify/type"
+	"strconv"
+	"strings"
+)
+
+// CFG 函数体的控制流图，由 BuildCFG 在检查函数体之后构建，保存在 FuncBlock.CFG 中供之后的阶段使用
+type CFG struct {
+	Func   *Node         // 函数节点
+	Blocks []*BasicBlock // 所有基本块，Blocks[0] 为入口
+	End    *BasicBlock   // 函数体末尾：执行完最后一条语句后到达，之后隐式返回
+	Exit   *BasicBlock   // 函数出口：ret 与函数体末尾都转移到这里
+
+	blocks map[*Node]*BasicBlock // 语句所在的基本块
+}
+
+// BasicBlock 控制流图中的基本块，Stmts 中的语句依次执行，执行完最后一条后转移到某个后继
+type BasicBlock struct {
+	ID    int
+	Stmts []*Node
+	// Cond 非空时按条件转移，Succs[0] 为条件成立，Succs[1] 为不成立；
+	// 为空时只有一个后继，或者由最后一条语句（switch）按匹配值选择后继
+	Cond      *Expression
+	Succs     []*BasicBlock
+	Preds     []*BasicBlock
+	Reachable bool // 是否可以从入口到达
+}
+
+// String 返回基本块的名称，如 b0
+func (b *BasicBlock) String() string {
+	return "b" + strconv.Itoa(b.ID)
+}
+
+// cfgBuilder 构建过程中的状态
+type cfgBuilder struct {
+	g         *CFG
+	cur       *BasicBlock           // 当前基本块，为空时表示之后的语句不可达（如 ret 之后）
+	breaks    map[*Node]*BasicBlock // 循环或 switch 节点 -> break 的目标
+	continues map[*Node]*BasicBlock // 循环节点 -> continue 的目标
+}
+
+// BuildCFG 为函数节点构建控制流图：复合语句（if、循环、switch 与带条件的 else）位于计算其条件或匹配值的基本块中，
+// 其中的语句位于之后的基本块中；没有条件或条件为常量 true 的循环只能通过 break 离开
+func BuildCFG(funcNode *Node) *CFG {
+	g := &CFG{Func: funcNode, blocks: make(map[*Node]*BasicBlock)}
+	b := &cfgBuilder{g: g, breaks: make(map[*Node]*BasicBlock), continues: make(map[*Node]*BasicBlock)}
+	b.cur = b.newBlock()
+	// 末尾与出口在函数体之后编号，ret 在此之前就需要转移到出口
+	g.End, g.Exit = &BasicBlock{}, &BasicBlock{}
+	b.list(funcNode)
+	b.jump(g.End)
+	g.End.addEdge(g.Exit)
+	for _, block := range []*BasicBlock{g.End, g.Exit} {
+		block.ID = len(g.Blocks)
+		g.Blocks = append(g.Blocks, block)
+	}
+	g.markReachable()
+	return g
+}
+
+func (b *cfgBuilder) newBlock() *BasicBlock {
+	block := &BasicBlock{ID: len(b.g.Blocks)}
+	b.g.Blocks = append(b.g.Blocks, block)
+	return block
+}
+
+func (from *BasicBlock) addEdge(to *BasicBlock) {
+	from.Succs = append(from.Succs, to)
+	to.Preds = append(to.Preds, from)
+}
+
+// jump 当前基本块无条件转移到 to，之后的语句不可达
+func (b *cfgBuilder) jump(to *BasicBlock) {
+	if b.cur != nil {
+		b.cur.addEdge(to)
+	}
+	b.cur = nil
+}
+
+// block 返回放置下一条语句的基本块，之前的语句不会转移到这里时新建一个没有前驱的基本块
+func (b *cfgBuilder) block() *BasicBlock {
+	if b.cur == nil {
+		b.cur = b.newBlock()
+	}
+	return b.cur
+}
+
+// place 将语句放入当前基本块
+func (b *cfgBuilder) place(n *Node) *BasicBlock {
+	block := b.block()
+	block.Stmts = append(block.Stmts, n)
+	b.g.blocks[n] = block
+	return block
+}
+
+// branch 以 cond 结束当前基本块，条件成立时转移到 then，否则转移到 els
+func (b *cfgBuilder) branch(cond *Expression, then, els *BasicBlock) {
+	block := b.block()
+	block.Cond = cond
+	block.addEdge(then)
+	block.addEdge(els)
+	b.cur = nil
+}
+
+// list 依次加入节点的子语句
+func (b *cfgBuilder) list(n *Node) {
+	for _, child := range n.Children {
+		if child.Ignore {
+			continue
+		}
+		b.stmt(child)
+	}
+}
+
+func (b *cfgBuilder) stmt(n *Node) {
+	switch v := n.Value.(type) {
+	case *IfBlock:
+		b.ifBlock(n, v)
+	case *ForBlock:
+		b.loop(n, v.Condition, v.Increment != nil)
+	case *WhileBlock:
+		b.loop(n, v.Condition, false)
+	case *SwitchBlock:
+		b.switchBlock(n)
+	case *ReturnBlock:
+		b.place(n)
+		b.jump(b.g.Exit)
+	case *BreakBlock:
+		b.place(n)
+		b.jump(b.breaks[v.Loop])
+	case *ContinueBlock:
+		b.place(n)
+		b.jump(b.continues[v.Loop])
+	case *FuncBlock:
+		// 嵌套的函数有自己的控制流图
+	default:
+		b.place(n)
+	}
+}
+
+func (b *cfgBuilder) ifBlock(n *Node, ifBlock *IfBlock) {
+	b.place(n)
+	then, end := b.newBlock(), b.newBlock()
+	els := end
+	if ifBlock.Else {
+		els = b.newBlock()
+	}
+	b.branch(ifBlock.Condition, then, els)
+	b.cur = then
+	b.list(n)
+	b.jump(end)
+	if ifBlock.Else {
+		elseNode := ifBlock.ElseBlock
+		b.cur = els
+		b.g.blocks[elseNode] = els
+		if cond := elseNode.Value.(*ElseBlock).IfCondition; cond != nil {
+			b.place(elseNode)
+			body := b.newBlock()
+			b.branch(cond, body, end)
+			b.cur = body
+		}
+		b.list(elseNode)
+		b.jump(end)
+	}
+	b.cur = end
+}
+
+// loop 加入循环：头部判断条件，continue 转移到增量部分（hasIncrement 为 false 时为头部），break 转移到循环之后
+func (b *cfgBuilder) loop(n *Node, cond *Expression, hasIncrement bool) {
+	header := b.newBlock()
+	b.jump(header)
+	b.cur = header
+	b.place(n)
+	body, exit := b.newBlock(), b.newBlock()
+	cont := header
+	if hasIncrement {
+		cont = b.newBlock()
+		cont.addEdge(header)
+	}
+	if alwaysTrue(cond) {
+		b.jump(body)
+	} else {
+		b.branch(cond, body, exit)
+	}
+	b.breaks[n], b.continues[n] = exit, cont
+	b.cur = body
+	b.list(n)
+	b.jump(cont)
+	b.cur = exit
+}
+
+// switchBlock 加入 switch：按匹配值转移到各分支，没有 default 时可能直接转移到 switch 之后；分支之间不贯穿
+func (b *cfgBuilder) switchBlock(n *Node) {
+	dispatch := b.place(n)
+	b.cur = nil
+	end := b.newBlock()
+	b.breaks[n] = end
+	hasDefault := false
+	for _, caseNode := range n.Children {
+		caseBlock, ok := caseNode.Value.(*CaseBlock)
+		if !ok || caseNode.Ignore {
+			continue
+		}
+		hasDefault = hasDefault || caseBlock.IsDefault
+		entry := b.newBlock()
+		dispatch.addEdge(entry)
+		b.g.blocks[caseNode] = entry
+		b.cur = entry
+		b.list(caseNode)
+		b.jump(end)
+	}
+	if !hasDefault {
+		dispatch.addEdge(end)
+	}
+	b.cur = end
+}
+
+// alwaysTrue 判断循环条件是否省略或为常量 true
+func alwaysTrue(cond *Expression) bool {
+	return cond == nil || cond.IsConst() && cond.Bool && typeSys.CheckTypeType(cond.Type, "bool")
+}
+
+// markReachable 从入口出发标记可以到达的基本块
+func (g *CFG) markReachable() {
+	stack := []*BasicBlock{g.Blocks[0]}
+	g.Blocks[0].Reachable = true
+	for len(stack) > 0 {
+		block := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, succ := range block.Succs {
+			if !succ.Reachable {
+				succ.Reachable = true
+				stack = append(stack, succ)
+			}
+		}
+	}
+}
+
+// Block 返回语句所在的基本块，else 与 case 为其分支开始的基本块；不在函数体中时返回 nil
+func (g *CFG) Block(n *Node) *BasicBlock {
+	return g.blocks[n]
+}
+
+// Reachable 判断语句是否可能被执行
+func (g *CFG) Reachable(n *Node) bool {
+	block := g.blocks[n]
+	return block != nil && block.Reachable
+}
+
+// EndReachable 判断执行是否可能到达函数体末尾（此时没有显式的 ret，需要隐式返回）
+func (g *CFG) EndReachable() bool {
+	return g.End.Reachable
+}
+
+// Unreachable 返回不可达的语句：每段连续的不可达语句只返回第一条，其中嵌套的语句不再单独返回
+func (g *CFG) Unreachable() (stmts []*Node) {
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		for _, child := range n.Children {
+			if child.Ignore {
+				continue
+			}
+			if _, ok := child.Value.(*FuncBlock); ok {
+				continue
+			}
+			if !g.Reachable(child) {
+				stmts = append(stmts, child)
+				return
+			}
+			walk(child)
+			if ifBlock, ok := child.Value.(*IfBlock); ok && ifBlock.Else {
+				walk(ifBlock.ElseBlock)
+			}
+		}
+	}
+	walk(g.Func)
+	return
+}
+
+// Diagnose 对不可达的语句给出警告；有返回值的函数可能执行到函数体末尾时报告缺少 ret。
+// 由外部提供实现（build ext）或函数体中有内联汇编（可能自行设置返回值）的函数不报告缺少 ret
+func (g *CFG) Diagnose(funcNode *Node) {
+	p := funcNode.Parser
+	if p == nil {
+		return
+	}
+	for _, n := range g.Unreachable() {
+		p.Error.WarningAt(n.Cursor, "unreachable code")
+	}
+	funcBlock := funcNode.Value.(*FuncBlock)
+	if len(funcBlock.Return) == 0 || !g.EndReachable() || g.external(funcBlock) {
+		return
+	}
+	cursor := funcNode.EndCursor
+	if cursor == 0 {
+		cursor = funcNode.Cursor
+	}
+	p.Error.MissError("Return Error", cursor, "missing return at end of function "+strings.Join(funcBlock.Name, "."))
+}
+
+// external 判断函数的返回值是否可能不由 ret 给出：build ext 或函数体中有内联汇编
+func (g *CFG) external(funcBlock *FuncBlock) bool {
+	for _, flag := range funcBlock.BuildFlags {
+		if flag.Type == "ext" {
+			return true
+		}
+	}
+	for n := range g.blocks {
+		if build, ok := n.Value.(*Build); ok && build.Type == "asm" {
+			return true
+		}
+	}
+	return false
+}
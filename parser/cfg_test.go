@@ -0,0 +1,224 @@
+package parser
+
+import (
+	"cuteify/lexer"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// parseSource 解析源代码，返回语法树的根节点
+func parseSource(t *testing.T, src string) *Node {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "main.cute")
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p := NewParser(lexer.NewLexer(path))
+	p.Block.Parser = p
+	return p.Parse()
+}
+
+// parseFunc 解析只含一个函数的源代码，返回函数节点
+func parseFunc(t *testing.T, src string) *Node {
+	t.Helper()
+	root := parseSource(t, src)
+	for _, n := range root.Children {
+		if _, ok := n.Value.(*FuncBlock); ok {
+			return n
+		}
+	}
+	t.Fatal("没有函数")
+	return nil
+}
+
+func TestCFG(t *testing.T) {
+	cases := []struct {
+		name        string
+		src         string
+		endReach    bool
+		unreachable []string // 不可达语句的类型
+	}{
+		{"顺序返回", `fn f(a: i32) i32 {
+    var b: i32 = a
+    ret b
+}
+`, false, nil},
+		{"if 缺少 else", `fn f(a: i32) i32 {
+    if (a > 0) {
+        ret 1
+    }
+}
+`, true, nil},
+		{"if 与 else 都返回", `fn f(a: i32) i32 {
+    if (a > 0) {
+        ret 1
+    } else {
+        ret 2
+    }
+}
+`, false, nil},
+		{"ret 之后的语句", `fn f(a: i32) i32 {
+    ret a
+    var b: i32 = 1
+    b = 2
+    ret b
+}
+`, false, []string{"*parser.VarBlock"}},
+		{"分支内 ret 之后", `fn f(a: i32) i32 {
+    if (a > 0) {
+        ret 1
+        a = 2
+    }
+    ret a
+}
+`, false, []string{"*parser.VarBlock"}},
+		{"无限循环", `fn f(a: i32) i32 {
+    while (true) {
+        a = a + 1
+    }
+    ret a
+}
+`, false, []string{"*parser.ReturnBlock"}},
+		{"break 离开无限循环", `fn f(a: i32) i32 {
+    while (true) {
+        if (a > 10) {
+            break
+        }
+        a = a + 1
+    }
+    ret a
+}
+`, false, nil},
+		{"条件循环之后", `fn f(a: i32) i32 {
+    while (a < 10) {
+        a = a + 1
+        continue
+        a = a + 2
+    }
+}
+`, true, []string{"*parser.VarBlock"}},
+		{"switch 各分支都返回", `fn f(a: i32) i32 {
+    switch (a) {
+    case 0:
+        ret 10
+    default:
+        ret 0
+    }
+    ret 1
+}
+`, false, []string{"*parser.ReturnBlock"}},
+		{"switch 没有 default", `fn f(a: i32) i32 {
+    switch (a) {
+    case 0:
+        ret 10
+    }
+}
+`, true, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g := BuildCFG(parseFunc(t, c.src))
+			if got := g.EndReachable(); got != c.endReach {
+				t.Errorf("函数体末尾可达为 %v，应为 %v", got, c.endReach)
+			}
+			var got []string
+			for _, n := range g.Unreachable() {
+				got = append(got, fmt.Sprintf("%T", n.Value))
+			}
+			if len(got) != len(c.unreachable) {
+				t.Fatalf("不可达的语句为 %v，应为 %v", got, c.unreachable)
+			}
+			for i := range got {
+				if got[i] != c.unreachable[i] {
+					t.Errorf("不可达的语句为 %v，应为 %v", got, c.unreachable)
+				}
+			}
+			for _, block := range g.Blocks {
+				for _, succ := range block.Succs {
+					if !contains(succ.Preds, block) {
+						t.Errorf("%s -> %s 没有对应的前驱", block, succ)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestElseIf 带条件的 else 在条件不成立时执行到 if 之后
+func TestElseIf(t *testing.T) {
+	n := parseFunc(t, `fn f(a: i32) i32 {
+    if (a > 0) {
+        ret 1
+    } else {
+        ret 2
+    }
+}
+`)
+	ifBlock := n.Children[0].Value.(*IfBlock)
+	if BuildCFG(n).EndReachable() {
+		t.Fatal("if 与 else 都返回时函数体末尾可达")
+	}
+	// 以 if 的条件代替 else if 的条件，只关心控制流
+	ifBlock.ElseBlock.Value.(*ElseBlock).IfCondition = ifBlock.Condition
+	g := BuildCFG(n)
+	if !g.EndReachable() {
+		t.Error("else if 的条件不成立时函数体末尾不可达")
+	}
+	if b := g.Block(ifBlock.ElseBlock); b == nil || b.Cond == nil || len(b.Succs) != 2 {
+		t.Errorf("else if 所在的基本块应按条件转移: %v", b)
+	}
+}
+
+// TestMissingReturn 有返回值的函数可能执行到末尾时检查函数体报错
+func TestMissingReturn(t *testing.T) {
+	n := parseFunc(t, `fn f(a: i32) i32 {
+    if (a > 0) {
+        ret 1
+    }
+}
+`)
+	defer func() {
+		if recover() == nil {
+			t.Error("缺少 ret 没有报错")
+		}
+	}()
+	n.Check()
+}
+
+// TestUnreachableWarning 不可达语句的警告输出到标准错误，不混入编译输出
+func TestUnreachableWarning(t *testing.T) {
+	n := parseFunc(t, `fn f(a: i32) i32 {
+    ret a
+    ret 1
+}
+`)
+	stdout, stderr := os.Stdout, os.Stderr
+	outR, outW, _ := os.Pipe()
+	errR, errW, _ := os.Pipe()
+	os.Stdout, os.Stderr = outW, errW
+	BuildCFG(n).Diagnose(n)
+	os.Stdout, os.Stderr = stdout, stderr
+	outW.Close()
+	errW.Close()
+	out, _ := io.ReadAll(outR)
+	warn, _ := io.ReadAll(errR)
+	if len(out) != 0 {
+		t.Errorf("标准输出中有内容:\n%s", out)
+	}
+	if !strings.Contains(string(warn), "unreachable code") {
+		t.Errorf("标准错误中没有不可达警告:\n%s", warn)
+	}
+}
+
+func contains(blocks []*BasicBlock, b *BasicBlock) bool {
+	for _, block := range blocks {
+		if block == b {
+			return true
+		}
+	}
+	return false
+}
@@ -23,7 +23,11 @@ type Expression struct {
 	Bool      bool         // 布尔值
 	ConstBool bool         // 常量布尔值
 	Type      typeSys.Type // 类型
-	Field     *Expression  // 字段访问（用于结构体字段访问，如 obj.field）
+	Field     *Expression  // 下标结果的字段访问 a[i].f 中的字段名（Var.Name 只有一段），所属的结构体为 Left
+	Unary     string       // 一元运算符：& 取地址，* 解引用，len 取长度，as 类型转换（目标类型即 Type），操作数为 Right
+	Index     *Expression  // 下标访问 a[i] 的下标，被索引的数组或切片为 Left
+	Cursor    int          // 在源代码中的起始位置，用于报错定位：ParseExp 解析出的整个表达式，或类型转换的 as 及目标类型
+	EndCursor int          // 在源代码中的结束位置
 	checked   bool
 }
 
@@ -40,6 +44,24 @@ func (exp *Expression) Check(p *Parser) bool {
 
 	exp.CheckVar(p)
 
+	if exp.Unary != "" {
+		if !exp.checkUnary(p) {
+			return false
+		}
+	}
+
+	if exp.Index != nil {
+		if !exp.checkIndex(p) {
+			return false
+		}
+	}
+
+	if exp.Field != nil {
+		if !exp.checkField(p) {
+			return false
+		}
+	}
+
 	if exp.Call != nil {
 		if !exp.checkCall(p) {
 			return false
@@ -69,6 +91,149 @@ func (exp *Expression) checkCall(p *Parser) bool {
 	return true
 }
 
+// checkUnary 检查取地址、解引用与 len：& 的操作数必须可取地址，* 的操作数必须是指针，len 的操作数必须是数组或切片
+func (exp *Expression) checkUnary(p *Parser) bool {
+	operand := exp.Right
+	if !operand.Check(p) || operand.Type == nil {
+		return false
+	}
+
+	switch exp.Unary {
+	case "&":
+		if !operand.addressable() {
+			p.Error.MissError("Type Error", p.Lexer.Cursor, "cannot take the address of this expression")
+		}
+		exp.Type = typeSys.NewPointerType(operand.Type)
+	case "*":
+		ptr, ok := operand.Type.(*typeSys.PointerType)
+		if !ok {
+			p.Error.MissError("Type Error", p.Lexer.Cursor, "cannot dereference non-pointer type "+operand.Type.Type())
+		}
+		exp.checkElemSize(p, ptr.Elem, "through a pointer")
+		exp.Type = ptr.Elem
+	case "as":
+		exp.checkCast(p, operand)
+	case "len":
+		switch t := operand.Type.(type) {
+		case *typeSys.ArrayType:
+			// 数组长度是编译期常量
+			exp.Num = float64(t.Len)
+			exp.Unary, exp.Right = "", nil
+		case *typeSys.SliceType:
+			if !operand.addressable() {
+				p.Error.MissError("Type Error", p.Lexer.Cursor, "cannot take the length of this expression")
+			}
+		default:
+			p.Error.MissError("Type Error", p.Lexer.Cursor, "invalid argument for len: "+operand.Type.Type())
+		}
+		exp.Type = typeSys.GetSystemType("int")
+	}
+	exp.checked = true
+	return true
+}
+
+// Span 返回表达式在源代码中的范围，不是由 ParseExp 解析得到的表达式返回当前位置
+func (exp *Expression) Span(p *Parser) (start, end int) {
+	if exp.EndCursor == 0 {
+		return p.Lexer.Cursor, p.Lexer.Cursor
+	}
+	return exp.Cursor, exp.EndCursor
+}
+
+// checkCast 检查 as 类型转换，常量转换在编译期完成
+func (exp *Expression) checkCast(p *Parser, operand *Expression) {
+	if !typeSys.Convertible(operand.Type, exp.Type) {
+		p.Error.MissErrors("Type Error", exp.Cursor, exp.EndCursor, "cannot convert "+operand.Type.Type()+" to "+exp.Type.Type())
+	}
+	if operand.IsConst() && typeSys.CheckTypeType(operand.Type, "int", "uint", "float", "byte") {
+		exp.Num = typeSys.ConvertConst(operand.Num, exp.Type)
+		exp.Unary, exp.Right = "", nil
+	}
+}
+
+// checkIndex 检查下标访问：被索引的必须是可取地址的数组或切片，下标必须是整数，常量下标在编译期检查数组越界
+func (exp *Expression) checkIndex(p *Parser) bool {
+	base, index := exp.Left, exp.Index
+	if !base.Check(p) || base.Type == nil || !index.Check(p) || index.Type == nil {
+		return false
+	}
+	if !typeSys.CheckTypeType(index.Type, "int", "uint") {
+		p.Error.MissError("Type Error", p.Lexer.Cursor, "index must be an integer, not "+index.Type.Type())
+	}
+
+	var elem typeSys.Type
+	switch t := base.Type.(type) {
+	case *typeSys.ArrayType:
+		if index.IsConst() && (index.Num < 0 || int(index.Num) >= t.Len) {
+			p.Error.MissError("Type Error", p.Lexer.Cursor, "index "+strconv.Itoa(int(index.Num))+" out of range for "+t.Type())
+		}
+		elem = t.Elem
+	case *typeSys.SliceType:
+		elem = t.Elem
+	default:
+		p.Error.MissError("Type Error", p.Lexer.Cursor, "cannot index non-array type "+base.Type.Type())
+	}
+	if !base.addressable() {
+		p.Error.MissError("Type Error", p.Lexer.Cursor, "cannot index this expression")
+	}
+	exp.checkElemSize(p, elem, "by index")
+	exp.Type = elem
+	exp.checked = true
+	return true
+}
+
+// checkElemSize 检查经由内存访问的元素宽度：只能按 1、2、4 字节或目标字长载入和存储
+// 作为 &、下标或 len 的操作数时只需要地址，不访问内存
+func (exp *Expression) checkElemSize(p *Parser, elem typeSys.Type, how string) {
+	if father := exp.Father; father != nil && (father.Unary == "&" || father.Unary == "len" || father.Left == exp && (father.Index != nil || father.Field != nil)) {
+		return
+	}
+	if size := elem.Size(); size != 1 && size != 2 && size != 4 && size != typeSys.PtrSize {
+		p.Error.MissError("Type Error", p.Lexer.Cursor, "cannot load or store "+elem.Type()+" "+how)
+	}
+}
+
+// checkField 检查下标结果的字段访问：被访问的必须是结构体，字段必须存在
+func (exp *Expression) checkField(p *Parser) bool {
+	base := exp.Left
+	if !base.Check(p) || base.Type == nil {
+		return false
+	}
+	st, ok := base.Type.(*typeSys.StructType)
+	if !ok {
+		p.Error.MissError("Field access error", p.Lexer.Cursor, "type '"+base.Type.Type()+"' is not a struct")
+		return false
+	}
+	name := exp.Field.Var.Name.String()
+	field := st.Field(name)
+	if field == nil {
+		p.Error.MissError("Field access error", p.Lexer.Cursor, "struct '"+st.Type()+"' has no field '"+name+"'")
+		return false
+	}
+	exp.checkElemSize(p, field.Type, "by field")
+	exp.Type = field.Type
+	exp.checked = true
+	return true
+}
+
+// StructField 返回下标结果的字段访问 a[i].f 所访问的字段
+func (exp *Expression) StructField() *typeSys.StructField {
+	st, ok := exp.Left.Type.(*typeSys.StructType)
+	if !ok {
+		panic("编译器内部错误: " + exp.Left.Type.Type() + " 不是结构体")
+	}
+	field := st.Field(exp.Field.Var.Name.String())
+	if field == nil {
+		panic("编译器内部错误: 结构体 " + st.Type() + " 没有字段 " + exp.Field.Var.Name.String())
+	}
+	return field
+}
+
+// addressable 报告表达式是否可以取地址（变量、字段、解引用或下标）
+func (exp *Expression) addressable() bool {
+	return (exp.Var != nil && exp.Var.Value == nil) || exp.Unary == "*" || exp.Index != nil || exp.Field != nil
+}
+
 func (exp *Expression) checkOperator(p *Parser) bool {
 	if exp.Left == nil || exp.Right == nil {
 		return false
@@ -77,6 +242,10 @@ func (exp *Expression) checkOperator(p *Parser) bool {
 	left, right := exp.Left, exp.Right
 	left.Check(p)
 	right.Check(p)
+	// 操作数调用了定义在后面的函数时类型尚未确定，留到最终检查
+	if left.Type == nil || right.Type == nil {
+		return false
+	}
 
 	switch exp.Separator {
 	case ".":
@@ -101,32 +270,36 @@ func (exp *Expression) checkOperator(p *Parser) bool {
 	}
 }
 
-func (exp *Expression) checkArithmeticOp(_ *Parser, left, right *Expression) bool {
+func (exp *Expression) checkArithmeticOp(p *Parser, left, right *Expression) bool {
+	if exp.Separator == "-" && isPointer(left.Type) {
+		return exp.checkPointerOp(p, left, right)
+	}
 	if !typeSys.CheckTypeType(left.Type, "uint", "int", "float") || !typeSys.CheckTypeType(right.Type, "uint", "int", "float") {
 		return false
 	}
 
 	if left.IsConst() && right.IsConst() {
 		exp.foldArithmeticConstants(left, right)
-	} else if typeSys.CheckTypeType(left.Type, "float") && typeSys.CheckTypeType(right.Type, "float") {
-		exp.Type = typeSys.GetSystemType("f64")
 	} else {
-		exp.Type = typeSys.GetSystemType("int")
+		exp.Type = exp.arithType(p, left, right)
 	}
 
 	exp.checked = true
 	return true
 }
 
-func (exp *Expression) checkAddOp(_ *Parser, left, right *Expression) bool {
+func (exp *Expression) checkAddOp(p *Parser, left, right *Expression) bool {
+	// 指针加减整数
+	if isPointer(left.Type) || isPointer(right.Type) {
+		return exp.checkPointerOp(p, left, right)
+	}
+
 	// 数值加法
 	if typeSys.CheckTypeType(left.Type, "uint", "int", "float") && typeSys.CheckTypeType(right.Type, "uint", "int", "float") {
 		if left.IsConst() && right.IsConst() {
 			exp.foldNumericConstants(left.Num + right.Num)
-		} else if typeSys.CheckTypeType(left.Type, "float") && typeSys.CheckTypeType(right.Type, "float") {
-			exp.Type = typeSys.GetSystemType("f64")
 		} else {
-			exp.Type = typeSys.GetSystemType("int")
+			exp.Type = exp.arithType(p, left, right)
 		}
 		exp.checked = true
 		return true
@@ -143,15 +316,92 @@ func (exp *Expression) checkAddOp(_ *Parser, left, right *Expression) bool {
 	return false
 }
 
-func (exp *Expression) checkMulOp(_ *Parser, left, right *Expression) bool {
+// arithType 返回二元数值运算的结果类型
+// 一侧为常量时取另一侧的类型（常量须能用该类型表示），否则两侧须能隐式拓宽为同一类型，有符号与无符号混用须显式转换
+func (exp *Expression) arithType(p *Parser, left, right *Expression) typeSys.Type {
+	switch {
+	case left.IsConst():
+		if !typeSys.Assignable(left.Type, right.Type, true, left.Num) {
+			start, end := left.Span(p)
+			p.Error.MissErrors("Type Error", start, end, "constant "+strconv.FormatFloat(left.Num, 'f', -1, 64)+" overflows "+right.Type.Type())
+		}
+		return right.Type
+	case right.IsConst():
+		if !typeSys.Assignable(right.Type, left.Type, true, right.Num) {
+			start, end := right.Span(p)
+			p.Error.MissErrors("Type Error", start, end, "constant "+strconv.FormatFloat(right.Num, 'f', -1, 64)+" overflows "+left.Type.Type())
+		}
+		return left.Type
+	case typeSys.Widens(left.Type, right.Type):
+		return right.Type
+	case typeSys.Widens(right.Type, left.Type):
+		return left.Type
+	}
+	start, _ := left.Span(p)
+	_, end := right.Span(p)
+	p.Error.MissErrors("Type Error", start, end, "mismatched types "+left.Type.Type()+" and "+right.Type.Type()+" (use 'as' to convert)")
+	return nil
+}
+
+// OperandType 返回比较运算的操作数类型：常量按另一侧的类型，两侧都是变量时取较宽的一侧
+func (exp *Expression) OperandType() typeSys.Type {
+	left, right := exp.Left, exp.Right
+	switch {
+	case left.IsConst() && !right.IsConst():
+		return right.Type
+	case right.IsConst() || left.Type == nil:
+		return left.Type
+	case right.Type == nil:
+		return left.Type
+	}
+	if typeSys.Widens(left.Type, right.Type) {
+		return right.Type
+	}
+	return left.Type
+}
+
+// checkPointerOp 检查指针加减整数，偏移量按元素大小缩放，结果仍为指针
+func (exp *Expression) checkPointerOp(p *Parser, left, right *Expression) bool {
+	// 整数 + 指针 交换为 指针 + 整数
+	if exp.Separator == "+" && !isPointer(left.Type) {
+		left, right = right, left
+		exp.Left, exp.Right = left, right
+	}
+	ptr, ok := left.Type.(*typeSys.PointerType)
+	if !ok || !typeSys.CheckTypeType(right.Type, "int", "uint") {
+		start, _ := left.Span(p)
+		_, end := right.Span(p)
+		p.Error.MissErrors("Type Error", start, end, "invalid operation: "+left.Type.Type()+" "+exp.Separator+" "+right.Type.Type())
+	}
+
+	if size := ptr.ElemSize(); size != 1 {
+		if right.IsConst() {
+			right.Num *= float64(size)
+		} else {
+			scaled := &Expression{Separator: "*", Type: typeSys.GetSystemType("int"), checked: true}
+			scaled.SetOperator(right, &Expression{Num: float64(size), Type: typeSys.GetSystemType("int")})
+			exp.Right = scaled
+			scaled.Father = exp
+		}
+	}
+
+	exp.Type = ptr
+	exp.checked = true
+	return true
+}
+
+// isPointer 报告类型是否为指针
+func isPointer(t typeSys.Type) bool {
+	return t != nil && t.IsPointer()
+}
+
+func (exp *Expression) checkMulOp(p *Parser, left, right *Expression) bool {
 	// 数值乘法
 	if typeSys.CheckTypeType(left.Type, "uint", "int", "float") && typeSys.CheckTypeType(right.Type, "uint", "int", "float") {
 		if left.IsConst() && right.IsConst() {
 			exp.foldNumericConstants(left.Num * right.Num)
-		} else if typeSys.CheckTypeType(left.Type, "float") && typeSys.CheckTypeType(right.Type, "float") {
-			exp.Type = typeSys.GetSystemType("f64")
 		} else {
-			exp.Type = typeSys.GetSystemType("int")
+			exp.Type = exp.arithType(p, left, right)
 		}
 		exp.checked = true
 		return true
@@ -172,10 +422,18 @@ func (exp *Expression) checkMulOp(_ *Parser, left, right *Expression) bool {
 	return false
 }
 
-func (exp *Expression) checkEqualityOp(_ *Parser, left, right *Expression) bool {
-	if typeSys.GetTypeType(left.Type) != typeSys.GetTypeType(right.Type) {
+func (exp *Expression) checkEqualityOp(p *Parser, left, right *Expression) bool {
+	// 指针可以与整数常量（如 0）比较
+	nullCmp := (isPointer(left.Type) && right.IsConst() && typeSys.CheckTypeType(right.Type, "int", "uint")) ||
+		(isPointer(right.Type) && left.IsConst() && typeSys.CheckTypeType(left.Type, "int", "uint"))
+	numeric := typeSys.CheckTypeType(left.Type, "uint", "int", "float") && typeSys.CheckTypeType(right.Type, "uint", "int", "float")
+	if typeSys.GetTypeType(left.Type) != typeSys.GetTypeType(right.Type) && !nullCmp && !numeric {
 		return false
 	}
+	// 数值比较与算术运算遵循相同的隐式转换规则
+	if numeric && !(left.IsConst() && right.IsConst()) {
+		exp.arithType(p, left, right)
+	}
 
 	exp.Type = typeSys.GetSystemType("bool")
 
@@ -186,13 +444,15 @@ func (exp *Expression) checkEqualityOp(_ *Parser, left, right *Expression) bool
 	return true
 }
 
-func (exp *Expression) checkComparisonOp(_ *Parser, left, right *Expression) bool {
+func (exp *Expression) checkComparisonOp(p *Parser, left, right *Expression) bool {
 	if !typeSys.CheckTypeType(left.Type, "uint", "int", "float") || !typeSys.CheckTypeType(right.Type, "uint", "int", "float") {
 		return false
 	}
 
 	if left.IsConst() && right.IsConst() {
 		exp.foldComparisonConstants(left.Num, right.Num)
+	} else {
+		exp.arithType(p, left, right)
 	}
 
 	exp.Type = typeSys.GetSystemType("bool")
@@ -215,7 +475,7 @@ func (exp *Expression) checkLogicalOp(_ *Parser, left, right *Expression) bool {
 		exp.foldBinaryOpConstants()
 	}
 
-	return false
+	return true
 }
 
 func (exp *Expression) checkFieldAccess(p *Parser, left, right *Expression) bool {
@@ -376,6 +636,10 @@ func (exp *Expression) setVarInfo(varBlock *VarBlock) {
 		varBlock.Offset = argDef.Offset
 		exp.Type = argDef.Type
 	}
+	// 字段访问时 ParseDefine 已解析出字段类型
+	if varBlock.Name.IsPath() && varBlock.Type != nil {
+		exp.Type = varBlock.Type
+	}
 	varBlock.Type = exp.Type
 }
 
@@ -384,7 +648,7 @@ func (exp *Expression) setVarInfo(varBlock *VarBlock) {
 //   - bool: 是否为常量表达式
 func (exp *Expression) IsConst() bool {
 	// 如果没有变量、函数调用且没有操作符，则为常量
-	return exp.Var == nil && exp.Call == nil && exp.Separator == ""
+	return exp.Var == nil && exp.Call == nil && exp.Separator == "" && exp.Unary == "" && exp.Index == nil && exp.Field == nil
 }
 
 // ParseExp 解析表达式
@@ -406,17 +670,29 @@ func (p *Parser) ParseExp(stopCursor int) *Expression {
 
 	// 负号标志
 	nextIsNar := false
+	// 当前位置是否期待操作数，用于区分一元 &/* 与二元运算
+	expectOperand := true
+
+	// 表达式在源代码中的范围，用于报错定位
+	start, finish := -1, p.Lexer.Cursor
 
 	// 循环解析直到停止位置
 	for p.Lexer.Cursor < stopCursor {
 		// 获取下一个词法单元
 		token := p.Lexer.Next()
+		if start < 0 && token.Value != ";" {
+			start = token.Cursor
+		}
 
 		if token.EndCursor > stopCursor {
 			p.Error.MissError("expression error", p.Lexer.Cursor, "expression error")
 		}
 
 		var exp *Expression
+		// 词法分析器将 true/false 作为标识符返回，这里按布尔值处理
+		if token.Type == lexer.NAME && (token.Value == "true" || token.Value == "false") {
+			token.Type = lexer.BOOL
+		}
 		// 根据词法单元类型进行处理
 		switch token.Type {
 		case lexer.SEPARATOR:
@@ -424,22 +700,51 @@ func (p *Parser) ParseExp(stopCursor int) *Expression {
 				p.Lexer.SetCursor(token.Cursor) // 退格
 				goto end
 			}
+			if expectOperand && (token.Value == "&" || token.Value == "*") {
+				exp = p.parseUnary(token.Value, stopCursor)
+				break
+			}
+			if expectOperand && token.Value == "(" {
+				if exp = p.parseGroupCast(stopCursor); exp != nil {
+					break
+				}
+			}
+			// 期待操作数时数字前的负号并入数字常量
+			if expectOperand && token.Value == "-" {
+				after := p.Lexer.Cursor
+				next := p.Lexer.Next()
+				p.Lexer.SetCursor(after)
+				if next.Type == lexer.NUMBER && next.EndCursor <= stopCursor {
+					nextIsNar = true
+					break
+				}
+			}
 			// 分隔符
 			stackSep = append(stackSep, &Expression{
 				Separator: token.Value,
 			})
-		case lexer.STRING, lexer.CHAR, lexer.RAW:
-			// 字符串、字符、原始字符串
+		case lexer.CHAR:
+			// 字符，按 u8 常量处理
+			exp = &Expression{
+				Num:  float64(charCode(p, token.Value)),
+				Type: typeSys.GetSystemType("u8"),
+			}
+		case lexer.STRING, lexer.RAW:
+			// 字符串、原始字符串（原始字符串不处理转义）
 			exp = &Expression{
 				StringVal: token.Value,
 				Type:      typeSys.GetSystemType("string"),
 			}
+			if token.Type == lexer.STRING {
+				exp.StringVal = unescape(p, token.Value)
+			}
 		case lexer.NAME:
 			// 标识符
 			exp = &Expression{}
 			if f := exp.parseName(p, token, stopCursor); f {
 				return exp
 			}
+			exp = p.parseIndex(exp, stopCursor)
 		case lexer.NUMBER:
 			// 数字
 			num, err := strconv.ParseFloat(token.Value, 64)
@@ -450,6 +755,7 @@ func (p *Parser) ParseExp(stopCursor int) *Expression {
 				Num: num,
 			}
 			exp.handleNum(p, nextIsNar)
+			nextIsNar = false
 		case lexer.BOOL:
 			// 布尔值
 			exp = &Expression{
@@ -462,7 +768,14 @@ func (p *Parser) ParseExp(stopCursor int) *Expression {
 		}
 
 		if exp != nil {
-			stackNum = append(stackNum, exp)
+			// 记录操作数的范围，二元运算的报错定位到操作数
+			if exp.EndCursor == 0 {
+				exp.Cursor, exp.EndCursor = token.Cursor, p.Lexer.Cursor
+			}
+			stackNum = append(stackNum, p.parseCast(exp, stopCursor))
+			expectOperand = false
+		} else if token.Type == lexer.SEPARATOR {
+			expectOperand = token.Value != ")"
 		}
 
 		// 处理括号和操作符优先级
@@ -480,12 +793,155 @@ func (p *Parser) ParseExp(stopCursor int) *Expression {
 			(token.Type != lexer.SEPARATOR || stackSep[len(stackSep)-1].Separator == ")") {
 			stackNum, stackSep = handleWe(stackNum, stackSep)
 		}
+		finish = p.Lexer.Cursor
 	}
 end:
 	if len(stackNum) == 0 {
 		p.Error.MissError("Invalid expression", p.Lexer.Cursor, "Missing expression")
 	}
-	return afterHandle(stackNum, stackSep)
+	exp := afterHandle(stackNum, stackSep)
+	// 类型转换已记录了 as 的位置
+	if exp.EndCursor == 0 {
+		exp.Cursor, exp.EndCursor = start, finish
+	}
+	return exp
+}
+
+// parseUnary 解析一元运算符 op 的操作数：变量、字段、函数调用、括号表达式或嵌套的一元运算
+func (p *Parser) parseUnary(op string, stopCursor int) *Expression {
+	exp := &Expression{Unary: op}
+	token := p.Lexer.Next()
+	switch {
+	case token.Type == lexer.SEPARATOR && (token.Value == "&" || token.Value == "*"):
+		exp.Right = p.parseUnary(token.Value, stopCursor)
+	case token.Type == lexer.SEPARATOR && token.Value == "(":
+		exp.Right = p.ParseExp(p.matchParen("(", ")", stopCursor))
+		p.Lexer.Skip(')')
+	case token.Type == lexer.NAME:
+		exp.Right = &Expression{}
+		exp.Right.parseName(p, token, stopCursor)
+		exp.Right = p.parseIndex(exp.Right, stopCursor)
+	default:
+		p.Error.MissError("Invalid expression", p.Lexer.Cursor, "need operand after '"+op+"'")
+	}
+	exp.Right.Father = exp
+	return exp
+}
+
+// parseIndex 解析紧跟在操作数之后的下标（可以连续，如 m[i][j]）以及下标之后的字段访问（如 vs[i].x），
+// 没有下标时原样返回
+func (p *Parser) parseIndex(base *Expression, stopCursor int) *Expression {
+	for p.Lexer.Cursor < stopCursor {
+		token := p.Lexer.Next()
+		if token.Type == lexer.SEPARATOR && token.Value == "." && (base.Index != nil || base.Field != nil) {
+			name := p.Lexer.Next()
+			if name.Type != lexer.NAME {
+				p.Error.MissError("Field access error", name.Cursor, "right operand of '.' must be a field name")
+			}
+			exp := &Expression{Left: base, Field: &Expression{Var: &VarBlock{Name: NewName(name.Value)}}}
+			base.Father = exp
+			exp.Field.Father = exp
+			base = exp
+			continue
+		}
+		if token.Type != lexer.SEPARATOR || token.Value != "[" {
+			p.Lexer.SetCursor(token.Cursor)
+			break
+		}
+		exp := &Expression{Left: base}
+		exp.Index = p.ParseExp(p.matchParen("[", "]", stopCursor))
+		p.Lexer.Skip(']')
+		base.Father = exp
+		exp.Index.Father = exp
+		base = exp
+	}
+	return base
+}
+
+// parseCast 解析紧跟在操作数之后的类型转换（可以连续，如 x as i32 as u32），没有时原样返回
+// as 只作用于紧邻的操作数，优先级高于所有二元运算符
+func (p *Parser) parseCast(operand *Expression, stopCursor int) *Expression {
+	for p.Lexer.Cursor < stopCursor {
+		token := p.Lexer.Next()
+		if token.Type != lexer.PACKAGE || token.Value != "as" {
+			p.Lexer.SetCursor(token.Cursor)
+			break
+		}
+		name, t := p.ParseType()
+		if t == nil {
+			p.Error.MissErrors("Type Error", token.Cursor, p.Lexer.Cursor, "type '"+name.String()+"' not found")
+		}
+		exp := &Expression{Unary: "as", Right: operand, Type: t, Cursor: token.Cursor, EndCursor: p.Lexer.Cursor}
+		operand.Father = exp
+		operand = exp
+	}
+	return operand
+}
+
+// parseGroupCast 括号表达式之后紧跟 as 时（如 (n % base) as u8）将整个括号作为一个操作数解析，已读入 (
+// 否则不移动游标并返回 nil，括号按普通分隔符处理
+func (p *Parser) parseGroupCast(stopCursor int) *Expression {
+	start := p.Lexer.Cursor
+	end := p.matchParen("(", ")", stopCursor)
+	p.Lexer.SetCursor(end)
+	p.Lexer.Skip(')')
+	isCast := false
+	if p.Lexer.Cursor < stopCursor {
+		token := p.Lexer.Next()
+		isCast = token.Type == lexer.PACKAGE && token.Value == "as"
+	}
+	p.Lexer.SetCursor(start)
+	if !isCast {
+		return nil
+	}
+	exp := p.ParseExp(end)
+	p.Lexer.Skip(')')
+	return exp
+}
+
+// parseLen 解析内建函数 len 的参数，已读入 len
+func (exp *Expression) parseLen(p *Parser, stopCursor int) {
+	p.Lexer.Skip('(')
+	exp.Unary = "len"
+	exp.Right = p.ParseExp(p.matchParen("(", ")", stopCursor))
+	exp.Right.Father = exp
+	p.Lexer.Skip(')')
+}
+
+// parseSizeof 解析 sizeof(T)，在编译期求出类型 T 的大小，结果为 int 常量
+func (exp *Expression) parseSizeof(p *Parser) {
+	p.Lexer.Skip('(')
+	name, t := p.ParseType()
+	if t == nil {
+		p.Error.MissError("Type Error", p.Lexer.Cursor, "type '"+name.String()+"' not found")
+	}
+	p.Lexer.Skip(')')
+	exp.Num = float64(t.Size())
+	exp.Type = typeSys.GetSystemType("int")
+}
+
+// matchParen 返回与已读入的 open 匹配的 close 的位置，不移动游标
+func (p *Parser) matchParen(open, close string, stopCursor int) int {
+	startCursor := p.Lexer.Cursor
+	depth := 0
+	for p.Lexer.Cursor < stopCursor {
+		token := p.Lexer.Next()
+		if token.Type != lexer.SEPARATOR {
+			continue
+		}
+		switch token.Value {
+		case open:
+			depth++
+		case close:
+			if depth == 0 {
+				p.Lexer.SetCursor(startCursor)
+				return token.Cursor
+			}
+			depth--
+		}
+	}
+	p.Error.MissError("Invalid expression", p.Lexer.Cursor, "need "+close)
+	return stopCursor
 }
 
 func (exp *Expression) parseName(p *Parser, nameToken lexer.Token, stopCursor int) (finish bool) {
@@ -497,11 +953,11 @@ func (exp *Expression) parseName(p *Parser, nameToken lexer.Token, stopCursor in
 		if token.Value == "(" {
 			p.Lexer.SetCursor(token.Cursor)
 			exp.handleMethodCall(p, name)
-			return true
+			return
 		}
-		p.Lexer.SetCursor(nameToken.Cursor)
+		p.Lexer.SetCursor(token.Cursor)
 		exp.handleFieldAccess(p, name)
-		return true
+		return
 	}
 
 	if p.Lexer.Cursor+2 > stopCursor {
@@ -519,9 +975,24 @@ func (exp *Expression) parseName(p *Parser, nameToken lexer.Token, stopCursor in
 	if token.Type == lexer.SEPARATOR {
 		switch token.Value {
 		case "(":
+			switch name.String() {
+			case "len":
+				exp.parseLen(p, stopCursor)
+				return
+			case "sizeof":
+				exp.parseSizeof(p)
+				return
+			}
 			exp.Call = &CallBlock{Name: name}
 			exp.Call.ParseCall(p)
 			return
+		case "[":
+			// name[T](...) 为带显式类型实参的泛型函数调用，否则交给下标解析
+			if p.isTypeArgCall(token.Cursor) {
+				exp.Call = &CallBlock{Name: name}
+				exp.Call.ParseCall(p)
+				return
+			}
 		case ".":
 			p.Lexer.SetCursor(nameStart)
 			checkToken := p.Lexer.Next()
@@ -537,7 +1008,8 @@ func (exp *Expression) parseName(p *Parser, nameToken lexer.Token, stopCursor in
 		case "=", ":=", "+=", "-=", "*=", "/=", "%=", "^=", "&=", "|=", "<<=", ">>=", "++", "--":
 			block := &VarBlock{}
 			p.Lexer.SetCursor(nameStart)
-			block.ParseVar(p)
+			// 赋值表达式只解析到当前表达式的结束位置（如 for 的增量部分）
+			block.ParseNameVar(p, p.Lexer.Next(), stopCursor)
 			exp.Var = block
 			p.AddChild(&Node{Value: block, Ignore: true})
 			finish = true
@@ -558,111 +1030,14 @@ func (exp *Expression) handleMethodCall(p *Parser, name Name) {
 func (exp *Expression) handleFieldAccess(p *Parser, name Name) {
 	objName := name[0]
 
-	if objName == "this" && len(name) > 1 {
-		// TODO: for current := p.ThisBlock; current != nil; current = current.Father {
-		// TODO: 	if funcBlock, ok := current.Value.(*FuncBlock); ok {
-		// TODO: 		if funcBlock.Class != nil {
-		// TODO: 			currentType := funcBlock.Class
-		// TODO: 			structName := currentType.Type()
-		// TODO: 			structBlock := p.FindStruct(structName)
-		// TODO: 			if structBlock == nil {
-		// TODO: 				p.Error.MissError("Field access error", p.Lexer.Cursor, "type '"+structName+"' is not a struct")
-		// TODO: 				return
-		// TODO: 			}
-		// TODO:
-		// TODO: 			fieldName := name[1]
-		// TODO: 			field := structBlock.GetFieldByName(fieldName)
-		// TODO: 			if field == nil {
-		// TODO: 				p.Error.MissError("Field access error", p.Lexer.Cursor, "struct '"+structName+"' has no field '"+fieldName+"'")
-		// TODO: 				return
-		// TODO: 			}
-		// TODO:
-		// TODO: 			thisVar := &VarBlock{
-		// TODO: 				Name:     Name([]string{"this"}),
-		// TODO: 				Type:     funcBlock.Class,
-		// TODO: 				IsDefine: true,
-		// TODO: 				Define:   &Node{Value: &VarBlock{Name: Name([]string{"this"}), Type: funcBlock.Class, IsDefine: true}},
-		// TODO: 			}
-		// TODO:
-		// TODO: 			fieldVar := &VarBlock{
-		// TODO: 				Name:     name,
-		// TODO: 				Type:     field.Type,
-		// TODO: 				IsDefine: true,
-		// TODO: 				Define:   &Node{Value: thisVar},
-		// TODO: 			}
-		// TODO:
-		// TODO: 			leftExp := &Expression{
-		// TODO: 				Var:  thisVar,
-		// TODO: 				Type: funcBlock.Class,
-		// TODO: 			}
-		// TODO:
-		// TODO: 			fieldExp := &Expression{
-		// TODO: 				Var:  fieldVar,
-		// TODO: 				Type: field.Type,
-		// TODO: 			}
-		// TODO:
-		// TODO: 			exp.Separator = "."
-		// TODO: 			exp.Left = leftExp
-		// TODO: 			exp.Right = fieldExp
-		// TODO: 			exp.Type = field.Type
-		// TODO: 			return
-		// TODO: 		}
-		// TODO: 	}
-		// TODO: }
-		p.Error.MissError("Field access error", p.Lexer.Cursor, "'this' can only be used in member function")
+	if objName == "self" && p.currentMethodOwner() == "" {
+		p.Error.MissError("Field access error", p.Lexer.Cursor, "'self' can only be used in member function")
 		return
 	}
 
-	objVar := &VarBlock{Name: Name([]string{objName})}
-	objVar.ParseDefine(p)
-
-	leftExp := &Expression{
-		Var:  objVar,
-		Type: objVar.Type,
-	}
-
-	currentExp := leftExp
-	currentType := objVar.Type
-
-	for i := 1; i < len(name); i++ {
-		fieldName := name[i]
-		// TODO: structName := currentType.Type()
-		// TODO: structBlock := p.FindStruct(structName)
-		// TODO: if structBlock == nil {
-		// TODO: 	p.Error.MissError("Field access error", p.Lexer.Cursor, "type '"+structName+"' is not a struct")
-		// TODO: 	return
-		// TODO: }
-		// TODO: field := structBlock.GetFieldByName(fieldName)
-		// TODO: if field == nil {
-		// TODO: 	p.Error.MissError("Field access error", p.Lexer.Cursor, "struct '"+structName+"' has no field '"+fieldName+"'")
-		// TODO: 	return
-		// TODO: }
-		// TODO:
-		// TODO: fieldVar := &VarBlock{
-		// TODO: 	Name:     Name([]string{objName, fieldName}),
-		// TODO: 	Type:     field.Type,
-		// TODO: 	IsDefine: true,
-		// TODO: 	Define:   objVar.Define,
-		// TODO: }
-		// TODO:
-		// TODO: fieldExp := &Expression{
-		// TODO: 	Var:  fieldVar,
-		// TODO: 	Type: field.Type,
-		// TODO: }
-		// TODO:
-		// TODO: newExp := &Expression{
-		// TODO: 	Separator: ".",
-		// TODO: 	Left:      currentExp,
-		// TODO: 	Right:     fieldExp,
-		// TODO: 	Type:      field.Type,
-		// TODO: }
-		// TODO: currentExp = newExp
-		// TODO: currentType = field.Type
-		_ = fieldName
-		_ = currentType
-	}
-
-	*exp = *currentExp
+	// 字段访问按变量处理，地址由结构体布局计算
+	exp.handleVar(p, name)
+	p.checkFieldAccess(exp.Var, false)
 }
 
 func (exp *Expression) handleVar(p *Parser, name Name) {
@@ -696,6 +1071,10 @@ func (exp *Expression) SetOperator(left *Expression, right *Expression) {
 	exp.Right = right
 	left.Father = exp
 	right.Father = exp
+	// 运算的范围从左操作数开始到右操作数结束
+	if exp.EndCursor == 0 && left.EndCursor != 0 && right.EndCursor != 0 {
+		exp.Cursor, exp.EndCursor = left.Cursor, right.EndCursor
+	}
 }
 
 // handleWe 处理操作符优先级栈的归约
@@ -803,6 +1182,11 @@ func (exp *Expression) FindVar(v Block) bool {
 		return true
 	}
 
+	// 在下标中递归查找
+	if exp.Index != nil && exp.Index.FindVar(v) {
+		return true
+	}
+
 	// 在左子表达式中递归查找
 	if exp.Left != nil && exp.Left.FindVar(v) {
 		return true
@@ -867,6 +1251,8 @@ func (exp *Expression) Print() {
 //
 // 返回:
 //   - int: 优先级（数字越大优先级越高）
+//
+// as 的右侧是类型而不是表达式，不进入运算符栈，由 parseCast 直接作用于紧邻的操作数，优先级高于这里的所有运算符
 func getWe(token string) int {
 	switch token {
 	case "||",
@@ -890,3 +1276,45 @@ func getWe(token string) int {
 	}
 	return 0
 }
+
+// unescape 处理字符串字面量中的转义字符
+func unescape(p *Parser, s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			b.WriteByte(byte(charCode(p, s[i:i+2])))
+			i++
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// charCode 返回字符字面量（可包含转义）对应的字符码
+func charCode(p *Parser, char string) int {
+	if char[0] != '\\' {
+		return int(char[0])
+	}
+	switch char[1:] {
+	case "n":
+		return '\n'
+	case "r":
+		return '\r'
+	case "t":
+		return '\t'
+	case "0":
+		return 0
+	case "\\":
+		return '\\'
+	case "'":
+		return '\''
+	case "\"":
+		return '"'
+	}
+	p.Lexer.Error.MissError("Syntax Error", p.Lexer.Cursor, "unknown escape character '"+char+"'")
+	return 0
+}
@@ -26,10 +26,15 @@ func (p *Parser) FindVar(name Name) (*Node, any) {
 		// fmt.Println("LOOP", p.ThisBlock.Value)
 		// 查找函数参数
 		if funcBlock, ok := p.ThisBlock.Value.(*FuncBlock); ok {
+			// 方法的隐式接收者
+			if self := funcBlock.Self; self != nil && self.Name.MatchT(name, self.Type) {
+				p.ThisBlock = oldThisBlock
+				return &Node{Value: self}, self
+			}
 			for _, arg := range funcBlock.Args {
 				if arg.Name.Eq(name) {
 					p.ThisBlock = oldThisBlock
-					return nil, arg
+					return &Node{Value: arg}, arg
 				}
 			}
 		}
@@ -139,14 +144,14 @@ func (p *Parser) FindGlobal(_name Name) *Node {
 			if v.Name.MatchT(name, v.Type) && v.IsDefine {
 				return child
 			}
-		// TODO: case *StructBlock:
-		// TODO: 	if v.Name.MatchT(name, v.Type()) {
-		// TODO: 		for _, field := range v.Fields() {
-		// TODO: 			if v.Name.ForkSlice(name).First() == field.Name {
-		// TODO: 				return child
-		// TODO: 			}
-		// TODO: 		}
-		// TODO: 	}
+		case *StructBlock:
+			if v.Name.Eq(name) {
+				return child
+			}
+		case *InterfaceBlock:
+			if v.Name.Eq(name) {
+				return child
+			}
 		case *FuncBlock:
 			if v.Name.Eq(name) {
 				return child
@@ -163,40 +168,57 @@ func (p *Parser) FindType(name Name) (*Node, typeSys.Type) {
 		return nil, t
 	}
 
+	// 泛型模板与实例中的类型参数
+	if p.generic != nil {
+		if t := p.generic.lookup(name); t != nil {
+			return nil, t
+		}
+	}
+
 	if p.Block == p.ThisBlock {
 		n := p.FindGlobal(name)
 		if n == nil {
 			return nil, nil
 		}
 
-		// TODO: if sb, ok := n.Value.(*StructBlock); ok {
-		// TODO: 	return n, sb.Type()
-		// TODO: }
+		if sb, ok := n.Value.(*StructBlock); ok {
+			return n, sb.Type()
+		}
+
+		if ib, ok := n.Value.(*InterfaceBlock); ok {
+			return n, ib.Type()
+		}
 
 		if tb, ok := n.Value.(*TypeBlock); ok {
 			return n, tb.Type
 		}
 	}
 
-	// TODO: // 查询类型定义或者结构体
-	// TODO: for i := len(p.Block.Children) - 1; i >= 0; i-- { // 从后往前查找，确保先找到最近的结构体
-	// TODO: 	child := p.Block.Children[i]
-	// TODO: 	if child.Value == nil {
-	// TODO: 		continue
-	// TODO: 	}
-	// TODO:
-	// TODO: 	if sb, ok := child.Value.(*StructBlock); ok {
-	// TODO: 		if sb.Name.Eq(name) {
-	// TODO: 			return child, sb.Type()
-	// TODO: 		}
-	// TODO: 	}
-	// TODO:
-	// TODO: 	if tb, ok := child.Value.(*TypeBlock); ok {
-	// TODO: 		if tb.Name.Eq(name) {
-	// TODO: 			return child, tb.Type
-	// TODO: 		}
-	// TODO: 	}
-	// TODO: }
+	// 查询类型定义或者结构体
+	for i := len(p.Block.Children) - 1; i >= 0; i-- { // 从后往前查找，确保先找到最近的结构体
+		child := p.Block.Children[i]
+		if child.Value == nil {
+			continue
+		}
+
+		if sb, ok := child.Value.(*StructBlock); ok {
+			if sb.Name.Eq(name) {
+				return child, sb.Type()
+			}
+		}
+
+		if ib, ok := child.Value.(*InterfaceBlock); ok {
+			if ib.Name.Eq(name) {
+				return child, ib.Type()
+			}
+		}
+
+		if tb, ok := child.Value.(*TypeBlock); ok {
+			if tb.Name.Eq(name) {
+				return child, tb.Type
+			}
+		}
+	}
 
 	return nil, nil
 }
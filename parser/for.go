@@ -100,15 +100,15 @@ func (f *ForBlock) parseIncrement(p *Parser) {
 
 	// 有增量表达式
 	p.Lexer.SetCursor(incToken.Cursor)
-	f.Increment = p.ParseExp(endCursor)
+	f.Increment = p.ParseExp(endCursor - 1) // 不包含结尾的 ')'
 	p.ThisBlock.Children = p.ThisBlock.Children[:len(p.ThisBlock.Children)-1]
 }
 
 // Check 检查 for 循环的有效性
 func (f *ForBlock) Check(p *Parser) bool {
 	// 检查初始化表达式
-	if f.Increment != nil {
-		if !f.Increment.Check(p) {
+	if f.Init != nil {
+		if !f.Init.Check(p) {
 			return false
 		}
 	}
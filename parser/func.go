@@ -9,10 +9,14 @@ import (
 type FuncBlock struct {
 	Args       []*ArgBlock    // 函数参数列表
 	Class      typeSys.Type   // 所属类类型（面向对象时使用）
+	Self       *ArgBlock      // 方法的隐式接收者 self，调用时以地址作为隐藏的第一个参数传入
 	Return     []typeSys.Type // 返回值类型列表（支持多返回值）
 	Name       Name           // 函数名
 	BuildFlags []*Build       // 编译标志
 	Useful     bool           // 是否有用（用于优化）
+	Generic    *Generic       // 泛型模板信息，非空时本身不生成代码，只用来生成实例
+	TypeArgs   []typeSys.Type // 泛型实例的类型实参
+	CFG        *CFG           // 函数体的控制流图，检查函数体之后构建
 }
 
 // ArgBlock 函数参数结构体
@@ -43,9 +47,30 @@ func (f *ArgBlock) Check(p *Parser) bool {
 	return true
 }
 
+// CallConv 返回函数通过 build callconv(...) 指定的调用约定，未指定时为空
+func (f *FuncBlock) CallConv() string {
+	for _, flag := range f.BuildFlags {
+		if flag.Type == "callconv" {
+			return flag.CallConv
+		}
+	}
+	return ""
+}
+
+// Inline 报告函数是否通过 build inline 要求在调用处展开（不受大小限制）
+func (f *FuncBlock) Inline() bool {
+	for _, flag := range f.BuildFlags {
+		if flag.Type == "inline" {
+			return true
+		}
+	}
+	return false
+}
+
 // Parse 解析函数定义
 // 语法格式: funcName(arg1 type1, arg2 type2) returnType { ... }
 // 或者: fn Type.methodName(arg1 type1, arg2 type2) returnType { ... }
+// 或者: fn funcName[T, U](arg1 T, arg2 U) returnType { ... }（泛型函数）
 func (f *FuncBlock) Parse(p *Parser) {
 	// 检查函数是否嵌套定义（不支持）
 	if p.ThisBlock.Father != nil {
@@ -53,6 +78,7 @@ func (f *FuncBlock) Parse(p *Parser) {
 	}
 
 	oldCursor := p.Lexer.Cursor
+	instance := p.instancing(f)
 	// 判断有没有父类
 	code := p.Lexer.Next()
 	if code.Type == lexer.NAME {
@@ -69,17 +95,49 @@ func (f *FuncBlock) Parse(p *Parser) {
 			p.Error.MissError("Syntax Error", p.Lexer.Cursor, "Function name can't be more than 2 parts")
 			return
 		}
+		if len(f.Name) == 1 && !instance {
+			p.checkReserved("Syntax", f.Name)
+		}
+
+		typeParams := p.parseTypeParams()
 
-		//TODO：解析成员函数
-		/*if len(f.Name) == 2 {
-			structName := NewName(f.Name[0])
+		// 解析成员函数
+		if len(f.Name) == 2 {
+			structName := Name([]string{f.Name[0]})
 			_, structBlock := p.FindStruct(structName)
 			if structBlock == nil {
 				p.Error.MissError("Struct Error", p.Lexer.Cursor, "struct '"+structName.String()+"' not found")
+				return
+			}
+			if len(typeParams) > 0 {
+				p.Error.MissError("Syntax Error", p.Lexer.Cursor, "method '"+f.Name.First()+"."+f.Name.Last()+"' can't have type parameters")
+			}
+			if structBlock.Generic != nil {
+				if !instance {
+					// 泛型结构体的方法作为模板，为结构体的每个实例各生成一份
+					f.parseTemplate(p, oldCursor, structBlock.Generic.TypeParams)
+					structBlock.MethodTemplates = append(structBlock.MethodTemplates, f)
+					for _, inst := range structBlock.Generic.Instances {
+						f.instantiateMethod(inst.Value.(*StructBlock))
+					}
+					return
+				}
+				structBlock = p.generic.Struct
+				f.Name = Name([]string{structBlock.Name.First(), f.Name.Last()})
+			}
+			if FindMethod(structBlock.Type(), f.Name.Last()) != nil {
+				p.Error.MissError("Struct Error", p.Lexer.Cursor, "method '"+f.Name.First()+"."+f.Name.Last()+"' redefined")
 			}
 			f.Class = structBlock.Type()
+			f.Self = &ArgBlock{Name: Name([]string{"self"}), Type: f.Class, Offset: 8}
 			structBlock.Methods = append(structBlock.Methods, f)
-		}*/
+		} else if len(typeParams) > 0 && !instance {
+			f.parseTemplate(p, oldCursor, typeParams)
+			nodeTmp := &Node{Value: f}
+			f.Generic.Node = nodeTmp
+			p.ThisBlock.AddChild(nodeTmp)
+			return
+		}
 	} else if code.Value == "(" { // 匿名函数/闭包支持
 		p.Lexer.SetCursor(code.Cursor)
 	} else {
@@ -96,8 +154,15 @@ func (f *FuncBlock) Parse(p *Parser) {
 
 	if code.Type == lexer.NAME {
 		nodeTmp := &Node{Value: f}
-		// 将函数添加到当前作用域
-		p.ThisBlock.AddChild(nodeTmp)
+		if instance {
+			// 泛型实例不加入语法树，由模板登记和管理
+			nodeTmp.Parser, nodeTmp.Father = p, p.ThisBlock
+			p.generic.register(nodeTmp)
+			f.Check(p)
+		} else {
+			// 将函数添加到当前作用域
+			p.ThisBlock.AddChild(nodeTmp)
+		}
 
 		// 进入函数作用域
 		p.ThisBlock = nodeTmp
@@ -106,6 +171,19 @@ func (f *FuncBlock) Parse(p *Parser) {
 	// TODO: 处理编译标志
 }
 
+// parseTemplate 解析泛型模板的签名并跳过函数体，签名中的类型参数绑定为占位类型
+// 函数体在实例化时按类型实参从 cursor 处重新解析
+func (f *FuncBlock) parseTemplate(p *Parser, cursor int, params []string) {
+	f.Generic = &Generic{TypeParams: params, Parser: p, Cursor: cursor}
+	generic := p.generic
+	p.generic = templateScope(params)
+	f.ParseArgs(p)
+	f.ParseRetType(p)
+	p.generic = generic
+	p.Wait("{")
+	p.skipBody()
+}
+
 // ParseArgs 解析函数参数列表
 // 语法格式: (arg1 type1, arg2 type2 = default, ...)
 func (f *FuncBlock) ParseArgs(p *Parser) {
@@ -161,9 +239,8 @@ func (f *FuncBlock) ParseArgs(p *Parser) {
 		// 处理其他部分
 		if bracketCount == 0 {
 			if token.Value == ":" {
-				// 先后解析一个Name作为参数类型
-				n, _ := p.Name(false)
-				_, argTmp.Type = p.FindType(n)
+				// 解析参数类型（可带指针前缀）
+				_, argTmp.Type = p.ParseType()
 				if argTmp.Type == nil {
 					p.Lexer.Error.MissError("Type Error", p.Lexer.Cursor, "type not found")
 					token = p.Lexer.Next()
@@ -171,11 +248,6 @@ func (f *FuncBlock) ParseArgs(p *Parser) {
 				}
 			}
 
-			// TODO: 处理指针类型（token.Value == "*"）
-			// if token.Value == "*" {
-			// 	argTmp.Type = typeSys.NewPointerType(argTmp.Type)
-			// }
-
 			if token.Value == "=" {
 				oldCursor := token.Cursor
 				p.Wait(",")
@@ -200,8 +272,14 @@ func (f *FuncBlock) ParseArgs(p *Parser) {
 // ParseRetType 解析函数返回类型
 func (f *FuncBlock) ParseRetType(p *Parser) {
 	// TODO:多参数支持
-	typName, _ := p.Name(false)
-	_, typ := p.FindType(typName)
+	// 直接遇到 '{'（或接口方法签名的行尾）表示无返回值
+	token := p.Lexer.Next()
+	p.Lexer.SetCursor(token.Cursor)
+	switch token.Value {
+	case "{", "}", "\n", "\r", ";":
+		return
+	}
+	_, typ := p.ParseType()
 	f.Return = append(f.Return, typ)
 }
 
@@ -288,8 +366,16 @@ func (f *FuncBlock) validateAllArgTypes(p *Parser) {
 // 在 cdecl 调用约定中，参数从右到左压栈
 // 返回地址占用 4 字节，所以第一个参数从 [ebp+8] 开始
 func (f *FuncBlock) Check(p *Parser) bool {
-	// 计算参数起始偏移量
+	// 泛型模板本身不检查，检查它生成的各个实例
+	if f.Generic != nil {
+		return f.Generic.checkInstances()
+	}
+
+	// 计算参数起始偏移量，方法的 [ebp+8] 为接收者地址
 	argCount := 8
+	if f.Class != nil {
+		argCount += 4
+	}
 
 	for _, v := range f.Args {
 		if !v.Check(p) {
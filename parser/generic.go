@@ -0,0 +1,311 @@
+package parser
+
+import (
+	"cuteify/lexer"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+	"strings"
+)
+
+// Generic 泛型模板（带 [T, U] 类型参数的函数或结构体）
+// 模板只解析签名，函数体和字段在实例化时按类型实参从源代码重新解析，每组类型实参生成一个独立的实例
+type Generic struct {
+	TypeParams []string         // 类型参数名
+	Parser     *Parser          // 模板所在文件的解析器
+	Cursor     int              // 模板名称在源代码中的位置
+	Node       *Node            // 模板节点，最终检查经过它之后生成的实例需要立即检查
+	Instances  []*Node          // 已生成的实例，按生成顺序排列
+	index      map[string]*Node // 类型实参到实例的映射
+}
+
+// genericScope 类型参数到类型的绑定，FindType 优先在这里查找
+// 解析模板签名时绑定到占位的 TypeParam，实例化时绑定到具体类型
+type genericScope struct {
+	Params   []string
+	Args     []typeSys.Type
+	Instance any          // 正在实例化的 FuncBlock 或 StructBlock，解析模板签名时为 nil
+	Struct   *StructBlock // 为泛型结构体实例生成方法时的结构体实例
+	template *Generic
+	key      string
+	node     *Node // 生成的实例节点
+}
+
+// lookup 返回类型参数 name 绑定的类型
+func (g *genericScope) lookup(name Name) typeSys.Type {
+	if name.IsPath() {
+		return nil
+	}
+	for i, param := range g.Params {
+		if param == name.First() {
+			return g.Args[i]
+		}
+	}
+	return nil
+}
+
+// register 在解析实例的函数体或字段之前登记实例，使递归引用直接找到它
+func (g *genericScope) register(node *Node) {
+	g.node = node
+	if g.template.index == nil {
+		g.template.index = map[string]*Node{}
+	}
+	g.template.index[g.key] = node
+	g.template.Instances = append(g.template.Instances, node)
+}
+
+// templateScope 返回解析模板签名时使用的绑定，类型参数绑定为占位类型
+func templateScope(params []string) *genericScope {
+	scope := &genericScope{Params: params}
+	for _, param := range params {
+		scope.Args = append(scope.Args, typeSys.NewTypeParam(param))
+	}
+	return scope
+}
+
+// instancing 判断当前是否正在按类型实参重新解析 instance
+func (p *Parser) instancing(instance any) bool {
+	return p.generic != nil && p.generic.Instance == instance
+}
+
+// typeArgsKey 返回一组类型实参的唯一标识
+func typeArgsKey(args []typeSys.Type) string {
+	names := make([]string, len(args))
+	for i, arg := range args {
+		names[i] = arg.Type()
+	}
+	return strings.Join(names, ",")
+}
+
+// mangle 返回模板名 name 以 args 为类型实参时的实例名
+func mangle(name string, args []typeSys.Type) string {
+	return utils.MangleName(name, strings.Split(typeArgsKey(args), ",")...)
+}
+
+// instantiate 以 scope 中的绑定从模板源代码重新解析出实例，parse 负责在模板位置解析实例
+// 实例化可能发生在任意解析器的任意位置（包括模板所在解析器本身），解析前后保存并恢复其状态
+func (g *Generic) instantiate(scope *genericScope, parse func(tp *Parser)) *Node {
+	tp := g.Parser
+	lexerState := *tp.Lexer
+	thisBlock, dontBack, bracketsNum, generic := tp.ThisBlock, tp.DontBack, tp.BracketsNum, tp.generic
+
+	scope.template = g
+	tp.ThisBlock, tp.DontBack, tp.BracketsNum, tp.generic = tp.Block, 0, 0, scope
+	tp.Lexer.SetCursor(g.Cursor)
+	parse(tp)
+
+	*tp.Lexer = lexerState
+	tp.ThisBlock, tp.DontBack, tp.BracketsNum, tp.generic = thisBlock, dontBack, bracketsNum, generic
+	return scope.node
+}
+
+// late 判断模板是否已经过最终检查，此后生成的实例不会再被遍历，需要立即检查
+func (g *Generic) late() bool {
+	return g.Node != nil && g.Node.Checked
+}
+
+// checkInstances 检查模板的所有实例（检查过程中可能生成新的实例）
+func (g *Generic) checkInstances() bool {
+	for i := 0; i < len(g.Instances); i++ {
+		if !g.Instances[i].Check() {
+			return false
+		}
+	}
+	return true
+}
+
+// Instantiate 返回函数模板以 args 为类型实参的实例，同一组类型实参只生成一次
+func (f *FuncBlock) Instantiate(args []typeSys.Type) *FuncBlock {
+	key := typeArgsKey(args)
+	if node := f.Generic.index[key]; node != nil {
+		return node.Value.(*FuncBlock)
+	}
+	inst := &FuncBlock{TypeArgs: args}
+	node := f.Generic.instantiate(&genericScope{Params: f.Generic.TypeParams, Args: args, Instance: inst, key: key}, inst.parseInstance)
+	// 实例名取自模板当前的名称（包中的函数带有包路径前缀）
+	inst.Name = append(f.Name[:len(f.Name)-1:len(f.Name)-1], mangle(f.Name.Last(), args))
+	f.Generic.checkName(inst.Name, inst, f.Name.String()+"["+key+"]")
+	if f.Generic.late() {
+		node.Check()
+	}
+	return inst
+}
+
+// instantiateMethod 为泛型结构体的实例 s 生成方法模板 f 的实例
+func (f *FuncBlock) instantiateMethod(s *StructBlock) *Node {
+	inst := &FuncBlock{TypeArgs: s.TypeArgs}
+	scope := &genericScope{Params: f.Generic.TypeParams, Args: s.TypeArgs, Instance: inst, Struct: s, key: s.Type().Type()}
+	return f.Generic.instantiate(scope, inst.parseInstance)
+}
+
+// parseInstance 从 fn 之后解析函数实例，直到函数体结束
+func (f *FuncBlock) parseInstance(p *Parser) {
+	f.Parse(p)
+	for p.ThisBlock != p.Block {
+		if p.Next() {
+			break
+		}
+	}
+}
+
+// Instantiate 返回结构体模板以 args 为类型实参的实例，并为其生成所有方法的实例
+func (s *StructBlock) Instantiate(args []typeSys.Type) *StructBlock {
+	key := typeArgsKey(args)
+	if node := s.Generic.index[key]; node != nil {
+		return node.Value.(*StructBlock)
+	}
+	inst := &StructBlock{TypeArgs: args}
+	node := s.Generic.instantiate(&genericScope{Params: s.Generic.TypeParams, Args: args, Instance: inst, key: key}, inst.Parse)
+	s.Generic.checkName(inst.Name, inst, s.Name.String()+"["+key+"]")
+	nodes := []*Node{node}
+	for _, method := range s.MethodTemplates {
+		nodes = append(nodes, method.instantiateMethod(inst))
+	}
+	// 全部方法生成之后再检查，方法之间可以互相调用
+	if s.Generic.late() {
+		for _, n := range nodes {
+			n.Check()
+		}
+	}
+	return inst
+}
+
+// checkName 检查实例名 name 没有被模板所在文件中的其他函数、结构体或其他模板的实例使用
+// 用户声明的名称不能带保留前缀，冲突只会出现在实例之间，如 a[b_int] 与 a_b[int]
+func (g *Generic) checkName(name Name, inst any, desc string) {
+	taken := func(other any, otherName Name) bool {
+		return other != inst && otherName.Eq(name)
+	}
+	for _, child := range g.Parser.Block.Children {
+		var decl any
+		var declName Name
+		var generic *Generic
+		switch v := child.Value.(type) {
+		case *FuncBlock:
+			decl, declName, generic = v, v.Name, v.Generic
+		case *StructBlock:
+			decl, declName, generic = v, v.Name, v.Generic
+		default:
+			continue
+		}
+		conflict := taken(decl, declName)
+		if generic != nil {
+			for _, n := range generic.Instances {
+				switch v := n.Value.(type) {
+				case *FuncBlock:
+					conflict = conflict || taken(v, v.Name)
+				case *StructBlock:
+					conflict = conflict || taken(v, v.Name)
+				}
+			}
+		}
+		if conflict {
+			g.Parser.Error.MissError("Generic Error", g.Cursor, "instance name '"+name.String()+"' of "+desc+" conflicts with another declaration")
+		}
+	}
+}
+
+// checkReserved 用户声明的名称不能以编译器保留的前缀开头（见 utils.ReservedPrefix）
+func (p *Parser) checkReserved(kind string, name Name) {
+	if strings.HasPrefix(name.Last(), utils.ReservedPrefix) {
+		p.Error.MissError(kind+" Error", p.Lexer.Cursor, "name '"+name.String()+"' is reserved: names starting with '"+utils.ReservedPrefix+"' are used by the compiler")
+	}
+}
+
+// parseTypeParams 解析名称之后的类型参数列表 [T, U]，没有时返回 nil 且不移动游标
+func (p *Parser) parseTypeParams() []string {
+	token := p.Lexer.Next()
+	if token.Type != lexer.SEPARATOR || token.Value != "[" {
+		p.Lexer.SetCursor(token.Cursor)
+		return nil
+	}
+	var params []string
+	for {
+		name, _ := p.Name(false)
+		if name.IsPath() {
+			p.Error.MissError("Syntax Error", p.Lexer.Cursor, "invalid type parameter '"+name.String()+"'")
+		}
+		for _, param := range params {
+			if param == name.First() {
+				p.Error.MissError("Syntax Error", p.Lexer.Cursor, "type parameter '"+param+"' redeclared")
+			}
+		}
+		params = append(params, name.First())
+		token = p.Lexer.Next()
+		if token.Value == "]" {
+			return params
+		}
+		if token.Value != "," {
+			p.Error.MissError("Syntax Error", p.Lexer.Cursor, "need ] after type parameters")
+		}
+	}
+}
+
+// parseTypeArgs 解析已读入 '[' 之后的类型实参列表，如 [int, *u8]
+func (p *Parser) parseTypeArgs() []typeSys.Type {
+	var args []typeSys.Type
+	for {
+		name, t := p.ParseType()
+		if t == nil {
+			p.Error.MissError("Type Error", p.Lexer.Cursor, "type '"+name.String()+"' not found")
+		}
+		args = append(args, t)
+		token := p.Lexer.Next()
+		if token.Value == "]" {
+			return args
+		}
+		if token.Value != "," {
+			p.Error.MissError("Syntax Error", p.Lexer.Cursor, "need ] after type arguments")
+		}
+	}
+}
+
+// isTypeArgCall 判断 cursor 处的 '[' ... ']' 之后是否紧跟 '('，即带显式类型实参的调用 name[T](...)，不移动游标
+func (p *Parser) isTypeArgCall(cursor int) bool {
+	oldCursor := p.Lexer.Cursor
+	defer p.Lexer.SetCursor(oldCursor)
+	p.Lexer.SetCursor(cursor)
+	depth := 0
+	for {
+		token := p.Lexer.Next()
+		if token.IsEmpty() {
+			return false
+		}
+		if token.Type != lexer.SEPARATOR {
+			continue
+		}
+		switch token.Value {
+		case "\n", "\r", ";", "=", "{", "}":
+			return false
+		case "[":
+			depth++
+		case "]":
+			depth--
+			if depth == 0 {
+				return p.Lexer.Next().Value == "("
+			}
+		}
+	}
+}
+
+// skipBody 跳过已读入 '{' 之后的代码块，直到与之匹配的 '}'
+func (p *Parser) skipBody() {
+	depth := 0
+	for {
+		token := p.Lexer.Next()
+		if token.IsEmpty() {
+			p.Error.MissError("Syntax Error", p.Lexer.Cursor, "need '}'")
+		}
+		if token.Type != lexer.SEPARATOR {
+			continue
+		}
+		switch token.Value {
+		case "{":
+			depth++
+		case "}":
+			if depth == 0 {
+				return
+			}
+			depth--
+		}
+	}
+}
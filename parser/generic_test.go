@@ -0,0 +1,76 @@
+package parser
+
+import "testing"
+
+// TestReservedName 用户声明的函数、结构体与接口不能使用编译器保留的前缀
+func TestReservedName(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+	}{
+		{"函数", "fn cute_max_int(a: int, b: int) int {\n    ret a\n}\n"},
+		{"结构体", "struct cute_Box_int {\n    v: int\n}\n"},
+		{"接口", "interface cute_Shape {\n    Area() int\n}\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("使用保留前缀的名称没有报错")
+				}
+			}()
+			parseSource(t, c.src)
+		})
+	}
+}
+
+// TestInstanceName 实例名带保留前缀，不与同名的普通函数冲突；不同模板的实例修饰后同名时报错
+func TestInstanceName(t *testing.T) {
+	root := parseSource(t, `fn main() int {
+    ret max(1, 2) + max_int(3, 4)
+}
+
+fn max[T](a: T, b: T) T {
+    ret a
+}
+
+fn max_int(a: int, b: int) int {
+    ret b
+}
+`)
+	root.Check()
+	for _, n := range root.Children {
+		if f, ok := n.Value.(*FuncBlock); ok && f.Generic != nil {
+			if got := f.Generic.Instances[0].Value.(*FuncBlock).Name.String(); got != "cute_max_int" {
+				t.Errorf("max[int] 的实例名为 %s，应为 cute_max_int", got)
+			}
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("a[b_int] 与 a_b[int] 的实例名冲突没有报错")
+		}
+	}()
+	parseSource(t, `struct b_int {
+    v: int
+}
+
+fn main() int {
+    var x: b_int
+    ret id(x) + a_b[int](1)
+}
+
+fn a[T](x: T) int {
+    ret 1
+}
+
+fn id(x: b_int) int {
+    ret a[b_int](x)
+}
+
+fn a_b[T](x: T) int {
+    ret 2
+}
+`).Check()
+}
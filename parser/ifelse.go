@@ -20,26 +20,18 @@ type ElseBlock struct {
 
 // Parse 解析 if 条件块
 func (i *IfBlock) Parse(p *Parser) {
-	bracketsCount := 0
 	oldCursor := p.Lexer.Cursor
 
 	// 找到末尾的{
-	for p.FindEndCursor() > p.Lexer.Cursor {
-		code := p.Lexer.Next()
-		switch code.Value {
-		case "(":
-			bracketsCount++
-		case ")":
-			bracketsCount--
-		}
-		if bracketsCount == 0 && code.Value == "{" && code.Type == lexer.SEPARATOR {
-			break
-		}
-	}
-	stopCursor := p.Lexer.Cursor
+	stopCursor := p.findBlockStart()
 	p.Lexer.SetCursor(oldCursor)
 	i.Condition = p.ParseExp(stopCursor)
 	p.Wait("{")
+
+	// 创建IfBlock节点并进入作用域
+	ifNode := &Node{Value: i}
+	p.ThisBlock.AddChild(ifNode)
+	p.ThisBlock = ifNode
 }
 
 // Check 检查 if 条件块的有效性
@@ -50,7 +42,7 @@ func (i *IfBlock) Check(p *Parser) bool {
 	if !typeSys.CheckTypeType(i.Condition.Type, "bool") {
 		return false
 	}
-	if i.Else {
+	if i.Else && i.ElseBlock.Value.(*ElseBlock).IfCondition != nil {
 		if !i.ElseBlock.Value.(*ElseBlock).IfCondition.Check(p) {
 			return false
 		}
@@ -92,7 +84,7 @@ func (e *ElseBlock) Parse(p *Parser) {
 		p.Error.MissError("Syntax Error", p.Lexer.Cursor, "else before if")
 	}
 	if reflect.TypeOf(p.ThisBlock.Children[len(p.ThisBlock.Children)-1].Value) == reflect.TypeOf(&IfBlock{}) {
-		nodeTmp := &Node{Value: e, Father: p.ThisBlock}
+		nodeTmp := &Node{Value: e, Father: p.ThisBlock, Parser: p, Cursor: p.stmtCursor}
 		p.ThisBlock.Children[len(p.ThisBlock.Children)-1].Value.(*IfBlock).Else = true
 		p.ThisBlock.Children[len(p.ThisBlock.Children)-1].Value.(*IfBlock).ElseBlock = nodeTmp
 		p.ThisBlock = nodeTmp
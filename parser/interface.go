@@ -1,38 +1,177 @@
 package parser
 
+import (
+	"cuteify/lexer"
+	typeSys "cuteify/type"
+	"cuteify/utils"
+)
+
 // InterfaceBlock 接口定义
 type InterfaceBlock struct {
 	Name        Name  // 接口名称
-	Methods     []any // 方法定义（简化处理）
+	Methods     []any // 方法签名（*FuncBlock），顺序即虚表槽位顺序
 	StartCursor int
 	EndCursor   int
+	iface       *typeSys.InterfaceType
 }
 
 // Parse 解析接口
+// 语法格式: interface Name { Method(arg: type, ...) [returnType] }
 func (i *InterfaceBlock) Parse(p *Parser) {
+	if p.ThisBlock.Father != nil {
+		p.Error.MissError("Interface Error", p.Lexer.Cursor, "interface can only be defined at top level")
+	}
+
 	// 解析接口名称
-	nameParts, _ := p.Name(true) // 等待名称
-	i.Name = nameParts
-	i.StartCursor = p.Lexer.Cursor
+	i.Name, i.StartCursor = p.Name(true) // 等待名称
+	if i.Name.IsPath() || !utils.CheckName(i.Name.First()) {
+		p.Error.MissError("Interface Error", p.Lexer.Cursor, "invalid interface name: '"+i.Name.String()+"'")
+	}
+	p.checkReserved("Interface", i.Name)
+	if _, t := p.FindType(i.Name); t != nil {
+		p.Error.MissError("Interface Error", p.Lexer.Cursor, "type '"+i.Name.String()+"' redefined")
+	}
+	i.iface = typeSys.NewInterfaceType(i.Name)
 
-	// 解析接口内容（简化实现）
 	// 期望 {
 	token := p.Lexer.Next()
 	if token.Value != "{" {
 		p.Error.MissError("Interface Error", token.Cursor, "expected '{'")
 	}
 
-	// 解析到 }
+	// 解析方法签名直到 }
 	for {
 		token := p.Lexer.Next()
 		if token.IsEmpty() {
 			p.Error.MissError("Interface Error", p.Lexer.Cursor, "unexpected EOF in interface")
 		}
+		if token.Type == lexer.SEPARATOR {
+			switch token.Value {
+			case "}":
+				i.EndCursor = token.Cursor
+				i.iface.Methods = i.Methods
+				return
+			case "\n", "\r", ";":
+				continue
+			}
+		}
+		p.Lexer.SetCursor(token.Cursor)
+		i.parseMethod(p)
+	}
+}
+
+// parseMethod 解析一个方法签名，签名的接收者为接口本身
+func (i *InterfaceBlock) parseMethod(p *Parser) {
+	name, _ := p.Name(false)
+	if name.IsEmpty() || name.IsPath() {
+		p.Error.MissError("Interface Error", p.Lexer.Cursor, "invalid method name: '"+name.String()+"'")
+	}
+	if FindMethod(i.iface, name.Last()) != nil {
+		p.Error.MissError("Interface Error", p.Lexer.Cursor, "duplicate method '"+name.Last()+"'")
+	}
+
+	method := &FuncBlock{Name: i.Name.ForkJoin(name.Last()), Class: i.iface}
+	method.ParseArgs(p)
+	method.ParseRetType(p)
+	for _, arg := range method.Args {
+		if arg.Type == nil {
+			p.Error.MissError("Interface Error", p.Lexer.Cursor, "missing type of argument '"+arg.Name.String()+"'")
+		}
+	}
+
+	i.Methods = append(i.Methods, method)
+	i.iface.Methods = i.Methods
+}
+
+// Type 返回接口类型
+func (i *InterfaceBlock) Type() typeSys.Type {
+	return i.iface
+}
+
+// assignable 检查 value 能否赋值给 target 类型，赋值给接口时要求结构体实现该接口
+// 数组不能整体赋值，切片只能由元素类型相同的数组变量或切片变量赋值
+func (p *Parser) assignable(value *Expression, target typeSys.Type, isConst bool) bool {
+	switch t := target.(type) {
+	case *typeSys.ArrayType:
+		return false
+	case *typeSys.SliceType:
+		if value.Var == nil || value.Var.Value != nil {
+			return false
+		}
+		switch v := value.Type.(type) {
+		case *typeSys.ArrayType:
+			return v.Elem.Type() == t.Elem.Type()
+		case *typeSys.SliceType:
+			return v.Type() == t.Type()
+		}
+		return false
+	}
+	iface, ok := target.(*typeSys.InterfaceType)
+	if !ok {
+		return typeSys.Assignable(value.Type, target, isConst, value.Num)
+	}
+	switch t := value.Type.(type) {
+	case *typeSys.InterfaceType:
+		return t.Type() == iface.Type()
+	case *typeSys.StructType:
+		// 只有变量（包括 self）才有可取的地址
+		if value.Var == nil || value.Var.Value != nil {
+			return false
+		}
+		return p.implements(value, t, iface)
+	}
+	return false
+}
+
+// assignError 报告 value 不能赋值给 target 类型，位置为值表达式，可以用 as 显式转换时给出提示
+func (p *Parser) assignError(value *Expression, target typeSys.Type, msg string) {
+	if typeSys.Convertible(value.Type, target) {
+		msg += " (use 'as' to convert)"
+	}
+	start, end := value.Span(p)
+	p.Error.MissErrors("Type Error", start, end, msg)
+}
 
-		if token.Value == "}" {
-			i.EndCursor = token.Cursor
-			break
+// implements 按方法签名结构化地检查结构体是否实现接口，并标记用到的方法，错误报告在赋值或传参的值表达式 value 处
+// 方法可能定义在使用之后，因此只在最终检查时报告缺失的方法
+func (p *Parser) implements(value *Expression, structType *typeSys.StructType, iface *typeSys.InterfaceType) bool {
+	final := p.Block == p.ThisBlock
+	start, end := value.Span(p)
+	for _, m := range iface.Methods {
+		want := m.(*FuncBlock)
+		got := FindMethod(structType, want.Name.Last())
+		if got == nil {
+			if final {
+				p.Error.MissErrors("Type Error", start, end, structType.Type()+" does not implement "+iface.Type()+" (missing method "+want.Name.Last()+")")
+			}
+			continue
+		}
+		if !sameSignature(got, want) {
+			p.Error.MissErrors("Type Error", start, end, structType.Type()+" does not implement "+iface.Type()+" (wrong signature for method "+want.Name.Last()+")")
+		}
+		// 通过接口动态分派时按默认调用约定调用，方法不能另行指定
+		if conv := got.CallConv(); conv != "" {
+			p.Error.MissErrors("Type Error", start, end, "method "+structType.Type()+"."+want.Name.Last()+" is called through "+iface.Type()+" and cannot use calling convention "+conv)
+		}
+		got.Useful = true
+	}
+	return true
+}
+
+// sameSignature 比较两个函数的参数和返回值类型是否一致（不含接收者）
+func sameSignature(a, b *FuncBlock) bool {
+	if len(a.Args) != len(b.Args) || len(a.Return) != len(b.Return) {
+		return false
+	}
+	for i := range a.Args {
+		if !typeSys.CheckType(a.Args[i].Type, b.Args[i].Type) {
+			return false
+		}
+	}
+	for i := range a.Return {
+		if !typeSys.CheckType(a.Return[i], b.Return[i]) {
+			return false
 		}
-		// 这里可以添加方法解析逻辑，但现在简化处理
 	}
+	return true
 }
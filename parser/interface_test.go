@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestInterfaceCallConv 通过接口调用的方法按默认调用约定调用，指定了调用约定时在传参的值表达式处报错
+func TestInterfaceCallConv(t *testing.T) {
+	msg := checkError(t, `interface Shape {
+    Area() int
+}
+
+struct Rect {
+    w: int
+}
+
+fn Rect.Area() int {
+    build callconv(fastcall)
+    ret self.w
+}
+
+fn Measure(s: Shape) int {
+    ret s.Area()
+}
+
+fn main() int {
+    var r: Rect
+    r.w = 2
+    ret Measure(r)
+}
+`)
+	if !strings.Contains(msg, "cannot use calling convention fastcall") {
+		t.Errorf("通过接口调用的 fastcall 方法没有报错:\n%s", msg)
+	}
+	if !strings.Contains(msg, "main.cute:21:16:") {
+		t.Errorf("报错位置不是实参 r:\n%s", msg)
+	}
+}
+
+// TestNotImplements 结构体没有实现接口时在赋值的值表达式处报错，方法定义在使用之后时同样如此
+func TestNotImplements(t *testing.T) {
+	msg := checkError(t, `interface Shape {
+    Area() int
+    Name() int
+}
+
+struct Rect {
+    w: int
+}
+
+fn main() int {
+    var r: Rect
+    var s: Shape = r
+    ret s.Area()
+}
+
+fn Rect.Area() int {
+    ret self.w
+}
+`)
+	if !strings.Contains(msg, "Rect does not implement Shape (missing method Name)") {
+		t.Errorf("缺少方法没有报错:\n%s", msg)
+	}
+	if !strings.Contains(msg, "main.cute:12:19:") {
+		t.Errorf("报错位置不是赋值的值 r:\n%s", msg)
+	}
+}
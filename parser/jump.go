@@ -0,0 +1,47 @@
+package parser
+
+// BreakBlock break 语句结构体，跳出最内层循环或 switch
+type BreakBlock struct {
+	Loop *Node // 所属的节点（ForBlock、WhileBlock 或 SwitchBlock）
+}
+
+// ContinueBlock continue 语句结构体，进入最内层循环的下一次迭代
+type ContinueBlock struct {
+	Loop *Node // 所属的循环节点（ForBlock 或 WhileBlock）
+}
+
+// Parse 解析 break 语句
+func (b *BreakBlock) Parse(p *Parser) {
+	b.Loop = p.findLoop(true)
+	if b.Loop == nil {
+		p.Error.MissError("Syntax Error", p.Lexer.Cursor, "break is not in a loop or switch")
+	}
+	p.ThisBlock.AddChild(&Node{Value: b})
+}
+
+// Parse 解析 continue 语句
+func (c *ContinueBlock) Parse(p *Parser) {
+	c.Loop = p.findLoop(false)
+	if c.Loop == nil {
+		p.Error.MissError("Syntax Error", p.Lexer.Cursor, "continue is not in a loop")
+	}
+	p.ThisBlock.AddChild(&Node{Value: c})
+}
+
+// findLoop 从当前作用域向上查找最近的循环节点，遇到函数边界时停止
+// withSwitch 为 true 时 switch 也视为可跳出的节点
+func (p *Parser) findLoop(withSwitch bool) *Node {
+	for current := p.ThisBlock; current != nil; current = current.Father {
+		switch current.Value.(type) {
+		case *ForBlock, *WhileBlock:
+			return current
+		case *SwitchBlock:
+			if withSwitch {
+				return current
+			}
+		case *FuncBlock:
+			return nil
+		}
+	}
+	return nil
+}
@@ -8,12 +8,6 @@ import (
 	"strings"
 )
 
-type CFGNode struct {
-	After     *Node
-	Before    *Node
-	Condition *Expression
-}
-
 type Checker interface {
 	Check(p *Parser) bool
 }
@@ -30,10 +24,8 @@ type Node struct {
 	Children []*Node
 	Ignore   bool
 
-	//CFG []CFGNode // 存储与该节点相关的CFG
-	// Code string
-	//CFG []CFGNode // 存储与该节点相关的CFG
-	// Code string
+	Cursor    int // 语句在源代码中的起始位置，用于报错定位
+	EndCursor int // 块的结束位置（'}'），用于报错定位
 
 	Checked bool
 	Parser  *Parser
@@ -58,6 +50,12 @@ func (n *Node) Check() bool {
 		}
 	}
 
+	// 函数体检查完成后构建控制流图，报告不可达的语句与缺少的返回
+	if f, ok := n.Value.(*FuncBlock); ok && f.Generic == nil {
+		f.CFG = BuildCFG(n)
+		f.CFG.Diagnose(n)
+	}
+
 	n.Checked = true
 
 	return true
@@ -68,46 +66,12 @@ func (n *Node) AddChild(node *Node) {
 	n.Children = append(n.Children, node)
 	node.Parser = n.Parser
 	node.Father = n
+	if node.Cursor == 0 && n.Parser != nil {
+		node.Cursor = n.Parser.stmtCursor
+	}
 	if checker, ok := node.Value.(Checker); ok {
 		checker.Check(n.Parser)
 	}
-	// 判断是否为转移或跳转节点，包括添加的子节点和目标父节点，如果不是将CFG的Before指向前一个节点，并将前一个的CFG的After指向该节点，都设为无条件转移
-	/*if len(n.Children) == 1 {
-		switch n.Value.(type) {
-		case *IfBlock:
-			// 添加CFG
-			node.CFG = append(node.CFG, CFGNode{Before: n})
-			n.CFG = append(n.CFG, CFGNode{After: node, Condition: n.Value.(*IfBlock).Condition})
-		case *ElseBlock:
-			// 添加CFG，添加到他的IfBlock的CFG中
-			node.CFG = append(node.CFG, CFGNode{Before: n.Father})
-			n.Father.CFG = append(n.Father.CFG, CFGNode{After: node, Condition: n.Value.(*ElseBlock).IfCondition})
-		case *FuncBlock:
-			node.CFG = append(node.CFG, CFGNode{Before: n})
-			n.CFG = append(n.CFG, CFGNode{After: node})
-		}
-	} else {
-		tmp := n.Children[len(n.Children)-2]
-		switch tmp.Value.(type) {
-		case *IfBlock:
-			// 把他的子节点的最后一个的After指向该节点
-			if len(tmp.Children) >= 1 {
-				node.CFG = append(node.CFG, CFGNode{Before: tmp.Children[len(tmp.Children)-1], Condition: tmp.Value.(*IfBlock).Condition})
-			} else {
-				node.CFG = append(node.CFG, CFGNode{Before: n.Children[len(n.Children)-2]})
-				tmp.CFG = append(n.CFG, CFGNode{After: node})
-			}
-			if tmp.Value.(*IfBlock).Else {
-				if len(tmp.Value.(*IfBlock).ElseBlock.Children) >= 1 {
-					node.CFG = append(node.CFG, CFGNode{Before: tmp.Value.(*IfBlock).ElseBlock.Children[len(tmp.Value.(*IfBlock).ElseBlock.Children)-1], Condition: tmp.Value.(*IfBlock).Condition})
-				}
-			}
-		default:
-			// before这是上个节点，after是下个节点
-			node.CFG = append(node.CFG, CFGNode{Before: n.Children[len(n.Children)-2]})
-			tmp.CFG = append(n.CFG, CFGNode{After: node})
-		}
-	}*/
 }
 
 // String 返回名称的点分连接形式（NASM 安全格式）
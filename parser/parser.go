@@ -17,6 +17,8 @@ type Parser struct {
 	Error       *errorUtil.Error
 	Package     *packageFmt.Info
 	DontBack    int
+	generic     *genericScope // 当前生效的泛型类型参数绑定
+	stmtCursor  int           // 正在解析的语句的起始位置，记录到新加入的节点中
 }
 
 // Next 解析下一个语法单元，返回是否结束
@@ -29,9 +31,16 @@ func (p *Parser) Next() (finish bool) {
 	}
 
 	if code.Value == "}" && code.Type == lexer.SEPARATOR {
+		// case 分支没有大括号，随 switch 一起结束
+		if _, ok := p.ThisBlock.Value.(*CaseBlock); ok {
+			p.ThisBlock.EndCursor = code.Cursor
+			p.Back(1)
+		}
+		p.ThisBlock.EndCursor = code.Cursor
 		p.Back(1)
 		return
 	}
+	p.stmtCursor = code.Cursor
 
 	//fmt.Println(code.Type)
 
@@ -45,9 +54,16 @@ func (p *Parser) Next() (finish bool) {
 	case lexer.VAR:
 		p.processVarToken(beforeCursor)
 	case lexer.TYPE:
-		//TODO: p.processTypeToken(code)
+		p.processTypeToken(code)
 	case lexer.BUILD:
 		p.processBuildToken(code)
+	case lexer.SEPARATOR:
+		// 以 * 开头的语句为通过指针赋值，如 *p = 5
+		if code.Value == "*" {
+			p.processVarToken(beforeCursor)
+			return
+		}
+		p.processDefaultToken(code)
 	default:
 		p.processDefaultToken(code)
 	}
@@ -83,6 +99,21 @@ func (p *Parser) processControlToken(code lexer.Token) {
 	case "for":
 		block := &ForBlock{}
 		block.Parse(p)
+	case "while":
+		block := &WhileBlock{}
+		block.Parse(p)
+	case "break":
+		block := &BreakBlock{}
+		block.Parse(p)
+	case "continue":
+		block := &ContinueBlock{}
+		block.Parse(p)
+	case "switch":
+		block := &SwitchBlock{}
+		block.Parse(p)
+	case "case", "default":
+		block := &CaseBlock{}
+		block.Parse(p, code.Value == "default")
 	}
 }
 
@@ -107,12 +138,17 @@ func (p *Parser) processNameToken(code lexer.Token, beforeCursor int) {
 		return
 	}
 
+	// name[T](...) 为带显式类型实参的泛型函数调用，否则 name[i] 为下标赋值
+	if code2.Value == "[" && p.isTypeArgCall(code2.Cursor) {
+		code2.Value = "("
+	}
+
 	switch code2.Value {
 	case "(":
 		p.Lexer.SetCursor(code2.Cursor)
 		block := &CallBlock{Name: name}
 		block.Parse(p)
-	case "=", ":=", "+=", "-=", "*=", "/=", "%=", "^=", "&=", "|=", "<<=", ">>=", "++", "--":
+	case "=", ":=", "+=", "-=", "*=", "/=", "%=", "^=", "&=", "|=", "<<=", ">>=", "++", "--", "[":
 		p.Lexer.SetCursor(beforeCursor)
 		block := &VarBlock{}
 		block.Parse(p)
@@ -128,20 +164,24 @@ func (p *Parser) processVarToken(beforeCursor int) {
 	block.Parse(p)
 }
 
-// TODO: func (p *Parser) processTypeToken(code lexer.Token) {
-// TODO: 	switch code.Value {
-// TODO: 	case "struct":
-// TODO: 		block := &StructBlock{}
-// TODO: 		block.Parse(p)
-// TODO: 		p.AddChild(&Node{Value: block})
-// TODO: 	case "interface":
-// TODO: 		block := &InterfaceBlock{}
-// TODO: 		block.Parse(p)
-// TODO: 		p.AddChild(&Node{Value: block})
-// TODO: 	default:
-// TODO: 		p.processDefaultToken(code)
-// TODO: 	}
-// TODO: }
+func (p *Parser) processTypeToken(code lexer.Token) {
+	switch code.Value {
+	case "struct":
+		block := &StructBlock{}
+		block.Parse(p)
+		node := &Node{Value: block}
+		if block.Generic != nil {
+			block.Generic.Node = node
+		}
+		p.AddChild(node)
+	case "interface":
+		block := &InterfaceBlock{}
+		block.Parse(p)
+		p.AddChild(&Node{Value: block})
+	default:
+		p.processDefaultToken(code)
+	}
+}
 
 func (p *Parser) processBuildToken(token lexer.Token) {
 	block := &Build{}
@@ -257,18 +297,20 @@ func (p *Parser) Name(wait bool) (name Name, cursor int) {
 	buf := ""
 
 	for {
+		// 回退到 Token 之前，字符串等 Token 的 Cursor 不包含引号
+		oldCursor := p.Lexer.Cursor
 		code := p.Lexer.Next()
 		switch code.Type {
 		case lexer.NAME:
 			buf += code.Value
 		case lexer.SEPARATOR:
 			if len(code.Value) != 1 || bytes.IndexByte([]byte{'.', '_'}, code.Value[0]) == -1 {
-				p.Lexer.SetCursor(code.Cursor)
+				p.Lexer.SetCursor(oldCursor)
 				goto nameFindEnd
 			}
 			buf += code.Value
 		default:
-			p.Lexer.SetCursor(code.Cursor)
+			p.Lexer.SetCursor(oldCursor)
 			goto nameFindEnd
 		}
 	}
@@ -19,9 +19,9 @@ func (r *ReturnBlock) Parse(p *Parser) {
 		code := p.Lexer.Next()
 		if code.Type == lexer.SEPARATOR {
 			switch code.Value {
-			case "(":
+			case "(", "[":
 				brecket++
-			case ")":
+			case ")", "]":
 				brecket--
 			}
 		}
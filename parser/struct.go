@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"cuteify/lexer"
 	typeSys "cuteify/type"
+	"cuteify/utils"
 	"unsafe"
 )
 
@@ -22,323 +24,395 @@ func (s *StructType) FromType(st *typeSys.StructType) {
 
 type StructBlock struct {
 	StructType
-	Checked     bool
-	StartCursor int
+	Parents         []Name // 继承的父结构体
+	Checked         bool
+	StartCursor     int
+	Generic         *Generic       // 泛型模板信息，非空时本身没有字段布局，只用来生成实例
+	TypeArgs        []typeSys.Type // 泛型实例的类型实参
+	MethodTemplates []*FuncBlock   // 泛型结构体的方法模板，每个实例各生成一份
 }
 
-// TODO: func (s *StructBlock) Parse(p *Parser) {
-// TODO: 	token := p.Lexer.Next()
-// TODO: 	if token.Type != lexer.NAME {
-// TODO: 		p.Error.MissError("Struct Error", token.Cursor, "struct name required")
-// TODO: 	}
-// TODO: 	s.Name = Name([]string{token.Value})
-// TODO:
-// TODO: 	if !utils.CheckName(s.Name.String()) {
-// TODO: 		p.Error.MissError("Struct Error", token.Cursor, "invalid struct name: '"+s.Name.String()+"'")
-// TODO: 	}
-// TODO:
-// TODO: 	nextToken := p.Lexer.Next()
-// TODO: 	if nextToken.Type == lexer.SEPARATOR && nextToken.Value == ":" {
-// TODO: 		s.RParents = s.parseInheritance(p)
-// TODO: 		openBraceToken := p.Lexer.Next()
-// TODO: 		if openBraceToken.Value != "{" {
-// TODO: 			p.Error.MissError("Struct Error", openBraceToken.Cursor, "expected '{'")
-// TODO: 		}
-// TODO: 	} else {
-// TODO: 		if nextToken.Value != "{" {
-// TODO: 			p.Error.MissError("Struct Error", nextToken.Cursor, "expected '{'")
-// TODO: 		}
-// TODO: 	}
-// TODO:
-// TODO: 	p.CurrentStruct = s
-// TODO: 	s.parseFields(p)
-// TODO: 	p.CurrentStruct = nil
-// TODO: 	s.CalculateMemoryLayout()
-// TODO: }
-
-// TODO: func (s *StructBlock) parseInheritance(p *Parser) []string {
-// TODO: 	var parents []string
-// TODO:
-// TODO: 	token := p.Lexer.Next()
-// TODO: 	if token.Type != lexer.NAME {
-// TODO: 		p.Error.MissError("Struct Error", token.Cursor, "parent struct name required")
-// TODO: 	}
-// TODO: 	parents = append(parents, token.Value)
-// TODO:
-// TODO: 	for {
-// TODO: 		token = p.Lexer.Next()
-// TODO: 		if token.Value != "+" {
-// TODO: 			p.Lexer.SetCursor(token.Cursor)
-// TODO: 			break
-// TODO: 		}
-// TODO:
-// TODO: 		token = p.Lexer.Next()
-// TODO: 		if token.Type != lexer.NAME {
-// TODO: 			p.Error.MissError("Struct Error", token.Cursor, "parent struct name required")
-// TODO: 		}
-// TODO: 		parents = append(parents, token.Value)
-// TODO: 	}
-// TODO:
-// TODO: 	return parents
-// TODO: }
-
-// TODO: func (s *StructBlock) parseFields(p *Parser) {
-// TODO: 	for {
-// TODO: 		token := p.Lexer.Next()
-// TODO: 		fmt.Println(token)
-// TODO: 		if token.IsEmpty() {
-// TODO: 			p.Error.MissError("Struct Error", p.Lexer.Cursor, "unexpected EOF in struct")
-// TODO: 		}
-// TODO:
-// TODO: 		if token.Value == "}" {
-// TODO: 			break
-// TODO: 		}
-// TODO:
-// TODO: 		s.parseField(p)
-// TODO: 	}
-// TODO: }
-
-// TODO: func (p *Parser) parseStructMethod(s *StructBlock) *FuncBlock {
-// TODO: 	method := &FuncBlock{
-// TODO: 		Class: createStructType(s),
-// TODO: 	}
-// TODO:
-// TODO: 	code := p.Lexer.Next()
-// TODO: 	if code.Type != lexer.NAME {
-// TODO: 		p.Error.MissError("Method Error", p.Lexer.Cursor, "method name required")
-// TODO: 		return nil
-// TODO: 	}
-// TODO:
-// TODO: 	method.Name = append(s.Name, code.Value)
-// TODO:
-// TODO: 	method.ParseArgs(p)
-// TODO:
-// TODO: 	code = p.Lexer.Next()
-// TODO: 	if code.Type == lexer.NAME {
-// TODO: 		method.Return = []typeSys.Type{typeSys.GetSystemType(code.Value)}
-// TODO: 	} else {
-// TODO: 		p.Lexer.SetCursor(code.Cursor)
-// TODO: 		method.Return = []typeSys.Type{}
-// TODO: 	}
-// TODO:
-// TODO: 	p.Wait("{")
-// TODO: 	nodeTmp := &Node{Value: method}
-// TODO: 	p.ThisBlock.AddChild(nodeTmp)
-// TODO: 	p.ThisBlock = nodeTmp
-// TODO:
-// TODO: 	p.parseMethodBody()
-// TODO:
-// TODO: 	return method
-// TODO: }
-
-// TODO: func (p *Parser) parseMethodBody() {
-// TODO: 	braceCount := 1
-// TODO: 	for {
-// TODO: 		token := p.Lexer.Next()
-// TODO: 		if token.IsEmpty() {
-// TODO: 			p.Error.MissError("Method Error", p.Lexer.Cursor, "unexpected EOF in method body")
-// TODO: 			return
-// TODO: 		}
-// TODO:
-// TODO: 		if token.Value == "{" {
-// TODO: 			braceCount++
-// TODO: 		} else if token.Value == "}" {
-// TODO: 			braceCount--
-// TODO: 			if braceCount == 0 {
-// TODO: 				p.ThisBlock = p.ThisBlock.Father
-// TODO: 				return
-// TODO: 			}
-// TODO: 		}
-// TODO:
-// TODO: 		p.Lexer.SetCursor(token.Cursor)
-// TODO: 		p.Next()
-// TODO: 	}
-// TODO: }
-
-// TODO: func (s *StructBlock) parseField(p *Parser) {
-// TODO: 	field := &StructField{Access: AccessPublic}
-// TODO: 	field.StartCursor = p.Lexer.Cursor
-// TODO:
-// TODO: 	// 解析字段标识
-// TODO: 	token := p.Lexer.Next()
-// TODO:
-// TODO: 	if token.Type == lexer.SEPARATOR {
-// TODO: 		switch token.Value {
-// TODO: 		case "!":
-// TODO: 			field.Access = AccessReadOnly
-// TODO: 		case "?":
-// TODO: 			field.Access = AccessWriteOnly
-// TODO: 		default:
-// TODO: 			p.Error.MissError("Struct Error", token.Cursor, "invalid field prefix: '"+token.Value+"'")
-// TODO: 		}
-// TODO: 	} else {
-// TODO: 		p.Lexer.SetCursor(token.Cursor)
-// TODO: 	}
-// TODO:
-// TODO: 	// 解析字段名
-// TODO: 	s.parseFieldName(p, field)
-// TODO:
-// TODO: 	// 解析字段类型
-// TODO: 	s.parseFieldType(p, field)
-// TODO:
-// TODO: 	// 其他数据
-// TODO: 	stopCursor := p.Lexer.Cursor
-// TODO: 	for p.Lexer.Cursor < stopCursor {
-// TODO: 		s.parseFieldOthers(p, field)
-// TODO: 	}
-// TODO: }
-
-// TODO: func (s *StructBlock) parseFieldType(p *Parser, field *StructField) {
-// TODO: 	// 跳过：
-// TODO: 	p.Lexer.Skip(':')
-// TODO:
-// TODO: 	name, _ := p.Name(false)
-// TODO:
-// TODO: 	field.Type = typeSys.GetSystemType(name.String())
-// TODO: }
-
-// TODO: func (s *StructBlock) parseFieldName(p *Parser, field *StructField) {
-// TODO: 	name, _ := p.Name(false)
-// TODO:
-// TODO: 	field.Name = name.Last()
-// TODO:
-// TODO: 	if len(name) > 0 && name.Last()[0] == '_' {
-// TODO: 		field.Access = AccessPrivate
-// TODO: 	}
-// TODO: }
-
-// TODO: func (s *StructBlock) parseFieldOthers(p *Parser, field *StructField) {
-// TODO: 	// 其他数据
-// TODO: 	nextToken := p.Lexer.Next()
-// TODO: 	if nextToken.Type == lexer.RAW {
-// TODO: 		field.parseTags(nextToken.Value)
-// TODO: 	}
-// TODO: 	switch nextToken.Value {
-// TODO: 	case "=":
-// TODO: 		field.DefaultValue = p.ParseExp(p.FindEndCursor())
-// TODO: 	case ";", "\n", "\r", "}":
-// TODO: 		s.Fields = append(s.Fields, field)
-// TODO: 	}
-// TODO: }
-
-// TODO: func convertStructFields(fields []*StructField) typeSys.StructFileds {
-// TODO: 	var result typeSys.StructFileds
-// TODO: 	for _, f := range fields {
-// TODO: 		result = append(result, &typeSys.StructField{
-// TODO: 			Name:   f.Name,
-// TODO: 			Type:   f.Type,
-// TODO: 			Offset: f.Offset,
-// TODO: 		})
-// TODO: 	}
-// TODO: 	return result
-// TODO: }
-
-// TODO: func (s *StructBlock) Check(p *Parser) bool {
-// TODO: 	if s.Checked {
-// TODO: 		return true
-// TODO: 	}
-// TODO: 	s.Checked = true
-// TODO:
-// TODO: 	if len(s.Parents) > 0 {
-// TODO: 		s.processInheritance(p)
-// TODO: 	}
-// TODO:
-// TODO: 	s.CalculateMemoryLayout()
-// TODO: 	return true
-// TODO: }
-
-// TODO: func (s *StructBlock) processInheritance(p *Parser) {
-// TODO: 	var inheritedFields []*StructField
-// TODO:
-// TODO: 	for _, parentName := range s.Parents {
-// TODO: 		parent := p.FindStruct(parentName)
-// TODO: 		if parent == nil {
-// TODO: 			p.Error.MissError("Struct Error", s.StartCursor,
-// TODO: 				"parent struct '"+parentName+"' not found")
-// TODO: 			continue
-// TODO: 		}
-// TODO: 		parent.Check(p)
-// TODO: 		for _, field := range parent.Fields {
-// TODO: 			newField := &StructField{
-// TODO: 				Name:   field.Name,
-// TODO: 				Type:   field.Type,
-// TODO: 				Tags:   field.Tags,
-// TODO: 				Access: field.Access,
-// TODO: 			}
-// TODO: 			inheritedFields = append(inheritedFields, newField)
-// TODO: 		}
-// TODO: 	}
-// TODO: 	s.Fields = append(inheritedFields, s.Fields...)
-// TODO: }
-
-// TODO: func (s *StructBlock) CalculateMemoryLayout() {
-// TODO: 	offset := 0
-// TODO: 	maxAlignment := 1
-// TODO:
-// TODO: 	for _, field := range s.Fields {
-// TODO: 		var alignment int
-// TODO: 		if field.Type != nil {
-// TODO: 			size := field.Type.Size()
-// TODO: 			if size == 0 {
-// TODO: 				alignment = 1
-// TODO: 			} else {
-// TODO: 				alignment = size
-// TODO: 			}
-// TODO: 		} else {
-// TODO: 			alignment = 1
-// TODO: 		}
-// TODO: 		field.Alignment = alignment
-// TODO: 		if field.Alignment > maxAlignment {
-// TODO: 			maxAlignment = field.Alignment
-// TODO: 		}
-// TODO:
-// TODO: 		if field.Alignment > 0 {
-// TODO: 			padding := (field.Alignment - (offset % field.Alignment)) % field.Alignment
-// TODO: 			offset += padding
-// TODO: 		}
-// TODO: 		field.Offset = offset
-// TODO:
-// TODO: 		if field.Type != nil {
-// TODO: 			field.Size = field.Type.Size()
-// TODO: 			if field.Size == 0 {
-// TODO: 				field.Size = 1
-// TODO: 			}
-// TODO: 		} else {
-// TODO: 			field.Size = 1
-// TODO: 		}
-// TODO: 		offset += field.Size
-// TODO: 		typeStr := ""
-// TODO: 		if field.Type != nil {
-// TODO: 			typeStr = field.Type.Type()
-// TODO: 		} else {
-// TODO: 			typeStr = "unknown"
-// TODO: 		}
-// TODO: 		field.EndCursor = field.StartCursor + len(field.Name) + len(typeStr) + 5
-// TODO: 	}
-// TODO:
-// TODO: 	s.Size = offset
-// TODO: 	s.Alignment = maxAlignment
-// TODO: }
-
-// TODO: func (s *StructBlock) GetFieldByName(fieldName any) *StructField {
-// TODO: 	var searchName string
-// TODO: 	switch n := fieldName.(type) {
-// TODO: 	case Name:
-// TODO: 		searchName = n.String()
-// TODO: 	case string:
-// TODO: 		searchName = n
-// TODO: 	default:
-// TODO: 		return nil
-// TODO: 	}
-// TODO:
-// TODO: 	for _, field := range s.Fields {
-// TODO: 		if field.Name == searchName {
-// TODO: 			return field
-// TODO: 		}
-// TODO: 	}
-// TODO: 	return nil
-// TODO: }
-
-// TODO: func (s *StructBlock) Type() typeSys.Type {
-// TODO: 	return s.ToType()
-// TODO: }
+// Parse 解析结构体定义
+// 语法格式: struct Name[T, U] [: Parent [+ Parent2]] { [pub|priv|prot] [!|?]field: type [= default] [`tags`] }
+func (s *StructBlock) Parse(p *Parser) {
+	if p.ThisBlock.Father != nil {
+		p.Error.MissError("Struct Error", p.Lexer.Cursor, "struct can only be defined at top level")
+	}
+
+	startCursor := p.Lexer.Cursor
+	instance := p.instancing(s)
+	s.Name, s.StartCursor = p.Name(true)
+	if s.Name.IsPath() || !utils.CheckName(s.Name.First()) {
+		p.Error.MissError("Struct Error", p.Lexer.Cursor, "invalid struct name: '"+s.Name.String()+"'")
+	}
+	if _, exists := p.FindStruct(s.Name); exists != nil && !instance {
+		p.Error.MissError("Struct Error", p.Lexer.Cursor, "struct '"+s.Name.String()+"' redefined")
+	}
+	if !instance {
+		p.checkReserved("Struct", s.Name)
+	}
+	s.TypeName = "struct"
+
+	typeParams := p.parseTypeParams()
+	if instance {
+		// 实例以修饰后的名称作为类型名，在解析字段之前登记，使字段可以引用自身（如 *Node[T]）
+		s.Name = Name([]string{mangle(s.Name.First(), s.TypeArgs)})
+		p.generic.register(&Node{Value: s, Parser: p, Father: p.Block})
+	} else if len(typeParams) > 0 {
+		// 泛型结构体的字段在实例化时按类型实参重新解析
+		s.Generic = &Generic{TypeParams: typeParams, Parser: p, Cursor: startCursor}
+		p.Wait("{")
+		p.skipBody()
+		return
+	}
+
+	token := p.Lexer.Next()
+	if token.Type == lexer.SEPARATOR && token.Value == ":" {
+		s.parseInheritance(p)
+		token = p.Lexer.Next()
+	}
+	if token.Value != "{" {
+		p.Error.MissError("Struct Error", token.Cursor, "expected '{'")
+	}
+
+	s.parseFields(p)
+	s.processInheritance(p)
+	s.CalculateMemoryLayout()
+}
+
+// parseInheritance 解析以 '+' 分隔的父结构体列表
+func (s *StructBlock) parseInheritance(p *Parser) {
+	for {
+		name, _ := p.Name(true)
+		s.Parents = append(s.Parents, name)
+
+		token := p.Lexer.Next()
+		if token.Value != "+" {
+			p.Lexer.SetCursor(token.Cursor)
+			return
+		}
+	}
+}
+
+func (s *StructBlock) parseFields(p *Parser) {
+	for {
+		token := p.Lexer.Next()
+		if token.IsEmpty() {
+			p.Error.MissError("Struct Error", p.Lexer.Cursor, "unexpected EOF in struct")
+		}
+		if token.Type == lexer.SEPARATOR {
+			switch token.Value {
+			case "}":
+				return
+			case "\n", "\r", ";":
+				continue
+			}
+		}
+		p.Lexer.SetCursor(token.Cursor)
+		s.parseField(p)
+	}
+}
+
+func (s *StructBlock) parseField(p *Parser) {
+	field := &typeSys.StructField{Access: typeSys.AccessPublic, Owner: s.ToType().Type()}
+
+	// 访问修饰符
+	token := p.Lexer.Next()
+	if token.Type == lexer.NAME {
+		access, isModifier := map[string]typeSys.FieldAccess{
+			"pub":  typeSys.AccessPublic,
+			"priv": typeSys.AccessPrivate,
+			"prot": typeSys.AccessProtected,
+		}[token.Value]
+		// 修饰符后紧跟 ':' 时说明它本身是字段名
+		if next := p.Lexer.Next(); isModifier && next.Value != ":" {
+			field.Access = access
+			p.Lexer.SetCursor(next.Cursor)
+		} else {
+			p.Lexer.SetCursor(token.Cursor)
+		}
+	} else if token.Type == lexer.SEPARATOR {
+		switch token.Value {
+		case "!":
+			field.Access = typeSys.AccessReadOnly
+		case "?":
+			field.Access = typeSys.AccessWriteOnly
+		default:
+			p.Error.MissError("Struct Error", token.Cursor, "invalid field prefix: '"+token.Value+"'")
+		}
+	} else {
+		p.Lexer.SetCursor(token.Cursor)
+	}
+
+	s.parseFieldName(p, field)
+	s.parseFieldType(p, field)
+	s.parseFieldOthers(p, field)
+
+	s.StructFields = append(s.StructFields, field)
+}
+
+func (s *StructBlock) parseFieldName(p *Parser, field *typeSys.StructField) {
+	name, _ := p.Name(false)
+	if name.IsPath() {
+		p.Error.MissError("Struct Error", p.Lexer.Cursor, "invalid field name: '"+name.String()+"'")
+	}
+	field.Name = name.Last()
+
+	if field.Name[0] == '_' {
+		field.Access = typeSys.AccessPrivate
+	}
+	if s.GetFieldByName(field.Name) != nil {
+		p.Error.MissError("Struct Error", p.Lexer.Cursor, "duplicate field '"+field.Name+"'")
+	}
+}
+
+func (s *StructBlock) parseFieldType(p *Parser, field *typeSys.StructField) {
+	// 跳过 ':'
+	p.Lexer.Skip(':')
+
+	name, t := p.ParseType()
+	field.Type = t
+	if field.Type == nil {
+		p.Error.MissError("Struct Error", p.Lexer.Cursor, "type '"+name.String()+"' not found")
+	}
+}
+
+// parseFieldOthers 解析字段的默认值与标签，直到行尾
+func (s *StructBlock) parseFieldOthers(p *Parser, field *typeSys.StructField) {
+	for {
+		token := p.Lexer.Next()
+		switch {
+		case token.IsEmpty():
+			return
+		case token.Type == lexer.RAW:
+			field.ParseTags(token.Value)
+		case token.Type == lexer.SEPARATOR && token.Value == "=":
+			field.Default = p.ParseExp(p.findFieldValueEnd())
+		case token.Type == lexer.SEPARATOR && (token.Value == "\n" || token.Value == "\r" || token.Value == ";"):
+			return
+		case token.Type == lexer.SEPARATOR && token.Value == "}":
+			p.Lexer.SetCursor(token.Cursor)
+			return
+		default:
+			p.Error.MissError("Struct Error", token.Cursor, "unexpected '"+token.Value+"' in field '"+field.Name+"'")
+		}
+	}
+}
+
+// findFieldValueEnd 返回默认值表达式最后一个 Token 的结束位置（不包含标签）
+// 调用后光标保持不变
+func (p *Parser) findFieldValueEnd() int {
+	oldCursor := p.Lexer.Cursor
+	stopCursor := oldCursor
+	for {
+		token := p.Lexer.Next()
+		if token.IsEmpty() || token.Type == lexer.RAW {
+			break
+		}
+		if token.Type == lexer.SEPARATOR && (token.Value == "\n" || token.Value == "\r" || token.Value == ";" || token.Value == "}") {
+			break
+		}
+		stopCursor = token.EndCursor
+	}
+	p.Lexer.SetCursor(oldCursor)
+	return stopCursor
+}
+
+// processInheritance 将父结构体的字段复制到当前结构体之前
+func (s *StructBlock) processInheritance(p *Parser) {
+	var inheritedFields typeSys.StructFileds
+
+	for _, parentName := range s.Parents {
+		_, parent := p.FindStruct(parentName)
+		if parent == nil {
+			p.Error.MissError("Struct Error", s.StartCursor, "parent struct '"+parentName.String()+"' not found")
+			continue
+		}
+		for _, field := range parent.StructFields {
+			if s.GetFieldByName(field.Name) != nil {
+				p.Error.MissError("Struct Error", s.StartCursor, "field '"+field.Name+"' conflicts with parent struct '"+parentName.String()+"'")
+			}
+			newField := *field
+			newField.Tags = append([]typeSys.StructTag(nil), field.Tags...)
+			inheritedFields = append(inheritedFields, &newField)
+		}
+	}
+	if len(s.Parents) > 0 {
+		_, parent := p.FindStruct(s.Parents[0])
+		if parent != nil {
+			s.RParent = parent.Type()
+		}
+	}
+	s.StructFields = append(inheritedFields, s.StructFields...)
+}
+
+// CalculateMemoryLayout 按自然对齐计算字段偏移、结构体大小（含尾部填充）和对齐值
+func (s *StructBlock) CalculateMemoryLayout() {
+	offset := 0
+	maxAlignment := 1
+
+	for _, field := range s.StructFields {
+		field.Size = field.Type.Size()
+		field.Alignment = typeSys.AlignOf(field.Type)
+		if field.Alignment > maxAlignment {
+			maxAlignment = field.Alignment
+		}
+
+		offset = typeSys.AlignUp(offset, field.Alignment)
+		field.Offset = offset
+		offset += field.Size
+	}
+
+	s.RSize = typeSys.AlignUp(offset, maxAlignment)
+	s.RAlignment = maxAlignment
+}
+
+// Check 检查字段默认值：必须为与字段类型兼容的常量
+func (s *StructBlock) Check(p *Parser) bool {
+	if s.Checked {
+		return true
+	}
+	// 泛型模板本身不检查，检查它生成的各个实例及其方法
+	if s.Generic != nil {
+		if !s.Generic.checkInstances() {
+			return false
+		}
+		for _, method := range s.MethodTemplates {
+			if !method.Generic.checkInstances() {
+				return false
+			}
+		}
+		return true
+	}
+	for _, field := range s.StructFields {
+		def, ok := field.Default.(*Expression)
+		if !ok || def == nil {
+			continue
+		}
+		if !def.Check(p) {
+			return false
+		}
+		if !def.IsConst() {
+			p.Error.MissError("Struct Error", s.StartCursor, "default value of field '"+field.Name+"' must be constant")
+		}
+		if !typeSys.Assignable(def.Type, field.Type, true, def.Num) {
+			p.Error.MissError("Type Error", s.StartCursor, "default value of field '"+field.Name+"' need type "+field.Type.Type()+", not "+def.Type.Type())
+		}
+	}
+	s.Checked = true
+	return true
+}
+
+// GetFieldByName 按名称查找字段
+func (s *StructBlock) GetFieldByName(fieldName string) *typeSys.StructField {
+	return s.ToType().Field(fieldName)
+}
+
+// Type 返回结构体类型，与 StructBlock 共用同一份字段布局
+func (s *StructBlock) Type() typeSys.Type {
+	return s.ToType()
+}
+
+// findFields 沿字段路径查找字段，baseType 为路径起点的类型
+func (p *Parser) findFields(baseType typeSys.Type, path Name) []*typeSys.StructField {
+	var fields []*typeSys.StructField
+	currentType := baseType
+	for _, fieldName := range path {
+		structType, ok := currentType.(*typeSys.StructType)
+		if !ok {
+			p.Error.MissError("Field access error", p.Lexer.Cursor, "type '"+currentType.Type()+"' is not a struct")
+			return nil
+		}
+		field := structType.Field(fieldName)
+		if field == nil {
+			p.Error.MissError("Field access error", p.Lexer.Cursor, "struct '"+structType.Type()+"' has no field '"+fieldName+"'")
+			return nil
+		}
+		fields = append(fields, field)
+		currentType = field.Type
+	}
+	return fields
+}
+
+// checkFieldAccess 检查字段路径的访问权限，write 表示最后一个字段被写入
+// 结构体自身的方法不受访问修饰符限制
+func (p *Parser) checkFieldAccess(v *VarBlock, write bool) {
+	baseType := v.baseType()
+	if baseType == nil || !v.Name.IsPath() {
+		return
+	}
+	owner := p.currentMethodOwner()
+
+	currentType := baseType
+	fields := p.findFields(baseType, v.Name[1:])
+	for i, field := range fields {
+		structName := currentType.Type()
+		currentType = field.Type
+		if field.IsPrivate() && owner != field.Owner {
+			p.Error.MissError("Field access error", p.Lexer.Cursor, "field '"+field.Name+"' is private")
+		}
+		// 在声明该字段的结构体或继承它的结构体的方法中不受限制
+		if owner == structName || owner == field.Owner {
+			continue
+		}
+		switch {
+		case field.IsProtected():
+			p.Error.MissError("Field access error", p.Lexer.Cursor, "field '"+field.Name+"' is protected")
+		case i == len(fields)-1 && write && field.IsReadOnly():
+			p.Error.MissError("Field access error", p.Lexer.Cursor, "field '"+field.Name+"' is read-only")
+		case (i != len(fields)-1 || !write) && field.IsWriteOnly():
+			p.Error.MissError("Field access error", p.Lexer.Cursor, "field '"+field.Name+"' is write-only")
+		}
+	}
+}
+
+// currentMethodOwner 返回当前所在方法所属的结构体名，不在方法中时返回空
+func (p *Parser) currentMethodOwner() string {
+	for current := p.ThisBlock; current != nil; current = current.Father {
+		if funcBlock, ok := current.Value.(*FuncBlock); ok {
+			if funcBlock.Class != nil {
+				return funcBlock.Class.Type()
+			}
+			if len(funcBlock.Name) == 2 {
+				return funcBlock.Name.First()
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// MethodSlot 返回方法在接口虚表中的槽位
+func MethodSlot(iface *typeSys.InterfaceType, name string) int {
+	for i, m := range iface.Methods {
+		if m.(*FuncBlock).Name.Last() == name {
+			return i
+		}
+	}
+	panic("编译器内部错误: 接口 " + iface.Type() + " 没有方法 " + name)
+}
+
+// FindMethod 在结构体及其（第一个）父结构体链中按名称查找方法，接口类型返回方法签名
+func FindMethod(t typeSys.Type, name string) *FuncBlock {
+	if iface, ok := t.(*typeSys.InterfaceType); ok {
+		for _, method := range iface.Methods {
+			if funcBlock := method.(*FuncBlock); funcBlock.Name.Last() == name {
+				return funcBlock
+			}
+		}
+		return nil
+	}
+	for t != nil {
+		structType, ok := t.(*typeSys.StructType)
+		if !ok {
+			return nil
+		}
+		for _, method := range structType.Methods {
+			if funcBlock, ok := method.(*FuncBlock); ok && funcBlock.Name.Last() == name {
+				return funcBlock
+			}
+		}
+		t = structType.Parent()
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"cuteify/lexer"
+	typeSys "cuteify/type"
+	"strconv"
+)
+
+// SwitchBlock switch 分支结构体
+type SwitchBlock struct {
+	Value   *Expression  // 被匹配的值
+	Cases   []*CaseBlock // 按出现顺序排列的分支（包含 default）
+	Default *CaseBlock   // default 分支，可为空
+	Offset  int          // switch 编号（用于生成唯一标签）
+}
+
+// CaseBlock switch 中的单个分支
+type CaseBlock struct {
+	Values    []*Expression // 匹配值（常量），default 分支为空
+	IsDefault bool          // 是否为 default 分支
+	Switch    *SwitchBlock  // 所属的 switch
+	Offset    int           // 分支在 switch 中的序号（用于生成唯一标签）
+	checked   bool
+}
+
+// Parse 解析 switch 语句
+// 语法格式: switch (value) { case 1, 2: ... default: ... }
+func (s *SwitchBlock) Parse(p *Parser) {
+	oldCursor := p.Lexer.Cursor
+	stopCursor := p.findBlockStart()
+	p.Lexer.SetCursor(oldCursor)
+	s.Value = p.ParseExp(stopCursor)
+	p.Wait("{")
+
+	switchNode := &Node{Value: s}
+	p.ThisBlock.AddChild(switchNode)
+	p.ThisBlock = switchNode
+}
+
+// Check 检查 switch 的值是否可用于分支匹配
+func (s *SwitchBlock) Check(p *Parser) bool {
+	if s.Value == nil {
+		p.Error.MissError("Switch Error", p.Lexer.Cursor, "switch need value")
+		return false
+	}
+	if !s.Value.Check(p) {
+		return false
+	}
+	if !typeSys.CheckTypeType(s.Value.Type, "int", "uint", "bool") {
+		p.Error.MissError("Switch Error", p.Lexer.Cursor, "switch value must be integer, char or bool")
+		return false
+	}
+	return true
+}
+
+// Parse 解析 case/default 分支，isDefault 表示是否为 default 分支
+func (c *CaseBlock) Parse(p *Parser, isDefault bool) {
+	// 上一个分支没有大括号，遇到新的分支时先回到 switch
+	if _, ok := p.ThisBlock.Value.(*CaseBlock); ok {
+		p.Back(1)
+	}
+	switchBlock, ok := p.ThisBlock.Value.(*SwitchBlock)
+	if !ok {
+		p.Error.MissError("Syntax Error", p.Lexer.Cursor, "case must be in switch")
+		return
+	}
+	c.Switch = switchBlock
+	c.IsDefault = isDefault
+	c.Offset = len(switchBlock.Cases)
+
+	if isDefault {
+		if switchBlock.Default != nil {
+			p.Error.MissError("Switch Error", p.Lexer.Cursor, "multiple defaults in switch")
+		}
+		p.Lexer.Skip(':')
+		switchBlock.Default = c
+	} else {
+		c.parseValues(p)
+	}
+	switchBlock.Cases = append(switchBlock.Cases, c)
+
+	caseNode := &Node{Value: c}
+	p.ThisBlock.AddChild(caseNode)
+	p.ThisBlock = caseNode
+}
+
+// parseValues 解析以 ',' 分隔、以 ':' 结尾的分支值列表
+func (c *CaseBlock) parseValues(p *Parser) {
+	for {
+		segStart := p.Lexer.Cursor
+		segEnd := segStart
+		var code lexer.Token
+		for {
+			code = p.Lexer.Next()
+			if code.IsEmpty() || code.Value == "\n" || code.Value == "\r" {
+				p.Error.MissError("Syntax Error", p.Lexer.Cursor, "case need ':'")
+			}
+			if code.Type == lexer.SEPARATOR && (code.Value == "," || code.Value == ":") {
+				break
+			}
+			segEnd = code.EndCursor
+		}
+		next := p.Lexer.Cursor
+		if segEnd == segStart {
+			p.Error.MissError("Syntax Error", segStart, "case need value")
+		}
+		p.Lexer.SetCursor(segStart)
+		c.Values = append(c.Values, p.ParseExp(segEnd))
+		p.Lexer.SetCursor(next)
+		if code.Value == ":" {
+			return
+		}
+	}
+}
+
+// Check 检查分支值：必须为常量、类型与 switch 值一致且不与之前的分支重复
+func (c *CaseBlock) Check(p *Parser) bool {
+	if c.checked || c.IsDefault {
+		return true
+	}
+	valueType := c.Switch.Value.Type
+	for i, v := range c.Values {
+		if !v.Check(p) {
+			return false
+		}
+		if !v.IsConst() {
+			p.Error.MissError("Switch Error", p.Lexer.Cursor, "case value must be constant")
+			return false
+		}
+		isBool := typeSys.CheckTypeType(valueType, "bool")
+		if isBool != typeSys.CheckTypeType(v.Type, "bool") || !typeSys.CheckTypeType(v.Type, "int", "uint", "bool") {
+			p.Error.MissError("Type Error", p.Lexer.Cursor, "case value "+v.Type.Type()+" mismatch switch value "+valueType.Type())
+			return false
+		}
+		// 与之前的分支及本分支之前的值比较
+		for _, other := range c.Switch.Cases {
+			if other == c {
+				if c.hasValue(v, c.Values[:i]) {
+					p.Error.MissError("Switch Error", p.Lexer.Cursor, "duplicate case "+caseValueString(v))
+				}
+				break
+			}
+			if c.hasValue(v, other.Values) {
+				p.Error.MissError("Switch Error", p.Lexer.Cursor, "duplicate case "+caseValueString(v))
+			}
+		}
+	}
+	c.checked = true
+	return true
+}
+
+func (c *CaseBlock) hasValue(v *Expression, values []*Expression) bool {
+	for _, other := range values {
+		if other.CaseValue() == v.CaseValue() {
+			return true
+		}
+	}
+	return false
+}
+
+// CaseValue 返回常量分支值的整数形式（bool 为 0/1）
+func (exp *Expression) CaseValue() int64 {
+	if typeSys.CheckTypeType(exp.Type, "bool") {
+		if exp.Bool {
+			return 1
+		}
+		return 0
+	}
+	return int64(exp.Num)
+}
+
+func caseValueString(v *Expression) string {
+	if typeSys.CheckTypeType(v.Type, "bool") {
+		return strconv.FormatBool(v.Bool)
+	}
+	return strconv.FormatInt(v.CaseValue(), 10)
+}
@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"cuteify/lexer"
 	typeSys "cuteify/type"
+	"strconv"
 )
 
 type TypeBlock struct {
@@ -9,6 +11,79 @@ type TypeBlock struct {
 	Name Name
 }
 
+// ParseType 解析类型标注，支持指针（*u8、**Point）、数组（[32]u8）和切片（[]int）及其组合
+// 返回类型名（用于报错）和类型，类型不存在时返回 nil
+func (p *Parser) ParseType() (Name, typeSys.Type) {
+	token := p.Lexer.Next()
+	if token.Type == lexer.SEPARATOR {
+		switch token.Value {
+		case "*":
+			name, elem := p.ParseType()
+			if elem == nil {
+				return name, nil
+			}
+			return name, typeSys.NewPointerType(elem)
+		case "[":
+			return p.parseArrayType()
+		}
+	}
+	p.Lexer.SetCursor(token.Cursor)
+	name, _ := p.Name(false)
+	node, t := p.FindType(name)
+	if t == nil {
+		return name, nil
+	}
+	if node != nil {
+		if sb, ok := node.Value.(*StructBlock); ok && sb.Generic != nil {
+			return name, p.instantiateType(name, sb)
+		}
+	}
+	return name, t
+}
+
+// instantiateType 解析泛型结构体名称之后的类型实参（如 Box[int]），返回对应实例的类型
+// 模板签名中含有类型参数的 Box[T] 此时无法实例化，以占位类型代替
+func (p *Parser) instantiateType(name Name, s *StructBlock) typeSys.Type {
+	if token := p.Lexer.Next(); token.Value != "[" {
+		p.Error.MissError("Type Error", p.Lexer.Cursor, "generic struct '"+name.String()+"' needs type arguments")
+	}
+	args := p.parseTypeArgs()
+	if len(args) != len(s.Generic.TypeParams) {
+		p.Error.MissError("Type Error", p.Lexer.Cursor, "wrong number of type arguments for '"+name.String()+"'")
+	}
+	for _, arg := range args {
+		if typeSys.HasTypeParam(arg) {
+			return typeSys.NewTypeParam(name.String() + "[" + typeArgsKey(args) + "]")
+		}
+	}
+	return s.Instantiate(args).Type()
+}
+
+// parseArrayType 解析已读入 '[' 之后的数组或切片类型，数组长度必须是正整数常量
+func (p *Parser) parseArrayType() (Name, typeSys.Type) {
+	length := -1
+	token := p.Lexer.Next()
+	if token.Type == lexer.NUMBER {
+		n, err := strconv.Atoi(token.Value)
+		if err != nil || n <= 0 {
+			p.Error.MissError("Type Error", p.Lexer.Cursor, "array length must be a positive integer constant")
+		}
+		length = n
+		token = p.Lexer.Next()
+	}
+	if token.Type != lexer.SEPARATOR || token.Value != "]" {
+		p.Error.MissError("Syntax Error", p.Lexer.Cursor, "need ]")
+	}
+	name, elem := p.ParseType()
+	if elem == nil {
+		return name, nil
+	}
+	if length == -1 {
+		return name, typeSys.NewSliceType(elem)
+	}
+	return name, typeSys.NewArrayType(elem, length)
+}
+
 // TODO: func (t *TypeBlock) Parse(p *Parser) {
 // TODO: 	tmp := &typeSys.RType{}
 // TODO: 	code := p.Lexer.Next()
@@ -19,6 +19,8 @@ type VarBlock struct {
 	Used          bool         // 变量是否已被使用（用于优化/警告）
 	StartCursor   int          // 变量名在源代码中的起始位置
 	Offset        int          // 变量在栈帧中的偏移量（编译时使用）
+	IsGlobal      bool         // 是否为包级全局变量（存放在数据段中）
+	Store         *Expression  // 通过指针或下标赋值时的目标（如 *p = 5 中的 *p、a[i] = 5 中的 a[i]）
 	Type          typeSys.Type // 变量的数据类型
 }
 
@@ -26,6 +28,7 @@ type VarBlock struct {
 // 这是入口函数，会调用ParseVar然后将自身添加到语法树
 func (v *VarBlock) Parse(p *Parser) {
 	v.ParseVar(p)
+	v.IsGlobal = v.IsDefine && p.ThisBlock.Father == nil
 	p.AddChild(&Node{Value: v})
 }
 
@@ -38,11 +41,22 @@ func (v *VarBlock) ParseVar(p *Parser) {
 
 	switch code.Type {
 	case lexer.NAME:
+		// 下标赋值，如 a[i] = 5
+		if p.isIndexStore(code) {
+			v.ParseStoreVar(p, code, p.FindEndCursor())
+			break
+		}
 		// 普通变量名形式
 		v.ParseNameVar(p, code, p.FindEndCursor())
 	case lexer.VAR:
 		// var关键字形式
 		v.ParseKeywordVar(p, code, p.FindEndCursor())
+	case lexer.SEPARATOR:
+		// 通过指针赋值
+		if code.Value != "*" {
+			p.Error.MissError("Syntax Error", p.Lexer.Cursor, "need name")
+		}
+		v.ParseStoreVar(p, code, p.FindEndCursor())
 	default:
 		// 语法错误：需要变量名
 		if p.Lexer.Cursor == 0 {
@@ -73,6 +87,7 @@ func (v *VarBlock) ParseNameVar(p *Parser, code lexer.Token, stopCursor int) {
 
 	// 解析变量引用（查找定义）
 	v.ParseDefine(p)
+	p.checkFieldAccess(v, true)
 
 	code = p.Lexer.Next()
 
@@ -84,14 +99,14 @@ func (v *VarBlock) ParseNameVar(p *Parser, code lexer.Token, stopCursor int) {
 	// 处理自增/自减操作 (x++ 或 x--)
 	if code.Value == "++" || code.Value == "--" {
 		valPart := &Expression{
-			Var: &VarBlock{Name: Name([]string{v.Name.First()})},
+			Var: &VarBlock{Name: v.Name.Fork()},
 		}
 
 		// 将 x++ 转换为表达式: x = x + 1
 		v.Value = &Expression{
 			Separator: code.Value[0 : len(code.Value)-1], // "+" 或 "-"
-			Left:      &Expression{Num: 1, Type: typeSys.GetSystemType("int")},
-			Right:     valPart,
+			Left:      valPart,
+			Right:     &Expression{Num: 1, Type: typeSys.GetSystemType("int")},
 		}
 
 		// 建立父子关系
@@ -123,8 +138,8 @@ func (v *VarBlock) ParseNameVar(p *Parser, code lexer.Token, stopCursor int) {
 
 		v.Value = &Expression{
 			Separator: code.Value[0 : len(code.Value)-1], // "+", "-", "*" 等
-			Left:      v.Value,
-			Right:     valPart,
+			Left:      valPart,
+			Right:     v.Value,
 		}
 
 		// 建立父子关系
@@ -138,6 +153,44 @@ func (v *VarBlock) ParseNameVar(p *Parser, code lexer.Token, stopCursor int) {
 	v.ParseDefine(p)
 }
 
+// isIndexStore 报告以 code 开头的语句是否为下标赋值（变量名后紧跟 '['），不移动游标
+func (p *Parser) isIndexStore(code lexer.Token) bool {
+	p.Lexer.SetCursor(code.Cursor)
+	p.Name(false)
+	token := p.Lexer.Next()
+	p.Lexer.SetCursor(code.EndCursor)
+	return token.Type == lexer.SEPARATOR && token.Value == "["
+}
+
+// ParseStoreVar 解析通过指针或下标赋值的语句，如 "*p = 5"、"*(p + 1) = x"、"a[i] = x"、"vs[i].x = y"
+func (v *VarBlock) ParseStoreVar(p *Parser, code lexer.Token, stopCursor int) {
+	v.StartCursor = code.Cursor
+
+	// 找到 '=' 之前最后一个 Token 的结束位置，作为左侧表达式的终点
+	targetEnd := -1
+	lastEnd := code.EndCursor
+	for p.Lexer.Cursor < stopCursor {
+		token := p.Lexer.Next()
+		if token.Type == lexer.SEPARATOR && token.Value == "=" {
+			targetEnd = lastEnd
+			break
+		}
+		lastEnd = token.EndCursor
+	}
+	if targetEnd == -1 {
+		p.Error.MissError("Syntax Error", p.Lexer.Cursor, "need '='")
+	}
+
+	// 左侧必须是解引用或下标表达式
+	p.Lexer.SetCursor(code.Cursor)
+	v.Store = p.ParseExp(targetEnd)
+	if v.Store.Unary != "*" && v.Store.Index == nil && v.Store.Field == nil || v.Store.Separator != "" {
+		p.Error.MissError("Syntax Error", p.Lexer.Cursor, "cannot assign to this expression")
+	}
+	p.Lexer.Skip('=')
+	v.Value = p.ParseExp(stopCursor)
+}
+
 // ParseKeywordVar 解析带关键字的变量声明
 // 支持的语法形式：
 //   - "var x int = 5"    : 带类型的变量定义
@@ -156,26 +209,12 @@ func (v *VarBlock) ParseKeywordVar(p *Parser, code lexer.Token, stopCursor int)
 	// 解析类型注解
 	code = p.Lexer.Next()
 	if code.Type == lexer.SEPARATOR && code.Value == ":" {
-		code = p.Lexer.Next()
-		if code.Type == lexer.NAME {
-			// 基本类型：如 "var x int"
-			_, tmpType := p.FindType(Name([]string{code.Value}))
-			v.Type = tmpType
-		} else if code.Type == lexer.SEPARATOR && code.Value == "*" {
-			// 指针类型：如 "var x *int"
-			code = p.Lexer.Next()
-			if code.Type == lexer.NAME {
-				_, tmpType := p.FindType(Name([]string{code.Value}))
-				if rType, ok := tmpType.(*typeSys.RType); ok {
-					rType.IsPtr = true // 标记为指针类型
-					v.Type = rType
-				}
-			} else {
-				p.Error.MissError("Syntax Error", p.Lexer.Cursor, "need type")
-			}
-		} else {
-			p.Error.MissError("Syntax Error", p.Lexer.Cursor, "need type")
+		// 类型：如 "var x int"、指针类型 "var x *int"
+		name, tmpType := p.ParseType()
+		if tmpType == nil {
+			p.Error.MissError("Type Error", p.Lexer.Cursor, "type '"+name.String()+"' not found")
 		}
+		v.Type = tmpType
 	} else {
 		p.Error.MissError("Syntax Error", p.Lexer.Cursor, "need type")
 	}
@@ -225,6 +264,11 @@ func (v *VarBlock) parseVarName(p *Parser, code lexer.Token) {
 	v.Name = name
 }
 
+// IsSelf 报告变量引用是否以方法 fn 的接收者开头
+func (v *VarBlock) IsSelf(fn *FuncBlock) bool {
+	return fn != nil && fn.Class != nil && len(v.Name) > 0 && v.Name.First() == "self"
+}
+
 // ParseDefine 解析变量引用，查找变量定义的位置
 // 这是变量解析的核心函数，会沿着作用域链向上查找
 func (v *VarBlock) ParseDefine(p *Parser) bool {
@@ -241,16 +285,25 @@ func (v *VarBlock) ParseDefine(p *Parser) bool {
 	if !v.IsDefine && v.Define == nil {
 		node, vb := p.FindVar(v.Name)
 		if vb != nil {
+			var defName Name
 			switch vbt := vb.(type) {
 			case *ArgBlock:
 				v.Type = vbt.Type
 				v.Offset = vbt.Offset
+				defName = vbt.Name
 			case *VarBlock:
 				v.Type = vbt.Type
 				v.Offset = vbt.Offset
+				defName = vbt.Name
 			}
 			v.Define = node
 			p.ThisBlock = oldThisBlock
+			// 字段访问（如 obj.a.b）：类型为路径最后一个字段的类型
+			if len(v.Name) > len(defName) && v.Name.First() == defName.First() {
+				if fields := p.findFields(v.Type, v.Name[len(defName):]); len(fields) > 0 {
+					v.Type = fields[len(fields)-1].Type
+				}
+			}
 			return true
 		}
 		p.ThisBlock = oldThisBlock
@@ -261,6 +314,20 @@ func (v *VarBlock) ParseDefine(p *Parser) bool {
 	return true
 }
 
+// baseType 返回变量定义处的类型（字段访问时为结构体本身的类型）
+func (v *VarBlock) baseType() typeSys.Type {
+	if v.Define == nil {
+		return nil
+	}
+	switch def := v.Define.Value.(type) {
+	case *VarBlock:
+		return def.Type
+	case *ArgBlock:
+		return def.Type
+	}
+	return nil
+}
+
 // removeOldStaticVal 移除旧的常量值
 // 当执行 "x = 5" 赋值时，如果之前x有常量值，需要先移除旧值
 // 这是为了确保常量折叠等优化能正确处理
@@ -285,7 +352,7 @@ func (v *VarBlock) removeOldStaticVal(p *Parser) {
 				continue
 			}
 			switch p.ThisBlock.Children[i].Value.(type) {
-			case *FuncBlock, *ForBlock, *ElseBlock, *IfBlock:
+			case *FuncBlock, *ForBlock, *WhileBlock, *SwitchBlock, *CaseBlock, *ElseBlock, *IfBlock:
 				// 遇到控制流块，停止搜索
 				goto end
 			case *VarBlock:
@@ -309,6 +376,9 @@ end:
 // Check 类型检查和验证函数
 // 确保变量定义和使用时的类型一致性
 func (v *VarBlock) Check(p *Parser) bool {
+	if v.Store != nil {
+		return v.checkStore(p)
+	}
 	if v.IsDefine {
 		// 定义分支：var x int = 5
 		if v.Value != nil {
@@ -323,8 +393,12 @@ func (v *VarBlock) Check(p *Parser) bool {
 				v.Type = v.Value.Type
 			}
 			// 检查类型兼容性
-			if !typeSys.AutoType(v.Value.Type, v.Type, true) {
-				p.Error.MissError("Type Error", p.Lexer.Cursor, "need type "+v.Type.Type()+", not "+v.Value.Type.Type())
+			if !p.assignable(v.Value, v.Type, v.Value.IsConst()) {
+				p.assignError(v.Value, v.Type, "need type "+v.Type.Type()+", not "+v.Value.Type.Type())
+			}
+			// 全局变量的初始值直接写入数据段，必须是常量
+			if v.IsGlobal && !v.Value.IsConst() {
+				p.Error.MissError("Syntax Error", p.Lexer.Cursor, "initializer of global variable '"+v.Name.String()+"' must be constant")
 			}
 		}
 	} else {
@@ -347,10 +421,22 @@ func (v *VarBlock) Check(p *Parser) bool {
 		}
 		// 检查赋值类型兼容性
 		if v.Type != nil && v.Value.Type != nil {
-			if !typeSys.AutoType(v.Value.Type, v.Type, v.Value.IsConst()) {
-				p.Error.MissError("Type Error", p.Lexer.Cursor, "need type "+v.Type.Type()+", not "+v.Value.Type.Type())
+			if !p.assignable(v.Value, v.Type, v.Value.IsConst()) {
+				p.assignError(v.Value, v.Type, "need type "+v.Type.Type()+", not "+v.Value.Type.Type())
 			}
 		}
 	}
 	return true
 }
+
+// checkStore 检查通过指针或下标赋值的语句，目标类型为元素类型
+func (v *VarBlock) checkStore(p *Parser) bool {
+	if !v.Store.Check(p) || !v.Value.Check(p) {
+		return false
+	}
+	v.Type = v.Store.Type
+	if !p.assignable(v.Value, v.Type, v.Value.IsConst()) {
+		p.assignError(v.Value, v.Type, "need type "+v.Type.Type()+", not "+v.Value.Type.Type())
+	}
+	return true
+}
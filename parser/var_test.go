@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// checkError 解析并检查源代码，返回检查阶段报告的错误（含位置）
+func checkError(t *testing.T, src string) (msg string) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() {
+		os.Stdout = stdout
+		w.Close()
+		out, _ := io.ReadAll(r)
+		msg = string(out)
+		if recover() == nil {
+			t.Error("没有报错")
+		}
+	}()
+	parseSource(t, src).Check()
+	return ""
+}
+
+// TestNarrowing 隐式缩窄在值表达式处报错，并提示使用 as 转换
+func TestNarrowing(t *testing.T) {
+	tests := []struct {
+		name, src, pos string
+	}{
+		{"define", "fn main() int {\n    var x: i32 = 300\n    var b: u8 = x + 1\n    ret 0\n}\n", "main.cute:3:16:"},
+		{"assign", "fn main() int {\n    var x: i32 = 300\n    var b: u8 = 1\n    b = x\n    ret 0\n}\n", "main.cute:4:8:"},
+		{"argument", "fn take(b: u8) int {\n    ret 0\n}\n\nfn main() int {\n    var x: i32 = 300\n    ret take(x)\n}\n", "main.cute:7:13:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := checkError(t, tt.src)
+			if !strings.Contains(msg, "(use 'as' to convert)") {
+				t.Errorf("没有提示使用 as:\n%s", msg)
+			}
+			if !strings.Contains(msg, tt.pos) {
+				t.Errorf("报错位置不是值表达式 %s:\n%s", tt.pos, msg)
+			}
+		})
+	}
+}
+
+// TestArithPosition 二元运算的类型错误在操作数处报错，而不是语句末尾
+func TestArithPosition(t *testing.T) {
+	tests := []struct {
+		name, src, want, pos string
+	}{
+		{"mismatch", "fn main() int {\n    var a: u64 = 1\n    var b: i32 = 2\n    if (a + b > 0) {\n        ret 1\n    }\n    ret 0\n}\n", "mismatched types u64 and i32", "main.cute:4:8:"},
+		{"overflow", "fn main() int {\n    var b: u8 = 1\n    var c: u8 = 0\n    c = b + 300\n    ret 0\n}\n", "constant 300 overflows u8", "main.cute:4:12:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := checkError(t, tt.src)
+			if !strings.Contains(msg, tt.want) {
+				t.Errorf("没有报告 %q:\n%s", tt.want, msg)
+			}
+			if !strings.Contains(msg, tt.pos) {
+				t.Errorf("报错位置不是操作数 %s:\n%s", tt.pos, msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"cuteify/lexer"
+	typeSys "cuteify/type"
+)
+
+// WhileBlock while 循环结构体
+type WhileBlock struct {
+	Condition *Expression // 循环条件
+	Offset    int         // 循环编号（用于生成唯一标签）
+}
+
+// Parse 解析 while 循环
+// 语法格式: while (condition) { ... }
+func (w *WhileBlock) Parse(p *Parser) {
+	oldCursor := p.Lexer.Cursor
+	stopCursor := p.findBlockStart()
+	p.Lexer.SetCursor(oldCursor)
+	w.Condition = p.ParseExp(stopCursor)
+	p.Wait("{")
+
+	// 创建WhileBlock节点并进入循环体作用域
+	whileNode := &Node{Value: w}
+	p.ThisBlock.AddChild(whileNode)
+	p.ThisBlock = whileNode
+}
+
+// Check 检查 while 循环的有效性
+func (w *WhileBlock) Check(p *Parser) bool {
+	if w.Condition == nil {
+		p.Error.MissError("While Loop Error", p.Lexer.Cursor, "while loop need condition")
+		return false
+	}
+	if !w.Condition.Check(p) {
+		return false
+	}
+	// 条件必须是布尔类型
+	if !typeSys.CheckType(w.Condition.Type, typeSys.GetSystemType("bool")) {
+		p.Error.MissError("While Loop Error", p.Lexer.Cursor, "while loop condition must be boolean")
+		return false
+	}
+	return true
+}
+
+// findBlockStart 查找当前行中括号外的 '{'，返回其之前最后一个 Token 的结束位置
+// 调用方需自行恢复光标
+func (p *Parser) findBlockStart() int {
+	bracketsCount := 0
+	stopCursor := p.Lexer.Cursor
+	for p.FindEndCursor() > p.Lexer.Cursor {
+		code := p.Lexer.Next()
+		if code.Type == lexer.SEPARATOR {
+			switch code.Value {
+			case "(":
+				bracketsCount++
+			case ")":
+				bracketsCount--
+			}
+			if bracketsCount == 0 && code.Value == "{" {
+				break
+			}
+		}
+		stopCursor = code.EndCursor
+	}
+	return stopCursor
+}
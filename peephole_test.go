@@ -0,0 +1,24 @@
+package main
+
+import (
+	"cuteify/compile"
+	"testing"
+)
+
+// TestPeephole 分别经语法树与 IR 编译 x86Cases 中的程序，做窥孔优化后在模拟器中运行，退出码与标准错误输出不变，
+// 且执行的指令数不多于不做优化时；删除的指令在 -v 时输出
+func TestPeephole(t *testing.T) {
+	eachX86Case(t, func(t *testing.T, c x86Case) {
+		for _, useIR := range []bool{false, true} {
+			co := &compile.Compiler{Legacy: !useIR}
+			_, m := runX86(t, c, co)
+			if co.Peephole == nil {
+				t.Fatal("没有做窥孔优化")
+			}
+			if _, plain := runX86(t, c, &compile.Compiler{Legacy: !useIR, NoPeephole: true}); m.Steps > plain.Steps {
+				t.Errorf("IR %v: 执行了 %d 条指令，不做优化时为 %d 条", useIR, m.Steps, plain.Steps)
+			}
+			t.Logf("IR %v: %v", useIR, co.Peephole)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"cuteify/compile"
+	"cuteify/compile/context"
+	"strings"
+	"testing"
+)
+
+// TestGraphAlloc 用图着色寄存器分配（-regalloc graph）编译 x86Cases 中的程序并在模拟器中运行，退出码与直接从语法树生成时相同，
+// 且执行的指令数不多于经 IR 生成、每个值放在栈帧中时（都不展开函数）；各方式的静态与执行的指令数在 -v 时输出以便比较
+func TestGraphAlloc(t *testing.T) {
+	eachX86Case(t, func(t *testing.T, c x86Case) {
+		code, m := runX86(t, c, &compile.Compiler{RegAlloc: context.GraphAlloc, NoInline: true})
+		stackCode, stack := runX86(t, c, &compile.Compiler{NoInline: true})
+		regmgrCode, regmgr := runX86(t, c, &compile.Compiler{Legacy: true, NoInline: true})
+		if m.Steps > stack.Steps {
+			t.Errorf("执行了 %d 条指令，每个值放在栈帧中时为 %d 条", m.Steps, stack.Steps)
+		}
+		t.Logf("指令数（静态/执行）：graph %d/%d，IR 栈帧 %d/%d，regmgr %d/%d",
+			countInsts(code), m.Steps, countInsts(stackCode), stack.Steps, countInsts(regmgrCode), regmgr.Steps)
+	})
+}
+
+// countInsts 统计汇编代码中的指令数，不含标签、注释、伪指令与数据段
+func countInsts(code string) (n int) {
+	for _, line := range strings.Split(code, "\n") {
+		line = strings.TrimSpace(line)
+		if i := strings.Index(line, ";"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		switch {
+		case strings.HasPrefix(line, "section .data"), strings.HasPrefix(line, "section .bss"):
+			return
+		case line == "", strings.HasSuffix(line, ":"), strings.HasPrefix(line, "section"),
+			strings.HasPrefix(line, "global"), strings.HasPrefix(line, "extern"):
+			continue
+		}
+		n++
+	}
+	return
+}
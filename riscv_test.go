@@ -0,0 +1,145 @@
+package main
+
+import (
+	"cuteify/compile"
+	packageSys "cuteify/package"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"errors"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "用当前的输出更新 testdata 下的黄金文件")
+
+// riscvCases 用 RISC-V 后端编译的测试程序及其在 rv64、rv32 下的退出码，与汇编后端一致；
+// 退出码为 -1 的程序不在该目标下编译：loop_test 按 4 字节 int 编写，在 64 位字长下不能通过类型检查
+var riscvCases = []struct {
+	name       string
+	rv64, rv32 int
+}{
+	{"loop_test", -1, 21},
+	{"switch_test", 49, 49},
+	{"struct_layout", 26, 26},
+	{"method_test", 20, 20},
+	{"simple_method", 42, 42},
+	{"interface_test", 31, 31},
+	{"global_test", 5, 5},
+	{"pointer_test", 52, 52},
+	{"array_test", 49, 49},
+	{"cast_test", 95, 95},
+	{"generic_test", 27, 27},
+	{"callconv_test", 36, 36},
+	{"fastcall_test", 87, 87},
+	{"struct_test", 0, 0},
+	{"struct_method", 0, 0},
+	{"link_test", 0, 0},
+}
+
+// riscvGolden 与 testdata/riscv 下的黄金文件比较输出的程序
+var riscvGolden = map[string]bool{
+	"switch_test":    true,
+	"interface_test": true,
+	"array_test":     true,
+	"cast_test":      true,
+	"global_test":    true,
+}
+
+// riscvTarget RISC-V 目标及汇编、链接、运行所用的工具参数
+type riscvTarget struct {
+	arch   string
+	triple string // llvm-mc 的目标三元组
+	march  string // GNU as 的 -march
+	mabi   string
+	emul   string // GNU ld 的 -m
+	qemu   string
+}
+
+var riscvTargets = []riscvTarget{
+	{"rv64", "riscv64", "rv64im", "lp64", "elf64lriscv", "qemu-riscv64"},
+	{"rv32", "riscv32", "rv32im", "ilp32", "elf32lriscv", "qemu-riscv32"},
+}
+
+// TestRISCV 用 RISC-V 后端编译 test/ 下的程序：部分程序的输出与黄金文件比较（go test -run TestRISCV -update 更新），
+// llvm-mc 可用时检查汇编能否通过，qemu-user 与 riscv64-linux-gnu 的 as、ld 都可用时链接运行并检查退出码
+func TestRISCV(t *testing.T) {
+	llvmMC, _ := exec.LookPath("llvm-mc")
+	as, _ := exec.LookPath("riscv64-linux-gnu-as")
+	ld, _ := exec.LookPath("riscv64-linux-gnu-ld")
+
+	arch, ptrSize := compile.GoArch, typeSys.PtrSize
+	defer func() { compile.GoArch, typeSys.PtrSize = arch, ptrSize }()
+
+	for _, target := range riscvTargets {
+		qemu, _ := exec.LookPath(target.qemu)
+		compile.GoArch, typeSys.PtrSize = target.arch, compile.WordSize(target.arch)
+		for _, c := range riscvCases {
+			exit := c.rv64
+			if target.arch == "rv32" {
+				exit = c.rv32
+			}
+			if exit < 0 {
+				continue
+			}
+			t.Run(target.arch+"/"+c.name, func(t *testing.T) {
+				tmp, err := packageSys.GetPackage("./test/"+c.name, true)
+				if err != nil {
+					t.Fatal(err)
+				}
+				co := &compile.Compiler{}
+				code := co.Compile(tmp.AST.(*parser.Node))
+
+				if riscvGolden[c.name] {
+					golden := filepath.Join("testdata", "riscv", c.name+"."+target.arch+".s")
+					if *updateGolden {
+						if err := os.WriteFile(golden, []byte(code), 0644); err != nil {
+							t.Fatal(err)
+						}
+					}
+					want, err := os.ReadFile(golden)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if code != string(want) {
+						t.Errorf("输出与 %s 不一致", golden)
+					}
+				}
+
+				dir := t.TempDir()
+				src, obj, bin := filepath.Join(dir, "main.s"), filepath.Join(dir, "main.o"), filepath.Join(dir, "main")
+				if err := os.WriteFile(src, []byte(code), 0644); err != nil {
+					t.Fatal(err)
+				}
+				if llvmMC != "" {
+					out, err := exec.Command(llvmMC, "-triple="+target.triple, "-mattr=+m", "-filetype=obj", src, "-o", obj).CombinedOutput()
+					if err != nil {
+						t.Fatalf("llvm-mc: %v\n%s", err, out)
+					}
+				}
+				if qemu == "" || as == "" || ld == "" {
+					return
+				}
+				if out, err := exec.Command(as, "-march="+target.march, "-mabi="+target.mabi, src, "-o", obj).CombinedOutput(); err != nil {
+					t.Fatalf("as: %v\n%s", err, out)
+				}
+				if out, err := exec.Command(ld, "-m", target.emul, obj, "-o", bin).CombinedOutput(); err != nil {
+					t.Fatalf("ld: %v\n%s", err, out)
+				}
+				got := 0
+				if err := exec.Command(qemu, bin).Run(); err != nil {
+					var exitErr *exec.ExitError
+					if !errors.As(err, &exitErr) {
+						t.Fatal(err)
+					}
+					got = exitErr.ExitCode()
+				}
+				if got != exit {
+					t.Errorf("退出码为 %d，应为 %d", got, exit)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,172 @@
+section .text
+global _start
+
+; ==============================
+; Function: sum1
+sum1:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    sub esp, 8; 分配栈空间(8字节)
+    mov DWORD[ebp-4], 0
+    mov DWORD[ebp-8], 0
+    sum1.b1:
+    mov EAX, DWORD[ebp-4]
+    cmp EAX, DWORD[ebp+12]
+    jge sum1.b3
+    sum1.b2:
+    mov EAX, DWORD[ebp-4]
+    imul EAX, EAX, 4
+    add EAX, DWORD[ebp+8]
+    mov EAX, DWORD[EAX]
+    add EAX, DWORD[ebp-8]
+    mov DWORD[ebp-8], EAX
+    mov EAX, DWORD[ebp-4]
+    add EAX, 1
+    mov DWORD[ebp-4], EAX
+    jmp sum1.b1
+    sum1.b3:
+    mov EAX, DWORD[ebp-8]
+    leave
+    ret
+; ======函数完毕=======
+
+
+; ==============================
+; Function: fill2
+fill2:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    sub esp, 4; 分配栈空间(4字节)
+    mov DWORD[ebp-4], 0
+    fill2.b1:
+    mov EAX, DWORD[ebp-4]
+    cmp EAX, DWORD[ebp+12]
+    jge fill2.b3
+    fill2.b2:
+    mov EAX, DWORD[ebp-4]
+    add EAX, DWORD[ebp+8]
+    mov EDX, DWORD[ebp+16]
+    mov BYTE[EAX], DL
+    mov EAX, DWORD[ebp-4]
+    add EAX, 1
+    mov DWORD[ebp-4], EAX
+    jmp fill2.b1
+    fill2.b3:
+    leave
+    ret
+; ======函数完毕=======
+
+
+; ==============================
+; Function: main
+main:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    sub esp, 88; 分配栈空间(88字节)
+    mov DWORD[ebp-28], 0
+    mov DWORD[ebp-24], 0
+    mov DWORD[ebp-20], 0
+    mov DWORD[ebp-16], 0
+    mov DWORD[ebp-12], 0
+    mov DWORD[ebp-4], 0
+    main.b1:
+    cmp DWORD[ebp-4], 5
+    jge main.b3
+    main.b2:
+    mov EAX, DWORD[ebp-4]
+    imul EAX, EAX, 2
+    mov DWORD[ebp-8], EAX
+    mov EAX, DWORD[ebp-4]
+    imul EAX, EAX, 4
+    lea ECX, [ebp-28]
+    add EAX, ECX
+    mov EDX, DWORD[ebp-8]
+    mov DWORD[EAX], EDX
+    mov EAX, DWORD[ebp-4]
+    add EAX, 1
+    mov DWORD[ebp-4], EAX
+    jmp main.b1
+    main.b3:
+    mov WORD[ebp-31], 0
+    mov BYTE[ebp-29], 0
+    lea EDX, [ebp-31]
+    mov DWORD[ebp-80], EDX
+    mov DWORD[ebp-76], 3
+    push 4
+    push DWORD[ebp-76]
+    push DWORD[ebp-80]
+    call fill2
+    add esp, 12; 清理参数
+    mov DWORD[ebp-56], 0
+    mov DWORD[ebp-52], 0
+    mov DWORD[ebp-48], 0
+    mov DWORD[ebp-44], 0
+    mov DWORD[ebp-40], 0
+    mov DWORD[ebp-36], 9
+    mov EAX, DWORD[ebp-36]
+    add EAX, 1
+    mov DWORD[ebp-52], EAX
+    mov DWORD[ebp-64], 0
+    mov DWORD[ebp-60], 0
+    mov WORD[ebp-60], 2
+    movsx EAX, WORD[ebp-60]
+    add EAX, 1
+    movsx EAX, AX
+    mov WORD[ebp-58], AX
+    movsx EAX, WORD[ebp-58]
+    imul EAX, EAX, 2
+    movsx EAX, AX
+    mov WORD[ebp-58], AX
+    mov DWORD[g_Table+12], 6
+    lea EDX, [ebp-28]
+    mov DWORD[ebp-72], EDX
+    mov DWORD[ebp-68], 5
+    mov ECX, DWORD[ebp-72]
+    mov DWORD[ECX], 1
+    lea EAX, [ebp-60]
+    cmp EAX, 0
+    jne main.b5
+    main.b4:
+    mov EAX, 1
+    leave
+    ret
+    main.b5:
+    push DWORD[ebp-68]
+    push DWORD[ebp-72]
+    call sum1
+    add esp, 8; 清理参数
+    mov DWORD[ebp-8], EAX
+    mov DWORD[ebp-88], g_Table
+    mov DWORD[ebp-84], 4
+    push DWORD[ebp-84]
+    push DWORD[ebp-88]
+    call sum1
+    add esp, 8; 清理参数
+    add EAX, DWORD[ebp-8]
+    mov DWORD[ebp-8], EAX
+    movzx EAX, BYTE[ebp-29]
+    add EAX, DWORD[ebp-8]
+    add EAX, DWORD[ebp-52]
+    add EAX, 2
+    mov DWORD[ebp-8], EAX
+    movsx EAX, WORD[ebp-58]
+    add EAX, DWORD[ebp-8]
+    leave
+    ret
+; ======函数完毕=======
+
+
+; ==============================
+; 程序入口点 (ELF入口)
+_start:
+    ; 调用main函数
+    call main
+    ; 使用系统调用退出程序 (sys_exit = 1)
+    ; 返回值在EAX中
+    mov ebx, eax; 返回码
+    mov eax, 1; sys_exit
+    int 0x80; 调用内核
+
+    section .bss
+    alignb 4
+    g_Table: resb 16
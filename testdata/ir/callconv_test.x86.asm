@@ -0,0 +1,126 @@
+section .text
+global _start
+
+; ==============================
+; Function: Counter_add1
+Counter_add1:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    mov EAX, DWORD[ebp+8]
+    mov EAX, DWORD[EAX]
+    add EAX, DWORD[ebp+12]
+    mov ECX, DWORD[ebp+8]
+    mov DWORD[ECX], EAX
+    leave
+    ret 8
+; ======函数完毕=======
+
+
+; ==============================
+; Function: fast3
+fast3:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    sub esp, 12; 分配栈空间(12字节)
+    mov DWORD[ebp-4], ECX; 保存参数a
+    mov DWORD[ebp-8], EDX; 保存参数b
+    mov EAX, DWORD[ebp-4]
+    imul EAX, EAX, 100
+    mov DWORD[ebp-12], EAX
+    mov EAX, DWORD[ebp-8]
+    imul EAX, EAX, 10
+    add EAX, DWORD[ebp-12]
+    add EAX, DWORD[ebp+8]
+    leave
+    ret 4
+; ======函数完毕=======
+
+
+; ==============================
+; Function: std2
+std2:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    push 3
+    mov ECX, DWORD[ebp+8]
+    mov EDX, DWORD[ebp+12]
+    call fast3
+    sub EAX, 100
+    leave
+    ret 8
+; ======函数完毕=======
+
+
+; ==============================
+; Function: plain2
+plain2:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    push DWORD[ebp+12]
+    push DWORD[ebp+8]
+    call std2
+    add EAX, DWORD[ebp+8]
+    leave
+    ret
+; ======函数完毕=======
+
+
+; ==============================
+; Function: cfunc1
+cfunc1:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    sub esp, 4; 分配栈空间(4字节)
+    push 2
+    mov ECX, DWORD[ebp+8]
+    mov EDX, 1
+    call fast3
+    mov DWORD[ebp-4], EAX
+    mov EAX, DWORD[ebp+8]
+    imul EAX, EAX, 99
+    mov ECX, EAX
+    mov EAX, DWORD[ebp-4]
+    sub EAX, ECX
+    sub EAX, 12
+    leave
+    ret
+; ======函数完毕=======
+
+
+; ==============================
+; Function: main
+main:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    sub esp, 8; 分配栈空间(8字节)
+    mov DWORD[ebp-8], 1
+    push 4
+    lea EAX, [ebp-8]
+    push EAX
+    call Counter_add1
+    push 2
+    push 1
+    call plain2
+    add esp, 8; 清理参数
+    add EAX, DWORD[ebp-8]
+    mov DWORD[ebp-4], EAX
+    push 7
+    call cfunc1
+    add esp, 4; 清理参数
+    add EAX, DWORD[ebp-4]
+    leave
+    ret
+; ======函数完毕=======
+
+
+; ==============================
+; 程序入口点 (ELF入口)
+_start:
+    ; 调用main函数
+    call main
+    ; 使用系统调用退出程序 (sys_exit = 1)
+    ; 返回值在EAX中
+    mov ebx, eax; 返回码
+    mov eax, 1; sys_exit
+    int 0x80; 调用内核
+
@@ -0,0 +1,170 @@
+section .text
+global _start
+
+; ==============================
+; Function: Rect_Area0
+Rect_Area0:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    sub esp, 4; 分配栈空间(4字节)
+    mov EAX, DWORD[ebp+8]
+    mov EAX, DWORD[EAX]
+    mov DWORD[ebp-4], EAX
+    mov EAX, DWORD[ebp+8]
+    mov EAX, DWORD[EAX+4]
+    imul EAX, DWORD[ebp-4]
+    leave
+    ret
+; ======函数完毕=======
+
+
+; ==============================
+; Function: Rect_Grow1
+Rect_Grow1:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    mov EAX, DWORD[ebp+8]
+    mov EAX, DWORD[EAX]
+    add EAX, DWORD[ebp+12]
+    mov ECX, DWORD[ebp+8]
+    mov DWORD[ECX], EAX
+    mov EAX, DWORD[ebp+8]
+    mov EAX, DWORD[EAX+4]
+    add EAX, DWORD[ebp+12]
+    mov ECX, DWORD[ebp+8]
+    mov DWORD[ECX+4], EAX
+    leave
+    ret
+; ======函数完毕=======
+
+
+; ==============================
+; Function: Square_Area0
+Square_Area0:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    sub esp, 4; 分配栈空间(4字节)
+    mov EAX, DWORD[ebp+8]
+    mov EAX, DWORD[EAX]
+    mov DWORD[ebp-4], EAX
+    mov EAX, DWORD[ebp+8]
+    mov EAX, DWORD[EAX]
+    imul EAX, DWORD[ebp-4]
+    leave
+    ret
+; ======函数完毕=======
+
+
+; ==============================
+; Function: Square_Grow1
+Square_Grow1:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    mov EAX, DWORD[ebp+8]
+    mov EAX, DWORD[EAX]
+    add EAX, DWORD[ebp+12]
+    mov ECX, DWORD[ebp+8]
+    mov DWORD[ECX], EAX
+    leave
+    ret
+; ======函数完毕=======
+
+
+; ==============================
+; Function: Measure1
+Measure1:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    sub esp, 4; 分配栈空间(4字节)
+    mov EAX, DWORD[ebp+12]
+    mov EAX, DWORD[EAX+4]
+    mov DWORD[ebp-4], EAX
+    push 1
+    push DWORD[ebp+8]
+    call DWORD[ebp-4]; 调用Shape_Grow
+    add esp, 8; 清理参数
+    mov EAX, DWORD[ebp+12]
+    mov EAX, DWORD[EAX]
+    mov DWORD[ebp-4], EAX
+    push DWORD[ebp+8]
+    call DWORD[ebp-4]; 调用Shape_Area
+    add esp, 4; 清理参数
+    leave
+    ret
+; ======函数完毕=======
+
+
+; ==============================
+; Function: main
+main:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    sub esp, 48; 分配栈空间(48字节)
+    mov DWORD[ebp-20], 2
+    mov DWORD[ebp-16], 3
+    mov DWORD[ebp-24], 2
+    lea EDX, [ebp-20]
+    mov DWORD[ebp-32], EDX
+    mov DWORD[ebp-28], vtable_Rect_Shape
+    mov EAX, DWORD[ebp-28]
+    mov EAX, DWORD[EAX]
+    mov DWORD[ebp-4], EAX
+    push DWORD[ebp-32]
+    call DWORD[ebp-4]; 调用Shape_Area
+    add esp, 4; 清理参数
+    mov DWORD[ebp-8], EAX
+    lea EDX, [ebp-24]
+    mov DWORD[ebp-32], EDX
+    mov DWORD[ebp-28], vtable_Square_Shape
+    mov EAX, DWORD[ebp-28]
+    mov EAX, DWORD[EAX]
+    mov DWORD[ebp-4], EAX
+    push DWORD[ebp-32]
+    call DWORD[ebp-4]; 调用Shape_Area
+    add esp, 4; 清理参数
+    mov DWORD[ebp-12], EAX
+    lea EDX, [ebp-20]
+    mov DWORD[ebp-40], EDX
+    mov DWORD[ebp-36], vtable_Rect_Shape
+    push DWORD[ebp-36]
+    push DWORD[ebp-40]
+    call Measure1
+    add esp, 8; 清理参数
+    mov DWORD[ebp-4], EAX
+    mov EAX, DWORD[ebp-8]
+    add EAX, DWORD[ebp-12]
+    add EAX, DWORD[ebp-4]
+    mov DWORD[ebp-4], EAX
+    lea EDX, [ebp-24]
+    mov DWORD[ebp-48], EDX
+    mov DWORD[ebp-44], vtable_Square_Shape
+    push DWORD[ebp-44]
+    push DWORD[ebp-48]
+    call Measure1
+    add esp, 8; 清理参数
+    add EAX, DWORD[ebp-4]
+    leave
+    ret
+; ======函数完毕=======
+
+
+; ==============================
+; 程序入口点 (ELF入口)
+_start:
+    ; 调用main函数
+    call main
+    ; 使用系统调用退出程序 (sys_exit = 1)
+    ; 返回值在EAX中
+    mov ebx, eax; 返回码
+    mov eax, 1; sys_exit
+    int 0x80; 调用内核
+
+    section .rodata
+    align 4
+    vtable_Rect_Shape:
+    dd Rect_Area0
+    dd Rect_Grow1
+    align 4
+    vtable_Square_Shape:
+    dd Square_Area0
+    dd Square_Grow1
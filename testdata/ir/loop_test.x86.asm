@@ -0,0 +1,57 @@
+section .text
+global _start
+
+; ==============================
+; Function: main
+main:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    sub esp, 12; 分配栈空间(12字节)
+    mov DWORD[ebp-4], 0
+    mov DWORD[ebp-8], 0
+    main.b1:
+    cmp DWORD[ebp-4], 10
+    jge main.b14
+    main.b2:
+    mov EAX, DWORD[ebp-4]
+    add EAX, 1
+    mov DWORD[ebp-4], EAX
+    cmp EAX, 3
+    je main.b1
+    main.b4:
+    mov DWORD[ebp-12], 0
+    main.b5:
+    cmp DWORD[ebp-12], 5
+    jge main.b10
+    main.b6:
+    cmp DWORD[ebp-12], 2
+    jg main.b10
+    main.b8:
+    mov EAX, DWORD[ebp-8]
+    add EAX, DWORD[ebp-12]
+    mov DWORD[ebp-8], EAX
+    mov EAX, DWORD[ebp-12]
+    add EAX, 1
+    mov DWORD[ebp-12], EAX
+    jmp main.b5
+    main.b10:
+    cmp DWORD[ebp-4], 7
+    jle main.b1
+    main.b14:
+    mov EAX, DWORD[ebp-8]
+    leave
+    ret
+; ======函数完毕=======
+
+
+; ==============================
+; 程序入口点 (ELF入口)
+_start:
+    ; 调用main函数
+    call main
+    ; 使用系统调用退出程序 (sys_exit = 1)
+    ; 返回值在EAX中
+    mov ebx, eax; 返回码
+    mov eax, 1; sys_exit
+    int 0x80; 调用内核
+
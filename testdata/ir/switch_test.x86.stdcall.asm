@@ -0,0 +1,174 @@
+section .text
+global _start
+
+; ==============================
+; Function: classify1
+classify1:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    mov EAX, DWORD[ebp+8]
+    sub EAX, -3; 减去最小分支值
+    cmp EAX, 8; 跳转表边界检查
+    ja classify1.b10; 超出范围
+    jmp [classify1.b0_table+EAX*4]; 跳转表分派
+    section .rodata
+    align 4
+    classify1.b0_table:
+    dd classify1.b9
+    dd classify1.b10
+    dd classify1.b9
+    dd classify1.b1
+    dd classify1.b4
+    dd classify1.b4
+    dd classify1.b5
+    dd classify1.b10
+    dd classify1.b6
+    section .text
+    classify1.b1:
+    mov EAX, 10
+    leave
+    ret 4
+    classify1.b4:
+    mov EAX, 20
+    leave
+    ret 4
+    classify1.b5:
+    mov EAX, 30
+    leave
+    ret 4
+    classify1.b6:
+    mov EAX, 50
+    leave
+    ret 4
+    classify1.b9:
+    mov EAX, 7
+    leave
+    ret 4
+    classify1.b10:
+    mov EAX, 0
+    leave
+    ret 4
+; ======函数完毕=======
+
+
+; ==============================
+; Function: main
+main:
+    push ebp; 保存调用者的栈帧基址
+    mov ebp, esp; 设置当前栈帧基址
+    sub esp, 8; 分配栈空间(8字节)
+    mov DWORD[ebp-4], 0
+    mov DWORD[ebp-8], 0
+    main.b1:
+    cmp DWORD[ebp-4], 8
+    jge main.b11
+    main.b2:
+    mov EAX, DWORD[ebp-4]
+    add EAX, 1
+    mov DWORD[ebp-4], EAX
+    cmp EAX, 1
+    je main.b3
+    cmp EAX, 6
+    je main.b1
+    cmp EAX, 7
+    je main.b1
+    cmp EAX, 100
+    je main.b6
+    cmp EAX, 200
+    je main.b6
+    jmp main.b9; 没有匹配的分支
+    main.b3:
+    push DWORD[ebp-4]
+    call classify1
+    add EAX, DWORD[ebp-8]
+    mov DWORD[ebp-8], EAX
+    jmp main.b1
+    main.b6:
+    mov EAX, DWORD[ebp-8]
+    add EAX, 1
+    mov DWORD[ebp-8], EAX
+    jmp main.b1
+    main.b9:
+    mov EAX, DWORD[ebp-8]
+    add EAX, 2
+    mov DWORD[ebp-8], EAX
+    jmp main.b1
+    main.b11:
+    mov EAX, 98
+    cmp EAX, 10
+    je main.b15
+    cmp EAX, 97
+    je main.b12
+    cmp EAX, 98
+    je main.b15
+    jmp main.b17; 没有匹配的分支
+    main.b12:
+    mov EAX, DWORD[ebp-8]
+    add EAX, 1
+    mov DWORD[ebp-8], EAX
+    jmp main.b17
+    main.b15:
+    mov EAX, DWORD[ebp-8]
+    add EAX, 3
+    mov DWORD[ebp-8], EAX
+    main.b17:
+    movzx EAX, BYTE[g_Flags]
+    cmp EAX, 0
+    je main.b19
+    cmp EAX, 1
+    je main.b18
+    jmp main.b21; 没有匹配的分支
+    main.b18:
+    mov EAX, DWORD[ebp-8]
+    add EAX, 100
+    mov DWORD[ebp-8], EAX
+    jmp main.b21
+    main.b19:
+    mov EAX, DWORD[ebp-8]
+    add EAX, 4
+    mov DWORD[ebp-8], EAX
+    main.b21:
+    push -1
+    call classify1
+    add EAX, DWORD[ebp-8]
+    mov DWORD[ebp-8], EAX
+    movzx EAX, BYTE[g_On]
+    cmp EAX, 0
+    je main.b23
+    cmp EAX, 1
+    je main.b22
+    jmp main.b25; 没有匹配的分支
+    main.b22:
+    mov EAX, DWORD[ebp-8]
+    add EAX, 5
+    mov DWORD[ebp-8], EAX
+    jmp main.b25
+    main.b23:
+    mov EAX, DWORD[ebp-8]
+    add EAX, 200
+    mov DWORD[ebp-8], EAX
+    main.b25:
+    mov EAX, DWORD[ebp-8]
+    leave
+    ret
+; ======函数完毕=======
+
+
+; ==============================
+; 程序入口点 (ELF入口)
+_start:
+    ; 调用main函数
+    call main
+    ; 使用系统调用退出程序 (sys_exit = 1)
+    ; 返回值在EAX中
+    mov ebx, eax; 返回码
+    mov eax, 1; sys_exit
+    int 0x80; 调用内核
+
+    section .data
+    align 1
+    g_On:
+    db 1
+    section .bss
+    alignb 1
+    g_Flags: resb 2
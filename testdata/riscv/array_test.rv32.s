@@ -0,0 +1,351 @@
+# 由 cuteify 生成的 RISC-V 汇编（rv32），使用 GNU as 汇编
+.text
+.globl _start
+
+# ==============================
+# Function: sum1
+sum1:
+    addi sp, sp, -48
+    sw ra, 44(sp)
+    sw s0, 40(sp)
+    sw s2, 36(sp)
+    sw s3, 32(sp)
+    sw s4, 28(sp)
+    sw s5, 24(sp)
+    sw s6, 20(sp)
+    sw s7, 16(sp)
+    sw s8, 12(sp)
+    sw s9, 8(sp)
+    sw s10, 4(sp)
+    sw s11, 0(sp)
+    addi s0, sp, 48  # 帧指针
+    addi sp, sp, -16
+    sw a0, -56(s0)
+    sw a1, -52(s0)
+    # ---- 函数开始 ----
+    li t0, 0
+    sw t0, -60(s0)
+    li t0, 0
+    sw t0, -64(s0)
+    while_1:  # while循环开始
+    lw t0, -64(s0)
+    addi t1, s0, -56
+    lw t1, 4(t1)
+    slt t0, t0, t1
+    beqz t0, while_1_end  # while循环条件检查
+    lw t0, -60(s0)
+    lw t1, -64(s0)
+    addi t2, s0, -56
+    lw t2, 0(t2)
+    slli t1, t1, 2
+    add t2, t2, t1  # 元素地址
+    lw t2, 0(t2)
+    add t0, t0, t2
+    sw t0, -60(s0)
+    lw t0, -64(s0)
+    addi t0, t0, 1
+    sw t0, -64(s0)
+    j while_1
+    while_1_end:  # while循环结束
+    lw t0, -60(s0)
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -48
+    lw ra, 44(sp)
+    lw s0, 40(sp)
+    lw s2, 36(sp)
+    lw s3, 32(sp)
+    lw s4, 28(sp)
+    lw s5, 24(sp)
+    lw s6, 20(sp)
+    lw s7, 16(sp)
+    lw s8, 12(sp)
+    lw s9, 8(sp)
+    lw s10, 4(sp)
+    lw s11, 0(sp)
+    addi sp, sp, 48
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: fill2
+fill2:
+    addi sp, sp, -48
+    sw ra, 44(sp)
+    sw s0, 40(sp)
+    sw s2, 36(sp)
+    sw s3, 32(sp)
+    sw s4, 28(sp)
+    sw s5, 24(sp)
+    sw s6, 20(sp)
+    sw s7, 16(sp)
+    sw s8, 12(sp)
+    sw s9, 8(sp)
+    sw s10, 4(sp)
+    sw s11, 0(sp)
+    addi s0, sp, 48  # 帧指针
+    addi sp, sp, -16
+    sw a0, -56(s0)
+    sw a1, -52(s0)
+    sw a2, -60(s0)
+    # ---- 函数开始 ----
+    li t0, 0
+    sw t0, -64(s0)
+    while_2:  # while循环开始
+    lw t0, -64(s0)
+    addi t1, s0, -56
+    lw t1, 4(t1)
+    slt t0, t0, t1
+    beqz t0, while_2_end  # while循环条件检查
+    lbu t0, -60(s0)
+    lw t1, -64(s0)
+    addi t2, s0, -56
+    lw t2, 0(t2)
+    add t2, t2, t1  # 元素地址
+    sb t0, 0(t2)
+    lw t0, -64(s0)
+    addi t0, t0, 1
+    sw t0, -64(s0)
+    j while_2
+    while_2_end:  # while循环结束
+    # ---- 退出函数 ----
+    addi sp, s0, -48
+    lw ra, 44(sp)
+    lw s0, 40(sp)
+    lw s2, 36(sp)
+    lw s3, 32(sp)
+    lw s4, 28(sp)
+    lw s5, 24(sp)
+    lw s6, 20(sp)
+    lw s7, 16(sp)
+    lw s8, 12(sp)
+    lw s9, 8(sp)
+    lw s10, 4(sp)
+    lw s11, 0(sp)
+    addi sp, sp, 48
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: main
+main:
+    addi sp, sp, -48
+    sw ra, 44(sp)
+    sw s0, 40(sp)
+    sw s2, 36(sp)
+    sw s3, 32(sp)
+    sw s4, 28(sp)
+    sw s5, 24(sp)
+    sw s6, 20(sp)
+    sw s7, 16(sp)
+    sw s8, 12(sp)
+    sw s9, 8(sp)
+    sw s10, 4(sp)
+    sw s11, 0(sp)
+    addi s0, sp, 48  # 帧指针
+    addi sp, sp, -80
+    # ---- 函数开始 ----
+    li t0, 0
+    sw t0, -72(s0)
+    while_3:  # while循环开始
+    lw t0, -72(s0)
+    li t1, 5
+    slt t0, t0, t1
+    beqz t0, while_3_end  # while循环条件检查
+    lw t0, -72(s0)
+    li t1, 2
+    mul t0, t0, t1
+    lw t1, -72(s0)
+    addi t2, s0, -68
+    slli t1, t1, 2
+    add t2, t2, t1  # 元素地址
+    sw t0, 0(t2)
+    lw t0, -72(s0)
+    addi t0, t0, 1
+    sw t0, -72(s0)
+    j while_3
+    while_3_end:  # while循环结束
+    addi sp, sp, -16  # 参数暂存区
+    addi t0, s0, -75
+    sw t0, 0(sp)
+    li t6, 3
+    sw t6, 4(sp)
+    li t0, 4
+    andi t0, t0, 255  # 截断为u8
+    sw t0, 8(sp)
+    lw a0, 0(sp)
+    lw a1, 4(sp)
+    lw a2, 8(sp)
+    call fill2
+    addi sp, sp, 16
+    li t0, 9
+    addi t1, s0, -100
+    addi t1, t1, 12
+    addi t1, t1, 8
+    sw t0, 0(t1)
+    addi t0, s0, -100
+    addi t0, t0, 12
+    addi t0, t0, 8
+    lw t0, 0(t0)
+    addi t0, t0, 1
+    addi t1, s0, -100
+    addi t1, t1, 4
+    sw t0, 0(t1)
+    addi t0, s0, -108
+    addi t0, t0, 4
+    sw t0, -112(s0)
+    li t0, 1
+    sw t0, -116(s0)
+    li t0, 2
+    slli t0, t0, 16
+    srai t0, t0, 16  # 截断为i16
+    lw t1, -116(s0)
+    addi t2, s0, -108
+    slli t1, t1, 2
+    add t2, t2, t1  # 元素地址
+    sh t0, 0(t2)
+    lw t0, -116(s0)
+    addi t1, s0, -108
+    slli t0, t0, 2
+    add t1, t1, t0  # 元素地址
+    lh t1, 0(t1)
+    addi t1, t1, 1
+    slli t1, t1, 16
+    srai t1, t1, 16  # 截断为i16
+    lw t0, -116(s0)
+    addi t2, s0, -108
+    slli t0, t0, 2
+    add t2, t2, t0  # 元素地址
+    addi t2, t2, 2
+    sh t1, 0(t2)
+    lw t0, -116(s0)
+    addi t1, s0, -108
+    slli t0, t0, 2
+    add t1, t1, t0  # 元素地址
+    addi t1, t1, 2
+    sw t1, -120(s0)
+    lw t0, -120(s0)
+    lh t0, 0(t0)
+    li t1, 2
+    mul t0, t0, t1
+    slli t0, t0, 16
+    srai t0, t0, 16  # 截断为i16
+    lw t1, -120(s0)
+    sh t0, 0(t1)
+    li t0, 6
+    la t6, g_Table
+    addi t1, t6, 0
+    addi t1, t1, 12
+    sw t0, 0(t1)
+    addi t0, s0, -68
+    addi t1, s0, -128
+    sw t0, 0(t1)
+    li t6, 5
+    sw t6, 4(t1)
+    li t0, 1
+    addi t1, s0, -128
+    lw t1, 0(t1)
+    sw t0, 0(t1)
+    lw t0, -112(s0)
+    li t1, 0
+    xor t0, t0, t1
+    seqz t0, t0
+    beqz t0, end_if_1  # if条件检查
+    if_1:
+    li t0, 1
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -48
+    lw ra, 44(sp)
+    lw s0, 40(sp)
+    lw s2, 36(sp)
+    lw s3, 32(sp)
+    lw s4, 28(sp)
+    lw s5, 24(sp)
+    lw s6, 20(sp)
+    lw s7, 16(sp)
+    lw s8, 12(sp)
+    lw s9, 8(sp)
+    lw s10, 4(sp)
+    lw s11, 0(sp)
+    addi sp, sp, 48
+    ret
+    end_if_1:
+    addi sp, sp, -16  # 参数暂存区
+    addi t0, s0, -128
+    lw t1, 0(t0)
+    sw t1, 0(sp)
+    lw t1, 4(t0)
+    sw t1, 4(sp)
+    lw a0, 0(sp)
+    lw a1, 4(sp)
+    call sum1
+    addi sp, sp, 16
+    mv t0, a0  # 返回值
+    addi sp, sp, -16  # 保存临时寄存器
+    sw t0, 0(sp)
+    addi sp, sp, -16  # 参数暂存区
+    la t6, g_Table
+    addi t1, t6, 0
+    sw t1, 0(sp)
+    li t6, 4
+    sw t6, 4(sp)
+    lw a0, 0(sp)
+    lw a1, 4(sp)
+    call sum1
+    addi sp, sp, 16
+    mv t1, a0  # 返回值
+    lw t0, 0(sp)
+    addi sp, sp, 16  # 恢复临时寄存器
+    add t0, t0, t1
+    addi t1, s0, -75
+    addi t1, t1, 2
+    lbu t1, 0(t1)
+    add t0, t0, t1
+    addi t1, s0, -100
+    addi t1, t1, 4
+    lw t1, 0(t1)
+    add t0, t0, t1
+    addi t0, t0, 2
+    addi t1, s0, -108
+    addi t1, t1, 4
+    lh t1, 2(t1)
+    add t0, t0, t1
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -48
+    lw ra, 44(sp)
+    lw s0, 40(sp)
+    lw s2, 36(sp)
+    lw s3, 32(sp)
+    lw s4, 28(sp)
+    lw s5, 24(sp)
+    lw s6, 20(sp)
+    lw s7, 16(sp)
+    lw s8, 12(sp)
+    lw s9, 8(sp)
+    lw s10, 4(sp)
+    lw s11, 0(sp)
+    addi sp, sp, 48
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# 程序入口点 (ELF入口)
+_start:
+    .option push
+    .option norelax
+    la gp, __global_pointer$
+    .option pop
+    call main
+    # 返回值在a0中，即为退出码
+    li a7, 93  # exit
+    ecall
+
+.bss
+.balign 4
+g_Table:
+.zero 16
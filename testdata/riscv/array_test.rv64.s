@@ -0,0 +1,351 @@
+# 由 cuteify 生成的 RISC-V 汇编（rv64），使用 GNU as 汇编
+.text
+.globl _start
+
+# ==============================
+# Function: sum1
+sum1:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -32
+    sd a0, -112(s0)
+    sd a1, -104(s0)
+    # ---- 函数开始 ----
+    li t0, 0
+    sd t0, -120(s0)
+    li t0, 0
+    sd t0, -128(s0)
+    while_1:  # while循环开始
+    ld t0, -128(s0)
+    addi t1, s0, -112
+    ld t1, 8(t1)
+    slt t0, t0, t1
+    beqz t0, while_1_end  # while循环条件检查
+    ld t0, -120(s0)
+    ld t1, -128(s0)
+    addi t2, s0, -112
+    ld t2, 0(t2)
+    slli t1, t1, 3
+    add t2, t2, t1  # 元素地址
+    ld t2, 0(t2)
+    add t0, t0, t2
+    sd t0, -120(s0)
+    ld t0, -128(s0)
+    addi t0, t0, 1
+    sd t0, -128(s0)
+    j while_1
+    while_1_end:  # while循环结束
+    ld t0, -120(s0)
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: fill2
+fill2:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -32
+    sd a0, -112(s0)
+    sd a1, -104(s0)
+    sd a2, -120(s0)
+    # ---- 函数开始 ----
+    li t0, 0
+    sd t0, -128(s0)
+    while_2:  # while循环开始
+    ld t0, -128(s0)
+    addi t1, s0, -112
+    ld t1, 8(t1)
+    slt t0, t0, t1
+    beqz t0, while_2_end  # while循环条件检查
+    lbu t0, -120(s0)
+    ld t1, -128(s0)
+    addi t2, s0, -112
+    ld t2, 0(t2)
+    add t2, t2, t1  # 元素地址
+    sb t0, 0(t2)
+    ld t0, -128(s0)
+    addi t0, t0, 1
+    sd t0, -128(s0)
+    j while_2
+    while_2_end:  # while循环结束
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: main
+main:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -160
+    # ---- 函数开始 ----
+    li t0, 0
+    sd t0, -144(s0)
+    while_3:  # while循环开始
+    ld t0, -144(s0)
+    li t1, 5
+    slt t0, t0, t1
+    beqz t0, while_3_end  # while循环条件检查
+    ld t0, -144(s0)
+    li t1, 2
+    mul t0, t0, t1
+    ld t1, -144(s0)
+    addi t2, s0, -136
+    slli t1, t1, 3
+    add t2, t2, t1  # 元素地址
+    sd t0, 0(t2)
+    ld t0, -144(s0)
+    addi t0, t0, 1
+    sd t0, -144(s0)
+    j while_3
+    while_3_end:  # while循环结束
+    addi sp, sp, -32  # 参数暂存区
+    addi t0, s0, -147
+    sd t0, 0(sp)
+    li t6, 3
+    sd t6, 8(sp)
+    li t0, 4
+    andi t0, t0, 255  # 截断为u8
+    sd t0, 16(sp)
+    ld a0, 0(sp)
+    ld a1, 8(sp)
+    ld a2, 16(sp)
+    call fill2
+    addi sp, sp, 32
+    li t0, 9
+    addi t1, s0, -200
+    addi t1, t1, 24
+    addi t1, t1, 16
+    sd t0, 0(t1)
+    addi t0, s0, -200
+    addi t0, t0, 24
+    addi t0, t0, 16
+    ld t0, 0(t0)
+    addi t0, t0, 1
+    addi t1, s0, -200
+    addi t1, t1, 8
+    sd t0, 0(t1)
+    addi t0, s0, -208
+    addi t0, t0, 4
+    sd t0, -216(s0)
+    li t0, 1
+    sd t0, -224(s0)
+    li t0, 2
+    slli t0, t0, 48
+    srai t0, t0, 48  # 截断为i16
+    ld t1, -224(s0)
+    addi t2, s0, -208
+    slli t1, t1, 2
+    add t2, t2, t1  # 元素地址
+    sh t0, 0(t2)
+    ld t0, -224(s0)
+    addi t1, s0, -208
+    slli t0, t0, 2
+    add t1, t1, t0  # 元素地址
+    lh t1, 0(t1)
+    addi t1, t1, 1
+    slli t1, t1, 48
+    srai t1, t1, 48  # 截断为i16
+    ld t0, -224(s0)
+    addi t2, s0, -208
+    slli t0, t0, 2
+    add t2, t2, t0  # 元素地址
+    addi t2, t2, 2
+    sh t1, 0(t2)
+    ld t0, -224(s0)
+    addi t1, s0, -208
+    slli t0, t0, 2
+    add t1, t1, t0  # 元素地址
+    addi t1, t1, 2
+    sd t1, -232(s0)
+    ld t0, -232(s0)
+    lh t0, 0(t0)
+    li t1, 2
+    mul t0, t0, t1
+    slli t0, t0, 48
+    srai t0, t0, 48  # 截断为i16
+    ld t1, -232(s0)
+    sh t0, 0(t1)
+    li t0, 6
+    la t6, g_Table
+    addi t1, t6, 0
+    addi t1, t1, 24
+    sd t0, 0(t1)
+    addi t0, s0, -136
+    addi t1, s0, -248
+    sd t0, 0(t1)
+    li t6, 5
+    sd t6, 8(t1)
+    li t0, 1
+    addi t1, s0, -248
+    ld t1, 0(t1)
+    sd t0, 0(t1)
+    ld t0, -216(s0)
+    li t1, 0
+    xor t0, t0, t1
+    seqz t0, t0
+    beqz t0, end_if_1  # if条件检查
+    if_1:
+    li t0, 1
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+    end_if_1:
+    addi sp, sp, -16  # 参数暂存区
+    addi t0, s0, -248
+    ld t1, 0(t0)
+    sd t1, 0(sp)
+    ld t1, 8(t0)
+    sd t1, 8(sp)
+    ld a0, 0(sp)
+    ld a1, 8(sp)
+    call sum1
+    addi sp, sp, 16
+    mv t0, a0  # 返回值
+    addi sp, sp, -16  # 保存临时寄存器
+    sd t0, 0(sp)
+    addi sp, sp, -16  # 参数暂存区
+    la t6, g_Table
+    addi t1, t6, 0
+    sd t1, 0(sp)
+    li t6, 4
+    sd t6, 8(sp)
+    ld a0, 0(sp)
+    ld a1, 8(sp)
+    call sum1
+    addi sp, sp, 16
+    mv t1, a0  # 返回值
+    ld t0, 0(sp)
+    addi sp, sp, 16  # 恢复临时寄存器
+    add t0, t0, t1
+    addi t1, s0, -147
+    addi t1, t1, 2
+    lbu t1, 0(t1)
+    add t0, t0, t1
+    addi t1, s0, -200
+    addi t1, t1, 8
+    ld t1, 0(t1)
+    add t0, t0, t1
+    addi t0, t0, 2
+    addi t1, s0, -208
+    addi t1, t1, 4
+    lh t1, 2(t1)
+    add t0, t0, t1
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# 程序入口点 (ELF入口)
+_start:
+    .option push
+    .option norelax
+    la gp, __global_pointer$
+    .option pop
+    call main
+    # 返回值在a0中，即为退出码
+    li a7, 93  # exit
+    ecall
+
+.bss
+.balign 8
+g_Table:
+.zero 32
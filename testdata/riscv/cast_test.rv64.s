@@ -0,0 +1,653 @@
+# 由 cuteify 生成的 RISC-V 汇编（rv64），使用 GNU as 汇编
+.text
+.globl _start
+
+# ==============================
+# Function: trunc1
+trunc1:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -16
+    sd a0, -104(s0)
+    # ---- 函数开始 ----
+    ld t0, -104(s0)
+    andi t0, t0, 255  # 截断为u8
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: sext1
+sext1:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -16
+    sd a0, -104(s0)
+    # ---- 函数开始 ----
+    ld t0, -104(s0)
+    slli t0, t0, 56
+    srai t0, t0, 56  # 截断为i8
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: widen1
+widen1:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -16
+    sd a0, -104(s0)
+    # ---- 函数开始 ----
+    lbu t0, -104(s0)
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: low1
+low1:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -16
+    sd a0, -104(s0)
+    # ---- 函数开始 ----
+    lh t0, -104(s0)
+    andi t0, t0, 255  # 截断为u8
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: signed1
+signed1:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -16
+    sd a0, -104(s0)
+    # ---- 函数开始 ----
+    lbu t0, -104(s0)
+    slli t0, t0, 56
+    srai t0, t0, 56  # 截断为i8
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: unsigned1
+unsigned1:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -16
+    sd a0, -104(s0)
+    # ---- 函数开始 ----
+    lb t0, -104(s0)
+    slli t0, t0, 32
+    srli t0, t0, 32  # 截断为u32
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: wrap1
+wrap1:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -16
+    sd a0, -104(s0)
+    # ---- 函数开始 ----
+    lbu t0, -104(s0)
+    lbu t1, -104(s0)
+    add t0, t0, t1
+    andi t0, t0, 255  # 截断为u8
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: bump1
+bump1:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -16
+    sd a0, -104(s0)
+    # ---- 函数开始 ----
+    ld t0, -104(s0)
+    sd t0, -112(s0)
+    ld t0, -112(s0)
+    ld t0, 0(t0)
+    addi t0, t0, 1
+    ld t1, -112(s0)
+    sd t0, 0(t1)
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: digit1
+digit1:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -16
+    sd a0, -104(s0)
+    # ---- 函数开始 ----
+    lbu t0, -104(s0)
+    addi t0, t0, 48
+    andi t0, t0, 255  # 截断为u8
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: main
+main:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -16
+    # ---- 函数开始 ----
+    addi sp, sp, -16  # 参数暂存区
+    li t0, -1
+    sd t0, 0(sp)
+    ld a0, 0(sp)
+    call low1
+    addi sp, sp, 16
+    mv t0, a0  # 返回值
+    li t1, 255
+    xor t0, t0, t1
+    snez t0, t0
+    beqz t0, end_if_1  # if条件检查
+    if_1:
+    li t0, 1
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+    end_if_1:
+    addi sp, sp, -16  # 参数暂存区
+    li t0, 255
+    andi t0, t0, 255  # 截断为u8
+    sd t0, 0(sp)
+    ld a0, 0(sp)
+    call signed1
+    addi sp, sp, 16
+    mv t0, a0  # 返回值
+    addi t0, t0, 1
+    li t1, 0
+    xor t0, t0, t1
+    snez t0, t0
+    beqz t0, end_if_2  # if条件检查
+    if_2:
+    li t0, 2
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+    end_if_2:
+    addi sp, sp, -16  # 参数暂存区
+    li t0, -1
+    sd t0, 0(sp)
+    ld a0, 0(sp)
+    call unsigned1
+    addi sp, sp, 16
+    mv t0, a0  # 返回值
+    li t1, 4294967295
+    xor t0, t0, t1
+    snez t0, t0
+    beqz t0, end_if_3  # if条件检查
+    if_3:
+    li t0, 3
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+    end_if_3:
+    addi sp, sp, -16  # 参数暂存区
+    li t0, 200
+    andi t0, t0, 255  # 截断为u8
+    sd t0, 0(sp)
+    ld a0, 0(sp)
+    call wrap1
+    addi sp, sp, 16
+    mv t0, a0  # 返回值
+    li t1, 144
+    xor t0, t0, t1
+    snez t0, t0
+    beqz t0, end_if_4  # if条件检查
+    if_4:
+    li t0, 4
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+    end_if_4:
+    li t0, 7
+    sd t0, -104(s0)
+    addi sp, sp, -16  # 参数暂存区
+    addi t0, s0, -104
+    sd t0, 0(sp)
+    ld a0, 0(sp)
+    call bump1
+    addi sp, sp, 16
+    li t0, -56
+    sb t0, -105(s0)
+    lb t0, -105(s0)
+    addi t0, t0, 56
+    li t1, 0
+    xor t0, t0, t1
+    snez t0, t0
+    beqz t0, end_if_5  # if条件检查
+    if_5:
+    li t0, 5
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+    end_if_5:
+    addi sp, sp, -16  # 参数暂存区
+    li t0, 300
+    sd t0, 0(sp)
+    ld a0, 0(sp)
+    call trunc1
+    addi sp, sp, 16
+    mv t0, a0  # 返回值
+    addi sp, sp, -16  # 保存临时寄存器
+    sd t0, 0(sp)
+    addi sp, sp, -16  # 参数暂存区
+    li t1, 200
+    sd t1, 0(sp)
+    ld a0, 0(sp)
+    call sext1
+    addi sp, sp, 16
+    mv t1, a0  # 返回值
+    ld t0, 0(sp)
+    addi sp, sp, 16  # 恢复临时寄存器
+    add t0, t0, t1
+    addi sp, sp, -16  # 保存临时寄存器
+    sd t0, 0(sp)
+    addi sp, sp, -16  # 参数暂存区
+    li t1, 200
+    andi t1, t1, 255  # 截断为u8
+    sd t1, 0(sp)
+    ld a0, 0(sp)
+    call widen1
+    addi sp, sp, 16
+    mv t1, a0  # 返回值
+    ld t0, 0(sp)
+    addi sp, sp, 16  # 恢复临时寄存器
+    add t0, t0, t1
+    addi sp, sp, -16  # 保存临时寄存器
+    sd t0, 0(sp)
+    addi sp, sp, -16  # 参数暂存区
+    li t1, 1
+    andi t1, t1, 255  # 截断为u8
+    sd t1, 0(sp)
+    ld a0, 0(sp)
+    call digit1
+    addi sp, sp, 16
+    mv t1, a0  # 返回值
+    ld t0, 0(sp)
+    addi sp, sp, 16  # 恢复临时寄存器
+    add t0, t0, t1
+    ld t1, -104(s0)
+    add t0, t0, t1
+    addi t0, t0, -150
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# 程序入口点 (ELF入口)
+_start:
+    .option push
+    .option norelax
+    la gp, __global_pointer$
+    .option pop
+    call main
+    # 返回值在a0中，即为退出码
+    li a7, 93  # exit
+    ecall
+
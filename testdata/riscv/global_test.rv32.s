@@ -0,0 +1,211 @@
+# 由 cuteify 生成的 RISC-V 汇编（rv32），使用 GNU as 汇编
+.text
+.globl _start
+
+# ==============================
+# Function: Counter_Tick0
+Counter_Tick0:
+    addi sp, sp, -64
+    sw ra, 60(sp)
+    sw s0, 56(sp)
+    sw s1, 52(sp)
+    sw s2, 48(sp)
+    sw s3, 44(sp)
+    sw s4, 40(sp)
+    sw s5, 36(sp)
+    sw s6, 32(sp)
+    sw s7, 28(sp)
+    sw s8, 24(sp)
+    sw s9, 20(sp)
+    sw s10, 16(sp)
+    sw s11, 12(sp)
+    addi s0, sp, 64  # 帧指针
+    mv s1, a0  # self地址
+    # ---- 函数开始 ----
+    lw t0, 4(s1)
+    lw t1, 0(s1)
+    add t0, t0, t1
+    sw t0, 4(s1)
+    # ---- 退出函数 ----
+    addi sp, s0, -64
+    lw ra, 60(sp)
+    lw s0, 56(sp)
+    lw s1, 52(sp)
+    lw s2, 48(sp)
+    lw s3, 44(sp)
+    lw s4, 40(sp)
+    lw s5, 36(sp)
+    lw s6, 32(sp)
+    lw s7, 28(sp)
+    lw s8, 24(sp)
+    lw s9, 20(sp)
+    lw s10, 16(sp)
+    lw s11, 12(sp)
+    addi sp, sp, 64
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: bump1
+bump1:
+    addi sp, sp, -48
+    sw ra, 44(sp)
+    sw s0, 40(sp)
+    sw s2, 36(sp)
+    sw s3, 32(sp)
+    sw s4, 28(sp)
+    sw s5, 24(sp)
+    sw s6, 20(sp)
+    sw s7, 16(sp)
+    sw s8, 12(sp)
+    sw s9, 8(sp)
+    sw s10, 4(sp)
+    sw s11, 0(sp)
+    addi s0, sp, 48  # 帧指针
+    addi sp, sp, -16
+    sw a0, -52(s0)
+    # ---- 函数开始 ----
+    la t6, g_Zero
+    lw t0, 0(t6)
+    lw t1, -52(s0)
+    add t0, t0, t1
+    la t6, g_Zero
+    sw t0, 0(t6)
+    # ---- 退出函数 ----
+    addi sp, s0, -48
+    lw ra, 44(sp)
+    lw s0, 40(sp)
+    lw s2, 36(sp)
+    lw s3, 32(sp)
+    lw s4, 28(sp)
+    lw s5, 24(sp)
+    lw s6, 20(sp)
+    lw s7, 16(sp)
+    lw s8, 12(sp)
+    lw s9, 8(sp)
+    lw s10, 4(sp)
+    lw s11, 0(sp)
+    addi sp, sp, 48
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: main
+main:
+    addi sp, sp, -48
+    sw ra, 44(sp)
+    sw s0, 40(sp)
+    sw s2, 36(sp)
+    sw s3, 32(sp)
+    sw s4, 28(sp)
+    sw s5, 24(sp)
+    sw s6, 20(sp)
+    sw s7, 16(sp)
+    sw s8, 12(sp)
+    sw s9, 8(sp)
+    sw s10, 4(sp)
+    sw s11, 0(sp)
+    addi s0, sp, 48  # 帧指针
+    addi sp, sp, -16
+    # ---- 函数开始 ----
+    la t0, str_1
+    sw t0, -52(s0)
+    addi sp, sp, -16  # 参数暂存区
+    la t6, g_Count
+    addi t0, t6, 0
+    sw t0, 0(sp)
+    lw a0, 0(sp)
+    call Counter_Tick0
+    addi sp, sp, 16
+    addi sp, sp, -16  # 参数暂存区
+    la t6, g_Count
+    addi t0, t6, 0
+    sw t0, 0(sp)
+    lw a0, 0(sp)
+    call Counter_Tick0
+    addi sp, sp, 16
+    addi sp, sp, -16  # 参数暂存区
+    la t6, g_Base
+    lw t0, 0(t6)
+    sw t0, 0(sp)
+    lw a0, 0(sp)
+    call bump1
+    addi sp, sp, 16
+    la t6, g_Zero
+    lw t0, 0(t6)
+    sw t0, -56(s0)
+    addi sp, sp, -16  # 参数暂存区
+    li t0, 1
+    sw t0, 0(sp)
+    lw a0, 0(sp)
+    call bump1
+    addi sp, sp, 16
+    la t6, g_Count+4
+    lw t0, 0(t6)
+    la t6, g_Zero
+    lw t1, 0(t6)
+    add t0, t0, t1
+    lw t1, -56(s0)
+    sub t0, t0, t1
+    la t6, g_Initial
+    lbu t1, 0(t6)
+    add t0, t0, t1
+    addi t0, t0, -65
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -48
+    lw ra, 44(sp)
+    lw s0, 40(sp)
+    lw s2, 36(sp)
+    lw s3, 32(sp)
+    lw s4, 28(sp)
+    lw s5, 24(sp)
+    lw s6, 20(sp)
+    lw s7, 16(sp)
+    lw s8, 12(sp)
+    lw s9, 8(sp)
+    lw s10, 4(sp)
+    lw s11, 0(sp)
+    addi sp, sp, 48
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# 程序入口点 (ELF入口)
+_start:
+    .option push
+    .option norelax
+    la gp, __global_pointer$
+    .option pop
+    call main
+    # 返回值在a0中，即为退出码
+    li a7, 93  # exit
+    ecall
+
+.section .rodata
+str_0: .asciz "hi\012"
+str_1: .asciz "hello"
+.data
+.balign 4
+g_Count:
+.word 2
+.zero 4
+.balign 4
+g_Base:
+.word 40
+.balign 1
+g_Flag:
+.byte 1
+.balign 1
+g_Initial:
+.byte 65
+.balign 4
+g_Greeting:
+.word str_0
+.bss
+.balign 4
+g_Zero:
+.zero 4
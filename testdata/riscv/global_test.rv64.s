@@ -0,0 +1,211 @@
+# 由 cuteify 生成的 RISC-V 汇编（rv64），使用 GNU as 汇编
+.text
+.globl _start
+
+# ==============================
+# Function: Counter_Tick0
+Counter_Tick0:
+    addi sp, sp, -112
+    sd ra, 104(sp)
+    sd s0, 96(sp)
+    sd s1, 88(sp)
+    sd s2, 80(sp)
+    sd s3, 72(sp)
+    sd s4, 64(sp)
+    sd s5, 56(sp)
+    sd s6, 48(sp)
+    sd s7, 40(sp)
+    sd s8, 32(sp)
+    sd s9, 24(sp)
+    sd s10, 16(sp)
+    sd s11, 8(sp)
+    addi s0, sp, 112  # 帧指针
+    mv s1, a0  # self地址
+    # ---- 函数开始 ----
+    ld t0, 8(s1)
+    ld t1, 0(s1)
+    add t0, t0, t1
+    sd t0, 8(s1)
+    # ---- 退出函数 ----
+    addi sp, s0, -112
+    ld ra, 104(sp)
+    ld s0, 96(sp)
+    ld s1, 88(sp)
+    ld s2, 80(sp)
+    ld s3, 72(sp)
+    ld s4, 64(sp)
+    ld s5, 56(sp)
+    ld s6, 48(sp)
+    ld s7, 40(sp)
+    ld s8, 32(sp)
+    ld s9, 24(sp)
+    ld s10, 16(sp)
+    ld s11, 8(sp)
+    addi sp, sp, 112
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: bump1
+bump1:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -16
+    sd a0, -104(s0)
+    # ---- 函数开始 ----
+    la t6, g_Zero
+    ld t0, 0(t6)
+    ld t1, -104(s0)
+    add t0, t0, t1
+    la t6, g_Zero
+    sd t0, 0(t6)
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: main
+main:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -16
+    # ---- 函数开始 ----
+    la t0, str_1
+    sd t0, -104(s0)
+    addi sp, sp, -16  # 参数暂存区
+    la t6, g_Count
+    addi t0, t6, 0
+    sd t0, 0(sp)
+    ld a0, 0(sp)
+    call Counter_Tick0
+    addi sp, sp, 16
+    addi sp, sp, -16  # 参数暂存区
+    la t6, g_Count
+    addi t0, t6, 0
+    sd t0, 0(sp)
+    ld a0, 0(sp)
+    call Counter_Tick0
+    addi sp, sp, 16
+    addi sp, sp, -16  # 参数暂存区
+    la t6, g_Base
+    ld t0, 0(t6)
+    sd t0, 0(sp)
+    ld a0, 0(sp)
+    call bump1
+    addi sp, sp, 16
+    la t6, g_Zero
+    ld t0, 0(t6)
+    sd t0, -112(s0)
+    addi sp, sp, -16  # 参数暂存区
+    li t0, 1
+    sd t0, 0(sp)
+    ld a0, 0(sp)
+    call bump1
+    addi sp, sp, 16
+    la t6, g_Count+8
+    ld t0, 0(t6)
+    la t6, g_Zero
+    ld t1, 0(t6)
+    add t0, t0, t1
+    ld t1, -112(s0)
+    sub t0, t0, t1
+    la t6, g_Initial
+    lbu t1, 0(t6)
+    add t0, t0, t1
+    addi t0, t0, -65
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# 程序入口点 (ELF入口)
+_start:
+    .option push
+    .option norelax
+    la gp, __global_pointer$
+    .option pop
+    call main
+    # 返回值在a0中，即为退出码
+    li a7, 93  # exit
+    ecall
+
+.section .rodata
+str_0: .asciz "hi\012"
+str_1: .asciz "hello"
+.data
+.balign 8
+g_Count:
+.dword 2
+.zero 8
+.balign 8
+g_Base:
+.dword 40
+.balign 1
+g_Flag:
+.byte 1
+.balign 1
+g_Initial:
+.byte 65
+.balign 8
+g_Greeting:
+.dword str_0
+.bss
+.balign 8
+g_Zero:
+.zero 8
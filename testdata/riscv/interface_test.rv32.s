@@ -0,0 +1,379 @@
+# 由 cuteify 生成的 RISC-V 汇编（rv32），使用 GNU as 汇编
+.text
+.globl _start
+
+# ==============================
+# Function: Rect_Area0
+Rect_Area0:
+    addi sp, sp, -64
+    sw ra, 60(sp)
+    sw s0, 56(sp)
+    sw s1, 52(sp)
+    sw s2, 48(sp)
+    sw s3, 44(sp)
+    sw s4, 40(sp)
+    sw s5, 36(sp)
+    sw s6, 32(sp)
+    sw s7, 28(sp)
+    sw s8, 24(sp)
+    sw s9, 20(sp)
+    sw s10, 16(sp)
+    sw s11, 12(sp)
+    addi s0, sp, 64  # 帧指针
+    mv s1, a0  # self地址
+    # ---- 函数开始 ----
+    lw t0, 0(s1)
+    lw t1, 4(s1)
+    mul t0, t0, t1
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -64
+    lw ra, 60(sp)
+    lw s0, 56(sp)
+    lw s1, 52(sp)
+    lw s2, 48(sp)
+    lw s3, 44(sp)
+    lw s4, 40(sp)
+    lw s5, 36(sp)
+    lw s6, 32(sp)
+    lw s7, 28(sp)
+    lw s8, 24(sp)
+    lw s9, 20(sp)
+    lw s10, 16(sp)
+    lw s11, 12(sp)
+    addi sp, sp, 64
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: Rect_Grow1
+Rect_Grow1:
+    addi sp, sp, -64
+    sw ra, 60(sp)
+    sw s0, 56(sp)
+    sw s1, 52(sp)
+    sw s2, 48(sp)
+    sw s3, 44(sp)
+    sw s4, 40(sp)
+    sw s5, 36(sp)
+    sw s6, 32(sp)
+    sw s7, 28(sp)
+    sw s8, 24(sp)
+    sw s9, 20(sp)
+    sw s10, 16(sp)
+    sw s11, 12(sp)
+    addi s0, sp, 64  # 帧指针
+    mv s1, a0  # self地址
+    addi sp, sp, -16
+    sw a1, -68(s0)
+    # ---- 函数开始 ----
+    lw t0, 0(s1)
+    lw t1, -68(s0)
+    add t0, t0, t1
+    sw t0, 0(s1)
+    lw t0, 4(s1)
+    lw t1, -68(s0)
+    add t0, t0, t1
+    sw t0, 4(s1)
+    # ---- 退出函数 ----
+    addi sp, s0, -64
+    lw ra, 60(sp)
+    lw s0, 56(sp)
+    lw s1, 52(sp)
+    lw s2, 48(sp)
+    lw s3, 44(sp)
+    lw s4, 40(sp)
+    lw s5, 36(sp)
+    lw s6, 32(sp)
+    lw s7, 28(sp)
+    lw s8, 24(sp)
+    lw s9, 20(sp)
+    lw s10, 16(sp)
+    lw s11, 12(sp)
+    addi sp, sp, 64
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: Square_Area0
+Square_Area0:
+    addi sp, sp, -64
+    sw ra, 60(sp)
+    sw s0, 56(sp)
+    sw s1, 52(sp)
+    sw s2, 48(sp)
+    sw s3, 44(sp)
+    sw s4, 40(sp)
+    sw s5, 36(sp)
+    sw s6, 32(sp)
+    sw s7, 28(sp)
+    sw s8, 24(sp)
+    sw s9, 20(sp)
+    sw s10, 16(sp)
+    sw s11, 12(sp)
+    addi s0, sp, 64  # 帧指针
+    mv s1, a0  # self地址
+    # ---- 函数开始 ----
+    lw t0, 0(s1)
+    lw t1, 0(s1)
+    mul t0, t0, t1
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -64
+    lw ra, 60(sp)
+    lw s0, 56(sp)
+    lw s1, 52(sp)
+    lw s2, 48(sp)
+    lw s3, 44(sp)
+    lw s4, 40(sp)
+    lw s5, 36(sp)
+    lw s6, 32(sp)
+    lw s7, 28(sp)
+    lw s8, 24(sp)
+    lw s9, 20(sp)
+    lw s10, 16(sp)
+    lw s11, 12(sp)
+    addi sp, sp, 64
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: Square_Grow1
+Square_Grow1:
+    addi sp, sp, -64
+    sw ra, 60(sp)
+    sw s0, 56(sp)
+    sw s1, 52(sp)
+    sw s2, 48(sp)
+    sw s3, 44(sp)
+    sw s4, 40(sp)
+    sw s5, 36(sp)
+    sw s6, 32(sp)
+    sw s7, 28(sp)
+    sw s8, 24(sp)
+    sw s9, 20(sp)
+    sw s10, 16(sp)
+    sw s11, 12(sp)
+    addi s0, sp, 64  # 帧指针
+    mv s1, a0  # self地址
+    addi sp, sp, -16
+    sw a1, -68(s0)
+    # ---- 函数开始 ----
+    lw t0, 0(s1)
+    lw t1, -68(s0)
+    add t0, t0, t1
+    sw t0, 0(s1)
+    # ---- 退出函数 ----
+    addi sp, s0, -64
+    lw ra, 60(sp)
+    lw s0, 56(sp)
+    lw s1, 52(sp)
+    lw s2, 48(sp)
+    lw s3, 44(sp)
+    lw s4, 40(sp)
+    lw s5, 36(sp)
+    lw s6, 32(sp)
+    lw s7, 28(sp)
+    lw s8, 24(sp)
+    lw s9, 20(sp)
+    lw s10, 16(sp)
+    lw s11, 12(sp)
+    addi sp, sp, 64
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: Measure1
+Measure1:
+    addi sp, sp, -48
+    sw ra, 44(sp)
+    sw s0, 40(sp)
+    sw s2, 36(sp)
+    sw s3, 32(sp)
+    sw s4, 28(sp)
+    sw s5, 24(sp)
+    sw s6, 20(sp)
+    sw s7, 16(sp)
+    sw s8, 12(sp)
+    sw s9, 8(sp)
+    sw s10, 4(sp)
+    sw s11, 0(sp)
+    addi s0, sp, 48  # 帧指针
+    addi sp, sp, -16
+    sw a0, -56(s0)
+    sw a1, -52(s0)
+    # ---- 函数开始 ----
+    addi sp, sp, -16  # 参数暂存区
+    lw t0, -56(s0)
+    sw t0, 0(sp)
+    li t0, 1
+    sw t0, 4(sp)
+    lw a0, 0(sp)
+    lw a1, 4(sp)
+    lw t6, -52(s0)
+    lw t6, 4(t6)
+    jalr t6  # 动态分派Shape_Grow
+    addi sp, sp, 16
+    addi sp, sp, -16  # 参数暂存区
+    lw t0, -56(s0)
+    sw t0, 0(sp)
+    lw a0, 0(sp)
+    lw t6, -52(s0)
+    lw t6, 0(t6)
+    jalr t6  # 动态分派Shape_Area
+    addi sp, sp, 16
+    mv t0, a0  # 返回值
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -48
+    lw ra, 44(sp)
+    lw s0, 40(sp)
+    lw s2, 36(sp)
+    lw s3, 32(sp)
+    lw s4, 28(sp)
+    lw s5, 24(sp)
+    lw s6, 20(sp)
+    lw s7, 16(sp)
+    lw s8, 12(sp)
+    lw s9, 8(sp)
+    lw s10, 4(sp)
+    lw s11, 0(sp)
+    addi sp, sp, 48
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: main
+main:
+    addi sp, sp, -48
+    sw ra, 44(sp)
+    sw s0, 40(sp)
+    sw s2, 36(sp)
+    sw s3, 32(sp)
+    sw s4, 28(sp)
+    sw s5, 24(sp)
+    sw s6, 20(sp)
+    sw s7, 16(sp)
+    sw s8, 12(sp)
+    sw s9, 8(sp)
+    sw s10, 4(sp)
+    sw s11, 0(sp)
+    addi s0, sp, 48  # 帧指针
+    addi sp, sp, -32
+    # ---- 函数开始 ----
+    sw zero, -56(s0)
+    sw zero, -52(s0)
+    li t0, 2
+    sw t0, -56(s0)
+    li t0, 3
+    sw t0, -52(s0)
+    sw zero, -60(s0)
+    li t0, 2
+    sw t0, -60(s0)
+    addi t0, s0, -56
+    addi t1, s0, -68
+    sw t0, 0(t1)
+    la t6, vtable_Rect_Shape
+    sw t6, 4(t1)
+    addi sp, sp, -16  # 参数暂存区
+    lw t0, -68(s0)
+    sw t0, 0(sp)
+    lw a0, 0(sp)
+    lw t6, -64(s0)
+    lw t6, 0(t6)
+    jalr t6  # 动态分派Shape_Area
+    addi sp, sp, 16
+    mv t0, a0  # 返回值
+    sw t0, -72(s0)
+    addi t0, s0, -60
+    addi t1, s0, -68
+    sw t0, 0(t1)
+    la t6, vtable_Square_Shape
+    sw t6, 4(t1)
+    addi sp, sp, -16  # 参数暂存区
+    lw t0, -68(s0)
+    sw t0, 0(sp)
+    lw a0, 0(sp)
+    lw t6, -64(s0)
+    lw t6, 0(t6)
+    jalr t6  # 动态分派Shape_Area
+    addi sp, sp, 16
+    mv t0, a0  # 返回值
+    sw t0, -76(s0)
+    addi sp, sp, -16  # 参数暂存区
+    addi t0, s0, -56
+    sw t0, 0(sp)
+    la t6, vtable_Rect_Shape
+    sw t6, 4(sp)
+    lw a0, 0(sp)
+    lw a1, 4(sp)
+    call Measure1
+    addi sp, sp, 16
+    mv t0, a0  # 返回值
+    sw t0, -80(s0)
+    lw t0, -72(s0)
+    lw t1, -76(s0)
+    add t0, t0, t1
+    lw t1, -80(s0)
+    add t0, t0, t1
+    addi sp, sp, -16  # 保存临时寄存器
+    sw t0, 0(sp)
+    addi sp, sp, -16  # 参数暂存区
+    addi t1, s0, -60
+    sw t1, 0(sp)
+    la t6, vtable_Square_Shape
+    sw t6, 4(sp)
+    lw a0, 0(sp)
+    lw a1, 4(sp)
+    call Measure1
+    addi sp, sp, 16
+    mv t1, a0  # 返回值
+    lw t0, 0(sp)
+    addi sp, sp, 16  # 恢复临时寄存器
+    add t0, t0, t1
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -48
+    lw ra, 44(sp)
+    lw s0, 40(sp)
+    lw s2, 36(sp)
+    lw s3, 32(sp)
+    lw s4, 28(sp)
+    lw s5, 24(sp)
+    lw s6, 20(sp)
+    lw s7, 16(sp)
+    lw s8, 12(sp)
+    lw s9, 8(sp)
+    lw s10, 4(sp)
+    lw s11, 0(sp)
+    addi sp, sp, 48
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# 程序入口点 (ELF入口)
+_start:
+    .option push
+    .option norelax
+    la gp, __global_pointer$
+    .option pop
+    call main
+    # 返回值在a0中，即为退出码
+    li a7, 93  # exit
+    ecall
+
+.section .rodata
+.balign 4
+vtable_Rect_Shape:
+.word Rect_Area0
+.word Rect_Grow1
+.balign 4
+vtable_Square_Shape:
+.word Square_Area0
+.word Square_Grow1
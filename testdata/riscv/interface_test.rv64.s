@@ -0,0 +1,379 @@
+# 由 cuteify 生成的 RISC-V 汇编（rv64），使用 GNU as 汇编
+.text
+.globl _start
+
+# ==============================
+# Function: Rect_Area0
+Rect_Area0:
+    addi sp, sp, -112
+    sd ra, 104(sp)
+    sd s0, 96(sp)
+    sd s1, 88(sp)
+    sd s2, 80(sp)
+    sd s3, 72(sp)
+    sd s4, 64(sp)
+    sd s5, 56(sp)
+    sd s6, 48(sp)
+    sd s7, 40(sp)
+    sd s8, 32(sp)
+    sd s9, 24(sp)
+    sd s10, 16(sp)
+    sd s11, 8(sp)
+    addi s0, sp, 112  # 帧指针
+    mv s1, a0  # self地址
+    # ---- 函数开始 ----
+    ld t0, 0(s1)
+    ld t1, 8(s1)
+    mul t0, t0, t1
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -112
+    ld ra, 104(sp)
+    ld s0, 96(sp)
+    ld s1, 88(sp)
+    ld s2, 80(sp)
+    ld s3, 72(sp)
+    ld s4, 64(sp)
+    ld s5, 56(sp)
+    ld s6, 48(sp)
+    ld s7, 40(sp)
+    ld s8, 32(sp)
+    ld s9, 24(sp)
+    ld s10, 16(sp)
+    ld s11, 8(sp)
+    addi sp, sp, 112
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: Rect_Grow1
+Rect_Grow1:
+    addi sp, sp, -112
+    sd ra, 104(sp)
+    sd s0, 96(sp)
+    sd s1, 88(sp)
+    sd s2, 80(sp)
+    sd s3, 72(sp)
+    sd s4, 64(sp)
+    sd s5, 56(sp)
+    sd s6, 48(sp)
+    sd s7, 40(sp)
+    sd s8, 32(sp)
+    sd s9, 24(sp)
+    sd s10, 16(sp)
+    sd s11, 8(sp)
+    addi s0, sp, 112  # 帧指针
+    mv s1, a0  # self地址
+    addi sp, sp, -16
+    sd a1, -120(s0)
+    # ---- 函数开始 ----
+    ld t0, 0(s1)
+    ld t1, -120(s0)
+    add t0, t0, t1
+    sd t0, 0(s1)
+    ld t0, 8(s1)
+    ld t1, -120(s0)
+    add t0, t0, t1
+    sd t0, 8(s1)
+    # ---- 退出函数 ----
+    addi sp, s0, -112
+    ld ra, 104(sp)
+    ld s0, 96(sp)
+    ld s1, 88(sp)
+    ld s2, 80(sp)
+    ld s3, 72(sp)
+    ld s4, 64(sp)
+    ld s5, 56(sp)
+    ld s6, 48(sp)
+    ld s7, 40(sp)
+    ld s8, 32(sp)
+    ld s9, 24(sp)
+    ld s10, 16(sp)
+    ld s11, 8(sp)
+    addi sp, sp, 112
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: Square_Area0
+Square_Area0:
+    addi sp, sp, -112
+    sd ra, 104(sp)
+    sd s0, 96(sp)
+    sd s1, 88(sp)
+    sd s2, 80(sp)
+    sd s3, 72(sp)
+    sd s4, 64(sp)
+    sd s5, 56(sp)
+    sd s6, 48(sp)
+    sd s7, 40(sp)
+    sd s8, 32(sp)
+    sd s9, 24(sp)
+    sd s10, 16(sp)
+    sd s11, 8(sp)
+    addi s0, sp, 112  # 帧指针
+    mv s1, a0  # self地址
+    # ---- 函数开始 ----
+    ld t0, 0(s1)
+    ld t1, 0(s1)
+    mul t0, t0, t1
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -112
+    ld ra, 104(sp)
+    ld s0, 96(sp)
+    ld s1, 88(sp)
+    ld s2, 80(sp)
+    ld s3, 72(sp)
+    ld s4, 64(sp)
+    ld s5, 56(sp)
+    ld s6, 48(sp)
+    ld s7, 40(sp)
+    ld s8, 32(sp)
+    ld s9, 24(sp)
+    ld s10, 16(sp)
+    ld s11, 8(sp)
+    addi sp, sp, 112
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: Square_Grow1
+Square_Grow1:
+    addi sp, sp, -112
+    sd ra, 104(sp)
+    sd s0, 96(sp)
+    sd s1, 88(sp)
+    sd s2, 80(sp)
+    sd s3, 72(sp)
+    sd s4, 64(sp)
+    sd s5, 56(sp)
+    sd s6, 48(sp)
+    sd s7, 40(sp)
+    sd s8, 32(sp)
+    sd s9, 24(sp)
+    sd s10, 16(sp)
+    sd s11, 8(sp)
+    addi s0, sp, 112  # 帧指针
+    mv s1, a0  # self地址
+    addi sp, sp, -16
+    sd a1, -120(s0)
+    # ---- 函数开始 ----
+    ld t0, 0(s1)
+    ld t1, -120(s0)
+    add t0, t0, t1
+    sd t0, 0(s1)
+    # ---- 退出函数 ----
+    addi sp, s0, -112
+    ld ra, 104(sp)
+    ld s0, 96(sp)
+    ld s1, 88(sp)
+    ld s2, 80(sp)
+    ld s3, 72(sp)
+    ld s4, 64(sp)
+    ld s5, 56(sp)
+    ld s6, 48(sp)
+    ld s7, 40(sp)
+    ld s8, 32(sp)
+    ld s9, 24(sp)
+    ld s10, 16(sp)
+    ld s11, 8(sp)
+    addi sp, sp, 112
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: Measure1
+Measure1:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -16
+    sd a0, -112(s0)
+    sd a1, -104(s0)
+    # ---- 函数开始 ----
+    addi sp, sp, -16  # 参数暂存区
+    ld t0, -112(s0)
+    sd t0, 0(sp)
+    li t0, 1
+    sd t0, 8(sp)
+    ld a0, 0(sp)
+    ld a1, 8(sp)
+    ld t6, -104(s0)
+    ld t6, 8(t6)
+    jalr t6  # 动态分派Shape_Grow
+    addi sp, sp, 16
+    addi sp, sp, -16  # 参数暂存区
+    ld t0, -112(s0)
+    sd t0, 0(sp)
+    ld a0, 0(sp)
+    ld t6, -104(s0)
+    ld t6, 0(t6)
+    jalr t6  # 动态分派Shape_Area
+    addi sp, sp, 16
+    mv t0, a0  # 返回值
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: main
+main:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -64
+    # ---- 函数开始 ----
+    sd zero, -112(s0)
+    sd zero, -104(s0)
+    li t0, 2
+    sd t0, -112(s0)
+    li t0, 3
+    sd t0, -104(s0)
+    sd zero, -120(s0)
+    li t0, 2
+    sd t0, -120(s0)
+    addi t0, s0, -112
+    addi t1, s0, -136
+    sd t0, 0(t1)
+    la t6, vtable_Rect_Shape
+    sd t6, 8(t1)
+    addi sp, sp, -16  # 参数暂存区
+    ld t0, -136(s0)
+    sd t0, 0(sp)
+    ld a0, 0(sp)
+    ld t6, -128(s0)
+    ld t6, 0(t6)
+    jalr t6  # 动态分派Shape_Area
+    addi sp, sp, 16
+    mv t0, a0  # 返回值
+    sd t0, -144(s0)
+    addi t0, s0, -120
+    addi t1, s0, -136
+    sd t0, 0(t1)
+    la t6, vtable_Square_Shape
+    sd t6, 8(t1)
+    addi sp, sp, -16  # 参数暂存区
+    ld t0, -136(s0)
+    sd t0, 0(sp)
+    ld a0, 0(sp)
+    ld t6, -128(s0)
+    ld t6, 0(t6)
+    jalr t6  # 动态分派Shape_Area
+    addi sp, sp, 16
+    mv t0, a0  # 返回值
+    sd t0, -152(s0)
+    addi sp, sp, -16  # 参数暂存区
+    addi t0, s0, -112
+    sd t0, 0(sp)
+    la t6, vtable_Rect_Shape
+    sd t6, 8(sp)
+    ld a0, 0(sp)
+    ld a1, 8(sp)
+    call Measure1
+    addi sp, sp, 16
+    mv t0, a0  # 返回值
+    sd t0, -160(s0)
+    ld t0, -144(s0)
+    ld t1, -152(s0)
+    add t0, t0, t1
+    ld t1, -160(s0)
+    add t0, t0, t1
+    addi sp, sp, -16  # 保存临时寄存器
+    sd t0, 0(sp)
+    addi sp, sp, -16  # 参数暂存区
+    addi t1, s0, -120
+    sd t1, 0(sp)
+    la t6, vtable_Square_Shape
+    sd t6, 8(sp)
+    ld a0, 0(sp)
+    ld a1, 8(sp)
+    call Measure1
+    addi sp, sp, 16
+    mv t1, a0  # 返回值
+    ld t0, 0(sp)
+    addi sp, sp, 16  # 恢复临时寄存器
+    add t0, t0, t1
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# 程序入口点 (ELF入口)
+_start:
+    .option push
+    .option norelax
+    la gp, __global_pointer$
+    .option pop
+    call main
+    # 返回值在a0中，即为退出码
+    li a7, 93  # exit
+    ecall
+
+.section .rodata
+.balign 8
+vtable_Rect_Shape:
+.dword Rect_Area0
+.dword Rect_Grow1
+.balign 8
+vtable_Square_Shape:
+.dword Square_Area0
+.dword Square_Grow1
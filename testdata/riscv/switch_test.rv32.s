@@ -0,0 +1,383 @@
+# 由 cuteify 生成的 RISC-V 汇编（rv32），使用 GNU as 汇编
+.text
+.globl _start
+
+# ==============================
+# Function: classify1
+classify1:
+    addi sp, sp, -48
+    sw ra, 44(sp)
+    sw s0, 40(sp)
+    sw s2, 36(sp)
+    sw s3, 32(sp)
+    sw s4, 28(sp)
+    sw s5, 24(sp)
+    sw s6, 20(sp)
+    sw s7, 16(sp)
+    sw s8, 12(sp)
+    sw s9, 8(sp)
+    sw s10, 4(sp)
+    sw s11, 0(sp)
+    addi s0, sp, 48  # 帧指针
+    addi sp, sp, -16
+    sw a0, -52(s0)
+    # ---- 函数开始 ----
+    # ---- switch开始 ----
+    lw t0, -52(s0)
+    li t6, 0
+    beq t0, t6, switch_1_case_0
+    li t6, 1
+    beq t0, t6, switch_1_case_1
+    li t6, 2
+    beq t0, t6, switch_1_case_1
+    li t6, 3
+    beq t0, t6, switch_1_case_2
+    li t6, 5
+    beq t0, t6, switch_1_case_3
+    li t6, -3
+    beq t0, t6, switch_1_case_4
+    li t6, -1
+    beq t0, t6, switch_1_case_4
+    j switch_1_default
+    switch_1_case_0:
+    li t0, 10
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -48
+    lw ra, 44(sp)
+    lw s0, 40(sp)
+    lw s2, 36(sp)
+    lw s3, 32(sp)
+    lw s4, 28(sp)
+    lw s5, 24(sp)
+    lw s6, 20(sp)
+    lw s7, 16(sp)
+    lw s8, 12(sp)
+    lw s9, 8(sp)
+    lw s10, 4(sp)
+    lw s11, 0(sp)
+    addi sp, sp, 48
+    ret
+    j switch_1_end
+    switch_1_case_1:
+    li t0, 20
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -48
+    lw ra, 44(sp)
+    lw s0, 40(sp)
+    lw s2, 36(sp)
+    lw s3, 32(sp)
+    lw s4, 28(sp)
+    lw s5, 24(sp)
+    lw s6, 20(sp)
+    lw s7, 16(sp)
+    lw s8, 12(sp)
+    lw s9, 8(sp)
+    lw s10, 4(sp)
+    lw s11, 0(sp)
+    addi sp, sp, 48
+    ret
+    j switch_1_end
+    switch_1_case_2:
+    li t0, 30
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -48
+    lw ra, 44(sp)
+    lw s0, 40(sp)
+    lw s2, 36(sp)
+    lw s3, 32(sp)
+    lw s4, 28(sp)
+    lw s5, 24(sp)
+    lw s6, 20(sp)
+    lw s7, 16(sp)
+    lw s8, 12(sp)
+    lw s9, 8(sp)
+    lw s10, 4(sp)
+    lw s11, 0(sp)
+    addi sp, sp, 48
+    ret
+    j switch_1_end
+    switch_1_case_3:
+    li t0, 50
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -48
+    lw ra, 44(sp)
+    lw s0, 40(sp)
+    lw s2, 36(sp)
+    lw s3, 32(sp)
+    lw s4, 28(sp)
+    lw s5, 24(sp)
+    lw s6, 20(sp)
+    lw s7, 16(sp)
+    lw s8, 12(sp)
+    lw s9, 8(sp)
+    lw s10, 4(sp)
+    lw s11, 0(sp)
+    addi sp, sp, 48
+    ret
+    j switch_1_end
+    switch_1_case_4:
+    li t0, 7
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -48
+    lw ra, 44(sp)
+    lw s0, 40(sp)
+    lw s2, 36(sp)
+    lw s3, 32(sp)
+    lw s4, 28(sp)
+    lw s5, 24(sp)
+    lw s6, 20(sp)
+    lw s7, 16(sp)
+    lw s8, 12(sp)
+    lw s9, 8(sp)
+    lw s10, 4(sp)
+    lw s11, 0(sp)
+    addi sp, sp, 48
+    ret
+    j switch_1_end
+    switch_1_default:
+    li t0, 0
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -48
+    lw ra, 44(sp)
+    lw s0, 40(sp)
+    lw s2, 36(sp)
+    lw s3, 32(sp)
+    lw s4, 28(sp)
+    lw s5, 24(sp)
+    lw s6, 20(sp)
+    lw s7, 16(sp)
+    lw s8, 12(sp)
+    lw s9, 8(sp)
+    lw s10, 4(sp)
+    lw s11, 0(sp)
+    addi sp, sp, 48
+    ret
+    j switch_1_end
+    switch_1_end:  # switch结束
+    li t0, 0
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -48
+    lw ra, 44(sp)
+    lw s0, 40(sp)
+    lw s2, 36(sp)
+    lw s3, 32(sp)
+    lw s4, 28(sp)
+    lw s5, 24(sp)
+    lw s6, 20(sp)
+    lw s7, 16(sp)
+    lw s8, 12(sp)
+    lw s9, 8(sp)
+    lw s10, 4(sp)
+    lw s11, 0(sp)
+    addi sp, sp, 48
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: main
+main:
+    addi sp, sp, -48
+    sw ra, 44(sp)
+    sw s0, 40(sp)
+    sw s2, 36(sp)
+    sw s3, 32(sp)
+    sw s4, 28(sp)
+    sw s5, 24(sp)
+    sw s6, 20(sp)
+    sw s7, 16(sp)
+    sw s8, 12(sp)
+    sw s9, 8(sp)
+    sw s10, 4(sp)
+    sw s11, 0(sp)
+    addi s0, sp, 48  # 帧指针
+    addi sp, sp, -32
+    # ---- 函数开始 ----
+    li t0, 0
+    sw t0, -52(s0)
+    li t0, 0
+    sw t0, -56(s0)
+    while_1:  # while循环开始
+    lw t0, -56(s0)
+    li t1, 8
+    slt t0, t0, t1
+    beqz t0, while_1_end  # while循环条件检查
+    lw t0, -56(s0)
+    addi t0, t0, 1
+    sw t0, -56(s0)
+    # ---- switch开始 ----
+    lw t0, -56(s0)
+    li t6, 1
+    beq t0, t6, switch_2_case_0
+    li t6, 100
+    beq t0, t6, switch_2_case_1
+    li t6, 200
+    beq t0, t6, switch_2_case_1
+    li t6, 6
+    beq t0, t6, switch_2_case_2
+    li t6, 7
+    beq t0, t6, switch_2_case_3
+    j switch_2_default
+    switch_2_case_0:
+    lw t0, -52(s0)
+    addi sp, sp, -16  # 保存临时寄存器
+    sw t0, 0(sp)
+    addi sp, sp, -16  # 参数暂存区
+    lw t1, -56(s0)
+    sw t1, 0(sp)
+    lw a0, 0(sp)
+    call classify1
+    addi sp, sp, 16
+    mv t1, a0  # 返回值
+    lw t0, 0(sp)
+    addi sp, sp, 16  # 恢复临时寄存器
+    add t0, t0, t1
+    sw t0, -52(s0)
+    j switch_2_end
+    switch_2_case_1:
+    lw t0, -52(s0)
+    addi t0, t0, 1
+    sw t0, -52(s0)
+    j switch_2_end
+    switch_2_case_2:
+    j while_1  # continue
+    j switch_2_end
+    switch_2_case_3:
+    j switch_2_end  # break
+    j switch_2_end
+    switch_2_default:
+    lw t0, -52(s0)
+    addi t0, t0, 2
+    sw t0, -52(s0)
+    j switch_2_end
+    switch_2_end:  # switch结束
+    j while_1
+    while_1_end:  # while循环结束
+    li t0, 98
+    sb t0, -57(s0)
+    # ---- switch开始 ----
+    lbu t0, -57(s0)
+    li t6, 97
+    beq t0, t6, switch_3_case_0
+    li t6, 98
+    beq t0, t6, switch_3_case_1
+    li t6, 10
+    beq t0, t6, switch_3_case_1
+    j switch_3_end
+    switch_3_case_0:
+    lw t0, -52(s0)
+    addi t0, t0, 1
+    sw t0, -52(s0)
+    j switch_3_end
+    switch_3_case_1:
+    lw t0, -52(s0)
+    addi t0, t0, 3
+    sw t0, -52(s0)
+    j switch_3_end
+    switch_3_end:  # switch结束
+    # ---- switch开始 ----
+    la t6, g_Flags
+    addi t0, t6, 0
+    lbu t0, 0(t0)
+    li t6, 1
+    beq t0, t6, switch_4_case_0
+    li t6, 0
+    beq t0, t6, switch_4_case_1
+    j switch_4_end
+    switch_4_case_0:
+    lw t0, -52(s0)
+    addi t0, t0, 100
+    sw t0, -52(s0)
+    j switch_4_end
+    switch_4_case_1:
+    lw t0, -52(s0)
+    addi t0, t0, 4
+    sw t0, -52(s0)
+    j switch_4_end
+    switch_4_end:  # switch结束
+    li t0, -1
+    sw t0, -64(s0)
+    lw t0, -52(s0)
+    addi sp, sp, -16  # 保存临时寄存器
+    sw t0, 0(sp)
+    addi sp, sp, -16  # 参数暂存区
+    lw t1, -64(s0)
+    sw t1, 0(sp)
+    lw a0, 0(sp)
+    call classify1
+    addi sp, sp, 16
+    mv t1, a0  # 返回值
+    lw t0, 0(sp)
+    addi sp, sp, 16  # 恢复临时寄存器
+    add t0, t0, t1
+    sw t0, -52(s0)
+    la t6, g_On
+    addi t0, t6, 0
+    sw t0, -68(s0)
+    # ---- switch开始 ----
+    lw t0, -68(s0)
+    lbu t0, 0(t0)
+    li t6, 1
+    beq t0, t6, switch_5_case_0
+    li t6, 0
+    beq t0, t6, switch_5_case_1
+    j switch_5_end
+    switch_5_case_0:
+    lw t0, -52(s0)
+    addi t0, t0, 5
+    sw t0, -52(s0)
+    j switch_5_end
+    switch_5_case_1:
+    lw t0, -52(s0)
+    addi t0, t0, 200
+    sw t0, -52(s0)
+    j switch_5_end
+    switch_5_end:  # switch结束
+    lw t0, -52(s0)
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -48
+    lw ra, 44(sp)
+    lw s0, 40(sp)
+    lw s2, 36(sp)
+    lw s3, 32(sp)
+    lw s4, 28(sp)
+    lw s5, 24(sp)
+    lw s6, 20(sp)
+    lw s7, 16(sp)
+    lw s8, 12(sp)
+    lw s9, 8(sp)
+    lw s10, 4(sp)
+    lw s11, 0(sp)
+    addi sp, sp, 48
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# 程序入口点 (ELF入口)
+_start:
+    .option push
+    .option norelax
+    la gp, __global_pointer$
+    .option pop
+    call main
+    # 返回值在a0中，即为退出码
+    li a7, 93  # exit
+    ecall
+
+.data
+.balign 1
+g_On:
+.byte 1
+.bss
+.balign 1
+g_Flags:
+.zero 2
@@ -0,0 +1,404 @@
+# 由 cuteify 生成的 RISC-V 汇编（rv64），使用 GNU as 汇编
+.text
+.globl _start
+
+# ==============================
+# Function: classify1
+classify1:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -16
+    sd a0, -104(s0)
+    # ---- 函数开始 ----
+    # ---- switch开始 ----
+    lw t0, -104(s0)
+    li t6, 0
+    beq t0, t6, switch_1_case_0
+    li t6, 1
+    beq t0, t6, switch_1_case_1
+    li t6, 2
+    beq t0, t6, switch_1_case_1
+    li t6, 3
+    beq t0, t6, switch_1_case_2
+    li t6, 5
+    beq t0, t6, switch_1_case_3
+    li t6, -3
+    beq t0, t6, switch_1_case_4
+    li t6, -1
+    beq t0, t6, switch_1_case_4
+    j switch_1_default
+    switch_1_case_0:
+    li t0, 10
+    sext.w t0, t0  # 截断为i32
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+    j switch_1_end
+    switch_1_case_1:
+    li t0, 20
+    sext.w t0, t0  # 截断为i32
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+    j switch_1_end
+    switch_1_case_2:
+    li t0, 30
+    sext.w t0, t0  # 截断为i32
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+    j switch_1_end
+    switch_1_case_3:
+    li t0, 50
+    sext.w t0, t0  # 截断为i32
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+    j switch_1_end
+    switch_1_case_4:
+    li t0, 7
+    sext.w t0, t0  # 截断为i32
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+    j switch_1_end
+    switch_1_default:
+    li t0, 0
+    sext.w t0, t0  # 截断为i32
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+    j switch_1_end
+    switch_1_end:  # switch结束
+    li t0, 0
+    sext.w t0, t0  # 截断为i32
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# Function: main
+main:
+    addi sp, sp, -96
+    sd ra, 88(sp)
+    sd s0, 80(sp)
+    sd s2, 72(sp)
+    sd s3, 64(sp)
+    sd s4, 56(sp)
+    sd s5, 48(sp)
+    sd s6, 40(sp)
+    sd s7, 32(sp)
+    sd s8, 24(sp)
+    sd s9, 16(sp)
+    sd s10, 8(sp)
+    sd s11, 0(sp)
+    addi s0, sp, 96  # 帧指针
+    addi sp, sp, -32
+    # ---- 函数开始 ----
+    li t0, 0
+    sext.w t0, t0  # 截断为i32
+    sw t0, -100(s0)
+    li t0, 0
+    sext.w t0, t0  # 截断为i32
+    sw t0, -104(s0)
+    while_1:  # while循环开始
+    lw t0, -104(s0)
+    li t1, 8
+    slt t0, t0, t1
+    beqz t0, while_1_end  # while循环条件检查
+    lw t0, -104(s0)
+    addi t0, t0, 1
+    sext.w t0, t0  # 截断为i32
+    sw t0, -104(s0)
+    # ---- switch开始 ----
+    lw t0, -104(s0)
+    li t6, 1
+    beq t0, t6, switch_2_case_0
+    li t6, 100
+    beq t0, t6, switch_2_case_1
+    li t6, 200
+    beq t0, t6, switch_2_case_1
+    li t6, 6
+    beq t0, t6, switch_2_case_2
+    li t6, 7
+    beq t0, t6, switch_2_case_3
+    j switch_2_default
+    switch_2_case_0:
+    lw t0, -100(s0)
+    addi sp, sp, -16  # 保存临时寄存器
+    sd t0, 0(sp)
+    addi sp, sp, -16  # 参数暂存区
+    lw t1, -104(s0)
+    sd t1, 0(sp)
+    ld a0, 0(sp)
+    call classify1
+    addi sp, sp, 16
+    mv t1, a0  # 返回值
+    ld t0, 0(sp)
+    addi sp, sp, 16  # 恢复临时寄存器
+    add t0, t0, t1
+    sext.w t0, t0  # 截断为i32
+    sw t0, -100(s0)
+    j switch_2_end
+    switch_2_case_1:
+    lw t0, -100(s0)
+    addi t0, t0, 1
+    sext.w t0, t0  # 截断为i32
+    sw t0, -100(s0)
+    j switch_2_end
+    switch_2_case_2:
+    j while_1  # continue
+    j switch_2_end
+    switch_2_case_3:
+    j switch_2_end  # break
+    j switch_2_end
+    switch_2_default:
+    lw t0, -100(s0)
+    addi t0, t0, 2
+    sext.w t0, t0  # 截断为i32
+    sw t0, -100(s0)
+    j switch_2_end
+    switch_2_end:  # switch结束
+    j while_1
+    while_1_end:  # while循环结束
+    li t0, 98
+    sb t0, -105(s0)
+    # ---- switch开始 ----
+    lbu t0, -105(s0)
+    li t6, 97
+    beq t0, t6, switch_3_case_0
+    li t6, 98
+    beq t0, t6, switch_3_case_1
+    li t6, 10
+    beq t0, t6, switch_3_case_1
+    j switch_3_end
+    switch_3_case_0:
+    lw t0, -100(s0)
+    addi t0, t0, 1
+    sext.w t0, t0  # 截断为i32
+    sw t0, -100(s0)
+    j switch_3_end
+    switch_3_case_1:
+    lw t0, -100(s0)
+    addi t0, t0, 3
+    sext.w t0, t0  # 截断为i32
+    sw t0, -100(s0)
+    j switch_3_end
+    switch_3_end:  # switch结束
+    # ---- switch开始 ----
+    la t6, g_Flags
+    addi t0, t6, 0
+    lbu t0, 0(t0)
+    li t6, 1
+    beq t0, t6, switch_4_case_0
+    li t6, 0
+    beq t0, t6, switch_4_case_1
+    j switch_4_end
+    switch_4_case_0:
+    lw t0, -100(s0)
+    addi t0, t0, 100
+    sext.w t0, t0  # 截断为i32
+    sw t0, -100(s0)
+    j switch_4_end
+    switch_4_case_1:
+    lw t0, -100(s0)
+    addi t0, t0, 4
+    sext.w t0, t0  # 截断为i32
+    sw t0, -100(s0)
+    j switch_4_end
+    switch_4_end:  # switch结束
+    li t0, -1
+    sext.w t0, t0  # 截断为i32
+    sw t0, -112(s0)
+    lw t0, -100(s0)
+    addi sp, sp, -16  # 保存临时寄存器
+    sd t0, 0(sp)
+    addi sp, sp, -16  # 参数暂存区
+    lw t1, -112(s0)
+    sd t1, 0(sp)
+    ld a0, 0(sp)
+    call classify1
+    addi sp, sp, 16
+    mv t1, a0  # 返回值
+    ld t0, 0(sp)
+    addi sp, sp, 16  # 恢复临时寄存器
+    add t0, t0, t1
+    sext.w t0, t0  # 截断为i32
+    sw t0, -100(s0)
+    la t6, g_On
+    addi t0, t6, 0
+    sd t0, -120(s0)
+    # ---- switch开始 ----
+    ld t0, -120(s0)
+    lbu t0, 0(t0)
+    li t6, 1
+    beq t0, t6, switch_5_case_0
+    li t6, 0
+    beq t0, t6, switch_5_case_1
+    j switch_5_end
+    switch_5_case_0:
+    lw t0, -100(s0)
+    addi t0, t0, 5
+    sext.w t0, t0  # 截断为i32
+    sw t0, -100(s0)
+    j switch_5_end
+    switch_5_case_1:
+    lw t0, -100(s0)
+    addi t0, t0, 200
+    sext.w t0, t0  # 截断为i32
+    sw t0, -100(s0)
+    j switch_5_end
+    switch_5_end:  # switch结束
+    lw t0, -100(s0)
+    mv a0, t0  # 返回值存入a0
+    # ---- 退出函数 ----
+    addi sp, s0, -96
+    ld ra, 88(sp)
+    ld s0, 80(sp)
+    ld s2, 72(sp)
+    ld s3, 64(sp)
+    ld s4, 56(sp)
+    ld s5, 48(sp)
+    ld s6, 40(sp)
+    ld s7, 32(sp)
+    ld s8, 24(sp)
+    ld s9, 16(sp)
+    ld s10, 8(sp)
+    ld s11, 0(sp)
+    addi sp, sp, 96
+    ret
+# ======函数完毕=======
+
+
+# ==============================
+# 程序入口点 (ELF入口)
+_start:
+    .option push
+    .option norelax
+    la gp, __global_pointer$
+    .option pop
+    call main
+    # 返回值在a0中，即为退出码
+    li a7, 93  # exit
+    ecall
+
+.data
+.balign 1
+g_On:
+.byte 1
+.bss
+.balign 1
+g_Flags:
+.zero 2
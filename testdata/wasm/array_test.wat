@@ -0,0 +1,302 @@
+;; 由 cuteify 生成的 WebAssembly 文本格式模块
+(module
+
+    (func $sum1 (param $s i32) (result i32)
+        (local $fp i32)
+        (local $total i32)
+        (local $i i32)
+        global.get $sp
+        i32.const 8
+        i32.sub
+        local.tee $fp
+        global.set $sp
+        local.get $fp
+        i32.const 0
+        i32.const 8
+        memory.fill
+        local.get $fp
+        local.get $s
+        i32.const 8
+        memory.copy
+        i32.const 0
+        local.set $total
+        i32.const 0
+        local.set $i
+        block $while_1_end
+            loop $while_1
+                local.get $i
+                local.get $fp
+                i32.load offset=4
+                i32.lt_s
+                i32.eqz
+                br_if $while_1_end
+                local.get $total
+                local.get $fp
+                i32.load
+                local.get $i
+                i32.const 4
+                i32.mul
+                i32.add
+                i32.load
+                i32.add
+                local.set $total
+                local.get $i
+                i32.const 1
+                i32.add
+                local.set $i
+                br $while_1
+            end
+        end
+        local.get $total
+        local.get $fp
+        i32.const 8
+        i32.add
+        global.set $sp
+        return
+    )
+
+    (func $fill2 (param $buf i32) (param $c i32)
+        (local $fp i32)
+        (local $i i32)
+        global.get $sp
+        i32.const 8
+        i32.sub
+        local.tee $fp
+        global.set $sp
+        local.get $fp
+        i32.const 0
+        i32.const 8
+        memory.fill
+        local.get $fp
+        local.get $buf
+        i32.const 8
+        memory.copy
+        i32.const 0
+        local.set $i
+        block $while_2_end
+            loop $while_2
+                local.get $i
+                local.get $fp
+                i32.load offset=4
+                i32.lt_s
+                i32.eqz
+                br_if $while_2_end
+                local.get $fp
+                i32.load
+                local.get $i
+                i32.add
+                local.get $c
+                i32.const 255
+                i32.and
+                i32.store8
+                local.get $i
+                i32.const 1
+                i32.add
+                local.set $i
+                br $while_2
+            end
+        end
+        local.get $fp
+        i32.const 8
+        i32.add
+        global.set $sp
+    )
+
+    (func $main (result i32)
+        (local $fp i32)
+        (local $i i32)
+        (local $pv i32)
+        (local $k i32)
+        (local $py i32)
+        global.get $sp
+        i32.const 80
+        i32.sub
+        local.tee $fp
+        global.set $sp
+        local.get $fp
+        i32.const 0
+        i32.const 80
+        memory.fill
+        i32.const 0
+        local.set $i
+        block $while_3_end
+            loop $while_3
+                local.get $i
+                i32.const 5
+                i32.lt_s
+                i32.eqz
+                br_if $while_3_end
+                local.get $fp
+                local.get $i
+                i32.const 4
+                i32.mul
+                i32.add
+                local.get $i
+                i32.const 2
+                i32.mul
+                i32.store
+                local.get $i
+                i32.const 1
+                i32.add
+                local.set $i
+                br $while_3
+            end
+        end
+        local.get $fp
+        local.get $fp
+        i32.const 20
+        i32.add
+        i32.store offset=64
+        local.get $fp
+        i32.const 3
+        i32.store offset=68
+        local.get $fp
+        i32.const 64
+        i32.add
+        i32.const 4
+        i32.const 255
+        i32.and
+        call $fill2
+        local.get $fp
+        i32.const 24
+        i32.add
+        i32.const 12
+        i32.add
+        i32.const 9
+        i32.store offset=8
+        local.get $fp
+        i32.const 24
+        i32.add
+        local.get $fp
+        i32.const 24
+        i32.add
+        i32.const 12
+        i32.add
+        i32.load offset=8
+        i32.const 1
+        i32.add
+        i32.store offset=4
+        local.get $fp
+        i32.const 48
+        i32.add
+        i32.const 4
+        i32.add
+        local.set $pv
+        i32.const 1
+        local.set $k
+        local.get $fp
+        i32.const 48
+        i32.add
+        local.get $k
+        i32.const 4
+        i32.mul
+        i32.add
+        i32.const 2
+        i32.extend16_s
+        i32.store16
+        local.get $fp
+        i32.const 48
+        i32.add
+        local.get $k
+        i32.const 4
+        i32.mul
+        i32.add
+        local.get $fp
+        i32.const 48
+        i32.add
+        local.get $k
+        i32.const 4
+        i32.mul
+        i32.add
+        i32.load16_s
+        i32.const 1
+        i32.add
+        i32.extend16_s
+        i32.store16 offset=2
+        local.get $fp
+        i32.const 48
+        i32.add
+        local.get $k
+        i32.const 4
+        i32.mul
+        i32.add
+        i32.const 2
+        i32.add
+        local.set $py
+        local.get $py
+        local.get $py
+        i32.load16_s
+        i32.const 2
+        i32.mul
+        i32.extend16_s
+        i32.store16
+        i32.const 16
+        i32.const 6
+        i32.store offset=12
+        local.get $fp
+        local.get $fp
+        i32.store offset=56
+        local.get $fp
+        i32.const 5
+        i32.store offset=60
+        local.get $fp
+        i32.const 56
+        i32.add
+        i32.load
+        i32.const 1
+        i32.store
+        local.get $pv
+        i32.const 0
+        i32.eq
+        if ;; if_1
+            i32.const 1
+            local.get $fp
+            i32.const 80
+            i32.add
+            global.set $sp
+            return
+        end
+        local.get $fp
+        i32.const 56
+        i32.add
+        call $sum1
+        local.get $fp
+        i32.const 16
+        i32.store offset=72
+        local.get $fp
+        i32.const 4
+        i32.store offset=76
+        local.get $fp
+        i32.const 72
+        i32.add
+        call $sum1
+        i32.add
+        local.get $fp
+        i32.const 20
+        i32.add
+        i32.load8_u offset=2
+        i32.add
+        local.get $fp
+        i32.const 24
+        i32.add
+        i32.load offset=4
+        i32.add
+        i32.const 2
+        i32.add
+        local.get $fp
+        i32.const 48
+        i32.add
+        i32.load16_s offset=6
+        i32.add
+        local.get $fp
+        i32.const 80
+        i32.add
+        global.set $sp
+        return
+    )
+
+    (export "main" (func $main))
+
+    (memory (export "memory") 2)
+    (global $sp (mut i32) (i32.const 131072))
+)
@@ -0,0 +1,195 @@
+;; 由 cuteify 生成的 WebAssembly 文本格式模块
+(module
+
+    (func $trunc1 (param $v i32) (result i32)
+        local.get $v
+        i32.const 255
+        i32.and
+        i32.const 255
+        i32.and
+        return
+    )
+
+    (func $sext1 (param $v i32) (result i32)
+        local.get $v
+        i32.extend8_s
+        return
+    )
+
+    (func $widen1 (param $v i32) (result i32)
+        local.get $v
+        return
+    )
+
+    (func $low1 (param $v i32) (result i32)
+        local.get $v
+        i32.const 255
+        i32.and
+        i32.const 255
+        i32.and
+        return
+    )
+
+    (func $signed1 (param $v i32) (result i32)
+        local.get $v
+        i32.extend8_s
+        i32.extend8_s
+        return
+    )
+
+    (func $unsigned1 (param $v i32) (result i32)
+        local.get $v
+        return
+    )
+
+    (func $wrap1 (param $v i32) (result i32)
+        local.get $v
+        local.get $v
+        i32.add
+        i32.const 255
+        i32.and
+        return
+    )
+
+    (func $bump1 (param $addr i32)
+        (local $p i32)
+        local.get $addr
+        local.set $p
+        local.get $p
+        local.get $p
+        i32.load
+        i32.const 1
+        i32.add
+        i32.store
+    )
+
+    (func $digit1 (param $c i32) (result i32)
+        local.get $c
+        i32.const 48
+        i32.add
+        i32.const 255
+        i32.and
+        return
+    )
+
+    (func $main (result i32)
+        (local $fp i32)
+        (local $neg i32)
+        global.get $sp
+        i32.const 8
+        i32.sub
+        local.tee $fp
+        global.set $sp
+        local.get $fp
+        i32.const 0
+        i32.const 8
+        memory.fill
+        i32.const -1
+        i32.extend16_s
+        call $low1
+        i32.const 255
+        i32.ne
+        if ;; if_1
+            i32.const 1
+            local.get $fp
+            i32.const 8
+            i32.add
+            global.set $sp
+            return
+        end
+        i32.const 255
+        i32.const 255
+        i32.and
+        call $signed1
+        i32.const 1
+        i32.add
+        i32.const 0
+        i32.ne
+        if ;; if_2
+            i32.const 2
+            local.get $fp
+            i32.const 8
+            i32.add
+            global.set $sp
+            return
+        end
+        i32.const -1
+        i32.extend8_s
+        call $unsigned1
+        i32.const -1
+        i32.ne
+        if ;; if_3
+            i32.const 3
+            local.get $fp
+            i32.const 8
+            i32.add
+            global.set $sp
+            return
+        end
+        i32.const 200
+        i32.const 255
+        i32.and
+        call $wrap1
+        i32.const 144
+        i32.ne
+        if ;; if_4
+            i32.const 4
+            local.get $fp
+            i32.const 8
+            i32.add
+            global.set $sp
+            return
+        end
+        local.get $fp
+        i32.const 7
+        i32.store
+        local.get $fp
+        call $bump1
+        i32.const -56
+        i32.extend8_s
+        local.set $neg
+        local.get $neg
+        i32.const 56
+        i32.add
+        i32.const 0
+        i32.ne
+        if ;; if_5
+            i32.const 5
+            local.get $fp
+            i32.const 8
+            i32.add
+            global.set $sp
+            return
+        end
+        i32.const 300
+        call $trunc1
+        i32.const 200
+        call $sext1
+        i32.add
+        i32.const 200
+        i32.const 255
+        i32.and
+        call $widen1
+        i32.add
+        i32.const 1
+        i32.const 255
+        i32.and
+        call $digit1
+        i32.add
+        local.get $fp
+        i32.load
+        i32.add
+        i32.const 150
+        i32.sub
+        local.get $fp
+        i32.const 8
+        i32.add
+        global.set $sp
+        return
+    )
+
+    (export "main" (func $main))
+
+    (memory (export "memory") 2)
+    (global $sp (mut i32) (i32.const 131072))
+)
@@ -0,0 +1,66 @@
+;; 由 cuteify 生成的 WebAssembly 文本格式模块
+(module
+
+    (func $Counter_Tick0 (param $self i32)
+        local.get $self
+        local.get $self
+        i32.load offset=4
+        local.get $self
+        i32.load
+        i32.add
+        i32.store offset=4
+    )
+
+    (func $bump1 (param $k i32)
+        i32.const 28
+        i32.const 28
+        i32.load
+        local.get $k
+        i32.add
+        i32.store
+    )
+
+    (func $main (result i32)
+        (local $s i32)
+        (local $before i32)
+        i32.const 40
+        local.set $s
+        i32.const 16
+        call $Counter_Tick0
+        i32.const 16
+        call $Counter_Tick0
+        i32.const 24
+        i32.load
+        call $bump1
+        i32.const 28
+        i32.load
+        local.set $before
+        i32.const 1
+        call $bump1
+        i32.const 16
+        i32.load offset=4
+        i32.const 28
+        i32.load
+        i32.add
+        local.get $before
+        i32.sub
+        i32.const 33
+        i32.load8_u
+        i32.add
+        i32.const 65
+        i32.sub
+        return
+    )
+
+    (export "main" (func $main))
+
+    (memory (export "memory") 2)
+    (global $sp (mut i32) (i32.const 131072))
+    (data (i32.const 16) "\02\00\00\00\00\00\00\00") ;; g_Count
+    (data (i32.const 24) "(\00\00\00") ;; g_Base
+    (data (i32.const 32) "\01") ;; g_Flag
+    (data (i32.const 33) "A") ;; g_Initial
+    (data (i32.const 36) ".\00\00\00") ;; g_Greeting
+    (data (i32.const 40) "hello\00") ;; str_1
+    (data (i32.const 46) "hi\0a\00") ;; str_0
+)
@@ -0,0 +1,188 @@
+;; 由 cuteify 生成的 WebAssembly 文本格式模块
+(module
+
+    (func $Rect_Area0 (param $self i32) (result i32)
+        local.get $self
+        i32.load
+        local.get $self
+        i32.load offset=4
+        i32.mul
+        return
+    )
+
+    (func $Rect_Grow1 (param $self i32) (param $k i32)
+        local.get $self
+        local.get $self
+        i32.load
+        local.get $k
+        i32.add
+        i32.store
+        local.get $self
+        local.get $self
+        i32.load offset=4
+        local.get $k
+        i32.add
+        i32.store offset=4
+    )
+
+    (func $Square_Area0 (param $self i32) (result i32)
+        local.get $self
+        i32.load
+        local.get $self
+        i32.load
+        i32.mul
+        return
+    )
+
+    (func $Square_Grow1 (param $self i32) (param $k i32)
+        local.get $self
+        local.get $self
+        i32.load
+        local.get $k
+        i32.add
+        i32.store
+    )
+
+    (func $Measure1 (param $s i32) (result i32)
+        (local $fp i32)
+        global.get $sp
+        i32.const 8
+        i32.sub
+        local.tee $fp
+        global.set $sp
+        local.get $fp
+        i32.const 0
+        i32.const 8
+        memory.fill
+        local.get $fp
+        local.get $s
+        i32.const 8
+        memory.copy
+        local.get $fp
+        i32.load
+        i32.const 1
+        local.get $fp
+        i32.load offset=4
+        i32.load offset=4
+        call_indirect (type $sig_i32_i32)
+        local.get $fp
+        i32.load
+        local.get $fp
+        i32.load offset=4
+        i32.load
+        call_indirect (type $sig_i32_to_i32)
+        local.get $fp
+        i32.const 8
+        i32.add
+        global.set $sp
+        return
+    )
+
+    (func $main (result i32)
+        (local $fp i32)
+        (local $a i32)
+        (local $b i32)
+        (local $c i32)
+        global.get $sp
+        i32.const 40
+        i32.sub
+        local.tee $fp
+        global.set $sp
+        local.get $fp
+        i32.const 0
+        i32.const 40
+        memory.fill
+        local.get $fp
+        i32.const 0
+        i32.const 8
+        memory.fill
+        local.get $fp
+        i32.const 2
+        i32.store
+        local.get $fp
+        i32.const 3
+        i32.store offset=4
+        local.get $fp
+        i32.const 8
+        i32.add
+        i32.const 0
+        i32.const 4
+        memory.fill
+        local.get $fp
+        i32.const 2
+        i32.store offset=8
+        local.get $fp
+        local.get $fp
+        i32.store offset=12
+        local.get $fp
+        i32.const 16
+        i32.store offset=16
+        local.get $fp
+        i32.load offset=12
+        local.get $fp
+        i32.load offset=16
+        i32.load
+        call_indirect (type $sig_i32_to_i32)
+        local.set $a
+        local.get $fp
+        local.get $fp
+        i32.const 8
+        i32.add
+        i32.store offset=12
+        local.get $fp
+        i32.const 24
+        i32.store offset=16
+        local.get $fp
+        i32.load offset=12
+        local.get $fp
+        i32.load offset=16
+        i32.load
+        call_indirect (type $sig_i32_to_i32)
+        local.set $b
+        local.get $fp
+        local.get $fp
+        i32.store offset=20
+        local.get $fp
+        i32.const 16
+        i32.store offset=24
+        local.get $fp
+        i32.const 20
+        i32.add
+        call $Measure1
+        local.set $c
+        local.get $a
+        local.get $b
+        i32.add
+        local.get $c
+        i32.add
+        local.get $fp
+        local.get $fp
+        i32.const 8
+        i32.add
+        i32.store offset=28
+        local.get $fp
+        i32.const 24
+        i32.store offset=32
+        local.get $fp
+        i32.const 28
+        i32.add
+        call $Measure1
+        i32.add
+        local.get $fp
+        i32.const 40
+        i32.add
+        global.set $sp
+        return
+    )
+
+    (export "main" (func $main))
+
+    (type $sig_i32_i32 (func (param i32 i32)))
+    (type $sig_i32_to_i32 (func (param i32) (result i32)))
+    (table 4 funcref)
+    (elem (i32.const 0) func $Rect_Area0 $Rect_Grow1 $Square_Area0 $Square_Grow1)
+    (memory (export "memory") 2)
+    (global $sp (mut i32) (i32.const 131072))
+    (data (i32.const 16) "\00\00\00\00\01\00\00\00") ;; vtable_Rect_Shape
+    (data (i32.const 24) "\02\00\00\00\03\00\00\00") ;; vtable_Square_Shape
+)
@@ -0,0 +1,325 @@
+;; 由 cuteify 生成的 WebAssembly 文本格式模块
+(module
+
+    (func $classify1 (param $op i32) (result i32)
+        (local $switch i32)
+        local.get $op
+        local.set $switch
+        block $switch_1_end
+            block $switch_1_case_0
+                local.get $switch
+                i32.const 0
+                i32.eq
+                i32.eqz
+                br_if $switch_1_case_0
+                i32.const 10
+                return
+                br $switch_1_end
+            end
+            block $switch_1_case_1
+                local.get $switch
+                i32.const 1
+                i32.eq
+                local.get $switch
+                i32.const 2
+                i32.eq
+                i32.or
+                i32.eqz
+                br_if $switch_1_case_1
+                i32.const 20
+                return
+                br $switch_1_end
+            end
+            block $switch_1_case_2
+                local.get $switch
+                i32.const 3
+                i32.eq
+                i32.eqz
+                br_if $switch_1_case_2
+                i32.const 30
+                return
+                br $switch_1_end
+            end
+            block $switch_1_case_3
+                local.get $switch
+                i32.const 5
+                i32.eq
+                i32.eqz
+                br_if $switch_1_case_3
+                i32.const 50
+                return
+                br $switch_1_end
+            end
+            block $switch_1_case_4
+                local.get $switch
+                i32.const -3
+                i32.eq
+                local.get $switch
+                i32.const -1
+                i32.eq
+                i32.or
+                i32.eqz
+                br_if $switch_1_case_4
+                i32.const 7
+                return
+                br $switch_1_end
+            end
+            block $switch_1_case_5
+                local.get $switch
+                i32.const 0
+                i32.ne
+                local.get $switch
+                i32.const 1
+                i32.ne
+                i32.and
+                local.get $switch
+                i32.const 2
+                i32.ne
+                i32.and
+                local.get $switch
+                i32.const 3
+                i32.ne
+                i32.and
+                local.get $switch
+                i32.const 5
+                i32.ne
+                i32.and
+                local.get $switch
+                i32.const -3
+                i32.ne
+                i32.and
+                local.get $switch
+                i32.const -1
+                i32.ne
+                i32.and
+                i32.eqz
+                br_if $switch_1_case_5
+                i32.const 0
+                return
+                br $switch_1_end
+            end
+        end
+        i32.const 0
+        return
+    )
+
+    (func $main (result i32)
+        (local $x i32)
+        (local $i i32)
+        (local $switch i32)
+        (local $c i32)
+        (local $switch_1 i32)
+        (local $switch_2 i32)
+        (local $d i32)
+        (local $pb i32)
+        (local $switch_3 i32)
+        i32.const 0
+        local.set $x
+        i32.const 0
+        local.set $i
+        block $while_1_end
+            loop $while_1
+                local.get $i
+                i32.const 8
+                i32.lt_s
+                i32.eqz
+                br_if $while_1_end
+                local.get $i
+                i32.const 1
+                i32.add
+                local.set $i
+                local.get $i
+                local.set $switch
+                block $switch_2_end
+                    block $switch_2_case_0
+                        local.get $switch
+                        i32.const 1
+                        i32.eq
+                        i32.eqz
+                        br_if $switch_2_case_0
+                        local.get $x
+                        local.get $i
+                        call $classify1
+                        i32.add
+                        local.set $x
+                        br $switch_2_end
+                    end
+                    block $switch_2_case_1
+                        local.get $switch
+                        i32.const 100
+                        i32.eq
+                        local.get $switch
+                        i32.const 200
+                        i32.eq
+                        i32.or
+                        i32.eqz
+                        br_if $switch_2_case_1
+                        local.get $x
+                        i32.const 1
+                        i32.add
+                        local.set $x
+                        br $switch_2_end
+                    end
+                    block $switch_2_case_2
+                        local.get $switch
+                        i32.const 6
+                        i32.eq
+                        i32.eqz
+                        br_if $switch_2_case_2
+                        br $while_1
+                        br $switch_2_end
+                    end
+                    block $switch_2_case_3
+                        local.get $switch
+                        i32.const 7
+                        i32.eq
+                        i32.eqz
+                        br_if $switch_2_case_3
+                        br $switch_2_end
+                        br $switch_2_end
+                    end
+                    block $switch_2_case_4
+                        local.get $switch
+                        i32.const 1
+                        i32.ne
+                        local.get $switch
+                        i32.const 100
+                        i32.ne
+                        i32.and
+                        local.get $switch
+                        i32.const 200
+                        i32.ne
+                        i32.and
+                        local.get $switch
+                        i32.const 6
+                        i32.ne
+                        i32.and
+                        local.get $switch
+                        i32.const 7
+                        i32.ne
+                        i32.and
+                        i32.eqz
+                        br_if $switch_2_case_4
+                        local.get $x
+                        i32.const 2
+                        i32.add
+                        local.set $x
+                        br $switch_2_end
+                    end
+                end
+                br $while_1
+            end
+        end
+        i32.const 98
+        i32.const 255
+        i32.and
+        local.set $c
+        local.get $c
+        local.set $switch_1
+        block $switch_3_end
+            block $switch_3_case_0
+                local.get $switch_1
+                i32.const 97
+                i32.eq
+                i32.eqz
+                br_if $switch_3_case_0
+                local.get $x
+                i32.const 1
+                i32.add
+                local.set $x
+                br $switch_3_end
+            end
+            block $switch_3_case_1
+                local.get $switch_1
+                i32.const 98
+                i32.eq
+                local.get $switch_1
+                i32.const 10
+                i32.eq
+                i32.or
+                i32.eqz
+                br_if $switch_3_case_1
+                local.get $x
+                i32.const 3
+                i32.add
+                local.set $x
+                br $switch_3_end
+            end
+        end
+        i32.const 16
+        i32.load8_u
+        local.set $switch_2
+        block $switch_4_end
+            block $switch_4_case_0
+                local.get $switch_2
+                i32.const 1
+                i32.eq
+                i32.eqz
+                br_if $switch_4_case_0
+                local.get $x
+                i32.const 100
+                i32.add
+                local.set $x
+                br $switch_4_end
+            end
+            block $switch_4_case_1
+                local.get $switch_2
+                i32.const 0
+                i32.eq
+                i32.eqz
+                br_if $switch_4_case_1
+                local.get $x
+                i32.const 4
+                i32.add
+                local.set $x
+                br $switch_4_end
+            end
+        end
+        i32.const -1
+        local.set $d
+        local.get $x
+        local.get $d
+        call $classify1
+        i32.add
+        local.set $x
+        i32.const 18
+        local.set $pb
+        local.get $pb
+        i32.load8_u
+        local.set $switch_3
+        block $switch_5_end
+            block $switch_5_case_0
+                local.get $switch_3
+                i32.const 1
+                i32.eq
+                i32.eqz
+                br_if $switch_5_case_0
+                local.get $x
+                i32.const 5
+                i32.add
+                local.set $x
+                br $switch_5_end
+            end
+            block $switch_5_case_1
+                local.get $switch_3
+                i32.const 0
+                i32.eq
+                i32.eqz
+                br_if $switch_5_case_1
+                local.get $x
+                i32.const 200
+                i32.add
+                local.set $x
+                br $switch_5_end
+            end
+        end
+        local.get $x
+        return
+    )
+
+    (export "main" (func $main))
+
+    (memory (export "memory") 2)
+    (global $sp (mut i32) (i32.const 131072))
+    (data (i32.const 18) "\01") ;; g_On
+)
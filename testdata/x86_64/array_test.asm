@@ -0,0 +1,226 @@
+section .text
+global _start
+
+; ==============================
+; Function: sum1
+sum1:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 40; 分配栈空间(40字节)
+    mov QWORD[rbp-24], RDI; 保存参数s
+    mov QWORD[rbp-24+8], RSI; 保存参数s
+    ; ---- 函数开始 ----
+    mov QWORD[rbp-32], 0; 设置变量total
+    mov QWORD[rbp-40], 0; 设置变量i
+    while_1: ; while循环开始
+    mov RAX, QWORD[rbp-40]
+    lea RCX, [rbp-24]; 取s地址
+    mov RCX, QWORD[RCX+8]; 切片长度
+    cmp RAX, RCX
+    jnl while_1_end; 判断后跳转到目标
+    mov RAX, QWORD[rbp-32]
+    mov RCX, QWORD[rbp-40]
+    lea RDX, [rbp-24]; 取s地址
+    mov RDX, QWORD[RDX]; 切片数据地址
+    lea RDX, [RDX+RCX*8]; 元素地址
+    mov RDX, QWORD[RDX]; 读取元素
+    add RAX, RDX
+    mov QWORD[rbp-32], RAX; 设置变量total
+    mov RAX, QWORD[rbp-40]
+    add RAX, 1
+    mov QWORD[rbp-40], RAX; 设置变量i
+    jmp while_1; while循环
+    while_1_end: ; while循环结束
+    mov RAX, QWORD[rbp-32]; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 40; 清理局部变量栈空间(40字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: fill2
+fill2:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 40; 分配栈空间(40字节)
+    mov QWORD[rbp-24], RDI; 保存参数buf
+    mov QWORD[rbp-24+8], RSI; 保存参数buf
+    mov QWORD[rbp-32], RDX; 保存参数c
+    ; ---- 函数开始 ----
+    mov QWORD[rbp-40], 0; 设置变量i
+    while_2: ; while循环开始
+    mov RAX, QWORD[rbp-40]
+    lea RCX, [rbp-24]; 取buf地址
+    mov RCX, QWORD[RCX+8]; 切片长度
+    cmp RAX, RCX
+    jnl while_2_end; 判断后跳转到目标
+    mov RAX, QWORD[rbp-40]
+    lea RCX, [rbp-24]; 取buf地址
+    mov RCX, QWORD[RCX]; 切片数据地址
+    lea RCX, [RCX+RAX*1]; 元素地址
+    movzx RAX, BYTE[rbp-32]
+    mov BYTE[RCX], AL; 通过指针赋值
+    mov RAX, QWORD[rbp-40]
+    add RAX, 1
+    mov QWORD[rbp-40], RAX; 设置变量i
+    jmp while_2; while循环
+    while_2_end: ; while循环结束
+    ; ---- 退出函数 ----
+    add rsp, 40; 清理局部变量栈空间(40字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: main
+main:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 152; 分配栈空间(152字节)
+    ; ---- 函数开始 ----
+    mov QWORD[rbp-56], 0; 设置变量i
+    while_3: ; while循环开始
+    mov RAX, QWORD[rbp-56]
+    cmp RAX, 5
+    jnl while_3_end; 判断后跳转到目标
+    mov RAX, QWORD[rbp-56]
+    lea RCX, [rbp-48]; 取a地址
+    lea RCX, [RCX+RAX*8]; 元素地址
+    mov RAX, QWORD[rbp-56]
+    imul RAX, 2
+    mov QWORD[RCX], RAX; 通过指针赋值
+    mov RAX, QWORD[rbp-56]
+    add RAX, 1
+    mov QWORD[rbp-56], RAX; 设置变量i
+    jmp while_3; while循环
+    while_3_end: ; while循环结束
+    push 4; 参数1
+    push 3; 参数0长度
+    lea RAX, [rbp-59]; 取bytes地址
+    push RAX; 参数0数据地址
+    pop RDI
+    pop RSI
+    pop RDX
+    call fill2
+    lea RAX, [rbp-112]; 取grid地址
+    lea RAX, [RAX+24]; 元素地址
+    lea RAX, [RAX+16]; 元素地址
+    mov QWORD[RAX], 9; 通过指针赋值
+    lea RAX, [rbp-112]; 取grid地址
+    lea RAX, [RAX+8]; 元素地址
+    lea RCX, [rbp-112]; 取grid地址
+    lea RCX, [RCX+24]; 元素地址
+    lea RCX, [RCX+16]; 元素地址
+    mov RCX, QWORD[RCX]; 读取元素
+    add RCX, 1
+    mov QWORD[RAX], RCX; 通过指针赋值
+    lea RAX, [rbp-120]; 取vs地址
+    lea RAX, [RAX+4]; 元素地址
+    mov QWORD[rbp-128], RAX; 设置变量pv
+    mov QWORD[rbp-136], 1; 设置变量k
+    mov RAX, QWORD[rbp-136]
+    lea RCX, [rbp-120]; 取vs地址
+    lea RCX, [RCX+RAX*4]; 元素地址
+    mov WORD[RCX], 2; 通过指针赋值
+    mov RAX, QWORD[rbp-136]
+    lea RCX, [rbp-120]; 取vs地址
+    lea RCX, [RCX+RAX*4]; 元素地址
+    lea RCX, [RCX+2]; 字段地址
+    mov RAX, QWORD[rbp-136]
+    lea RDX, [rbp-120]; 取vs地址
+    lea RDX, [RDX+RAX*4]; 元素地址
+    movsx RDX, WORD[RDX]; 读取字段
+    add RDX, 1
+    mov WORD[RCX], DX; 通过指针赋值
+    mov RAX, QWORD[rbp-136]
+    lea RCX, [rbp-120]; 取vs地址
+    lea RCX, [RCX+RAX*4]; 元素地址
+    lea RCX, [RCX+2]; 字段地址
+    mov QWORD[rbp-144], RCX; 设置变量py
+    mov RAX, QWORD[rbp-144]
+    mov RCX, QWORD[rbp-144]
+    movsx RCX, WORD[RCX]; 解引用
+    imul RCX, 2
+    mov WORD[RAX], CX; 通过指针赋值
+    lea RAX, [g_Table]; 取Table地址
+    lea RAX, [RAX+24]; 元素地址
+    mov QWORD[RAX], 6; 通过指针赋值
+    lea RAX, [rbp-48]; 取a地址
+    mov QWORD[rbp-160], RAX; 切片数据地址
+    mov QWORD[rbp-160+8], 5; 切片长度
+    lea RAX, [rbp-160]; 取s地址
+    mov RAX, QWORD[RAX]; 切片数据地址
+    mov QWORD[RAX], 1; 通过指针赋值
+    mov RAX, QWORD[rbp-128]
+    cmp RAX, 0
+    jne end_if_1; 判断后跳转到目标
+    if_1:
+    mov RAX, 1; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 152; 清理局部变量栈空间(152字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+    end_if_1:
+    push QWORD[rbp-160+8]; 参数0长度
+    push QWORD[rbp-160]; 参数0数据地址
+    pop RDI
+    pop RSI
+    call sum1
+    mov RBX, RAX; 函数返回值直接移到RBX
+    push 4; 参数0长度
+    lea RAX, [g_Table]; 取Table地址
+    push RAX; 参数0数据地址
+    pop RDI
+    pop RSI
+    call sum1
+    add RBX, RAX
+    lea RAX, [rbp-59]; 取bytes地址
+    lea RAX, [RAX+2]; 元素地址
+    movzx RAX, BYTE[RAX]; 读取元素
+    add RBX, RAX
+    lea RCX, [rbp-112]; 取grid地址
+    lea RCX, [RCX+8]; 元素地址
+    mov RCX, QWORD[RCX]; 读取元素
+    add RBX, RCX
+    add RBX, 2
+    lea RDX, [rbp-120]; 取vs地址
+    lea RDX, [RDX+4]; 元素地址
+    lea RDX, [RDX+2]; 字段地址
+    movsx RDX, WORD[RDX]; 读取字段
+    add RBX, RDX
+    mov RAX, RBX; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 152; 清理局部变量栈空间(152字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; 程序入口点 (ELF入口)
+_start:
+    ; 调用main函数
+    call main
+    ; 使用系统调用退出程序 (sys_exit = 60)
+    ; 返回值在RAX中
+    mov rdi, rax; 返回码
+    mov rax, 60; sys_exit
+    syscall; 调用内核
+
+    section .bss
+    alignb 8
+    g_Table: resb 32
@@ -0,0 +1,322 @@
+section .text
+global _start
+
+; ==============================
+; Function: trunc1
+trunc1:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 8; 分配栈空间(8字节)
+    mov QWORD[rbp-16], RDI; 保存参数v
+    ; ---- 函数开始 ----
+    mov RAX, QWORD[rbp-16]
+    movzx RAX, AL; 转换为u8; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 8; 清理局部变量栈空间(8字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: sext1
+sext1:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 8; 分配栈空间(8字节)
+    mov QWORD[rbp-16], RDI; 保存参数v
+    ; ---- 函数开始 ----
+    mov RAX, QWORD[rbp-16]
+    movsx RAX, AL; 转换为i8
+    movsx RAX, AL; i8扩展为int; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 8; 清理局部变量栈空间(8字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: widen1
+widen1:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 8; 分配栈空间(8字节)
+    mov QWORD[rbp-16], RDI; 保存参数v
+    ; ---- 函数开始 ----
+    movzx RAX, BYTE[rbp-16]; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 8; 清理局部变量栈空间(8字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: low1
+low1:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 8; 分配栈空间(8字节)
+    mov QWORD[rbp-16], RDI; 保存参数v
+    ; ---- 函数开始 ----
+    movsx RAX, WORD[rbp-16]
+    movzx RAX, AL; 转换为u8; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 8; 清理局部变量栈空间(8字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: signed1
+signed1:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 8; 分配栈空间(8字节)
+    mov QWORD[rbp-16], RDI; 保存参数v
+    ; ---- 函数开始 ----
+    movzx RAX, BYTE[rbp-16]
+    movsx RAX, AL; 转换为i8; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 8; 清理局部变量栈空间(8字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: unsigned1
+unsigned1:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 8; 分配栈空间(8字节)
+    mov QWORD[rbp-16], RDI; 保存参数v
+    ; ---- 函数开始 ----
+    movsx RAX, BYTE[rbp-16]
+    mov EAX, EAX; 转换为u32; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 8; 清理局部变量栈空间(8字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: wrap1
+wrap1:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 8; 分配栈空间(8字节)
+    mov QWORD[rbp-16], RDI; 保存参数v
+    ; ---- 函数开始 ----
+    movzx RAX, BYTE[rbp-16]
+    movzx RCX, BYTE[rbp-16]
+    add RAX, RCX
+    movzx RAX, AL; 转换为u8
+    movzx RAX, AL; u8扩展为int; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 8; 清理局部变量栈空间(8字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: bump1
+bump1:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 24; 分配栈空间(24字节)
+    mov QWORD[rbp-16], RDI; 保存参数addr
+    ; ---- 函数开始 ----
+    mov RAX, QWORD[rbp-16]
+    mov QWORD[rbp-24], RAX; 设置变量p
+    mov RAX, QWORD[rbp-24]
+    mov RCX, QWORD[rbp-24]
+    mov RCX, QWORD[RCX]; 解引用
+    add RCX, 1
+    mov QWORD[RAX], RCX; 通过指针赋值
+    ; ---- 退出函数 ----
+    add rsp, 24; 清理局部变量栈空间(24字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: digit1
+digit1:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 8; 分配栈空间(8字节)
+    mov QWORD[rbp-16], RDI; 保存参数c
+    ; ---- 函数开始 ----
+    movzx RAX, BYTE[rbp-16]
+    add RAX, 48; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 8; 清理局部变量栈空间(8字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: main
+main:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 24; 分配栈空间(24字节)
+    ; ---- 函数开始 ----
+    push -1; 参数0
+    pop RDI
+    call low1
+    cmp RAX, 255
+    je end_if_1; 判断后跳转到目标
+    if_1:
+    mov RAX, 1; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 24; 清理局部变量栈空间(24字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+    end_if_1:
+    push 255; 参数0
+    pop RDI
+    call signed1
+    movsx RAX, AL; i8扩展为int
+    add RAX, 1
+    cmp RAX, 0
+    je end_if_2; 判断后跳转到目标
+    if_2:
+    mov RAX, 2; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 24; 清理局部变量栈空间(24字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+    end_if_2:
+    push -1; 参数0
+    pop RDI
+    call unsigned1
+    mov RCX, RAX
+    mov R11, 4294967295; 64位常量
+    cmp RCX, R11
+    je end_if_3; 判断后跳转到目标
+    if_3:
+    mov RAX, 3; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 24; 清理局部变量栈空间(24字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+    end_if_3:
+    push 200; 参数0
+    pop RDI
+    call wrap1
+    mov RCX, RAX
+    cmp RCX, 144
+    je end_if_4; 判断后跳转到目标
+    if_4:
+    mov RAX, 4; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 24; 清理局部变量栈空间(24字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+    end_if_4:
+    mov QWORD[rbp-16], 7; 设置变量x
+    lea RCX, [rbp-16]; 取x地址
+    push RCX; 参数0
+    pop RDI
+    call bump1
+    mov BYTE[rbp-17], -56; 设置变量neg
+    movsx RCX, BYTE[rbp-17]
+    movsx RCX, CL; i8扩展为int
+    add RCX, 56
+    cmp RCX, 0
+    je end_if_5; 判断后跳转到目标
+    if_5:
+    mov RAX, 5; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 24; 清理局部变量栈空间(24字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+    end_if_5:
+    push 300; 参数0
+    pop RDI
+    call trunc1
+    mov RBX, RAX; 函数返回值直接移到RBX
+    push 200; 参数0
+    pop RDI
+    call sext1
+    add RBX, RAX
+    mov RBX, RBX; 保存中间结果到RBX(callee-save)
+    push 200; 参数0
+    pop RDI
+    call widen1
+    add RBX, RAX
+    mov RBX, RBX; 保存中间结果到RBX(callee-save)
+    push 1; 参数0
+    pop RDI
+    call digit1
+    add RBX, RAX
+    mov RDX, QWORD[rbp-16]
+    add RBX, RDX
+    sub RBX, 150
+    mov RAX, RBX; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 24; 清理局部变量栈空间(24字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; 程序入口点 (ELF入口)
+_start:
+    ; 调用main函数
+    call main
+    ; 使用系统调用退出程序 (sys_exit = 60)
+    ; 返回值在RAX中
+    mov rdi, rax; 返回码
+    mov rax, 60; sys_exit
+    syscall; 调用内核
+
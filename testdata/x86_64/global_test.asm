@@ -0,0 +1,125 @@
+section .text
+global _start
+
+; ==============================
+; Function: Counter_Tick0
+Counter_Tick0:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    push R12; 保存R12
+    mov R12, RDI; self地址
+    ; ---- 函数开始 ----
+    mov RAX, QWORD[R12+8]
+    mov RCX, QWORD[R12]
+    add RAX, RCX
+    mov QWORD[R12+8], RAX; 设置变量self_total
+    ; ---- 退出函数 ----
+    pop R12; 恢复R12
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: bump1
+bump1:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 8; 分配栈空间(8字节)
+    mov QWORD[rbp-16], RDI; 保存参数k
+    ; ---- 函数开始 ----
+    mov RAX, QWORD[g_Zero]
+    mov RCX, QWORD[rbp-16]
+    add RAX, RCX
+    mov QWORD[g_Zero], RAX; 设置变量Zero
+    ; ---- 退出函数 ----
+    add rsp, 8; 清理局部变量栈空间(8字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: main
+main:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 24; 分配栈空间(24字节)
+    ; ---- 函数开始 ----
+    mov RAX, str_1
+    mov QWORD[rbp-16], RAX; 设置变量s
+    lea RAX, [g_Count]; 取接收者地址
+    push RAX; 接收者地址
+    pop RDI
+    call Counter_Tick0
+    lea RAX, [g_Count]; 取接收者地址
+    push RAX; 接收者地址
+    pop RDI
+    call Counter_Tick0
+    mov RAX, QWORD[g_Base]
+    push RAX; 参数0
+    pop RDI
+    call bump1
+    mov RAX, QWORD[g_Zero]
+    mov QWORD[rbp-24], RAX; 设置变量before
+    push 1; 参数0
+    pop RDI
+    call bump1
+    mov RAX, QWORD[g_Count+8]
+    mov RCX, QWORD[g_Zero]
+    add RAX, RCX
+    mov RCX, QWORD[rbp-24]
+    sub RAX, RCX
+    movzx RCX, BYTE[g_Initial]
+    add RAX, RCX
+    sub RAX, 65; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 24; 清理局部变量栈空间(24字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; 程序入口点 (ELF入口)
+_start:
+    ; 调用main函数
+    call main
+    ; 使用系统调用退出程序 (sys_exit = 60)
+    ; 返回值在RAX中
+    mov rdi, rax; 返回码
+    mov rax, 60; sys_exit
+    syscall; 调用内核
+
+    section .rodata
+    str_0: db "hi", 10, 0
+    str_1: db "hello", 0
+    section .data
+    align 8
+    g_Count:
+    dq 2
+    times 8 db 0
+    align 8
+    g_Base:
+    dq 40
+    align 1
+    g_Flag:
+    db 1
+    align 1
+    g_Initial:
+    db 65
+    align 8
+    g_Greeting:
+    dq str_0
+    section .bss
+    alignb 8
+    g_Zero: resb 8
@@ -0,0 +1,208 @@
+section .text
+global _start
+
+; ==============================
+; Function: Rect_Area0
+Rect_Area0:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    push R12; 保存R12
+    mov R12, RDI; self地址
+    ; ---- 函数开始 ----
+    mov RAX, QWORD[R12]
+    mov RCX, QWORD[R12+8]
+    imul RAX, RCX; return值存入RAX
+    ; ---- 退出函数 ----
+    pop R12; 恢复R12
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: Rect_Grow1
+Rect_Grow1:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    push R12; 保存R12
+    mov R12, RDI; self地址
+    sub rsp, 16; 分配栈空间(16字节)
+    mov QWORD[rbp-24], RSI; 保存参数k
+    ; ---- 函数开始 ----
+    mov RAX, QWORD[R12]
+    mov RCX, QWORD[rbp-24]
+    add RAX, RCX
+    mov QWORD[R12], RAX; 设置变量self_w
+    mov RAX, QWORD[R12+8]
+    mov RCX, QWORD[rbp-24]
+    add RAX, RCX
+    mov QWORD[R12+8], RAX; 设置变量self_h
+    ; ---- 退出函数 ----
+    add rsp, 16; 清理局部变量栈空间(16字节)
+    pop R12; 恢复R12
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: Square_Area0
+Square_Area0:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    push R12; 保存R12
+    mov R12, RDI; self地址
+    ; ---- 函数开始 ----
+    mov RAX, QWORD[R12]
+    mov RCX, QWORD[R12]
+    imul RAX, RCX; return值存入RAX
+    ; ---- 退出函数 ----
+    pop R12; 恢复R12
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: Square_Grow1
+Square_Grow1:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    push R12; 保存R12
+    mov R12, RDI; self地址
+    sub rsp, 16; 分配栈空间(16字节)
+    mov QWORD[rbp-24], RSI; 保存参数k
+    ; ---- 函数开始 ----
+    mov RAX, QWORD[R12]
+    mov RCX, QWORD[rbp-24]
+    add RAX, RCX
+    mov QWORD[R12], RAX; 设置变量self_side
+    ; ---- 退出函数 ----
+    add rsp, 16; 清理局部变量栈空间(16字节)
+    pop R12; 恢复R12
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: Measure1
+Measure1:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 24; 分配栈空间(24字节)
+    mov QWORD[rbp-24], RDI; 保存参数s
+    mov QWORD[rbp-24+8], RSI; 保存参数s
+    ; ---- 函数开始 ----
+    push 1; 参数0
+    push QWORD[rbp-24]; 接收者地址
+    pop RDI
+    pop RSI
+    mov RAX, QWORD[rbp-24+8]; 取虚表地址
+    call QWORD[RAX+8]; 动态分派Shape_Grow
+    push QWORD[rbp-24]; 接收者地址
+    pop RDI
+    mov RAX, QWORD[rbp-24+8]; 取虚表地址
+    call QWORD[RAX]; 动态分派Shape_Area; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 24; 清理局部变量栈空间(24字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: main
+main:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 72; 分配栈空间(72字节)
+    ; ---- 函数开始 ----
+    mov QWORD[rbp-24], 0; 清零
+    mov QWORD[rbp-24+8], 0; 清零
+    mov QWORD[rbp-24], 2; 设置变量r_w
+    mov QWORD[rbp-16], 3; 设置变量r_h
+    mov QWORD[rbp-32], 0; 清零
+    mov QWORD[rbp-32], 2; 设置变量q_side
+    lea RAX, [rbp-24]; 取r地址
+    mov QWORD[rbp-48], RAX; 接口数据地址
+    mov QWORD[rbp-48+8], vtable_Rect_Shape; 接口虚表
+    push QWORD[rbp-48]; 接收者地址
+    pop RDI
+    mov RAX, QWORD[rbp-48+8]; 取虚表地址
+    call QWORD[RAX]; 动态分派Shape_Area
+    mov QWORD[rbp-56], RAX; 设置变量a
+    lea RAX, [rbp-32]; 取q地址
+    mov QWORD[rbp-48], RAX; 接口数据地址
+    mov QWORD[rbp-48+8], vtable_Square_Shape; 接口虚表
+    push QWORD[rbp-48]; 接收者地址
+    pop RDI
+    mov RAX, QWORD[rbp-48+8]; 取虚表地址
+    call QWORD[RAX]; 动态分派Shape_Area
+    mov QWORD[rbp-64], RAX; 设置变量b
+    push vtable_Rect_Shape; 参数0虚表
+    lea RAX, [rbp-24]; 取r地址
+    push RAX; 参数0数据地址
+    pop RDI
+    pop RSI
+    call Measure1
+    mov QWORD[rbp-72], RAX; 设置变量c
+    mov RAX, QWORD[rbp-56]
+    mov RCX, QWORD[rbp-64]
+    add RAX, RCX
+    mov RCX, QWORD[rbp-72]
+    add RAX, RCX
+    mov RBX, RAX; 保存中间结果到RBX(callee-save)
+    push vtable_Square_Shape; 参数0虚表
+    lea RAX, [rbp-32]; 取q地址
+    push RAX; 参数0数据地址
+    pop RDI
+    pop RSI
+    call Measure1
+    add RBX, RAX
+    mov RAX, RBX; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 72; 清理局部变量栈空间(72字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; 程序入口点 (ELF入口)
+_start:
+    ; 调用main函数
+    call main
+    ; 使用系统调用退出程序 (sys_exit = 60)
+    ; 返回值在RAX中
+    mov rdi, rax; 返回码
+    mov rax, 60; sys_exit
+    syscall; 调用内核
+
+    section .rodata
+    align 8
+    vtable_Rect_Shape:
+    dq Rect_Area0
+    dq Rect_Grow1
+    align 8
+    vtable_Square_Shape:
+    dq Square_Area0
+    dq Square_Grow1
@@ -0,0 +1,170 @@
+section .text
+global _start
+
+; ==============================
+; Function: fill3
+fill3:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 40; 分配栈空间(40字节)
+    mov QWORD[rbp-16], RDI; 保存参数dst
+    mov QWORD[rbp-24], RSI; 保存参数n
+    mov QWORD[rbp-32], RDX; 保存参数c
+    ; ---- 函数开始 ----
+    mov QWORD[rbp-40], 0; 设置变量i
+    while_1: ; while循环开始
+    mov RAX, QWORD[rbp-40]
+    mov RCX, QWORD[rbp-24]
+    cmp RAX, RCX
+    jnl while_1_end; 判断后跳转到目标
+    mov RAX, QWORD[rbp-16]
+    mov RCX, QWORD[rbp-40]
+    add RAX, RCX
+    movzx RCX, BYTE[rbp-32]
+    mov BYTE[RAX], CL; 通过指针赋值
+    mov RCX, QWORD[rbp-40]
+    add RCX, 1
+    mov QWORD[rbp-40], RCX; 设置变量i
+    jmp while_1; while循环
+    while_1_end: ; while循环结束
+    ; ---- 退出函数 ----
+    add rsp, 40; 清理局部变量栈空间(40字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: sum2
+sum2:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 40; 分配栈空间(40字节)
+    mov QWORD[rbp-16], RDI; 保存参数p
+    mov QWORD[rbp-24], RSI; 保存参数n
+    ; ---- 函数开始 ----
+    mov QWORD[rbp-32], 0; 设置变量s
+    mov QWORD[rbp-40], 0; 设置变量i
+    while_2: ; while循环开始
+    mov RAX, QWORD[rbp-40]
+    mov RCX, QWORD[rbp-24]
+    cmp RAX, RCX
+    jnl while_2_end; 判断后跳转到目标
+    mov RAX, QWORD[rbp-32]
+    mov RCX, QWORD[rbp-16]
+    mov RDX, QWORD[rbp-40]
+    imul RDX, 2
+    add RCX, RDX
+    movsx RCX, WORD[RCX]; 解引用
+    add RAX, RCX
+    mov QWORD[rbp-32], RAX; 设置变量s
+    mov RAX, QWORD[rbp-40]
+    add RAX, 1
+    mov QWORD[rbp-40], RAX; 设置变量i
+    jmp while_2; while循环
+    while_2_end: ; while循环结束
+    mov RAX, QWORD[rbp-32]; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 40; 清理局部变量栈空间(40字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: main
+main:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 56; 分配栈空间(56字节)
+    ; ---- 函数开始 ----
+    mov QWORD[rbp-16], 5; 设置变量x
+    lea RAX, [rbp-16]; 取x地址
+    mov QWORD[rbp-24], RAX; 设置变量px
+    mov RAX, QWORD[rbp-24]
+    mov RCX, QWORD[rbp-24]
+    mov RCX, QWORD[RCX]; 解引用
+    add RCX, 2
+    mov QWORD[RAX], RCX; 通过指针赋值
+    lea RAX, [rbp-24]; 取px地址
+    mov QWORD[rbp-32], RAX; 设置变量ppx
+    mov RAX, QWORD[rbp-32]
+    mov RAX, QWORD[RAX]; 解引用
+    mov RCX, QWORD[rbp-32]
+    mov RCX, QWORD[RCX]; 解引用
+    mov RCX, QWORD[RCX]; 解引用
+    imul RCX, 2
+    mov QWORD[RAX], RCX; 通过指针赋值
+    mov DWORD[rbp-36], 0; 清零
+    mov WORD[rbp-36], 30; 设置变量pair_a
+    mov WORD[rbp-34], 40; 设置变量pair_b
+    lea RCX, [rbp-36]; 取pair_a地址
+    mov QWORD[rbp-48], RCX; 设置变量pa
+    mov RCX, QWORD[rbp-48]
+    add RCX, 2
+    mov WORD[RCX], 7; 通过指针赋值
+    mov DWORD[rbp-52], 0; 清零
+    push 1; 参数2
+    push 4; 参数1
+    lea RDX, [rbp-52]; 取bytes_a地址
+    push RDX; 参数0
+    pop RDI
+    pop RSI
+    pop RDX
+    call fill3
+    lea RDX, [g_Buf]; 取Buf地址
+    mov QWORD[rbp-64], RDX; 设置变量pb
+    mov RDX, QWORD[rbp-64]
+    cmp RDX, 0
+    jne end_if_1; 判断后跳转到目标
+    if_1:
+    mov RAX, 1; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 56; 清理局部变量栈空间(56字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+    end_if_1:
+    mov RDX, QWORD[rbp-16]
+    mov RBX, RDX; 保存中间结果到RBX(callee-save)
+    push 2; 参数1
+    lea RDX, [rbp-36]; 取pair_a地址
+    push RDX; 参数0
+    pop RDI
+    pop RSI
+    call sum2
+    add RBX, RAX
+    movzx RDX, BYTE[rbp-49]
+    add RBX, RDX
+    mov RAX, RBX; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 56; 清理局部变量栈空间(56字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; 程序入口点 (ELF入口)
+_start:
+    ; 调用main函数
+    call main
+    ; 使用系统调用退出程序 (sys_exit = 60)
+    ; 返回值在RAX中
+    mov rdi, rax; 返回码
+    mov rax, 60; sys_exit
+    syscall; 调用内核
+
+    section .bss
+    alignb 2
+    g_Buf: resb 4
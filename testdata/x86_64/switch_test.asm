@@ -0,0 +1,253 @@
+section .text
+global _start
+
+; ==============================
+; Function: classify1
+classify1:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 8; 分配栈空间(8字节)
+    mov QWORD[rbp-16], RDI; 保存参数op
+    ; ---- 函数开始 ----
+    ; ---- switch开始 ----
+    movsxd RAX, DWORD[rbp-16]; switch值扩展后存入RAX
+    sub RAX, -3; 减去最小分支值
+    cmp RAX, 8; 跳转表边界检查
+    ja switch_1_default; 超出范围
+    jmp [switch_1_table+RAX*8]; 跳转表分派
+    section .rodata
+    align 8
+    switch_1_table:
+    dq switch_1_case_4
+    dq switch_1_default
+    dq switch_1_case_4
+    dq switch_1_case_0
+    dq switch_1_case_1
+    dq switch_1_case_1
+    dq switch_1_case_2
+    dq switch_1_default
+    dq switch_1_case_3
+    section .text
+    switch_1_case_0:
+    mov RAX, 10; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 8; 清理局部变量栈空间(8字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+    jmp switch_1_end; 分支结束
+    switch_1_case_1:
+    mov RAX, 20; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 8; 清理局部变量栈空间(8字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+    jmp switch_1_end; 分支结束
+    switch_1_case_2:
+    mov RAX, 30; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 8; 清理局部变量栈空间(8字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+    jmp switch_1_end; 分支结束
+    switch_1_case_3:
+    mov RAX, 50; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 8; 清理局部变量栈空间(8字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+    jmp switch_1_end; 分支结束
+    switch_1_case_4:
+    mov RAX, 7; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 8; 清理局部变量栈空间(8字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+    jmp switch_1_end; 分支结束
+    switch_1_default:
+    mov RAX, 0; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 8; 清理局部变量栈空间(8字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+    jmp switch_1_end; 分支结束
+    switch_1_end: ; switch结束
+    mov RAX, 0; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 8; 清理局部变量栈空间(8字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: main
+main:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 24; 分配栈空间(24字节)
+    ; ---- 函数开始 ----
+    mov DWORD[rbp-12], 0; 设置变量x
+    mov DWORD[rbp-16], 0; 设置变量i
+    while_1: ; while循环开始
+    movsxd RAX, DWORD[rbp-16]
+    cmp RAX, 8
+    jnl while_1_end; 判断后跳转到目标
+    movsxd RAX, DWORD[rbp-16]
+    add RAX, 1
+    mov DWORD[rbp-16], EAX; 设置变量i
+    ; ---- switch开始 ----
+    movsxd RAX, DWORD[rbp-16]; switch值扩展后存入RAX
+    cmp RAX, 1
+    je switch_2_case_0
+    cmp RAX, 6
+    je switch_2_case_2
+    cmp RAX, 7
+    je switch_2_case_3
+    cmp RAX, 100
+    je switch_2_case_1
+    cmp RAX, 200
+    je switch_2_case_1
+    jmp switch_2_default; 没有匹配的分支
+    switch_2_case_0:
+    movsxd RAX, DWORD[rbp-12]
+    mov RBX, RAX; 保存中间结果到RBX(callee-save)
+    movsxd RAX, DWORD[rbp-16]
+    push RAX; 参数0
+    pop RDI
+    call classify1
+    add RBX, RAX
+    mov DWORD[rbp-12], EBX; 设置变量x
+    jmp switch_2_end; 分支结束
+    switch_2_case_1:
+    movsxd RAX, DWORD[rbp-12]
+    add RAX, 1
+    mov DWORD[rbp-12], EAX; 设置变量x
+    jmp switch_2_end; 分支结束
+    switch_2_case_2:
+    jmp while_1; continue
+    jmp switch_2_end; 分支结束
+    switch_2_case_3:
+    jmp switch_2_end; break
+    jmp switch_2_end; 分支结束
+    switch_2_default:
+    movsxd RAX, DWORD[rbp-12]
+    add RAX, 2
+    mov DWORD[rbp-12], EAX; 设置变量x
+    jmp switch_2_end; 分支结束
+    switch_2_end: ; switch结束
+    jmp while_1; while循环
+    while_1_end: ; while循环结束
+    mov BYTE[rbp-17], 98; 设置变量c
+    ; ---- switch开始 ----
+    movzx RAX, BYTE[rbp-17]; switch值扩展后存入RAX
+    cmp RAX, 10
+    je switch_3_case_1
+    cmp RAX, 97
+    je switch_3_case_0
+    cmp RAX, 98
+    je switch_3_case_1
+    jmp switch_3_end; 没有匹配的分支
+    switch_3_case_0:
+    movsxd RAX, DWORD[rbp-12]
+    add RAX, 1
+    mov DWORD[rbp-12], EAX; 设置变量x
+    jmp switch_3_end; 分支结束
+    switch_3_case_1:
+    movsxd RAX, DWORD[rbp-12]
+    add RAX, 3
+    mov DWORD[rbp-12], EAX; 设置变量x
+    jmp switch_3_end; 分支结束
+    switch_3_end: ; switch结束
+    ; ---- switch开始 ----
+    lea RAX, [g_Flags]; 取Flags地址
+    movzx RAX, BYTE[RAX]; 读取元素
+    test RAX, RAX
+    je switch_4_false; switch条件
+    jmp switch_4_case_0; switch值为真
+    switch_4_false:
+    jmp switch_4_case_1; switch值为假
+    switch_4_case_0:
+    movsxd RAX, DWORD[rbp-12]
+    add RAX, 100
+    mov DWORD[rbp-12], EAX; 设置变量x
+    jmp switch_4_end; 分支结束
+    switch_4_case_1:
+    movsxd RAX, DWORD[rbp-12]
+    add RAX, 4
+    mov DWORD[rbp-12], EAX; 设置变量x
+    jmp switch_4_end; 分支结束
+    switch_4_end: ; switch结束
+    mov DWORD[rbp-24], -1; 设置变量d
+    movsxd RAX, DWORD[rbp-12]
+    mov RBX, RAX; 保存中间结果到RBX(callee-save)
+    movsxd RAX, DWORD[rbp-24]
+    push RAX; 参数0
+    pop RDI
+    call classify1
+    add RBX, RAX
+    mov DWORD[rbp-12], EBX; 设置变量x
+    lea RAX, [g_On]; 取On地址
+    mov QWORD[rbp-32], RAX; 设置变量pb
+    ; ---- switch开始 ----
+    mov RAX, QWORD[rbp-32]
+    movzx RAX, BYTE[RAX]; 解引用
+    test RAX, RAX
+    je switch_5_false; switch条件
+    jmp switch_5_case_0; switch值为真
+    switch_5_false:
+    jmp switch_5_case_1; switch值为假
+    switch_5_case_0:
+    movsxd RAX, DWORD[rbp-12]
+    add RAX, 5
+    mov DWORD[rbp-12], EAX; 设置变量x
+    jmp switch_5_end; 分支结束
+    switch_5_case_1:
+    movsxd RAX, DWORD[rbp-12]
+    add RAX, 200
+    mov DWORD[rbp-12], EAX; 设置变量x
+    jmp switch_5_end; 分支结束
+    switch_5_end: ; switch结束
+    movsxd RAX, DWORD[rbp-12]; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 24; 清理局部变量栈空间(24字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; 程序入口点 (ELF入口)
+_start:
+    ; 调用main函数
+    call main
+    ; 使用系统调用退出程序 (sys_exit = 60)
+    ; 返回值在RAX中
+    mov rdi, rax; 返回码
+    mov rax, 60; sys_exit
+    syscall; 调用内核
+
+    section .data
+    align 1
+    g_On:
+    db 1
+    section .bss
+    alignb 1
+    g_Flags: resb 2
@@ -0,0 +1,273 @@
+section .text
+global _start
+
+; ==============================
+; Function: Point_scale1
+Point_scale1:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    push R12; 保存R12
+    mov R12, RDI; self地址
+    sub rsp, 16; 分配栈空间(16字节)
+    mov QWORD[rbp-24], RSI; 保存参数k
+    ; ---- 函数开始 ----
+    mov RAX, QWORD[R12]
+    mov RCX, QWORD[rbp-24]
+    imul RAX, RCX
+    mov QWORD[R12], RAX; 设置变量self_x
+    mov RAX, QWORD[R12+8]
+    mov RCX, QWORD[rbp-24]
+    imul RAX, RCX
+    mov QWORD[R12+8], RAX; 设置变量self_y
+    ; ---- 退出函数 ----
+    add rsp, 16; 清理局部变量栈空间(16字节)
+    pop R12; 恢复R12
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: many8
+many8:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 56; 分配栈空间(56字节)
+    mov QWORD[rbp-16], RDI; 保存参数a
+    mov QWORD[rbp-24], RSI; 保存参数b
+    mov QWORD[rbp-32], RDX; 保存参数c
+    mov QWORD[rbp-40], RCX; 保存参数d
+    mov QWORD[rbp-48], R8; 保存参数e
+    mov QWORD[rbp-56], R9; 保存参数f
+    ; ---- 函数开始 ----
+    mov RAX, QWORD[rbp-16]
+    mov RCX, QWORD[rbp-24]
+    add RAX, RCX
+    mov RCX, QWORD[rbp-32]
+    add RAX, RCX
+    mov RCX, QWORD[rbp-40]
+    add RAX, RCX
+    mov RCX, QWORD[rbp-48]
+    add RAX, RCX
+    mov RCX, QWORD[rbp-56]
+    add RAX, RCX
+    mov RCX, QWORD[rbp+16]
+    mov RDX, QWORD[rbp+24]
+    imul RCX, RDX
+    add RAX, RCX; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 56; 清理局部变量栈空间(56字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: total2
+total2:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 40; 分配栈空间(40字节)
+    mov QWORD[rbp-24], RDI; 保存参数xs
+    mov QWORD[rbp-24+8], RSI; 保存参数xs
+    mov QWORD[rbp-32], RDX; 保存参数k
+    ; ---- 函数开始 ----
+    mov QWORD[rbp-40], 0; 设置变量s
+    mov QWORD[rbp-48], 0; 设置变量i
+    while_1: ; while循环开始
+    mov RAX, QWORD[rbp-48]
+    lea RCX, [rbp-24]; 取xs地址
+    mov RCX, QWORD[RCX+8]; 切片长度
+    cmp RAX, RCX
+    jnl while_1_end; 判断后跳转到目标
+    mov RAX, QWORD[rbp-40]
+    mov RCX, QWORD[rbp-48]
+    lea RDX, [rbp-24]; 取xs地址
+    mov RDX, QWORD[RDX]; 切片数据地址
+    lea RDX, [RDX+RCX*8]; 元素地址
+    mov RDX, QWORD[RDX]; 读取元素
+    movzx RCX, BYTE[rbp-32]
+    imul RDX, RCX
+    add RAX, RDX
+    mov QWORD[rbp-40], RAX; 设置变量s
+    mov RAX, QWORD[rbp-48]
+    add RAX, 1
+    mov QWORD[rbp-48], RAX; 设置变量i
+    jmp while_1; while循环
+    while_1_end: ; while循环结束
+    mov RAX, QWORD[rbp-40]; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 40; 清理局部变量栈空间(40字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: high1
+high1:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 8; 分配栈空间(8字节)
+    mov QWORD[rbp-16], RDI; 保存参数n
+    ; ---- 函数开始 ----
+    mov RAX, QWORD[rbp-16]
+    mov R11, 4294967296; 64位常量
+    imul RAX, R11; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 8; 清理局部变量栈空间(8字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; Function: main
+main:
+    push rbp; 保存调用者的栈帧基址
+    mov rbp, rsp; 设置当前栈帧基址
+    push RBX; 保存RBX
+    sub rsp, 88; 分配栈空间(88字节)
+    ; ---- 函数开始 ----
+    mov QWORD[rbp-16], 16; 设置变量w
+    mov RAX, QWORD[rbp-16]
+    cmp RAX, 16
+    je end_if_1; 判断后跳转到目标
+    if_1:
+    mov RAX, 1; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 88; 清理局部变量栈空间(88字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+    end_if_1:
+    push 3; 参数0
+    pop RDI
+    call high1
+    add RAX, 7
+    mov QWORD[rbp-24], RAX; 设置变量big
+    mov RAX, QWORD[rbp-24]
+    mov R11, 4294967296; 64位常量
+    push RDX
+    cqo
+    idiv R11
+    pop RDX
+    cmp RAX, 3
+    je end_if_2; 判断后跳转到目标
+    if_2:
+    mov RAX, 2; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 88; 清理局部变量栈空间(88字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+    end_if_2:
+    mov RCX, QWORD[rbp-24]
+    mov R11, 4294967296; 64位常量
+    push RAX
+    push RDX
+    mov RAX, RCX
+    cqo
+    idiv R11
+    mov RCX, RDX
+    pop RDX
+    pop RAX
+    cmp RCX, 7
+    je end_if_3; 判断后跳转到目标
+    if_3:
+    mov RAX, 3; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 88; 清理局部变量栈空间(88字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+    end_if_3:
+    mov QWORD[rbp-40], 0; 清零
+    mov QWORD[rbp-40+8], 0; 清零
+    mov QWORD[rbp-40], 2; 设置变量p_x
+    mov QWORD[rbp-32], 3; 设置变量p_y
+    push 5; 参数0
+    lea RAX, [rbp-40]; 取接收者地址
+    push RAX; 接收者地址
+    pop RDI
+    pop RSI
+    call Point_scale1
+    lea RDX, [rbp-64]; 取arr地址
+    mov QWORD[RDX], 1; 通过指针赋值
+    lea RDX, [rbp-64]; 取arr地址
+    lea RDX, [RDX+8]; 元素地址
+    mov QWORD[RDX], 2; 通过指针赋值
+    lea RDX, [rbp-64]; 取arr地址
+    lea RDX, [RDX+16]; 元素地址
+    mov QWORD[RDX], 3; 通过指针赋值
+    lea RAX, [rbp-64]; 取arr地址
+    mov QWORD[rbp-80], RAX; 切片数据地址
+    mov QWORD[rbp-80+8], 3; 切片长度
+    push 8; 参数7
+    push 7; 参数6
+    push 6; 参数5
+    push 5; 参数4
+    push 4; 参数3
+    push 3; 参数2
+    push 2; 参数1
+    push 1; 参数0
+    pop RDI
+    pop RSI
+    pop RDX
+    pop RCX
+    pop R8
+    pop R9
+    call many8
+    add rsp, 16; 清理参数栈(sysv)
+    mov RDX, RAX
+    mov QWORD[rbp-88], RDX; 设置变量m
+    mov RDX, QWORD[rbp-88]
+    mov R10, QWORD[rbp-40]
+    sub RDX, R10
+    mov R10, QWORD[rbp-32]
+    sub RDX, R10
+    mov RBX, RDX; 保存中间结果到RBX(callee-save)
+    push 2; 参数1
+    push QWORD[rbp-80+8]; 参数0长度
+    push QWORD[rbp-80]; 参数0数据地址
+    pop RDI
+    pop RSI
+    pop RDX
+    call total2
+    add RBX, RAX
+    mov RAX, RBX; return值存入RAX
+    ; ---- 退出函数 ----
+    add rsp, 88; 清理局部变量栈空间(88字节)
+    pop RBX; 恢复RBX
+    leave
+    ret
+
+; ======函数完毕=======
+
+
+; ==============================
+; 程序入口点 (ELF入口)
+_start:
+    ; 调用main函数
+    call main
+    ; 使用系统调用退出程序 (sys_exit = 60)
+    ; 返回值在RAX中
+    mov rdi, rax; 返回码
+    mov rax, 60; sys_exit
+    syscall; 调用内核
+
@@ -0,0 +1,59 @@
+package typeSys
+
+import "strconv"
+
+// ArrayType 定长数组类型，元素按元素类型的大小连续存放
+type ArrayType struct {
+	RType
+	Elem Type // 元素类型
+	Len  int  // 元素个数
+}
+
+// NewArrayType 创建元素类型为 elem、长度为 n 的数组类型
+func NewArrayType(elem Type, n int) *ArrayType {
+	return &ArrayType{
+		RType: RType{TypeName: "array", RSize: elem.Size() * n, RAlignment: AlignOf(elem)},
+		Elem:  elem,
+		Len:   n,
+	}
+}
+
+// Type 数组的类型名为 "[长度]" 加元素类型名，如 [32]u8
+func (t *ArrayType) Type() string {
+	return "[" + strconv.Itoa(t.Len) + "]" + t.Elem.Type()
+}
+
+func (t *ArrayType) String() string {
+	return t.Type()
+}
+
+func (t *ArrayType) Fields() StructFileds {
+	return nil
+}
+
+// SliceType 切片类型，切片值为两个字长：[+0] 数据地址，[+PtrSize] 长度
+type SliceType struct {
+	RType
+	Elem Type // 元素类型
+}
+
+// NewSliceType 创建元素类型为 elem 的切片类型
+func NewSliceType(elem Type) *SliceType {
+	return &SliceType{
+		RType: RType{TypeName: "slice", RSize: 2 * PtrSize, RAlignment: PtrSize},
+		Elem:  elem,
+	}
+}
+
+// Type 切片的类型名为 "[]" 加元素类型名，如 []int
+func (t *SliceType) Type() string {
+	return "[]" + t.Elem.Type()
+}
+
+func (t *SliceType) String() string {
+	return t.Type()
+}
+
+func (t *SliceType) Fields() StructFileds {
+	return nil
+}
@@ -0,0 +1,130 @@
+package typeSys
+
+import "math"
+
+// 数值转换规则：
+//   - 隐式转换只允许无损拓宽：同符号整数从窄到宽（i8 -> i16 -> i32/int -> i64，u8/byte -> u16 -> u32/uint -> u64），
+//     无符号整数到更宽的有符号整数（u8 -> i16、u16 -> i32/int），f32 -> f64
+//   - 有符号到无符号、无符号到等宽或更窄的有符号、整数与浮点数之间、从宽到窄都必须使用 as 显式转换
+//   - 数值常量只要能用目标类型精确表示即可隐式赋值
+
+// IntInfo 返回整数类型的位宽与是否有符号，byte 视为 u8，非整数类型返回 ok = false
+func IntInfo(t Type) (bits int, signed bool, ok bool) {
+	if t == nil {
+		return 0, false, false
+	}
+	switch GetTypeType(t) {
+	case "int":
+		return t.Size() * 8, true, true
+	case "uint", "byte":
+		return t.Size() * 8, false, true
+	}
+	return 0, false, false
+}
+
+// isNumeric 报告类型是否为整数或浮点数
+func isNumeric(t Type) bool {
+	_, _, ok := IntInfo(t)
+	return ok || GetTypeType(t) == "float"
+}
+
+// Widens 报告 from 类型的值能否无损地隐式转换为 to 类型
+func Widens(from, to Type) bool {
+	if from == nil || to == nil {
+		return false
+	}
+	if from.Type() == to.Type() && from.IsPointer() == to.IsPointer() {
+		return true
+	}
+	if fromBits, fromSigned, ok := IntInfo(from); ok {
+		toBits, toSigned, ok := IntInfo(to)
+		if !ok {
+			return false
+		}
+		if fromSigned == toSigned {
+			return toBits >= fromBits
+		}
+		return !fromSigned && toBits > fromBits
+	}
+	if GetTypeType(from) == "float" && GetTypeType(to) == "float" {
+		return to.Size() >= from.Size()
+	}
+	return false
+}
+
+// ConstFits 报告数值常量 value 能否用 t 类型精确表示
+func ConstFits(value float64, t Type) bool {
+	if GetTypeType(t) == "float" {
+		return true
+	}
+	bits, signed, ok := IntInfo(t)
+	if !ok || value != math.Trunc(value) {
+		return false
+	}
+	if bits >= 64 {
+		return signed || value >= 0
+	}
+	if signed {
+		limit := float64(int64(1) << (bits - 1))
+		return value >= -limit && value < limit
+	}
+	return value >= 0 && value < float64(int64(1)<<bits)
+}
+
+// Assignable 报告 from 类型的值能否隐式赋值给 to 类型
+// isConst 为 true 时 value 为常量的值：数值常量按范围检查，整数常量（如 0）可以赋给指针
+func Assignable(from, to Type, isConst bool, value float64) bool {
+	if Widens(from, to) {
+		return true
+	}
+	if !isConst || from == nil || to == nil {
+		return false
+	}
+	if to.IsPointer() {
+		return CheckTypeType(from, "int", "uint")
+	}
+	return isNumeric(from) && isNumeric(to) && ConstFits(value, to)
+}
+
+// Convertible 报告能否使用 as 将 from 类型显式转换为 to 类型
+// 数值类型之间可以任意转换；指针、字符串（数据地址）与整数之间，以及指针之间可以互相转换
+func Convertible(from, to Type) bool {
+	if Widens(from, to) {
+		return true
+	}
+	if isNumeric(from) && isNumeric(to) {
+		return true
+	}
+	isAddr := func(t Type) bool {
+		return t.IsPointer() || GetTypeType(t) == "string"
+	}
+	_, _, fromInt := IntInfo(from)
+	_, _, toInt := IntInfo(to)
+	switch {
+	case isAddr(from) && to.IsPointer():
+		return true
+	case isAddr(from) && toInt, fromInt && to.IsPointer():
+		return true
+	}
+	return false
+}
+
+// ConvertConst 按 as 转换的语义计算常量转换后的值：整数截断到目标位宽，浮点数转整数时向零取整
+func ConvertConst(value float64, to Type) float64 {
+	bits, signed, ok := IntInfo(to)
+	if !ok {
+		return value
+	}
+	n := int64(math.Trunc(value))
+	if bits >= 64 {
+		if !signed {
+			return float64(uint64(n))
+		}
+		return float64(n)
+	}
+	n &= int64(1)<<bits - 1
+	if signed && n >= int64(1)<<(bits-1) {
+		n -= int64(1) << bits
+	}
+	return float64(n)
+}
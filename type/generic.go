@@ -0,0 +1,70 @@
+package typeSys
+
+// TypeParam 泛型类型参数的占位类型，只出现在泛型模板的签名中
+// 模板实例化时重新解析源代码，类型参数被替换为具体类型，因此占位类型不会进入代码生成
+type TypeParam struct {
+	RType
+	Name string
+}
+
+// NewTypeParam 创建名为 name 的类型参数占位类型
+func NewTypeParam(name string) *TypeParam {
+	return &TypeParam{RType: RType{TypeName: "typeparam"}, Name: name}
+}
+
+// Type 类型参数以参数名作为类型名
+func (t *TypeParam) Type() string {
+	return t.Name
+}
+
+func (t *TypeParam) String() string {
+	return t.Type()
+}
+
+func (t *TypeParam) Fields() StructFileds {
+	return nil
+}
+
+// HasTypeParam 判断类型中是否含有类型参数占位类型，即仍处在泛型模板的签名中
+func HasTypeParam(t Type) bool {
+	switch t := t.(type) {
+	case *TypeParam:
+		return true
+	case *PointerType:
+		return HasTypeParam(t.Elem)
+	case *SliceType:
+		return HasTypeParam(t.Elem)
+	case *ArrayType:
+		return HasTypeParam(t.Elem)
+	}
+	return false
+}
+
+// Unify 将模板签名中的类型 pattern 与实参类型 actual 对照，推导出其中的类型参数并记录到 bindings
+// 已推导出的类型参数保持不变，不匹配的部分直接忽略，由实例化后的参数类型检查报错
+func Unify(pattern, actual Type, bindings map[string]Type) {
+	if pattern == nil || actual == nil {
+		return
+	}
+	switch pt := pattern.(type) {
+	case *TypeParam:
+		if _, ok := bindings[pt.Name]; !ok {
+			bindings[pt.Name] = actual
+		}
+	case *PointerType:
+		if at, ok := actual.(*PointerType); ok {
+			Unify(pt.Elem, at.Elem, bindings)
+		}
+	case *SliceType:
+		switch at := actual.(type) {
+		case *SliceType:
+			Unify(pt.Elem, at.Elem, bindings)
+		case *ArrayType:
+			Unify(pt.Elem, at.Elem, bindings)
+		}
+	case *ArrayType:
+		if at, ok := actual.(*ArrayType); ok && at.Len == pt.Len {
+			Unify(pt.Elem, at.Elem, bindings)
+		}
+	}
+}
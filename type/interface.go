@@ -0,0 +1,31 @@
+package typeSys
+
+import "strings"
+
+// InterfaceType 接口类型，接口值为胖指针：数据指针（+0）与虚表指针（+PtrSize）
+type InterfaceType struct {
+	RType
+	Name    []string
+	Methods []any // 方法签名，按声明顺序对应虚表中的槽位
+}
+
+// NewInterfaceType 创建接口类型
+func NewInterfaceType(name []string) *InterfaceType {
+	return &InterfaceType{
+		RType: RType{TypeName: "interface", RSize: 2 * PtrSize, RAlignment: PtrSize},
+		Name:  name,
+	}
+}
+
+// Type 接口以其名称作为类型名
+func (t *InterfaceType) Type() string {
+	return strings.Join(t.Name, ".")
+}
+
+func (t *InterfaceType) String() string {
+	return t.Type()
+}
+
+func (t *InterfaceType) Fields() StructFileds {
+	return nil
+}
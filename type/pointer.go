@@ -0,0 +1,36 @@
+package typeSys
+
+// PointerType 指针类型，值为一个字长（PtrSize）的地址
+type PointerType struct {
+	RType
+	Elem Type // 指向的元素类型
+}
+
+// NewPointerType 创建指向 elem 的指针类型
+func NewPointerType(elem Type) *PointerType {
+	return &PointerType{
+		RType: RType{TypeName: "pointer", RSize: PtrSize, RAlignment: PtrSize, IsPtr: true},
+		Elem:  elem,
+	}
+}
+
+// Type 指针的类型名为 "*" 加元素类型名，如 *u8
+func (t *PointerType) Type() string {
+	return "*" + t.Elem.Type()
+}
+
+func (t *PointerType) String() string {
+	return t.Type()
+}
+
+func (t *PointerType) Fields() StructFileds {
+	return nil
+}
+
+// ElemSize 返回指针运算时的步长（元素大小）
+func (t *PointerType) ElemSize() int {
+	if size := t.Elem.Size(); size > 0 {
+		return size
+	}
+	return 1
+}
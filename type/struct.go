@@ -1,5 +1,7 @@
 package typeSys
 
+import "strings"
+
 type StructType struct {
 	RType
 	Name         []string
@@ -7,6 +9,29 @@ type StructType struct {
 	Methods      []any
 }
 
+// Type 结构体以其名称作为类型名
+func (s *StructType) Type() string {
+	return strings.Join(s.Name, ".")
+}
+
+func (s *StructType) String() string {
+	return s.Type()
+}
+
+func (s *StructType) Fields() StructFileds {
+	return s.StructFields
+}
+
+// Field 按名称查找字段
+func (s *StructType) Field(name string) *StructField {
+	for _, field := range s.StructFields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
 type FieldAccess int
 
 const (
@@ -14,6 +39,7 @@ const (
 	AccessPrivate
 	AccessReadOnly
 	AccessWriteOnly
+	AccessProtected
 )
 
 type StructTag struct {
@@ -30,6 +56,7 @@ type StructField struct {
 	Size      int
 	Alignment int
 	Access    FieldAccess
+	Owner     string // 声明该字段的结构体名（继承时保留父结构体名）
 }
 
 func (f *StructField) IsPrivate() bool {
@@ -44,6 +71,10 @@ func (f *StructField) IsWriteOnly() bool {
 	return f.Access == AccessWriteOnly
 }
 
+func (f *StructField) IsProtected() bool {
+	return f.Access == AccessProtected
+}
+
 func (f *StructField) IsPublic() bool {
 	return f.Access == AccessPublic
 }
@@ -56,37 +87,35 @@ func (f *StructField) CanWrite() bool {
 	return f.Access == AccessPublic || f.Access == AccessWriteOnly
 }
 
-func (s *StructField) parseTags(tags string) {
-	// 状态机变量
+// ParseTags 解析结构体标签，格式如 `json:"name" db:"id"`
+func (s *StructField) ParseTags(tags string) {
 	isString := false
-	isName := true
-
-	// 解析标签
-	for _, tag := range tags[:] {
-		switch tag {
-		case '"':
-			isString = !isString
-			if !isString {
-				isName = true
-				s.Tags = append(s.Tags, StructTag{})
+	start := 0
+	for i := 0; i <= len(tags); i++ {
+		if i < len(tags) {
+			if tags[i] == '"' {
+				isString = !isString
 			}
-		case ':':
-			if isString {
-				s.Tags[len(s.Tags)-1].Value += string(tag)
+			if isString || tags[i] != ' ' {
 				continue
 			}
-			isName = false
-		case ' ':
-			isName = true
-			s.Tags = append(s.Tags, StructTag{})
-		default:
-			if isString {
-				s.Tags[len(s.Tags)-1].Value += string(tag)
-			} else if isName {
-				s.Tags[len(s.Tags)-1].Key += string(tag)
-			}
+		}
+		if i > start {
+			kv := splitTagKeyValue(tags[start:i])
+			s.Tags = append(s.Tags, StructTag{Key: kv[0], Value: kv[1]})
+		}
+		start = i + 1
+	}
+}
+
+// Tag 获取指定键的标签值
+func (s *StructField) Tag(key string) (string, bool) {
+	for _, tag := range s.Tags {
+		if tag.Key == key {
+			return tag.Value, true
 		}
 	}
+	return "", false
 }
 
 func splitTagKeyValue(s string) []string {
@@ -1,11 +1,14 @@
 package typeSys
 
 import (
-	"math"
 	"strings"
 	"unsafe"
 )
 
+// PtrSize 目标平台的字长（字节），即指针、int/uint 和字符串的大小，切片与接口值各占两个字长
+// 由编译器在解析之前按目标架构设置，默认为 32 位 x86
+var PtrSize = 4
+
 type Type interface {
 	// Type returns the type of the value.
 	Type() string
@@ -55,11 +58,7 @@ func (r RType) Size() int {
 	if r.RSize == 0 {
 		switch r.TypeName {
 		case "int", "uint":
-			if math.MaxInt != math.MaxInt32 { // 临时修改！！！！！！
-				r.RSize = 4
-			} else {
-				r.RSize = 8
-			}
+			r.RSize = PtrSize
 		case "i64", "u64", "f64":
 			r.RSize = 8
 		case "i32", "u32", "f32":
@@ -68,6 +67,8 @@ func (r RType) Size() int {
 			r.RSize = 2
 		case "i8", "u8", "bool", "byte":
 			r.RSize = 1
+		case "string":
+			r.RSize = PtrSize // 字符串按指针存储
 		}
 	}
 	return r.RSize
@@ -174,44 +175,39 @@ func GetSystemType(name string) Type {
 	}
 }
 
-func ToRType(t Type) *RType {
-	return (*RType)(unsafe.Pointer((*[2]uintptr)(unsafe.Pointer(&t))[1]))
+// AlignOf 返回类型的自然对齐值（结构体为其最大字段对齐值）
+func AlignOf(t Type) int {
+	if a := t.Alignment(); a > 0 {
+		return a
+	}
+	if size := t.Size(); size > 0 {
+		return size
+	}
+	return 1
 }
 
-func AutoType(before, after Type, IsConst bool) (ok bool) {
-	if before != nil && after != nil {
-		beforeType := before.Type()
-		afterType := after.Type()
-		beforePtr := before.IsPointer()
-		afterPtr := after.IsPointer()
-		if beforeType == afterType && beforePtr == afterPtr {
-			return true
-		}
-		if GetTypeType(before) == GetTypeType(after) && before.IsPointer() == after.IsPointer() {
-			if IsConst {
-				switch GetTypeType(before) {
-				case "int", "uint", "float":
-					return true
-				}
-			}
-			if before.Size() == after.Size() {
-				return true
-			}
-		}
-		if IsConst && before.IsPointer() == after.IsPointer() {
-			switch GetTypeType(before) {
-			case "int", "uint", "float":
-				switch GetTypeType(after) {
-				case "int", "uint", "float":
-					return true
-				}
-			}
-		}
+// AlignUp 将 offset 向上对齐到 align 的倍数
+func AlignUp(offset, align int) int {
+	if align <= 1 {
+		return offset
 	}
-	return false
+	return (offset + align - 1) / align * align
+}
+
+func ToRType(t Type) *RType {
+	return (*RType)(unsafe.Pointer((*[2]uintptr)(unsafe.Pointer(&t))[1]))
 }
 
 func GetTypeType(t Type) string {
+	if t.IsPointer() {
+		return "pointer"
+	}
+	switch t.(type) {
+	case *ArrayType:
+		return "array"
+	case *SliceType:
+		return "slice"
+	}
 	switch t.Type() {
 	case "int", "i64", "i32", "i16", "i8":
 		return "int"
@@ -63,6 +63,36 @@ func ToNASMName(name string) string {
 	return result
 }
 
+// ReservedPrefix 编译器生成的名称（泛型实例、C 后端的运行时例程等）的前缀，用户声明的函数、结构体与接口不能以它开头
+const ReservedPrefix = "cute_"
+
+// MangleName 生成泛型实例的名称：保留前缀与模板名后依次接上各类型实参
+// 类型名中的 * 写作 p_，[] 写作 s_，[N] 写作 aN_，如 max[*u8] 为 cute_max_p_u8，Box[[4]int] 为 cute_Box_a4_int
+func MangleName(name string, typeArgs ...string) string {
+	var result strings.Builder
+	result.WriteString(ReservedPrefix + name)
+	for _, arg := range typeArgs {
+		result.WriteByte('_')
+		for i := 0; i < len(arg); i++ {
+			switch arg[i] {
+			case '*':
+				result.WriteString("p_")
+			case '[':
+				if i+1 < len(arg) && arg[i+1] == ']' {
+					result.WriteString("s")
+				} else {
+					result.WriteString("a")
+				}
+			case ']':
+				result.WriteByte('_')
+			default:
+				result.WriteByte(arg[i])
+			}
+		}
+	}
+	return ToNASMName(result.String())
+}
+
 // sanitizeNASMName 清理 NASM 标签中的非法字符
 func sanitizeNASMName(name string) string {
 	var result strings.Builder
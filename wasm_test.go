@@ -0,0 +1,102 @@
+package main
+
+import (
+	"cuteify/compile"
+	packageSys "cuteify/package"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// wasmCases 用 WebAssembly 后端编译的测试程序
+var wasmCases = []string{
+	"loop_test",
+	"switch_test",
+	"struct_layout",
+	"method_test",
+	"simple_method",
+	"interface_test",
+	"global_test",
+	"pointer_test",
+	"array_test",
+	"cast_test",
+	"generic_test",
+	"callconv_test",
+	"fastcall_test",
+	"inline_test",
+	"struct_test",
+	"struct_method",
+	"link_test",
+	"build_keyword",
+	"memory_test",
+	"fs_test",
+	"bounds_test",
+}
+
+// wasmGolden 与 testdata/wasm 下的黄金文件比较输出的程序
+var wasmGolden = map[string]bool{
+	"switch_test":    true,
+	"interface_test": true,
+	"array_test":     true,
+	"cast_test":      true,
+	"global_test":    true,
+}
+
+// TestWasm 用 WebAssembly 后端编译 test/ 下的程序：部分程序的输出与黄金文件比较（go test -run TestWasm -update 更新），
+// wat2wasm 可用时检查模块能否转换为二进制格式，wasm-validate 也可用时再校验转换结果
+func TestWasm(t *testing.T) {
+	wat2wasm, _ := exec.LookPath("wat2wasm")
+	validate, _ := exec.LookPath("wasm-validate")
+
+	arch, ptrSize := compile.GoArch, typeSys.PtrSize
+	compile.GoArch, typeSys.PtrSize = "wasm", compile.WordSize("wasm")
+	defer func() { compile.GoArch, typeSys.PtrSize = arch, ptrSize }()
+
+	for _, name := range wasmCases {
+		t.Run(name, func(t *testing.T) {
+			tmp, err := packageSys.GetPackage("./test/"+name, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			co := &compile.Compiler{}
+			code := co.Compile(tmp.AST.(*parser.Node))
+
+			if wasmGolden[name] {
+				golden := filepath.Join("testdata", "wasm", name+".wat")
+				if *updateGolden {
+					if err := os.WriteFile(golden, []byte(code), 0644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if code != string(want) {
+					t.Errorf("输出与 %s 不一致", golden)
+				}
+			}
+
+			if wat2wasm == "" {
+				return
+			}
+			dir := t.TempDir()
+			wat, bin := filepath.Join(dir, "main.wat"), filepath.Join(dir, "main.wasm")
+			if err := os.WriteFile(wat, []byte(code), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if out, err := exec.Command(wat2wasm, wat, "-o", bin).CombinedOutput(); err != nil {
+				t.Fatalf("wat2wasm: %v\n%s", err, out)
+			}
+			if validate == "" {
+				return
+			}
+			if out, err := exec.Command(validate, bin).CombinedOutput(); err != nil {
+				t.Fatalf("wasm-validate: %v\n%s", err, out)
+			}
+		})
+	}
+}
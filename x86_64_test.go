@@ -0,0 +1,116 @@
+package main
+
+import (
+	"cuteify/compile"
+	packageSys "cuteify/package"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// x86_64Cases 用 x86-64 后端编译的测试程序及其退出码，与解释器在 64 位字长下一致；
+// loop_test 按 4 字节 int 编写，在 64 位字长下不能通过类型检查，不在其中
+var x86_64Cases = []struct {
+	name string
+	exit int
+}{
+	{"sysv_test", 64},
+	{"switch_test", 49},
+	{"struct_layout", 26},
+	{"method_test", 20},
+	{"simple_method", 42},
+	{"interface_test", 31},
+	{"global_test", 5},
+	{"pointer_test", 52},
+	{"array_test", 49},
+	{"cast_test", 95},
+	{"generic_test", 27},
+	{"callconv_test", 36},
+	{"fastcall_test", 87},
+	{"struct_test", 0},
+	{"struct_method", 0},
+	{"link_test", 0},
+}
+
+// x86_64Golden 与 testdata/x86_64 下的黄金文件比较输出的程序
+var x86_64Golden = map[string]bool{
+	"sysv_test":      true,
+	"switch_test":    true,
+	"interface_test": true,
+	"array_test":     true,
+	"pointer_test":   true,
+	"cast_test":      true,
+	"global_test":    true,
+}
+
+// TestX86_64 用 x86-64 后端编译 test/ 下的程序：部分程序的输出与黄金文件比较（go test -run TestX86_64 -update 更新），
+// 在 amd64 上找到 nasm 与 ld 时汇编链接后运行并检查退出码
+func TestX86_64(t *testing.T) {
+	nasm, _ := exec.LookPath("nasm")
+	ld, _ := exec.LookPath("ld")
+	run := nasm != "" && ld != "" && runtime.GOARCH == "amd64"
+
+	arch, ptrSize := compile.GoArch, typeSys.PtrSize
+	compile.GoArch, typeSys.PtrSize = "x86_64", compile.WordSize("x86_64")
+	defer func() { compile.GoArch, typeSys.PtrSize = arch, ptrSize }()
+
+	for _, c := range x86_64Cases {
+		t.Run(c.name, func(t *testing.T) {
+			tmp, err := packageSys.GetPackage("./test/"+c.name, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			co := &compile.Compiler{}
+			code := co.Compile(tmp.AST.(*parser.Node))
+
+			if x86_64Golden[c.name] {
+				golden := filepath.Join("testdata", "x86_64", c.name+".asm")
+				if *updateGolden {
+					if err := os.WriteFile(golden, []byte(code), 0644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if code != string(want) {
+					t.Errorf("输出与 %s 不一致", golden)
+				}
+			}
+
+			if !run {
+				return
+			}
+			dir := t.TempDir()
+			src, obj, bin := filepath.Join(dir, "main.asm"), filepath.Join(dir, "main.o"), filepath.Join(dir, "main")
+			if err := os.WriteFile(src, []byte(code), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if out, err := exec.Command(nasm, "-f", "elf64", src, "-o", obj).CombinedOutput(); err != nil {
+				t.Fatalf("nasm: %v\n%s", err, out)
+			}
+			if out, err := exec.Command(ld, obj, "-o", bin).CombinedOutput(); err != nil {
+				t.Fatalf("ld: %v\n%s", err, out)
+			}
+			cmd := exec.Command(bin)
+			cmd.Dir = dir
+			exit := 0
+			if err := cmd.Run(); err != nil {
+				var exitErr *exec.ExitError
+				if !errors.As(err, &exitErr) {
+					t.Fatal(err)
+				}
+				exit = exitErr.ExitCode()
+			}
+			if exit != c.exit {
+				t.Errorf("退出码为 %d，应为 %d", exit, c.exit)
+			}
+		})
+	}
+}
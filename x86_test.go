@@ -0,0 +1,102 @@
+package main
+
+import (
+	"cuteify/compile"
+	"cuteify/compile/emu"
+	packageSys "cuteify/package"
+	"cuteify/parser"
+	typeSys "cuteify/type"
+	"strings"
+	"testing"
+)
+
+// x86Case 用 32 位 x86 后端编译并在内置模拟器中运行的测试程序
+type x86Case struct {
+	name        string
+	arch        string // CUTE_ARCH
+	boundsCheck bool
+	exit        int
+	stderr      string // 标准错误输出应包含的内容
+}
+
+var x86Cases = []x86Case{
+	{name: "loop_test", arch: "x86", exit: 21},
+	{name: "bool_test", arch: "x86", exit: 36},
+	{name: "bool_test", arch: "x86.stdcall", exit: 36},
+	{name: "bool_test", arch: "x86.fastcall", exit: 36},
+	{name: "switch_test", arch: "x86", exit: 49},
+	{name: "switch_test", arch: "x86.stdcall", exit: 49},
+	{name: "switch_test", arch: "x86.fastcall", exit: 49},
+	{name: "struct_layout", arch: "x86", exit: 26},
+	{name: "method_test", arch: "x86", exit: 20},
+	{name: "simple_method", arch: "x86", exit: 42},
+	{name: "interface_test", arch: "x86", exit: 31},
+	{name: "global_test", arch: "x86", exit: 5},
+	{name: "pointer_test", arch: "x86", exit: 52},
+	{name: "array_test", arch: "x86", exit: 49},
+	{name: "array_test", arch: "x86", boundsCheck: true, exit: 49},
+	{name: "cast_test", arch: "x86", exit: 95},
+	{name: "generic_test", arch: "x86", exit: 27},
+	{name: "callconv_test", arch: "x86", exit: 36},
+	{name: "callconv_test", arch: "x86.stdcall", exit: 36},
+	{name: "fastcall_test", arch: "x86.fastcall", exit: 87},
+	{name: "fastcall_spill", arch: "x86.fastcall", exit: 46},
+	{name: "inline_test", arch: "x86", exit: 76},
+	{name: "struct_test", arch: "x86", exit: 0},
+	{name: "struct_method", arch: "x86", exit: 0},
+	{name: "link_test", arch: "x86", exit: 0},
+	{name: "build_keyword", arch: "x86", exit: 0},
+	{name: "memory_test", arch: "x86", exit: 0},
+	{name: "fs_test", arch: "x86", exit: 0},
+	{name: "bounds_test", arch: "x86", boundsCheck: true, exit: 2, stderr: "index out of range"},
+}
+
+// TestX86 用 x86 后端（默认经 IR 生成）编译 test/ 下的程序，在 compile/emu 模拟器中运行并检查退出码，不需要 nasm 与 ld
+func TestX86(t *testing.T) {
+	eachX86Case(t, func(t *testing.T, c x86Case) {
+		runX86(t, c, &compile.Compiler{})
+	})
+}
+
+// eachX86Case 对 x86Cases 中的每个程序运行子测试 f，运行期间 CUTE_ARCH 与字长按程序设置
+func eachX86Case(t *testing.T, f func(t *testing.T, c x86Case)) {
+	arch, ptrSize := compile.GoArch, typeSys.PtrSize
+	defer func() { compile.GoArch, typeSys.PtrSize = arch, ptrSize }()
+
+	for _, c := range x86Cases {
+		name := c.arch + "/" + c.name
+		if c.boundsCheck {
+			name += "/bounds-check"
+		}
+		t.Run(name, func(t *testing.T) {
+			compile.GoArch, typeSys.PtrSize = c.arch, compile.WordSize(c.arch)
+			f(t, c)
+		})
+	}
+}
+
+// runX86 用 co 编译程序（越界检查按程序设置）并在模拟器中运行，检查退出码与标准错误输出，返回生成的汇编与运行后的模拟器
+func runX86(t *testing.T, c x86Case, co *compile.Compiler) (string, *emu.Machine) {
+	t.Helper()
+	tmp, err := packageSys.GetPackage("./test/"+c.name, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	co.BoundsCheck = c.boundsCheck
+	code := co.Compile(tmp.AST.(*parser.Node))
+	m, err := emu.New(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exit, err := m.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exit != c.exit {
+		t.Errorf("退出码为 %d，应为 %d", exit, c.exit)
+	}
+	if !strings.Contains(m.Stderr.String(), c.stderr) {
+		t.Errorf("标准错误输出为 %q，应包含 %q", m.Stderr.String(), c.stderr)
+	}
+	return code, m
+}